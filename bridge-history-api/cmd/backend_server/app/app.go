@@ -1,7 +1,9 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"os"
 	"os/signal"
 
@@ -9,11 +11,15 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/urfave/cli/v2"
+	"gorm.io/gorm"
 
 	"bridge-history-api/config"
 	"bridge-history-api/internal/controller"
+	"bridge-history-api/internal/grpcserver"
+	"bridge-history-api/internal/ratelimit"
 	"bridge-history-api/internal/route"
 	"bridge-history-api/observability"
+	"bridge-history-api/orm"
 	"bridge-history-api/utils"
 )
 
@@ -28,7 +34,7 @@ func init() {
 	app.Name = "Scroll Bridge History Web Service"
 	app.Usage = "The Scroll Bridge History Web Service"
 	app.Flags = append(app.Flags, utils.CommonFlags...)
-	app.Commands = []*cli.Command{}
+	app.Commands = []*cli.Command{utils.MigrateCommand}
 
 	app.Before = func(ctx *cli.Context) error {
 		return utils.LogSetup(ctx)
@@ -42,23 +48,70 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
 	}
-	db, err := utils.InitDB(cfg.DB)
+	networkConfigs, err := cfg.NetworkConfigs()
 	if err != nil {
-		log.Crit("failed to init db", "err", err)
+		log.Crit("invalid networks of the config", "config file", cfgFile, "error", err)
+	}
+
+	shutdownTracing, err := observability.InitTracing(ctx.Context, cfg.Tracing, "bridge-history-api")
+	if err != nil {
+		log.Crit("failed to init the tracing", "err", err)
 	}
 	defer func() {
-		if deferErr := utils.CloseDB(db); deferErr != nil {
-			log.Error("failed to close db", "err", err)
+		if shutdownErr := shutdownTracing(context.Background()); shutdownErr != nil {
+			log.Error("failed to flush the spans", "err", shutdownErr)
 		}
 	}()
-	// init Prover Stats API
-	port := cfg.Server.HostPort
 
 	router := gin.Default()
-	controller.InitController(db)
-
 	registry := prometheus.DefaultRegisterer
-	route.Route(router, cfg, registry)
+	networks := make(map[string]*controller.Controllers, len(networkConfigs))
+	var dbs []*gorm.DB
+	for _, networkCfg := range networkConfigs {
+		db, initErr := utils.InitDB(networkCfg.DB)
+		if initErr != nil {
+			log.Crit("failed to init db", "network", networkCfg.Network, "err", initErr)
+		}
+		defer func(network string) {
+			if deferErr := utils.CloseDB(db); deferErr != nil {
+				log.Error("failed to close db", "network", network, "err", deferErr)
+			}
+		}(networkCfg.Network)
+		// the migrations are applied with `migrate up`, the server doesn't serve a schema it isn't built for
+		if initErr = utils.CheckSchemaVersion(db); initErr != nil {
+			log.Crit("the schema of the db doesn't match the binary", "network", networkCfg.Network, "err", initErr)
+		}
+		dbs = append(dbs, db)
+		// the server only reads what the fetcher writes, the reads are served by the replicas if any
+		if initErr = utils.InitReplicas(db, networkCfg.DB); initErr != nil {
+			log.Crit("failed to init the db replicas", "network", networkCfg.Network, "err", initErr)
+		}
+
+		// the metrics of the queries and the db of each network are labeled by the network
+		networkRegistry := prometheus.WrapRegistererWith(prometheus.Labels{"network": networkCfg.Network}, registry)
+		if err = orm.RegisterMetrics(db, networkRegistry); err != nil {
+			log.Crit("failed to register the db metrics", "network", networkCfg.Network, "err", err)
+		}
+		networks[networkCfg.Network] = controller.NewControllers(networkCfg, db, networkRegistry)
+	}
+	// init Prover Stats API
+	port := cfg.Server.HostPort
+
+	// the client ips of the rate limits and of the admin audits are only taken from X-Forwarded-For behind the trusted
+	// proxies, none are trusted without the rate limit config
+	var trustedProxies []string
+	if cfg.RateLimit != nil {
+		trustedProxies = cfg.RateLimit.TrustedProxies
+	}
+	if err = router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Crit("invalid trusted proxies of the rate limit config", "err", err)
+	}
+	var limiter *ratelimit.Limiter
+	if cfg.RateLimit != nil {
+		// the api keys are shared by the networks, they are stored in the db of the top level network
+		limiter = ratelimit.NewLimiter(cfg.RateLimit, dbs[0], registry)
+	}
+	route.Route(router, cfg, registry, networks, limiter)
 
 	go func() {
 		if runServerErr := router.Run(fmt.Sprintf(":%s", port)); runServerErr != nil {
@@ -66,7 +119,25 @@ func action(ctx *cli.Context) error {
 		}
 	}()
 
-	observability.Server(ctx, db)
+	if cfg.GRPC != nil {
+		services := make(map[string]*grpcserver.HistoryService, len(networks))
+		for network, controllers := range networks {
+			services[network] = controllers.GRPC
+		}
+		grpcServer := grpcserver.NewServer(cfg.NetworkName(), services)
+		listener, listenErr := net.Listen("tcp", fmt.Sprintf(":%s", cfg.GRPC.HostPort))
+		if listenErr != nil {
+			log.Crit("failed to listen the grpc port", "error", listenErr)
+		}
+		go func() {
+			if runServerErr := grpcServer.Serve(listener); runServerErr != nil {
+				log.Crit("run grpc server failure", "error", runServerErr)
+			}
+		}()
+		defer grpcServer.GracefulStop()
+	}
+
+	observability.Server(ctx, dbs...)
 
 	// Catch CTRL-C to ensure a graceful shutdown.
 	interrupt := make(chan os.Signal, 1)
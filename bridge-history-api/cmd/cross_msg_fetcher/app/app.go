@@ -7,13 +7,16 @@ import (
 	"os/signal"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/urfave/cli/v2"
+	"gorm.io/gorm"
 
 	"bridge-history-api/config"
 	"bridge-history-api/crossmsg"
 	"bridge-history-api/crossmsg/messageproof"
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/observability"
 	"bridge-history-api/orm"
 	"bridge-history-api/utils"
 )
@@ -29,7 +32,36 @@ func init() {
 	app.Name = "Scroll Bridge History API"
 	app.Usage = "The Scroll Bridge Web Backend"
 	app.Flags = append(app.Flags, utils.CommonFlags...)
-	app.Commands = []*cli.Command{}
+	app.Flags = append(app.Flags, &utils.NetworkFlag)
+	app.Commands = []*cli.Command{
+		{
+			Name:   "backfill",
+			Usage:  "Re-index the events of a block range, the events already indexed are skipped.",
+			Action: backfill,
+			Flags: []cli.Flag{
+				&utils.ConfigFileFlag,
+				&utils.NetworkFlag,
+				&cli.StringFlag{
+					Name:     "layer",
+					Usage:    "The layer the events are fetched from, L1 or L2.",
+					Required: true,
+				},
+				&cli.Uint64Flag{
+					Name:     "start",
+					Usage:    "The first block of the range.",
+					Required: true,
+				},
+				&cli.Uint64Flag{
+					Name:     "end",
+					Usage:    "The last block of the range.",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:  "events",
+					Usage: "The comma separated types of the events to backfill, cross_msgs, relayed_msgs, failed_relayed_msgs, l2_sent_msgs (L2 only), batches, replayed_msgs, dropped_msgs or refunded_msgs (L1 only). All the types of the layer if not specified.",
+				}},
+		},
+	}
 
 	app.Before = func(ctx *cli.Context) error {
 		return utils.LogSetup(ctx)
@@ -43,120 +75,208 @@ func action(ctx *cli.Context) error {
 	if err != nil {
 		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
 	}
-	subCtx, cancel := context.WithCancel(ctx.Context)
-	defer cancel()
-
-	l1client, err := ethclient.Dial(cfg.L1.Endpoint)
+	networkConfigs, err := cfg.NetworkConfigs()
 	if err != nil {
-		log.Crit("failed to connect l1 geth", "config file", cfgFile, "error", err)
+		log.Crit("invalid networks of the config", "config file", cfgFile, "error", err)
 	}
-	l2client, err := ethclient.Dial(cfg.L2.Endpoint)
-	if err != nil {
-		log.Crit("failed to connect l2 geth", "config file", cfgFile, "error", err)
+	if network := ctx.String(utils.NetworkFlag.Name); network != "" {
+		networkCfg, networkErr := cfg.NetworkConfig(network)
+		if networkErr != nil {
+			log.Crit("failed to select the network", "config file", cfgFile, "error", networkErr)
+		}
+		networkConfigs = []*config.Config{networkCfg}
 	}
+	subCtx, cancel := context.WithCancel(ctx.Context)
+	defer cancel()
 
-	db, err := utils.InitDB(cfg.DB)
+	// the metrics are exposed by the metrics server when enabled, the fetcher metrics add up the networks fetched
+	registry := prometheus.DefaultRegisterer
+	crossmsg.RegisterMetrics(registry)
+	messageproof.RegisterMetrics(registry)
+	shutdownTracing, err := observability.InitTracing(ctx.Context, cfg.Tracing, "bridge-history-fetcher")
 	if err != nil {
-		log.Crit("failed to init db", "err", err)
+		log.Crit("failed to init the tracing", "err", err)
 	}
 	defer func() {
-		if deferErr := utils.CloseDB(db); deferErr != nil {
-			log.Error("failed to close db", "err", err)
+		if shutdownErr := shutdownTracing(context.Background()); shutdownErr != nil {
+			log.Error("failed to flush the spans", "err", shutdownErr)
 		}
 	}()
-	if err != nil {
-		log.Crit("failed to connect to db", "config file", cfgFile, "error", err)
+
+	var dbs []*gorm.DB
+	for _, networkCfg := range networkConfigs {
+		db, stop := startFetchers(subCtx, networkCfg, registry)
+		defer stop()
+		dbs = append(dbs, db)
 	}
+	observability.Server(ctx, dbs...)
 
-	l1worker := &crossmsg.FetchEventWorker{F: crossmsg.L1FetchAndSaveEvents, G: crossmsg.GetLatestL1ProcessedHeight, Name: "L1 events fetch Worker"}
+	// Catch CTRL-C to ensure a graceful shutdown.
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
 
-	l2worker := &crossmsg.FetchEventWorker{F: crossmsg.L2FetchAndSaveEvents, G: crossmsg.GetLatestL2ProcessedHeight, Name: "L2 events fetch Worker"}
+	// Wait until the interrupt signal is received from an OS signal.
+	<-interrupt
 
-	l1AddressList := []common.Address{
-		common.HexToAddress(cfg.L1.CustomERC20GatewayAddr),
-		common.HexToAddress(cfg.L1.ERC721GatewayAddr),
-		common.HexToAddress(cfg.L1.ERC1155GatewayAddr),
-		common.HexToAddress(cfg.L1.MessengerAddr),
-		common.HexToAddress(cfg.L1.ETHGatewayAddr),
-		common.HexToAddress(cfg.L1.StandardERC20Gateway),
-		common.HexToAddress(cfg.L1.WETHGatewayAddr),
-	}
+	return nil
+}
 
-	if cfg.L1.USDCGatewayAddr != "" {
-		l1AddressList = append(l1AddressList, common.HexToAddress(cfg.L1.USDCGatewayAddr))
+// startFetchers starts the fetchers of the network of cfg and returns its db, the returned function stops the fetchers
+// and closes the db
+func startFetchers(subCtx context.Context, cfg *config.Config, reg prometheus.Registerer) (*gorm.DB, func()) {
+	var stops []func()
+	stop := func() {
+		for i := len(stops) - 1; i >= 0; i-- {
+			stops[i]()
+		}
 	}
 
-	if cfg.L1.LIDOGatewayAddr != "" {
-		l1AddressList = append(l1AddressList, common.HexToAddress(cfg.L1.LIDOGatewayAddr))
+	l1client, err := utils.DialEthClient(cfg.L1.Endpoint)
+	if err != nil {
+		log.Crit("failed to connect l1 geth", "network", cfg.Network, "error", err)
 	}
-
-	if cfg.L2.DAIGatewayAddr != "" {
-		l1AddressList = append(l1AddressList, common.HexToAddress(cfg.L1.DAIGatewayAddr))
+	l2client, err := utils.DialEthClient(cfg.L2.Endpoint)
+	if err != nil {
+		log.Crit("failed to connect l2 geth", "network", cfg.Network, "error", err)
 	}
 
-	l2AddressList := []common.Address{
-		common.HexToAddress(cfg.L2.CustomERC20GatewayAddr),
-		common.HexToAddress(cfg.L2.ERC721GatewayAddr),
-		common.HexToAddress(cfg.L2.ERC1155GatewayAddr),
-		common.HexToAddress(cfg.L2.MessengerAddr),
-		common.HexToAddress(cfg.L2.ETHGatewayAddr),
-		common.HexToAddress(cfg.L2.StandardERC20Gateway),
-		common.HexToAddress(cfg.L2.WETHGatewayAddr),
+	db, err := utils.InitDB(cfg.DB)
+	if err != nil {
+		log.Crit("failed to init db", "network", cfg.Network, "err", err)
 	}
+	if err = utils.CheckSchemaVersion(db); err != nil {
+		log.Crit("the schema of the db doesn't match the binary", "network", cfg.Network, "err", err)
+	}
+	stops = append(stops, func() {
+		if closeErr := utils.CloseDB(db); closeErr != nil {
+			log.Error("failed to close db", "network", cfg.Network, "err", closeErr)
+		}
+	})
 
-	if cfg.L2.USDCGatewayAddr != "" {
-		l2AddressList = append(l2AddressList, common.HexToAddress(cfg.L2.USDCGatewayAddr))
+	// the metrics of the db of each network are labeled by the network
+	if err = orm.RegisterMetrics(db, prometheus.WrapRegistererWith(prometheus.Labels{"network": cfg.Network}, reg)); err != nil {
+		log.Crit("failed to register the db metrics", "network", cfg.Network, "err", err)
 	}
 
-	if cfg.L2.LIDOGatewayAddr != "" {
-		l2AddressList = append(l2AddressList, common.HexToAddress(cfg.L2.LIDOGatewayAddr))
+	// the cached histories of the api servers are invalidated once the new messages are saved
+	cache := logic.NewRedisCache(cfg.Redis)
+	// the watchers of the api servers are notified once the withdrawals get claimable or finalized
+	claimableEvents := logic.NewRedisClaimableEvents(cfg.Redis)
+	// the webhooks of the addresses are notified once the withdrawals get claimable or finalized and the deposits finalized
+	webhooks := logic.NewWebhookNotifier(cfg.Webhooks, db)
+	// the indexed messages, relays and batch finalizations are queued for the message broker of the publisher
+	publisher, err := logic.NewEventPublisher(cfg.Publisher, cfg.Network, db)
+	if err != nil {
+		log.Crit("invalid publisher", "network", cfg.Network, "error", err)
 	}
 
-	if cfg.L2.DAIGatewayAddr != "" {
-		l2AddressList = append(l2AddressList, common.HexToAddress(cfg.L2.DAIGatewayAddr))
+	// the bridge events of the custom gateways are decoded by their configs
+	l1Gateways, err := utils.NewCustomGateways(cfg.L1.CustomGateways)
+	if err != nil {
+		log.Crit("invalid l1 custom gateways", "network", cfg.Network, "error", err)
+	}
+	l2Gateways, err := utils.NewCustomGateways(cfg.L2.CustomGateways)
+	if err != nil {
+		log.Crit("invalid l2 custom gateways", "network", cfg.Network, "error", err)
 	}
 
-	l1crossMsgFetcher, err := crossmsg.NewMsgFetcher(subCtx, cfg.L1, db, l1client, l1worker, l1AddressList, crossmsg.L1ReorgHandling)
+	l1worker := &crossmsg.FetchEventWorker{F: crossmsg.L1FetchAndSaveEventsWithHooks(cache, claimableEvents, webhooks, publisher, l1Gateways), G: crossmsg.GetLatestL1ProcessedHeight, Name: "L1 events fetch Worker", Layer: orm.Layer1Msg}
+
+	l2worker := &crossmsg.FetchEventWorker{F: crossmsg.L2FetchAndSaveEventsWithHooks(cache, webhooks, publisher, l2Gateways), G: crossmsg.GetLatestL2ProcessedHeight, Name: "L2 events fetch Worker", Layer: orm.Layer2Msg}
+
+	l1AddressList := crossmsg.L1Addresses(cfg)
+	l2AddressList := crossmsg.L2Addresses(cfg)
+
+	l1crossMsgFetcher, err := crossmsg.NewMsgFetcher(subCtx, cfg.L1, db, l1client, l1worker, l1AddressList, crossmsg.L1ReorgHandlingWithHooks(cache))
 	if err != nil {
-		log.Crit("failed to create l1 cross message fetcher", "error", err)
+		log.Crit("failed to create l1 cross message fetcher", "network", cfg.Network, "error", err)
 	}
 
 	go l1crossMsgFetcher.Start()
-	defer l1crossMsgFetcher.Stop()
+	stops = append(stops, l1crossMsgFetcher.Stop)
 
-	l2crossMsgFetcher, err := crossmsg.NewMsgFetcher(subCtx, cfg.L2, db, l2client, l2worker, l2AddressList, crossmsg.L2ReorgHandling)
+	l2crossMsgFetcher, err := crossmsg.NewMsgFetcher(subCtx, cfg.L2, db, l2client, l2worker, l2AddressList, crossmsg.L2ReorgHandlingWithHooks(cache))
 	if err != nil {
-		log.Crit("failed to create l2 cross message fetcher", "error", err)
+		log.Crit("failed to create l2 cross message fetcher", "network", cfg.Network, "error", err)
 	}
 
 	go l2crossMsgFetcher.Start()
-	defer l2crossMsgFetcher.Stop()
+	stops = append(stops, l2crossMsgFetcher.Stop)
 
 	CrossMsgOrm := orm.NewCrossMsg(db)
 
 	// BlockTimestamp fetcher for l1 and l2
 	l1BlockTimeFetcher := crossmsg.NewBlockTimestampFetcher(subCtx, cfg.L1.Confirmation, int(cfg.L1.BlockTime), l1client, CrossMsgOrm.UpdateL1BlockTimestamp, CrossMsgOrm.GetL1EarliestNoBlockTimestampHeight)
 	go l1BlockTimeFetcher.Start()
-	defer l1BlockTimeFetcher.Stop()
+	stops = append(stops, l1BlockTimeFetcher.Stop)
 
 	l2BlockTimeFetcher := crossmsg.NewBlockTimestampFetcher(subCtx, cfg.L2.Confirmation, int(cfg.L2.BlockTime), l2client, CrossMsgOrm.UpdateL2BlockTimestamp, CrossMsgOrm.GetL2EarliestNoBlockTimestampHeight)
 	go l2BlockTimeFetcher.Start()
-	defer l2BlockTimeFetcher.Stop()
+	stops = append(stops, l2BlockTimeFetcher.Stop)
+
+	// BlockTimestamp fetcher for the batch finalizations and the relays on l1
+	RollupBatchOrm := orm.NewRollupBatch(db)
+	finalizeTimeFetcher := crossmsg.NewBlockTimestampFetcher(subCtx, cfg.L1.Confirmation, int(cfg.L1.BlockTime), l1client, RollupBatchOrm.UpdateFinalizeTimestamp, RollupBatchOrm.GetEarliestNoFinalizeTimestampHeight)
+	go finalizeTimeFetcher.Start()
+	stops = append(stops, finalizeTimeFetcher.Stop)
+
+	RelayedMsgOrm := orm.NewRelayedMsg(db)
+	l1RelayTimeFetcher := crossmsg.NewBlockTimestampFetcher(subCtx, cfg.L1.Confirmation, int(cfg.L1.BlockTime), l1client, RelayedMsgOrm.UpdateL1BlockTimestamp, RelayedMsgOrm.GetL1EarliestNoBlockTimestampHeight)
+	go l1RelayTimeFetcher.Start()
+	stops = append(stops, l1RelayTimeFetcher.Stop)
+
+	// BlockTimestamp fetcher for the relays on l2, the times to finalize the deposits are measured with them
+	l2RelayTimeFetcher := crossmsg.NewBlockTimestampFetcher(subCtx, cfg.L2.Confirmation, int(cfg.L2.BlockTime), l2client, RelayedMsgOrm.UpdateL2BlockTimestamp, RelayedMsgOrm.GetL2EarliestNoBlockTimestampHeight)
+	go l2RelayTimeFetcher.Start()
+	stops = append(stops, l2RelayTimeFetcher.Stop)
+
+	// L1 finality fetcher for the finality of the deposits
+	l1FinalityFetcher := crossmsg.NewL1FinalityFetcher(subCtx, int(cfg.L1.BlockTime), l1client, db)
+	go l1FinalityFetcher.Start()
+	stops = append(stops, l1FinalityFetcher.Stop)
 
 	// Proof updater and batch fetcher
-	l2msgProofUpdater := messageproof.NewMsgProofUpdater(subCtx, cfg.L1.Confirmation, cfg.BatchInfoFetcher.BatchIndexStartBlock, db)
-	batchFetcher := crossmsg.NewBatchInfoFetcher(subCtx, common.HexToAddress(cfg.BatchInfoFetcher.ScrollChainAddr), cfg.BatchInfoFetcher.BatchIndexStartBlock, cfg.L1.Confirmation, int(cfg.L1.BlockTime), l1client, db, l2msgProofUpdater)
+	l2msgProofUpdater := messageproof.NewMsgProofUpdater(subCtx, cfg.L1.Confirmation, cfg.BatchInfoFetcher.BatchIndexStartBlock, db, cache, claimableEvents, webhooks)
+	batchFetcher := crossmsg.NewBatchInfoFetcher(subCtx, common.HexToAddress(cfg.BatchInfoFetcher.ScrollChainAddr), cfg.BatchInfoFetcher.BatchIndexStartBlock, cfg.L1.Confirmation, int(cfg.L1.BlockTime), l1client, db, l2msgProofUpdater, publisher)
 	go batchFetcher.Start()
-	defer batchFetcher.Stop()
+	stops = append(stops, batchFetcher.Stop)
+
+	// Stats aggregator maintaining the daily bridge stats of the stats apis
+	statsAggregator := crossmsg.NewStatsAggregator(subCtx, db)
+	go statsAggregator.Start()
+	stops = append(stops, statsAggregator.Stop)
+
+	// Token metadata fetcher for the symbols and the decimals of the tx histories
+	if cfg.TokenMetadata != nil {
+		tokenMetadataFetcher := crossmsg.NewTokenMetadataFetcher(subCtx, cfg.TokenMetadata, l1client, l2client, db)
+		go tokenMetadataFetcher.Start()
+		stops = append(stops, tokenMetadataFetcher.Stop)
+	}
 
-	// Catch CTRL-C to ensure a graceful shutdown.
-	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt)
+	// Webhook dispatcher delivering the queued events to the webhooks
+	if cfg.Webhooks != nil {
+		webhookDispatcher := crossmsg.NewWebhookDispatcher(subCtx, cfg.Webhooks, db)
+		go webhookDispatcher.Start()
+		stops = append(stops, webhookDispatcher.Stop)
+	}
 
-	// Wait until the interrupt signal is received from an OS signal.
-	<-interrupt
+	// Event dispatcher publishing the queued events to the message broker
+	if cfg.Publisher != nil {
+		eventDispatcher, dispatcherErr := crossmsg.NewEventDispatcher(subCtx, cfg.Publisher, cfg.Network, db)
+		if dispatcherErr != nil {
+			log.Crit("failed to create the event dispatcher", "network", cfg.Network, "error", dispatcherErr)
+		}
+		go eventDispatcher.Start()
+		stops = append(stops, eventDispatcher.Stop)
+	}
 
-	return nil
+	// Archiver moving the old relayed messages into the archive tables
+	if cfg.Archive != nil {
+		archiver := crossmsg.NewArchiver(subCtx, cfg.Archive, db)
+		go archiver.Start()
+		stops = append(stops, archiver.Stop)
+	}
+	return db, stop
 }
 
 // Run event watcher cmd instance.
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"bridge-history-api/config"
+	"bridge-history-api/crossmsg"
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/orm"
+	"bridge-history-api/utils"
+)
+
+// backfill re-indexes the events of the types on the layer in the block range
+func backfill(ctx *cli.Context) error {
+	var layer orm.MsgType
+	switch strings.ToUpper(ctx.String("layer")) {
+	case "L1":
+		layer = orm.Layer1Msg
+	case "L2":
+		layer = orm.Layer2Msg
+	default:
+		return fmt.Errorf("unknown layer %q, expected L1 or L2", ctx.String("layer"))
+	}
+	kinds, err := crossmsg.ParseBackfillKinds(layer, ctx.String("events"))
+	if err != nil {
+		return err
+	}
+
+	cfgFile := ctx.String(utils.ConfigFileFlag.Name)
+	fileCfg, err := config.NewConfig(cfgFile)
+	if err != nil {
+		log.Crit("failed to load config file", "config file", cfgFile, "error", err)
+	}
+	cfg, err := fileCfg.NetworkConfig(ctx.String(utils.NetworkFlag.Name))
+	if err != nil {
+		return err
+	}
+	layerCfg, addressList := cfg.L1, crossmsg.L1Addresses(cfg)
+	if layer == orm.Layer2Msg {
+		layerCfg, addressList = cfg.L2, crossmsg.L2Addresses(cfg)
+	}
+	gateways, err := utils.NewCustomGateways(layerCfg.CustomGateways)
+	if err != nil {
+		return err
+	}
+	client, err := utils.DialEthClient(layerCfg.Endpoint)
+	if err != nil {
+		log.Crit("failed to connect geth", "config file", cfgFile, "error", err)
+	}
+	db, err := utils.InitDB(cfg.DB)
+	if err != nil {
+		log.Crit("failed to init db", "err", err)
+	}
+	defer func() {
+		if deferErr := utils.CloseDB(db); deferErr != nil {
+			log.Error("failed to close db", "err", deferErr)
+		}
+	}()
+
+	// the cached histories of the api servers are invalidated once the messages are saved
+	cache := logic.NewRedisCache(cfg.Redis)
+	from, to := ctx.Uint64("start"), ctx.Uint64("end")
+	err = crossmsg.Backfill(ctx.Context, client, db, layer, from, to, addressList, common.HexToAddress(cfg.BatchInfoFetcher.ScrollChainAddr), kinds, cache, gateways)
+	if err != nil {
+		return fmt.Errorf("failed to backfill the blocks from %d to %d: %w", from, to, err)
+	}
+	log.Info("backfill finished", "layer", ctx.String("layer"), "from", from, "to", to)
+	return nil
+}
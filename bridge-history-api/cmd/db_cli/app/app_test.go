@@ -0,0 +1,26 @@
+package app
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateWithoutSubcommand(t *testing.T) {
+	// the deployments run `migrate` and `rollback` without the subcommands of `migrate`, they must reach the
+	// migrations rather than print the help and succeed, so the missing config fails them
+	missing := filepath.Join(t.TempDir(), "config.json")
+	for _, args := range [][]string{
+		{"db_cli", "migrate", "--config", missing},
+		{"db_cli", "migrate", "--config", missing, "--network", "sepolia"},
+		{"db_cli", "migrate", "up", "--config", missing},
+		{"db_cli", "rollback", "--config", missing},
+		{"db_cli", "rollback", "--config", missing, "--version", "33"},
+		{"db_cli", "migrate", "down", "--config", missing},
+	} {
+		err := app.Run(args)
+		assert.ErrorIs(t, err, fs.ErrNotExist, args)
+	}
+}
@@ -10,13 +10,14 @@ import (
 	"bridge-history-api/utils"
 )
 
+// getConfig returns the config of the network selected by the network flag, the top level network if not specified
 func getConfig(ctx *cli.Context) (*config.Config, error) {
 	file := ctx.String(utils.ConfigFileFlag.Name)
 	dbCfg, err := config.NewConfig(file)
 	if err != nil {
 		return nil, err
 	}
-	return dbCfg, nil
+	return dbCfg.NetworkConfig(ctx.String(utils.NetworkFlag.Name))
 }
 
 func initDB(dbCfg *config.DBConfig) (*gorm.DB, error) {
@@ -81,38 +82,3 @@ func dbVersion(ctx *cli.Context) error {
 
 	return err
 }
-
-// migrateDB migrate db
-func migrateDB(ctx *cli.Context) error {
-	cfg, err := getConfig(ctx)
-	if err != nil {
-		return err
-	}
-	gormDB, err := initDB(cfg.DB)
-	if err != nil {
-		return err
-	}
-	db, err := gormDB.DB()
-	if err != nil {
-		return err
-	}
-	return migrate.Migrate(db)
-}
-
-// rollbackDB rollback db by version
-func rollbackDB(ctx *cli.Context) error {
-	cfg, err := getConfig(ctx)
-	if err != nil {
-		return err
-	}
-	gormDB, err := initDB(cfg.DB)
-	if err != nil {
-		return err
-	}
-	db, err := gormDB.DB()
-	if err != nil {
-		return err
-	}
-	version := ctx.Int64("version")
-	return migrate.Rollback(db, &version)
-}
@@ -0,0 +1,63 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkConfigs(t *testing.T) {
+	cfg := &Config{
+		L1:               &LayerConfig{Endpoint: "l1"},
+		L2:               &LayerConfig{Endpoint: "l2"},
+		DB:               &DBConfig{DSN: "mainnet"},
+		Server:           &ServerConfig{HostPort: "8080", QueryTimeout: 5},
+		BatchInfoFetcher: &BatchInfoFetcherConfig{},
+		Networks: []*NetworkConfig{{
+			Name:             "sepolia",
+			L1:               &LayerConfig{Endpoint: "sepolia-l1"},
+			L2:               &LayerConfig{Endpoint: "sepolia-l2"},
+			DB:               &DBConfig{DSN: "sepolia"},
+			Server:           &ServerConfig{HostPort: "9090", FaucetAddrs: []string{"0x01"}},
+			BatchInfoFetcher: &BatchInfoFetcherConfig{},
+		}},
+	}
+	configs, err := cfg.NetworkConfigs()
+	assert.NoError(t, err)
+	assert.Len(t, configs, 2)
+	assert.Equal(t, DefaultNetwork, configs[0].Network)
+	assert.Nil(t, configs[0].Networks)
+	assert.Equal(t, "sepolia", configs[1].Network)
+	assert.Equal(t, "sepolia-l1", configs[1].L1.Endpoint)
+	// the port is always the top level one
+	assert.Equal(t, &ServerConfig{HostPort: "8080", FaucetAddrs: []string{"0x01"}}, configs[1].Server)
+
+	sepolia, err := cfg.NetworkConfig("sepolia")
+	assert.NoError(t, err)
+	assert.Equal(t, configs[1], sepolia)
+	top, err := cfg.NetworkConfig("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultNetwork, top.Network)
+	_, err = cfg.NetworkConfig("holesky")
+	assert.Error(t, err)
+
+	// the server config of the top level is taken if not overridden
+	cfg.Networks[0].Server = nil
+	configs, err = cfg.NetworkConfigs()
+	assert.NoError(t, err)
+	assert.Equal(t, cfg.Server, configs[1].Server)
+
+	cfg.Server.DepositCreditFinality = "justified"
+	_, err = cfg.NetworkConfigs()
+	assert.Error(t, err)
+	cfg.Server.DepositCreditFinality = "finalized"
+
+	cfg.Networks[0].DB = &DBConfig{DSN: "mainnet"}
+	_, err = cfg.NetworkConfigs()
+	assert.Error(t, err)
+
+	cfg.Networks[0].DB = &DBConfig{DSN: "sepolia"}
+	cfg.Networks[0].Name = DefaultNetwork
+	_, err = cfg.NetworkConfigs()
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+package crossmsg
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"bridge-history-api/config"
+	"bridge-history-api/orm"
+)
+
+const (
+	// defaultArchiveMinAge is the age of the relayed messages archived by default
+	defaultArchiveMinAge = 180 * 24 * time.Hour
+	// defaultArchivePartitionSize is the number of the heights of each partition of the archive tables by default
+	defaultArchivePartitionSize = 1000000
+	// defaultArchiveInterval is the interval the messages are archived by default
+	defaultArchiveInterval = time.Hour
+	// defaultArchiveBatchSize is the number of the messages moved per transaction by default
+	defaultArchiveBatchSize = 1000
+)
+
+// Archiver moves the relayed cross messages older than the min age into the archive tables, the withdrawals along with
+// their l2 sent messages. The messages not aggregated into the bridge stats yet are kept, so that the stats never
+// miss a message.
+type Archiver struct {
+	ctx           context.Context
+	minAge        time.Duration
+	partitionSize uint64
+	interval      time.Duration
+	batchSize     int
+	archiveOrm    *orm.Archive
+	cursorOrm     *orm.BridgeStatsCursor
+}
+
+// NewArchiver creates a new Archiver instance
+func NewArchiver(ctx context.Context, cfg *config.ArchiveConfig, db *gorm.DB) *Archiver {
+	a := &Archiver{
+		ctx:           ctx,
+		minAge:        time.Duration(cfg.MinAge) * time.Second,
+		partitionSize: cfg.PartitionSize,
+		interval:      time.Duration(cfg.Interval) * time.Second,
+		batchSize:     cfg.BatchSize,
+		archiveOrm:    orm.NewArchive(db),
+		cursorOrm:     orm.NewBridgeStatsCursor(db),
+	}
+	if a.minAge == 0 {
+		a.minAge = defaultArchiveMinAge
+	}
+	if a.partitionSize == 0 {
+		a.partitionSize = defaultArchivePartitionSize
+	}
+	if a.interval == 0 {
+		a.interval = defaultArchiveInterval
+	}
+	if a.batchSize <= 0 {
+		a.batchSize = defaultArchiveBatchSize
+	}
+	return a
+}
+
+// Start the Archiver
+func (a *Archiver) Start() {
+	log.Info("Archiver Start")
+	a.archive()
+	go func() {
+		tick := time.NewTicker(a.interval)
+		for {
+			select {
+			case <-a.ctx.Done():
+				tick.Stop()
+				return
+			case <-tick.C:
+				a.archive()
+			}
+		}
+	}()
+}
+
+// Stop the Archiver
+func (a *Archiver) Stop() {
+	log.Info("Archiver Stop")
+}
+
+// archive archives the old relayed messages of both directions
+func (a *Archiver) archive() {
+	for _, msgType := range []orm.MsgType{orm.Layer1Msg, orm.Layer2Msg} {
+		if err := a.archiveMsgs(msgType); err != nil {
+			log.Error("failed to archive the cross messages", "msgType", msgType, "err", err)
+		}
+	}
+}
+
+// archiveMsgs moves the archivable messages of the msg type batch by batch, until fewer than a batch are left
+func (a *Archiver) archiveMsgs(msgType orm.MsgType) error {
+	maxID, err := a.cursorOrm.GetLastID(a.ctx, msgsStatsCursors[msgType])
+	if err != nil {
+		return err
+	}
+	maxRelayID, err := a.cursorOrm.GetLastID(a.ctx, relaysStatsCursors[msgType])
+	if err != nil {
+		return err
+	}
+	before := time.Now().Add(-a.minAge)
+	for a.ctx.Err() == nil {
+		crossMsgs, err := a.archiveOrm.GetArchivableCrossMsgs(a.ctx, msgType, before, maxID, maxRelayID, a.batchSize)
+		if err != nil {
+			return err
+		}
+		if err = a.archiveOrm.ArchiveCrossMsgs(a.ctx, crossMsgs, a.partitionSize); err != nil {
+			return err
+		}
+		metrics.observeArchived(msgType, len(crossMsgs))
+		if len(crossMsgs) != 0 {
+			log.Info("archived the cross messages", "msgType", msgType, "count", len(crossMsgs))
+		}
+		if len(crossMsgs) < a.batchSize {
+			return nil
+		}
+	}
+	return a.ctx.Err()
+}
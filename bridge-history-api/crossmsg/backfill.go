@@ -0,0 +1,159 @@
+package crossmsg
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/orm"
+	"bridge-history-api/utils"
+)
+
+// BackfillKind is the kind of the events a backfill saves
+type BackfillKind string
+
+const (
+	// BackfillCrossMsgs the deposits on L1 or the withdrawals on L2
+	BackfillCrossMsgs BackfillKind = "cross_msgs"
+	// BackfillRelayedMsgs the messages relayed on the layer
+	BackfillRelayedMsgs BackfillKind = "relayed_msgs"
+	// BackfillFailedRelayedMsgs the messages failed to relay on the layer
+	BackfillFailedRelayedMsgs BackfillKind = "failed_relayed_msgs"
+	// BackfillL2SentMsgs the messages sent on L2, L2 only
+	BackfillL2SentMsgs BackfillKind = "l2_sent_msgs"
+	// BackfillBatches the batches committed, finalized and reverted on the scroll chain, L1 only
+	BackfillBatches BackfillKind = "batches"
+	// BackfillReplayedMsgs the deposits replayed by the messenger, L1 only
+	BackfillReplayedMsgs BackfillKind = "replayed_msgs"
+	// BackfillDroppedMsgs the deposits dropped by the messenger, L1 only
+	BackfillDroppedMsgs BackfillKind = "dropped_msgs"
+	// BackfillRefundedMsgs the dropped deposits refunded by the gateways, L1 only
+	BackfillRefundedMsgs BackfillKind = "refunded_msgs"
+)
+
+// backfillKinds the kinds of the events of each layer
+var backfillKinds = map[orm.MsgType][]BackfillKind{
+	orm.Layer1Msg: {BackfillCrossMsgs, BackfillRelayedMsgs, BackfillFailedRelayedMsgs, BackfillBatches, BackfillReplayedMsgs, BackfillDroppedMsgs, BackfillRefundedMsgs},
+	orm.Layer2Msg: {BackfillCrossMsgs, BackfillRelayedMsgs, BackfillFailedRelayedMsgs, BackfillL2SentMsgs},
+}
+
+// ParseBackfillKinds parses the comma separated kinds of the events of the layer to backfill, all the kinds of the
+// layer if kinds is empty
+func ParseBackfillKinds(layer orm.MsgType, kinds string) (map[BackfillKind]bool, error) {
+	layerKinds, ok := backfillKinds[layer]
+	if !ok {
+		return nil, fmt.Errorf("unknown layer %d", layer)
+	}
+	result := make(map[BackfillKind]bool)
+	if strings.TrimSpace(kinds) == "" {
+		for _, kind := range layerKinds {
+			result[kind] = true
+		}
+		return result, nil
+	}
+	for _, name := range strings.Split(kinds, ",") {
+		kind := BackfillKind(strings.TrimSpace(name))
+		found := false
+		for _, layerKind := range layerKinds {
+			found = found || kind == layerKind
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown event type %q on layer %d, expected one of %v", kind, layer, layerKinds)
+		}
+		result[kind] = true
+	}
+	return result, nil
+}
+
+// Backfill fetches the events of the kinds on the layer in the blocks from to to, both inclusive, and saves them the
+// same way as the fetcher does. The events already saved are skipped, so a range is backfilled any number of times
+// without duplicating the messages. The blocks are not recorded as indexed, the fetcher checks the blocks it indexes
+// itself, but the checkpoints of the kinds are extended over the range, closing the gaps it fills. The cached
+// histories of the saved messages are invalidated if cache is not nil. The events of the custom gateways are decoded
+// too if gateways is not nil.
+func Backfill(ctx context.Context, client *ethclient.Client, db *gorm.DB, layer orm.MsgType, from, to uint64, addrList []common.Address, scrollChainAddr common.Address, kinds map[BackfillKind]bool, cache logic.Cache, gateways *utils.CustomGateways) error {
+	if from > to {
+		return fmt.Errorf("invalid block range, start %d is greater than end %d", from, to)
+	}
+	for start := from; start <= to; start += fetchLimit {
+		end := start + fetchLimit - 1
+		if end > to {
+			end = to
+		}
+		if err := backfillRange(ctx, client, db, layer, int64(start), int64(end), addrList, scrollChainAddr, kinds, cache, gateways); err != nil {
+			return err
+		}
+		log.Info("backfilled blocks", "layer", layer, "from", start, "to", end)
+		if end == to {
+			break
+		}
+	}
+	return nil
+}
+
+// backfillRange backfills the blocks from to to, at most fetchLimit blocks
+func backfillRange(ctx context.Context, client *ethclient.Client, db *gorm.DB, layer orm.MsgType, from, to int64, addrList []common.Address, scrollChainAddr common.Address, kinds map[BackfillKind]bool, cache logic.Cache, gateways *utils.CustomGateways) error {
+	if layer == orm.Layer1Msg && kinds[BackfillBatches] {
+		events, err := fetchBatchEvents(ctx, client, from, to, scrollChainAddr)
+		if err != nil {
+			return err
+		}
+		if err = saveBatchEvents(ctx, db, events, nil); err != nil {
+			return fmt.Errorf("failed to save the batch events: %w", err)
+		}
+	}
+
+	// the events of all the kinds are fetched, the message hashes of the messages are parsed from the events of
+	// the other kinds in the same tx
+	fetchEvents, saveEvents := l1FetchEvents, saveL1Events
+	if layer == orm.Layer2Msg {
+		fetchEvents, saveEvents = l2FetchEvents, saveL2Events
+	}
+	events, err := fetchEvents(ctx, client, from, to, addrList, gateways)
+	if err != nil {
+		return err
+	}
+	events.blocks = nil
+	if !kinds[BackfillCrossMsgs] {
+		events.crossMsgs = nil
+	}
+	if !kinds[BackfillRelayedMsgs] {
+		events.relayedMsgs = nil
+	}
+	if !kinds[BackfillFailedRelayedMsgs] {
+		events.failedRelayedMsgs = nil
+	}
+	if !kinds[BackfillL2SentMsgs] {
+		events.l2SentMsgs = nil
+	}
+	if !kinds[BackfillReplayedMsgs] {
+		events.replayedMsgs = nil
+	}
+	if !kinds[BackfillDroppedMsgs] {
+		events.droppedMsgs = nil
+	}
+	if !kinds[BackfillRefundedMsgs] {
+		events.refundedMsgs = nil
+	}
+	// only the kinds backfilled are checkpointed
+	var checkpointKinds []BackfillKind
+	for _, kind := range messageKinds(layer) {
+		if kinds[kind] {
+			checkpointKinds = append(checkpointKinds, kind)
+		}
+	}
+	events.checkpoints = eventCheckpoints(layer, addrList, checkpointKinds, from, to)
+	if err = saveEvents(ctx, db, events, nil); err != nil {
+		return fmt.Errorf("failed to save the events: %w", err)
+	}
+	if err = logic.InvalidateHistoryCache(ctx, cache, db, events.crossMsgs, events.relayedMsgs, events.l2SentMsgs); err != nil {
+		log.Error("Backfill: Failed to invalidate the cached histories", "err", err)
+	}
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"gorm.io/gorm"
 
 	"bridge-history-api/crossmsg/messageproof"
+	"bridge-history-api/internal/logic"
 	"bridge-history-api/orm"
 	"bridge-history-api/utils"
 )
@@ -25,10 +26,12 @@ type BatchInfoFetcher struct {
 	db                   *gorm.DB
 	rollupOrm            *orm.RollupBatch
 	msgProofUpdater      *messageproof.MsgProofUpdater
+	publisher            *logic.EventPublisher
 }
 
-// NewBatchInfoFetcher creates a new BatchInfoFetcher instance
-func NewBatchInfoFetcher(ctx context.Context, scrollChainAddr common.Address, batchInfoStartNumber uint64, confirmation uint64, blockTimeInSec int, client *ethclient.Client, db *gorm.DB, msgProofUpdater *messageproof.MsgProofUpdater) *BatchInfoFetcher {
+// NewBatchInfoFetcher creates a new BatchInfoFetcher instance, the batch finalizations are published to publisher, may
+// be nil
+func NewBatchInfoFetcher(ctx context.Context, scrollChainAddr common.Address, batchInfoStartNumber uint64, confirmation uint64, blockTimeInSec int, client *ethclient.Client, db *gorm.DB, msgProofUpdater *messageproof.MsgProofUpdater, publisher *logic.EventPublisher) *BatchInfoFetcher {
 	return &BatchInfoFetcher{
 		ctx:                  ctx,
 		scrollChainAddr:      scrollChainAddr,
@@ -39,6 +42,7 @@ func NewBatchInfoFetcher(ctx context.Context, scrollChainAddr common.Address, ba
 		db:                   db,
 		rollupOrm:            orm.NewRollupBatch(db),
 		msgProofUpdater:      msgProofUpdater,
+		publisher:            publisher,
 	}
 }
 
@@ -106,11 +110,12 @@ func (b *BatchInfoFetcher) fetchBatchInfo() error {
 			to = number
 		}
 		// filter logs to fetch batches
-		err = FetchAndSaveBatchIndex(b.ctx, b.client, b.db, int64(from), int64(to), b.scrollChainAddr)
+		err = FetchAndSaveBatchIndex(b.ctx, b.client, b.db, int64(from), int64(to), b.scrollChainAddr, b.publisher)
 		if err != nil {
 			log.Error("Can not fetch and save from chain: ", "err", err)
 			return err
 		}
 	}
+	metrics.observeLag(b.ctx, b.client, "batch", number)
 	return nil
 }
@@ -12,12 +12,23 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/modern-go/reflect2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 
 	"bridge-history-api/config"
+	"bridge-history-api/orm"
 	"bridge-history-api/utils"
 )
 
+// tracerName the instrumentation name of the spans of the fetches
+const tracerName = "bridge-history-api/crossmsg"
+
+// the number of latest indexed blocks compared with the chain to find the common ancestor of a reorg
+const reorgLookback = 64
+
 // MsgFetcher fetches cross message events from blockchain and saves them to database
 type MsgFetcher struct {
 	ctx           context.Context
@@ -120,6 +131,10 @@ func (c *MsgFetcher) forwardFetchAndSaveMissingEvents(confirmation uint64) {
 		log.Error(fmt.Sprintf("%s: invalid get/fetch function", c.worker.Name))
 		return
 	}
+	if err = c.rollbackDivergedBlocks(); err != nil {
+		log.Error(fmt.Sprintf("%s: can not roll back the diverged blocks", c.worker.Name), "err", err)
+		return
+	}
 	processedHeight, err := c.worker.G(c.ctx, c.db)
 	if err != nil {
 		log.Error(fmt.Sprintf("%s: can not get latest processed block height", c.worker.Name))
@@ -130,18 +145,44 @@ func (c *MsgFetcher) forwardFetchAndSaveMissingEvents(confirmation uint64) {
 	} else {
 		processedHeight++
 	}
+	fetchedHeight := processedHeight - 1
 	for from := processedHeight; from <= number; from += fetchLimit {
 		to := from + fetchLimit - 1
 		if to > number {
 			to = number
 		}
+		// the calls of the nodes and the queries of each range are traced under the span of the range
+		ctx, span := otel.Tracer(tracerName).Start(c.ctx, c.worker.Name,
+			trace.WithAttributes(attribute.Int64("from", int64(from)), attribute.Int64("to", int64(to))))
 		// watch for overflow here, tho its unlikely to happen
-		err := c.worker.F(c.ctx, c.client, c.db, int64(from), int64(to), c.addressList)
+		err := c.worker.F(ctx, c.client, c.db, int64(from), int64(to), c.addressList)
 		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, "fetch failed")
+			span.End()
 			log.Error(fmt.Sprintf("%s: failed!", c.worker.Name), "err", err)
 			break
 		}
+		span.End()
+		fetchedHeight = to
+	}
+	metrics.observeLag(c.ctx, c.client, layerLabel(c.worker.Layer), fetchedHeight)
+}
+
+// rollbackDivergedBlocks compares the latest indexed blocks with the chain and rolls the indexed events back to the
+// common ancestor if they diverged, the events are indexed again from there by the next fetch. It catches the reorgs
+// the cached headers miss, the ones deeper than the cache and the ones happening while the fetcher is down.
+func (c *MsgFetcher) rollbackDivergedBlocks() error {
+	blocks, err := orm.NewIndexedBlock(c.db).GetLatestIndexedBlocks(c.ctx, c.worker.Layer, reorgLookback)
+	if err != nil {
+		return err
+	}
+	ancestor, diverged, err := FindCommonAncestor(c.ctx, c.client, blocks)
+	if err != nil || !diverged {
+		return err
 	}
+	log.Warn(fmt.Sprintf("%s: indexed blocks diverged from the chain", c.worker.Name), "common ancestor", ancestor, "latest indexed", blocks[0].Height)
+	return c.reorgHandling(c.ctx, ancestor, c.db)
 }
 
 func (c *MsgFetcher) fetchMissingLatestHeaders() {
@@ -213,3 +254,68 @@ func (c *MsgFetcher) fetchMissingLatestHeaders() {
 	}
 
 }
+
+// L1Addresses returns the addresses of the gateways, the messenger and the message queue on L1 the events are fetched
+// from
+func L1Addresses(cfg *config.Config) []common.Address {
+	addressList := []common.Address{
+		common.HexToAddress(cfg.L1.CustomERC20GatewayAddr),
+		common.HexToAddress(cfg.L1.ERC721GatewayAddr),
+		common.HexToAddress(cfg.L1.ERC1155GatewayAddr),
+		common.HexToAddress(cfg.L1.MessengerAddr),
+		common.HexToAddress(cfg.L1.ETHGatewayAddr),
+		common.HexToAddress(cfg.L1.StandardERC20Gateway),
+		common.HexToAddress(cfg.L1.WETHGatewayAddr),
+	}
+
+	if cfg.L1.USDCGatewayAddr != "" {
+		addressList = append(addressList, common.HexToAddress(cfg.L1.USDCGatewayAddr))
+	}
+
+	if cfg.L1.LIDOGatewayAddr != "" {
+		addressList = append(addressList, common.HexToAddress(cfg.L1.LIDOGatewayAddr))
+	}
+
+	if cfg.L2.DAIGatewayAddr != "" {
+		addressList = append(addressList, common.HexToAddress(cfg.L1.DAIGatewayAddr))
+	}
+
+	if cfg.L1.MessageQueueAddr != "" {
+		addressList = append(addressList, common.HexToAddress(cfg.L1.MessageQueueAddr))
+	}
+
+	for _, gateway := range cfg.L1.CustomGateways {
+		addressList = append(addressList, common.HexToAddress(gateway.Address))
+	}
+	return addressList
+}
+
+// L2Addresses returns the addresses of the gateways and the messenger on L2 the events are fetched from
+func L2Addresses(cfg *config.Config) []common.Address {
+	addressList := []common.Address{
+		common.HexToAddress(cfg.L2.CustomERC20GatewayAddr),
+		common.HexToAddress(cfg.L2.ERC721GatewayAddr),
+		common.HexToAddress(cfg.L2.ERC1155GatewayAddr),
+		common.HexToAddress(cfg.L2.MessengerAddr),
+		common.HexToAddress(cfg.L2.ETHGatewayAddr),
+		common.HexToAddress(cfg.L2.StandardERC20Gateway),
+		common.HexToAddress(cfg.L2.WETHGatewayAddr),
+	}
+
+	if cfg.L2.USDCGatewayAddr != "" {
+		addressList = append(addressList, common.HexToAddress(cfg.L2.USDCGatewayAddr))
+	}
+
+	if cfg.L2.LIDOGatewayAddr != "" {
+		addressList = append(addressList, common.HexToAddress(cfg.L2.LIDOGatewayAddr))
+	}
+
+	if cfg.L2.DAIGatewayAddr != "" {
+		addressList = append(addressList, common.HexToAddress(cfg.L2.DAIGatewayAddr))
+	}
+
+	for _, gateway := range cfg.L2.CustomGateways {
+		addressList = append(addressList, common.HexToAddress(gateway.Address))
+	}
+	return addressList
+}
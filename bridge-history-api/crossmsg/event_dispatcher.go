@@ -0,0 +1,162 @@
+package crossmsg
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"bridge-history-api/config"
+	"bridge-history-api/orm"
+)
+
+const (
+	// defaultPublisherInterval is the interval the queued events are published by default
+	defaultPublisherInterval = time.Second
+	// defaultPublisherBatchSize is the maximum number of the queued events published per interval by default
+	defaultPublisherBatchSize = 500
+	// defaultPublishTimeout is the timeout of each publication by default
+	defaultPublishTimeout = 5 * time.Second
+	// publishRetryBackoff is the wait before the publication is retried after a failure, doubled after each failure
+	publishRetryBackoff = time.Second
+	// publishMaxRetryBackoff caps the wait between the retries of a publication
+	publishMaxRetryBackoff = time.Minute
+)
+
+// EventDispatcher publishes the events of the event_outbox table to the message broker in the order they are queued,
+// from the cursor of the last event the broker accepted. A failed publication is retried with an exponential backoff
+// until the broker accepts it, so the events are published at least once and none is lost while the broker is down.
+type EventDispatcher struct {
+	ctx       context.Context
+	network   string
+	interval  time.Duration
+	batchSize int
+	timeout   time.Duration
+	broker    messageBroker
+	outboxOrm *orm.EventOutbox
+
+	failures      int
+	nextAttemptAt time.Time
+}
+
+// NewEventDispatcher creates a new EventDispatcher instance publishing the events of the network queued in db
+func NewEventDispatcher(ctx context.Context, cfg *config.PublisherConfig, network string, db *gorm.DB) (*EventDispatcher, error) {
+	interval := time.Duration(cfg.Interval) * time.Second
+	if interval == 0 {
+		interval = defaultPublisherInterval
+	}
+	batchSize := cfg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultPublisherBatchSize
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout == 0 {
+		timeout = defaultPublishTimeout
+	}
+	broker, err := newMessageBroker(cfg, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &EventDispatcher{
+		ctx:       ctx,
+		network:   network,
+		interval:  interval,
+		batchSize: batchSize,
+		timeout:   timeout,
+		broker:    broker,
+		outboxOrm: orm.NewEventOutbox(db),
+	}, nil
+}
+
+// Start the EventDispatcher
+func (d *EventDispatcher) Start() {
+	log.Info("EventDispatcher Start", "network", d.network)
+	go func() {
+		tick := time.NewTicker(d.interval)
+		for {
+			select {
+			case <-d.ctx.Done():
+				tick.Stop()
+				return
+			case <-tick.C:
+				if time.Now().Before(d.nextAttemptAt) {
+					continue
+				}
+				if err := d.dispatch(); err != nil {
+					d.failures++
+					d.nextAttemptAt = time.Now().Add(publishBackoff(d.failures))
+					log.Error("EventDispatcher: failed to publish the queued events", "network", d.network, "failures", d.failures, "err", err)
+					continue
+				}
+				d.failures = 0
+			}
+		}
+	}()
+}
+
+// Stop the EventDispatcher
+func (d *EventDispatcher) Stop() {
+	log.Info("EventDispatcher Stop", "network", d.network)
+	if err := d.broker.Close(); err != nil {
+		log.Warn("EventDispatcher: failed to close the broker", "network", d.network, "err", err)
+	}
+}
+
+// dispatch publishes the events queued after the cursor, the cursor moves past each run of the events of a topic
+// once the broker accepts it
+func (d *EventDispatcher) dispatch() error {
+	cursor, err := d.outboxOrm.GetEventOutboxCursor(d.ctx)
+	if err != nil {
+		return err
+	}
+	events, err := d.outboxOrm.GetEventOutboxAfter(d.ctx, cursor, d.batchSize)
+	if err != nil {
+		return err
+	}
+	for len(events) > 0 {
+		// the consecutive events of a topic are published at once, so that the order of the events is kept
+		n := 1
+		for n < len(events) && events[n].Topic == events[0].Topic {
+			n++
+		}
+		if err = d.publish(events[0].Topic, events[:n]); err != nil {
+			return err
+		}
+		if err = d.outboxOrm.UpdateEventOutboxCursor(d.ctx, events[n-1].ID); err != nil {
+			return err
+		}
+		events = events[n:]
+	}
+	return nil
+}
+
+// publish publishes the events to the topic within the timeout
+func (d *EventDispatcher) publish(topic string, events []*orm.EventOutbox) error {
+	messages := make([]*brokerMessage, 0, len(events))
+	for _, event := range events {
+		// the ids of the outbox of each network start at 1
+		id := d.network + "-" + strconv.FormatUint(event.ID, 10)
+		messages = append(messages, &brokerMessage{id: id, key: event.MsgKey, value: []byte(event.Payload)})
+	}
+	ctx, cancel := context.WithTimeout(d.ctx, d.timeout)
+	defer cancel()
+	if err := d.broker.Publish(ctx, topic, messages); err != nil {
+		return fmt.Errorf("failed to publish %d events to %s: %w", len(messages), topic, err)
+	}
+	return nil
+}
+
+// publishBackoff returns the wait after the failed publications before the publication is retried
+func publishBackoff(failures int) time.Duration {
+	backoff := publishRetryBackoff
+	for i := 1; i < failures && backoff < publishMaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > publishMaxRetryBackoff {
+		return publishMaxRetryBackoff
+	}
+	return backoff
+}
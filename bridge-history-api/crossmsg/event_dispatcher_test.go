@@ -0,0 +1,93 @@
+package crossmsg
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bridge-history-api/config"
+	"bridge-history-api/orm"
+)
+
+type fakeBroker struct {
+	topics   []string
+	messages [][]*brokerMessage
+	err      error
+}
+
+func (f *fakeBroker) Publish(_ context.Context, topic string, messages []*brokerMessage) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.topics = append(f.topics, topic)
+	f.messages = append(f.messages, messages)
+	return nil
+}
+
+func (f *fakeBroker) Close() error {
+	return nil
+}
+
+func TestPublishBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, publishBackoff(1))
+	assert.Equal(t, 2*time.Second, publishBackoff(2))
+	assert.Equal(t, 8*time.Second, publishBackoff(4))
+	assert.Equal(t, publishMaxRetryBackoff, publishBackoff(20))
+}
+
+func TestEventDispatcherPublish(t *testing.T) {
+	broker := &fakeBroker{}
+	dispatcher := &EventDispatcher{ctx: context.Background(), network: "sepolia", timeout: time.Second, broker: broker}
+	events := []*orm.EventOutbox{
+		{ID: 7, Topic: "bridge.cross_msg", MsgKey: "0x01", Payload: `{"type":"cross_msg"}`},
+		{ID: 9, Topic: "bridge.cross_msg", MsgKey: "0x02", Payload: `{}`},
+	}
+	require.NoError(t, dispatcher.publish("bridge.cross_msg", events))
+	assert.Equal(t, []string{"bridge.cross_msg"}, broker.topics)
+	assert.Equal(t, []*brokerMessage{
+		{id: "sepolia-7", key: "0x01", value: []byte(`{"type":"cross_msg"}`)},
+		{id: "sepolia-9", key: "0x02", value: []byte(`{}`)},
+	}, broker.messages[0])
+
+	broker.err = errors.New("broker down")
+	assert.ErrorContains(t, dispatcher.publish("bridge.cross_msg", events), "broker down")
+}
+
+func TestNewMessageBroker(t *testing.T) {
+	_, err := newMessageBroker(&config.PublisherConfig{Broker: "rabbitmq", URL: "amqp://localhost"}, time.Second)
+	assert.Error(t, err)
+	_, err = newMessageBroker(&config.PublisherConfig{Broker: "kafka", URL: " , "}, time.Second)
+	assert.Error(t, err)
+	_, err = newMessageBroker(&config.PublisherConfig{Broker: "kafka", URL: "localhost:9092", Username: "alice", SASLMechanism: "GSSAPI"}, time.Second)
+	assert.Error(t, err)
+	_, err = newMessageBroker(&config.PublisherConfig{Broker: "kafka", URL: "localhost:9092", TLS: &config.PublisherTLSConfig{CAFile: "missing.pem"}}, time.Second)
+	assert.Error(t, err)
+
+	broker, err := newMessageBroker(&config.PublisherConfig{Broker: "kafka", URL: "localhost:9092, localhost:9093", Username: "alice", Password: "secret", SASLMechanism: "scram-sha-512"}, time.Second)
+	require.NoError(t, err)
+	writer := broker.(*kafkaBroker).writer
+	assert.Equal(t, "localhost:9092,localhost:9093", writer.Addr.String())
+	assert.NotNil(t, writer.Transport)
+	assert.NoError(t, broker.Close())
+}
+
+func TestNATSBrokerDown(t *testing.T) {
+	// a port nothing listens on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	// the servers down are reconnected in the background, the publications fail meanwhile rather than being buffered
+	broker, err := newMessageBroker(&config.PublisherConfig{Broker: "nats", URL: "nats://" + addr}, time.Second)
+	require.NoError(t, err)
+	defer func() { _ = broker.Close() }()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Error(t, broker.Publish(ctx, "bridge.cross_msg", []*brokerMessage{{id: "sepolia-1", key: "0x01", value: []byte(`{}`)}}))
+}
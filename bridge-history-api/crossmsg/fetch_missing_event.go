@@ -3,14 +3,17 @@ package crossmsg
 import (
 	"context"
 	"math/big"
+	"time"
 
 	geth "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 	"gorm.io/gorm"
 
 	backendabi "bridge-history-api/abi"
+	"bridge-history-api/internal/logic"
 	"bridge-history-api/orm"
 	"bridge-history-api/utils"
 )
@@ -27,11 +30,13 @@ type FetchAndSave func(ctx context.Context, client *ethclient.Client, database *
 // GetLatestProcessed is a function type that gets the latest processed block height from database
 type GetLatestProcessed func(ctx context.Context, db *gorm.DB) (uint64, error)
 
-// FetchEventWorker defines worker with fetch and save function, processed number getter, and name
+// FetchEventWorker defines worker with fetch and save function, processed number getter, name, and the layer the
+// events are fetched from, Layer1Msg or Layer2Msg
 type FetchEventWorker struct {
-	F    FetchAndSave
-	G    GetLatestProcessed
-	Name string
+	F     FetchAndSave
+	G     GetLatestProcessed
+	Name  string
+	Layer orm.MsgType
 }
 
 // GetLatestL1ProcessedHeight get L1 the latest processed height
@@ -48,10 +53,53 @@ func GetLatestL1ProcessedHeight(ctx context.Context, db *gorm.DB) (uint64, error
 		log.Error("failed to get L1 relayed message processed height: ", "err", err)
 		return 0, err
 	}
-	if crossHeight > relayedHeight {
-		return crossHeight, nil
+	failedRelayedHeight, err := orm.NewFailedRelayedMsg(db).GetLatestFailedRelayedHeightOnL1(ctx)
+	if err != nil {
+		log.Error("failed to get L1 failed relayed message processed height: ", "err", err)
+		return 0, err
+	}
+	replayedHeight, err := orm.NewReplayedMsg(db).GetLatestReplayedHeight(ctx)
+	if err != nil {
+		log.Error("failed to get L1 replayed message processed height: ", "err", err)
+		return 0, err
+	}
+	droppedHeight, err := orm.NewDroppedMsg(db).GetLatestDroppedHeight(ctx)
+	if err != nil {
+		log.Error("failed to get L1 dropped message processed height: ", "err", err)
+		return 0, err
+	}
+	maxHeight := crossHeight
+	if maxHeight < relayedHeight {
+		maxHeight = relayedHeight
+	}
+	if maxHeight < failedRelayedHeight {
+		maxHeight = failedRelayedHeight
+	}
+	if maxHeight < replayedHeight {
+		maxHeight = replayedHeight
+	}
+	if maxHeight < droppedHeight {
+		maxHeight = droppedHeight
 	}
-	return relayedHeight, nil
+	refundedHeight, err := orm.NewRefundedMsg(db).GetLatestRefundedHeight(ctx)
+	if err != nil {
+		log.Error("failed to get L1 refunded message processed height: ", "err", err)
+		return 0, err
+	}
+	if maxHeight < refundedHeight {
+		maxHeight = refundedHeight
+	}
+	// the checkpoints cover the ranges without events too, the heights of the events stand in for the checkpoints of
+	// the ranges indexed before the checkpoints. The batches are checkpointed by the batch fetcher, on its own.
+	checkpointHeight, err := orm.NewEventCheckpoint(db).GetLatestCheckpointHeight(ctx, orm.Layer1Msg, messageEventTypes(orm.Layer1Msg))
+	if err != nil {
+		log.Error("failed to get L1 event checkpoint height", "err", err)
+		return 0, err
+	}
+	if maxHeight < checkpointHeight {
+		maxHeight = checkpointHeight
+	}
+	return maxHeight, nil
 }
 
 // GetLatestL2ProcessedHeight get L2 latest processed height
@@ -74,6 +122,11 @@ func GetLatestL2ProcessedHeight(ctx context.Context, db *gorm.DB) (uint64, error
 		log.Error("failed to get L2 sent message processed height", "err", err)
 		return 0, err
 	}
+	failedRelayedHeight, err := orm.NewFailedRelayedMsg(db).GetLatestFailedRelayedHeightOnL2(ctx)
+	if err != nil {
+		log.Error("failed to get L2 failed relayed message processed height", "err", err)
+		return 0, err
+	}
 	maxHeight := crossHeight
 	if maxHeight < relayedHeight {
 		maxHeight = relayedHeight
@@ -81,20 +134,148 @@ func GetLatestL2ProcessedHeight(ctx context.Context, db *gorm.DB) (uint64, error
 	if maxHeight < l2SentHeight {
 		maxHeight = l2SentHeight
 	}
+	if maxHeight < failedRelayedHeight {
+		maxHeight = failedRelayedHeight
+	}
+	checkpointHeight, err := orm.NewEventCheckpoint(db).GetLatestCheckpointHeight(ctx, orm.Layer2Msg, messageEventTypes(orm.Layer2Msg))
+	if err != nil {
+		log.Error("failed to get L2 event checkpoint height", "err", err)
+		return 0, err
+	}
+	if maxHeight < checkpointHeight {
+		maxHeight = checkpointHeight
+	}
 	return maxHeight, nil
 }
 
 // L1FetchAndSaveEvents fetch and save events on L1
 func L1FetchAndSaveEvents(ctx context.Context, client *ethclient.Client, db *gorm.DB, from int64, to int64, addrList []common.Address) error {
-	l1CrossMsgOrm := orm.NewCrossMsg(db)
-	relayedOrm := orm.NewRelayedMsg(db)
+	_, err := l1FetchAndSaveEvents(ctx, client, db, from, to, addrList, nil, nil)
+	return err
+}
+
+// L1FetchAndSaveEventsWithHooks returns L1FetchAndSaveEvents decoding the deposits of the custom gateways too and
+// queueing the saved events for the publisher along with them, then invalidating the cached histories of the senders
+// and the tx hashes of the messages in the saved events and publishing the finalized events of the withdrawals relayed
+// to the watchers and the webhooks. The hooks are skipped if cache, events and webhooks are all nil.
+func L1FetchAndSaveEventsWithHooks(cache logic.Cache, events logic.ClaimableEvents, webhooks *logic.WebhookNotifier, publisher *logic.EventPublisher, gateways *utils.CustomGateways) FetchAndSave {
+	fetchAndSave := func(ctx context.Context, client *ethclient.Client, db *gorm.DB, from int64, to int64, addrList []common.Address) (*savedEvents, error) {
+		return l1FetchAndSaveEvents(ctx, client, db, from, to, addrList, gateways, publisher)
+	}
+	if cache == nil && events == nil && webhooks == nil {
+		return withoutSaveHooks(fetchAndSave)
+	}
+	return withSaveHooks(cache, events, webhooks, "L1FetchAndSaveEvents", fetchAndSave)
+}
+
+// savedEvents the messages fetched and saved by one fetch, along with the blocks they are indexed from
+type savedEvents struct {
+	crossMsgs         []*orm.CrossMsg
+	relayedMsgs       []*orm.RelayedMsg
+	failedRelayedMsgs []*orm.FailedRelayedMsg
+	l2SentMsgs        []*orm.L2SentMsg
+	// the replays, the drops and the refunds of the deposits, L1 only
+	replayedMsgs []*orm.ReplayedMsg
+	droppedMsgs  []*orm.DroppedMsg
+	refundedMsgs []*orm.RefundedMsg
+	blocks       []*orm.IndexedBlock
+	// the ranges of the blocks the events are fetched from, saved along with the events
+	checkpoints []*orm.EventCheckpoint
+}
+
+// messageKinds the kinds of the events of the messages of each layer, checkpointed by the message fetchers
+func messageKinds(layer orm.MsgType) []BackfillKind {
+	var kinds []BackfillKind
+	for _, kind := range backfillKinds[layer] {
+		if kind != BackfillBatches {
+			kinds = append(kinds, kind)
+		}
+	}
+	return kinds
+}
+
+// messageEventTypes the event types of the checkpoints of the message fetcher of the layer
+func messageEventTypes(layer orm.MsgType) []string {
+	var eventTypes []string
+	for _, kind := range messageKinds(layer) {
+		eventTypes = append(eventTypes, string(kind))
+	}
+	return eventTypes
+}
+
+// eventCheckpoints returns the checkpoints of the events of the kinds of the contracts in the blocks from to to, each
+// contract and kind once
+func eventCheckpoints(layer orm.MsgType, contracts []common.Address, kinds []BackfillKind, from, to int64) []*orm.EventCheckpoint {
+	seen := make(map[common.Address]bool, len(contracts))
+	var checkpoints []*orm.EventCheckpoint
+	for _, contract := range contracts {
+		// a batch upsert can't update a checkpoint twice
+		if seen[contract] {
+			continue
+		}
+		seen[contract] = true
+		for _, kind := range kinds {
+			checkpoints = append(checkpoints, &orm.EventCheckpoint{Layer: int(layer), Contract: contract.Hex(), EventType: string(kind), StartHeight: uint64(from), Height: uint64(to)})
+		}
+	}
+	return checkpoints
+}
+
+// withoutSaveHooks returns the FetchAndSave running fetchAndSave only
+func withoutSaveHooks(fetchAndSave func(ctx context.Context, client *ethclient.Client, db *gorm.DB, from int64, to int64, addrList []common.Address) (*savedEvents, error)) FetchAndSave {
+	return func(ctx context.Context, client *ethclient.Client, db *gorm.DB, from int64, to int64, addrList []common.Address) error {
+		_, err := fetchAndSave(ctx, client, db, from, to, addrList)
+		return err
+	}
+}
+
+// withSaveHooks returns the FetchAndSave running fetchAndSave then invalidating the cached histories of the saved
+// messages, publishing the finalized events of the withdrawals relayed on layer1 and queueing the webhook deliveries of
+// the messages relayed. The events are saved already when the hooks fail, the cache entries expire shortly anyway and
+// the watchers and the webhooks miss the events.
+func withSaveHooks(cache logic.Cache, events logic.ClaimableEvents, webhooks *logic.WebhookNotifier, name string, fetchAndSave func(ctx context.Context, client *ethclient.Client, db *gorm.DB, from int64, to int64, addrList []common.Address) (*savedEvents, error)) FetchAndSave {
+	return func(ctx context.Context, client *ethclient.Client, db *gorm.DB, from int64, to int64, addrList []common.Address) error {
+		saved, err := fetchAndSave(ctx, client, db, from, to, addrList)
+		if err != nil {
+			return err
+		}
+		if err = logic.InvalidateHistoryCache(ctx, cache, db, saved.crossMsgs, saved.relayedMsgs, saved.l2SentMsgs); err != nil {
+			log.Error(name+": Failed to invalidate the cached histories", "err", err)
+		}
+		if err = logic.PublishFinalizedEvents(ctx, events, db, saved.relayedMsgs); err != nil {
+			log.Error(name+": Failed to publish the finalized events", "err", err)
+		}
+		if err = webhooks.NotifyRelayed(ctx, saved.relayedMsgs); err != nil {
+			log.Error(name+": Failed to queue the webhook deliveries", "err", err)
+		}
+		return nil
+	}
+}
+
+// l1FetchAndSaveEvents fetch and save events on L1, the saved messages are returned and queued for the publisher,
+// may be nil
+func l1FetchAndSaveEvents(ctx context.Context, client *ethclient.Client, db *gorm.DB, from int64, to int64, addrList []common.Address, gateways *utils.CustomGateways, publisher *logic.EventPublisher) (*savedEvents, error) {
+	events, err := l1FetchEvents(ctx, client, from, to, addrList, gateways)
+	if err != nil {
+		return nil, err
+	}
+	if err = saveL1Events(ctx, db, events, publisher); err != nil {
+		log.Crit("l1FetchAndSaveEvents: Failed to finish transaction", "err", err)
+		return nil, err
+	}
+	metrics.observeEvents(orm.Layer1Msg, events)
+	return events, nil
+}
+
+// l1FetchEvents fetch and parse the events on L1 in the blocks from to to, along with the events of the custom gateways
+func l1FetchEvents(ctx context.Context, client *ethclient.Client, from int64, to int64, addrList []common.Address, gateways *utils.CustomGateways) (*savedEvents, error) {
 	query := geth.FilterQuery{
 		FromBlock: big.NewInt(from), // inclusive
 		ToBlock:   big.NewInt(to),   // inclusive
 		Addresses: addrList,
 		Topics:    make([][]common.Hash, 1),
 	}
-	query.Topics[0] = make([]common.Hash, 7)
+	query.Topics[0] = make([]common.Hash, 20)
 	query.Topics[0][0] = backendabi.L1DepositETHSig
 	query.Topics[0][1] = backendabi.L1DepositERC20Sig
 	query.Topics[0][2] = backendabi.L1RelayedMessageEventSignature
@@ -102,46 +283,344 @@ func L1FetchAndSaveEvents(ctx context.Context, client *ethclient.Client, db *gor
 	query.Topics[0][4] = backendabi.L1DepositERC721Sig
 	query.Topics[0][5] = backendabi.L1DepositERC1155Sig
 	query.Topics[0][6] = backendabi.L1DepositWETHSig
+	query.Topics[0][7] = backendabi.L1FinalizeWithdrawETHSig
+	query.Topics[0][8] = backendabi.L1FinalizeWithdrawERC20Sig
+	query.Topics[0][9] = backendabi.L1FinalizeWithdrawERC721Sig
+	query.Topics[0][10] = backendabi.L1FinalizeWithdrawERC1155Sig
+	query.Topics[0][11] = backendabi.L1FinalizeBatchWithdrawERC721Sig
+	query.Topics[0][12] = backendabi.L1FinalizeBatchWithdrawERC1155Sig
+	query.Topics[0][13] = backendabi.L1FailedRelayedMessageEventSignature
+	query.Topics[0][14] = backendabi.L1BatchDepositERC721Sig
+	query.Topics[0][15] = backendabi.L1BatchDepositERC1155Sig
+	query.Topics[0][16] = backendabi.L1QueueTransactionEventSignature
+	query.Topics[0][17] = backendabi.L1DropTransactionEventSignature
+	query.Topics[0][18] = backendabi.L1RefundETHSig
+	query.Topics[0][19] = backendabi.L1RefundERC20Sig
+	query.Topics[0] = append(query.Topics[0], gateways.Topics()...)
 
 	logs, err := client.FilterLogs(ctx, query)
 	if err != nil {
 		log.Warn("Failed to get l1 event logs", "err", err)
-		return err
+		return nil, err
 	}
-	depositL1CrossMsgs, relayedMsg, err := utils.ParseBackendL1EventLogs(logs)
+	depositL1CrossMsgs, relayedMsg, err := utils.ParseBackendL1EventLogs(logs, gateways)
 	if err != nil {
 		log.Error("l1FetchAndSaveEvents: Failed to parse cross msg event logs", "err", err)
-		return err
+		return nil, err
+	}
+	failedRelayedMsgs, err := utils.ParseBackendL1FailedRelayedMsgs(logs)
+	if err != nil {
+		log.Error("l1FetchAndSaveEvents: Failed to parse failed relayed msg event logs", "err", err)
+		return nil, err
+	}
+	replayedMsgs, droppedMsgs, err := utils.ParseBackendL1MessageQueueEvents(logs)
+	if err != nil {
+		log.Error("l1FetchAndSaveEvents: Failed to parse message queue event logs", "err", err)
+		return nil, err
+	}
+	refundedMsgs, err := utils.ParseBackendL1RefundEvents(logs)
+	if err != nil {
+		log.Error("l1FetchAndSaveEvents: Failed to parse refund event logs", "err", err)
+		return nil, err
+	}
+	txs, err := fetchTxs(ctx, client, depositL1CrossMsgs, relayedMsg)
+	if err != nil {
+		log.Error("l1FetchAndSaveEvents: Failed to get txs of deposits and relayed msgs", "err", err)
+		return nil, err
+	}
+	if err = updateL1Relayers(ctx, client, txs, relayedMsg); err != nil {
+		log.Error("l1FetchAndSaveEvents: Failed to get relayers of relayed msgs", "err", err)
+		return nil, err
+	}
+	updateL1OriginMethods(txs, depositL1CrossMsgs)
+	if err = updateFees(ctx, client, txs, depositL1CrossMsgs); err != nil {
+		log.Error("l1FetchAndSaveEvents: Failed to get fees of deposits", "err", err)
+		return nil, err
+	}
+	blocks, err := indexedBlocks(ctx, client, orm.Layer1Msg, logs, to)
+	if err != nil {
+		log.Error("l1FetchAndSaveEvents: Failed to get the hashes of the indexed blocks", "err", err)
+		return nil, err
 	}
-	err = db.Transaction(func(tx *gorm.DB) error {
-		if txErr := l1CrossMsgOrm.InsertL1CrossMsg(ctx, depositL1CrossMsgs, tx); txErr != nil {
+	return &savedEvents{crossMsgs: depositL1CrossMsgs, relayedMsgs: relayedMsg, failedRelayedMsgs: failedRelayedMsgs, replayedMsgs: replayedMsgs, droppedMsgs: droppedMsgs, refundedMsgs: refundedMsgs, blocks: blocks,
+		checkpoints: eventCheckpoints(orm.Layer1Msg, addrList, messageKinds(orm.Layer1Msg), from, to)}, nil
+}
+
+// saveL1Events save the events on L1 along with their checkpoints in one transaction, the events saved already are
+// skipped so that a range interrupted or fetched again is saved once
+func saveL1Events(ctx context.Context, db *gorm.DB, events *savedEvents, publisher *logic.EventPublisher) error {
+	l1CrossMsgOrm := orm.NewCrossMsg(db)
+	relayedOrm := orm.NewRelayedMsg(db)
+	failedRelayedOrm := orm.NewFailedRelayedMsg(db)
+	replayedOrm := orm.NewReplayedMsg(db)
+	droppedOrm := orm.NewDroppedMsg(db)
+	refundedOrm := orm.NewRefundedMsg(db)
+	indexedBlockOrm := orm.NewIndexedBlock(db)
+	checkpointOrm := orm.NewEventCheckpoint(db)
+	return db.Transaction(func(tx *gorm.DB) error {
+		if txErr := l1CrossMsgOrm.InsertL1CrossMsg(ctx, events.crossMsgs, tx); txErr != nil {
 			log.Error("l1FetchAndSaveEvents: Failed to insert cross msg event logs", "err", txErr)
 			return txErr
 		}
-		if txErr := relayedOrm.InsertRelayedMsg(ctx, relayedMsg, tx); txErr != nil {
+		if txErr := replayedOrm.InsertReplayedMsg(ctx, events.replayedMsgs, tx); txErr != nil {
+			log.Error("l1FetchAndSaveEvents: Failed to insert replayed msg event logs", "err", txErr)
+			return txErr
+		}
+		// resolved after the deposits of the same range are inserted
+		droppedMsgs, txErr := resolveDroppedMsgs(ctx, l1CrossMsgOrm, events.droppedMsgs, tx)
+		if txErr != nil {
+			log.Error("l1FetchAndSaveEvents: Failed to resolve the deposits of dropped msg event logs", "err", txErr)
+			return txErr
+		}
+		if txErr = droppedOrm.InsertDroppedMsg(ctx, droppedMsgs, tx); txErr != nil {
+			log.Error("l1FetchAndSaveEvents: Failed to insert dropped msg event logs", "err", txErr)
+			return txErr
+		}
+		// resolved after the drops of the same range are inserted
+		refundedMsgs, txErr := resolveRefundedMsgs(ctx, droppedOrm, events.refundedMsgs, tx)
+		if txErr != nil {
+			log.Error("l1FetchAndSaveEvents: Failed to resolve the deposits of refund event logs", "err", txErr)
+			return txErr
+		}
+		if txErr = refundedOrm.InsertRefundedMsg(ctx, refundedMsgs, tx); txErr != nil {
+			log.Error("l1FetchAndSaveEvents: Failed to insert refunded msg event logs", "err", txErr)
+			return txErr
+		}
+		if txErr := relayedOrm.InsertRelayedMsg(ctx, events.relayedMsgs, tx); txErr != nil {
 			log.Error("l1FetchAndSaveEvents: Failed to insert relayed msg event logs", "err", txErr)
 			return txErr
 		}
+		if txErr := failedRelayedOrm.InsertFailedRelayedMsg(ctx, events.failedRelayedMsgs, tx); txErr != nil {
+			log.Error("l1FetchAndSaveEvents: Failed to insert failed relayed msg event logs", "err", txErr)
+			return txErr
+		}
+		if txErr := indexedBlockOrm.InsertIndexedBlocks(ctx, events.blocks, tx); txErr != nil {
+			log.Error("l1FetchAndSaveEvents: Failed to insert indexed blocks", "err", txErr)
+			return txErr
+		}
+		if txErr := checkpointOrm.ExtendEventCheckpoints(ctx, events.checkpoints, tx); txErr != nil {
+			log.Error("l1FetchAndSaveEvents: Failed to update event checkpoints", "err", txErr)
+			return txErr
+		}
+		if txErr := publisher.QueueMsgs(ctx, events.crossMsgs, events.relayedMsgs, tx); txErr != nil {
+			log.Error("l1FetchAndSaveEvents: Failed to queue the indexed events", "err", txErr)
+			return txErr
+		}
 		return nil
 	})
+}
+
+// resolveDroppedMsgs fills the message hash of each drop with the deposit sent at its queue index. A drop drops the
+// queue indexes of the deposit and of all its replays, only the drops of the queue indexes the deposits are sent at
+// are kept, the drops of the deposits not indexed are skipped.
+func resolveDroppedMsgs(ctx context.Context, crossMsgOrm *orm.CrossMsg, droppedMsgs []*orm.DroppedMsg, tx *gorm.DB) ([]*orm.DroppedMsg, error) {
+	if len(droppedMsgs) == 0 {
+		return nil, nil
+	}
+	queueIndexes := make([]uint64, 0, len(droppedMsgs))
+	for _, droppedMsg := range droppedMsgs {
+		queueIndexes = append(queueIndexes, droppedMsg.QueueIndex)
+	}
+	crossMsgs, err := crossMsgOrm.GetL1CrossMsgsByMsgNonces(ctx, queueIndexes, tx)
 	if err != nil {
-		log.Crit("l2FetchAndSaveEvents: Failed to finish transaction", "err", err)
+		return nil, err
+	}
+	msgHashes := make(map[uint64]string, len(crossMsgs))
+	for _, crossMsg := range crossMsgs {
+		msgHashes[crossMsg.MsgNonce] = crossMsg.MsgHash
+	}
+	var resolved []*orm.DroppedMsg
+	for _, droppedMsg := range droppedMsgs {
+		if msgHash, found := msgHashes[droppedMsg.QueueIndex]; found {
+			droppedMsg.MsgHash = msgHash
+			resolved = append(resolved, droppedMsg)
+		}
+	}
+	return resolved, nil
+}
+
+// resolveRefundedMsgs fills the message hash of each refund with the deposit dropped by the same tx, the dropMessage
+// tx drops one deposit and its gateway refunds it. The refunds of the deposits whose drops are not indexed are skipped.
+func resolveRefundedMsgs(ctx context.Context, droppedOrm *orm.DroppedMsg, refundedMsgs []*orm.RefundedMsg, tx *gorm.DB) ([]*orm.RefundedMsg, error) {
+	if len(refundedMsgs) == 0 {
+		return nil, nil
+	}
+	layer1Hashes := make([]string, 0, len(refundedMsgs))
+	for _, refundedMsg := range refundedMsgs {
+		layer1Hashes = append(layer1Hashes, refundedMsg.Layer1Hash)
+	}
+	droppedMsgs, err := droppedOrm.GetDroppedMsgsByLayer1Hashes(ctx, layer1Hashes, tx)
+	if err != nil {
+		return nil, err
+	}
+	msgHashes := make(map[string]string, len(droppedMsgs))
+	for _, droppedMsg := range droppedMsgs {
+		msgHashes[droppedMsg.Layer1Hash] = droppedMsg.MsgHash
+	}
+	var resolved []*orm.RefundedMsg
+	for _, refundedMsg := range refundedMsgs {
+		if msgHash, found := msgHashes[refundedMsg.Layer1Hash]; found {
+			refundedMsg.MsgHash = msgHash
+			resolved = append(resolved, refundedMsg)
+		}
+	}
+	return resolved, nil
+}
+
+// fetchTxs fetches the txs sending the cross msgs on their source layer and the txs relaying the relayed msgs, each
+// tx once however many msgs it sends or relays
+func fetchTxs(ctx context.Context, client *ethclient.Client, crossMsgs []*orm.CrossMsg, relayedMsgs []*orm.RelayedMsg) (map[common.Hash]*types.Transaction, error) {
+	txs := make(map[common.Hash]*types.Transaction)
+	fetch := func(txHash common.Hash) error {
+		if _, found := txs[txHash]; found {
+			return nil
+		}
+		tx, _, err := client.TransactionByHash(ctx, txHash)
+		if err != nil {
+			return err
+		}
+		txs[txHash] = tx
+		return nil
+	}
+	for _, crossMsg := range crossMsgs {
+		if err := fetch(crossMsgTxHash(crossMsg)); err != nil {
+			return nil, err
+		}
+	}
+	for _, relayedMsg := range relayedMsgs {
+		txHash := relayedMsg.Layer1Hash
+		if txHash == "" {
+			txHash = relayedMsg.Layer2Hash
+		}
+		if err := fetch(common.HexToHash(txHash)); err != nil {
+			return nil, err
+		}
+	}
+	return txs, nil
+}
+
+// crossMsgTxHash returns the hash of the tx sending the cross msg on its source layer
+func crossMsgTxHash(crossMsg *orm.CrossMsg) common.Hash {
+	if orm.MsgType(crossMsg.MsgType) == orm.Layer2Msg {
+		return common.HexToHash(crossMsg.Layer2Hash)
+	}
+	return common.HexToHash(crossMsg.Layer1Hash)
+}
+
+// updateL1Relayers fills the relayer and the gas fee of each relayed msg with the sender and the gas fee of its
+// layer1 relay tx, the relay txs are taken from txs
+func updateL1Relayers(ctx context.Context, client *ethclient.Client, txs map[common.Hash]*types.Transaction, relayedMsgs []*orm.RelayedMsg) error {
+	relayers := make(map[common.Hash]string)
+	gasFees := make(map[common.Hash]string)
+	for _, relayedMsg := range relayedMsgs {
+		txHash := common.HexToHash(relayedMsg.Layer1Hash)
+		relayer, found := relayers[txHash]
+		if !found {
+			tx := txs[txHash]
+			sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+			if err != nil {
+				return err
+			}
+			gasFee, err := utils.GetTxGasFee(ctx, client, tx)
+			if err != nil {
+				return err
+			}
+			relayer = sender.Hex()
+			relayers[txHash] = relayer
+			gasFees[txHash] = gasFee.String()
+		}
+		relayedMsg.Relayer = relayer
+		relayedMsg.GasFee = gasFees[txHash]
+	}
+	return nil
+}
+
+// updateFees fills the gas fee of each cross msg with the gas fee of the tx sending it and the bridge fee with the
+// value the tx pays on top of the message value, the layer2 messenger charges no fee for withdrawals. The withdrawals
+// also get the layer1 data fee of their tx. The msgs sent by the same tx share its gas fee, the txs are taken from txs.
+func updateFees(ctx context.Context, client *ethclient.Client, txs map[common.Hash]*types.Transaction, crossMsgs []*orm.CrossMsg) error {
+	gasFees := make(map[common.Hash]string)
+	l1DataFees := make(map[common.Hash]string)
+	for _, crossMsg := range crossMsgs {
+		txHash := crossMsgTxHash(crossMsg)
+		tx := txs[txHash]
+		if _, found := gasFees[txHash]; !found {
+			gasFee, l1DataFee, err := utils.GetTxFees(ctx, client, tx)
+			if err != nil {
+				return err
+			}
+			gasFees[txHash] = gasFee.String()
+			if l1DataFee != nil {
+				l1DataFees[txHash] = l1DataFee.String()
+			}
+		}
+		crossMsg.GasFee = gasFees[txHash]
+		crossMsg.BridgeFee = "0"
+		if orm.MsgType(crossMsg.MsgType) == orm.Layer1Msg {
+			crossMsg.BridgeFee = utils.GetBridgeFee(tx, crossMsg.MsgValue).String()
+		} else {
+			// empty if the node does not report the data fee
+			crossMsg.L1DataFee = l1DataFees[txHash]
+		}
+	}
+	return nil
+}
+
+// updateL1OriginMethods fills the origin method of each deposit with the method called by its layer1 tx,
+// and the origin tx nonce with the account nonce of the tx, the txs are taken from txs
+func updateL1OriginMethods(txs map[common.Hash]*types.Transaction, crossMsgs []*orm.CrossMsg) {
+	for _, crossMsg := range crossMsgs {
+		tx := txs[common.HexToHash(crossMsg.Layer1Hash)]
+		crossMsg.OriginMethod = utils.DecodeL1DepositMethod(tx.Data())
+		nonce := tx.Nonce()
+		crossMsg.OriginTxNonce = &nonce
 	}
-	return err
 }
 
 // L2FetchAndSaveEvents fetche and save events on L2
 func L2FetchAndSaveEvents(ctx context.Context, client *ethclient.Client, db *gorm.DB, from int64, to int64, addrList []common.Address) error {
-	l2CrossMsgOrm := orm.NewCrossMsg(db)
-	relayedOrm := orm.NewRelayedMsg(db)
-	l2SentMsgOrm := orm.NewL2SentMsg(db)
+	_, err := l2FetchAndSaveEvents(ctx, client, db, from, to, addrList, nil, nil)
+	return err
+}
+
+// L2FetchAndSaveEventsWithHooks returns L2FetchAndSaveEvents decoding the withdrawals of the custom gateways too and
+// queueing the saved events for the publisher along with them, then invalidating the cached histories of the senders
+// and the tx hashes of the messages in the saved events and notifying the webhooks of the deposits relayed. The hooks
+// are skipped if cache and webhooks are both nil.
+func L2FetchAndSaveEventsWithHooks(cache logic.Cache, webhooks *logic.WebhookNotifier, publisher *logic.EventPublisher, gateways *utils.CustomGateways) FetchAndSave {
+	fetchAndSave := func(ctx context.Context, client *ethclient.Client, db *gorm.DB, from int64, to int64, addrList []common.Address) (*savedEvents, error) {
+		return l2FetchAndSaveEvents(ctx, client, db, from, to, addrList, gateways, publisher)
+	}
+	if cache == nil && webhooks == nil {
+		return withoutSaveHooks(fetchAndSave)
+	}
+	return withSaveHooks(cache, nil, webhooks, "L2FetchAndSaveEvents", fetchAndSave)
+}
+
+// l2FetchAndSaveEvents fetch and save events on L2, the saved messages are returned and queued for the publisher,
+// may be nil
+func l2FetchAndSaveEvents(ctx context.Context, client *ethclient.Client, db *gorm.DB, from int64, to int64, addrList []common.Address, gateways *utils.CustomGateways, publisher *logic.EventPublisher) (*savedEvents, error) {
+	events, err := l2FetchEvents(ctx, client, from, to, addrList, gateways)
+	if err != nil {
+		return nil, err
+	}
+	if err = saveL2Events(ctx, db, events, publisher); err != nil {
+		log.Crit("l2FetchAndSaveEvents: Failed to begin db transaction", "err", err)
+		return nil, err
+	}
+	metrics.observeEvents(orm.Layer2Msg, events)
+	return events, nil
+}
+
+// l2FetchEvents fetch and parse the events on L2 in the blocks from to to, along with the events of the custom gateways
+func l2FetchEvents(ctx context.Context, client *ethclient.Client, from int64, to int64, addrList []common.Address, gateways *utils.CustomGateways) (*savedEvents, error) {
 	query := geth.FilterQuery{
 		FromBlock: big.NewInt(from), // inclusive
 		ToBlock:   big.NewInt(to),   // inclusive
 		Addresses: addrList,
 		Topics:    make([][]common.Hash, 1),
 	}
-	query.Topics[0] = make([]common.Hash, 7)
+	query.Topics[0] = make([]common.Hash, 16)
 	query.Topics[0][0] = backendabi.L2WithdrawETHSig
 	query.Topics[0][1] = backendabi.L2WithdrawERC20Sig
 	query.Topics[0][2] = backendabi.L2RelayedMessageEventSignature
@@ -149,65 +628,197 @@ func L2FetchAndSaveEvents(ctx context.Context, client *ethclient.Client, db *gor
 	query.Topics[0][4] = backendabi.L2WithdrawERC721Sig
 	query.Topics[0][5] = backendabi.L2WithdrawERC1155Sig
 	query.Topics[0][6] = backendabi.L2WithdrawWETHSig
+	query.Topics[0][7] = backendabi.L2FinalizeDepositETHSig
+	query.Topics[0][8] = backendabi.L2FinalizeDepositERC20Sig
+	query.Topics[0][9] = backendabi.L2FinalizeDepositERC721Sig
+	query.Topics[0][10] = backendabi.L2FinalizeDepositERC1155Sig
+	query.Topics[0][11] = backendabi.L2FinalizeBatchDepositERC721Sig
+	query.Topics[0][12] = backendabi.L2FinalizeBatchDepositERC1155Sig
+	query.Topics[0][13] = backendabi.L2FailedRelayedMessageEventSignature
+	query.Topics[0][14] = backendabi.L2BatchWithdrawERC721Sig
+	query.Topics[0][15] = backendabi.L2BatchWithdrawERC1155Sig
+	query.Topics[0] = append(query.Topics[0], gateways.Topics()...)
 
 	logs, err := client.FilterLogs(ctx, query)
 	if err != nil {
 		log.Warn("Failed to get l2 event logs", "err", err)
-		return err
+		return nil, err
 	}
-	depositL2CrossMsgs, relayedMsg, l2SentMsgs, err := utils.ParseBackendL2EventLogs(logs)
+	depositL2CrossMsgs, relayedMsg, l2SentMsgs, err := utils.ParseBackendL2EventLogs(logs, gateways)
 	if err != nil {
 		log.Error("l2FetchAndSaveEvents: Failed to parse cross msg event logs", "err", err)
-		return err
+		return nil, err
+	}
+	failedRelayedMsgs, err := utils.ParseBackendL2FailedRelayedMsgs(logs)
+	if err != nil {
+		log.Error("l2FetchAndSaveEvents: Failed to parse failed relayed msg event logs", "err", err)
+		return nil, err
+	}
+	txs, err := fetchTxs(ctx, client, depositL2CrossMsgs, nil)
+	if err != nil {
+		log.Error("l2FetchAndSaveEvents: Failed to get txs of withdrawals", "err", err)
+		return nil, err
+	}
+	if err = updateFees(ctx, client, txs, depositL2CrossMsgs); err != nil {
+		log.Error("l2FetchAndSaveEvents: Failed to get fees of withdrawals", "err", err)
+		return nil, err
+	}
+	blocks, err := indexedBlocks(ctx, client, orm.Layer2Msg, logs, to)
+	if err != nil {
+		log.Error("l2FetchAndSaveEvents: Failed to get the hashes of the indexed blocks", "err", err)
+		return nil, err
 	}
 
-	err = db.Transaction(func(tx *gorm.DB) error {
-		if txErr := l2CrossMsgOrm.InsertL2CrossMsg(ctx, depositL2CrossMsgs, tx); txErr != nil {
+	return &savedEvents{crossMsgs: depositL2CrossMsgs, relayedMsgs: relayedMsg, failedRelayedMsgs: failedRelayedMsgs, l2SentMsgs: l2SentMsgs, blocks: blocks,
+		checkpoints: eventCheckpoints(orm.Layer2Msg, addrList, messageKinds(orm.Layer2Msg), from, to)}, nil
+}
+
+// saveL2Events save the events on L2 along with their checkpoints in one transaction, see saveL1Events
+func saveL2Events(ctx context.Context, db *gorm.DB, events *savedEvents, publisher *logic.EventPublisher) error {
+	l2CrossMsgOrm := orm.NewCrossMsg(db)
+	relayedOrm := orm.NewRelayedMsg(db)
+	l2SentMsgOrm := orm.NewL2SentMsg(db)
+	failedRelayedOrm := orm.NewFailedRelayedMsg(db)
+	indexedBlockOrm := orm.NewIndexedBlock(db)
+	checkpointOrm := orm.NewEventCheckpoint(db)
+	return db.Transaction(func(tx *gorm.DB) error {
+		if txErr := l2CrossMsgOrm.InsertL2CrossMsg(ctx, events.crossMsgs, tx); txErr != nil {
 			log.Error("l2FetchAndSaveEvents: Failed to insert cross msg event logs", "err", txErr)
 			return txErr
 		}
 
-		if txErr := relayedOrm.InsertRelayedMsg(ctx, relayedMsg, tx); txErr != nil {
+		if txErr := relayedOrm.InsertRelayedMsg(ctx, events.relayedMsgs, tx); txErr != nil {
 			log.Error("l2FetchAndSaveEvents: Failed to insert relayed message event logs", "err", txErr)
 			return txErr
 		}
 
-		if txErr := l2SentMsgOrm.InsertL2SentMsg(ctx, l2SentMsgs, tx); txErr != nil {
+		if txErr := l2SentMsgOrm.InsertL2SentMsg(ctx, events.l2SentMsgs, tx); txErr != nil {
 			log.Error("l2FetchAndSaveEvents: Failed to insert l2 sent message", "err", txErr)
 			return txErr
 		}
+
+		if txErr := failedRelayedOrm.InsertFailedRelayedMsg(ctx, events.failedRelayedMsgs, tx); txErr != nil {
+			log.Error("l2FetchAndSaveEvents: Failed to insert failed relayed message event logs", "err", txErr)
+			return txErr
+		}
+		if txErr := indexedBlockOrm.InsertIndexedBlocks(ctx, events.blocks, tx); txErr != nil {
+			log.Error("l2FetchAndSaveEvents: Failed to insert indexed blocks", "err", txErr)
+			return txErr
+		}
+		if txErr := checkpointOrm.ExtendEventCheckpoints(ctx, events.checkpoints, tx); txErr != nil {
+			log.Error("l2FetchAndSaveEvents: Failed to update event checkpoints", "err", txErr)
+			return txErr
+		}
+		if txErr := publisher.QueueMsgs(ctx, events.crossMsgs, events.relayedMsgs, tx); txErr != nil {
+			log.Error("l2FetchAndSaveEvents: Failed to queue the indexed events", "err", txErr)
+			return txErr
+		}
 		return nil
 	})
+}
+
+// batchEvents the batches committed, finalized and reverted in a block range
+type batchEvents struct {
+	rollupBatches    []*orm.RollupBatch
+	finalizedBatches []*orm.RollupBatch
+	revertedBatches  []*orm.RollupBatch
+	// the range of the blocks the events are fetched from, saved along with the events
+	checkpoints []*orm.EventCheckpoint
+}
+
+// FetchAndSaveBatchIndex fetche and save batch index, the saved finalizations are queued for publisher, may be nil
+func FetchAndSaveBatchIndex(ctx context.Context, client *ethclient.Client, db *gorm.DB, from int64, to int64, scrollChainAddr common.Address, publisher *logic.EventPublisher) error {
+	events, err := fetchBatchEvents(ctx, client, from, to, scrollChainAddr)
 	if err != nil {
-		log.Crit("l2FetchAndSaveEvents: Failed to begin db transaction", "err", err)
+		return err
 	}
-	return err
+	err = saveBatchEvents(ctx, db, events, publisher)
+	if err != nil {
+		log.Crit("FetchAndSaveBatchIndex: Failed to finish transaction", "err", err)
+		return err
+	}
+	metrics.observeBatchEvents(events)
+	return nil
 }
 
-// FetchAndSaveBatchIndex fetche and save batch index
-func FetchAndSaveBatchIndex(ctx context.Context, client *ethclient.Client, db *gorm.DB, from int64, to int64, scrollChainAddr common.Address) error {
-	rollupBatchOrm := orm.NewRollupBatch(db)
+// fetchBatchEvents fetch and parse the batch events of the scroll chain in the blocks from to to
+func fetchBatchEvents(ctx context.Context, client *ethclient.Client, from int64, to int64, scrollChainAddr common.Address) (*batchEvents, error) {
 	query := geth.FilterQuery{
 		FromBlock: big.NewInt(from), // inclusive
 		ToBlock:   big.NewInt(to),   // inclusive
 		Addresses: []common.Address{scrollChainAddr},
 		Topics:    make([][]common.Hash, 1),
 	}
-	query.Topics[0] = make([]common.Hash, 1)
+	query.Topics[0] = make([]common.Hash, 3)
 	query.Topics[0][0] = backendabi.L1CommitBatchEventSignature
+	query.Topics[0][1] = backendabi.L1FinalizeBatchEventSignature
+	query.Topics[0][2] = backendabi.L1RevertBatchEventSignature
 	logs, err := client.FilterLogs(ctx, query)
 	if err != nil {
 		log.Warn("Failed to get batch commit event logs", "err", err)
-		return err
+		return nil, err
 	}
 	rollupBatches, err := utils.ParseBatchInfoFromScrollChain(ctx, client, logs)
 	if err != nil {
 		log.Error("FetchAndSaveBatchIndex: Failed to parse batch commit msg event logs", "err", err)
-		return err
+		return nil, err
 	}
-	if txErr := rollupBatchOrm.InsertRollupBatch(ctx, rollupBatches); txErr != nil {
-		log.Crit("FetchAndSaveBatchIndex: Failed to insert batch commit msg event logs", "err", txErr)
-		return txErr
+	finalizedBatches, err := utils.ParseBatchFinalizationFromScrollChain(logs)
+	if err != nil {
+		log.Error("FetchAndSaveBatchIndex: Failed to parse batch finalize event logs", "err", err)
+		return nil, err
 	}
-	return nil
+	revertedBatches, err := utils.ParseBatchRevertFromScrollChain(logs)
+	if err != nil {
+		log.Error("FetchAndSaveBatchIndex: Failed to parse batch revert event logs", "err", err)
+		return nil, err
+	}
+	// the batches committed and reverted in the range are inserted as reverted,
+	// so that the batch index committed again in the range does not conflict
+	revertedBatchHashes := make(map[string]struct{}, len(revertedBatches))
+	for _, batch := range revertedBatches {
+		revertedBatchHashes[batch.BatchHash] = struct{}{}
+	}
+	for _, batch := range rollupBatches {
+		if _, found := revertedBatchHashes[batch.BatchHash]; found {
+			batch.Reverted = true
+			batch.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+		}
+	}
+	checkpoints := eventCheckpoints(orm.Layer1Msg, []common.Address{scrollChainAddr}, []BackfillKind{BackfillBatches}, from, to)
+	return &batchEvents{rollupBatches: rollupBatches, finalizedBatches: finalizedBatches, revertedBatches: revertedBatches, checkpoints: checkpoints}, nil
+}
+
+// saveBatchEvents save the batch events along with their checkpoint in one transaction, the batches saved already
+// are skipped. The reverts and the finalizations are updates, saving them again changes nothing.
+func saveBatchEvents(ctx context.Context, db *gorm.DB, events *batchEvents, publisher *logic.EventPublisher) error {
+	rollupBatchOrm := orm.NewRollupBatch(db)
+	checkpointOrm := orm.NewEventCheckpoint(db)
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, batch := range events.revertedBatches {
+			if txErr := rollupBatchOrm.RevertRollupBatch(ctx, batch.BatchHash, tx); txErr != nil {
+				log.Error("FetchAndSaveBatchIndex: Failed to revert batch", "batch index", batch.BatchIndex, "err", txErr)
+				return txErr
+			}
+		}
+		if txErr := rollupBatchOrm.InsertRollupBatch(ctx, events.rollupBatches, tx); txErr != nil {
+			log.Error("FetchAndSaveBatchIndex: Failed to insert batch commit msg event logs", "err", txErr)
+			return txErr
+		}
+		for _, batch := range events.finalizedBatches {
+			if txErr := rollupBatchOrm.UpdateRollupBatchFinalization(ctx, batch.BatchIndex, batch.FinalizeHeight, batch.FinalizeTxHash, batch.StateRoot, tx); txErr != nil {
+				log.Error("FetchAndSaveBatchIndex: Failed to update batch finalization", "batch index", batch.BatchIndex, "err", txErr)
+				return txErr
+			}
+		}
+		if txErr := checkpointOrm.ExtendEventCheckpoints(ctx, events.checkpoints, tx); txErr != nil {
+			log.Error("FetchAndSaveBatchIndex: Failed to update event checkpoints", "err", txErr)
+			return txErr
+		}
+		if txErr := publisher.QueueFinalizedBatches(ctx, events.finalizedBatches, tx); txErr != nil {
+			log.Error("FetchAndSaveBatchIndex: Failed to queue the batch finalizations", "err", txErr)
+			return txErr
+		}
+		return nil
+	})
 }
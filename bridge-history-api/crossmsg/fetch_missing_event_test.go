@@ -0,0 +1,72 @@
+package crossmsg
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bridge-history-api/orm"
+	"bridge-history-api/utils"
+)
+
+func TestFetchTxs(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	depositTx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: 7, GasPrice: big.NewInt(1), Gas: 21000, Data: common.FromHex("0x9f8420b3")})
+	require.NoError(t, err)
+	relayTx, err := types.SignNewTx(key, signer, &types.LegacyTx{Nonce: 8, GasPrice: big.NewInt(1), Gas: 21000})
+	require.NoError(t, err)
+	nodeTxs := map[common.Hash]*types.Transaction{depositTx.Hash(): depositTx, relayTx.Hash(): relayTx}
+
+	var mu sync.Mutex
+	calls := make(map[common.Hash]int)
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []common.Hash   `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "eth_getTransactionByHash", req.Method)
+		mu.Lock()
+		calls[req.Params[0]]++
+		mu.Unlock()
+		result, err := nodeTxs[req.Params[0]].MarshalJSON()
+		require.NoError(t, err)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": json.RawMessage(result)})
+	}))
+	defer node.Close()
+	client, err := utils.DialEthClient(node.URL)
+	require.NoError(t, err)
+
+	// the two deposits of the same tx, the relayed msg of another tx
+	deposits := []*orm.CrossMsg{
+		{MsgHash: "0xa1", Layer1Hash: depositTx.Hash().Hex(), MsgType: int(orm.Layer1Msg)},
+		{MsgHash: "0xa2", Layer1Hash: depositTx.Hash().Hex(), MsgType: int(orm.Layer1Msg)},
+	}
+	relayedMsgs := []*orm.RelayedMsg{{MsgHash: "0xb1", Layer1Hash: relayTx.Hash().Hex()}}
+	txs, err := fetchTxs(context.Background(), client, deposits, relayedMsgs)
+	require.NoError(t, err)
+	assert.Len(t, txs, 2)
+	// each tx is fetched once
+	assert.Equal(t, map[common.Hash]int{depositTx.Hash(): 1, relayTx.Hash(): 1}, calls)
+
+	updateL1OriginMethods(txs, deposits)
+	for _, deposit := range deposits {
+		if assert.NotNil(t, deposit.OriginTxNonce) {
+			assert.Equal(t, uint64(7), *deposit.OriginTxNonce)
+		}
+	}
+}
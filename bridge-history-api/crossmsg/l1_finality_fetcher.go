@@ -0,0 +1,76 @@
+package crossmsg
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gorm.io/gorm"
+
+	"bridge-history-api/orm"
+)
+
+// l1BlockTags the block numbers of the block tags polled by the L1FinalityFetcher
+var l1BlockTags = map[string]rpc.BlockNumber{
+	orm.L1BlockTagSafe:      rpc.SafeBlockNumber,
+	orm.L1BlockTagFinalized: rpc.FinalizedBlockNumber,
+}
+
+// L1FinalityFetcher polls the safe and the finalized blocks of the layer1 node, which follow the justified and the
+// finalized checkpoints of the beacon chain, and saves their numbers, the finality of the deposits is derived from them
+type L1FinalityFetcher struct {
+	ctx            context.Context
+	blockTimeInSec int
+	client         *ethclient.Client
+	blockTagOrm    *orm.L1BlockTag
+}
+
+// NewL1FinalityFetcher creates a new L1FinalityFetcher instance
+func NewL1FinalityFetcher(ctx context.Context, blockTimeInSec int, client *ethclient.Client, db *gorm.DB) *L1FinalityFetcher {
+	return &L1FinalityFetcher{
+		ctx:            ctx,
+		blockTimeInSec: blockTimeInSec,
+		client:         client,
+		blockTagOrm:    orm.NewL1BlockTag(db),
+	}
+}
+
+// Start the L1FinalityFetcher
+func (f *L1FinalityFetcher) Start() {
+	log.Info("L1FinalityFetcher Start")
+	f.fetch()
+	go func() {
+		tick := time.NewTicker(time.Duration(f.blockTimeInSec) * time.Second)
+		for {
+			select {
+			case <-f.ctx.Done():
+				tick.Stop()
+				return
+			case <-tick.C:
+				f.fetch()
+			}
+		}
+	}()
+}
+
+// Stop the L1FinalityFetcher and log the info
+func (f *L1FinalityFetcher) Stop() {
+	log.Info("L1FinalityFetcher Stop")
+}
+
+// fetch saves the block number of each block tag, the tags the node doesn't support, e.g. before the merge, are skipped
+func (f *L1FinalityFetcher) fetch() {
+	for tag, blockNumber := range l1BlockTags {
+		header, err := f.client.HeaderByNumber(f.ctx, big.NewInt(int64(blockNumber)))
+		if err != nil {
+			log.Error("Can not get the block of the tag", "tag", tag, "err", err)
+			continue
+		}
+		if err = f.blockTagOrm.UpdateL1BlockTagNumber(f.ctx, tag, header.Number.Uint64()); err != nil {
+			log.Error("Can not update the block number of the tag", "tag", tag, "err", err)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package crossmsg
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+
+	"bridge-history-api/config"
+)
+
+const (
+	// brokerClientName names the connections of the publisher to the message broker
+	brokerClientName = "bridge-history-api"
+	// natsReconnectWait is the wait between the attempts to reconnect the nats servers
+	natsReconnectWait = 2 * time.Second
+)
+
+// brokerMessage a message published to a topic of the message broker, the messages of the same key are kept in order.
+// id is unique per event, the brokers deduplicating the messages do it by id.
+type brokerMessage struct {
+	id    string
+	key   string
+	value []byte
+}
+
+// messageBroker publishes the messages to the topics of a message broker
+type messageBroker interface {
+	// Publish publishes the messages to the topic, they are all acknowledged by the broker once it returns nil
+	Publish(ctx context.Context, topic string, messages []*brokerMessage) error
+	// Close closes the connections to the broker
+	Close() error
+}
+
+// newMessageBroker returns the broker of the config, connected with the timeout
+func newMessageBroker(cfg *config.PublisherConfig, timeout time.Duration) (messageBroker, error) {
+	tlsConfig, err := brokerTLSConfig(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+	switch cfg.Broker {
+	case "kafka":
+		return newKafkaBroker(cfg, tlsConfig, timeout)
+	case "nats":
+		return newNATSBroker(cfg, tlsConfig, timeout)
+	default:
+		return nil, fmt.Errorf("unknown broker %q of the publisher, expected kafka or nats", cfg.Broker)
+	}
+}
+
+// brokerTLSConfig returns the TLS config of the connections to the broker, nil if cfg is nil
+func brokerTLSConfig(cfg *config.PublisherTLSConfig) (*tls.Config, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(filepath.Clean(cfg.CAFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the CAs of the broker: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate in the CA file %s of the broker", cfg.CAFile)
+		}
+	}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the client certificate of the broker: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// kafkaBroker publishes the messages as the records of the topics of a kafka cluster, the records are partitioned by
+// their keys and acknowledged by all the in sync replicas
+type kafkaBroker struct {
+	writer *kafka.Writer
+}
+
+// newKafkaBroker returns the broker publishing to the kafka cluster of the bootstrap brokers of the config, the
+// connections are dialed on the first publication and again after a failure
+func newKafkaBroker(cfg *config.PublisherConfig, tlsConfig *tls.Config, timeout time.Duration) (*kafkaBroker, error) {
+	var addrs []string
+	for _, addr := range strings.Split(cfg.URL, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, addr)
+		}
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New("no bootstrap broker of the kafka cluster, expected host:port in the url")
+	}
+	transport := &kafka.Transport{DialTimeout: timeout, ClientID: brokerClientName, TLS: tlsConfig}
+	if cfg.Username != "" {
+		mechanism, err := kafkaSASLMechanism(cfg.SASLMechanism, cfg.Username, cfg.Password)
+		if err != nil {
+			return nil, err
+		}
+		transport.SASL = mechanism
+	}
+	return &kafkaBroker{writer: &kafka.Writer{
+		Addr:         kafka.TCP(addrs...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		// the messages of a publication are sent at once, rather than once the batch timeout expires
+		BatchTimeout: time.Millisecond,
+		BatchSize:    defaultPublisherBatchSize,
+		ReadTimeout:  timeout,
+		WriteTimeout: timeout,
+		Transport:    transport,
+	}}, nil
+}
+
+// kafkaSASLMechanism returns the SASL mechanism of the name authenticating the username
+func kafkaSASLMechanism(name, username, password string) (sasl.Mechanism, error) {
+	switch strings.ToUpper(name) {
+	case "", "PLAIN":
+		return plain.Mechanism{Username: username, Password: password}, nil
+	case "SCRAM-SHA-256":
+		return scram.Mechanism(scram.SHA256, username, password)
+	case "SCRAM-SHA-512":
+		return scram.Mechanism(scram.SHA512, username, password)
+	default:
+		return nil, fmt.Errorf("unknown SASL mechanism %q of the kafka cluster", name)
+	}
+}
+
+// Publish implements messageBroker
+func (k *kafkaBroker) Publish(ctx context.Context, topic string, messages []*brokerMessage) error {
+	records := make([]kafka.Message, 0, len(messages))
+	for _, message := range messages {
+		records = append(records, kafka.Message{
+			Topic:   topic,
+			Key:     []byte(message.key),
+			Value:   message.value,
+			Headers: []kafka.Header{{Key: "id", Value: []byte(message.id)}},
+		})
+	}
+	return k.writer.WriteMessages(ctx, records...)
+}
+
+// Close implements messageBroker
+func (k *kafkaBroker) Close() error {
+	return k.writer.Close()
+}
+
+// natsBroker publishes the messages to the subjects of nats servers, to their JetStream streams if enabled. The
+// connection is reconnected forever, the publications fail while it is down rather than being buffered.
+type natsBroker struct {
+	conn *nats.Conn
+	js   nats.JetStreamContext
+}
+
+// newNATSBroker returns the broker publishing to the nats servers of the urls of the config, the connection is
+// retried in the background if the servers are down
+func newNATSBroker(cfg *config.PublisherConfig, tlsConfig *tls.Config, timeout time.Duration) (*natsBroker, error) {
+	options := []nats.Option{
+		nats.Name(brokerClientName),
+		nats.Timeout(timeout),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(natsReconnectWait),
+		nats.ReconnectBufSize(-1),
+	}
+	if tlsConfig != nil {
+		options = append(options, nats.Secure(tlsConfig))
+	}
+	switch {
+	case cfg.CredentialsFile != "":
+		options = append(options, nats.UserCredentials(cfg.CredentialsFile))
+	case cfg.Token != "":
+		options = append(options, nats.Token(cfg.Token))
+	case cfg.Username != "":
+		options = append(options, nats.UserInfo(cfg.Username, cfg.Password))
+	}
+	conn, err := nats.Connect(cfg.URL, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect the nats servers: %w", err)
+	}
+	broker := &natsBroker{conn: conn}
+	if cfg.JetStream {
+		if broker.js, err = conn.JetStream(); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return broker, nil
+}
+
+// Publish implements messageBroker
+func (n *natsBroker) Publish(ctx context.Context, topic string, messages []*brokerMessage) error {
+	if n.js != nil {
+		return n.publishJetStream(ctx, topic, messages)
+	}
+	for _, message := range messages {
+		if err := n.conn.Publish(topic, message.value); err != nil {
+			return err
+		}
+	}
+	// the messages are processed by the server once the PONG of the flush is received
+	return n.conn.FlushWithContext(ctx)
+}
+
+// publishJetStream publishes the messages to the stream of the topic at once and waits for all their acks, the
+// stream deduplicates the messages of the same id
+func (n *natsBroker) publishJetStream(ctx context.Context, topic string, messages []*brokerMessage) error {
+	futures := make([]nats.PubAckFuture, 0, len(messages))
+	for _, message := range messages {
+		msg := nats.NewMsg(topic)
+		msg.Data = message.value
+		msg.Header.Set(nats.MsgIdHdr, message.id)
+		future, err := n.js.PublishMsgAsync(msg)
+		if err != nil {
+			return err
+		}
+		futures = append(futures, future)
+	}
+	for _, future := range futures {
+		select {
+		case <-future.Ok():
+		case err := <-future.Err():
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Close implements messageBroker
+func (n *natsBroker) Close() error {
+	n.conn.Close()
+	return nil
+}
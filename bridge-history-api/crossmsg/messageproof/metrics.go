@@ -0,0 +1,25 @@
+package messageproof
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// the failures of the proof updates, nil until RegisterMetrics is called so that nothing is recorded
+var proofFailures *prometheus.CounterVec
+
+// RegisterMetrics registers the metrics of the proof updater on reg, it's called before the updater starts
+func RegisterMetrics(reg prometheus.Registerer) {
+	proofFailures = promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+		Name: "bridge_history_api_msg_proof_failures_total",
+		Help: "The total number of the failures generating and saving the claim proofs of the withdrawals by step",
+	}, []string{"step"})
+}
+
+// observeFailure records a failure of the step of the proof update
+func observeFailure(step string) {
+	if proofFailures == nil {
+		return
+	}
+	proofFailures.WithLabelValues(step).Inc()
+}
@@ -9,7 +9,9 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"gorm.io/gorm"
 
+	"bridge-history-api/internal/logic"
 	"bridge-history-api/orm"
+	"bridge-history-api/utils"
 )
 
 // MsgProofUpdater is used to update message proof in db
@@ -18,17 +20,25 @@ type MsgProofUpdater struct {
 	db           *gorm.DB
 	l2SentMsgOrm *orm.L2SentMsg
 	rollupOrm    *orm.RollupBatch
-	withdrawTrie *WithdrawTrie
+	withdrawTrie *utils.WithdrawTrie
+	cache        logic.Cache
+	events       logic.ClaimableEvents
+	webhooks     *logic.WebhookNotifier
 }
 
-// NewMsgProofUpdater new MsgProofUpdater instance
-func NewMsgProofUpdater(ctx context.Context, confirmations uint64, startBlock uint64, db *gorm.DB) *MsgProofUpdater {
+// NewMsgProofUpdater new MsgProofUpdater instance, the cached histories of the messages are invalidated in cache and
+// the claimable events of the messages are published to events and queued to webhooks once their proofs are updated,
+// cache, events and webhooks can be nil
+func NewMsgProofUpdater(ctx context.Context, confirmations uint64, startBlock uint64, db *gorm.DB, cache logic.Cache, events logic.ClaimableEvents, webhooks *logic.WebhookNotifier) *MsgProofUpdater {
 	return &MsgProofUpdater{
 		ctx:          ctx,
 		db:           db,
 		l2SentMsgOrm: orm.NewL2SentMsg(db),
 		rollupOrm:    orm.NewRollupBatch(db),
-		withdrawTrie: NewWithdrawTrie(),
+		withdrawTrie: utils.NewWithdrawTrie(),
+		cache:        cache,
+		events:       events,
+		webhooks:     webhooks,
 	}
 }
 
@@ -74,6 +84,7 @@ func (m *MsgProofUpdater) Start() {
 					msgs, proofs, err := m.appendL2Messages(batch.StartBlockNumber, batch.EndBlockNumber)
 					if err != nil {
 						log.Error("MsgProofUpdater: can not append l2messages", "startBlockNumber", batch.StartBlockNumber, "endBlockNumber", batch.EndBlockNumber, "err", err)
+						observeFailure("append_messages")
 						break
 					}
 					// here we update batch withdraw root
@@ -81,12 +92,14 @@ func (m *MsgProofUpdater) Start() {
 					if err != nil {
 						// if failed better restart the binary
 						log.Error("MsgProofUpdater: can not update batch withdraw root", "err", err)
+						observeFailure("update_withdraw_root")
 						break
 					}
 					err = m.updateMsgProof(msgs, proofs, batch.BatchIndex)
 					if err != nil {
 						// if failed better restart the binary
 						log.Error("MsgProofUpdater: can not update msg proof", "err", err)
+						observeFailure("update_proofs")
 						break
 					}
 				}
@@ -111,6 +124,7 @@ func (m *MsgProofUpdater) initialize(ctx context.Context) {
 			err := m.initializeWithdrawTrie()
 			if err != nil {
 				log.Error("can not initialize withdraw trie", "err", err)
+				observeFailure("initialize")
 				// give it some time to retry
 				time.Sleep(10 * time.Second)
 				continue
@@ -218,6 +232,16 @@ func (m *MsgProofUpdater) updateMsgProof(msgs []*orm.L2SentMsg, proofs [][]byte,
 	if err != nil {
 		return err
 	}
+	// the messages are claimable once the proofs are saved, the histories cached without the proofs are stale
+	if err = logic.InvalidateHistoryCache(m.ctx, m.cache, m.db, nil, nil, msgs); err != nil {
+		log.Error("MsgProofUpdater: can not invalidate the cached histories", "err", err, "batchIndex", batchIndex)
+	}
+	if err = logic.PublishClaimableEvents(m.ctx, m.events, msgs); err != nil {
+		log.Error("MsgProofUpdater: can not publish the claimable events", "err", err, "batchIndex", batchIndex)
+	}
+	if err = m.webhooks.NotifyClaimable(m.ctx, msgs); err != nil {
+		log.Error("MsgProofUpdater: can not queue the webhook deliveries", "err", err, "batchIndex", batchIndex)
+	}
 	return nil
 }
 
@@ -0,0 +1,101 @@
+package crossmsg
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"bridge-history-api/orm"
+)
+
+// the metrics of the fetchers, nil until RegisterMetrics is called so that nothing is recorded
+var metrics *fetcherMetrics
+
+// fetcherMetrics instruments the fetchers, a nil fetcherMetrics records nothing
+type fetcherMetrics struct {
+	indexedEvents *prometheus.CounterVec
+	lag           *prometheus.GaugeVec
+	archivedMsgs  *prometheus.CounterVec
+}
+
+// RegisterMetrics registers the metrics of the fetchers on reg, it's called before the fetchers start
+func RegisterMetrics(reg prometheus.Registerer) {
+	metrics = &fetcherMetrics{
+		indexedEvents: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "bridge_history_api_fetcher_indexed_events_total",
+			Help: "The total number of the events indexed by the fetchers by layer and type",
+		}, []string{"layer", "type"}),
+		lag: promauto.With(reg).NewGaugeVec(prometheus.GaugeOpts{
+			Name: "bridge_history_api_fetcher_lag_blocks",
+			Help: "The number of the blocks the processed height of the fetcher is behind the chain head",
+		}, []string{"fetcher"}),
+		archivedMsgs: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "bridge_history_api_fetcher_archived_msgs_total",
+			Help: "The total number of the relayed cross messages moved into the archive tables by layer",
+		}, []string{"layer"}),
+	}
+}
+
+// layerLabel returns the label of the layer of the metrics
+func layerLabel(layer orm.MsgType) string {
+	if layer == orm.Layer1Msg {
+		return "l1"
+	}
+	return "l2"
+}
+
+// observeEvents records the events indexed on the layer
+func (m *fetcherMetrics) observeEvents(layer orm.MsgType, events *savedEvents) {
+	if m == nil {
+		return
+	}
+	m.indexedEvents.WithLabelValues(layerLabel(layer), string(BackfillCrossMsgs)).Add(float64(len(events.crossMsgs)))
+	m.indexedEvents.WithLabelValues(layerLabel(layer), string(BackfillRelayedMsgs)).Add(float64(len(events.relayedMsgs)))
+	m.indexedEvents.WithLabelValues(layerLabel(layer), string(BackfillFailedRelayedMsgs)).Add(float64(len(events.failedRelayedMsgs)))
+	if layer == orm.Layer1Msg {
+		m.indexedEvents.WithLabelValues(layerLabel(layer), string(BackfillReplayedMsgs)).Add(float64(len(events.replayedMsgs)))
+		m.indexedEvents.WithLabelValues(layerLabel(layer), string(BackfillDroppedMsgs)).Add(float64(len(events.droppedMsgs)))
+		m.indexedEvents.WithLabelValues(layerLabel(layer), string(BackfillRefundedMsgs)).Add(float64(len(events.refundedMsgs)))
+	}
+	if layer == orm.Layer2Msg {
+		m.indexedEvents.WithLabelValues(layerLabel(layer), string(BackfillL2SentMsgs)).Add(float64(len(events.l2SentMsgs)))
+	}
+}
+
+// observeBatchEvents records the batch events indexed on L1
+func (m *fetcherMetrics) observeBatchEvents(events *batchEvents) {
+	if m == nil {
+		return
+	}
+	m.indexedEvents.WithLabelValues(layerLabel(orm.Layer1Msg), string(BackfillBatches)).Add(float64(len(events.rollupBatches)))
+	m.indexedEvents.WithLabelValues(layerLabel(orm.Layer1Msg), "batch_finalizations").Add(float64(len(events.finalizedBatches)))
+	m.indexedEvents.WithLabelValues(layerLabel(orm.Layer1Msg), "batch_reverts").Add(float64(len(events.revertedBatches)))
+}
+
+// observeLag records how many blocks the processed height of the fetcher is behind the head of the chain of client
+func (m *fetcherMetrics) observeLag(ctx context.Context, client *ethclient.Client, fetcher string, processedHeight uint64) {
+	if m == nil {
+		return
+	}
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		log.Warn("failed to get the chain head of the fetcher lag", "fetcher", fetcher, "err", err)
+		return
+	}
+	var lag uint64
+	if head > processedHeight {
+		lag = head - processedHeight
+	}
+	m.lag.WithLabelValues(fetcher).Set(float64(lag))
+}
+
+// observeArchived records the cross messages of the layer archived
+func (m *fetcherMetrics) observeArchived(layer orm.MsgType, count int) {
+	if m == nil {
+		return
+	}
+	m.archivedMsgs.WithLabelValues(layerLabel(layer)).Add(float64(count))
+}
@@ -1,15 +1,18 @@
 package crossmsg_test
 
 import (
+	"context"
 	"crypto/rand"
 	"math/big"
 	"testing"
 
+	geth "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/assert"
 
 	"bridge-history-api/crossmsg"
+	"bridge-history-api/orm"
 )
 
 func TestMergeIntoList(t *testing.T) {
@@ -61,5 +64,68 @@ func generateHeaders(amount int) ([]*types.Header, error) {
 	return headers, nil
 }
 
-// TODO: add more test cases
-// func TestReorg(t *testing.T)
+// chainReader is the HeaderReader of the headers of a chain indexed by number
+type chainReader []*types.Header
+
+func (c chainReader) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	if number.Uint64() >= uint64(len(c)) {
+		return nil, geth.NotFound
+	}
+	return c[number.Uint64()], nil
+}
+
+func TestFindCommonAncestor(t *testing.T) {
+	headers, err := generateHeaders(10)
+	assert.NoError(t, err)
+	indexed := func(heights ...uint64) []*orm.IndexedBlock {
+		blocks := make([]*orm.IndexedBlock, len(heights))
+		for i, height := range heights {
+			blocks[i] = &orm.IndexedBlock{Layer: int(orm.Layer1Msg), Height: height, BlockHash: headers[height].Hash().Hex()}
+		}
+		return blocks
+	}
+
+	ancestor, diverged, err := crossmsg.FindCommonAncestor(context.Background(), chainReader(headers), indexed(9, 5, 2))
+	assert.NoError(t, err)
+	assert.False(t, diverged)
+	assert.Equal(t, uint64(9), ancestor)
+
+	// the chain is reorged from the block 4 and shorter than the latest indexed block
+	reorged, err := generateHeaders(8)
+	assert.NoError(t, err)
+	reorgedChain := append(append([]*types.Header{}, headers[:4]...), reorged[4:]...)
+	ancestor, diverged, err = crossmsg.FindCommonAncestor(context.Background(), chainReader(reorgedChain), indexed(9, 5, 2))
+	assert.NoError(t, err)
+	assert.True(t, diverged)
+	assert.Equal(t, uint64(2), ancestor)
+
+	// none of the indexed blocks is on chain anymore
+	ancestor, diverged, err = crossmsg.FindCommonAncestor(context.Background(), chainReader(reorgedChain), indexed(9, 5))
+	assert.NoError(t, err)
+	assert.True(t, diverged)
+	assert.Equal(t, uint64(4), ancestor)
+
+	_, diverged, err = crossmsg.FindCommonAncestor(context.Background(), chainReader(headers), nil)
+	assert.NoError(t, err)
+	assert.False(t, diverged)
+}
+
+func TestParseBackfillKinds(t *testing.T) {
+	kinds, err := crossmsg.ParseBackfillKinds(orm.Layer1Msg, "")
+	assert.NoError(t, err)
+	assert.Len(t, kinds, 7)
+	assert.True(t, kinds[crossmsg.BackfillBatches])
+	assert.True(t, kinds[crossmsg.BackfillDroppedMsgs])
+	assert.True(t, kinds[crossmsg.BackfillRefundedMsgs])
+	assert.False(t, kinds[crossmsg.BackfillL2SentMsgs])
+
+	kinds, err = crossmsg.ParseBackfillKinds(orm.Layer2Msg, "cross_msgs, l2_sent_msgs")
+	assert.NoError(t, err)
+	assert.Equal(t, map[crossmsg.BackfillKind]bool{crossmsg.BackfillCrossMsgs: true, crossmsg.BackfillL2SentMsgs: true}, kinds)
+
+	// the batches are committed on L1 only
+	_, err = crossmsg.ParseBackfillKinds(orm.Layer2Msg, "batches")
+	assert.Error(t, err)
+	_, err = crossmsg.ParseBackfillKinds(orm.Layer1Msg, "deposits")
+	assert.Error(t, err)
+}
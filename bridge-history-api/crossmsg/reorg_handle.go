@@ -2,18 +2,27 @@ package crossmsg
 
 import (
 	"context"
+	"errors"
+	"math/big"
 
+	geth "github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 	"gorm.io/gorm"
 
+	"bridge-history-api/internal/logic"
 	"bridge-history-api/orm"
 )
 
 // ReorgHandling handles reorg function type
 type ReorgHandling func(ctx context.Context, reorgHeight uint64, db *gorm.DB) error
 
+// HeaderReader reads the canonical headers of a chain by number
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
 func reverseArray(arr []*types.Header) []*types.Header {
 	for i := 0; i < len(arr)/2; i++ {
 		j := len(arr) - i - 1
@@ -60,11 +69,92 @@ func BackwardFindReorgBlock(ctx context.Context, headers []*types.Header, client
 	return -1, false, nil
 }
 
+// FindCommonAncestor compares the indexed blocks, the highest first, with the canonical chain of reader. It returns
+// the height of the highest indexed block still on chain and whether the blocks above it diverged. The height below
+// the lowest block is returned if none of them is on chain anymore.
+func FindCommonAncestor(ctx context.Context, reader HeaderReader, blocks []*orm.IndexedBlock) (uint64, bool, error) {
+	for i, block := range blocks {
+		header, err := reader.HeaderByNumber(ctx, new(big.Int).SetUint64(block.Height))
+		if err != nil && !errors.Is(err, geth.NotFound) {
+			return 0, false, err
+		}
+		// the chain is shorter than the indexed block after the reorg if not found
+		if err == nil && header.Hash().Hex() == block.BlockHash {
+			return block.Height, i > 0, nil
+		}
+	}
+	if len(blocks) == 0 {
+		return 0, false, nil
+	}
+	lowest := blocks[len(blocks)-1].Height
+	if lowest == 0 {
+		return 0, true, nil
+	}
+	return lowest - 1, true, nil
+}
+
+// indexedBlocks returns the blocks whose hashes are tracked once the logs of the range ending at the block to are
+// indexed, the blocks of the logs and the last block of the range
+func indexedBlocks(ctx context.Context, client *ethclient.Client, layer orm.MsgType, logs []types.Log, to int64) ([]*orm.IndexedBlock, error) {
+	header, err := client.HeaderByNumber(ctx, big.NewInt(to))
+	if err != nil {
+		return nil, err
+	}
+	blocks := []*orm.IndexedBlock{{Layer: int(layer), Height: uint64(to), BlockHash: header.Hash().Hex()}}
+	heights := map[uint64]struct{}{uint64(to): {}}
+	for _, vlog := range logs {
+		if _, found := heights[vlog.BlockNumber]; found {
+			continue
+		}
+		heights[vlog.BlockNumber] = struct{}{}
+		blocks = append(blocks, &orm.IndexedBlock{Layer: int(layer), Height: vlog.BlockNumber, BlockHash: vlog.BlockHash.Hex()})
+	}
+	return blocks, nil
+}
+
 // L1ReorgHandling handles l1 reorg
 func L1ReorgHandling(ctx context.Context, reorgHeight uint64, db *gorm.DB) error {
+	return l1ReorgHandling(ctx, reorgHeight, db, nil)
+}
+
+// L1ReorgHandlingWithHooks returns L1ReorgHandling invalidating the cached histories of the messages deleted, and of
+// the withdrawals of the batches committed or finalized after the height, once the reorg is handled
+func L1ReorgHandlingWithHooks(cache logic.Cache) ReorgHandling {
+	if cache == nil {
+		return L1ReorgHandling
+	}
+	return func(ctx context.Context, reorgHeight uint64, db *gorm.DB) error {
+		return l1ReorgHandling(ctx, reorgHeight, db, cache)
+	}
+}
+
+// l1ReorgHandling handles l1 reorg, the cached histories are invalidated if cache is not nil
+func l1ReorgHandling(ctx context.Context, reorgHeight uint64, db *gorm.DB, cache logic.Cache) error {
+	var reorged reorgedMsgs
 	l1CrossMsgOrm := orm.NewCrossMsg(db)
 	relayedOrm := orm.NewRelayedMsg(db)
+	failedRelayedOrm := orm.NewFailedRelayedMsg(db)
+	replayedOrm := orm.NewReplayedMsg(db)
+	droppedOrm := orm.NewDroppedMsg(db)
+	refundedOrm := orm.NewRefundedMsg(db)
+	rollupBatchOrm := orm.NewRollupBatch(db)
+	indexedBlockOrm := orm.NewIndexedBlock(db)
+	checkpointOrm := orm.NewEventCheckpoint(db)
+	l2SentMsgOrm := orm.NewL2SentMsg(db)
 	err := db.Transaction(func(tx *gorm.DB) error {
+		if cache != nil {
+			if err := reorged.get(ctx, db, orm.Layer1Msg, reorgHeight, tx); err != nil {
+				log.Error("get l1 msgs from height", "height", reorgHeight, "err", err)
+				return err
+			}
+			// the withdrawals are claimable again or not anymore once their batches are reorged
+			l2SentMsgs, err := l2SentMsgOrm.GetL2SentMsgsInBatchesAfterHeight(ctx, reorgHeight, tx)
+			if err != nil {
+				log.Error("get l2 sent msgs in the batches from height", "height", reorgHeight, "err", err)
+				return err
+			}
+			reorged.l2SentMsgs = l2SentMsgs
+		}
 		if err := l1CrossMsgOrm.DeleteL1CrossMsgAfterHeight(ctx, reorgHeight, tx); err != nil {
 			log.Error("delete l1 cross msg from height", "height", reorgHeight, "err", err)
 			return err
@@ -73,20 +163,89 @@ func L1ReorgHandling(ctx context.Context, reorgHeight uint64, db *gorm.DB) error
 			log.Error("delete l1 relayed msg from height", "height", reorgHeight, "err", err)
 			return err
 		}
+		if err := failedRelayedOrm.DeleteL1FailedRelayedHashAfterHeight(ctx, reorgHeight, tx); err != nil {
+			log.Error("delete l1 failed relayed msg from height", "height", reorgHeight, "err", err)
+			return err
+		}
+		if err := replayedOrm.DeleteReplayedMsgAfterHeight(ctx, reorgHeight, tx); err != nil {
+			log.Error("delete l1 replayed msg from height", "height", reorgHeight, "err", err)
+			return err
+		}
+		if err := droppedOrm.DeleteDroppedMsgAfterHeight(ctx, reorgHeight, tx); err != nil {
+			log.Error("delete l1 dropped msg from height", "height", reorgHeight, "err", err)
+			return err
+		}
+		if err := refundedOrm.DeleteRefundedMsgAfterHeight(ctx, reorgHeight, tx); err != nil {
+			log.Error("delete l1 refunded msg from height", "height", reorgHeight, "err", err)
+			return err
+		}
+		// the claim infos of the withdrawals are built from the batches, so the commits and the finalizations
+		// reorged out are dropped too
+		if err := rollupBatchOrm.DeleteRollupBatchesCommittedAfterHeight(ctx, reorgHeight, tx); err != nil {
+			log.Error("delete rollup batches committed from height", "height", reorgHeight, "err", err)
+			return err
+		}
+		if err := rollupBatchOrm.ResetRollupBatchFinalizationAfterHeight(ctx, reorgHeight, tx); err != nil {
+			log.Error("reset rollup batch finalizations from height", "height", reorgHeight, "err", err)
+			return err
+		}
+		if err := indexedBlockOrm.DeleteIndexedBlocksAfterHeight(ctx, orm.Layer1Msg, reorgHeight, tx); err != nil {
+			log.Error("delete l1 indexed blocks from height", "height", reorgHeight, "err", err)
+			return err
+		}
+		// the events after the height are fetched again from the checkpoints
+		if err := checkpointOrm.RollbackEventCheckpoints(ctx, orm.Layer1Msg, reorgHeight, tx); err != nil {
+			log.Error("roll back l1 event checkpoints to height", "height", reorgHeight, "err", err)
+			return err
+		}
 		return nil
 	})
 	if err != nil {
 		log.Crit("l1 reorg handling failed", "err", err)
+		return err
 	}
-	return err
+	reorged.invalidate(ctx, db, cache)
+	return nil
 }
 
 // L2ReorgHandling handles l2 reorg
 func L2ReorgHandling(ctx context.Context, reorgHeight uint64, db *gorm.DB) error {
+	return l2ReorgHandling(ctx, reorgHeight, db, nil)
+}
+
+// L2ReorgHandlingWithHooks returns L2ReorgHandling invalidating the cached histories of the messages deleted once the
+// reorg is handled
+func L2ReorgHandlingWithHooks(cache logic.Cache) ReorgHandling {
+	if cache == nil {
+		return L2ReorgHandling
+	}
+	return func(ctx context.Context, reorgHeight uint64, db *gorm.DB) error {
+		return l2ReorgHandling(ctx, reorgHeight, db, cache)
+	}
+}
+
+// l2ReorgHandling handles l2 reorg, the cached histories are invalidated if cache is not nil
+func l2ReorgHandling(ctx context.Context, reorgHeight uint64, db *gorm.DB, cache logic.Cache) error {
+	var reorged reorgedMsgs
 	l2CrossMsgOrm := orm.NewCrossMsg(db)
 	relayedOrm := orm.NewRelayedMsg(db)
 	l2SentMsgOrm := orm.NewL2SentMsg(db)
+	failedRelayedOrm := orm.NewFailedRelayedMsg(db)
+	indexedBlockOrm := orm.NewIndexedBlock(db)
+	checkpointOrm := orm.NewEventCheckpoint(db)
 	err := db.Transaction(func(tx *gorm.DB) error {
+		if cache != nil {
+			if err := reorged.get(ctx, db, orm.Layer2Msg, reorgHeight, tx); err != nil {
+				log.Error("get l2 msgs from height", "height", reorgHeight, "err", err)
+				return err
+			}
+			l2SentMsgs, err := l2SentMsgOrm.GetL2SentMsgsAfterHeight(ctx, reorgHeight, tx)
+			if err != nil {
+				log.Error("get l2 sent msgs from height", "height", reorgHeight, "err", err)
+				return err
+			}
+			reorged.l2SentMsgs = l2SentMsgs
+		}
 		if err := l2CrossMsgOrm.DeleteL2CrossMsgFromHeight(ctx, reorgHeight, tx); err != nil {
 			log.Error("delete l2 cross msg from height", "height", reorgHeight, "err", err)
 			return err
@@ -99,10 +258,49 @@ func L2ReorgHandling(ctx context.Context, reorgHeight uint64, db *gorm.DB) error
 			log.Error("delete l2 sent msg from height", "height", reorgHeight, "err", err)
 			return err
 		}
+		if err := failedRelayedOrm.DeleteL2FailedRelayedHashAfterHeight(ctx, reorgHeight, tx); err != nil {
+			log.Error("delete l2 failed relayed msg from height", "height", reorgHeight, "err", err)
+			return err
+		}
+		if err := indexedBlockOrm.DeleteIndexedBlocksAfterHeight(ctx, orm.Layer2Msg, reorgHeight, tx); err != nil {
+			log.Error("delete l2 indexed blocks from height", "height", reorgHeight, "err", err)
+			return err
+		}
+		// the events after the height are fetched again from the checkpoints
+		if err := checkpointOrm.RollbackEventCheckpoints(ctx, orm.Layer2Msg, reorgHeight, tx); err != nil {
+			log.Error("roll back l2 event checkpoints to height", "height", reorgHeight, "err", err)
+			return err
+		}
 		return nil
 	})
 	if err != nil {
 		log.Crit("l2 reorg handling failed", "err", err)
+		return err
 	}
+	reorged.invalidate(ctx, db, cache)
+	return nil
+}
+
+// reorgedMsgs the messages a reorg deletes or changes, read before they are deleted
+type reorgedMsgs struct {
+	crossMsgs   []*orm.CrossMsg
+	relayedMsgs []*orm.RelayedMsg
+	l2SentMsgs  []*orm.L2SentMsg
+}
+
+// get reads the cross msgs and the relayed msgs of the layer after the height
+func (r *reorgedMsgs) get(ctx context.Context, db *gorm.DB, layer orm.MsgType, height uint64, tx *gorm.DB) error {
+	var err error
+	if r.crossMsgs, err = orm.NewCrossMsg(db).GetCrossMsgsAfterHeight(ctx, layer, height, tx); err != nil {
+		return err
+	}
+	r.relayedMsgs, err = orm.NewRelayedMsg(db).GetRelayedMsgsAfterHeight(ctx, layer, height, tx)
 	return err
 }
+
+// invalidate invalidates the cached histories of the messages, the failures are logged since the reorg is handled
+func (r *reorgedMsgs) invalidate(ctx context.Context, db *gorm.DB, cache logic.Cache) {
+	if err := logic.InvalidateHistoryCache(ctx, cache, db, r.crossMsgs, r.relayedMsgs, r.l2SentMsgs); err != nil {
+		log.Error("failed to invalidate the cached histories of the reorged msgs", "err", err)
+	}
+}
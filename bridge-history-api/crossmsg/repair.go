@@ -0,0 +1,169 @@
+package crossmsg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	geth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"bridge-history-api/config"
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/orm"
+	"bridge-history-api/utils"
+)
+
+// repairLayer the node, the contracts and the custom gateways the events of a layer are fetched again with
+type repairLayer struct {
+	client   *ethclient.Client
+	addrList []common.Address
+	gateways *utils.CustomGateways
+}
+
+// Repairer fetches the events of single txs and the enrichments of single messages again from the nodes, for the
+// corrections of the operators. It implements logic.MsgRepairer.
+type Repairer struct {
+	db     *gorm.DB
+	cache  logic.Cache
+	layers map[orm.MsgType]*repairLayer
+}
+
+// NewRepairer returns the Repairer of the network of cfg, the layers without an endpoint are not repaired. The cached
+// histories of the messages repaired are invalidated in cache, may be nil.
+func NewRepairer(cfg *config.Config, db *gorm.DB, cache logic.Cache) (*Repairer, error) {
+	r := &Repairer{db: db, cache: cache, layers: make(map[orm.MsgType]*repairLayer)}
+	for layer, layerCfg := range map[orm.MsgType]*config.LayerConfig{orm.Layer1Msg: cfg.L1, orm.Layer2Msg: cfg.L2} {
+		if layerCfg == nil || layerCfg.Endpoint == "" {
+			continue
+		}
+		gateways, err := utils.NewCustomGateways(layerCfg.CustomGateways)
+		if err != nil {
+			return nil, err
+		}
+		client, err := utils.DialEthClient(layerCfg.Endpoint)
+		if err != nil {
+			return nil, err
+		}
+		addrList := L1Addresses(cfg)
+		if layer == orm.Layer2Msg {
+			addrList = L2Addresses(cfg)
+		}
+		r.layers[layer] = &repairLayer{client: client, addrList: addrList, gateways: gateways}
+	}
+	return r, nil
+}
+
+// layer returns the node of the layer, an invalid parameter if the layer has no endpoint
+func (r *Repairer) layer(layer orm.MsgType) (*repairLayer, error) {
+	repair, found := r.layers[layer]
+	if !found {
+		return nil, fmt.Errorf("%w: no node of layer %d", logic.ErrInvalidParameter, layer)
+	}
+	return repair, nil
+}
+
+// RefetchTx fetches the events of the block of the tx again and replaces the cross messages sent by the tx in one
+// transaction, the other events of the block missing are added and the ones saved already are kept. The number of
+// the cross messages of the tx saved is returned.
+func (r *Repairer) RefetchTx(ctx context.Context, layer orm.MsgType, txHash common.Hash) (int64, error) {
+	repair, err := r.layer(layer)
+	if err != nil {
+		return 0, err
+	}
+	receipt, err := repair.client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		if errors.Is(err, geth.NotFound) {
+			return 0, fmt.Errorf("%w: no tx %s on layer %d", logic.ErrNotFound, txHash.Hex(), layer)
+		}
+		return 0, err
+	}
+	height := receipt.BlockNumber.Int64()
+	var events *savedEvents
+	if layer == orm.Layer1Msg {
+		events, err = l1FetchEvents(ctx, repair.client, height, height, repair.addrList, repair.gateways)
+	} else {
+		events, err = l2FetchEvents(ctx, repair.client, height, height, repair.addrList, repair.gateways)
+	}
+	if err != nil {
+		return 0, err
+	}
+	// a single block isn't a range of the checkpoints unless adjacent, the checkpoints are extended by the fetchers
+	events.checkpoints = nil
+
+	var saved int64
+	for _, crossMsg := range events.crossMsgs {
+		if crossMsg.Layer1Hash == txHash.Hex() || crossMsg.Layer2Hash == txHash.Hex() {
+			saved++
+		}
+	}
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if _, txErr := orm.NewCrossMsg(tx).DeleteCrossMsgsByTxHash(ctx, layer, txHash.Hex(), tx); txErr != nil {
+			return txErr
+		}
+		if layer == orm.Layer1Msg {
+			return saveL1Events(ctx, tx, events, nil)
+		}
+		return saveL2Events(ctx, tx, events, nil)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err = logic.InvalidateHistoryCache(ctx, r.cache, r.db, events.crossMsgs, events.relayedMsgs, events.l2SentMsgs); err != nil {
+		log.Error("RefetchTx: Failed to invalidate the cached histories", "err", err)
+	}
+	return saved, nil
+}
+
+// ReenrichCrossMsg reads the gas fee, the bridge fee, the block timestamp and, for the deposits, the origin method of
+// the cross message of the hash from its node again. The number of the messages updated is returned.
+func (r *Repairer) ReenrichCrossMsg(ctx context.Context, msgHash common.Hash) (int64, error) {
+	crossMsgOrm := orm.NewCrossMsg(r.db)
+	crossMsgs, err := crossMsgOrm.GetL1CrossMsgByMsgHashList(ctx, []string{msgHash.Hex()})
+	if err != nil {
+		return 0, err
+	}
+	if len(crossMsgs) == 0 {
+		if crossMsgs, err = crossMsgOrm.GetL2CrossMsgByMsgHashList(ctx, []string{msgHash.Hex()}); err != nil {
+			return 0, err
+		}
+	}
+	if len(crossMsgs) == 0 {
+		return 0, fmt.Errorf("%w: no cross message of hash %s", logic.ErrNotFound, msgHash.Hex())
+	}
+	layer := orm.MsgType(crossMsgs[0].MsgType)
+	repair, err := r.layer(layer)
+	if err != nil {
+		return 0, err
+	}
+	txs, err := fetchTxs(ctx, repair.client, crossMsgs, nil)
+	if err != nil {
+		return 0, err
+	}
+	if err = updateFees(ctx, repair.client, txs, crossMsgs); err != nil {
+		return 0, err
+	}
+	if layer == orm.Layer1Msg {
+		updateL1OriginMethods(txs, crossMsgs)
+	}
+	for _, crossMsg := range crossMsgs {
+		header, headerErr := repair.client.HeaderByNumber(ctx, new(big.Int).SetUint64(crossMsg.Height))
+		if headerErr != nil {
+			return 0, headerErr
+		}
+		timestamp := time.Unix(int64(header.Time), 0)
+		crossMsg.Timestamp = &timestamp
+	}
+	if err = crossMsgOrm.UpdateCrossMsgEnrichments(ctx, crossMsgs); err != nil {
+		return 0, err
+	}
+	if err = logic.InvalidateHistoryCache(ctx, r.cache, r.db, crossMsgs, nil, nil); err != nil {
+		log.Error("ReenrichCrossMsg: Failed to invalidate the cached histories", "err", err)
+	}
+	return int64(len(crossMsgs)), nil
+}
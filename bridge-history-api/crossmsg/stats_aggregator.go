@@ -0,0 +1,243 @@
+package crossmsg
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"bridge-history-api/orm"
+)
+
+const (
+	// statsAggregateInterval is the interval the new cross messages and relays are aggregated into the bridge stats
+	statsAggregateInterval = time.Minute
+	// statsAggregateBatchSize is the maximum number of the rows of a source table aggregated per transaction
+	statsAggregateBatchSize = 1000
+	// statsRelayWaitPeriod is how long a relay whose cross message isn't indexed, e.g. the layer1 fetcher lags behind,
+	// holds the aggregation of the relays back before being skipped, e.g. the message is sent by calling the
+	// messenger directly
+	statsRelayWaitPeriod = 24 * time.Hour
+)
+
+// the names of the cursors of the source tables of each direction
+var (
+	msgsStatsCursors   = map[orm.MsgType]string{orm.Layer1Msg: "deposits", orm.Layer2Msg: "withdrawals"}
+	relaysStatsCursors = map[orm.MsgType]string{orm.Layer1Msg: "deposit_relays", orm.Layer2Msg: "withdrawal_relays"}
+)
+
+// StatsAggregator maintains the daily bridge stats incrementally: the cross messages are added to the daily volumes
+// of the day they are sent and the relays to the daily finalizations of the day they are relayed, in id order once
+// their block timestamps are fetched. Each source table is read from a cursor saved along the aggregates, so that each
+// row is counted once. The rows removed by a reorg after being aggregated stay counted.
+type StatsAggregator struct {
+	ctx             context.Context
+	db              *gorm.DB
+	crossMsgOrm     *orm.CrossMsg
+	relayedMsgOrm   *orm.RelayedMsg
+	volumeOrm       *orm.BridgeDailyVolume
+	finalizationOrm *orm.BridgeDailyFinalization
+	cursorOrm       *orm.BridgeStatsCursor
+}
+
+// NewStatsAggregator creates a new StatsAggregator instance
+func NewStatsAggregator(ctx context.Context, db *gorm.DB) *StatsAggregator {
+	return &StatsAggregator{
+		ctx:             ctx,
+		db:              db,
+		crossMsgOrm:     orm.NewCrossMsg(db),
+		relayedMsgOrm:   orm.NewRelayedMsg(db),
+		volumeOrm:       orm.NewBridgeDailyVolume(db),
+		finalizationOrm: orm.NewBridgeDailyFinalization(db),
+		cursorOrm:       orm.NewBridgeStatsCursor(db),
+	}
+}
+
+// Start the StatsAggregator
+func (s *StatsAggregator) Start() {
+	log.Info("StatsAggregator Start")
+	s.aggregate()
+	go func() {
+		tick := time.NewTicker(statsAggregateInterval)
+		for {
+			select {
+			case <-s.ctx.Done():
+				tick.Stop()
+				return
+			case <-tick.C:
+				s.aggregate()
+			}
+		}
+	}()
+}
+
+// Stop the StatsAggregator
+func (s *StatsAggregator) Stop() {
+	log.Info("StatsAggregator Stop")
+}
+
+// aggregate catches the stats of both directions up with the source tables
+func (s *StatsAggregator) aggregate() {
+	for _, msgType := range []orm.MsgType{orm.Layer1Msg, orm.Layer2Msg} {
+		if err := s.aggregateMsgs(msgType); err != nil {
+			log.Error("failed to aggregate the cross messages into the daily volumes", "msg type", msgType, "err", err)
+		}
+		if err := s.aggregateRelays(msgType); err != nil {
+			log.Error("failed to aggregate the relays into the daily finalizations", "msg type", msgType, "err", err)
+		}
+	}
+}
+
+// aggregateMsgs adds the cross messages of the msg type after the cursor to the daily volumes, until the first one
+// whose block timestamp isn't fetched yet
+func (s *StatsAggregator) aggregateMsgs(msgType orm.MsgType) error {
+	cursor := msgsStatsCursors[msgType]
+	for s.ctx.Err() == nil {
+		lastID, err := s.cursorOrm.GetLastID(s.ctx, cursor)
+		if err != nil {
+			return err
+		}
+		msgs, err := s.crossMsgOrm.GetCrossMsgsAfterID(s.ctx, msgType, lastID, statsAggregateBatchSize)
+		if err != nil {
+			return err
+		}
+		volumes, aggregatedID := dailyVolumes(msgs)
+		if aggregatedID == 0 {
+			return nil
+		}
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			if err := s.volumeOrm.InsertBridgeDailyVolumes(s.ctx, volumes, tx); err != nil {
+				return err
+			}
+			return s.cursorOrm.UpdateLastID(s.ctx, cursor, aggregatedID, tx)
+		})
+		if err != nil {
+			return err
+		}
+		if len(msgs) < statsAggregateBatchSize || aggregatedID != msgs[len(msgs)-1].ID {
+			return nil
+		}
+	}
+	return s.ctx.Err()
+}
+
+// aggregateRelays adds the relays of the cross messages of the msg type after the cursor to the daily finalizations,
+// until the first one whose block timestamps aren't fetched yet
+func (s *StatsAggregator) aggregateRelays(msgType orm.MsgType) error {
+	cursor := relaysStatsCursors[msgType]
+	for s.ctx.Err() == nil {
+		lastID, err := s.cursorOrm.GetLastID(s.ctx, cursor)
+		if err != nil {
+			return err
+		}
+		relays, err := s.relayedMsgOrm.GetRelayTimingsAfterID(s.ctx, msgType, lastID, statsAggregateBatchSize)
+		if err != nil {
+			return err
+		}
+		finalizations, aggregatedID := dailyFinalizations(msgType, relays, time.Now())
+		if aggregatedID == 0 {
+			return nil
+		}
+		err = s.db.Transaction(func(tx *gorm.DB) error {
+			if err := s.finalizationOrm.InsertBridgeDailyFinalizations(s.ctx, finalizations, tx); err != nil {
+				return err
+			}
+			return s.cursorOrm.UpdateLastID(s.ctx, cursor, aggregatedID, tx)
+		})
+		if err != nil {
+			return err
+		}
+		if len(relays) < statsAggregateBatchSize || aggregatedID != relays[len(relays)-1].ID {
+			return nil
+		}
+	}
+	return s.ctx.Err()
+}
+
+// statsDay returns the UTC day of the block timestamp
+func statsDay(timestamp time.Time) time.Time {
+	return timestamp.UTC().Truncate(24 * time.Hour)
+}
+
+// dailyVolumes sums the cross messages ordered by id into the daily volumes of their day, direction and token, it
+// stops at the first message without block timestamp. The id of the last message summed is returned, 0 if none.
+// The messages without token addresses bridge ETH, and only the amounts of ETH and the ERC20 tokens are summed.
+func dailyVolumes(msgs []*orm.CrossMsg) ([]*orm.BridgeDailyVolume, uint64) {
+	type volumeKey struct {
+		day     time.Time
+		msgType int
+		asset   int
+		token   string
+	}
+	var volumes []*orm.BridgeDailyVolume
+	amounts := make(map[volumeKey]*big.Int)
+	indexes := make(map[volumeKey]int)
+	var aggregatedID uint64
+	for _, msg := range msgs {
+		if msg.Timestamp == nil {
+			break
+		}
+		aggregatedID = msg.ID
+		key := volumeKey{day: statsDay(*msg.Timestamp), msgType: msg.MsgType, asset: msg.Asset, token: msg.Layer1Token}
+		if key.token == "" || common.HexToAddress(key.token) == (common.Address{}) {
+			key.asset, key.token = int(orm.ETH), ""
+		}
+		index, exists := indexes[key]
+		if !exists {
+			index = len(volumes)
+			indexes[key] = index
+			amounts[key] = new(big.Int)
+			volumes = append(volumes, &orm.BridgeDailyVolume{Day: key.day, MsgType: key.msgType, Asset: key.asset, Token: key.token})
+		}
+		volumes[index].TxCount++
+		if orm.AssetType(key.asset) == orm.ETH || orm.AssetType(key.asset) == orm.ERC20 {
+			if amount, ok := new(big.Int).SetString(msg.Amount, 10); ok && amount.Sign() > 0 {
+				amounts[key].Add(amounts[key], amount)
+			}
+		}
+	}
+	for key, index := range indexes {
+		volumes[index].Volume = amounts[key].String()
+	}
+	return volumes, aggregatedID
+}
+
+// dailyFinalizations sums the relays ordered by id into the daily finalizations of the day of the relay, it stops at
+// the first relay whose block timestamps aren't both fetched. The relays whose cross message isn't indexed are
+// waited for statsRelayWaitPeriod, then skipped. The id of the last relay summed or skipped is returned, 0 if none.
+func dailyFinalizations(msgType orm.MsgType, relays []*orm.RelayTiming, now time.Time) ([]*orm.BridgeDailyFinalization, uint64) {
+	var finalizations []*orm.BridgeDailyFinalization
+	indexes := make(map[time.Time]int)
+	var aggregatedID uint64
+	for _, relay := range relays {
+		if relay.RelayTimestamp == nil {
+			break
+		}
+		if !relay.MsgFound {
+			if relay.CreatedAt == nil || now.Sub(*relay.CreatedAt) < statsRelayWaitPeriod {
+				break
+			}
+			aggregatedID = relay.ID
+			continue
+		}
+		if relay.MsgTimestamp == nil {
+			break
+		}
+		aggregatedID = relay.ID
+		day := statsDay(*relay.RelayTimestamp)
+		index, exists := indexes[day]
+		if !exists {
+			index = len(finalizations)
+			indexes[day] = index
+			finalizations = append(finalizations, &orm.BridgeDailyFinalization{Day: day, MsgType: int(msgType)})
+		}
+		finalizations[index].FinalizedCount++
+		if elapsed := relay.RelayTimestamp.Sub(*relay.MsgTimestamp); elapsed > 0 {
+			finalizations[index].TotalFinalizeSeconds += uint64(elapsed / time.Second)
+		}
+	}
+	return finalizations, aggregatedID
+}
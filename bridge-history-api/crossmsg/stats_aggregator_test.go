@@ -0,0 +1,67 @@
+package crossmsg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/orm"
+)
+
+func TestDailyVolumes(t *testing.T) {
+	usdc := "0x0000000000000000000000000000000000000Abc"
+	day1 := time.Date(2024, 1, 1, 23, 59, 0, 0, time.UTC)
+	day2 := day1.Add(time.Minute)
+	msgs := []*orm.CrossMsg{
+		{ID: 1, MsgType: int(orm.Layer1Msg), Asset: int(orm.ETH), Amount: "100", Timestamp: &day1},
+		{ID: 2, MsgType: int(orm.Layer1Msg), Asset: int(orm.ETH), Amount: "50", Timestamp: &day1},
+		{ID: 3, MsgType: int(orm.Layer1Msg), Asset: int(orm.ERC20), Layer1Token: usdc, Amount: "7", Timestamp: &day1},
+		// the next day in UTC
+		{ID: 4, MsgType: int(orm.Layer1Msg), Asset: int(orm.ETH), Amount: "1", Timestamp: &day2},
+		{ID: 5, MsgType: int(orm.Layer1Msg), Asset: int(orm.ERC721), Layer1Token: usdc, Amount: "0", Timestamp: &day2},
+		// not summed until its block timestamp is fetched, and neither are the next ones
+		{ID: 6, MsgType: int(orm.Layer1Msg), Asset: int(orm.ETH), Amount: "1"},
+		{ID: 7, MsgType: int(orm.Layer1Msg), Asset: int(orm.ETH), Amount: "1", Timestamp: &day2},
+	}
+	volumes, aggregatedID := dailyVolumes(msgs)
+	assert.Equal(t, uint64(5), aggregatedID)
+	assert.Equal(t, []*orm.BridgeDailyVolume{
+		{Day: statsDay(day1), MsgType: int(orm.Layer1Msg), Asset: int(orm.ETH), TxCount: 2, Volume: "150"},
+		{Day: statsDay(day1), MsgType: int(orm.Layer1Msg), Asset: int(orm.ERC20), Token: usdc, TxCount: 1, Volume: "7"},
+		{Day: statsDay(day2), MsgType: int(orm.Layer1Msg), Asset: int(orm.ETH), TxCount: 1, Volume: "1"},
+		{Day: statsDay(day2), MsgType: int(orm.Layer1Msg), Asset: int(orm.ERC721), Token: usdc, TxCount: 1, Volume: "0"},
+	}, volumes)
+
+	volumes, aggregatedID = dailyVolumes(msgs[5:])
+	assert.Empty(t, volumes)
+	assert.Zero(t, aggregatedID)
+}
+
+func TestDailyFinalizations(t *testing.T) {
+	now := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+	sent := now.Add(-48 * time.Hour)
+	relayed := sent.Add(20 * time.Minute)
+	recent, old := now.Add(-time.Hour), now.Add(-statsRelayWaitPeriod)
+	relays := []*orm.RelayTiming{
+		{ID: 1, RelayTimestamp: &relayed, MsgFound: true, MsgTimestamp: &sent},
+		{ID: 2, RelayTimestamp: &relayed, MsgFound: true, MsgTimestamp: &relayed},
+		// the message isn't indexed for long, e.g. sent by calling the messenger directly
+		{ID: 3, RelayTimestamp: &relayed, CreatedAt: &old},
+		{ID: 4, RelayTimestamp: &now, MsgFound: true, MsgTimestamp: &sent},
+		// the message may still be indexed
+		{ID: 5, RelayTimestamp: &now, CreatedAt: &recent},
+		{ID: 6, RelayTimestamp: &now, MsgFound: true, MsgTimestamp: &sent},
+	}
+	finalizations, aggregatedID := dailyFinalizations(orm.Layer2Msg, relays, now)
+	assert.Equal(t, uint64(4), aggregatedID)
+	assert.Equal(t, []*orm.BridgeDailyFinalization{
+		{Day: statsDay(relayed), MsgType: int(orm.Layer2Msg), FinalizedCount: 2, TotalFinalizeSeconds: 1200},
+		{Day: statsDay(now), MsgType: int(orm.Layer2Msg), FinalizedCount: 1, TotalFinalizeSeconds: 48 * 3600},
+	}, finalizations)
+
+	// the relay waits for its block timestamp
+	finalizations, aggregatedID = dailyFinalizations(orm.Layer2Msg, []*orm.RelayTiming{{ID: 1, MsgFound: true, MsgTimestamp: &sent}}, now)
+	assert.Empty(t, finalizations)
+	assert.Zero(t, aggregatedID)
+}
@@ -0,0 +1,231 @@
+package crossmsg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+
+	backendabi "bridge-history-api/abi"
+	"bridge-history-api/config"
+	"bridge-history-api/orm"
+)
+
+const (
+	// defaultTokenMetadataFetchInterval is the interval the token list is reloaded and the new tokens are read by default
+	defaultTokenMetadataFetchInterval = 10 * time.Minute
+	// tokenMetadataReadLimit is the maximum number of the tokens of each layer read from their contracts per fetch
+	tokenMetadataReadLimit = 100
+)
+
+// tokenList the official token list in the token lists standard, only the fields of the metadata are decoded
+type tokenList struct {
+	Tokens []struct {
+		ChainID  uint64 `json:"chainId"`
+		Address  string `json:"address"`
+		Name     string `json:"name"`
+		Symbol   string `json:"symbol"`
+		Decimals uint8  `json:"decimals"`
+		LogoURI  string `json:"logoURI"`
+	} `json:"tokens"`
+}
+
+// TokenMetadataFetcher maintains the metadata of the bridged ERC20 tokens, the tokens of the official token list take
+// the metadata of the list and the other tokens are read from their contracts once
+type TokenMetadataFetcher struct {
+	ctx              context.Context
+	tokenListURL     string
+	fetchInterval    time.Duration
+	l1Client         *ethclient.Client
+	l2Client         *ethclient.Client
+	httpClient       *http.Client
+	tokenMetadataOrm *orm.TokenMetadata
+	// the tokens whose contracts failed to be read, they are skipped until restart
+	unreadableTokens map[orm.MsgType][]string
+}
+
+// NewTokenMetadataFetcher creates a new TokenMetadataFetcher instance
+func NewTokenMetadataFetcher(ctx context.Context, cfg *config.TokenMetadataConfig, l1Client, l2Client *ethclient.Client, db *gorm.DB) *TokenMetadataFetcher {
+	fetchInterval := time.Duration(cfg.FetchInterval) * time.Second
+	if fetchInterval == 0 {
+		fetchInterval = defaultTokenMetadataFetchInterval
+	}
+	return &TokenMetadataFetcher{
+		ctx:              ctx,
+		tokenListURL:     cfg.TokenListURL,
+		fetchInterval:    fetchInterval,
+		l1Client:         l1Client,
+		l2Client:         l2Client,
+		httpClient:       &http.Client{Timeout: time.Minute},
+		tokenMetadataOrm: orm.NewTokenMetadata(db),
+		unreadableTokens: make(map[orm.MsgType][]string),
+	}
+}
+
+// Start the TokenMetadataFetcher
+func (t *TokenMetadataFetcher) Start() {
+	log.Info("TokenMetadataFetcher Start")
+	t.fetchTokenMetadata()
+	go func() {
+		tick := time.NewTicker(t.fetchInterval)
+		for {
+			select {
+			case <-t.ctx.Done():
+				tick.Stop()
+				return
+			case <-tick.C:
+				t.fetchTokenMetadata()
+			}
+		}
+	}()
+}
+
+// Stop the TokenMetadataFetcher
+func (t *TokenMetadataFetcher) Stop() {
+	log.Info("TokenMetadataFetcher Stop")
+}
+
+// fetchTokenMetadata saves the metadata of the token list, then reads the tokens of each layer out of the list
+func (t *TokenMetadataFetcher) fetchTokenMetadata() {
+	if t.tokenListURL != "" {
+		if err := t.fetchTokenList(); err != nil {
+			log.Error("failed to fetch the token list", "url", t.tokenListURL, "err", err)
+		}
+	}
+	for layer, client := range map[orm.MsgType]*ethclient.Client{orm.Layer1Msg: t.l1Client, orm.Layer2Msg: t.l2Client} {
+		if err := t.readNewTokens(layer, client); err != nil {
+			log.Error("failed to read the metadata of the new tokens", "layer", layer, "err", err)
+		}
+	}
+}
+
+// fetchTokenList saves the metadata of the tokens of both layers in the token list
+func (t *TokenMetadataFetcher) fetchTokenList() error {
+	l1ChainID, err := t.l1Client.ChainID(t.ctx)
+	if err != nil {
+		return err
+	}
+	l2ChainID, err := t.l2Client.ChainID(t.ctx)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(t.ctx, http.MethodGet, t.tokenListURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			log.Warn("failed to close the token list response", "err", closeErr)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	tokens, err := parseTokenList(body, l1ChainID.Uint64(), l2ChainID.Uint64())
+	if err != nil {
+		return err
+	}
+	return t.tokenMetadataOrm.InsertTokenMetadata(t.ctx, tokens)
+}
+
+// parseTokenList returns the metadata of the tokens of the layer1 and layer2 chains in the token list, the tokens of
+// the other chains are skipped
+func parseTokenList(body []byte, l1ChainID, l2ChainID uint64) ([]*orm.TokenMetadata, error) {
+	var list tokenList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("invalid token list: %w", err)
+	}
+	var tokens []*orm.TokenMetadata
+	for _, token := range list.Tokens {
+		var layer orm.MsgType
+		switch token.ChainID {
+		case l1ChainID:
+			layer = orm.Layer1Msg
+		case l2ChainID:
+			layer = orm.Layer2Msg
+		default:
+			continue
+		}
+		if !common.IsHexAddress(token.Address) {
+			continue
+		}
+		tokens = append(tokens, &orm.TokenMetadata{
+			Layer:    int(layer),
+			Address:  common.HexToAddress(token.Address).Hex(),
+			Symbol:   token.Symbol,
+			Name:     token.Name,
+			Decimals: token.Decimals,
+			LogoURI:  token.LogoURI,
+		})
+	}
+	return tokens, nil
+}
+
+// readNewTokens reads the metadata of the tokens of the layer without metadata from their contracts
+func (t *TokenMetadataFetcher) readNewTokens(layer orm.MsgType, client *ethclient.Client) error {
+	tokens, err := t.tokenMetadataOrm.GetTokensWithoutMetadata(t.ctx, layer, t.unreadableTokens[layer], tokenMetadataReadLimit)
+	if err != nil {
+		return err
+	}
+	var metadata []*orm.TokenMetadata
+	for _, token := range tokens {
+		tokenMetadata, err := readTokenMetadata(t.ctx, client, common.HexToAddress(token))
+		if err != nil {
+			log.Warn("failed to read the token metadata", "layer", layer, "token", token, "err", err)
+			t.unreadableTokens[layer] = append(t.unreadableTokens[layer], token)
+			continue
+		}
+		tokenMetadata.Layer = int(layer)
+		metadata = append(metadata, tokenMetadata)
+	}
+	return t.tokenMetadataOrm.InsertTokenMetadata(t.ctx, metadata)
+}
+
+// readTokenMetadata reads the symbol, the decimals and the name of the token from its contract at the latest block,
+// the name is left empty if the token doesn't implement it
+func readTokenMetadata(ctx context.Context, client *ethclient.Client, token common.Address) (*orm.TokenMetadata, error) {
+	call := func(method string) ([]interface{}, error) {
+		data, err := backendabi.ERC20MetadataABI.Pack(method)
+		if err != nil {
+			return nil, err
+		}
+		output, err := client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return backendabi.ERC20MetadataABI.Unpack(method, output)
+	}
+	symbol, err := call("symbol")
+	if err != nil {
+		return nil, fmt.Errorf("symbol: %w", err)
+	}
+	decimals, err := call("decimals")
+	if err != nil {
+		return nil, fmt.Errorf("decimals: %w", err)
+	}
+	tokenMetadata := &orm.TokenMetadata{
+		Address:  token.Hex(),
+		Symbol:   symbol[0].(string),
+		Decimals: decimals[0].(uint8),
+	}
+	if name, err := call("name"); err == nil {
+		tokenMetadata.Name = name[0].(string)
+	}
+	return tokenMetadata, nil
+}
@@ -0,0 +1,286 @@
+package crossmsg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+
+	"bridge-history-api/config"
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+const (
+	// defaultWebhookDeliveryInterval is the interval the due deliveries are attempted by default
+	defaultWebhookDeliveryInterval = 5 * time.Second
+	// defaultWebhookMaxAttempts is the number of the attempts of a delivery before it's given up by default
+	defaultWebhookMaxAttempts = 10
+	// defaultWebhookTimeout is the timeout of each attempt by default
+	defaultWebhookTimeout = 10 * time.Second
+	// webhookDeliveryBatchSize is the maximum number of the due deliveries attempted per interval
+	webhookDeliveryBatchSize = 100
+	// webhookDeliveryConcurrency is the maximum number of the deliveries attempted at the same time
+	webhookDeliveryConcurrency = 10
+	// webhookRetryBackoff is the wait before the second attempt of a delivery, doubled after each failed attempt
+	webhookRetryBackoff = 30 * time.Second
+	// webhookMaxRetryBackoff caps the wait between the attempts of a delivery
+	webhookMaxRetryBackoff = 6 * time.Hour
+	// webhookMaxErrorLength is the maximum length of the error of the last attempt saved
+	webhookMaxErrorLength = 256
+)
+
+// WebhookDispatcher delivers the queued events to the webhooks, the failed deliveries are retried with an exponential
+// backoff until they succeed or run out of attempts. The deliveries are at least once, the receivers dedupe them by id.
+type WebhookDispatcher struct {
+	ctx         context.Context
+	interval    time.Duration
+	maxAttempts int
+	httpClient  *http.Client
+	webhookOrm  *orm.Webhook
+	deliveryOrm *orm.WebhookDelivery
+}
+
+// NewWebhookDispatcher creates a new WebhookDispatcher instance
+func NewWebhookDispatcher(ctx context.Context, cfg *config.WebhookConfig, db *gorm.DB) *WebhookDispatcher {
+	interval := time.Duration(cfg.DeliveryInterval) * time.Second
+	if interval == 0 {
+		interval = defaultWebhookDeliveryInterval
+	}
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultWebhookMaxAttempts
+	}
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout == 0 {
+		timeout = defaultWebhookTimeout
+	}
+	return &WebhookDispatcher{
+		ctx:         ctx,
+		interval:    interval,
+		maxAttempts: maxAttempts,
+		httpClient:  newWebhookHTTPClient(timeout, cfg.AllowPrivateNetworks),
+		webhookOrm:  orm.NewWebhook(db),
+		deliveryOrm: orm.NewWebhookDelivery(db),
+	}
+}
+
+// newWebhookHTTPClient returns the client of the deliveries, the redirects aren't followed and the connections to
+// the non public addresses are refused unless allowPrivateNetworks is set
+func newWebhookHTTPClient(timeout time.Duration, allowPrivateNetworks bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivateNetworks {
+		dialer.Control = refuseNonPublicAddress
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DialContext: dialer.DialContext, MaxIdleConnsPerHost: 2},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// nonPublicNetworks are the special purpose ranges of the IANA registries not reachable on the internet, e.g. the
+// loopback, the private, the shared address space of the carrier grade NATs, the link local, the documentation, the
+// benchmarking, the multicast and the reserved ranges. The IPv4 mapped IPv6 addresses are matched as IPv4.
+var nonPublicNetworks = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("192.88.99.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001::/23"),
+	netip.MustParsePrefix("2001:db8::/32"),
+	netip.MustParsePrefix("2002::/16"),
+	netip.MustParsePrefix("3fff::/20"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// isPublicAddr returns whether the address is in none of the nonPublicNetworks, the zone is ignored since the zoned
+// addresses match no prefix
+func isPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap().WithZone("")
+	for _, network := range nonPublicNetworks {
+		if network.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// refuseNonPublicAddress refuses to connect to the addresses not reachable on the internet, see nonPublicNetworks. It's
+// called with the resolved address so that the host names resolving to them are refused too
+func refuseNonPublicAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !isPublicAddr(addr) {
+		return fmt.Errorf("the webhook address %s is not public", address)
+	}
+	return nil
+}
+
+// Start the WebhookDispatcher
+func (d *WebhookDispatcher) Start() {
+	log.Info("WebhookDispatcher Start")
+	go func() {
+		tick := time.NewTicker(d.interval)
+		for {
+			select {
+			case <-d.ctx.Done():
+				tick.Stop()
+				return
+			case <-tick.C:
+				if err := d.dispatch(); err != nil {
+					log.Error("WebhookDispatcher: failed to dispatch the due deliveries", "err", err)
+				}
+			}
+		}
+	}()
+}
+
+// Stop the WebhookDispatcher
+func (d *WebhookDispatcher) Stop() {
+	log.Info("WebhookDispatcher Stop")
+}
+
+// dispatch attempts the due deliveries and records the outcome of each
+func (d *WebhookDispatcher) dispatch() error {
+	deliveries, err := d.deliveryOrm.GetDueWebhookDeliveries(d.ctx, time.Now(), webhookDeliveryBatchSize)
+	if err != nil || len(deliveries) == 0 {
+		return err
+	}
+	ids := make([]uint64, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		ids = append(ids, delivery.WebhookID)
+	}
+	webhooks, err := d.webhookOrm.GetWebhooksByIDs(d.ctx, ids)
+	if err != nil {
+		return err
+	}
+	webhookByID := make(map[uint64]*orm.Webhook, len(webhooks))
+	for _, webhook := range webhooks {
+		webhookByID[webhook.ID] = webhook
+	}
+
+	var g errgroup.Group
+	g.SetLimit(webhookDeliveryConcurrency)
+	for _, delivery := range deliveries {
+		webhook, ok := webhookByID[delivery.WebhookID]
+		if !ok {
+			// the webhook is deleted since the deliveries are queried
+			continue
+		}
+		delivery := delivery
+		g.Go(func() error {
+			d.attempt(webhook, delivery)
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// attempt delivers the event to the webhook once and saves the outcome, the delivery is given up once it runs out of
+// attempts
+func (d *WebhookDispatcher) attempt(webhook *orm.Webhook, delivery *orm.WebhookDelivery) {
+	attempts := delivery.Attempts + 1
+	deliverErr := d.deliver(webhook, delivery)
+	if deliverErr == nil {
+		if err := d.deliveryOrm.UpdateWebhookDelivered(d.ctx, delivery.ID, attempts, time.Now()); err != nil {
+			log.Error("WebhookDispatcher: failed to save the delivery", "id", delivery.ID, "err", err)
+		}
+		return
+	}
+
+	status := orm.WebhookDeliveryPending
+	if attempts >= d.maxAttempts {
+		status = orm.WebhookDeliveryFailed
+	}
+	log.Debug("WebhookDispatcher: delivery failed", "id", delivery.ID, "webhook", webhook.ID, "attempts", attempts, "err", deliverErr)
+	lastError := deliverErr.Error()
+	if len(lastError) > webhookMaxErrorLength {
+		lastError = lastError[:webhookMaxErrorLength]
+	}
+	err := d.deliveryOrm.UpdateWebhookDeliveryAttempt(d.ctx, delivery.ID, status, attempts, time.Now().Add(webhookBackoff(attempts)), lastError)
+	if err != nil {
+		log.Error("WebhookDispatcher: failed to save the delivery attempt", "id", delivery.ID, "err", err)
+	}
+}
+
+// webhookBackoff returns the wait after the failed attempts of a delivery before it's attempted again
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookRetryBackoff
+	for i := 1; i < attempts && backoff < webhookMaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > webhookMaxRetryBackoff {
+		return webhookMaxRetryBackoff
+	}
+	return backoff
+}
+
+// deliver posts the signed payload of the delivery to the webhook, the delivery succeeds if a 2xx status is returned
+func (d *WebhookDispatcher) deliver(webhook *orm.Webhook, delivery *orm.WebhookDelivery) error {
+	payload, err := json.Marshal(&types.WebhookPayload{
+		ID:        delivery.ID,
+		Type:      types.WebhookEventType(delivery.EventType),
+		Address:   delivery.Address,
+		MsgHash:   delivery.MsgHash,
+		TxHash:    delivery.TxHash,
+		Timestamp: time.Now().Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(types.WebhookSignatureHeader, logic.SignWebhookPayload(webhook.Secret, payload))
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// drained so that the connection is reused
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			log.Warn("WebhookDispatcher: failed to close the response", "err", closeErr)
+		}
+	}()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package crossmsg
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhookBackoff(1))
+	assert.Equal(t, time.Minute, webhookBackoff(2))
+	assert.Equal(t, 4*time.Minute, webhookBackoff(4))
+	assert.Equal(t, webhookMaxRetryBackoff, webhookBackoff(20))
+}
+
+func TestRefuseNonPublicAddress(t *testing.T) {
+	for _, address := range []string{
+		"127.0.0.1:80", "10.1.2.3:443", "100.64.0.1:443", "100.127.255.255:443", "169.254.169.254:80", "172.16.0.1:80",
+		"192.0.0.8:80", "192.0.2.1:80", "192.168.1.1:80", "198.18.0.1:80", "203.0.113.9:80", "224.0.0.1:80", "255.255.255.255:80",
+		"0.0.0.0:80", "[::1]:80", "[::]:80", "[::ffff:127.0.0.1]:80", "[::ffff:100.64.0.1]:80", "[fc00::1]:80", "[fe80::1%eth0]:80",
+		"[2001:db8::1]:80", "[ff02::1]:80", "localhost:80",
+	} {
+		assert.Error(t, refuseNonPublicAddress("tcp", address, nil), address)
+	}
+	for _, address := range []string{"8.8.8.8:443", "100.128.0.1:443", "1.1.1.1:80", "[2606:4700:4700::1111]:443", "[::ffff:8.8.8.8]:443"} {
+		assert.NoError(t, refuseNonPublicAddress("tcp", address, nil), address)
+	}
+}
+
+func TestWebhookDeliver(t *testing.T) {
+	var received *types.WebhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, logic.SignWebhookPayload("0123456789abcdef", body), r.Header.Get(types.WebhookSignatureHeader))
+		assert.NoError(t, json.Unmarshal(body, &received))
+		if received.ID == 2 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	webhook := &orm.Webhook{ID: 1, URL: server.URL, Secret: "0123456789abcdef"}
+	delivery := &orm.WebhookDelivery{ID: 1, EventType: string(types.WebhookEventWithdrawalClaimable), Address: "0x01", MsgHash: "0x02", TxHash: "0x03"}
+	dispatcher := &WebhookDispatcher{ctx: context.Background(), httpClient: newWebhookHTTPClient(time.Second, true)}
+	assert.NoError(t, dispatcher.deliver(webhook, delivery))
+	assert.Equal(t, types.WebhookEventWithdrawalClaimable, received.Type)
+	assert.Equal(t, "0x02", received.MsgHash)
+	assert.Equal(t, "0x03", received.TxHash)
+
+	delivery.ID = 2
+	assert.EqualError(t, dispatcher.deliver(webhook, delivery), "unexpected status 500")
+
+	// the test server listens on the loopback
+	dispatcher.httpClient = newWebhookHTTPClient(time.Second, false)
+	delivery.ID = 1
+	assert.Error(t, dispatcher.deliver(webhook, delivery))
+}
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"bridge-history-api/config"
+	"bridge-history-api/crossmsg"
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/internal/types"
+)
+
+// AdminController contains the admin services of the operators, authenticated by the admin token of the config
+type AdminController struct {
+	token string
+	// tokenID identifies the admin token in the audit records without revealing it
+	tokenID    string
+	gapLogic   *logic.GapLogic
+	adminLogic *logic.AdminLogic
+}
+
+// NewAdminController return AdminController instance, the nodes the messages are fetched again from are only dialed
+// if the admin apis are served
+func NewAdminController(cfg *config.Config, db *gorm.DB) *AdminController {
+	c := &AdminController{gapLogic: logic.NewGapLogic(cfg, db)}
+	if cfg.Server != nil {
+		c.token = cfg.Server.AdminToken
+	}
+	if c.token != "" {
+		c.tokenID = adminTokenID(c.token)
+	}
+	cache := logic.NewRedisCache(cfg.Redis)
+	var repairer logic.MsgRepairer
+	if c.token != "" {
+		if r, err := crossmsg.NewRepairer(cfg, db, cache); err != nil {
+			log.Warn("failed to connect the nodes of the admin apis", "err", err)
+		} else {
+			repairer = r
+		}
+	}
+	c.adminLogic = logic.NewAdminLogic(db, cache, repairer)
+	return c
+}
+
+// adminTokenID returns the first 8 bytes of the sha256 of the admin token in hex
+func adminTokenID(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:8])
+}
+
+// actor returns the actor of the audit records of the authenticated request, the id of the admin token along with
+// the client ip
+func (c *AdminController) actor(ctx *gin.Context) string {
+	return "token:" + c.tokenID + "@" + ctx.ClientIP()
+}
+
+// authenticate rejects the request unless the admin token of the header matches, all the requests are rejected if the
+// network has no admin token
+func (c *AdminController) authenticate(ctx *gin.Context) bool {
+	if c.token == "" || !hmac.Equal([]byte(ctx.GetHeader(types.AdminTokenHeader)), []byte(c.token)) {
+		types.RenderAbort(ctx, http.StatusUnauthorized, types.ErrInvalidAdminTokenNo, errors.New("invalid admin token"))
+		return false
+	}
+	return true
+}
+
+// GetGaps defines the http get method behavior, the checkpoints and the nonces of the indexed events are scanned for
+// the gaps
+func (c *AdminController) GetGaps(ctx *gin.Context) {
+	if !c.authenticate(ctx) {
+		return
+	}
+	result, err := c.gapLogic.ScanGaps(ctx)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrScanGapsFailure, err)
+		return
+	}
+	types.RenderSuccess(ctx, result)
+}
+
+// PostRefetchTx defines the http post method behavior, the events of the tx of the body are fetched again
+func (c *AdminController) PostRefetchTx(ctx *gin.Context) {
+	var req types.AdminRefetchTxRequest
+	if !c.authenticate(ctx) || !bindAdminRequest(ctx, &req) {
+		return
+	}
+	result, err := c.adminLogic.RefetchTx(ctx, c.actor(ctx), &req)
+	renderAdminResult(ctx, result, err)
+}
+
+// PostRecomputeProof defines the http post method behavior, the proof of the withdrawal of the body is rebuilt
+func (c *AdminController) PostRecomputeProof(ctx *gin.Context) {
+	var req types.AdminMsgRequest
+	if !c.authenticate(ctx) || !bindAdminRequest(ctx, &req) {
+		return
+	}
+	result, err := c.adminLogic.RecomputeProof(ctx, c.actor(ctx), &req)
+	renderAdminResult(ctx, result, err)
+}
+
+// PostReenrichMsg defines the http post method behavior, the enrichments of the message of the body are read again
+func (c *AdminController) PostReenrichMsg(ctx *gin.Context) {
+	var req types.AdminMsgRequest
+	if !c.authenticate(ctx) || !bindAdminRequest(ctx, &req) {
+		return
+	}
+	result, err := c.adminLogic.ReenrichMsg(ctx, c.actor(ctx), &req)
+	renderAdminResult(ctx, result, err)
+}
+
+// PostDeleteRow defines the http post method behavior, the row of the body is soft deleted
+func (c *AdminController) PostDeleteRow(ctx *gin.Context) {
+	var req types.AdminDeleteRowRequest
+	if !c.authenticate(ctx) || !bindAdminRequest(ctx, &req) {
+		return
+	}
+	result, err := c.adminLogic.DeleteRow(ctx, c.actor(ctx), &req)
+	renderAdminResult(ctx, result, err)
+}
+
+// bindAdminRequest binds the json body of the request to req, the failure is rendered
+func bindAdminRequest(ctx *gin.Context, req interface{}) bool {
+	if err := ctx.ShouldBindJSON(req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return false
+	}
+	return true
+}
+
+// renderAdminResult renders the result of an admin action or its failure
+func renderAdminResult(ctx *gin.Context, result *types.AdminActionResult, err error) {
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrAdminActionFailure, err)
+		return
+	}
+	types.RenderSuccess(ctx, result)
+}
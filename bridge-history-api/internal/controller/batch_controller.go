@@ -29,9 +29,24 @@ func (b *BatchController) GetWithdrawRootByBatchIndex(ctx *gin.Context) {
 	}
 	result, err := b.batchLogic.GetWithdrawRootByBatchIndex(ctx, req.BatchIndex)
 	if err != nil {
-		types.RenderFailure(ctx, types.ErrGetWithdrawRootByBatchIndexFailure, err)
+		renderQueryFailure(ctx, types.ErrGetWithdrawRootByBatchIndexFailure, err)
 		return
 	}
 
 	types.RenderSuccess(ctx, result)
 }
+
+// GetBatches defines the http get method behavior, the rollup batches from the latest one
+func (b *BatchController) GetBatches(ctx *gin.Context) {
+	var req types.Pagination
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	batches, total, err := b.batchLogic.GetBatches(ctx, req)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrGetBatchesFailure, err)
+		return
+	}
+	types.RenderSuccess(ctx, &types.BatchResultData{Result: batches, Total: total})
+}
@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+
+	"bridge-history-api/config"
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/internal/types"
+)
+
+const (
+	// claimableWatchPingInterval is the interval of the pings keeping the idle connections alive
+	claimableWatchPingInterval = 30 * time.Second
+	// claimableWatchWriteTimeout is the deadline of writing an event or a ping to the connection
+	claimableWatchWriteTimeout = 10 * time.Second
+	// defaultMaxWatchesPerIP is the number of the connections each client ip may keep open at once by default
+	defaultMaxWatchesPerIP = 10
+)
+
+// ClaimableWatchController contains the watch claimable txs service
+type ClaimableWatchController struct {
+	watcher  *logic.ClaimableWatcher
+	upgrader websocket.Upgrader
+	// ens resolves the ENS names of the address parameters, nil rejects the names
+	ens *logic.ENSResolver
+	// maxWatchesPerIP caps the connections open at once by each client ip
+	maxWatchesPerIP int
+
+	mu      sync.Mutex
+	watches map[string]int
+}
+
+// NewClaimableWatchController return ClaimableWatchController instance, the claimable events published by the fetcher
+// are dispatched to the watchers from then on, the ENS names of the addresses are resolved by ens if not nil
+func NewClaimableWatchController(cfg *config.Config, ens *logic.ENSResolver) *ClaimableWatchController {
+	watcher := logic.NewClaimableWatcher(logic.NewRedisClaimableEvents(cfg.Redis))
+	if watcher != nil {
+		go watcher.Run(context.Background())
+	}
+	var allowedOrigins []string
+	maxWatchesPerIP := defaultMaxWatchesPerIP
+	if cfg.Server != nil {
+		allowedOrigins = cfg.Server.WatchAllowedOrigins
+		if cfg.Server.MaxWatchesPerIP > 0 {
+			maxWatchesPerIP = cfg.Server.MaxWatchesPerIP
+		}
+	}
+	return &ClaimableWatchController{
+		watcher:         watcher,
+		upgrader:        websocket.Upgrader{CheckOrigin: checkWatchOrigin(allowedOrigins)},
+		ens:             ens,
+		maxWatchesPerIP: maxWatchesPerIP,
+		watches:         make(map[string]int),
+	}
+}
+
+// checkWatchOrigin returns the origin check of the upgrader allowing the origins, the browsers don't apply the cors
+// to the websockets so that the origins are checked on upgrade. "*" allows all of them, no origins allows the same
+// origin only by the default check of the upgrader. The requests without origin aren't sent by browsers and allowed.
+func checkWatchOrigin(origins []string) func(*http.Request) bool {
+	if len(origins) == 0 {
+		return nil
+	}
+	allowed := make(map[string]struct{}, len(origins))
+	for _, origin := range origins {
+		if origin == "*" {
+			return func(*http.Request) bool { return true }
+		}
+		allowed[strings.ToLower(strings.TrimSuffix(origin, "/"))] = struct{}{}
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		_, found := allowed[strings.ToLower(origin)]
+		return found
+	}
+}
+
+// acquireWatch counts a connection of the client ip, false is returned if the ip keeps maxWatchesPerIP open already
+func (c *ClaimableWatchController) acquireWatch(ip string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.watches[ip] >= c.maxWatchesPerIP {
+		return false
+	}
+	c.watches[ip]++
+	return true
+}
+
+// releaseWatch uncounts a connection of the client ip once closed
+func (c *ClaimableWatchController) releaseWatch(ip string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.watches[ip]--; c.watches[ip] <= 0 {
+		delete(c.watches, ip)
+	}
+}
+
+// WatchClaimables defines the websocket behavior, the claimable events of the address are pushed until the client
+// closes the connection
+func (c *ClaimableWatchController) WatchClaimables(ctx *gin.Context) {
+	param := ctx.Param("address")
+	if !common.IsHexAddress(param) && !logic.IsENSName(param) {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, errors.New("invalid address"))
+		return
+	}
+	if c.watcher == nil {
+		types.RenderFailure(ctx, types.ErrWatchClaimablesFailure, errors.New("watching the claimable txs is not enabled"))
+		return
+	}
+	address, err := resolveAddress(ctx, c.ens, param)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrResolveENSNameFailure, err)
+		return
+	}
+	ip := ctx.ClientIP()
+	if !c.acquireWatch(ip) {
+		types.RenderAbort(ctx, http.StatusTooManyRequests, types.ErrTooManyRequestsNo, errors.New("too many claimable watches of the client ip"))
+		return
+	}
+	defer c.releaseWatch(ip)
+	conn, err := c.upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		// the upgrader responds the failure already
+		log.Debug("upgrade claimable watch connection failed", "address", address, "error", err)
+		return
+	}
+	defer func() { _ = conn.Close() }()
+
+	events, unwatch := c.watcher.Watch(address)
+	defer unwatch()
+
+	// the client messages are discarded, reading is needed to handle the pongs and notice the close
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(claimableWatchPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-closed:
+			return
+		case event := <-events:
+			if err := conn.SetWriteDeadline(time.Now().Add(claimableWatchWriteTimeout)); err != nil {
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				log.Debug("write claimable event failed", "address", address, "error", err)
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(claimableWatchWriteTimeout)); err != nil {
+				return
+			}
+		}
+	}
+}
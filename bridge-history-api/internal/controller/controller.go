@@ -1,24 +1,91 @@
 package controller
 
 import (
-	"sync"
+	"context"
+	"errors"
+	"fmt"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"gorm.io/gorm"
+
+	"bridge-history-api/config"
+	"bridge-history-api/internal/grpcserver"
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/utils"
 )
 
-var (
-	// HistoryCtrler is controller instance
-	HistoryCtrler *HistoryController
-	// BatchCtrler is controller instance
-	BatchCtrler *BatchController
+// Controllers are the controller instances of the apis of one network
+type Controllers struct {
+	History        *HistoryController
+	Batch          *BatchController
+	ClaimableWatch *ClaimableWatchController
+	GraphQL        *GraphQLController
+	Webhook        *WebhookController
+	Stats          *StatsController
+	Admin          *AdminController
+	// GRPC serves the history logic of the network over grpc
+	GRPC *grpcserver.HistoryService
+	// Health checks the db, the nodes and the indexing lag of the network for the probes
+	Health *logic.HealthLogic
+}
 
-	initControllerOnce sync.Once
-)
+// NewControllers creates the Controllers of the network of the config with its database and the registerer of the metrics
+func NewControllers(cfg *config.Config, db *gorm.DB, reg prometheus.Registerer) *Controllers {
+	history := NewHistoryController(cfg, db, reg)
+	return &Controllers{
+		History:        history,
+		Batch:          NewBatchController(db),
+		ClaimableWatch: NewClaimableWatchController(cfg, history.ens),
+		GraphQL:        NewGraphQLController(history.historyLogic, db),
+		Webhook:        NewWebhookController(db),
+		Stats:          NewStatsController(db),
+		Admin:          NewAdminController(cfg, db),
+		GRPC:           grpcserver.NewHistoryService(history.historyLogic, logic.NewBatchLogic(db)),
+		Health:         logic.NewHealthLogic(cfg, db, dialNode(cfg.L1), dialNode(cfg.L2)),
+	}
+}
+
+// dialNode returns the client of the node of the layer checked by the probes, nil if the layer has no endpoint
+func dialNode(layer *config.LayerConfig) logic.HeadReader {
+	if layer == nil || layer.Endpoint == "" {
+		return nil
+	}
+	client, err := utils.DialEthClient(layer.Endpoint)
+	if err != nil {
+		log.Warn("failed to connect the node of the probes", "err", err)
+		return nil
+	}
+	return client
+}
+
+// resolveAddress returns the address of the address parameter, either a hex address or an ENS name resolved by ens
+func resolveAddress(ctx context.Context, ens *logic.ENSResolver, address string) (common.Address, error) {
+	if !logic.IsENSName(address) {
+		return common.HexToAddress(address), nil
+	}
+	if ens == nil {
+		return common.Address{}, fmt.Errorf("%w: the ENS names are not enabled", logic.ErrInvalidParameter)
+	}
+	return ens.Resolve(ctx, address)
+}
 
-// InitController inits Controller with database
-func InitController(db *gorm.DB) {
-	initControllerOnce.Do(func() {
-		HistoryCtrler = NewHistoryController(db)
-		BatchCtrler = NewBatchController(db)
-	})
+// renderQueryFailure renders the error returned by the logic, the invalid parameters and the records not found are
+// reported as such and the database failures are fatal, the other errors are reported with errCode
+func renderQueryFailure(ctx *gin.Context, errCode int, err error) {
+	switch {
+	case errors.Is(err, logic.ErrInvalidParameter):
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+	case errors.Is(err, logic.ErrNotFound):
+		types.RenderFailure(ctx, types.ErrNotFoundNo, err)
+	case errors.Is(err, logic.ErrConflict):
+		types.RenderFailure(ctx, types.ErrConflictNo, err)
+	case errors.Is(err, logic.ErrDatabase):
+		types.RenderFatal(ctx, err)
+	default:
+		types.RenderFailure(ctx, errCode, err)
+	}
 }
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gin-gonic/gin"
+
+	"bridge-history-api/internal/types"
+)
+
+// exportCSVHeader the columns of the csv exports, the nested fields are flattened and the lists left out
+var exportCSVHeader = []string{
+	"offset", "hash", "msgHash", "isL1", "status", "operationType", "amount", "formattedAmount", "l1Token", "l2Token",
+	"tokenType", "tokenSymbol", "blockNumber", "blockTimestamp", "finalizeTxHash", "claimStatus", "gasFee", "bridgeFee",
+	"createdTime",
+}
+
+// PostExportTxs defines the http post method behavior, the txs matching the filter of the body streamed in csv or
+// ndjson. The failure envelope is only rendered if nothing is written yet, a later failure ends the response early
+// and the client resumes from the offset of the last record received.
+func (c *HistoryController) PostExportTxs(ctx *gin.Context) {
+	var req types.ExportTxsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	var writeRecords func([]*types.TxExportRecord) error
+	switch req.Format {
+	case "", types.TxExportFormatCSV:
+		writeRecords = csvExportWriter(ctx)
+	case types.TxExportFormatNDJSON:
+		writeRecords = ndjsonExportWriter(ctx)
+	default:
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, fmt.Errorf("unknown export format %q, csv or ndjson", req.Format))
+		return
+	}
+
+	started := false
+	err := c.historyLogic.ExportTxsByFilter(ctx, req.TxFilter, req.Order, req.Offset, func(records []*types.TxExportRecord) error {
+		started = true
+		if err := writeRecords(records); err != nil {
+			return err
+		}
+		ctx.Writer.Flush()
+		return nil
+	})
+	switch {
+	case err != nil && !started:
+		renderQueryFailure(ctx, types.ErrExportTxsFailure, err)
+	case err != nil:
+		log.Warn("the export of the txs ended early", "offset", req.Offset, "err", err)
+	case !started:
+		// no tx matched, the csv header is still written
+		if err = writeRecords(nil); err != nil {
+			log.Warn("failed to write the empty export", "err", err)
+		}
+	}
+}
+
+// csvExportWriter returns the writer of the csv records, the headers and the header row are written with the first batch
+func csvExportWriter(ctx *gin.Context) func([]*types.TxExportRecord) error {
+	writer := csv.NewWriter(ctx.Writer)
+	headerWritten := false
+	return func(records []*types.TxExportRecord) error {
+		if !headerWritten {
+			ctx.Header("Content-Type", "text/csv; charset=utf-8")
+			ctx.Header("Content-Disposition", `attachment; filename="txs.csv"`)
+			if err := writer.Write(exportCSVHeader); err != nil {
+				return err
+			}
+			headerWritten = true
+		}
+		for _, record := range records {
+			if err := writer.Write(exportCSVRow(record)); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+}
+
+// ndjsonExportWriter returns the writer of the ndjson records, a json record per line
+func ndjsonExportWriter(ctx *gin.Context) func([]*types.TxExportRecord) error {
+	encoder := json.NewEncoder(ctx.Writer)
+	headersWritten := false
+	return func(records []*types.TxExportRecord) error {
+		if !headersWritten {
+			ctx.Header("Content-Type", "application/x-ndjson")
+			ctx.Header("Content-Disposition", `attachment; filename="txs.ndjson"`)
+			headersWritten = true
+		}
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// exportCSVRow flattens the record into the columns of exportCSVHeader
+func exportCSVRow(record *types.TxExportRecord) []string {
+	var finalizeTxHash string
+	if record.FinalizeTx != nil {
+		finalizeTxHash = record.FinalizeTx.Hash
+	}
+	return []string{
+		strconv.FormatUint(record.Offset, 10),
+		record.Hash,
+		record.MsgHash,
+		strconv.FormatBool(record.IsL1),
+		string(record.Status),
+		string(record.OperationType),
+		record.Amount,
+		record.FormattedAmount,
+		record.L1Token,
+		record.L2Token,
+		string(record.TokenType),
+		record.TokenSymbol,
+		strconv.FormatUint(record.BlockNumber, 10),
+		formatExportTime(record.BlockTimestamp),
+		finalizeTxHash,
+		strconv.Itoa(int(record.ClaimStatus)),
+		record.GasFee,
+		record.BridgeFee,
+		formatExportTime(record.CreatedAt),
+	}
+}
+
+// formatExportTime formats the time in RFC 3339 in UTC, empty if unknown
+func formatExportTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/graph"
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/internal/types"
+)
+
+// GraphQLController contains the graphql query service
+type GraphQLController struct {
+	schema *graph.Schema
+}
+
+// NewGraphQLController return GraphQLController instance resolving the queries with the history logic
+func NewGraphQLController(historyLogic *logic.HistoryLogic, db *gorm.DB) *GraphQLController {
+	return &GraphQLController{
+		schema: graph.NewSchema(historyLogic, db),
+	}
+}
+
+// PostQuery defines the http post method behavior, the graphql response is rendered as is so that the graphql clients
+// can consume it
+func (c *GraphQLController) PostQuery(ctx *gin.Context) {
+	var req types.GraphQLRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, c.schema.Exec(ctx, req.Query, req.OperationName, req.Variables))
+}
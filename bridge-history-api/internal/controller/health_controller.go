@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/internal/types"
+)
+
+// probeTimeout bounds the checks of each probe, the load balancers time the probes out shortly
+const probeTimeout = 3 * time.Second
+
+// HealthController the probes of the load balancers, checking the networks all together
+type HealthController struct {
+	networks []*logic.HealthLogic
+}
+
+// NewHealthController returns the HealthController of the networks, keyed by the network name
+func NewHealthController(networks map[string]*Controllers) *HealthController {
+	names := make([]string, 0, len(networks))
+	for name := range networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	controller := &HealthController{}
+	for _, name := range names {
+		controller.networks = append(controller.networks, networks[name].Health)
+	}
+	return controller
+}
+
+// Healthz responds 200 if the db and the nodes of all the networks are reachable, 503 otherwise
+func (c *HealthController) Healthz(ctx *gin.Context) {
+	c.render(ctx, (*logic.HealthLogic).Health)
+}
+
+// Readyz responds 200 if the networks are healthy and their indexers are not lagging behind the heads, 503 otherwise
+// so that the load balancers stop routing to the stale replicas
+func (c *HealthController) Readyz(ctx *gin.Context) {
+	c.render(ctx, (*logic.HealthLogic).Readiness)
+}
+
+func (c *HealthController) render(ctx *gin.Context, check func(*logic.HealthLogic, context.Context) []*types.HealthCheck) {
+	checkCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+	data := &types.HealthData{}
+	healthy := true
+	for _, network := range c.networks {
+		for _, result := range check(network, checkCtx) {
+			healthy = healthy && result.Healthy
+			data.Checks = append(data.Checks, result)
+		}
+	}
+	if healthy {
+		types.RenderSuccess(ctx, data)
+		return
+	}
+	ctx.JSON(http.StatusServiceUnavailable, types.Response{
+		ErrCode: types.ErrUnavailableNo,
+		ErrMsg:  "some checks failed",
+		Data:    data,
+	})
+}
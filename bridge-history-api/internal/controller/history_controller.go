@@ -3,22 +3,27 @@ package controller
 import (
 	"errors"
 	"reflect"
+	"strconv"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/gin-gonic/gin"
 	"github.com/patrickmn/go-cache"
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
 
+	"bridge-history-api/config"
 	"bridge-history-api/internal/logic"
 	"bridge-history-api/internal/types"
+	"bridge-history-api/utils"
 )
 
 const (
 	cacheKeyPrefixClaimableTxsByAddr = "claimableTxsByAddr:"
 	cacheKeyPrefixQueryTxsByHash     = "queryTxsByHash:"
+	cacheKeyPrefixWithdrawProof      = "withdrawProof:"
 )
 
 // HistoryController contains the query claimable txs service
@@ -27,15 +32,42 @@ type HistoryController struct {
 	cache        *cache.Cache
 	singleFlight singleflight.Group
 	cacheMetrics *cacheMetrics
+	// l1Messenger is the L1ScrollMessenger the claims are sent to
+	l1Messenger common.Address
+	// gasEstimator estimates the gas of the claims with the layer1 node, nil estimates them statically
+	gasEstimator logic.GasEstimator
+	// ens resolves the ENS names of the address parameters with the layer1 node, nil rejects the names
+	ens *logic.ENSResolver
 }
 
 // NewHistoryController return HistoryController instance
-func NewHistoryController(db *gorm.DB) *HistoryController {
-	return &HistoryController{
-		historyLogic: logic.NewHistoryLogic(db),
+func NewHistoryController(cfg *config.Config, db *gorm.DB, reg prometheus.Registerer) *HistoryController {
+	controller := &HistoryController{
+		historyLogic: logic.NewHistoryLogic(cfg, db, logic.NewRedisCache(cfg.Redis), reg),
 		cache:        cache.New(30*time.Second, 10*time.Minute),
 		cacheMetrics: initCacheMetrics(),
 	}
+	if cfg.L1 != nil {
+		controller.l1Messenger = common.HexToAddress(cfg.L1.MessengerAddr)
+		if cfg.L1.Endpoint != "" {
+			if l1Client, err := utils.DialEthClient(cfg.L1.Endpoint); err != nil {
+				log.Warn("failed to connect l1 geth, the claim gas is estimated statically", "err", err)
+			} else {
+				controller.gasEstimator = l1Client
+				controller.ens = logic.NewENSResolver(cfg.ENS, l1Client)
+				if cfg.Server != nil && cfg.Server.VerifyClaimsOnChain {
+					controller.historyLogic.SetClaimVerifier(logic.NewClaimVerifier(l1Client, controller.l1Messenger))
+				}
+			}
+		}
+	}
+	if cfg.ENS != nil && controller.ens == nil {
+		log.Warn("the ENS names are rejected, resolving them requires the l1 endpoint")
+	}
+	if cfg.Server != nil && cfg.Server.VerifyClaimsOnChain && controller.gasEstimator == nil {
+		log.Warn("the claims are not verified on chain, verifying them requires the l1 endpoint")
+	}
+	return controller
 }
 
 // GetAllClaimableTxsByAddr defines the http get method behavior
@@ -45,8 +77,13 @@ func (c *HistoryController) GetAllClaimableTxsByAddr(ctx *gin.Context) {
 		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
 		return
 	}
+	address, err := resolveAddress(ctx, c.ens, req.Address)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrResolveENSNameFailure, err)
+		return
+	}
 
-	cacheKey := cacheKeyPrefixClaimableTxsByAddr + req.Address
+	cacheKey := cacheKeyPrefixClaimableTxsByAddr + address.Hex()
 	if cachedData, found := c.cache.Get(cacheKey); found {
 		c.cacheMetrics.cacheHits.WithLabelValues("GetAllClaimableTxsByAddr").Inc()
 		// Log cache hit along with request param.
@@ -67,7 +104,7 @@ func (c *HistoryController) GetAllClaimableTxsByAddr(ctx *gin.Context) {
 	}
 
 	result, err, _ := c.singleFlight.Do(cacheKey, func() (interface{}, error) {
-		txs, total, err := c.historyLogic.GetClaimableTxsByAddress(ctx, common.HexToAddress(req.Address))
+		txs, total, err := c.historyLogic.GetClaimableTxsByAddress(ctx, address, nil, types.TokenTypeAll, types.SortOrderDesc)
 		if err != nil {
 			return nil, err
 		}
@@ -77,7 +114,7 @@ func (c *HistoryController) GetAllClaimableTxsByAddr(ctx *gin.Context) {
 	})
 
 	if err != nil {
-		types.RenderFailure(ctx, types.ErrGetClaimablesFailure, err)
+		renderQueryFailure(ctx, types.ErrGetClaimablesFailure, err)
 		return
 	}
 
@@ -89,6 +126,150 @@ func (c *HistoryController) GetAllClaimableTxsByAddr(ctx *gin.Context) {
 	}
 }
 
+// GetTxsByAddr defines the http get method behavior, the txs of the address are paginated by the opaque cursor
+func (c *HistoryController) GetTxsByAddr(ctx *gin.Context) {
+	var req types.QueryByAddressWithCursorRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	address, err := resolveAddress(ctx, c.ens, req.Address)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrResolveENSNameFailure, err)
+		return
+	}
+	txs, nextCursor, err := c.historyLogic.GetTxsByAddressWithKeyset(ctx, address, req.KeysetPagination)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrGetTxsByAddrFailure, err)
+		return
+	}
+	types.RenderSuccess(ctx, &types.KeysetResultData{Result: txs, NextCursor: nextCursor})
+}
+
+// GetTxsByBatchIndex defines the http get method behavior, the withdrawals of the rollup batch of the index
+func (c *HistoryController) GetTxsByBatchIndex(ctx *gin.Context) {
+	var req types.QueryTxsByBatchIndexRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	txs, total, err := c.historyLogic.GetTxsByBatchIndex(ctx, req.BatchIndex, req.Pagination)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrGetTxsByBatchIndexFailure, err)
+		return
+	}
+	types.RenderSuccess(ctx, &types.ResultData{Result: txs, Total: total})
+}
+
+// GetTxsByTimeRange defines the http get method behavior, the txs of all addresses in the block timestamp range
+func (c *HistoryController) GetTxsByTimeRange(ctx *gin.Context) {
+	var req types.QueryByTimeRangeRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	txs, total, err := c.historyLogic.GetTxsByTimeRange(ctx, req.StartTime, req.EndTime, req.Direction, req.Order, req.Pagination)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrGetTxsByTimeRangeFailure, err)
+		return
+	}
+	types.RenderSuccess(ctx, &types.ResultData{Result: txs, Total: total})
+}
+
+// GetClaimableTxsByAddrWithCursor defines the http get method behavior, the claimable txs of the address are paginated
+// by the opaque cursor
+func (c *HistoryController) GetClaimableTxsByAddrWithCursor(ctx *gin.Context) {
+	var req types.QueryByAddressWithCursorRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	address, err := resolveAddress(ctx, c.ens, req.Address)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrResolveENSNameFailure, err)
+		return
+	}
+	txs, nextCursor, err := c.historyLogic.GetClaimableTxsByAddressWithKeyset(ctx, address, req.KeysetPagination)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrGetClaimablesFailure, err)
+		return
+	}
+	types.RenderSuccess(ctx, &types.KeysetResultData{Result: txs, NextCursor: nextCursor})
+}
+
+// GetTxStatus defines the http get method behavior, the lifecycle state of the message sent by the tx
+func (c *HistoryController) GetTxStatus(ctx *gin.Context) {
+	var req types.QueryTxStatusRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	status, err := c.historyLogic.GetTxStatus(ctx, req.Hash)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrGetTxStatusFailure, err)
+		return
+	}
+	if status == nil {
+		types.RenderFailure(ctx, types.ErrNotFoundNo, errors.New("the tx sends no bridge message"))
+		return
+	}
+	types.RenderSuccess(ctx, status)
+}
+
+// GetClaimableSummary defines the http get method behavior, the counts and the values by token of the claimable
+// withdrawals of the address
+func (c *HistoryController) GetClaimableSummary(ctx *gin.Context) {
+	var req types.QueryClaimableSummaryRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	address, err := resolveAddress(ctx, c.ens, req.Address)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrResolveENSNameFailure, err)
+		return
+	}
+	summary, err := c.historyLogic.GetClaimableSummary(ctx, address)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrGetClaimableSummaryFailure, err)
+		return
+	}
+	types.RenderSuccess(ctx, summary)
+}
+
+// GetWithdrawProof defines the http get method behavior, the claim info of the withdrawal of the nonce with its proof
+// regenerated, the concurrent requests of the same nonce share one regeneration
+func (c *HistoryController) GetWithdrawProof(ctx *gin.Context) {
+	var req types.QueryWithdrawProofRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	result, err, _ := c.singleFlight.Do(cacheKeyPrefixWithdrawProof+strconv.FormatUint(req.Nonce, 10), func() (interface{}, error) {
+		return c.historyLogic.GetWithdrawProof(ctx, req.Nonce)
+	})
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrGetWithdrawProofFailure, err)
+		return
+	}
+	types.RenderSuccess(ctx, result)
+}
+
+// PostQueryTxsByFilter defines the http post method behavior, the txs matching the filter of the body
+func (c *HistoryController) PostQueryTxsByFilter(ctx *gin.Context) {
+	var req types.QueryByFilterRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	txs, total, err := c.historyLogic.GetTxsByFilter(ctx, req.TxFilter, req.Order, types.Pagination{Page: req.Page, PageSize: req.PageSize})
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrGetTxsByFilterFailure, err)
+		return
+	}
+	types.RenderSuccess(ctx, &types.ResultData{Result: txs, Total: total})
+}
+
 // PostQueryTxsByHash defines the http post method behavior
 func (c *HistoryController) PostQueryTxsByHash(ctx *gin.Context) {
 	var req types.QueryByHashRequest
@@ -133,9 +314,9 @@ func (c *HistoryController) PostQueryTxsByHash(ctx *gin.Context) {
 	}
 
 	if len(uncachedHashes) > 0 {
-		dbResults, err := c.historyLogic.GetTxsByHashes(ctx, uncachedHashes)
+		dbResults, err := c.historyLogic.GetTxsByHashes(ctx, uncachedHashes, types.TokenTypeAll, types.SortOrderDesc)
 		if err != nil {
-			types.RenderFailure(ctx, types.ErrGetTxsByHashFailure, err)
+			renderQueryFailure(ctx, types.ErrGetTxsByHashFailure, err)
 			return
 		}
 
@@ -159,3 +340,26 @@ func (c *HistoryController) PostQueryTxsByHash(ctx *gin.Context) {
 	resultData := &types.ResultData{Result: results, Total: uint64(len(results))}
 	types.RenderSuccess(ctx, resultData)
 }
+
+// GetClaimTx defines the http get method behavior, the layer1 tx claiming the withdrawal of the msg hash
+func (c *HistoryController) GetClaimTx(ctx *gin.Context) {
+	var req types.QueryClaimTxRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	var from common.Address
+	if req.From != "" {
+		if !common.IsHexAddress(req.From) {
+			types.RenderFailure(ctx, types.ErrParameterInvalidNo, errors.New("invalid from address"))
+			return
+		}
+		from = common.HexToAddress(req.From)
+	}
+	result, err := c.historyLogic.GetClaimTx(ctx, req.MsgHash, from, c.l1Messenger, c.gasEstimator)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrGetClaimTxFailure, err)
+		return
+	}
+	types.RenderSuccess(ctx, result)
+}
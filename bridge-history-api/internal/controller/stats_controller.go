@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/internal/types"
+)
+
+// StatsController contains the query bridge stats service
+type StatsController struct {
+	statsLogic *logic.StatsLogic
+}
+
+// NewStatsController return NewStatsController instance
+func NewStatsController(db *gorm.DB) *StatsController {
+	return &StatsController{
+		statsLogic: logic.NewStatsLogic(db),
+	}
+}
+
+// GetVolumes defines the http get method behavior
+func (s *StatsController) GetVolumes(ctx *gin.Context) {
+	var req types.QueryVolumeStatsRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	result, err := s.statsLogic.GetDailyVolumes(ctx, &req)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrGetStatsFailure, err)
+		return
+	}
+
+	types.RenderSuccess(ctx, result)
+}
+
+// GetTxCounts defines the http get method behavior
+func (s *StatsController) GetTxCounts(ctx *gin.Context) {
+	var req types.QueryStatsRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	result, err := s.statsLogic.GetDailyTxCounts(ctx, &req)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrGetStatsFailure, err)
+		return
+	}
+
+	types.RenderSuccess(ctx, result)
+}
+
+// GetFinalizeTimes defines the http get method behavior
+func (s *StatsController) GetFinalizeTimes(ctx *gin.Context) {
+	var req types.QueryStatsRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	result, err := s.statsLogic.GetDailyFinalizeTimes(ctx, &req)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrGetStatsFailure, err)
+		return
+	}
+
+	types.RenderSuccess(ctx, result)
+}
+
+// GetPendingWithdrawals defines the http get method behavior
+func (s *StatsController) GetPendingWithdrawals(ctx *gin.Context) {
+	result, err := s.statsLogic.GetPendingWithdrawals(ctx)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrGetStatsFailure, err)
+		return
+	}
+
+	types.RenderSuccess(ctx, result)
+}
@@ -0,0 +1,72 @@
+package controller
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/internal/types"
+)
+
+// WebhookController contains the webhook registration service
+type WebhookController struct {
+	webhookLogic *logic.WebhookLogic
+}
+
+// NewWebhookController return WebhookController instance
+func NewWebhookController(db *gorm.DB) *WebhookController {
+	return &WebhookController{
+		webhookLogic: logic.NewWebhookLogic(db),
+	}
+}
+
+// PostRegisterWebhook defines the http post method behavior, the webhook of the body is registered
+func (c *WebhookController) PostRegisterWebhook(ctx *gin.Context) {
+	var req types.RegisterWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	result, err := c.webhookLogic.RegisterWebhook(ctx, &req)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrRegisterWebhookFailure, err)
+		return
+	}
+	types.RenderSuccess(ctx, result)
+}
+
+// PutUpdateWebhook defines the http put method behavior, the secret of the webhook of the id is replaced by the one
+// of the body if the current secret of the header matches
+func (c *WebhookController) PutUpdateWebhook(ctx *gin.Context) {
+	var uri types.DeleteWebhookRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	var req types.UpdateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	result, err := c.webhookLogic.UpdateWebhookSecret(ctx, uri.ID, ctx.GetHeader(types.WebhookSecretHeader), req.Secret)
+	if err != nil {
+		renderQueryFailure(ctx, types.ErrUpdateWebhookFailure, err)
+		return
+	}
+	types.RenderSuccess(ctx, result)
+}
+
+// DeleteWebhook defines the http delete method behavior, the webhook of the id is deleted if the secret of the
+// header matches
+func (c *WebhookController) DeleteWebhook(ctx *gin.Context) {
+	var req types.DeleteWebhookRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		types.RenderFailure(ctx, types.ErrParameterInvalidNo, err)
+		return
+	}
+	if err := c.webhookLogic.DeleteWebhook(ctx, req.ID, ctx.GetHeader(types.WebhookSecretHeader)); err != nil {
+		renderQueryFailure(ctx, types.ErrDeleteWebhookFailure, err)
+		return
+	}
+	types.RenderSuccess(ctx, nil)
+}
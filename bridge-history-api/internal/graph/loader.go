@@ -0,0 +1,146 @@
+package graph
+
+import (
+	"context"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// loader batches the lookups of one query by key. The keys primed by the resolved parents are fetched along with the
+// first key loaded, so the fields of the items of a list cost one query instead of one per item.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	fetched map[K]V
+}
+
+// newLoader returns the loader fetching the values of the keys with fetch, the keys not found are absent in its result
+func newLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, fetched: make(map[K]V)}
+}
+
+// prime schedules the keys to be fetched by the next load
+func (l *loader[K, V]) prime(keys ...K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.pending = append(l.pending, keys...)
+}
+
+// load returns the value of the key, the zero value if not found. The pending keys are fetched at once if the key is
+// not fetched yet.
+func (l *loader[K, V]) load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if value, fetched := l.fetched[key]; fetched {
+		return value, nil
+	}
+
+	keySet := map[K]struct{}{key: {}}
+	keys := []K{key}
+	for _, pending := range l.pending {
+		if _, fetched := l.fetched[pending]; fetched {
+			continue
+		}
+		if _, exists := keySet[pending]; !exists {
+			keySet[pending] = struct{}{}
+			keys = append(keys, pending)
+		}
+	}
+	values, err := l.fetch(ctx, keys)
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	l.pending = nil
+	for _, k := range keys {
+		l.fetched[k] = values[k]
+	}
+	return l.fetched[key], nil
+}
+
+// loaders the loaders of one query
+type loaders struct {
+	relayedMsgs *loader[string, *orm.RelayedMsg]
+	l2SentMsgs  *loader[string, *orm.L2SentMsg]
+	batches     *loader[uint64, *orm.RollupBatch]
+	claimInfos  *loader[string, *types.UserClaimInfo]
+}
+
+// newLoaders returns the loaders of one query, the batches of the fetched withdrawals are primed
+func newLoaders(historyLogic *logic.HistoryLogic, db *gorm.DB) *loaders {
+	l := &loaders{}
+	l.relayedMsgs = newLoader(func(ctx context.Context, msgHashes []string) (map[string]*orm.RelayedMsg, error) {
+		relayedMsgs, err := orm.NewRelayedMsg(db).GetRelayedMsgsByHashes(ctx, msgHashes)
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[string]*orm.RelayedMsg, len(relayedMsgs))
+		for _, relayedMsg := range relayedMsgs {
+			result[relayedMsg.MsgHash] = relayedMsg
+		}
+		return result, nil
+	})
+	l.l2SentMsgs = newLoader(func(ctx context.Context, msgHashes []string) (map[string]*orm.L2SentMsg, error) {
+		l2SentMsgs, err := orm.NewL2SentMsg(db).GetL2SentMsgsByHashes(ctx, msgHashes)
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[string]*orm.L2SentMsg, len(l2SentMsgs))
+		for _, l2SentMsg := range l2SentMsgs {
+			result[l2SentMsg.MsgHash] = l2SentMsg
+			if l2SentMsg.BatchIndex != 0 {
+				l.batches.prime(l2SentMsg.BatchIndex)
+			}
+		}
+		return result, nil
+	})
+	l.batches = newLoader(func(ctx context.Context, indexes []uint64) (map[uint64]*orm.RollupBatch, error) {
+		batches, err := orm.NewRollupBatch(db).GetRollupBatchesByIndexes(ctx, indexes)
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[uint64]*orm.RollupBatch, len(batches))
+		for _, batch := range batches {
+			result[batch.BatchIndex] = batch
+		}
+		return result, nil
+	})
+	l.claimInfos = newLoader(func(ctx context.Context, msgHashes []string) (map[string]*types.UserClaimInfo, error) {
+		result := make(map[string]*types.UserClaimInfo, len(msgHashes))
+		for start := 0; start < len(msgHashes); start += maxHashes {
+			end := start + maxHashes
+			if end > len(msgHashes) {
+				end = len(msgHashes)
+			}
+			claimInfos, err := historyLogic.GetClaimInfosByMsgHashes(ctx, msgHashes[start:end])
+			if err != nil {
+				return nil, err
+			}
+			for msgHash, claimInfo := range claimInfos {
+				result[msgHash] = claimInfo
+			}
+		}
+		return result, nil
+	})
+	return l
+}
+
+// loadersKey is the context key of the loaders of the query
+type loadersKey struct{}
+
+// withLoaders returns the context of a query carrying its loaders
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+// loadersFrom returns the loaders of the query
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
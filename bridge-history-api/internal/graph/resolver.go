@@ -0,0 +1,376 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// maxHashes is the upper bound of the hashes or the indexes queried at once by a root field
+const maxHashes = 100
+
+// checkMaxHashes returns ErrInvalidParameter if count exceeds maxHashes
+func checkMaxHashes(count int, name string) error {
+	if count > maxHashes {
+		return fmt.Errorf("%w: %d %s exceed the allowed maximum of %d", logic.ErrInvalidParameter, count, name, maxHashes)
+	}
+	return nil
+}
+
+// graphqlTime returns the graphql Time of t, nil if t is nil
+func graphqlTime(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *t}
+}
+
+// queryResolver resolves the root fields
+type queryResolver struct {
+	db *gorm.DB
+}
+
+// CrossMessages resolves Query.crossMessages
+func (q *queryResolver) CrossMessages(ctx context.Context, args struct{ Hashes []string }) ([]*crossMessageResolver, error) {
+	if err := checkMaxHashes(len(args.Hashes), "hashes"); err != nil {
+		return nil, err
+	}
+	if len(args.Hashes) == 0 {
+		return nil, nil
+	}
+	crossMsgs, err := orm.NewCrossMsg(q.db).GetCrossMsgsByHashes(ctx, args.Hashes, nil, orm.SortDesc)
+	if err != nil {
+		return nil, err
+	}
+	l := loadersFrom(ctx)
+	resolvers := make([]*crossMessageResolver, 0, len(crossMsgs))
+	for _, crossMsg := range crossMsgs {
+		l.relayedMsgs.prime(crossMsg.MsgHash)
+		if crossMsg.MsgType == int(orm.Layer2Msg) {
+			l.l2SentMsgs.prime(crossMsg.MsgHash)
+			l.claimInfos.prime(crossMsg.MsgHash)
+		}
+		resolvers = append(resolvers, &crossMessageResolver{msg: crossMsg})
+	}
+	return resolvers, nil
+}
+
+// RollupBatches resolves Query.rollupBatches
+func (q *queryResolver) RollupBatches(ctx context.Context, args struct{ Indexes []uint64Scalar }) ([]*rollupBatchResolver, error) {
+	if err := checkMaxHashes(len(args.Indexes), "indexes"); err != nil {
+		return nil, err
+	}
+	if len(args.Indexes) == 0 {
+		return nil, nil
+	}
+	indexes := make([]uint64, 0, len(args.Indexes))
+	for _, index := range args.Indexes {
+		indexes = append(indexes, uint64(index))
+	}
+	batches, err := orm.NewRollupBatch(q.db).GetRollupBatchesByIndexes(ctx, indexes)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*rollupBatchResolver, 0, len(batches))
+	for _, batch := range batches {
+		resolvers = append(resolvers, &rollupBatchResolver{batch: batch})
+	}
+	return resolvers, nil
+}
+
+// ClaimInfos resolves Query.claimInfos
+func (q *queryResolver) ClaimInfos(ctx context.Context, args struct{ MsgHashes []string }) ([]*claimInfoResolver, error) {
+	if err := checkMaxHashes(len(args.MsgHashes), "msg hashes"); err != nil {
+		return nil, err
+	}
+	l := loadersFrom(ctx)
+	l.claimInfos.prime(args.MsgHashes...)
+	resolvers := make([]*claimInfoResolver, 0, len(args.MsgHashes))
+	for _, msgHash := range args.MsgHashes {
+		claimInfo, err := l.claimInfos.load(ctx, msgHash)
+		if err != nil {
+			return nil, err
+		}
+		if claimInfo != nil {
+			resolvers = append(resolvers, &claimInfoResolver{msgHash: msgHash, info: claimInfo})
+		}
+	}
+	return resolvers, nil
+}
+
+// crossMessageResolver resolves CrossMessage
+type crossMessageResolver struct {
+	msg *orm.CrossMsg
+}
+
+// MsgHash resolves CrossMessage.msgHash
+func (c *crossMessageResolver) MsgHash() string {
+	return c.msg.MsgHash
+}
+
+// IsL1 resolves CrossMessage.isL1
+func (c *crossMessageResolver) IsL1() bool {
+	return c.msg.MsgType == int(orm.Layer1Msg)
+}
+
+// Height resolves CrossMessage.height
+func (c *crossMessageResolver) Height() uint64Scalar {
+	return uint64Scalar(c.msg.Height)
+}
+
+// Sender resolves CrossMessage.sender
+func (c *crossMessageResolver) Sender() string {
+	return c.msg.Sender
+}
+
+// Target resolves CrossMessage.target
+func (c *crossMessageResolver) Target() string {
+	return c.msg.Target
+}
+
+// Amount resolves CrossMessage.amount
+func (c *crossMessageResolver) Amount() string {
+	return c.msg.Amount
+}
+
+// Asset resolves CrossMessage.asset
+func (c *crossMessageResolver) Asset() string {
+	return orm.AssetType(c.msg.Asset).String()
+}
+
+// Layer1Hash resolves CrossMessage.layer1Hash
+func (c *crossMessageResolver) Layer1Hash() string {
+	return c.msg.Layer1Hash
+}
+
+// Layer2Hash resolves CrossMessage.layer2Hash
+func (c *crossMessageResolver) Layer2Hash() string {
+	return c.msg.Layer2Hash
+}
+
+// Layer1Token resolves CrossMessage.layer1Token
+func (c *crossMessageResolver) Layer1Token() string {
+	return c.msg.Layer1Token
+}
+
+// Layer2Token resolves CrossMessage.layer2Token
+func (c *crossMessageResolver) Layer2Token() string {
+	return c.msg.Layer2Token
+}
+
+// TokenIds resolves CrossMessage.tokenIds
+func (c *crossMessageResolver) TokenIds() []string {
+	return c.msg.TokenIDList()
+}
+
+// TokenAmounts resolves CrossMessage.tokenAmounts
+func (c *crossMessageResolver) TokenAmounts() []string {
+	return c.msg.TokenAmountList()
+}
+
+// BlockTimestamp resolves CrossMessage.blockTimestamp
+func (c *crossMessageResolver) BlockTimestamp() *graphql.Time {
+	return graphqlTime(c.msg.Timestamp)
+}
+
+// RelayedMessage resolves CrossMessage.relayedMessage
+func (c *crossMessageResolver) RelayedMessage(ctx context.Context) (*relayedMessageResolver, error) {
+	relayedMsg, err := loadersFrom(ctx).relayedMsgs.load(ctx, c.msg.MsgHash)
+	if err != nil || relayedMsg == nil {
+		return nil, err
+	}
+	return &relayedMessageResolver{msg: relayedMsg}, nil
+}
+
+// Batch resolves CrossMessage.batch
+func (c *crossMessageResolver) Batch(ctx context.Context) (*rollupBatchResolver, error) {
+	if c.IsL1() {
+		return nil, nil
+	}
+	l := loadersFrom(ctx)
+	l2SentMsg, err := l.l2SentMsgs.load(ctx, c.msg.MsgHash)
+	if err != nil || l2SentMsg == nil || l2SentMsg.BatchIndex == 0 {
+		return nil, err
+	}
+	batch, err := l.batches.load(ctx, l2SentMsg.BatchIndex)
+	// the batch index is stale if the batch is reverted and the message is not committed again yet
+	if err != nil || batch == nil || !batch.ContainsBlock(l2SentMsg.Height) {
+		return nil, err
+	}
+	return &rollupBatchResolver{batch: batch}, nil
+}
+
+// ClaimInfo resolves CrossMessage.claimInfo
+func (c *crossMessageResolver) ClaimInfo(ctx context.Context) (*claimInfoResolver, error) {
+	if c.IsL1() {
+		return nil, nil
+	}
+	claimInfo, err := loadersFrom(ctx).claimInfos.load(ctx, c.msg.MsgHash)
+	if err != nil || claimInfo == nil {
+		return nil, err
+	}
+	return &claimInfoResolver{msgHash: c.msg.MsgHash, info: claimInfo}, nil
+}
+
+// relayedMessageResolver resolves RelayedMessage
+type relayedMessageResolver struct {
+	msg *orm.RelayedMsg
+}
+
+// MsgHash resolves RelayedMessage.msgHash
+func (r *relayedMessageResolver) MsgHash() string {
+	return r.msg.MsgHash
+}
+
+// Height resolves RelayedMessage.height
+func (r *relayedMessageResolver) Height() uint64Scalar {
+	return uint64Scalar(r.msg.Height)
+}
+
+// Layer1Hash resolves RelayedMessage.layer1Hash
+func (r *relayedMessageResolver) Layer1Hash() string {
+	return r.msg.Layer1Hash
+}
+
+// Layer2Hash resolves RelayedMessage.layer2Hash
+func (r *relayedMessageResolver) Layer2Hash() string {
+	return r.msg.Layer2Hash
+}
+
+// BlockTimestamp resolves RelayedMessage.blockTimestamp
+func (r *relayedMessageResolver) BlockTimestamp() *graphql.Time {
+	return graphqlTime(r.msg.Timestamp)
+}
+
+// rollupBatchResolver resolves RollupBatch
+type rollupBatchResolver struct {
+	batch *orm.RollupBatch
+}
+
+// BatchIndex resolves RollupBatch.batchIndex
+func (r *rollupBatchResolver) BatchIndex() uint64Scalar {
+	return uint64Scalar(r.batch.BatchIndex)
+}
+
+// BatchHash resolves RollupBatch.batchHash
+func (r *rollupBatchResolver) BatchHash() string {
+	return r.batch.BatchHash
+}
+
+// CommitHeight resolves RollupBatch.commitHeight
+func (r *rollupBatchResolver) CommitHeight() uint64Scalar {
+	return uint64Scalar(r.batch.CommitHeight)
+}
+
+// CommitTxHash resolves RollupBatch.commitTxHash
+func (r *rollupBatchResolver) CommitTxHash() string {
+	return r.batch.CommitTxHash
+}
+
+// StartBlockNumber resolves RollupBatch.startBlockNumber
+func (r *rollupBatchResolver) StartBlockNumber() uint64Scalar {
+	return uint64Scalar(r.batch.StartBlockNumber)
+}
+
+// EndBlockNumber resolves RollupBatch.endBlockNumber
+func (r *rollupBatchResolver) EndBlockNumber() uint64Scalar {
+	return uint64Scalar(r.batch.EndBlockNumber)
+}
+
+// WithdrawRoot resolves RollupBatch.withdrawRoot
+func (r *rollupBatchResolver) WithdrawRoot() string {
+	return r.batch.WithdrawRoot
+}
+
+// Finalized resolves RollupBatch.finalized
+func (r *rollupBatchResolver) Finalized() bool {
+	return r.batch.IsFinalized()
+}
+
+// FinalizeHeight resolves RollupBatch.finalizeHeight
+func (r *rollupBatchResolver) FinalizeHeight() uint64Scalar {
+	return uint64Scalar(r.batch.FinalizeHeight)
+}
+
+// FinalizeTxHash resolves RollupBatch.finalizeTxHash
+func (r *rollupBatchResolver) FinalizeTxHash() string {
+	return r.batch.FinalizeTxHash
+}
+
+// FinalizeTimestamp resolves RollupBatch.finalizeTimestamp
+func (r *rollupBatchResolver) FinalizeTimestamp() *graphql.Time {
+	return graphqlTime(r.batch.FinalizeTimestamp)
+}
+
+// claimInfoResolver resolves ClaimInfo
+type claimInfoResolver struct {
+	msgHash string
+	info    *types.UserClaimInfo
+}
+
+// MsgHash resolves ClaimInfo.msgHash
+func (c *claimInfoResolver) MsgHash() string {
+	return c.msgHash
+}
+
+// From resolves ClaimInfo.from
+func (c *claimInfoResolver) From() string {
+	return c.info.From
+}
+
+// To resolves ClaimInfo.to
+func (c *claimInfoResolver) To() string {
+	return c.info.To
+}
+
+// Value resolves ClaimInfo.value
+func (c *claimInfoResolver) Value() string {
+	return c.info.Value
+}
+
+// Nonce resolves ClaimInfo.nonce
+func (c *claimInfoResolver) Nonce() string {
+	return c.info.Nonce
+}
+
+// Message resolves ClaimInfo.message
+func (c *claimInfoResolver) Message() string {
+	return c.info.Message
+}
+
+// Proof resolves ClaimInfo.proof
+func (c *claimInfoResolver) Proof() string {
+	return c.info.Proof
+}
+
+// BatchHash resolves ClaimInfo.batchHash
+func (c *claimInfoResolver) BatchHash() string {
+	return c.info.BatchHash
+}
+
+// BatchIndex resolves ClaimInfo.batchIndex
+func (c *claimInfoResolver) BatchIndex() string {
+	return c.info.BatchIndex
+}
+
+// EstimatedGas resolves ClaimInfo.estimatedGas
+func (c *claimInfoResolver) EstimatedGas() uint64Scalar {
+	return uint64Scalar(c.info.EstimatedGas)
+}
+
+// ClaimKey resolves ClaimInfo.claimKey
+func (c *claimInfoResolver) ClaimKey() string {
+	return c.info.ClaimKey
+}
+
+// ClaimExpiresAt resolves ClaimInfo.claimExpiresAt
+func (c *claimInfoResolver) ClaimExpiresAt() *graphql.Time {
+	return graphqlTime(c.info.ClaimExpiresAt)
+}
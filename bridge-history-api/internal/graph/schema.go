@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"context"
+	// embed the graphql schema
+	_ "embed"
+	"fmt"
+	"strconv"
+
+	"github.com/graph-gophers/graphql-go"
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/logic"
+)
+
+//go:embed schema.graphql
+var schemaString string
+
+// Schema is the executable graphql schema of the cross messages, the relayed messages, the rollup batches and the
+// claim infos
+type Schema struct {
+	schema       *graphql.Schema
+	historyLogic *logic.HistoryLogic
+	db           *gorm.DB
+}
+
+// NewSchema returns the Schema resolved by the history logic and the orm of db
+func NewSchema(historyLogic *logic.HistoryLogic, db *gorm.DB) *Schema {
+	return &Schema{
+		schema:       graphql.MustParseSchema(schemaString, &queryResolver{db: db}),
+		historyLogic: historyLogic,
+		db:           db,
+	}
+}
+
+// Exec runs the query, the lookups of the nested fields are batched per query
+func (s *Schema) Exec(ctx context.Context, query, operationName string, variables map[string]interface{}) *graphql.Response {
+	return s.schema.Exec(withLoaders(ctx, newLoaders(s.historyLogic, s.db)), query, operationName, variables)
+}
+
+// uint64Scalar is the Uint64 scalar, graphql Int is 32 bits only
+type uint64Scalar uint64
+
+// ImplementsGraphQLType maps the type to the Uint64 scalar of the schema
+func (uint64Scalar) ImplementsGraphQLType(name string) bool {
+	return name == "Uint64"
+}
+
+// UnmarshalGraphQL parses the Uint64 input, either a number or a decimal string
+func (u *uint64Scalar) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case int32:
+		if input < 0 {
+			return fmt.Errorf("invalid Uint64: %d", input)
+		}
+		*u = uint64Scalar(input)
+	case float64:
+		if input < 0 || input != float64(uint64(input)) {
+			return fmt.Errorf("invalid Uint64: %v", input)
+		}
+		*u = uint64Scalar(input)
+	case string:
+		value, err := strconv.ParseUint(input, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid Uint64: %w", err)
+		}
+		*u = uint64Scalar(value)
+	default:
+		return fmt.Errorf("invalid Uint64 type: %T", input)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/orm"
+)
+
+// tableCounter counts the queries of each table and answers them with the fixture rows of the table
+type tableCounter struct {
+	mu       sync.Mutex
+	calls    map[string]int
+	fixtures map[string]interface{}
+}
+
+func (c *tableCounter) onQuery(db *gorm.DB) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[db.Statement.Table]++
+	fixture, ok := c.fixtures[db.Statement.Table]
+	if !ok {
+		return
+	}
+	rows := reflect.ValueOf(fixture)
+	dest := reflect.ValueOf(db.Statement.Dest).Elem()
+	if rows.Type().AssignableTo(dest.Type()) {
+		dest.Set(rows)
+		db.RowsAffected = int64(rows.Len())
+	}
+}
+
+// newTestSchema returns the Schema of a dry run db answering the queries from the fixtures keyed by the table name
+func newTestSchema(t *testing.T, fixtures map[string]interface{}) (*Schema, *tableCounter) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter := &tableCounter{calls: make(map[string]int), fixtures: fixtures}
+	if err = db.Callback().Query().After("gorm:query").Register("test:count_table_queries", counter.onQuery); err != nil {
+		t.Fatal(err)
+	}
+	return NewSchema(logic.NewHistoryLogic(nil, db, nil, prometheus.NewRegistry()), db), counter
+}
+
+// withdrawalFixtures returns the fixtures of count withdrawals committed in the same batch
+func withdrawalFixtures(count int) map[string]interface{} {
+	var crossMsgs []*orm.CrossMsg
+	var l2SentMsgs []*orm.L2SentMsg
+	var relayedMsgs []*orm.RelayedMsg
+	for i := 0; i < count; i++ {
+		msgHash := "0x" + string(rune('a'+i))
+		crossMsgs = append(crossMsgs, &orm.CrossMsg{MsgHash: msgHash, Layer2Hash: "0x01", MsgType: int(orm.Layer2Msg), Amount: "1", Height: 95})
+		l2SentMsgs = append(l2SentMsgs, &orm.L2SentMsg{MsgHash: msgHash, TxHash: "0x01", Height: 95, BatchIndex: 1, MsgProof: "abcd"})
+		relayedMsgs = append(relayedMsgs, &orm.RelayedMsg{MsgHash: msgHash, Layer1Hash: "0x02", Height: 20})
+	}
+	return map[string]interface{}{
+		(&orm.CrossMsg{}).TableName():   crossMsgs,
+		(&orm.L2SentMsg{}).TableName():  l2SentMsgs,
+		(&orm.RelayedMsg{}).TableName(): relayedMsgs,
+		(&orm.RollupBatch{}).TableName(): []*orm.RollupBatch{
+			{BatchIndex: 1, BatchHash: "0x03", StartBlockNumber: 90, EndBlockNumber: 99, FinalizeHeight: 15, FinalizeTxHash: "0x04"},
+		},
+	}
+}
+
+const crossMessagesQuery = `query($hashes: [String!]!) {
+	crossMessages(hashes: $hashes) {
+		msgHash
+		height
+		tokenIds
+		relayedMessage { layer1Hash }
+		batch { batchIndex finalizeTxHash }
+		claimInfo { proof }
+	}
+}`
+
+func TestCrossMessages(t *testing.T) {
+	schema, _ := newTestSchema(t, withdrawalFixtures(1))
+	response := schema.Exec(context.Background(), crossMessagesQuery, "", map[string]interface{}{"hashes": []interface{}{"0x01"}})
+	assert.Empty(t, response.Errors)
+
+	var data struct {
+		CrossMessages []struct {
+			MsgHash        string
+			Height         uint64
+			TokenIds       []string
+			RelayedMessage *struct{ Layer1Hash string }
+			Batch          *struct {
+				BatchIndex     uint64
+				FinalizeTxHash string
+			}
+		}
+	}
+	assert.NoError(t, json.Unmarshal(response.Data, &data))
+	assert.Len(t, data.CrossMessages, 1)
+	assert.Equal(t, "0xa", data.CrossMessages[0].MsgHash)
+	assert.Equal(t, uint64(95), data.CrossMessages[0].Height)
+	assert.Empty(t, data.CrossMessages[0].TokenIds)
+	assert.Equal(t, "0x02", data.CrossMessages[0].RelayedMessage.Layer1Hash)
+	assert.Equal(t, uint64(1), data.CrossMessages[0].Batch.BatchIndex)
+	assert.Equal(t, "0x04", data.CrossMessages[0].Batch.FinalizeTxHash)
+}
+
+func TestCrossMessagesBatchesNestedFields(t *testing.T) {
+	schema, single := newTestSchema(t, withdrawalFixtures(1))
+	response := schema.Exec(context.Background(), crossMessagesQuery, "", map[string]interface{}{"hashes": []interface{}{"0x01"}})
+	assert.Empty(t, response.Errors)
+
+	schema, many := newTestSchema(t, withdrawalFixtures(5))
+	response = schema.Exec(context.Background(), crossMessagesQuery, "", map[string]interface{}{"hashes": []interface{}{"0x01"}})
+	assert.Empty(t, response.Errors)
+
+	// the nested fields of the items cost the same queries no matter how many items there are
+	assert.NotZero(t, single.calls[(&orm.RelayedMsg{}).TableName()])
+	assert.Equal(t, single.calls, many.calls)
+}
+
+func TestRootFieldsMaxHashes(t *testing.T) {
+	schema, counter := newTestSchema(t, nil)
+	hashes := make([]interface{}, maxHashes+1)
+	for i := range hashes {
+		hashes[i] = "0x01"
+	}
+	response := schema.Exec(context.Background(), `query($hashes: [String!]!) { claimInfos(msgHashes: $hashes) { proof } }`, "", map[string]interface{}{"hashes": hashes})
+	assert.Len(t, response.Errors, 1)
+	assert.Empty(t, counter.calls)
+}
+
+func TestUint64Scalar(t *testing.T) {
+	var u uint64Scalar
+	assert.NoError(t, u.UnmarshalGraphQL(int32(7)))
+	assert.Equal(t, uint64Scalar(7), u)
+	assert.NoError(t, u.UnmarshalGraphQL("18446744073709551615"))
+	assert.Equal(t, uint64Scalar(18446744073709551615), u)
+	assert.Error(t, u.UnmarshalGraphQL(int32(-1)))
+	assert.Error(t, u.UnmarshalGraphQL(1.5))
+	assert.Error(t, u.UnmarshalGraphQL(true))
+}
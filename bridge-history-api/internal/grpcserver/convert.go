@@ -0,0 +1,135 @@
+package grpcserver
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"bridge-history-api/internal/historypb"
+	"bridge-history-api/internal/types"
+)
+
+// timestamp returns the timestamp of t, nil if t is nil
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func txHistoryInfos(txHistories []*types.TxHistoryInfo) []*historypb.TxHistoryInfo {
+	messages := make([]*historypb.TxHistoryInfo, 0, len(txHistories))
+	for _, txHistory := range txHistories {
+		messages = append(messages, txHistoryInfoMessage(txHistory))
+	}
+	return messages
+}
+
+func txHistoryInfoMessage(txHistory *types.TxHistoryInfo) *historypb.TxHistoryInfo {
+	message := &historypb.TxHistoryInfo{
+		Hash:                    txHistory.Hash,
+		MsgHash:                 txHistory.MsgHash,
+		Amount:                  txHistory.Amount,
+		GasFee:                  txHistory.GasFee,
+		BridgeFee:               txHistory.BridgeFee,
+		IsL1:                    txHistory.IsL1,
+		L1Token:                 txHistory.L1Token,
+		L2Token:                 txHistory.L2Token,
+		TokenType:               string(txHistory.TokenType),
+		TokenIds:                txHistory.TokenIDs,
+		TokenAmounts:            txHistory.TokenAmounts,
+		TokenSymbol:             txHistory.TokenSymbol,
+		FormattedAmount:         txHistory.FormattedAmount,
+		BlockNumber:             txHistory.BlockNumber,
+		BlockTimestamp:          timestamp(txHistory.BlockTimestamp),
+		L1BlockHash:             txHistory.L1BlockHash,
+		OriginMethod:            txHistory.OriginMethod,
+		OriginTxNonce:           txHistory.OriginTxNonce,
+		L1FinalityStatus:        string(txHistory.L1FinalityStatus),
+		Replayed:                txHistory.Replayed,
+		ReplayTxHash:            txHistory.ReplayTxHash,
+		DropTxHash:              txHistory.DropTxHash,
+		Refunded:                txHistory.Refunded,
+		RefundTxHash:            txHistory.RefundTxHash,
+		ToLabel:                 txHistory.ToLabel,
+		Delivered:               txHistory.Delivered,
+		ClaimStatus:             historypb.ClaimStatus(txHistory.ClaimStatus),
+		GlobalWithdrawalIndex:   txHistory.GlobalWithdrawalIndex,
+		MessageIndexInBlock:     txHistory.MessageIndexInBlock,
+		BatchReverted:           txHistory.BatchReverted,
+		RebatchedIndex:          txHistory.RebatchedIndex,
+		OperationType:           string(txHistory.OperationType),
+		Status:                  string(txHistory.Status),
+		RequiresManualExecution: txHistory.RequiresManualExecution,
+		CreatedAt:               timestamp(txHistory.CreatedAt),
+		DataCompleteness:        uint32(txHistory.DataCompleteness),
+	}
+	if txHistory.TokenDecimals != nil {
+		decimals := uint32(*txHistory.TokenDecimals)
+		message.TokenDecimals = &decimals
+	}
+	if finalizeTx := txHistory.FinalizeTx; finalizeTx != nil {
+		message.FinalizeTx = &historypb.Finalized{
+			Hash:           finalizeTx.Hash,
+			Amount:         finalizeTx.Amount,
+			IsL1:           finalizeTx.IsL1,
+			BlockNumber:    finalizeTx.BlockNumber,
+			BlockTimestamp: timestamp(finalizeTx.BlockTimestamp),
+			GasFee:         finalizeTx.GasFee,
+		}
+	}
+	if txHistory.ClaimInfo != nil {
+		message.ClaimInfo = claimInfoMessage(txHistory.ClaimInfo)
+	}
+	if executeParams := txHistory.ExecuteParams; executeParams != nil {
+		message.ExecuteParams = &historypb.ExecuteParams{
+			From:      executeParams.From,
+			To:        executeParams.To,
+			Value:     executeParams.Value,
+			Nonce:     executeParams.Nonce,
+			Message:   executeParams.Message,
+			BlockHash: executeParams.BlockHash,
+		}
+	}
+	if route := txHistory.Route; route != nil {
+		message.Route = &historypb.Route{
+			L1Gateway:   route.L1Gateway,
+			L1Messenger: route.L1Messenger,
+			L2Messenger: route.L2Messenger,
+			L2Gateway:   route.L2Gateway,
+		}
+	}
+	return message
+}
+
+func claimInfoMessage(claimInfo *types.UserClaimInfo) *historypb.ClaimInfo {
+	return &historypb.ClaimInfo{
+		From:           claimInfo.From,
+		To:             claimInfo.To,
+		Value:          claimInfo.Value,
+		Nonce:          claimInfo.Nonce,
+		BatchHash:      claimInfo.BatchHash,
+		Message:        claimInfo.Message,
+		Proof:          claimInfo.Proof,
+		BatchIndex:     claimInfo.BatchIndex,
+		StateRoot:      claimInfo.StateRoot,
+		Claimable:      claimInfo.Claimable,
+		ProofPruned:    claimInfo.ProofPruned,
+		EstimatedGas:   claimInfo.EstimatedGas,
+		ClaimKey:       claimInfo.ClaimKey,
+		ClaimExpiresAt: timestamp(claimInfo.ClaimExpiresAt),
+		FromLabel:      claimInfo.FromLabel,
+		ToLabel:        claimInfo.ToLabel,
+	}
+}
+
+func batchInfoMessage(batchInfo *types.BatchInfo) *historypb.BatchInfo {
+	return &historypb.BatchInfo{
+		BatchIndex:     batchInfo.BatchIndex,
+		BatchHash:      batchInfo.BatchHash,
+		WithdrawRoot:   batchInfo.WithdrawRoot,
+		StateRoot:      batchInfo.StateRoot,
+		TreeLeafCount:  batchInfo.TreeLeafCount,
+		IsGenesisBatch: batchInfo.IsGenesisBatch,
+	}
+}
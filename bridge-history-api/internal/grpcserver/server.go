@@ -0,0 +1,163 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+
+	"bridge-history-api/internal/historypb"
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/internal/types"
+)
+
+// networkMetadata is the metadata key of the calls selecting the network served, the top level network of the config
+// if absent
+const networkMetadata = "network"
+
+// maxTxHashes is the upper bound of the tx hashes queried at once, the same as the http api
+const maxTxHashes = 10
+
+// HistoryService the history logic of one network served over grpc
+type HistoryService struct {
+	historyLogic *logic.HistoryLogic
+	batchLogic   *logic.BatchLogic
+}
+
+// NewHistoryService returns the HistoryService of the logic of a network
+func NewHistoryService(historyLogic *logic.HistoryLogic, batchLogic *logic.BatchLogic) *HistoryService {
+	return &HistoryService{historyLogic: historyLogic, batchLogic: batchLogic}
+}
+
+// NewServer returns the grpc server of the HistoryService of the network of each call, networks are keyed by the
+// network name. The reflection service is registered for the tooling, e.g. grpcurl.
+func NewServer(defaultNetwork string, networks map[string]*HistoryService) *grpc.Server {
+	server := grpc.NewServer()
+	historypb.RegisterHistoryServiceServer(server, &networkServer{networks: networks, defaultNetwork: defaultNetwork})
+	reflection.Register(server)
+	return server
+}
+
+// networkServer dispatches the calls to the HistoryService of the network of the call metadata
+type networkServer struct {
+	historypb.UnimplementedHistoryServiceServer
+	networks       map[string]*HistoryService
+	defaultNetwork string
+}
+
+func (s *networkServer) network(ctx context.Context) (*HistoryService, error) {
+	network := s.defaultNetwork
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(networkMetadata); len(values) != 0 && values[0] != "" {
+			network = values[0]
+		}
+	}
+	service, ok := s.networks[network]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown network %q", network)
+	}
+	return service, nil
+}
+
+// GetTxsByHashes implements HistoryServiceServer
+func (s *networkServer) GetTxsByHashes(ctx context.Context, req *historypb.GetTxsByHashesRequest) (*historypb.GetTxsByHashesResponse, error) {
+	service, err := s.network(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Hashes) > maxTxHashes {
+		return nil, status.Errorf(codes.InvalidArgument, "the number of hashes in the request exceeds the allowed maximum of %d", maxTxHashes)
+	}
+	txs, err := service.historyLogic.GetTxsByHashes(ctx, req.Hashes, types.TokenTypeAll, types.SortOrderDesc)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &historypb.GetTxsByHashesResponse{Txs: txHistoryInfos(txs)}, nil
+}
+
+// GetClaimableTxs implements HistoryServiceServer
+func (s *networkServer) GetClaimableTxs(ctx context.Context, req *historypb.GetClaimableTxsRequest) (*historypb.GetClaimableTxsResponse, error) {
+	service, err := s.network(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !common.IsHexAddress(req.Address) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid address %q", req.Address)
+	}
+	pagination := types.Pagination{Page: req.Page, PageSize: req.PageSize}
+	txs, total, err := service.historyLogic.GetClaimableTxsByAddressPaged(ctx, common.HexToAddress(req.Address), types.TokenTypeAll, pagination)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &historypb.GetClaimableTxsResponse{Txs: txHistoryInfos(txs), Total: total}, nil
+}
+
+// GetClaimInfos implements HistoryServiceServer
+func (s *networkServer) GetClaimInfos(ctx context.Context, req *historypb.GetClaimInfosRequest) (*historypb.GetClaimInfosResponse, error) {
+	service, err := s.network(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claimInfos, err := service.historyLogic.GetClaimInfosByMsgHashes(ctx, req.MsgHashes)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	resp := &historypb.GetClaimInfosResponse{ClaimInfos: make(map[string]*historypb.ClaimInfo, len(claimInfos))}
+	for msgHash, claimInfo := range claimInfos {
+		resp.ClaimInfos[msgHash] = claimInfoMessage(claimInfo)
+	}
+	return resp, nil
+}
+
+// GetWithdrawProof implements HistoryServiceServer
+func (s *networkServer) GetWithdrawProof(ctx context.Context, req *historypb.GetWithdrawProofRequest) (*historypb.ClaimInfo, error) {
+	service, err := s.network(ctx)
+	if err != nil {
+		return nil, err
+	}
+	claimInfo, err := service.historyLogic.GetWithdrawProof(ctx, req.Nonce)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return claimInfoMessage(claimInfo), nil
+}
+
+// GetBatchInfo implements HistoryServiceServer
+func (s *networkServer) GetBatchInfo(ctx context.Context, req *historypb.GetBatchInfoRequest) (*historypb.BatchInfo, error) {
+	service, err := s.network(ctx)
+	if err != nil {
+		return nil, err
+	}
+	batchInfo, err := service.batchLogic.GetBatchInfoByBatchIndex(ctx, req.BatchIndex)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	if batchInfo == nil {
+		return nil, status.Errorf(codes.NotFound, "batch %d not found", req.BatchIndex)
+	}
+	return batchInfoMessage(batchInfo), nil
+}
+
+// statusError returns the grpc status of the error returned by the logic, the errors are classified the way the http
+// apis render them
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, logic.ErrInvalidParameter):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, logic.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, logic.ErrDatabase):
+		return status.Error(codes.Internal, err.Error())
+	default:
+		return status.Error(codes.Unknown, err.Error())
+	}
+}
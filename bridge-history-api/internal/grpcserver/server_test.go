@@ -0,0 +1,111 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"bridge-history-api/internal/historypb"
+	"bridge-history-api/internal/logic"
+	"bridge-history-api/internal/types"
+)
+
+// dialTestServer serves the networks on an in-memory listener and returns a connection to it
+func dialTestServer(t *testing.T, networks map[string]*HistoryService) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	server := NewServer("mainnet", networks)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func TestServerNetworks(t *testing.T) {
+	conn := dialTestServer(t, map[string]*HistoryService{"mainnet": {}, "sepolia": {}})
+	client := historypb.NewHistoryServiceClient(conn)
+
+	// the hashes are validated before the logic of the network is queried
+	hashes := make([]string, maxTxHashes+1)
+	for i := range hashes {
+		hashes[i] = fmt.Sprintf("0x%02x", i)
+	}
+	_, err := client.GetTxsByHashes(context.Background(), &historypb.GetTxsByHashesRequest{Hashes: hashes})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), networkMetadata, "sepolia")
+	_, err = client.GetClaimableTxs(ctx, &historypb.GetClaimableTxsRequest{Address: "invalid"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), "invalid address")
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), networkMetadata, "holesky")
+	_, err = client.GetBatchInfo(ctx, &historypb.GetBatchInfoRequest{BatchIndex: 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, err.Error(), `unknown network "holesky"`)
+}
+
+func TestServerReflection(t *testing.T) {
+	conn := dialTestServer(t, map[string]*HistoryService{"mainnet": {}})
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	assert.NoError(t, err)
+	assert.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	resp, err := stream.Recv()
+	assert.NoError(t, err)
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.Name)
+	}
+	assert.Contains(t, services, "bridgehistory.v1.HistoryService")
+}
+
+func TestStatusError(t *testing.T) {
+	assert.Equal(t, codes.InvalidArgument, status.Code(statusError(fmt.Errorf("%w: bad page", logic.ErrInvalidParameter))))
+	assert.Equal(t, codes.NotFound, status.Code(statusError(fmt.Errorf("%w: no withdrawal", logic.ErrNotFound))))
+	assert.Equal(t, codes.Internal, status.Code(statusError(logic.ErrDatabase)))
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(statusError(fmt.Errorf("query: %w", context.DeadlineExceeded))))
+	assert.Equal(t, codes.Unknown, status.Code(statusError(fmt.Errorf("estimate failed"))))
+}
+
+func TestTxHistoryInfoMessage(t *testing.T) {
+	blockTime := time.Unix(1700000000, 0).UTC()
+	decimals, nonce := uint8(18), uint64(3)
+	message := txHistoryInfoMessage(&types.TxHistoryInfo{
+		Hash:           "0x01",
+		TokenDecimals:  &decimals,
+		BlockTimestamp: &blockTime,
+		FinalizeTx:     &types.Finalized{Hash: "0x02"},
+		ClaimInfo:      &types.UserClaimInfo{Proof: "0x03", Claimable: true},
+		ClaimStatus:    types.ClaimStatusClaimable,
+		RebatchedIndex: &nonce,
+		Status:         types.TxStatusClaimable,
+	})
+	assert.Equal(t, "0x01", message.Hash)
+	assert.Equal(t, uint32(18), message.GetTokenDecimals())
+	assert.Equal(t, blockTime, message.BlockTimestamp.AsTime())
+	assert.Equal(t, "0x02", message.FinalizeTx.Hash)
+	assert.Equal(t, "0x03", message.ClaimInfo.Proof)
+	assert.True(t, message.ClaimInfo.Claimable)
+	assert.Equal(t, historypb.ClaimStatus_CLAIM_STATUS_CLAIMABLE, message.ClaimStatus)
+	assert.Equal(t, uint64(3), message.GetRebatchedIndex())
+	assert.Equal(t, "Claimable", message.Status)
+	// the absent fields stay absent
+	assert.Nil(t, message.CreatedAt)
+	assert.Nil(t, message.OriginTxNonce)
+	assert.Nil(t, message.ExecuteParams)
+}
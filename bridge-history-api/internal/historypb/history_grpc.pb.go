@@ -0,0 +1,267 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.23.4
+// source: history.proto
+
+package historypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	HistoryService_GetTxsByHashes_FullMethodName   = "/bridgehistory.v1.HistoryService/GetTxsByHashes"
+	HistoryService_GetClaimableTxs_FullMethodName  = "/bridgehistory.v1.HistoryService/GetClaimableTxs"
+	HistoryService_GetClaimInfos_FullMethodName    = "/bridgehistory.v1.HistoryService/GetClaimInfos"
+	HistoryService_GetWithdrawProof_FullMethodName = "/bridgehistory.v1.HistoryService/GetWithdrawProof"
+	HistoryService_GetBatchInfo_FullMethodName     = "/bridgehistory.v1.HistoryService/GetBatchInfo"
+)
+
+// HistoryServiceClient is the client API for HistoryService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type HistoryServiceClient interface {
+	// GetTxsByHashes returns the txs of the hashes, at most 10 hashes
+	GetTxsByHashes(ctx context.Context, in *GetTxsByHashesRequest, opts ...grpc.CallOption) (*GetTxsByHashesResponse, error)
+	// GetClaimableTxs returns the claimable withdrawals of the address, page starts from 1
+	GetClaimableTxs(ctx context.Context, in *GetClaimableTxsRequest, opts ...grpc.CallOption) (*GetClaimableTxsResponse, error)
+	// GetClaimInfos returns the claim infos of the withdrawals of the msg hashes, at most 100 hashes
+	GetClaimInfos(ctx context.Context, in *GetClaimInfosRequest, opts ...grpc.CallOption) (*GetClaimInfosResponse, error)
+	// GetWithdrawProof returns the claim info of the withdrawal of the nonce with its proof regenerated
+	GetWithdrawProof(ctx context.Context, in *GetWithdrawProofRequest, opts ...grpc.CallOption) (*ClaimInfo, error)
+	// GetBatchInfo returns the info of the batch of the index
+	GetBatchInfo(ctx context.Context, in *GetBatchInfoRequest, opts ...grpc.CallOption) (*BatchInfo, error)
+}
+
+type historyServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHistoryServiceClient(cc grpc.ClientConnInterface) HistoryServiceClient {
+	return &historyServiceClient{cc}
+}
+
+func (c *historyServiceClient) GetTxsByHashes(ctx context.Context, in *GetTxsByHashesRequest, opts ...grpc.CallOption) (*GetTxsByHashesResponse, error) {
+	out := new(GetTxsByHashesResponse)
+	err := c.cc.Invoke(ctx, HistoryService_GetTxsByHashes_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *historyServiceClient) GetClaimableTxs(ctx context.Context, in *GetClaimableTxsRequest, opts ...grpc.CallOption) (*GetClaimableTxsResponse, error) {
+	out := new(GetClaimableTxsResponse)
+	err := c.cc.Invoke(ctx, HistoryService_GetClaimableTxs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *historyServiceClient) GetClaimInfos(ctx context.Context, in *GetClaimInfosRequest, opts ...grpc.CallOption) (*GetClaimInfosResponse, error) {
+	out := new(GetClaimInfosResponse)
+	err := c.cc.Invoke(ctx, HistoryService_GetClaimInfos_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *historyServiceClient) GetWithdrawProof(ctx context.Context, in *GetWithdrawProofRequest, opts ...grpc.CallOption) (*ClaimInfo, error) {
+	out := new(ClaimInfo)
+	err := c.cc.Invoke(ctx, HistoryService_GetWithdrawProof_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *historyServiceClient) GetBatchInfo(ctx context.Context, in *GetBatchInfoRequest, opts ...grpc.CallOption) (*BatchInfo, error) {
+	out := new(BatchInfo)
+	err := c.cc.Invoke(ctx, HistoryService_GetBatchInfo_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HistoryServiceServer is the server API for HistoryService service.
+// All implementations must embed UnimplementedHistoryServiceServer
+// for forward compatibility
+type HistoryServiceServer interface {
+	// GetTxsByHashes returns the txs of the hashes, at most 10 hashes
+	GetTxsByHashes(context.Context, *GetTxsByHashesRequest) (*GetTxsByHashesResponse, error)
+	// GetClaimableTxs returns the claimable withdrawals of the address, page starts from 1
+	GetClaimableTxs(context.Context, *GetClaimableTxsRequest) (*GetClaimableTxsResponse, error)
+	// GetClaimInfos returns the claim infos of the withdrawals of the msg hashes, at most 100 hashes
+	GetClaimInfos(context.Context, *GetClaimInfosRequest) (*GetClaimInfosResponse, error)
+	// GetWithdrawProof returns the claim info of the withdrawal of the nonce with its proof regenerated
+	GetWithdrawProof(context.Context, *GetWithdrawProofRequest) (*ClaimInfo, error)
+	// GetBatchInfo returns the info of the batch of the index
+	GetBatchInfo(context.Context, *GetBatchInfoRequest) (*BatchInfo, error)
+	mustEmbedUnimplementedHistoryServiceServer()
+}
+
+// UnimplementedHistoryServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedHistoryServiceServer struct {
+}
+
+func (UnimplementedHistoryServiceServer) GetTxsByHashes(context.Context, *GetTxsByHashesRequest) (*GetTxsByHashesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTxsByHashes not implemented")
+}
+func (UnimplementedHistoryServiceServer) GetClaimableTxs(context.Context, *GetClaimableTxsRequest) (*GetClaimableTxsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClaimableTxs not implemented")
+}
+func (UnimplementedHistoryServiceServer) GetClaimInfos(context.Context, *GetClaimInfosRequest) (*GetClaimInfosResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClaimInfos not implemented")
+}
+func (UnimplementedHistoryServiceServer) GetWithdrawProof(context.Context, *GetWithdrawProofRequest) (*ClaimInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWithdrawProof not implemented")
+}
+func (UnimplementedHistoryServiceServer) GetBatchInfo(context.Context, *GetBatchInfoRequest) (*BatchInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBatchInfo not implemented")
+}
+func (UnimplementedHistoryServiceServer) mustEmbedUnimplementedHistoryServiceServer() {}
+
+// UnsafeHistoryServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to HistoryServiceServer will
+// result in compilation errors.
+type UnsafeHistoryServiceServer interface {
+	mustEmbedUnimplementedHistoryServiceServer()
+}
+
+func RegisterHistoryServiceServer(s grpc.ServiceRegistrar, srv HistoryServiceServer) {
+	s.RegisterService(&HistoryService_ServiceDesc, srv)
+}
+
+func _HistoryService_GetTxsByHashes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTxsByHashesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HistoryServiceServer).GetTxsByHashes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HistoryService_GetTxsByHashes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HistoryServiceServer).GetTxsByHashes(ctx, req.(*GetTxsByHashesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HistoryService_GetClaimableTxs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClaimableTxsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HistoryServiceServer).GetClaimableTxs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HistoryService_GetClaimableTxs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HistoryServiceServer).GetClaimableTxs(ctx, req.(*GetClaimableTxsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HistoryService_GetClaimInfos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClaimInfosRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HistoryServiceServer).GetClaimInfos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HistoryService_GetClaimInfos_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HistoryServiceServer).GetClaimInfos(ctx, req.(*GetClaimInfosRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HistoryService_GetWithdrawProof_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWithdrawProofRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HistoryServiceServer).GetWithdrawProof(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HistoryService_GetWithdrawProof_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HistoryServiceServer).GetWithdrawProof(ctx, req.(*GetWithdrawProofRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HistoryService_GetBatchInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBatchInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HistoryServiceServer).GetBatchInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HistoryService_GetBatchInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HistoryServiceServer).GetBatchInfo(ctx, req.(*GetBatchInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// HistoryService_ServiceDesc is the grpc.ServiceDesc for HistoryService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var HistoryService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bridgehistory.v1.HistoryService",
+	HandlerType: (*HistoryServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetTxsByHashes",
+			Handler:    _HistoryService_GetTxsByHashes_Handler,
+		},
+		{
+			MethodName: "GetClaimableTxs",
+			Handler:    _HistoryService_GetClaimableTxs_Handler,
+		},
+		{
+			MethodName: "GetClaimInfos",
+			Handler:    _HistoryService_GetClaimInfos_Handler,
+		},
+		{
+			MethodName: "GetWithdrawProof",
+			Handler:    _HistoryService_GetWithdrawProof_Handler,
+		},
+		{
+			MethodName: "GetBatchInfo",
+			Handler:    _HistoryService_GetBatchInfo_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "history.proto",
+}
@@ -0,0 +1,92 @@
+package logic
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// addressBookRefreshInterval is the interval the labels are reloaded from the db
+const addressBookRefreshInterval = time.Minute
+
+// addressBook caches the labels of the address_label table keyed by the checksummed address, the labels are reloaded
+// once stale in the background of the query noticing it, the queries keep using the stale labels meanwhile
+type addressBook struct {
+	load func(ctx context.Context) ([]*orm.AddressLabel, error)
+
+	mu       sync.RWMutex
+	labels   map[string]string
+	loaded   bool
+	loadedAt time.Time
+	loading  bool
+}
+
+func newAddressBook(load func(ctx context.Context) ([]*orm.AddressLabel, error)) *addressBook {
+	return &addressBook{load: load}
+}
+
+// lookup returns the labels of the addresses, the labels due are reloaded by one of the queries, synchronously until
+// loaded once and in the background after
+func (b *addressBook) lookup(ctx context.Context, now time.Time) map[string]string {
+	b.mu.Lock()
+	loaded := b.loaded
+	reload := !b.loading && now.Sub(b.loadedAt) >= addressBookRefreshInterval
+	if reload {
+		b.loading = true
+	}
+	b.mu.Unlock()
+	if reload {
+		if loaded {
+			go b.reload(context.Background(), now)
+		} else {
+			b.reload(ctx, now)
+		}
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.labels
+}
+
+// reload replaces the labels by the labels of the db, the labels are kept if the loading fails and retried after the
+// refresh interval
+func (b *addressBook) reload(ctx context.Context, now time.Time) {
+	addressLabels, err := b.load(ctx)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.loading = false
+	b.loadedAt = now
+	if err != nil {
+		log.Error("failed to load the address labels", "err", err)
+		return
+	}
+	labels := make(map[string]string, len(addressLabels))
+	for _, addressLabel := range addressLabels {
+		labels[normalizeAddress(addressLabel.Address)] = addressLabel.Label
+	}
+	b.labels, b.loaded = labels, true
+}
+
+// updateAddressLabels annotates the recipients of the tx histories and the senders and the recipients of their claim
+// infos with the labels of the address book if enabled, the addresses without labels are left unlabeled
+func (h *HistoryLogic) updateAddressLabels(ctx context.Context, txHistories []*types.TxHistoryInfo) {
+	if h.addressBook == nil || len(txHistories) == 0 {
+		return
+	}
+	labels := h.addressBook.lookup(ctx, time.Now())
+	if len(labels) == 0 {
+		return
+	}
+	for _, txHistory := range txHistories {
+		txHistory.ToLabel = labels[normalizeAddress(txHistory.To)]
+		if txHistory.ClaimInfo != nil {
+			txHistory.ClaimInfo.FromLabel = labels[normalizeAddress(txHistory.ClaimInfo.From)]
+			txHistory.ClaimInfo.ToLabel = labels[normalizeAddress(txHistory.ClaimInfo.To)]
+		}
+	}
+}
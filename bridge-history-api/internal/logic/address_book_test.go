@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestUpdateAddressLabels(t *testing.T) {
+	gateway, messenger := "0x6774Bcbd5ceCeF1336b5300fb5186a12DDD8b367", "0x1c5a77d9fa7ef466951b2f01f724bca3a5820b63"
+	loads := 0
+	logic := &HistoryLogic{addressBook: newAddressBook(func(context.Context) ([]*orm.AddressLabel, error) {
+		loads++
+		return []*orm.AddressLabel{{Address: gateway, Label: "L1GatewayRouter"}, {Address: messenger, Label: "L1ScrollMessenger"}}, nil
+	})}
+	txHistories := []*types.TxHistoryInfo{
+		{To: "0x6774bcbd5cecef1336b5300fb5186a12ddd8b367", ClaimInfo: &types.UserClaimInfo{From: messenger, To: "0x01"}},
+		{To: "0x02"},
+	}
+	logic.updateAddressLabels(context.Background(), txHistories)
+	assert.Equal(t, "L1GatewayRouter", txHistories[0].ToLabel)
+	assert.Equal(t, "L1ScrollMessenger", txHistories[0].ClaimInfo.FromLabel)
+	assert.Empty(t, txHistories[0].ClaimInfo.ToLabel)
+	assert.Empty(t, txHistories[1].ToLabel)
+	// the labels are loaded once per refresh interval
+	logic.updateAddressLabels(context.Background(), txHistories)
+	assert.Equal(t, 1, loads)
+
+	// the failed loads leave the addresses unlabeled
+	logic.addressBook = newAddressBook(func(context.Context) ([]*orm.AddressLabel, error) { return nil, errors.New("connection refused") })
+	txHistories = []*types.TxHistoryInfo{{To: gateway}}
+	logic.updateAddressLabels(context.Background(), txHistories)
+	assert.Empty(t, txHistories[0].ToLabel)
+
+	// disabled
+	(&HistoryLogic{}).updateAddressLabels(context.Background(), txHistories)
+	assert.Empty(t, txHistories[0].ToLabel)
+}
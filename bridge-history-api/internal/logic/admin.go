@@ -0,0 +1,209 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// the actions of the audit records of the admin apis
+const (
+	adminActionRefetchTx      = "refetch_tx"
+	adminActionRecomputeProof = "recompute_proof"
+	adminActionReenrichMsg    = "reenrich_msg"
+	adminActionDeleteRow      = "delete_row"
+)
+
+// MsgRepairer fetches the events and the enrichments of the messages again from the nodes, the returned counts are the
+// numbers of the messages saved or updated
+type MsgRepairer interface {
+	// RefetchTx fetches the events of the tx on the layer again, replacing its cross messages
+	RefetchTx(ctx context.Context, layer orm.MsgType, txHash common.Hash) (int64, error)
+	// ReenrichCrossMsg reads the fees, the origin method and the block timestamp of the cross message again
+	ReenrichCrossMsg(ctx context.Context, msgHash common.Hash) (int64, error)
+}
+
+// deletedRow the row of a message table to soft delete, its messages are passed to InvalidateHistoryCache once deleted
+type deletedRow struct {
+	crossMsgs   []*orm.CrossMsg
+	relayedMsgs []*orm.RelayedMsg
+	l2SentMsgs  []*orm.L2SentMsg
+}
+
+// rowTable soft deletes the rows of a message table
+type rowTable struct {
+	// load returns the row of the id, nil if it doesn't exist
+	load func(ctx context.Context, id uint64) (*deletedRow, error)
+	// delete soft deletes the row of the id, returning the number of the rows deleted
+	delete func(ctx context.Context, id uint64, dbTx ...*gorm.DB) (int64, error)
+}
+
+// AdminLogic runs the corrections of the operators on the indexed messages, each action is audited along with its
+// failure if any
+type AdminLogic struct {
+	db             *gorm.DB
+	cache          Cache
+	repairer       MsgRepairer
+	adminActionOrm *orm.AdminAction
+	l2SentMsgOrm   *orm.L2SentMsg
+	// rowTables are the tables whose rows can be deleted by their names
+	rowTables map[string]*rowTable
+}
+
+// NewAdminLogic returns the AdminLogic of the network backed with a "db", the messages are fetched again through
+// repairer, nil if the network has no node. The cached histories of the corrected messages are invalidated in cache,
+// may be nil.
+func NewAdminLogic(db *gorm.DB, cache Cache, repairer MsgRepairer) *AdminLogic {
+	crossMsgOrm, relayedMsgOrm, l2SentMsgOrm := orm.NewCrossMsg(db), orm.NewRelayedMsg(db), orm.NewL2SentMsg(db)
+	return &AdminLogic{
+		db:             db,
+		cache:          cache,
+		repairer:       repairer,
+		adminActionOrm: orm.NewAdminAction(db),
+		l2SentMsgOrm:   l2SentMsgOrm,
+		rowTables: map[string]*rowTable{
+			crossMsgOrm.TableName(): {
+				load: func(ctx context.Context, id uint64) (*deletedRow, error) {
+					crossMsg, err := crossMsgOrm.GetCrossMsgByID(ctx, id)
+					if err != nil || crossMsg == nil {
+						return nil, err
+					}
+					return &deletedRow{crossMsgs: []*orm.CrossMsg{crossMsg}}, nil
+				},
+				delete: crossMsgOrm.DeleteCrossMsgByID,
+			},
+			relayedMsgOrm.TableName(): {
+				load: func(ctx context.Context, id uint64) (*deletedRow, error) {
+					relayedMsg, err := relayedMsgOrm.GetRelayedMsgByID(ctx, id)
+					if err != nil || relayedMsg == nil {
+						return nil, err
+					}
+					return &deletedRow{relayedMsgs: []*orm.RelayedMsg{relayedMsg}}, nil
+				},
+				delete: relayedMsgOrm.DeleteRelayedMsgByID,
+			},
+			l2SentMsgOrm.TableName(): {
+				load: func(ctx context.Context, id uint64) (*deletedRow, error) {
+					l2SentMsg, err := l2SentMsgOrm.GetL2SentMsgByID(ctx, id)
+					if err != nil || l2SentMsg == nil {
+						return nil, err
+					}
+					return &deletedRow{l2SentMsgs: []*orm.L2SentMsg{l2SentMsg}}, nil
+				},
+				delete: l2SentMsgOrm.DeleteL2SentMsgByID,
+			},
+		},
+	}
+}
+
+// RefetchTx fetches the events of the tx of the layer, 1 or 2, again from its node, the cross messages of the tx are
+// replaced by the ones fetched
+func (a *AdminLogic) RefetchTx(ctx context.Context, actor string, req *types.AdminRefetchTxRequest) (*types.AdminActionResult, error) {
+	layer := orm.MsgType(req.Layer)
+	if layer != orm.Layer1Msg && layer != orm.Layer2Msg {
+		return nil, fmt.Errorf("%w: unknown layer %d, expected 1 or 2", ErrInvalidParameter, req.Layer)
+	}
+	if a.repairer == nil {
+		return nil, fmt.Errorf("%w: the network has no node to fetch the tx from", ErrInvalidParameter)
+	}
+	txHash := common.HexToHash(req.TxHash)
+	return a.run(ctx, adminActionRefetchTx, txHash.Hex(), actor, func() (int64, error) {
+		return a.repairer.RefetchTx(ctx, layer, txHash)
+	})
+}
+
+// ReenrichMsg reads the enrichments of the cross message of the hash again from its node
+func (a *AdminLogic) ReenrichMsg(ctx context.Context, actor string, req *types.AdminMsgRequest) (*types.AdminActionResult, error) {
+	if a.repairer == nil {
+		return nil, fmt.Errorf("%w: the network has no node to read the message from", ErrInvalidParameter)
+	}
+	msgHash := common.HexToHash(req.MsgHash)
+	return a.run(ctx, adminActionReenrichMsg, msgHash.Hex(), actor, func() (int64, error) {
+		return a.repairer.ReenrichCrossMsg(ctx, msgHash)
+	})
+}
+
+// RecomputeProof rebuilds the proof of the finalized withdrawal of the hash from the stored messages and saves it in
+// place of the stored one
+func (a *AdminLogic) RecomputeProof(ctx context.Context, actor string, req *types.AdminMsgRequest) (*types.AdminActionResult, error) {
+	msgHash := common.HexToHash(req.MsgHash).Hex()
+	return a.run(ctx, adminActionRecomputeProof, msgHash, actor, func() (int64, error) {
+		l2SentMsg, err := a.l2SentMsgOrm.GetL2SentMsgByHash(ctx, msgHash)
+		if err != nil {
+			return 0, classifyError(err)
+		}
+		proofCtx, cancel := context.WithTimeout(ctx, withdrawProofTimeout)
+		defer cancel()
+		_, batch, proof, err := withdrawProofOf(proofCtx, a.db, l2SentMsg.Nonce)
+		if err != nil {
+			return 0, err
+		}
+		if err = a.l2SentMsgOrm.UpdateL2MessageProof(ctx, msgHash, common.Bytes2Hex(proof), batch.BatchIndex); err != nil {
+			return 0, classifyError(err)
+		}
+		if a.cache != nil {
+			if err = a.cache.Del(ctx, withdrawProofCacheKey(l2SentMsg.Nonce)); err != nil {
+				log.Error("failed to delete the cached withdraw proof", "nonce", l2SentMsg.Nonce, "err", err)
+			}
+		}
+		if err = InvalidateHistoryCache(ctx, a.cache, a.db, nil, nil, []*orm.L2SentMsg{l2SentMsg}); err != nil {
+			log.Error("failed to invalidate the cached histories", "err", err)
+		}
+		return 1, nil
+	})
+}
+
+// DeleteRow soft deletes the orphaned row of the id of the table, one of the message tables, and invalidates the
+// cached histories of its messages
+func (a *AdminLogic) DeleteRow(ctx context.Context, actor string, req *types.AdminDeleteRowRequest) (*types.AdminActionResult, error) {
+	table, found := a.rowTables[req.Table]
+	if !found {
+		return nil, fmt.Errorf("%w: the rows of table %q can't be deleted", ErrInvalidParameter, req.Table)
+	}
+	target := req.Table + ":" + strconv.FormatUint(req.ID, 10)
+	return a.run(ctx, adminActionDeleteRow, target, actor, func() (int64, error) {
+		row, err := table.load(ctx, req.ID)
+		if err != nil {
+			return 0, classifyError(err)
+		}
+		if row == nil {
+			return 0, fmt.Errorf("%w: no row %s", ErrNotFound, target)
+		}
+		deleted, err := table.delete(ctx, req.ID)
+		if err != nil {
+			return 0, classifyError(err)
+		}
+		if deleted == 0 {
+			return 0, fmt.Errorf("%w: no row %s", ErrNotFound, target)
+		}
+		if err = InvalidateHistoryCache(ctx, a.cache, a.db, row.crossMsgs, row.relayedMsgs, row.l2SentMsgs); err != nil {
+			log.Error("failed to invalidate the cached histories", "err", err)
+		}
+		return deleted, nil
+	})
+}
+
+// run runs the action on the target and audits it, failed or not. The action is reported failed if its audit record
+// can't be saved, it's run already then.
+func (a *AdminLogic) run(ctx context.Context, action, target, actor string, fn func() (int64, error)) (*types.AdminActionResult, error) {
+	affected, actionErr := fn()
+	record := &orm.AdminAction{Action: action, Target: target, Actor: actor, Affected: affected}
+	if actionErr != nil {
+		record.Error = actionErr.Error()
+	}
+	if err := a.adminActionOrm.InsertAdminAction(ctx, record); err != nil {
+		log.Error("failed to audit the admin action", "action", action, "target", target, "err", err)
+		return nil, classifyError(err)
+	}
+	if actionErr != nil {
+		return nil, actionErr
+	}
+	return &types.AdminActionResult{AuditID: record.ID, Action: action, Target: target, Affected: affected}, nil
+}
@@ -0,0 +1,113 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// fakeRepairer a repairer recording the messages repaired
+type fakeRepairer struct {
+	layer    orm.MsgType
+	hash     common.Hash
+	affected int64
+	err      error
+}
+
+func (f *fakeRepairer) RefetchTx(_ context.Context, layer orm.MsgType, txHash common.Hash) (int64, error) {
+	f.layer, f.hash = layer, txHash
+	return f.affected, f.err
+}
+
+func (f *fakeRepairer) ReenrichCrossMsg(_ context.Context, msgHash common.Hash) (int64, error) {
+	f.hash = msgHash
+	return f.affected, f.err
+}
+
+// newAuditedDB returns a dry run db answering the queries from the fixtures and recording the audit records of the
+// admin actions created
+func newAuditedDB(t *testing.T, fixtures map[string]interface{}) (*gorm.DB, *[]*orm.AdminAction) {
+	db, _ := newCountingDB(t, fixtures)
+	var audits []*orm.AdminAction
+	err := db.Callback().Create().After("gorm:create").Register("test:audit_admin_actions", func(db *gorm.DB) {
+		if action, ok := db.Statement.Dest.(*orm.AdminAction); ok {
+			audits = append(audits, action)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db.Session(&gorm.Session{SkipDefaultTransaction: true}), &audits
+}
+
+func TestAdminRefetchTx(t *testing.T) {
+	db, audits := newAuditedDB(t, nil)
+	repairer := &fakeRepairer{affected: 2}
+	admin := NewAdminLogic(db, nil, repairer)
+	txHash := common.HexToHash("0x01")
+
+	result, err := admin.RefetchTx(context.Background(), "10.0.0.1", &types.AdminRefetchTxRequest{Layer: 2, TxHash: "0x01"})
+	assert.NoError(t, err)
+	assert.Equal(t, &types.AdminActionResult{Action: "refetch_tx", Target: txHash.Hex(), Affected: 2}, result)
+	assert.Equal(t, orm.Layer2Msg, repairer.layer)
+	assert.Equal(t, txHash, repairer.hash)
+	assert.Len(t, *audits, 1)
+	assert.Equal(t, "10.0.0.1", (*audits)[0].Actor)
+	assert.Empty(t, (*audits)[0].Error)
+
+	// the failures are audited too
+	repairer.err = errors.New("connection refused")
+	_, err = admin.RefetchTx(context.Background(), "10.0.0.1", &types.AdminRefetchTxRequest{Layer: 1, TxHash: "0x01"})
+	assert.EqualError(t, err, "connection refused")
+	assert.Len(t, *audits, 2)
+	assert.Equal(t, "connection refused", (*audits)[1].Error)
+
+	// the invalid requests are not run
+	_, err = admin.RefetchTx(context.Background(), "10.0.0.1", &types.AdminRefetchTxRequest{Layer: 3, TxHash: "0x01"})
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+	_, err = NewAdminLogic(db, nil, nil).ReenrichMsg(context.Background(), "10.0.0.1", &types.AdminMsgRequest{MsgHash: "0x01"})
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+	assert.Len(t, *audits, 2)
+}
+
+func TestAdminDeleteRow(t *testing.T) {
+	db, audits := newAuditedDB(t, nil)
+	admin := NewAdminLogic(db, nil, nil)
+	_, err := admin.DeleteRow(context.Background(), "10.0.0.1", &types.AdminDeleteRowRequest{Table: "rollup_batch", ID: 1})
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+	assert.Empty(t, *audits)
+
+	// nothing is deleted by a dry run
+	_, err = admin.DeleteRow(context.Background(), "10.0.0.1", &types.AdminDeleteRowRequest{Table: "cross_message", ID: 7})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Len(t, *audits, 1)
+	assert.Equal(t, "delete_row", (*audits)[0].Action)
+	assert.Equal(t, "cross_message:7", (*audits)[0].Target)
+}
+
+func TestAdminDeleteRowInvalidatesHistoryCache(t *testing.T) {
+	sender := common.HexToAddress("0x01")
+	db, audits := newAuditedDB(t, map[string]interface{}{
+		(&orm.CrossMsg{}).TableName(): []*orm.CrossMsg{{ID: 7, Sender: sender.Hex(), Layer1Hash: "0xaa", MsgType: int(orm.Layer1Msg)}},
+	})
+	// the dry run deletes the row
+	err := db.Callback().Delete().After("gorm:delete").Register("test:delete_row", func(db *gorm.DB) {
+		db.RowsAffected = 1
+	})
+	assert.NoError(t, err)
+	cache := newMemCache()
+	admin := NewAdminLogic(db, cache, nil)
+
+	result, err := admin.DeleteRow(context.Background(), "10.0.0.1", &types.AdminDeleteRowRequest{Table: "cross_message", ID: 7})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), result.Affected)
+	assert.ElementsMatch(t, []string{txsCacheVersionKey(sender), claimableCacheKey(sender), txCacheKey("0xaa")}, cache.deleted)
+	assert.Len(t, *audits, 1)
+}
@@ -0,0 +1,30 @@
+package logic
+
+import (
+	"context"
+
+	"bridge-history-api/orm"
+)
+
+// getArchivedCrossMsgs returns the archived cross messages of the hashes none of the crossMsgs is sent or relayed by,
+// nil if the archive lookups are disabled. The archived messages are all relayed, they rarely get queried.
+func (h *HistoryLogic) getArchivedCrossMsgs(ctx context.Context, hashes []string, crossMsgs []*orm.CrossMsg, assets []orm.AssetType, order orm.SortOrder) ([]*orm.CrossMsg, error) {
+	if !h.archiveLookup {
+		return nil, nil
+	}
+	found := make(map[string]bool, 2*len(crossMsgs))
+	for _, crossMsg := range crossMsgs {
+		found[crossMsg.Layer1Hash] = true
+		found[crossMsg.Layer2Hash] = true
+	}
+	var missing []string
+	for _, hash := range hashes {
+		if !found[hash] {
+			missing = append(missing, hash)
+		}
+	}
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	return orm.NewArchiveExcludingSenders(h.db, h.faucets).GetArchivedCrossMsgsByHashes(ctx, missing, assets, order)
+}
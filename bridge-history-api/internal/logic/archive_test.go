@@ -0,0 +1,45 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestGetTxsByHashesLooksUpArchive(t *testing.T) {
+	deposit, withdrawal := common.HexToHash("0x01").Hex(), common.HexToHash("0x02").Hex()
+	fixtures := map[string]interface{}{
+		(&orm.CrossMsg{}).TableName(): []*orm.CrossMsg{
+			{MsgHash: "0xa1", Layer1Hash: deposit, MsgType: int(orm.Layer1Msg), Amount: "1"},
+		},
+		orm.CrossMsgArchiveTableName: []*orm.CrossMsg{
+			{MsgHash: "0xb1", Layer2Hash: withdrawal, MsgType: int(orm.Layer2Msg), Amount: "2"},
+		},
+		(&orm.RelayedMsg{}).TableName(): []*orm.RelayedMsg{
+			{MsgHash: "0xb1", Layer1Hash: "0x03", Height: 20},
+		},
+	}
+	db, counter := newCountingDB(t, fixtures)
+	txHistories, err := (&HistoryLogic{db: db, archiveLookup: true}).GetTxsByHashes(context.Background(), []string{deposit, withdrawal}, types.TokenTypeAll, types.SortOrderDesc)
+	assert.NoError(t, err)
+	assert.Len(t, txHistories, 2)
+	// only the hash missing from the hot tables is looked up, the archived withdrawal is enriched as the others
+	assert.Equal(t, withdrawal, counter.vars["(*Archive).GetArchivedCrossMsgsByHashes"][0])
+	for _, txHistory := range txHistories {
+		if txHistory.Hash == withdrawal {
+			assert.Equal(t, "0x03", txHistory.FinalizeTx.Hash)
+		}
+	}
+
+	// the archive is not looked up unless enabled
+	db, counter = newCountingDB(t, fixtures)
+	txHistories, err = (&HistoryLogic{db: db}).GetTxsByHashes(context.Background(), []string{deposit, withdrawal}, types.TokenTypeAll, types.SortOrderDesc)
+	assert.NoError(t, err)
+	assert.Len(t, txHistories, 1)
+	assert.Zero(t, counter.calls["(*Archive).GetArchivedCrossMsgsByHashes"])
+}
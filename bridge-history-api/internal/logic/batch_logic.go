@@ -6,17 +6,22 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"gorm.io/gorm"
 
+	"bridge-history-api/internal/types"
 	"bridge-history-api/orm"
 )
 
 // BatchLogic example service.
 type BatchLogic struct {
-	rollupOrm *orm.RollupBatch
+	rollupOrm    *orm.RollupBatch
+	l2SentMsgOrm *orm.L2SentMsg
 }
 
 // NewBatchLogic returns services backed with a "db"
 func NewBatchLogic(db *gorm.DB) *BatchLogic {
-	logic := &BatchLogic{rollupOrm: orm.NewRollupBatch(db)}
+	logic := &BatchLogic{
+		rollupOrm:    orm.NewRollupBatch(db),
+		l2SentMsgOrm: orm.NewL2SentMsg(db),
+	}
 	return logic
 }
 
@@ -25,7 +30,7 @@ func (b *BatchLogic) GetWithdrawRootByBatchIndex(ctx context.Context, batchIndex
 	batch, err := b.rollupOrm.GetRollupBatchByIndex(ctx, batchIndex)
 	if err != nil {
 		log.Debug("getWithdrawRootByBatchIndex failed", "error", err)
-		return "", err
+		return "", classifyError(err)
 	}
 	if batch == nil {
 		log.Debug("getWithdrawRootByBatchIndex failed", "error", "batch not found")
@@ -33,3 +38,89 @@ func (b *BatchLogic) GetWithdrawRootByBatchIndex(ctx context.Context, batchIndex
 	}
 	return batch.WithdrawRoot, nil
 }
+
+// GetBatchInfoByBatchIndex get batch info by batch index from db
+func (b *BatchLogic) GetBatchInfoByBatchIndex(ctx context.Context, batchIndex uint64) (*types.BatchInfo, error) {
+	batch, err := b.rollupOrm.GetRollupBatchByIndex(ctx, batchIndex)
+	if err != nil {
+		log.Debug("getBatchInfoByBatchIndex failed", "error", err)
+		return nil, classifyError(err)
+	}
+	if batch == nil {
+		log.Debug("getBatchInfoByBatchIndex failed", "error", "batch not found")
+		return nil, nil
+	}
+	lastMsg, err := b.l2SentMsgOrm.GetLatestL2SentMsgLEHeight(ctx, batch.EndBlockNumber)
+	if err != nil {
+		log.Debug("getBatchInfoByBatchIndex failed", "error", err)
+		return nil, classifyError(err)
+	}
+	return newBatchInfo(batch, lastMsg), nil
+}
+
+// GetBatches get the rollup batches from the latest one along with the number of the withdrawals sent in their blocks
+// and their finalize txs, the reverted batches are excluded
+func (b *BatchLogic) GetBatches(ctx context.Context, pagination types.Pagination) ([]*types.BatchSummary, uint64, error) {
+	total, err := b.rollupOrm.GetTotalRollupBatchCount(ctx)
+	if err != nil || total == 0 {
+		return nil, 0, classifyError(err)
+	}
+	offset, limit := getOffsetLimit(pagination)
+	batches, err := b.rollupOrm.GetRollupBatchesWithMsgCounts(ctx, offset, limit)
+	if err != nil {
+		log.Debug("getBatches failed", "error", err)
+		return nil, 0, classifyError(err)
+	}
+	summaries := make([]*types.BatchSummary, 0, len(batches))
+	for _, batch := range batches {
+		summaries = append(summaries, newBatchSummary(batch))
+	}
+	return summaries, total, nil
+}
+
+// newBatchSummary builds the summary of the batch in the batch lists
+func newBatchSummary(batch *orm.RollupBatchWithMsgCount) *types.BatchSummary {
+	summary := &types.BatchSummary{
+		BatchIndex:       batch.BatchIndex,
+		BatchHash:        batch.BatchHash,
+		StartBlockNumber: batch.StartBlockNumber,
+		EndBlockNumber:   batch.EndBlockNumber,
+		CommitTxHash:     batch.CommitTxHash,
+		IsFinalized:      batch.IsFinalized(),
+		MsgCount:         batch.MsgCount,
+	}
+	if summary.IsFinalized {
+		summary.FinalizeTxHash = batch.FinalizeTxHash
+		summary.FinalizeTimestamp = batch.FinalizeTimestamp
+	}
+	return summary
+}
+
+// newBatchInfo builds the batch info of the batch given the last message appended to the withdraw tree up to it
+func newBatchInfo(batch *orm.RollupBatch, lastMsg *orm.L2SentMsg) *types.BatchInfo {
+	batchInfo := &types.BatchInfo{
+		BatchIndex:     batch.BatchIndex,
+		BatchHash:      batch.BatchHash,
+		WithdrawRoot:   batch.WithdrawRoot,
+		TreeLeafCount:  withdrawTreeLeafCount(lastMsg),
+		IsGenesisBatch: isGenesisBatch(batch.BatchIndex),
+	}
+	if batch.IsFinalized() {
+		batchInfo.StateRoot = batch.StateRoot
+	}
+	return batchInfo
+}
+
+// withdrawTreeLeafCount returns the leaf count of the withdraw tree given the last message appended to it,
+// the withdraw tree is append only and each message nonce is its leaf index.
+func withdrawTreeLeafCount(lastMsg *orm.L2SentMsg) uint64 {
+	if lastMsg == nil {
+		return 0
+	}
+	return lastMsg.Nonce + 1
+}
+
+// isGenesisBatch returns whether the batch is the genesis batch 0, the batches committed on top of it are not
+func isGenesisBatch(batchIndex uint64) bool {
+	return batchIndex == 0
+}
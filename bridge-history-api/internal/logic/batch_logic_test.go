@@ -0,0 +1,65 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/orm"
+)
+
+func TestWithdrawTreeLeafCount(t *testing.T) {
+	// no message is sent up to the batch
+	assert.Equal(t, uint64(0), withdrawTreeLeafCount(nil))
+
+	// the batch contains the messages with nonce 0, 1 and 2
+	batchMsgs := []*orm.L2SentMsg{{Nonce: 0}, {Nonce: 1}, {Nonce: 2}}
+	assert.Equal(t, uint64(len(batchMsgs)), withdrawTreeLeafCount(batchMsgs[len(batchMsgs)-1]))
+
+	// the leaves of the previous batches are counted too
+	assert.Equal(t, uint64(10), withdrawTreeLeafCount(&orm.L2SentMsg{Nonce: 9}))
+}
+
+func TestIsGenesisBatch(t *testing.T) {
+	assert.True(t, isGenesisBatch(0))
+	// the first batch committed on top of the genesis batch is a normal batch
+	assert.False(t, isGenesisBatch(1))
+	assert.False(t, isGenesisBatch(100))
+}
+
+func TestNewBatchInfoStateRoot(t *testing.T) {
+	stateRoot := "0x0000000000000000000000000000000000000000000000000000000000000001"
+
+	finalizedBatch := &orm.RollupBatch{BatchIndex: 2, BatchHash: "batchhash", FinalizeHeight: 100, StateRoot: stateRoot}
+	batchInfo := newBatchInfo(finalizedBatch, &orm.L2SentMsg{Nonce: 4})
+	assert.Equal(t, stateRoot, batchInfo.StateRoot)
+	assert.Equal(t, "batchhash", batchInfo.BatchHash)
+	assert.Equal(t, uint64(5), batchInfo.TreeLeafCount)
+
+	// not finalized yet
+	batchInfo = newBatchInfo(&orm.RollupBatch{BatchIndex: 3, StateRoot: stateRoot}, nil)
+	assert.Empty(t, batchInfo.StateRoot)
+}
+
+func TestNewBatchSummary(t *testing.T) {
+	finalizedAt := time.Unix(1700000000, 0).UTC()
+	batch := &orm.RollupBatchWithMsgCount{
+		RollupBatch: orm.RollupBatch{BatchIndex: 2, BatchHash: "batchhash", StartBlockNumber: 10, EndBlockNumber: 20, CommitTxHash: "0x01",
+			FinalizeHeight: 100, FinalizeTxHash: "0x02", FinalizeTimestamp: &finalizedAt},
+		MsgCount: 3,
+	}
+	summary := newBatchSummary(batch)
+	assert.True(t, summary.IsFinalized)
+	assert.Equal(t, "0x02", summary.FinalizeTxHash)
+	assert.Equal(t, &finalizedAt, summary.FinalizeTimestamp)
+	assert.Equal(t, uint64(3), summary.MsgCount)
+	assert.Equal(t, uint64(10), summary.StartBlockNumber)
+
+	// not finalized yet
+	batch.FinalizeHeight = 0
+	summary = newBatchSummary(batch)
+	assert.False(t, summary.IsFinalized)
+	assert.Empty(t, summary.FinalizeTxHash)
+	assert.Nil(t, summary.FinalizeTimestamp)
+}
@@ -0,0 +1,290 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	backendabi "bridge-history-api/abi"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+const (
+	// claimTxIntrinsicGas is the intrinsic gas of an L1 transaction
+	claimTxIntrinsicGas = 21000
+	// claimCalldataZeroByteGas and claimCalldataNonZeroByteGas are the calldata costs defined in EIP-2028
+	claimCalldataZeroByteGas    = 4
+	claimCalldataNonZeroByteGas = 16
+	// claimExecutionGas is the approximate gas used by L1ScrollMessenger.relayMessageWithProof excluding the proof verification
+	claimExecutionGas = 100000
+	// claimProofNodeGas is the approximate gas used to verify one 32 bytes node of the withdraw trie merkle proof
+	claimProofNodeGas = 1000
+	// maxClaimInfoMsgHashes is the upper bound of the msg hashes queried at once by GetClaimInfosByMsgHashes
+	maxClaimInfoMsgHashes = 100
+	// claimGasLimitMarginPercent is the margin in percent added to the estimated gas of a claim for its gas limit
+	claimGasLimitMarginPercent = 20
+)
+
+// GasEstimator estimates the gas of a call, e.g. the eth_estimateGas of the layer1 node
+type GasEstimator interface {
+	EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error)
+}
+
+// l2MessageProof is the proof argument of L1ScrollMessenger.relayMessageWithProof
+type l2MessageProof struct {
+	BatchHash   common.Hash
+	MerkleProof []byte
+}
+
+// ClaimArgs returns the arguments of L1ScrollMessenger.relayMessageWithProof built from the claim info,
+// in the ABI input order: from, to, value, nonce, message and proof.
+func ClaimArgs(claimInfo *types.UserClaimInfo) ([]interface{}, error) {
+	value, ok := new(big.Int).SetString(claimInfo.Value, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid claim value: %s", claimInfo.Value)
+	}
+	nonce, ok := new(big.Int).SetString(claimInfo.Nonce, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid claim nonce: %s", claimInfo.Nonce)
+	}
+	message, err := hexutil.Decode(claimInfo.Message)
+	if err != nil {
+		return nil, fmt.Errorf("invalid claim message: %w", err)
+	}
+	proof, err := hexutil.Decode(claimInfo.Proof)
+	if err != nil {
+		return nil, fmt.Errorf("invalid claim proof: %w", err)
+	}
+	return []interface{}{
+		common.HexToAddress(claimInfo.From),
+		common.HexToAddress(claimInfo.To),
+		value,
+		nonce,
+		message,
+		l2MessageProof{
+			BatchHash:   common.HexToHash(claimInfo.BatchHash),
+			MerkleProof: proof,
+		},
+	}, nil
+}
+
+// buildClaimCalldata builds the L1ScrollMessenger.relayMessageWithProof calldata from the claim info
+func buildClaimCalldata(claimInfo *types.UserClaimInfo) ([]byte, error) {
+	args, err := ClaimArgs(claimInfo)
+	if err != nil {
+		return nil, err
+	}
+	return backendabi.L1ScrollMessengerABI.Pack("relayMessageWithProof", args...)
+}
+
+// claimKey returns the keccak256 hash of the claim calldata without the merkle proof, it's the idempotency key of
+// the claim submissions. The proof is excluded since it's filled once generated, the key only depends on the message
+// and its batch, and the message nonce makes it unique per message.
+func claimKey(claimInfo *types.UserClaimInfo) (string, error) {
+	withoutProof := *claimInfo
+	withoutProof.Proof = "0x"
+	calldata, err := buildClaimCalldata(&withoutProof)
+	if err != nil {
+		return "", err
+	}
+	return crypto.Keccak256Hash(calldata).Hex(), nil
+}
+
+// estimateClaimGas estimates the gas of claiming the message on L1 statically, without calling the L1 node.
+func estimateClaimGas(claimInfo *types.UserClaimInfo) (uint64, error) {
+	calldata, err := buildClaimCalldata(claimInfo)
+	if err != nil {
+		return 0, err
+	}
+	gas := uint64(claimTxIntrinsicGas + claimExecutionGas)
+	for _, b := range calldata {
+		if b == 0 {
+			gas += claimCalldataZeroByteGas
+		} else {
+			gas += claimCalldataNonZeroByteGas
+		}
+	}
+	proofNodes := (len(claimInfo.Proof) - len("0x")) / (2 * common.HashLength)
+	gas += uint64(proofNodes) * claimProofNodeGas
+	return gas, nil
+}
+
+// sumEstimatedGas sums up the estimated claim gas of the given txs, txs without claim info are skipped
+func sumEstimatedGas(txHistories []*types.TxHistoryInfo) uint64 {
+	var total uint64
+	for _, txHistory := range txHistories {
+		if txHistory.ClaimInfo != nil {
+			total += txHistory.ClaimInfo.EstimatedGas
+		}
+	}
+	return total
+}
+
+// minAggregatableClaims is the least number of messages of a batch to be claimed together
+const minAggregatableClaims = 2
+
+// groupAggregatableClaims groups the claimable messages with proofs by batch, only the batches with at least
+// minAggregatableClaims messages are returned, ordered by batch index asc. The messages whose proof is not generated
+// yet or is redacted are skipped.
+func groupAggregatableClaims(txHistories []*types.TxHistoryInfo) []*types.AggregatableClaimGroup {
+	groupMap := make(map[string]*types.AggregatableClaimGroup)
+	for _, txHistory := range txHistories {
+		claimInfo := txHistory.ClaimInfo
+		if claimInfo == nil || txHistory.ClaimStatus != types.ClaimStatusClaimable || claimInfo.Proof == "0x" || claimInfo.Proof == "" {
+			continue
+		}
+		group, found := groupMap[claimInfo.BatchHash]
+		if !found {
+			batchIndex, err := strconv.ParseUint(claimInfo.BatchIndex, 10, 64)
+			if err != nil {
+				continue
+			}
+			group = &types.AggregatableClaimGroup{BatchIndex: batchIndex, BatchHash: claimInfo.BatchHash}
+			groupMap[claimInfo.BatchHash] = group
+		}
+		group.Txs = append(group.Txs, txHistory)
+		group.Proofs = append(group.Proofs, claimInfo.Proof)
+	}
+
+	var groups []*types.AggregatableClaimGroup
+	for _, group := range groupMap {
+		if len(group.Txs) >= minAggregatableClaims {
+			groups = append(groups, group)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].BatchIndex < groups[j].BatchIndex
+	})
+	return groups
+}
+
+// GetAggregatableClaimable get the claimable messages of the given address grouped by batch, for the batches with
+// several messages to claim. L1ScrollMessenger verifies one proof per claim, so the group carries the proof of
+// each message against the same batch for the clients to submit the claims together, e.g. in a multicall.
+func (h *HistoryLogic) GetAggregatableClaimable(ctx context.Context, address common.Address) ([]*types.AggregatableClaimGroup, error) {
+	return instrument(h.metrics, "GetAggregatableClaimable", func() ([]*types.AggregatableClaimGroup, error) {
+		return h.getAggregatableClaimable(ctx, address)
+	})
+}
+
+// getAggregatableClaimable implements GetAggregatableClaimable
+func (h *HistoryLogic) getAggregatableClaimable(ctx context.Context, address common.Address) ([]*types.AggregatableClaimGroup, error) {
+	txHistories, _, err := h.GetClaimableTxsByAddress(ctx, address, nil, types.TokenTypeAll, types.SortOrderDesc)
+	if err != nil {
+		return nil, err
+	}
+	return groupAggregatableClaims(txHistories), nil
+}
+
+// GetClaimInfosByMsgHashes get the claim infos of the layer2 withdrawals of the msg hashes keyed by the msg hash, for
+// the clients to claim them together, e.g. in a multicall. The withdrawals whose batch isn't finalized or whose proof
+// isn't generated yet are skipped, so are the unknown msg hashes. At most maxClaimInfoMsgHashes are queried at once.
+func (h *HistoryLogic) GetClaimInfosByMsgHashes(ctx context.Context, msgHashes []string) (map[string]*types.UserClaimInfo, error) {
+	return instrument(h.metrics, "GetClaimInfosByMsgHashes", func() (map[string]*types.UserClaimInfo, error) {
+		return h.getClaimInfosByMsgHashes(ctx, msgHashes)
+	})
+}
+
+// getClaimInfosByMsgHashes implements GetClaimInfosByMsgHashes
+func (h *HistoryLogic) getClaimInfosByMsgHashes(ctx context.Context, msgHashes []string) (map[string]*types.UserClaimInfo, error) {
+	if len(msgHashes) > maxClaimInfoMsgHashes {
+		return nil, fmt.Errorf("%w: %d msg hashes exceed the allowed maximum of %d", ErrInvalidParameter, len(msgHashes), maxClaimInfoMsgHashes)
+	}
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	txHistories := make([]*types.TxHistoryInfo, 0, len(msgHashes))
+	for _, msgHash := range msgHashes {
+		txHistories = append(txHistories, &types.TxHistoryInfo{MsgHash: msgHash, FinalizeTx: &types.Finalized{}})
+	}
+	l2MsgHashes := uniqueMsgHashes(txHistories, nil)
+	if len(l2MsgHashes) == 0 {
+		return nil, nil
+	}
+	l2sentMsgs, err := orm.NewL2SentMsg(h.db).GetL2SentMsgsByHashes(ctx, l2MsgHashes)
+	if err != nil {
+		return nil, err
+	}
+	if err = updateL2TxClaimInfoFromMsgs(ctx, txHistories, l2sentMsgs, h.db, h.claimLookups); err != nil {
+		return nil, err
+	}
+	h.updateAddressLabels(ctx, txHistories)
+	h.redactSensitiveFields(txHistories)
+
+	claimInfos := make(map[string]*types.UserClaimInfo)
+	for _, txHistory := range txHistories {
+		if txHistory.ClaimInfo != nil && txHistory.ClaimInfo.Claimable {
+			claimInfos[txHistory.MsgHash] = txHistory.ClaimInfo
+		}
+	}
+	return claimInfos, nil
+}
+
+// claimGasLimit returns the gas limit suggested for the claim using the estimated gas
+func claimGasLimit(estimatedGas uint64) uint64 {
+	return estimatedGas + estimatedGas*claimGasLimitMarginPercent/100
+}
+
+// GetClaimTx get the layer1 tx of from claiming the withdrawal of the msg hash through l1Messenger, the gas of the
+// claim is estimated by the estimator, or statically if the estimator is nil or fails. ErrNotFound is returned if the
+// withdrawal isn't claimable yet.
+func (h *HistoryLogic) GetClaimTx(ctx context.Context, msgHash string, from, l1Messenger common.Address, estimator GasEstimator) (*types.ClaimTx, error) {
+	return instrument(h.metrics, "GetClaimTx", func() (*types.ClaimTx, error) {
+		return h.getClaimTx(ctx, msgHash, from, l1Messenger, estimator)
+	})
+}
+
+// getClaimTx implements GetClaimTx
+func (h *HistoryLogic) getClaimTx(ctx context.Context, msgHash string, from, l1Messenger common.Address, estimator GasEstimator) (*types.ClaimTx, error) {
+	if h.redactSensitive {
+		return nil, fmt.Errorf("%w: the claim calldata is redacted by the server", ErrInvalidParameter)
+	}
+	msgHashes, err := normalizeTxHashes([]string{msgHash})
+	if err != nil {
+		return nil, err
+	}
+	claimInfos, err := h.GetClaimInfosByMsgHashes(ctx, msgHashes)
+	if err != nil {
+		return nil, err
+	}
+	claimInfo := claimInfos[msgHashes[0]]
+	if claimInfo == nil {
+		return nil, fmt.Errorf("%w: no claimable withdrawal of msg hash %s", ErrNotFound, msgHashes[0])
+	}
+	calldata, err := buildClaimCalldata(claimInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	claimTx := &types.ClaimTx{
+		To:           l1Messenger.Hex(),
+		Data:         hexutil.Encode(calldata),
+		Value:        "0",
+		EstimatedGas: claimInfo.EstimatedGas,
+		ClaimInfo:    claimInfo,
+	}
+	if estimator != nil {
+		ctx, cancel := h.withQueryTimeout(ctx)
+		defer cancel()
+		gas, estimateErr := estimator.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &l1Messenger, Data: calldata})
+		if estimateErr != nil {
+			claimTx.EstimateError = estimateErr.Error()
+		} else {
+			claimTx.EstimatedGas = gas
+			claimTx.EstimatedByNode = true
+		}
+	}
+	claimTx.GasLimit = claimGasLimit(claimTx.EstimatedGas)
+	return claimTx, nil
+}
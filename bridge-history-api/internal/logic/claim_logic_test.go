@@ -0,0 +1,233 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+
+	backendabi "bridge-history-api/abi"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestClaimArgs(t *testing.T) {
+	proofNode := "0000000000000000000000000000000000000000000000000000000000000001"
+	claimInfo := &types.UserClaimInfo{
+		From:      "0x0000000000000000000000000000000000000001",
+		To:        "0x0000000000000000000000000000000000000002",
+		Value:     "1000",
+		Nonce:     "7",
+		BatchHash: "0x01",
+		Message:   "0x1234",
+		Proof:     "0x" + proofNode,
+	}
+	args, err := ClaimArgs(claimInfo)
+	assert.NoError(t, err)
+
+	inputs := backendabi.L1ScrollMessengerABI.Methods["relayMessageWithProof"].Inputs
+	assert.Len(t, args, len(inputs))
+	for i, input := range inputs {
+		if input.Type.T == abi.TupleTy {
+			// the tuple is packed from any struct with the same field names and convertible field types
+			argType, tupleType := reflect.TypeOf(args[i]), input.Type.GetType()
+			assert.Equal(t, tupleType.NumField(), argType.NumField(), input.Name)
+			for j := 0; j < tupleType.NumField(); j++ {
+				assert.Equal(t, tupleType.Field(j).Name, argType.Field(j).Name, input.Name)
+				assert.True(t, argType.Field(j).Type.ConvertibleTo(tupleType.Field(j).Type), input.Name)
+			}
+			continue
+		}
+		assert.Equal(t, input.Type.GetType(), reflect.TypeOf(args[i]), input.Name)
+	}
+
+	assert.Equal(t, common.HexToAddress(claimInfo.From), args[0])
+	assert.Equal(t, common.HexToAddress(claimInfo.To), args[1])
+	assert.Equal(t, big.NewInt(1000), args[2])
+	assert.Equal(t, big.NewInt(7), args[3])
+	assert.Equal(t, []byte{0x12, 0x34}, args[4])
+	assert.Equal(t, common.HexToHash("0x01"), args[5].(l2MessageProof).BatchHash)
+	assert.Equal(t, common.FromHex(proofNode), args[5].(l2MessageProof).MerkleProof)
+
+	_, err = backendabi.L1ScrollMessengerABI.Pack("relayMessageWithProof", args...)
+	assert.NoError(t, err)
+
+	claimInfo.Message = "invalid"
+	_, err = ClaimArgs(claimInfo)
+	assert.Error(t, err)
+}
+
+func TestClaimKey(t *testing.T) {
+	newClaimInfo := func(nonce string) *types.UserClaimInfo {
+		return &types.UserClaimInfo{
+			From:      "0x0000000000000000000000000000000000000001",
+			To:        "0x0000000000000000000000000000000000000002",
+			Value:     "1000",
+			Nonce:     nonce,
+			BatchHash: "0x01",
+			Message:   "0x1234",
+			Proof:     "0x",
+		}
+	}
+
+	key, err := claimKey(newClaimInfo("1"))
+	assert.NoError(t, err)
+	assert.Len(t, key, len("0x")+2*common.HashLength)
+
+	// stable for the same claim params
+	sameKey, err := claimKey(newClaimInfo("1"))
+	assert.NoError(t, err)
+	assert.Equal(t, key, sameKey)
+
+	// not changed once the proof is generated
+	withProof := newClaimInfo("1")
+	withProof.Proof = "0x0000000000000000000000000000000000000000000000000000000000000001"
+	proofKey, err := claimKey(withProof)
+	assert.NoError(t, err)
+	assert.Equal(t, key, proofKey)
+
+	// unique per message
+	otherKey, err := claimKey(newClaimInfo("2"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, otherKey)
+
+	_, err = claimKey(newClaimInfo("invalid"))
+	assert.Error(t, err)
+}
+
+func TestGroupAggregatableClaims(t *testing.T) {
+	newTxHistory := func(msgHash string, batchIndex string, proof string, claimStatus types.ClaimStatus) *types.TxHistoryInfo {
+		return &types.TxHistoryInfo{
+			MsgHash:     msgHash,
+			ClaimStatus: claimStatus,
+			ClaimInfo:   &types.UserClaimInfo{BatchIndex: batchIndex, BatchHash: "batch" + batchIndex, Proof: proof},
+		}
+	}
+	txHistories := []*types.TxHistoryInfo{
+		newTxHistory("hash1", "3", "0x01", types.ClaimStatusClaimable),
+		newTxHistory("hash2", "2", "0x02", types.ClaimStatusClaimable),
+		newTxHistory("hash3", "3", "0x03", types.ClaimStatusClaimable),
+		newTxHistory("hash4", "2", "0x04", types.ClaimStatusClaimable),
+		newTxHistory("hash5", "2", "0x05", types.ClaimStatusClaimable),
+		// the only claimable message of batch 1
+		newTxHistory("hash6", "1", "0x06", types.ClaimStatusClaimable),
+		// the proof is not generated yet
+		newTxHistory("hash7", "1", "0x", types.ClaimStatusClaimable),
+		// not finalized yet
+		newTxHistory("hash8", "4", "0x08", types.ClaimStatusPending),
+		newTxHistory("hash9", "4", "0x09", types.ClaimStatusPending),
+		{MsgHash: "hash10"},
+	}
+
+	groups := groupAggregatableClaims(txHistories)
+	assert.Len(t, groups, 2)
+
+	assert.Equal(t, uint64(2), groups[0].BatchIndex)
+	assert.Equal(t, "batch2", groups[0].BatchHash)
+	assert.Equal(t, []string{"0x02", "0x04", "0x05"}, groups[0].Proofs)
+	assert.Len(t, groups[0].Txs, 3)
+	assert.Equal(t, "hash2", groups[0].Txs[0].MsgHash)
+
+	assert.Equal(t, uint64(3), groups[1].BatchIndex)
+	assert.Equal(t, []string{"0x01", "0x03"}, groups[1].Proofs)
+}
+
+func TestGetClaimInfosByMsgHashes(t *testing.T) {
+	db, _ := newCountingDB(t, map[string]interface{}{
+		(&orm.L2SentMsg{}).TableName(): []*orm.L2SentMsg{
+			{MsgHash: "0xb1", Height: 100, BatchIndex: 1, Nonce: 1, MsgProof: "abcd"},
+			// the proof isn't generated yet
+			{MsgHash: "0xb2", Height: 101, BatchIndex: 1, Nonce: 2},
+			// the batch isn't finalized
+			{MsgHash: "0xb3", Height: 110, BatchIndex: 2, Nonce: 3, MsgProof: "abcd"},
+		},
+		(&orm.RollupBatch{}).TableName(): []*orm.RollupBatch{
+			{BatchIndex: 1, BatchHash: "0xbatch1", StartBlockNumber: 90, EndBlockNumber: 105, FinalizeHeight: 15},
+			{BatchIndex: 2, BatchHash: "0xbatch2", StartBlockNumber: 106, EndBlockNumber: 120},
+		},
+	})
+	logic := NewHistoryLogic(nil, db, nil, nil)
+
+	claimInfos, err := logic.GetClaimInfosByMsgHashes(context.Background(), []string{"0xb1", "0xb2", "0xb3", "0xb4"})
+	assert.NoError(t, err)
+	assert.Len(t, claimInfos, 1)
+	assert.Equal(t, "0xabcd", claimInfos["0xb1"].Proof)
+	assert.Equal(t, "0xbatch1", claimInfos["0xb1"].BatchHash)
+	assert.Equal(t, "1", claimInfos["0xb1"].Nonce)
+
+	claimInfos, err = logic.GetClaimInfosByMsgHashes(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, claimInfos)
+
+	msgHashes := make([]string, maxClaimInfoMsgHashes+1)
+	for i := range msgHashes {
+		msgHashes[i] = strconv.Itoa(i)
+	}
+	_, err = logic.GetClaimInfosByMsgHashes(context.Background(), msgHashes)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+}
+
+// fakeGasEstimator returns the gas or the error, and records the estimated call
+type fakeGasEstimator struct {
+	gas  uint64
+	err  error
+	call ethereum.CallMsg
+}
+
+func (f *fakeGasEstimator) EstimateGas(_ context.Context, call ethereum.CallMsg) (uint64, error) {
+	f.call = call
+	return f.gas, f.err
+}
+
+func TestGetClaimTx(t *testing.T) {
+	msgHash := "0x00000000000000000000000000000000000000000000000000000000000000b1"
+	db, _ := newCountingDB(t, map[string]interface{}{
+		(&orm.L2SentMsg{}).TableName(): []*orm.L2SentMsg{
+			{MsgHash: msgHash, Sender: "0x01", Target: "0x02", Value: "5", Height: 100, BatchIndex: 1, Nonce: 1, MsgProof: "abcd", MsgData: "0x1234"},
+		},
+		(&orm.RollupBatch{}).TableName(): []*orm.RollupBatch{
+			{BatchIndex: 1, BatchHash: "0xbatch1", StartBlockNumber: 90, EndBlockNumber: 105, FinalizeHeight: 15},
+		},
+	})
+	logic := NewHistoryLogic(nil, db, nil, nil)
+	from, messenger := common.HexToAddress("0x03"), common.HexToAddress("0x04")
+
+	estimator := &fakeGasEstimator{gas: 150000}
+	claimTx, err := logic.GetClaimTx(context.Background(), strings.ToUpper(msgHash[2:]), from, messenger, estimator)
+	assert.NoError(t, err)
+	calldata, err := buildClaimCalldata(claimTx.ClaimInfo)
+	assert.NoError(t, err)
+	assert.Equal(t, hexutil.Encode(calldata), claimTx.Data)
+	assert.Equal(t, messenger.Hex(), claimTx.To)
+	assert.Equal(t, "0", claimTx.Value)
+	assert.True(t, claimTx.EstimatedByNode)
+	assert.Equal(t, uint64(150000), claimTx.EstimatedGas)
+	assert.Equal(t, uint64(180000), claimTx.GasLimit)
+	assert.Equal(t, ethereum.CallMsg{From: from, To: &messenger, Data: calldata}, estimator.call)
+
+	// the static estimation is returned once the node fails to estimate
+	estimator.err = errors.New("execution reverted: Message was already successfully executed")
+	claimTx, err = logic.GetClaimTx(context.Background(), msgHash, from, messenger, estimator)
+	assert.NoError(t, err)
+	assert.False(t, claimTx.EstimatedByNode)
+	assert.Equal(t, claimTx.ClaimInfo.EstimatedGas, claimTx.EstimatedGas)
+	assert.Equal(t, estimator.err.Error(), claimTx.EstimateError)
+	claimTx, err = logic.GetClaimTx(context.Background(), msgHash, from, messenger, nil)
+	assert.NoError(t, err)
+	assert.False(t, claimTx.EstimatedByNode)
+	assert.Empty(t, claimTx.EstimateError)
+
+	_, err = logic.GetClaimTx(context.Background(), "0x00000000000000000000000000000000000000000000000000000000000000b2", from, messenger, nil)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = logic.GetClaimTx(context.Background(), "0xb1", from, messenger, nil)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+}
@@ -0,0 +1,130 @@
+package logic
+
+import (
+	"math"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"bridge-history-api/orm"
+)
+
+// claimLookupTTL bounds the time a cached batch or withdrawal is served, so that the proofs recomputed by the admin
+// apis on any server replace the cached ones in time
+const claimLookupTTL = 10 * time.Minute
+
+// cachedBatch a finalized batch along with the batches of the same index reverted before it was committed again
+type cachedBatch struct {
+	batch     *orm.RollupBatch
+	reverted  []*orm.RollupBatch
+	expiresAt time.Time
+}
+
+// cachedL2SentMsg a withdrawal proven against a finalized batch
+type cachedL2SentMsg struct {
+	msg       *orm.L2SentMsg
+	expiresAt time.Time
+}
+
+// claimLookupCache caches the finalized batches by their index and the proven withdrawals by their message hash in
+// memory for the claim infos, they no longer change once finalized. The least recently used entries are evicted over
+// the size of each cache. A nil claimLookupCache caches nothing. The cached rows are shared by the requests and must
+// not be modified.
+type claimLookupCache struct {
+	batches *lru.Cache[uint64, *cachedBatch]
+	msgs    *lru.Cache[string, *cachedL2SentMsg]
+	// lookups counts the lookups by the kind of the entry and the result, nil records nothing
+	lookups *prometheus.CounterVec
+}
+
+// newClaimLookupCache returns the claimLookupCache of size batches and size withdrawals, the hit rates are registered
+// on reg if it's not nil. nil is returned if size is 0.
+func newClaimLookupCache(size int, reg prometheus.Registerer) *claimLookupCache {
+	if size <= 0 {
+		return nil
+	}
+	c := &claimLookupCache{
+		batches: lru.NewCache[uint64, *cachedBatch](size),
+		msgs:    lru.NewCache[string, *cachedL2SentMsg](size),
+	}
+	if reg != nil {
+		c.lookups = promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "bridge_history_api_claim_lookup_cache_total",
+			Help: "The lookups of the finalized batches and the proven withdrawals in the in-memory cache of the claim infos by result",
+		}, []string{"kind", "result"})
+	}
+	return c
+}
+
+// batch returns the cached finalized batch of the index along with its reverted batches, false if not cached
+func (c *claimLookupCache) batch(index uint64) (*cachedBatch, bool) {
+	if c == nil {
+		return nil, false
+	}
+	entry, found := c.batches.Get(index)
+	if found && time.Now().After(entry.expiresAt) {
+		c.batches.Remove(index)
+		found = false
+	}
+	c.observe("batch", found)
+	return entry, found
+}
+
+// addBatches caches the finalized batches of the indexes, the batches committed but not finalized yet may be reverted
+// and are not cached
+func (c *claimLookupCache) addBatches(indexes []uint64, batchMap map[uint64]*orm.RollupBatch, revertedBatchMap map[uint64][]*orm.RollupBatch) {
+	if c == nil {
+		return
+	}
+	expiresAt := time.Now().Add(claimLookupTTL)
+	for _, index := range indexes {
+		if batch, found := batchMap[index]; found && batch.IsFinalized() {
+			c.batches.Add(index, &cachedBatch{batch: batch, reverted: revertedBatchMap[index], expiresAt: expiresAt})
+		}
+	}
+}
+
+// l2SentMsg returns the cached withdrawal of the message hash, false if not cached
+func (c *claimLookupCache) l2SentMsg(msgHash string) (*orm.L2SentMsg, bool) {
+	if c == nil {
+		return nil, false
+	}
+	entry, found := c.msgs.Get(msgHash)
+	if found && time.Now().After(entry.expiresAt) {
+		c.msgs.Remove(msgHash)
+		found = false
+	}
+	c.observe("l2_sent_msg", found)
+	if !found {
+		return nil, false
+	}
+	return entry.msg, true
+}
+
+// addL2SentMsgs caches the withdrawals proven against their finalized batch in batchMap, the others get their proof
+// or their batch later
+func (c *claimLookupCache) addL2SentMsgs(l2sentMsgs []*orm.L2SentMsg, batchMap map[uint64]*orm.RollupBatch) {
+	if c == nil {
+		return
+	}
+	expiresAt := time.Now().Add(claimLookupTTL)
+	for _, l2sentMsg := range l2sentMsgs {
+		if isClaimableAtBlock(l2sentMsg, batchMap[l2sentMsg.BatchIndex], math.MaxUint64) {
+			c.msgs.Add(l2sentMsg.MsgHash, &cachedL2SentMsg{msg: l2sentMsg, expiresAt: expiresAt})
+		}
+	}
+}
+
+// observe counts a lookup of the kind
+func (c *claimLookupCache) observe(kind string, hit bool) {
+	if c.lookups == nil {
+		return
+	}
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	c.lookups.WithLabelValues(kind, result).Inc()
+}
@@ -0,0 +1,82 @@
+package logic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestClaimLookupCacheSkipsTheImmutableLookups(t *testing.T) {
+	db, counter := newCountingDB(t, txsByHashesFixtures())
+	reg := prometheus.NewRegistry()
+	lookups := newClaimLookupCache(10, reg)
+	withdrawal := func() []*types.TxHistoryInfo {
+		return []*types.TxHistoryInfo{{MsgHash: "0xb1", FinalizeTx: &types.Finalized{}}}
+	}
+
+	require.NoError(t, updateCrossTxHashesAndL2TxClaimInfo(context.Background(), withdrawal(), db, "", lookups))
+	assert.Equal(t, 1, counter.calls["(*L2SentMsg).GetL2SentMsgsByHashes"])
+	assert.Equal(t, 1, counter.calls["(*RollupBatch).GetRollupBatchesByIndexes"])
+
+	// the finalized batch and the proven withdrawal are served from the cache, the latest batch is queried again
+	counter.calls = make(map[string]int)
+	txHistories := withdrawal()
+	require.NoError(t, updateCrossTxHashesAndL2TxClaimInfo(context.Background(), txHistories, db, "", lookups))
+	assert.Zero(t, counter.calls["(*L2SentMsg).GetL2SentMsgsByHashes"])
+	assert.Zero(t, counter.calls["(*RollupBatch).GetRollupBatchesByIndexes"])
+	assert.Zero(t, counter.calls["(*RollupBatch).GetRevertedRollupBatchesByIndexes"])
+	assert.Equal(t, 1, counter.calls["(*RollupBatch).GetLatestRollupBatch"])
+	require.NotNil(t, txHistories[0].ClaimInfo)
+	assert.Equal(t, "0xabcd", txHistories[0].ClaimInfo.Proof)
+
+	assert.Equal(t, 4, testutil.CollectAndCount(reg, "bridge_history_api_claim_lookup_cache_total"))
+	assert.Equal(t, float64(1), testutil.ToFloat64(lookups.lookups.WithLabelValues("batch", "hit")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(lookups.lookups.WithLabelValues("l2_sent_msg", "hit")))
+}
+
+func TestClaimLookupCacheEntries(t *testing.T) {
+	lookups := newClaimLookupCache(1, nil)
+	batchMap := map[uint64]*orm.RollupBatch{1: {BatchIndex: 1, FinalizeHeight: 10}, 2: {BatchIndex: 2}}
+	lookups.addBatches([]uint64{1, 2}, batchMap, nil)
+	_, found := lookups.batch(1)
+	assert.True(t, found)
+	// the batches not finalized yet may be reverted
+	_, found = lookups.batch(2)
+	assert.False(t, found)
+
+	// only the withdrawals proven against a finalized batch are cached
+	lookups.addL2SentMsgs([]*orm.L2SentMsg{{MsgHash: "0x01", BatchIndex: 1}, {MsgHash: "0x02", BatchIndex: 2, MsgProof: "ab"}}, batchMap)
+	_, found = lookups.l2SentMsg("0x01")
+	assert.False(t, found)
+	_, found = lookups.l2SentMsg("0x02")
+	assert.False(t, found)
+
+	// the least recently used entries are evicted over the size
+	lookups.addL2SentMsgs([]*orm.L2SentMsg{{MsgHash: "0x03", BatchIndex: 1, MsgProof: "ab"}, {MsgHash: "0x04", BatchIndex: 1, MsgProof: "cd"}}, batchMap)
+	_, found = lookups.l2SentMsg("0x03")
+	assert.False(t, found)
+	l2sentMsg, found := lookups.l2SentMsg("0x04")
+	assert.True(t, found)
+	assert.Equal(t, "cd", l2sentMsg.MsgProof)
+
+	// the expired entries are looked up again
+	entry, _ := lookups.msgs.Get("0x04")
+	entry.expiresAt = time.Now().Add(-time.Second)
+	_, found = lookups.l2SentMsg("0x04")
+	assert.False(t, found)
+
+	// a nil cache caches nothing
+	assert.Nil(t, newClaimLookupCache(0, nil))
+	var disabled *claimLookupCache
+	disabled.addBatches([]uint64{1}, batchMap, nil)
+	_, found = disabled.batch(1)
+	assert.False(t, found)
+}
@@ -0,0 +1,122 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/lru"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"golang.org/x/sync/errgroup"
+
+	"bridge-history-api/internal/types"
+)
+
+const (
+	// claimVerifierCacheSize is the number of the executed withdrawals remembered by the verifier
+	claimVerifierCacheSize = 10000
+	// claimVerifierConcurrency bounds the calls to the layer1 node in flight for one request
+	claimVerifierConcurrency = 8
+)
+
+// isL2MessageExecutedSelector is the selector of L1ScrollMessenger.isL2MessageExecuted(bytes32)
+var isL2MessageExecutedSelector = crypto.Keccak256([]byte("isL2MessageExecuted(bytes32)"))[:4]
+
+// ClaimVerifier checks the withdrawals indexed as claimable against the isL2MessageExecuted mapping of the layer1
+// messenger, so that the withdrawals claimed on layer1 but whose relay is not indexed yet, e.g. while the fetcher
+// lags behind, are not offered for claiming again. Only the executed withdrawals are cached, they never become
+// claimable again.
+type ClaimVerifier struct {
+	caller    ethereum.ContractCaller
+	messenger common.Address
+	executed  *lru.Cache[string, struct{}]
+}
+
+// NewClaimVerifier returns the verifier of the withdrawals with the layer1 messenger at messenger and the layer1 node
+// of caller
+func NewClaimVerifier(caller ethereum.ContractCaller, messenger common.Address) *ClaimVerifier {
+	return &ClaimVerifier{caller: caller, messenger: messenger, executed: lru.NewCache[string, struct{}](claimVerifierCacheSize)}
+}
+
+// IsL2MessageExecuted returns whether the withdrawal of msgHash is executed on layer1
+func (v *ClaimVerifier) IsL2MessageExecuted(ctx context.Context, msgHash string) (bool, error) {
+	msgHash = strings.ToLower(msgHash)
+	if v.executed.Contains(msgHash) {
+		return true, nil
+	}
+	data := append(append([]byte{}, isL2MessageExecutedSelector...), common.HexToHash(msgHash).Bytes()...)
+	output, err := v.caller.CallContract(ctx, ethereum.CallMsg{To: &v.messenger, Data: data}, nil)
+	if err != nil {
+		return false, err
+	}
+	if len(output) != common.HashLength {
+		return false, fmt.Errorf("unexpected output length %d of isL2MessageExecuted", len(output))
+	}
+	executed := common.BytesToHash(output) != (common.Hash{})
+	if executed {
+		v.executed.Add(msgHash, struct{}{})
+	}
+	return executed, nil
+}
+
+// executedMsgHashes returns the set of the given withdrawals executed on layer1. The withdrawals failing to be
+// checked are logged and treated as not executed, so that a layer1 node outage doesn't hide the claimable ones.
+// A nil verifier reports none executed.
+func (v *ClaimVerifier) executedMsgHashes(ctx context.Context, msgHashes []string) map[string]struct{} {
+	if v == nil || len(msgHashes) == 0 {
+		return nil
+	}
+	results := make([]bool, len(msgHashes))
+	var g errgroup.Group
+	g.SetLimit(claimVerifierConcurrency)
+	for i, msgHash := range msgHashes {
+		i, msgHash := i, msgHash
+		g.Go(func() error {
+			executed, err := v.IsL2MessageExecuted(ctx, msgHash)
+			if err != nil {
+				log.Warn("failed to check whether the withdrawal is executed on layer1", "msgHash", msgHash, "err", err)
+				return nil
+			}
+			results[i] = executed
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	executed := make(map[string]struct{})
+	for i, msgHash := range msgHashes {
+		if results[i] {
+			log.Warn("the withdrawal is executed on layer1 but its relay is not indexed", "msgHash", msgHash)
+			executed[msgHash] = struct{}{}
+		}
+	}
+	return executed
+}
+
+// dropExecutedClaimables removes the claimable withdrawals executed on layer1 from the tx histories, a nil verifier
+// removes none
+func (v *ClaimVerifier) dropExecutedClaimables(ctx context.Context, txHistories []*types.TxHistoryInfo) []*types.TxHistoryInfo {
+	if v == nil {
+		return txHistories
+	}
+	var msgHashes []string
+	for _, txHistory := range txHistories {
+		if !txHistory.IsL1 && !isRelayed(txHistory) && txHistory.ClaimStatus == types.ClaimStatusClaimable {
+			msgHashes = append(msgHashes, txHistory.MsgHash)
+		}
+	}
+	executed := v.executedMsgHashes(ctx, msgHashes)
+	if len(executed) == 0 {
+		return txHistories
+	}
+	kept := make([]*types.TxHistoryInfo, 0, len(txHistories)-len(executed))
+	for _, txHistory := range txHistories {
+		if _, found := executed[txHistory.MsgHash]; !found {
+			kept = append(kept, txHistory)
+		}
+	}
+	return kept
+}
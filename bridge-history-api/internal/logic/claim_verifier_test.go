@@ -0,0 +1,98 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// fakeMessengerCaller answers isL2MessageExecuted of the messenger by the message hash, the calls of the failing
+// hashes fail
+type fakeMessengerCaller struct {
+	mu       sync.Mutex
+	executed map[common.Hash]bool
+	failing  map[common.Hash]bool
+	calls    int
+}
+
+func (f *fakeMessengerCaller) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	msgHash := common.BytesToHash(call.Data[4:])
+	if f.failing[msgHash] {
+		return nil, errors.New("connection refused")
+	}
+	if f.executed[msgHash] {
+		return common.LeftPadBytes([]byte{1}, common.HashLength), nil
+	}
+	return make([]byte, common.HashLength), nil
+}
+
+func TestClaimVerifier(t *testing.T) {
+	executed, claimable, failing := "0x01", "0x02", "0x03"
+	caller := &fakeMessengerCaller{
+		executed: map[common.Hash]bool{common.HexToHash(executed): true},
+		failing:  map[common.Hash]bool{common.HexToHash(failing): true},
+	}
+	verifier := NewClaimVerifier(caller, common.HexToAddress("0x11"))
+
+	isExecuted, err := verifier.IsL2MessageExecuted(context.Background(), executed)
+	assert.NoError(t, err)
+	assert.True(t, isExecuted)
+	isExecuted, err = verifier.IsL2MessageExecuted(context.Background(), claimable)
+	assert.NoError(t, err)
+	assert.False(t, isExecuted)
+	_, err = verifier.IsL2MessageExecuted(context.Background(), failing)
+	assert.Error(t, err)
+	assert.Equal(t, 3, caller.calls)
+
+	// only the executed withdrawals are cached
+	_, err = verifier.IsL2MessageExecuted(context.Background(), executed)
+	assert.NoError(t, err)
+	_, err = verifier.IsL2MessageExecuted(context.Background(), claimable)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, caller.calls)
+
+	// the withdrawals failing to be checked are kept, the pending and the relayed ones are not checked
+	caller.calls = 0
+	txHistories := []*types.TxHistoryInfo{
+		{MsgHash: executed, ClaimStatus: types.ClaimStatusClaimable, FinalizeTx: &types.Finalized{}},
+		{MsgHash: claimable, ClaimStatus: types.ClaimStatusClaimable, FinalizeTx: &types.Finalized{}},
+		{MsgHash: failing, ClaimStatus: types.ClaimStatusClaimable, FinalizeTx: &types.Finalized{}},
+		{MsgHash: "0x04", ClaimStatus: types.ClaimStatusPending, FinalizeTx: &types.Finalized{}},
+		{MsgHash: "0x05", ClaimStatus: types.ClaimStatusClaimable, FinalizeTx: &types.Finalized{Hash: "0xaa"}},
+	}
+	kept := verifier.dropExecutedClaimables(context.Background(), txHistories)
+	var keptHashes []string
+	for _, txHistory := range kept {
+		keptHashes = append(keptHashes, txHistory.MsgHash)
+	}
+	assert.Equal(t, []string{claimable, failing, "0x04", "0x05"}, keptHashes)
+	assert.Equal(t, 2, caller.calls)
+
+	// a nil verifier trusts the indexed relays
+	var disabled *ClaimVerifier
+	assert.Equal(t, txHistories, disabled.dropExecutedClaimables(context.Background(), txHistories))
+	assert.Nil(t, disabled.executedMsgHashes(context.Background(), []string{executed}))
+}
+
+func TestGetClaimableSummary(t *testing.T) {
+	db, counter := newCountingDB(t, nil)
+	logic := NewHistoryLogic(nil, db, nil, nil)
+	address := common.HexToAddress("0x1c5a77d9fa7ef466951b2f01f724bca3a5820b63")
+
+	summary, err := logic.GetClaimableSummary(context.Background(), address)
+	assert.NoError(t, err)
+	assert.Equal(t, &types.ClaimableSummary{Address: address.Hex(), Tokens: []*types.StaleClaimableSummary{}}, summary)
+	assert.Equal(t, []interface{}{int(orm.ETH), int(orm.Layer2Msg), orm.Layer2Msg, address.Hex(), address.Hex()}, counter.vars["(*CrossMsg).GetClaimableWithdrawalsByAddress"])
+}
@@ -0,0 +1,133 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// defaultCacheTTL is short, the UI polls for the claim status. The cached histories are invalidated anyway once the
+// fetcher saves new messages, updates the proofs of the withdrawals and handles the reorgs
+const defaultCacheTTL = 15 * time.Second
+
+// Cache is the key value store caching the query results shared by the api servers and the fetcher, e.g. redis
+type Cache interface {
+	// Get returns the value of the key, found is false if the key doesn't exist or expired
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+	// Set stores the value of the key expiring after ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Del removes the keys, the keys not existing are ignored
+	Del(ctx context.Context, keys ...string) error
+}
+
+// claimableCacheKey returns the cache key of the claimable txs of the address
+func claimableCacheKey(address common.Address) string {
+	return "claimable:" + address.Hex()
+}
+
+// getCachedClaimables returns the cached claimable txs of the address refreshed as of now, nil if the cache is not
+// configured or missed
+func (h *HistoryLogic) getCachedClaimables(ctx context.Context, address common.Address) *types.ResultData {
+	var resultData types.ResultData
+	if !h.getCached(ctx, claimableCacheKey(address), &resultData) {
+		return nil
+	}
+	h.refreshCachedTxHistories(resultData.Result)
+	return &resultData
+}
+
+// setCachedClaimables caches the claimable txs of the address, nothing is done if the cache is not configured
+func (h *HistoryLogic) setCachedClaimables(ctx context.Context, address common.Address, resultData *types.ResultData) {
+	h.setCached(ctx, claimableCacheKey(address), resultData)
+}
+
+// getCached unmarshals the cached value of the key into value, false is returned if the cache is not configured,
+// missed or the cached value is corrupted
+func (h *HistoryLogic) getCached(ctx context.Context, key string, value interface{}) bool {
+	if h.cache == nil {
+		return false
+	}
+	cached, found, err := h.cache.Get(ctx, key)
+	if err != nil || !found {
+		if err != nil {
+			log.Debug("get cached value failed", "key", key, "error", err)
+		}
+		return false
+	}
+	if err = json.Unmarshal(cached, value); err != nil {
+		log.Debug("unmarshal cached value failed", "key", key, "error", err)
+		return false
+	}
+	return true
+}
+
+// setCached caches the value of the key for the configured ttl, nothing is done if the cache is not configured
+func (h *HistoryLogic) setCached(ctx context.Context, key string, value interface{}) {
+	if h.cache == nil {
+		return
+	}
+	cached, err := json.Marshal(value)
+	if err != nil {
+		log.Debug("marshal cached value failed", "key", key, "error", err)
+		return
+	}
+	if err = h.cache.Set(ctx, key, cached, h.cacheExpiry()); err != nil {
+		log.Debug("cache value failed", "key", key, "error", err)
+	}
+}
+
+// cacheExpiry returns the ttl of the cached values, defaultCacheTTL if not configured
+func (h *HistoryLogic) cacheExpiry() time.Duration {
+	if h.cacheTTL <= 0 {
+		return defaultCacheTTL
+	}
+	return h.cacheTTL
+}
+
+// InvalidateClaimableCache removes the cached claimable txs of the senders of the withdrawals relayed on layer1 by
+// the relayed msgs, it must run once the relayed msgs are saved so that claimed txs aren't shown as claimable.
+func InvalidateClaimableCache(ctx context.Context, cache Cache, db *gorm.DB, relayedMsgs []*orm.RelayedMsg) error {
+	var msgHashes []string
+	for _, relayedMsg := range relayedMsgs {
+		if relayedMsg.Layer1Hash != "" {
+			msgHashes = append(msgHashes, relayedMsg.MsgHash)
+		}
+	}
+	if cache == nil || len(msgHashes) == 0 {
+		return nil
+	}
+
+	l2sentMsgs, err := orm.NewL2SentMsg(db).GetL2SentMsgsByHashes(ctx, msgHashes)
+	if err != nil {
+		return err
+	}
+	keySet := make(map[string]struct{})
+	var keys []string
+	for _, l2sentMsg := range l2sentMsgs {
+		for _, sender := range []string{l2sentMsg.Sender, l2sentMsg.OriginalSender} {
+			if sender == "" {
+				continue
+			}
+			key := claimableCacheKey(common.HexToAddress(sender))
+			if _, exists := keySet[key]; !exists {
+				keySet[key] = struct{}{}
+				keys = append(keys, key)
+			}
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	if err = cache.Del(ctx, keys...); err != nil {
+		return fmt.Errorf("delete cached claimables error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+type memCache struct {
+	values  map[string][]byte
+	deleted []string
+}
+
+func newMemCache() *memCache {
+	return &memCache{values: make(map[string][]byte)}
+}
+
+func (m *memCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	value, found := m.values[key]
+	return value, found, nil
+}
+
+func (m *memCache) Set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	m.values[key] = value
+	return nil
+}
+
+func (m *memCache) Del(_ context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(m.values, key)
+		m.deleted = append(m.deleted, key)
+	}
+	return nil
+}
+
+func TestGetClaimableTxsByAddressCache(t *testing.T) {
+	address := common.HexToAddress("0x01")
+	cache := newMemCache()
+	db, counter := newCountingDB(t, map[string]interface{}{
+		(&orm.L2SentMsg{}).TableName(): []*orm.L2SentMsg{{MsgHash: "0xb1", Sender: address.Hex(), MsgProof: "abcd"}},
+	})
+	logic := &HistoryLogic{db: db, cache: cache}
+
+	// the sent msgs and their relays are queried in each db call
+	const queriesPerCall = 2
+
+	// miss: queried from the db and cached
+	txHistories, total, err := logic.GetClaimableTxsByAddress(context.Background(), address, nil, types.TokenTypeAll, types.SortOrderDesc)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), total)
+	assert.Len(t, txHistories, 1)
+	assert.Equal(t, queriesPerCall, counter.calls["(*L2SentMsg).GetClaimableL2SentMsgByAddress"])
+	assert.Contains(t, cache.values, claimableCacheKey(address))
+
+	// hit: served from the cache
+	txHistories, total, err = logic.GetClaimableTxsByAddress(context.Background(), address, nil, types.TokenTypeAll, types.SortOrderDesc)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), total)
+	assert.Len(t, txHistories, 1)
+	assert.Equal(t, "0xb1", txHistories[0].MsgHash)
+	assert.Equal(t, queriesPerCall, counter.calls["(*L2SentMsg).GetClaimableL2SentMsgByAddress"])
+
+	// the filtered queries aren't cached
+	_, _, err = logic.GetClaimableTxsByAddress(context.Background(), address, nil, types.TokenTypeERC20, types.SortOrderDesc)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*queriesPerCall, counter.calls["(*L2SentMsg).GetClaimableL2SentMsgByAddress"])
+
+	// a corrupted entry falls back to the db
+	cache.values[claimableCacheKey(address)] = []byte("{")
+	_, total, err = logic.GetClaimableTxsByAddress(context.Background(), address, nil, types.TokenTypeAll, types.SortOrderDesc)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), total)
+	assert.Equal(t, 3*queriesPerCall, counter.calls["(*L2SentMsg).GetClaimableL2SentMsgByAddress"])
+
+	// no cache configured
+	logic.cache = nil
+	_, total, err = logic.GetClaimableTxsByAddress(context.Background(), address, nil, types.TokenTypeAll, types.SortOrderDesc)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), total)
+	assert.Equal(t, 4*queriesPerCall, counter.calls["(*L2SentMsg).GetClaimableL2SentMsgByAddress"])
+}
+
+func TestGetClaimableTxsByAddressCacheRefresh(t *testing.T) {
+	address := common.HexToAddress("0x01")
+	cache := newMemCache()
+	db, counter := newCountingDB(t, nil)
+	logic := &HistoryLogic{db: db, cache: cache, includeRelativeTime: true, claimExpiry: time.Hour}
+
+	// cached an hour ago by a server not expiring the claims
+	blockTimestamp := time.Now().Add(-2 * time.Hour)
+	cached, err := json.Marshal(&types.ResultData{Result: []*types.TxHistoryInfo{{
+		MsgHash:        "0xb1",
+		BlockTimestamp: &blockTimestamp,
+		RelativeTime:   "1 hour ago",
+		ClaimInfo:      &types.UserClaimInfo{},
+	}}, Total: 1})
+	assert.NoError(t, err)
+	cache.values[claimableCacheKey(address)] = cached
+
+	txHistories, _, err := logic.GetClaimableTxsByAddress(context.Background(), address, nil, types.TokenTypeAll, types.SortOrderDesc)
+	assert.NoError(t, err)
+	assert.Zero(t, counter.calls["(*L2SentMsg).GetClaimableL2SentMsgByAddress"])
+	assert.Len(t, txHistories, 1)
+	assert.Equal(t, "2 hours ago", txHistories[0].RelativeTime)
+	if assert.NotNil(t, txHistories[0].ClaimInfo.ClaimExpiresAt) {
+		assert.True(t, blockTimestamp.Add(time.Hour).Equal(*txHistories[0].ClaimInfo.ClaimExpiresAt))
+	}
+}
+
+func TestInvalidateClaimableCache(t *testing.T) {
+	sender := common.HexToAddress("0x01")
+	originalSender := common.HexToAddress("0x02")
+	other := common.HexToAddress("0x03")
+	db, _ := newCountingDB(t, map[string]interface{}{
+		(&orm.L2SentMsg{}).TableName(): []*orm.L2SentMsg{{MsgHash: "0xb1", Sender: sender.Hex(), OriginalSender: originalSender.Hex()}},
+	})
+	cache := newMemCache()
+	cached, err := json.Marshal(&types.ResultData{Total: 1})
+	assert.NoError(t, err)
+	for _, address := range []common.Address{sender, originalSender, other} {
+		cache.values[claimableCacheKey(address)] = cached
+	}
+
+	// the relays on layer2 don't claim withdrawals
+	assert.NoError(t, InvalidateClaimableCache(context.Background(), cache, db, []*orm.RelayedMsg{{MsgHash: "0xa1", Layer2Hash: "0x01"}}))
+	assert.Empty(t, cache.deleted)
+
+	assert.NoError(t, InvalidateClaimableCache(context.Background(), cache, db, []*orm.RelayedMsg{{MsgHash: "0xb1", Layer1Hash: "0x02"}}))
+	assert.ElementsMatch(t, []string{claimableCacheKey(sender), claimableCacheKey(originalSender)}, cache.deleted)
+	assert.Contains(t, cache.values, claimableCacheKey(other))
+
+	// no cache configured
+	assert.NoError(t, InvalidateClaimableCache(context.Background(), nil, db, []*orm.RelayedMsg{{MsgHash: "0xb1", Layer1Hash: "0x02"}}))
+}
@@ -0,0 +1,189 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+const (
+	// claimableWatchBuffer is the number of events buffered per watcher, the events overflowing a slow watcher are dropped
+	claimableWatchBuffer = 16
+	// claimableResubscribeInterval is the wait before subscribing again once the subscription failed
+	claimableResubscribeInterval = 5 * time.Second
+)
+
+// ClaimableEvents carries the claimable events from the fetcher writing the messages to the api servers pushing them
+// to the watchers, e.g. redis pub/sub
+type ClaimableEvents interface {
+	// Publish sends the events to the current subscribers
+	Publish(ctx context.Context, events ...*types.ClaimableEvent) error
+	// Subscribe returns the events published from now on, the channel is closed once ctx is done or the subscription fails
+	Subscribe(ctx context.Context) (<-chan *types.ClaimableEvent, error)
+}
+
+// eventAddresses returns the distinct non empty addresses checksummed in order, the events of a message are sent once
+// to each of its addresses
+func eventAddresses(addresses ...string) []common.Address {
+	var distinct []common.Address
+	seen := make(map[common.Address]struct{}, len(addresses))
+	for _, address := range addresses {
+		if address == "" {
+			continue
+		}
+		checksummed := common.HexToAddress(address)
+		if _, exists := seen[checksummed]; exists {
+			continue
+		}
+		seen[checksummed] = struct{}{}
+		distinct = append(distinct, checksummed)
+	}
+	return distinct
+}
+
+// withdrawalEvents returns the events of the sender and the original sender of the withdrawal
+func withdrawalEvents(eventType types.ClaimableEventType, l2SentMsg *orm.L2SentMsg, txHash string) []*types.ClaimableEvent {
+	var events []*types.ClaimableEvent
+	for _, address := range eventAddresses(l2SentMsg.Sender, l2SentMsg.OriginalSender) {
+		events = append(events, &types.ClaimableEvent{
+			Type:    eventType,
+			Address: address.Hex(),
+			MsgHash: l2SentMsg.MsgHash,
+			TxHash:  txHash,
+		})
+	}
+	return events
+}
+
+// PublishClaimableEvents publishes the claimable events of the withdrawals whose proofs are saved, nothing is done if
+// the events are not configured
+func PublishClaimableEvents(ctx context.Context, claimableEvents ClaimableEvents, l2SentMsgs []*orm.L2SentMsg) error {
+	if claimableEvents == nil {
+		return nil
+	}
+	var events []*types.ClaimableEvent
+	for _, l2SentMsg := range l2SentMsgs {
+		events = append(events, withdrawalEvents(types.ClaimableEventClaimable, l2SentMsg, l2SentMsg.TxHash)...)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	if err := claimableEvents.Publish(ctx, events...); err != nil {
+		return fmt.Errorf("publish claimable events error: %w", err)
+	}
+	return nil
+}
+
+// PublishFinalizedEvents publishes the finalized events of the withdrawals relayed by the relayed msgs saved on layer1,
+// the relays of the deposits are skipped. Nothing is done if the events are not configured.
+func PublishFinalizedEvents(ctx context.Context, claimableEvents ClaimableEvents, db *gorm.DB, relayedMsgs []*orm.RelayedMsg) error {
+	if claimableEvents == nil {
+		return nil
+	}
+	relayTxHashes := make(map[string]string, len(relayedMsgs))
+	var msgHashes []string
+	for _, relayedMsg := range relayedMsgs {
+		if relayedMsg.Layer1Hash == "" {
+			continue
+		}
+		relayTxHashes[relayedMsg.MsgHash] = relayedMsg.Layer1Hash
+		msgHashes = append(msgHashes, relayedMsg.MsgHash)
+	}
+	if len(msgHashes) == 0 {
+		return nil
+	}
+	l2SentMsgs, err := orm.NewL2SentMsg(db).GetL2SentMsgsByHashes(ctx, msgHashes)
+	if err != nil {
+		return err
+	}
+	var events []*types.ClaimableEvent
+	for _, l2SentMsg := range l2SentMsgs {
+		events = append(events, withdrawalEvents(types.ClaimableEventFinalized, l2SentMsg, relayTxHashes[l2SentMsg.MsgHash])...)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+	if err = claimableEvents.Publish(ctx, events...); err != nil {
+		return fmt.Errorf("publish finalized events error: %w", err)
+	}
+	return nil
+}
+
+// ClaimableWatcher fans the subscribed claimable events out to the watchers of their addresses
+type ClaimableWatcher struct {
+	events ClaimableEvents
+
+	mu       sync.Mutex
+	watchers map[common.Address]map[chan *types.ClaimableEvent]struct{}
+}
+
+// NewClaimableWatcher returns the ClaimableWatcher of the events, nil is returned if the events are not configured
+func NewClaimableWatcher(events ClaimableEvents) *ClaimableWatcher {
+	if events == nil {
+		return nil
+	}
+	return &ClaimableWatcher{
+		events:   events,
+		watchers: make(map[common.Address]map[chan *types.ClaimableEvent]struct{}),
+	}
+}
+
+// Run dispatches the subscribed events to the watchers until ctx is done, subscribing again if the subscription fails
+func (w *ClaimableWatcher) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		events, err := w.events.Subscribe(ctx)
+		if err != nil {
+			log.Error("ClaimableWatcher: can not subscribe the claimable events", "err", err)
+		} else {
+			for event := range events {
+				w.dispatch(event)
+			}
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(claimableResubscribeInterval):
+		}
+	}
+}
+
+// Watch returns the events of the address and the func to stop watching, which must be called once done
+func (w *ClaimableWatcher) Watch(address common.Address) (<-chan *types.ClaimableEvent, func()) {
+	watcher := make(chan *types.ClaimableEvent, claimableWatchBuffer)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.watchers[address] == nil {
+		w.watchers[address] = make(map[chan *types.ClaimableEvent]struct{})
+	}
+	w.watchers[address][watcher] = struct{}{}
+	return watcher, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.watchers[address], watcher)
+		if len(w.watchers[address]) == 0 {
+			delete(w.watchers, address)
+		}
+	}
+}
+
+// dispatch sends the event to the watchers of its address without blocking, the watchers whose buffers are full
+// miss the event
+func (w *ClaimableWatcher) dispatch(event *types.ClaimableEvent) {
+	address := common.HexToAddress(event.Address)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for watcher := range w.watchers[address] {
+		select {
+		case watcher <- event:
+		default:
+			log.Debug("ClaimableWatcher: watcher is full, event dropped", "address", address, "msgHash", event.MsgHash)
+		}
+	}
+}
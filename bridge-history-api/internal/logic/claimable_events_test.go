@@ -0,0 +1,108 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// memEvents is the ClaimableEvents recording the published events
+type memEvents struct {
+	published []*types.ClaimableEvent
+}
+
+func (m *memEvents) Publish(_ context.Context, events ...*types.ClaimableEvent) error {
+	m.published = append(m.published, events...)
+	return nil
+}
+
+func (m *memEvents) Subscribe(ctx context.Context) (<-chan *types.ClaimableEvent, error) {
+	events := make(chan *types.ClaimableEvent)
+	go func() {
+		defer close(events)
+		for _, event := range m.published {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+func TestPublishClaimableEvents(t *testing.T) {
+	sender := common.HexToAddress("0x01")
+	originalSender := common.HexToAddress("0x02")
+	events := &memEvents{}
+
+	assert.NoError(t, PublishClaimableEvents(context.Background(), nil, []*orm.L2SentMsg{{MsgHash: "0xa1", Sender: sender.Hex()}}))
+
+	assert.NoError(t, PublishClaimableEvents(context.Background(), events, []*orm.L2SentMsg{
+		{MsgHash: "0xa1", TxHash: "0x11", Sender: sender.Hex(), OriginalSender: originalSender.Hex()},
+		// the sender and the original sender are notified once if they're the same
+		{MsgHash: "0xa2", TxHash: "0x12", Sender: sender.Hex(), OriginalSender: sender.Hex()},
+	}))
+	assert.Equal(t, []*types.ClaimableEvent{
+		{Type: types.ClaimableEventClaimable, Address: sender.Hex(), MsgHash: "0xa1", TxHash: "0x11"},
+		{Type: types.ClaimableEventClaimable, Address: originalSender.Hex(), MsgHash: "0xa1", TxHash: "0x11"},
+		{Type: types.ClaimableEventClaimable, Address: sender.Hex(), MsgHash: "0xa2", TxHash: "0x12"},
+	}, events.published)
+}
+
+func TestPublishFinalizedEvents(t *testing.T) {
+	withdrawer := common.HexToAddress("0x01")
+	db, counter := newCountingDB(t, map[string]interface{}{
+		(&orm.L2SentMsg{}).TableName(): []*orm.L2SentMsg{{MsgHash: "0xa1", TxHash: "0x11", Sender: withdrawer.Hex()}},
+	})
+	events := &memEvents{}
+
+	// the relays on layer2 are deposits
+	assert.NoError(t, PublishFinalizedEvents(context.Background(), events, db, []*orm.RelayedMsg{{MsgHash: "0xb1", Layer2Hash: "0x21"}}))
+	assert.Zero(t, counter.calls["(*L2SentMsg).GetL2SentMsgsByHashes"])
+	assert.Empty(t, events.published)
+
+	assert.NoError(t, PublishFinalizedEvents(context.Background(), events, db, []*orm.RelayedMsg{{MsgHash: "0xa1", Layer1Hash: "0x31"}}))
+	assert.Equal(t, []*types.ClaimableEvent{
+		{Type: types.ClaimableEventFinalized, Address: withdrawer.Hex(), MsgHash: "0xa1", TxHash: "0x31"},
+	}, events.published)
+}
+
+func TestClaimableWatcher(t *testing.T) {
+	assert.Nil(t, NewClaimableWatcher(nil))
+
+	address1 := common.HexToAddress("0x01")
+	address2 := common.HexToAddress("0x02")
+	events := &memEvents{published: []*types.ClaimableEvent{
+		{Type: types.ClaimableEventClaimable, Address: address1.Hex(), MsgHash: "0xa1"},
+		{Type: types.ClaimableEventClaimable, Address: address2.Hex(), MsgHash: "0xa2"},
+		{Type: types.ClaimableEventFinalized, Address: address1.Hex(), MsgHash: "0xa1"},
+	}}
+	watcher := NewClaimableWatcher(events)
+	watched1, unwatch1 := watcher.Watch(address1)
+	watched2, unwatch2 := watcher.Watch(address2)
+	unwatch2()
+
+	subscribed, err := events.Subscribe(context.Background())
+	assert.NoError(t, err)
+	for event := range subscribed {
+		watcher.dispatch(event)
+	}
+	assert.Equal(t, "0xa1", (<-watched1).MsgHash)
+	assert.Equal(t, types.ClaimableEventFinalized, (<-watched1).Type)
+	assert.Empty(t, watched2)
+
+	// the events overflowing the buffer are dropped
+	for i := 0; i < claimableWatchBuffer+1; i++ {
+		watcher.dispatch(events.published[0])
+	}
+	assert.Len(t, watched1, claimableWatchBuffer)
+
+	unwatch1()
+	assert.Empty(t, watcher.watchers)
+}
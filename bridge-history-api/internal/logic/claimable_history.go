@@ -0,0 +1,135 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// maxClaimableHistoryDays is the upper bound of the days of a claimable history query
+const maxClaimableHistoryDays = 366
+
+// claimableInterval is the period a layer2 withdrawal is claimable on layer1,
+// it starts at the finalization of its batch and ends at its relay on layer1.
+type claimableInterval struct {
+	value *big.Int
+	// claimableAt is nil if the batch is not finalized yet or the finalize timestamp is not fetched yet
+	claimableAt *time.Time
+	// claimedAt is nil if the withdrawal is not claimed yet
+	claimedAt *time.Time
+}
+
+// claimableAtEndOf returns whether the withdrawal is claimable right before the given time
+func (c *claimableInterval) claimableAtEndOf(end time.Time) bool {
+	if c.claimableAt == nil || !c.claimableAt.Before(end) {
+		return false
+	}
+	return c.claimedAt == nil || !c.claimedAt.Before(end)
+}
+
+// claimableHistory reconstructs the claimable totals at the end of each UTC day in [from, to].
+func claimableHistory(intervals []*claimableInterval, from, to time.Time) []*types.ClaimableHistoryPoint {
+	var points []*types.ClaimableHistoryPoint
+	for day := truncateToDay(from); !day.After(to); day = day.AddDate(0, 0, 1) {
+		endOfDay := day.AddDate(0, 0, 1)
+		point := &types.ClaimableHistoryPoint{Date: day}
+		value := new(big.Int)
+		for _, interval := range intervals {
+			if interval.claimableAtEndOf(endOfDay) {
+				value.Add(value, interval.value)
+				point.Count++
+			}
+		}
+		point.Value = value.String()
+		points = append(points, point)
+	}
+	return points
+}
+
+func truncateToDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
+
+// GetClaimableHistory get the claimable totals of the address at the end of each day in [from, to].
+//
+// The claimability is not stored per day, it is reconstructed as of each day instead: every withdrawal of
+// the address is claimable from the layer1 finalize timestamp of its batch, until it is relayed on layer1.
+// Withdrawals whose batch or relay timestamps are not fetched yet are treated as not finalized or not claimed.
+// All the withdrawals of the address are loaded, the cost grows with the number of withdrawals times the days.
+func (h *HistoryLogic) GetClaimableHistory(ctx context.Context, address common.Address, from, to time.Time) ([]*types.ClaimableHistoryPoint, error) {
+	return instrument(h.metrics, "GetClaimableHistory", func() ([]*types.ClaimableHistoryPoint, error) {
+		return h.getClaimableHistory(ctx, address, from, to)
+	})
+}
+
+// getClaimableHistory implements GetClaimableHistory
+func (h *HistoryLogic) getClaimableHistory(ctx context.Context, address common.Address, from, to time.Time) ([]*types.ClaimableHistoryPoint, error) {
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: from %v is after to %v", ErrInvalidParameter, from, to)
+	}
+	if to.Sub(truncateToDay(from)) >= maxClaimableHistoryDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: range of more than %d days", ErrInvalidParameter, maxClaimableHistoryDays)
+	}
+
+	l2sentMsgs, err := orm.NewL2SentMsg(h.db).GetL2SentMsgsByAddress(ctx, address.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if len(l2sentMsgs) == 0 {
+		return claimableHistory(nil, from, to), nil
+	}
+
+	batchIndexes := make([]uint64, 0, len(l2sentMsgs))
+	msgHashes := make([]string, 0, len(l2sentMsgs))
+	for _, l2sentMsg := range l2sentMsgs {
+		batchIndexes = append(batchIndexes, l2sentMsg.BatchIndex)
+		msgHashes = append(msgHashes, l2sentMsg.MsgHash)
+	}
+
+	batches, err := orm.NewRollupBatch(h.db).GetRollupBatchesByIndexes(ctx, batchIndexes)
+	if err != nil {
+		return nil, err
+	}
+	batchMap := make(map[uint64]*orm.RollupBatch, len(batches))
+	for _, batch := range batches {
+		batchMap[batch.BatchIndex] = batch
+	}
+
+	relayedMsgs, err := orm.NewRelayedMsg(h.db).GetRelayedMsgsByHashes(ctx, msgHashes)
+	if err != nil {
+		return nil, err
+	}
+	relayedMsgMap := make(map[string]*orm.RelayedMsg, len(relayedMsgs))
+	for _, relayedMsg := range relayedMsgs {
+		relayedMsgMap[relayedMsg.MsgHash] = relayedMsg
+	}
+
+	intervals := make([]*claimableInterval, 0, len(l2sentMsgs))
+	for _, l2sentMsg := range l2sentMsgs {
+		value, ok := new(big.Int).SetString(l2sentMsg.Value, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid value %s of msg %s", l2sentMsg.Value, l2sentMsg.MsgHash)
+		}
+		interval := &claimableInterval{value: value}
+		// the batch index is 0 until the message is committed in a batch
+		if batch, found := batchMap[l2sentMsg.BatchIndex]; found && l2sentMsg.BatchIndex != 0 && batch.IsFinalized() {
+			interval.claimableAt = batch.FinalizeTimestamp
+		}
+		if relayedMsg, found := relayedMsgMap[l2sentMsg.MsgHash]; found {
+			interval.claimedAt = relayedMsg.Timestamp
+		}
+		intervals = append(intervals, interval)
+	}
+	return claimableHistory(intervals, from, to), nil
+}
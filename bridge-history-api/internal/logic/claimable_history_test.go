@@ -0,0 +1,52 @@
+package logic
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaimableHistory(t *testing.T) {
+	day1 := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	at := func(days int, hours int) *time.Time {
+		ts := day1.AddDate(0, 0, days).Add(time.Duration(hours) * time.Hour)
+		return &ts
+	}
+
+	intervals := []*claimableInterval{
+		// finalized on day 1, claimed on day 3
+		{value: big.NewInt(100), claimableAt: at(0, 10), claimedAt: at(2, 12)},
+		// finalized on day 2, never claimed
+		{value: big.NewInt(20), claimableAt: at(1, 23)},
+		// not finalized yet
+		{value: big.NewInt(3)},
+	}
+
+	points := claimableHistory(intervals, day1.Add(5*time.Hour), *at(3, 1))
+	assert.Len(t, points, 4)
+	expected := []struct {
+		value string
+		count uint64
+	}{
+		{"100", 1},
+		{"120", 2},
+		// claimed mid-range
+		{"20", 1},
+		{"20", 1},
+	}
+	for i, point := range points {
+		assert.Equal(t, day1.AddDate(0, 0, i), point.Date)
+		assert.Equal(t, expected[i].value, point.Value)
+		assert.Equal(t, expected[i].count, point.Count)
+	}
+
+	// a withdrawal claimed at the end of the day is not claimable at the end of that day
+	points = claimableHistory([]*claimableInterval{
+		{value: big.NewInt(1), claimableAt: at(0, 1), claimedAt: at(0, 23)},
+	}, day1, day1)
+	assert.Len(t, points, 1)
+	assert.Equal(t, "0", points[0].Value)
+	assert.Zero(t, points[0].Count)
+}
@@ -0,0 +1,122 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/patrickmn/go-cache"
+
+	"bridge-history-api/config"
+)
+
+const (
+	// defaultENSRegistryAddr is the ENS registry deployed at the same address on mainnet and the testnets
+	defaultENSRegistryAddr = "0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e"
+	// defaultENSCacheTTL is the time the resolutions are cached by default
+	defaultENSCacheTTL = 10 * time.Minute
+)
+
+var (
+	// ensResolverSelector is the selector of ENS.resolver(bytes32)
+	ensResolverSelector = crypto.Keccak256([]byte("resolver(bytes32)"))[:4]
+	// ensAddrSelector is the selector of Resolver.addr(bytes32)
+	ensAddrSelector = crypto.Keccak256([]byte("addr(bytes32)"))[:4]
+)
+
+// ENSResolver resolves the ENS names into the addresses with the ENS registry of layer1, the resolutions including
+// the names not registered are cached
+type ENSResolver struct {
+	caller   ethereum.ContractCaller
+	registry common.Address
+	cache    *cache.Cache
+}
+
+// NewENSResolver returns the resolver of the names with the registry of cfg and the layer1 node of caller, nil if cfg
+// is nil
+func NewENSResolver(cfg *config.ENSConfig, caller ethereum.ContractCaller) *ENSResolver {
+	if cfg == nil {
+		return nil
+	}
+	registry := cfg.RegistryAddr
+	if registry == "" {
+		registry = defaultENSRegistryAddr
+	}
+	ttl := time.Duration(cfg.CacheTTL) * time.Second
+	if ttl == 0 {
+		ttl = defaultENSCacheTTL
+	}
+	return &ENSResolver{caller: caller, registry: common.HexToAddress(registry), cache: cache.New(ttl, 2*ttl)}
+}
+
+// IsENSName reports whether the address parameter is an ENS name rather than a hex address, e.g. "vitalik.eth"
+func IsENSName(address string) bool {
+	return strings.Contains(address, ".")
+}
+
+// Resolve returns the address the name resolves to. The names are matched case insensitively, the other UTS-46
+// normalizations are not applied. ErrNotFound is returned if the name has no resolver or no address.
+func (r *ENSResolver) Resolve(ctx context.Context, name string) (common.Address, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if cached, found := r.cache.Get(name); found {
+		return r.resolved(name, cached.(common.Address))
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" {
+			return common.Address{}, fmt.Errorf("%w: invalid ENS name %q", ErrInvalidParameter, name)
+		}
+	}
+
+	node := ensNamehash(name)
+	resolver, err := r.callAddress(ctx, r.registry, ensResolverSelector, node)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to get the resolver of the ENS name %q: %w", name, err)
+	}
+	var address common.Address
+	if resolver != (common.Address{}) {
+		if address, err = r.callAddress(ctx, resolver, ensAddrSelector, node); err != nil {
+			return common.Address{}, fmt.Errorf("failed to resolve the ENS name %q: %w", name, err)
+		}
+	}
+	r.cache.SetDefault(name, address)
+	return r.resolved(name, address)
+}
+
+// resolved returns the resolved address, ErrNotFound if the name resolves to none
+func (r *ENSResolver) resolved(name string, address common.Address) (common.Address, error) {
+	if address == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("%w: the ENS name %q resolves to no address", ErrNotFound, name)
+	}
+	return address, nil
+}
+
+// callAddress calls the method of the selector taking the node and returning an address, the zero address is
+// returned if the contract has no code
+func (r *ENSResolver) callAddress(ctx context.Context, contract common.Address, selector []byte, node common.Hash) (common.Address, error) {
+	data := append(append([]byte{}, selector...), node.Bytes()...)
+	output, err := r.caller.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(output) < common.HashLength {
+		return common.Address{}, nil
+	}
+	return common.BytesToAddress(output[:common.HashLength]), nil
+}
+
+// ensNamehash returns the namehash of the ENS name, the node of the name in the registry
+func ensNamehash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = crypto.Keccak256Hash(node.Bytes(), crypto.Keccak256([]byte(labels[i])))
+	}
+	return node
+}
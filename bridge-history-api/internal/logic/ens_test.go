@@ -0,0 +1,66 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/config"
+)
+
+// fakeENSCaller answers the calls of the registry and the resolvers by the contract and the node
+type fakeENSCaller struct {
+	addresses map[common.Address]map[common.Hash]common.Address
+	calls     int
+}
+
+func (f *fakeENSCaller) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	f.calls++
+	contract, ok := f.addresses[*call.To]
+	if !ok {
+		return nil, nil
+	}
+	return common.LeftPadBytes(contract[common.BytesToHash(call.Data[4:])].Bytes(), common.HashLength), nil
+}
+
+func TestENSNamehash(t *testing.T) {
+	assert.Equal(t, common.Hash{}, ensNamehash(""))
+	assert.Equal(t, common.HexToHash("0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"), ensNamehash("eth"))
+	assert.Equal(t, common.HexToHash("0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"), ensNamehash("foo.eth"))
+}
+
+func TestENSResolver(t *testing.T) {
+	registry, resolver := common.HexToAddress(defaultENSRegistryAddr), common.HexToAddress("0x01")
+	owner := common.HexToAddress("0x1c5a77d9fa7ef466951b2f01f724bca3a5820b63")
+	caller := &fakeENSCaller{addresses: map[common.Address]map[common.Hash]common.Address{
+		registry: {ensNamehash("foo.eth"): resolver, ensNamehash("empty.eth"): resolver},
+		resolver: {ensNamehash("foo.eth"): owner},
+	}}
+	ens := NewENSResolver(&config.ENSConfig{}, caller)
+
+	address, err := ens.Resolve(context.Background(), "Foo.eth")
+	assert.NoError(t, err)
+	assert.Equal(t, owner, address)
+	assert.Equal(t, 2, caller.calls)
+	// the resolution is cached
+	address, err = ens.Resolve(context.Background(), "foo.eth")
+	assert.NoError(t, err)
+	assert.Equal(t, owner, address)
+	assert.Equal(t, 2, caller.calls)
+
+	for _, name := range []string{"bar.eth", "empty.eth"} {
+		_, err = ens.Resolve(context.Background(), name)
+		assert.True(t, errors.Is(err, ErrNotFound), name)
+	}
+	_, err = ens.Resolve(context.Background(), "foo..eth")
+	assert.True(t, errors.Is(err, ErrInvalidParameter))
+
+	assert.Nil(t, NewENSResolver(nil, caller))
+	assert.True(t, IsENSName("foo.eth"))
+	assert.False(t, IsENSName(owner.Hex()))
+}
@@ -0,0 +1,49 @@
+package logic
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrInvalidParameter the parameters of the query are invalid
+	ErrInvalidParameter = errors.New("invalid parameter")
+	// ErrNotFound the queried record doesn't exist, an empty list of txs is not an error
+	ErrNotFound = errors.New("not found")
+	// ErrConflict the record to create already exists
+	ErrConflict = errors.New("conflict")
+	// ErrDatabase the db failed to serve the query, e.g. the connection is dropped or the query timed out
+	ErrDatabase = errors.New("database failure")
+)
+
+// dbError is a database failure, the underlying error is still matched by errors.Is and errors.As
+type dbError struct {
+	err error
+}
+
+func (e *dbError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrDatabase, e.err)
+}
+
+func (e *dbError) Unwrap() error {
+	return e.err
+}
+
+func (e *dbError) Is(target error) bool {
+	return target == ErrDatabase
+}
+
+// classifyError classifies the error returned to the callers of the exported methods: the invalid parameters and the
+// conflicts are returned as is, the missing records are ErrNotFound and the other errors are ErrDatabase.
+func classifyError(err error) error {
+	switch {
+	case err == nil, errors.Is(err, ErrInvalidParameter), errors.Is(err, ErrNotFound), errors.Is(err, ErrConflict), errors.Is(err, ErrDatabase):
+		return err
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fmt.Errorf("%w: %v", ErrNotFound, err)
+	default:
+		return &dbError{err: err}
+	}
+}
@@ -0,0 +1,78 @@
+package logic
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/types"
+)
+
+// newFailingDB returns a dry run db failing every query with err
+func newFailingDB(t *testing.T, err error) *gorm.DB {
+	db, _ := newCountingDB(t, nil)
+	if registerErr := db.Callback().Query().Before("gorm:query").Register("test:fail", func(db *gorm.DB) {
+		_ = db.AddError(err)
+	}); registerErr != nil {
+		t.Fatal(registerErr)
+	}
+	return db
+}
+
+func TestClassifyError(t *testing.T) {
+	assert.NoError(t, classifyError(nil))
+
+	invalid := fmt.Errorf("%w: unknown token type", ErrInvalidParameter)
+	assert.Equal(t, invalid, classifyError(invalid))
+
+	notFound := classifyError(fmt.Errorf("RollupBatch.GetRollupBatchByIndex error: %w", gorm.ErrRecordNotFound))
+	assert.ErrorIs(t, notFound, ErrNotFound)
+	assert.NotErrorIs(t, notFound, ErrDatabase)
+
+	dbErr := classifyError(fmt.Errorf("CrossMsg.GetCrossMsgsByHashes error: %w", driver.ErrBadConn))
+	assert.ErrorIs(t, dbErr, ErrDatabase)
+	assert.ErrorIs(t, dbErr, driver.ErrBadConn)
+	// classified once
+	assert.Equal(t, dbErr, classifyError(dbErr))
+}
+
+func TestQueryErrors(t *testing.T) {
+	address := common.HexToAddress("0x01")
+
+	// an empty history is not an error
+	db, _ := newCountingDB(t, nil)
+	logic := NewHistoryLogic(nil, db, nil, nil)
+	txHistories, total, err := logic.GetTxsByAddress(context.Background(), address, 1, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, txHistories)
+	assert.Equal(t, uint64(0), total)
+	txHistories, err = logic.GetTxsByHashes(context.Background(), []string{common.HexToHash("0x01").Hex()}, types.TokenTypeAll, types.SortOrderDesc)
+	assert.NoError(t, err)
+	assert.Empty(t, txHistories)
+
+	_, err = logic.GetTxsByHashes(context.Background(), []string{"0xzz"}, types.TokenTypeAll, types.SortOrderDesc)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+	assert.NotErrorIs(t, err, ErrDatabase)
+	_, _, err = logic.GetClaimableTxsByAddress(context.Background(), address, nil, types.TokenType("unknown"), types.SortOrderDesc)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+
+	// the dropped connection
+	logic = NewHistoryLogic(nil, newFailingDB(t, driver.ErrBadConn), nil, nil)
+	_, _, err = logic.GetTxsByAddress(context.Background(), address, 1, 10)
+	assert.ErrorIs(t, err, ErrDatabase)
+	assert.ErrorIs(t, err, driver.ErrBadConn)
+	_, _, err = logic.GetClaimableTxsByAddress(context.Background(), address, nil, types.TokenTypeAll, types.SortOrderDesc)
+	assert.ErrorIs(t, err, ErrDatabase)
+
+	// the missing batch
+	_, err = NewBatchLogic(newFailingDB(t, gorm.ErrRecordNotFound)).GetWithdrawRootByBatchIndex(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = NewBatchLogic(newFailingDB(t, errors.New("connection reset by peer"))).GetWithdrawRootByBatchIndex(context.Background(), 1)
+	assert.ErrorIs(t, err, ErrDatabase)
+}
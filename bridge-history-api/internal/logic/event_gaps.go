@@ -0,0 +1,105 @@
+package logic
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"bridge-history-api/config"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// maxNonceGaps is the upper bound of the ranges of the missing nonces returned by a gap scan
+const maxNonceGaps = 100
+
+// batchesEventType the event type of the checkpoints of the batches, starting at the batch index start block rather
+// than at the start height of layer1
+const batchesEventType = "batches"
+
+// GapLogic scans the checkpoints and the nonces of the indexed events for the gaps, for the operators
+type GapLogic struct {
+	// the first blocks the events are indexed from, the blocks before have no gap. 0 if unknown, the ranges before the
+	// checkpoints are not reported then
+	startHeights    map[orm.MsgType]uint64
+	batchStartBlock uint64
+	checkpointOrm   *orm.EventCheckpoint
+	l2SentMsgOrm    *orm.L2SentMsg
+}
+
+// NewGapLogic returns the GapLogic of the network of cfg backed with a "db"
+func NewGapLogic(cfg *config.Config, db *gorm.DB) *GapLogic {
+	g := &GapLogic{
+		startHeights:  make(map[orm.MsgType]uint64),
+		checkpointOrm: orm.NewEventCheckpoint(db),
+		l2SentMsgOrm:  orm.NewL2SentMsg(db),
+	}
+	if cfg.L1 != nil {
+		g.startHeights[orm.Layer1Msg] = cfg.L1.StartHeight
+	}
+	if cfg.L2 != nil {
+		g.startHeights[orm.Layer2Msg] = cfg.L2.StartHeight
+	}
+	if cfg.BatchInfoFetcher != nil {
+		g.batchStartBlock = cfg.BatchInfoFetcher.BatchIndexStartBlock
+	}
+	return g
+}
+
+// ScanGaps reports the block ranges the events of each type of each contract are not indexed from, the ranges before
+// the first block of a checkpoint and the ranges from its last block to the highest checkpoint of the same layer and
+// event type, along with the nonces missing between the messages sent on layer2. The indexing is gap free if nothing
+// is reported, the gaps are filled by backfilling their ranges.
+func (g *GapLogic) ScanGaps(ctx context.Context) (*types.EventGaps, error) {
+	checkpoints, err := g.checkpointOrm.GetEventCheckpoints(ctx)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	type layerEventType struct {
+		layer     int
+		eventType string
+	}
+	latestHeights := make(map[layerEventType]uint64)
+	for _, checkpoint := range checkpoints {
+		key := layerEventType{checkpoint.Layer, checkpoint.EventType}
+		if latestHeights[key] < checkpoint.Height {
+			latestHeights[key] = checkpoint.Height
+		}
+	}
+
+	gaps := &types.EventGaps{Checkpoints: len(checkpoints), CheckpointGaps: []*types.CheckpointGap{}}
+	for _, checkpoint := range checkpoints {
+		startHeight := g.startHeights[orm.MsgType(checkpoint.Layer)]
+		if checkpoint.EventType == batchesEventType {
+			startHeight = g.batchStartBlock
+		}
+		if startHeight != 0 && checkpoint.StartHeight > startHeight {
+			gaps.CheckpointGaps = append(gaps.CheckpointGaps, checkpointGap(checkpoint, startHeight, checkpoint.StartHeight-1))
+		}
+		if latestHeight := latestHeights[layerEventType{checkpoint.Layer, checkpoint.EventType}]; checkpoint.Height < latestHeight {
+			gaps.CheckpointGaps = append(gaps.CheckpointGaps, checkpointGap(checkpoint, checkpoint.Height+1, latestHeight))
+		}
+	}
+
+	nonceRanges, err := g.l2SentMsgOrm.GetMissingNonceRanges(ctx, maxNonceGaps)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	gaps.L2SentMsgNonceGaps = make([]*types.NonceGap, 0, len(nonceRanges))
+	for _, nonceRange := range nonceRanges {
+		gaps.L2SentMsgNonceGaps = append(gaps.L2SentMsgNonceGaps, &types.NonceGap{StartNonce: nonceRange.StartNonce, EndNonce: nonceRange.EndNonce})
+	}
+	gaps.GapFree = len(gaps.CheckpointGaps) == 0 && len(gaps.L2SentMsgNonceGaps) == 0
+	return gaps, nil
+}
+
+// checkpointGap returns the gap of the blocks from to to of the contract and the event type of the checkpoint
+func checkpointGap(checkpoint *orm.EventCheckpoint, from, to uint64) *types.CheckpointGap {
+	return &types.CheckpointGap{
+		Layer:     checkpoint.Layer,
+		Contract:  checkpoint.Contract,
+		EventType: checkpoint.EventType,
+		FromBlock: from,
+		ToBlock:   to,
+	}
+}
@@ -0,0 +1,43 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/config"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestScanGaps(t *testing.T) {
+	db, counter := newCountingDB(t, map[string]interface{}{
+		(&orm.EventCheckpoint{}).TableName(): []*orm.EventCheckpoint{
+			{Layer: int(orm.Layer1Msg), Contract: "0xa", EventType: "cross_msgs", StartHeight: 100, Height: 500},
+			{Layer: int(orm.Layer1Msg), Contract: "0xb", EventType: "cross_msgs", StartHeight: 300, Height: 450},
+			{Layer: int(orm.Layer1Msg), Contract: "0xc", EventType: batchesEventType, StartHeight: 20, Height: 400},
+			{Layer: int(orm.Layer2Msg), Contract: "0xd", EventType: "cross_msgs", StartHeight: 1, Height: 900},
+		},
+	})
+	cfg := &config.Config{L1: &config.LayerConfig{StartHeight: 100}, L2: &config.LayerConfig{}, BatchInfoFetcher: &config.BatchInfoFetcherConfig{BatchIndexStartBlock: 20}}
+	gaps, err := NewGapLogic(cfg, db).ScanGaps(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, gaps.GapFree)
+	assert.Equal(t, 4, gaps.Checkpoints)
+	// the contract added later misses the blocks before its checkpoint, and lags behind the other contract
+	assert.Equal(t, []*types.CheckpointGap{
+		{Layer: int(orm.Layer1Msg), Contract: "0xb", EventType: "cross_msgs", FromBlock: 100, ToBlock: 299},
+		{Layer: int(orm.Layer1Msg), Contract: "0xb", EventType: "cross_msgs", FromBlock: 451, ToBlock: 500},
+	}, gaps.CheckpointGaps)
+	assert.Empty(t, gaps.L2SentMsgNonceGaps)
+	assert.Equal(t, 1, counter.calls["(*EventCheckpoint).GetEventCheckpoints"])
+}
+
+func TestScanGapsWithoutCheckpoint(t *testing.T) {
+	db, _ := newCountingDB(t, nil)
+	gaps, err := NewGapLogic(&config.Config{}, db).ScanGaps(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, gaps.GapFree)
+	assert.Zero(t, gaps.Checkpoints)
+}
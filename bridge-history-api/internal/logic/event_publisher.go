@@ -0,0 +1,147 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"bridge-history-api/config"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// defaultTopicPrefix prefixes the topics of the indexed events if the config has no prefix
+const defaultTopicPrefix = "bridge-history"
+
+// EventPublisher queues the indexed cross messages, relays and batch finalizations for the topics of a message broker
+// in the transactions saving them, so that the downstream systems consume the bridge events without polling the db.
+// The queued events are published by the dispatcher of the fetcher, a nil EventPublisher queues nothing.
+type EventPublisher struct {
+	outboxOrm   *orm.EventOutbox
+	network     string
+	topicPrefix string
+}
+
+// NewEventPublisher returns the EventPublisher of the indexed events of the network, nil is returned if cfg is nil
+func NewEventPublisher(cfg *config.PublisherConfig, network string, db *gorm.DB) (*EventPublisher, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	switch cfg.Broker {
+	case "kafka", "nats":
+	default:
+		return nil, fmt.Errorf("unknown broker %q of the publisher, expected kafka or nats", cfg.Broker)
+	}
+	p := &EventPublisher{outboxOrm: orm.NewEventOutbox(db), network: network, topicPrefix: cfg.TopicPrefix}
+	if p.topicPrefix == "" {
+		p.topicPrefix = defaultTopicPrefix
+	}
+	return p, nil
+}
+
+// QueueMsgs queues the cross messages and relays saved by dbTx, the ones saved before included
+func (p *EventPublisher) QueueMsgs(ctx context.Context, crossMsgs []*orm.CrossMsg, relayedMsgs []*orm.RelayedMsg, dbTx *gorm.DB) error {
+	if p == nil {
+		return nil
+	}
+	outbox, err := p.msgsOutbox(crossMsgs, relayedMsgs)
+	if err != nil {
+		return err
+	}
+	return p.outboxOrm.InsertEventOutbox(ctx, outbox, dbTx)
+}
+
+// QueueFinalizedBatches queues the finalizations of the batches on layer1 saved by dbTx
+func (p *EventPublisher) QueueFinalizedBatches(ctx context.Context, batches []*orm.RollupBatch, dbTx *gorm.DB) error {
+	if p == nil {
+		return nil
+	}
+	outbox, err := p.batchesOutbox(batches)
+	if err != nil {
+		return err
+	}
+	return p.outboxOrm.InsertEventOutbox(ctx, outbox, dbTx)
+}
+
+// msgsOutbox returns the outbox rows of the cross messages followed by the ones of the relays
+func (p *EventPublisher) msgsOutbox(crossMsgs []*orm.CrossMsg, relayedMsgs []*orm.RelayedMsg) ([]*orm.EventOutbox, error) {
+	events := make([]*types.IndexedEvent, 0, len(crossMsgs))
+	for _, crossMsg := range crossMsgs {
+		event := &types.IndexedEvent{
+			Type:         types.IndexedEventCrossMsg,
+			Layer:        crossMsg.MsgType,
+			TxHash:       crossMsg.Layer1Hash,
+			Height:       crossMsg.Height,
+			MsgHash:      crossMsg.MsgHash,
+			Sender:       crossMsg.Sender,
+			Target:       crossMsg.Target,
+			Amount:       crossMsg.Amount,
+			L1Token:      crossMsg.Layer1Token,
+			L2Token:      crossMsg.Layer2Token,
+			TokenIDs:     crossMsg.TokenIDList(),
+			TokenAmounts: crossMsg.TokenAmountList(),
+		}
+		if orm.MsgType(crossMsg.MsgType) == orm.Layer2Msg {
+			event.TxHash = crossMsg.Layer2Hash
+		}
+		events = append(events, event)
+	}
+	outbox, err := p.outboxEvents(types.IndexedEventCrossMsg, events)
+	if err != nil {
+		return nil, err
+	}
+
+	events = make([]*types.IndexedEvent, 0, len(relayedMsgs))
+	for _, relayedMsg := range relayedMsgs {
+		// the relays on layer1 carry the layer1 tx, the ones on layer2 the layer2 tx
+		event := &types.IndexedEvent{Type: types.IndexedEventRelayedMsg, Layer: int(orm.Layer2Msg), TxHash: relayedMsg.Layer2Hash, Height: relayedMsg.Height, MsgHash: relayedMsg.MsgHash, Relayer: relayedMsg.Relayer}
+		if relayedMsg.Layer1Hash != "" {
+			event.Layer, event.TxHash = int(orm.Layer1Msg), relayedMsg.Layer1Hash
+		}
+		events = append(events, event)
+	}
+	relayed, err := p.outboxEvents(types.IndexedEventRelayedMsg, events)
+	if err != nil {
+		return nil, err
+	}
+	return append(outbox, relayed...), nil
+}
+
+// batchesOutbox returns the outbox rows of the finalizations of the batches
+func (p *EventPublisher) batchesOutbox(batches []*orm.RollupBatch) ([]*orm.EventOutbox, error) {
+	events := make([]*types.IndexedEvent, 0, len(batches))
+	for _, batch := range batches {
+		events = append(events, &types.IndexedEvent{
+			Type:       types.IndexedEventBatchFinalized,
+			Layer:      int(orm.Layer1Msg),
+			TxHash:     batch.FinalizeTxHash,
+			Height:     batch.FinalizeHeight,
+			BatchIndex: batch.BatchIndex,
+			BatchHash:  batch.BatchHash,
+			StateRoot:  batch.StateRoot,
+		})
+	}
+	return p.outboxEvents(types.IndexedEventBatchFinalized, events)
+}
+
+// outboxEvents returns the outbox rows of the events of the type for its topic, keyed by the message hash or the batch
+// hash
+func (p *EventPublisher) outboxEvents(eventType types.IndexedEventType, events []*types.IndexedEvent) ([]*orm.EventOutbox, error) {
+	outbox := make([]*orm.EventOutbox, 0, len(events))
+	topic := p.topicPrefix + "." + string(eventType)
+	for _, event := range events {
+		event.Network = p.network
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return nil, err
+		}
+		key := event.MsgHash
+		if eventType == types.IndexedEventBatchFinalized {
+			key = event.BatchHash
+		}
+		outbox = append(outbox, &orm.EventOutbox{Topic: topic, MsgKey: key, Payload: string(payload)})
+	}
+	return outbox, nil
+}
@@ -0,0 +1,64 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"bridge-history-api/config"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestNewEventPublisher(t *testing.T) {
+	publisher, err := NewEventPublisher(nil, "mainnet", nil)
+	assert.NoError(t, err)
+	assert.Nil(t, publisher)
+	// a nil publisher queues nothing
+	assert.NoError(t, publisher.QueueMsgs(context.Background(), []*orm.CrossMsg{{MsgHash: "0x01"}}, nil, nil))
+	assert.NoError(t, publisher.QueueFinalizedBatches(context.Background(), []*orm.RollupBatch{{BatchHash: "0x01"}}, nil))
+
+	_, err = NewEventPublisher(&config.PublisherConfig{Broker: "rabbitmq", URL: "amqp://localhost"}, "mainnet", nil)
+	assert.Error(t, err)
+
+	publisher, err = NewEventPublisher(&config.PublisherConfig{Broker: "nats", URL: "nats://localhost"}, "mainnet", nil)
+	require.NoError(t, err)
+	assert.Equal(t, defaultTopicPrefix, publisher.topicPrefix)
+}
+
+func TestEventPublisherOutbox(t *testing.T) {
+	publisher := &EventPublisher{network: "sepolia", topicPrefix: "bridge"}
+
+	crossMsgs := []*orm.CrossMsg{
+		{MsgHash: "0x01", MsgType: int(orm.Layer1Msg), Layer1Hash: "0x0a", Height: 10, Sender: "0x0b", Amount: "100"},
+		{MsgHash: "0x02", MsgType: int(orm.Layer2Msg), Layer2Hash: "0x0c", Height: 20, TokenIDs: "1,2", TokenAmounts: "3,4"},
+	}
+	relayedMsgs := []*orm.RelayedMsg{{MsgHash: "0x03", Layer1Hash: "0x0d", Height: 30}}
+	outbox, err := publisher.msgsOutbox(crossMsgs, relayedMsgs)
+	require.NoError(t, err)
+	batches, err := publisher.batchesOutbox([]*orm.RollupBatch{{BatchIndex: 5, BatchHash: "0x04", FinalizeTxHash: "0x0e", FinalizeHeight: 40}})
+	require.NoError(t, err)
+	outbox = append(outbox, batches...)
+	// nothing is queued without events
+	empty, err := publisher.msgsOutbox(nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	var topics, keys []string
+	events := make([]*types.IndexedEvent, 0, len(outbox))
+	for _, row := range outbox {
+		topics, keys = append(topics, row.Topic), append(keys, row.MsgKey)
+		var event types.IndexedEvent
+		require.NoError(t, json.Unmarshal([]byte(row.Payload), &event))
+		events = append(events, &event)
+	}
+	assert.Equal(t, []string{"bridge.cross_msg", "bridge.cross_msg", "bridge.relayed_msg", "bridge.batch_finalized"}, topics)
+	assert.Equal(t, []string{"0x01", "0x02", "0x03", "0x04"}, keys)
+	assert.Equal(t, &types.IndexedEvent{Type: types.IndexedEventCrossMsg, Network: "sepolia", Layer: 1, TxHash: "0x0a", Height: 10, MsgHash: "0x01", Sender: "0x0b", Amount: "100"}, events[0])
+	assert.Equal(t, &types.IndexedEvent{Type: types.IndexedEventCrossMsg, Network: "sepolia", Layer: 2, TxHash: "0x0c", Height: 20, MsgHash: "0x02", TokenIDs: []string{"1", "2"}, TokenAmounts: []string{"3", "4"}}, events[1])
+	assert.Equal(t, &types.IndexedEvent{Type: types.IndexedEventRelayedMsg, Network: "sepolia", Layer: 1, TxHash: "0x0d", Height: 30, MsgHash: "0x03"}, events[2])
+	assert.Equal(t, &types.IndexedEvent{Type: types.IndexedEventBatchFinalized, Network: "sepolia", Layer: 1, TxHash: "0x0e", Height: 40, BatchIndex: 5, BatchHash: "0x04"}, events[3])
+}
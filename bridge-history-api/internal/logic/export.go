@@ -0,0 +1,73 @@
+package logic
+
+import (
+	"context"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// exportBatchSize is the number of the messages read from the db cursor and enriched at once by an export
+const exportBatchSize = 500
+
+// ExportTxsByFilter streams the deposits and withdrawals matching the filter sorted by block timestamp in the given
+// order, asc if empty, from the offset on to write in batches, read from a db cursor instead of loaded at once. The
+// streaming stops at the first error of write, which is still matched by errors.Is on the returned error.
+func (h *HistoryLogic) ExportTxsByFilter(ctx context.Context, filter types.TxFilter, order types.SortOrder, offset uint64, write func([]*types.TxExportRecord) error) error {
+	_, err := instrument(h.metrics, "ExportTxsByFilter", func() (exportedRecords, error) {
+		var count exportedRecords
+		err := h.exportTxsByFilter(ctx, filter, order, offset, func(records []*types.TxExportRecord) error {
+			count += exportedRecords(len(records))
+			return write(records)
+		})
+		return count, err
+	})
+	return err
+}
+
+// exportTxsByFilter implements ExportTxsByFilter
+func (h *HistoryLogic) exportTxsByFilter(ctx context.Context, filter types.TxFilter, order types.SortOrder, offset uint64, write func([]*types.TxExportRecord) error) error {
+	msgFilter, err := newMsgFilter(filter)
+	if err != nil {
+		return err
+	}
+	if order == "" {
+		order = types.SortOrderAsc
+	}
+	ormOrder, err := sortOrder(order)
+	if err != nil {
+		return err
+	}
+	// no query timeout, the export of a large account outlasts it, it ends with the request
+	next := offset
+	return h.crossMsgOrm().StreamMsgsByFilter(ctx, msgFilter, ormOrder, int(offset), exportBatchSize, func(crossMsgs []*orm.CrossMsg) error {
+		records, err := h.crossMsgsToTxExportRecords(ctx, crossMsgs, next)
+		if err != nil {
+			return err
+		}
+		next += uint64(len(crossMsgs))
+		if len(records) == 0 {
+			return nil
+		}
+		return write(records)
+	})
+}
+
+// crossMsgsToTxExportRecords converts and enriches a batch of the messages of an export the way the history queries
+// do, the records are numbered from offset by their positions in the matched messages, so that the offsets resume the
+// export where it stopped.
+func (h *HistoryLogic) crossMsgsToTxExportRecords(ctx context.Context, crossMsgs []*orm.CrossMsg, offset uint64) ([]*types.TxExportRecord, error) {
+	txHistories := h.crossMsgsToTxHistoryInfos(crossMsgs)
+	if len(txHistories) == 0 {
+		return nil, nil
+	}
+	records := make([]*types.TxExportRecord, 0, len(txHistories))
+	for i, txHistory := range txHistories {
+		records = append(records, &types.TxExportRecord{Offset: offset + uint64(i), TxHistoryInfo: txHistory})
+	}
+	if err := updateCrossTxHashesAndL2TxClaimInfo(ctx, txHistories, h.db, h.depositCreditFinality, h.claimLookups); err != nil {
+		return nil, err
+	}
+	h.finishTxHistories(ctx, txHistories)
+	return records, nil
+}
@@ -0,0 +1,50 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestExportTxsByFilter(t *testing.T) {
+	db, _ := newCountingDB(t, nil)
+	logic := &HistoryLogic{db: db}
+	write := func([]*types.TxExportRecord) error { return nil }
+	err := logic.ExportTxsByFilter(context.Background(), types.TxFilter{}, types.SortOrderAsc, 0, write)
+	assert.True(t, errors.Is(err, ErrInvalidParameter))
+	err = logic.ExportTxsByFilter(context.Background(), types.TxFilter{Addresses: []string{"0x1c5a77d9fa7ef466951b2f01f724bca3a5820b63"}}, "sideways", 0, write)
+	assert.True(t, errors.Is(err, ErrInvalidParameter))
+}
+
+func TestCrossMsgsToTxExportRecords(t *testing.T) {
+	db, counter := newCountingDB(t, map[string]interface{}{
+		(&orm.RelayedMsg{}).TableName(): []*orm.RelayedMsg{{MsgHash: "0xa3", Layer2Hash: "0x23"}},
+	})
+	logic := &HistoryLogic{db: db}
+	records, err := logic.crossMsgsToTxExportRecords(context.Background(), []*orm.CrossMsg{
+		{MsgHash: "0xa2", MsgType: int(orm.Layer2Msg)},
+		{MsgHash: "0xa3", MsgType: int(orm.Layer1Msg)},
+	}, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, records, 2) {
+		assert.Equal(t, uint64(10), records[0].Offset)
+		assert.Equal(t, "0xa2", records[0].MsgHash)
+		assert.Equal(t, uint64(11), records[1].Offset)
+		assert.Equal(t, "0xa3", records[1].MsgHash)
+		assert.Equal(t, "0x23", records[1].FinalizeTx.Hash)
+	}
+	assert.Equal(t, 1, counter.calls["(*RelayedMsg).GetRelayedMsgsByHashes"])
+
+	// nothing is enriched without messages
+	db, counter = newCountingDB(t, nil)
+	logic.db = db
+	records, err = logic.crossMsgsToTxExportRecords(context.Background(), nil, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+	assert.Empty(t, counter.calls)
+}
@@ -0,0 +1,75 @@
+package logic
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+
+	"bridge-history-api/config"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// gatewayRegistry holds the known counterpart gateways and the messengers of layer1 and layer2
+type gatewayRegistry struct {
+	l1Messenger string
+	l2Messenger string
+	// l1ToL2 maps the layer1 gateway to its layer2 counterpart, l2ToL1 is the reverse
+	l1ToL2 map[string]string
+	l2ToL1 map[string]string
+}
+
+// newGatewayRegistry builds the gateway registry from the layer configs, the gateways not configured are skipped
+func newGatewayRegistry(l1, l2 *config.LayerConfig) *gatewayRegistry {
+	registry := &gatewayRegistry{
+		l1Messenger: normalizeAddress(l1.MessengerAddr),
+		l2Messenger: normalizeAddress(l2.MessengerAddr),
+		l1ToL2:      make(map[string]string),
+		l2ToL1:      make(map[string]string),
+	}
+	pairs := [][2]string{
+		{l1.ETHGatewayAddr, l2.ETHGatewayAddr},
+		{l1.WETHGatewayAddr, l2.WETHGatewayAddr},
+		{l1.USDCGatewayAddr, l2.USDCGatewayAddr},
+		{l1.LIDOGatewayAddr, l2.LIDOGatewayAddr},
+		{l1.DAIGatewayAddr, l2.DAIGatewayAddr},
+		{l1.StandardERC20Gateway, l2.StandardERC20Gateway},
+		{l1.ERC721GatewayAddr, l2.ERC721GatewayAddr},
+		{l1.ERC1155GatewayAddr, l2.ERC1155GatewayAddr},
+		{l1.CustomERC20GatewayAddr, l2.CustomERC20GatewayAddr},
+	}
+	for _, pair := range pairs {
+		if pair[0] == "" || pair[1] == "" {
+			continue
+		}
+		l1Gateway, l2Gateway := normalizeAddress(pair[0]), normalizeAddress(pair[1])
+		registry.l1ToL2[l1Gateway] = l2Gateway
+		registry.l2ToL1[l2Gateway] = l1Gateway
+	}
+	return registry
+}
+
+// route returns the route of the cross message, the gateways are empty if the message is sent through the messenger directly
+func (g *gatewayRegistry) route(crossMsg *orm.CrossMsg) *types.Route {
+	route := &types.Route{
+		L1Messenger: g.l1Messenger,
+		L2Messenger: g.l2Messenger,
+	}
+	if crossMsg.Gateway == "" {
+		return route
+	}
+	gateway := normalizeAddress(crossMsg.Gateway)
+	if orm.MsgType(crossMsg.MsgType) == orm.Layer1Msg {
+		route.L1Gateway = gateway
+		route.L2Gateway = g.l1ToL2[gateway]
+	} else {
+		route.L2Gateway = gateway
+		route.L1Gateway = g.l2ToL1[gateway]
+	}
+	return route
+}
+
+func normalizeAddress(address string) string {
+	if address == "" {
+		return ""
+	}
+	return common.HexToAddress(address).Hex()
+}
@@ -0,0 +1,42 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/config"
+	"bridge-history-api/orm"
+)
+
+func TestGatewayRegistryRoute(t *testing.T) {
+	l1 := &config.LayerConfig{
+		MessengerAddr:        "0x0000000000000000000000000000000000000011",
+		ETHGatewayAddr:       "0x0000000000000000000000000000000000000012",
+		StandardERC20Gateway: "0x0000000000000000000000000000000000000013",
+	}
+	l2 := &config.LayerConfig{
+		MessengerAddr:        "0x0000000000000000000000000000000000000021",
+		ETHGatewayAddr:       "0x0000000000000000000000000000000000000022",
+		StandardERC20Gateway: "0x0000000000000000000000000000000000000023",
+	}
+	registry := newGatewayRegistry(l1, l2)
+
+	// standard erc20 deposit
+	route := registry.route(&orm.CrossMsg{MsgType: int(orm.Layer1Msg), Asset: int(orm.ERC20), Gateway: l1.StandardERC20Gateway})
+	assert.Equal(t, l1.StandardERC20Gateway, route.L1Gateway)
+	assert.Equal(t, l1.MessengerAddr, route.L1Messenger)
+	assert.Equal(t, l2.MessengerAddr, route.L2Messenger)
+	assert.Equal(t, l2.StandardERC20Gateway, route.L2Gateway)
+
+	// standard erc20 withdrawal
+	route = registry.route(&orm.CrossMsg{MsgType: int(orm.Layer2Msg), Asset: int(orm.ERC20), Gateway: l2.StandardERC20Gateway})
+	assert.Equal(t, l1.StandardERC20Gateway, route.L1Gateway)
+	assert.Equal(t, l2.StandardERC20Gateway, route.L2Gateway)
+
+	// message sent through the messenger directly
+	route = registry.route(&orm.CrossMsg{MsgType: int(orm.Layer2Msg)})
+	assert.Empty(t, route.L1Gateway)
+	assert.Empty(t, route.L2Gateway)
+	assert.Equal(t, l2.MessengerAddr, route.L2Messenger)
+}
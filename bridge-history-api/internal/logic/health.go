@@ -0,0 +1,135 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"bridge-history-api/config"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// defaultMaxIndexingLag is the number of the blocks the indexed height may be behind the head by default
+const defaultMaxIndexingLag = 100
+
+// HeadReader reads the head of a chain, implemented by *ethclient.Client
+type HeadReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+}
+
+// layerHealth the node and the confirmations of a layer checked by the probes
+type layerHealth struct {
+	layer        orm.MsgType
+	client       HeadReader
+	confirmation uint64
+}
+
+// HealthLogic checks the db, the nodes and the indexing lag of a network for the probes of the load balancers
+type HealthLogic struct {
+	network string
+	db      *gorm.DB
+	layers  []layerHealth
+	maxLag  uint64
+}
+
+// NewHealthLogic returns the HealthLogic of the network of cfg, the clients are the nodes of the layers, nil if the
+// layer has no endpoint
+func NewHealthLogic(cfg *config.Config, db *gorm.DB, l1Client, l2Client HeadReader) *HealthLogic {
+	h := &HealthLogic{network: cfg.NetworkName(), db: db, maxLag: defaultMaxIndexingLag}
+	if cfg.Server != nil && cfg.Server.MaxIndexingLag != 0 {
+		h.maxLag = cfg.Server.MaxIndexingLag
+	}
+	l1 := layerHealth{layer: orm.Layer1Msg, client: l1Client}
+	if cfg.L1 != nil {
+		l1.confirmation = cfg.L1.Confirmation
+	}
+	l2 := layerHealth{layer: orm.Layer2Msg, client: l2Client}
+	if cfg.L2 != nil {
+		l2.confirmation = cfg.L2.Confirmation
+	}
+	h.layers = []layerHealth{l1, l2}
+	return h
+}
+
+// Health checks the db and the nodes of both layers are reachable
+func (h *HealthLogic) Health(ctx context.Context) []*types.HealthCheck {
+	checks := []*types.HealthCheck{h.check("db", h.pingDB(ctx))}
+	for _, layer := range h.layers {
+		_, err := h.head(ctx, layer)
+		checks = append(checks, h.check(layerName(layer.layer)+"_rpc", err))
+	}
+	return checks
+}
+
+// Readiness checks the db and the nodes of both layers are reachable, and the indexed height of each layer is at most
+// maxLag blocks behind the head beyond the confirmations of the layer
+func (h *HealthLogic) Readiness(ctx context.Context) []*types.HealthCheck {
+	checks := []*types.HealthCheck{h.check("db", h.pingDB(ctx))}
+	for _, layer := range h.layers {
+		head, err := h.head(ctx, layer)
+		checks = append(checks, h.check(layerName(layer.layer)+"_rpc", err))
+		if err != nil {
+			checks = append(checks, h.check(layerName(layer.layer)+"_lag", fmt.Errorf("the head is unknown: %w", err)))
+			continue
+		}
+		lag, err := h.indexingLag(ctx, layer, head)
+		if err == nil && lag > h.maxLag {
+			err = fmt.Errorf("the indexed height is %d blocks behind, more than %d", lag, h.maxLag)
+		}
+		check := h.check(layerName(layer.layer)+"_lag", err)
+		check.Lag = &lag
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+func (h *HealthLogic) check(name string, err error) *types.HealthCheck {
+	check := &types.HealthCheck{Network: h.network, Name: name, Healthy: err == nil}
+	if err != nil {
+		check.Error = err.Error()
+	}
+	return check
+}
+
+func (h *HealthLogic) pingDB(ctx context.Context) error {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func (h *HealthLogic) head(ctx context.Context, layer layerHealth) (uint64, error) {
+	if layer.client == nil {
+		return 0, errors.New("no endpoint of the layer")
+	}
+	return layer.client.BlockNumber(ctx)
+}
+
+// indexingLag returns the number of the blocks the latest indexed block of the layer is behind the head beyond the
+// confirmations, the end of each range fetched is indexed so that the lag doesn't grow while no events are emitted
+func (h *HealthLogic) indexingLag(ctx context.Context, layer layerHealth, head uint64) (uint64, error) {
+	blocks, err := orm.NewIndexedBlock(h.db).GetLatestIndexedBlocks(ctx, layer.layer, 1)
+	if err != nil {
+		return 0, err
+	}
+	var indexed uint64
+	if len(blocks) != 0 {
+		indexed = blocks[0].Height
+	}
+	if head < layer.confirmation || head-layer.confirmation <= indexed {
+		return 0, nil
+	}
+	return head - layer.confirmation - indexed, nil
+}
+
+// layerName returns the name of the layer in the checks
+func layerName(layer orm.MsgType) string {
+	if layer == orm.Layer1Msg {
+		return "l1"
+	}
+	return "l2"
+}
@@ -0,0 +1,63 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/config"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// fakeHead a node at a fixed head
+type fakeHead struct {
+	head uint64
+	err  error
+}
+
+func (f *fakeHead) BlockNumber(context.Context) (uint64, error) {
+	return f.head, f.err
+}
+
+func TestReadinessIndexingLag(t *testing.T) {
+	db, _ := newCountingDB(t, map[string]interface{}{
+		(&orm.IndexedBlock{}).TableName(): []*orm.IndexedBlock{{Height: 90}},
+	})
+	cfg := &config.Config{L1: &config.LayerConfig{Confirmation: 10}, L2: &config.LayerConfig{}}
+	checks := map[string]*types.HealthCheck{}
+	for _, check := range NewHealthLogic(cfg, db, &fakeHead{head: 200}, &fakeHead{head: 300}).Readiness(context.Background()) {
+		assert.Equal(t, config.DefaultNetwork, check.Network)
+		checks[check.Name] = check
+	}
+	// the confirmations are not counted as lag, up to the max lag is ready
+	assert.True(t, checks["l1_lag"].Healthy)
+	assert.Equal(t, uint64(100), *checks["l1_lag"].Lag)
+	assert.False(t, checks["l2_lag"].Healthy)
+	assert.Equal(t, uint64(210), *checks["l2_lag"].Lag)
+	assert.True(t, checks["l1_rpc"].Healthy)
+
+	// the lag of an unreachable node is unknown
+	cfg.Server = &config.ServerConfig{MaxIndexingLag: 300}
+	checks = map[string]*types.HealthCheck{}
+	for _, check := range NewHealthLogic(cfg, db, &fakeHead{err: errors.New("dial tcp: connection refused")}, &fakeHead{head: 300}).Readiness(context.Background()) {
+		checks[check.Name] = check
+	}
+	assert.False(t, checks["l1_rpc"].Healthy)
+	assert.False(t, checks["l1_lag"].Healthy)
+	assert.Nil(t, checks["l1_lag"].Lag)
+	assert.True(t, checks["l2_lag"].Healthy)
+}
+
+func TestHealthWithoutEndpoint(t *testing.T) {
+	db, counter := newCountingDB(t, nil)
+	checks := NewHealthLogic(&config.Config{}, db, nil, &fakeHead{head: 1}).Health(context.Background())
+	assert.Len(t, checks, 3)
+	assert.Equal(t, "l1_rpc", checks[1].Name)
+	assert.False(t, checks[1].Healthy)
+	assert.True(t, checks[2].Healthy)
+	// the liveness doesn't query the indexed heights
+	assert.Zero(t, counter.calls["(*IndexedBlock).GetLatestIndexedBlocks"])
+}
@@ -0,0 +1,193 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// txsCacheVersionKey returns the cache key of the version of the cached tx histories of the address, the pages are
+// cached under the version so that all of them are invalidated at once by removing it
+func txsCacheVersionKey(address common.Address) string {
+	return "txs_version:" + address.Hex()
+}
+
+// txsCacheKey returns the cache key of a page of the tx histories of the address under the version
+func txsCacheKey(address common.Address, version string, page, pageSize uint64) string {
+	return fmt.Sprintf("txs:%s:%s:%d:%d", address.Hex(), version, page, pageSize)
+}
+
+// txCacheKey returns the cache key of the tx histories of the tx hash
+func txCacheKey(hash string) string {
+	return "tx:" + strings.ToLower(hash)
+}
+
+// txsCacheVersion returns the version the tx histories of the address are cached under, a new version is created if
+// there's none. It must be taken before querying the db, so that the results of a query racing with an invalidation
+// are cached under the removed version and never served. Empty is returned if the cache is not configured or failed.
+func (h *HistoryLogic) txsCacheVersion(ctx context.Context, address common.Address) string {
+	if h.cache == nil {
+		return ""
+	}
+	var version string
+	if h.getCached(ctx, txsCacheVersionKey(address), &version) {
+		return version
+	}
+	version = strconv.FormatInt(time.Now().UnixNano(), 10)
+	h.setCached(ctx, txsCacheVersionKey(address), version)
+	return version
+}
+
+// refreshCachedTxHistories recomputes the enrichments of the cached tx histories which depend on the time of the read
+// or on the config of the server reading them, the relative time and the claim expiry. It runs on every cache read,
+// the cached values are the ones of the server which wrote them.
+func (h *HistoryLogic) refreshCachedTxHistories(txHistories []*types.TxHistoryInfo) {
+	h.updateRelativeTimes(txHistories, time.Now())
+	h.updateClaimExpiresAt(txHistories)
+}
+
+// getCachedTxsByHashes returns the cached tx histories of the hashes and the hashes missed
+func (h *HistoryLogic) getCachedTxsByHashes(ctx context.Context, hashes []string) ([]*types.TxHistoryInfo, []string) {
+	var txHistories []*types.TxHistoryInfo
+	var missed []string
+	for _, hash := range hashes {
+		var cached []*types.TxHistoryInfo
+		if !h.getCached(ctx, txCacheKey(hash), &cached) {
+			missed = append(missed, hash)
+			continue
+		}
+		txHistories = append(txHistories, cached...)
+	}
+	h.refreshCachedTxHistories(txHistories)
+	return txHistories, missed
+}
+
+// setCachedTxsByHashes caches the tx histories of the cross msgs queried by the hashes under each of the hashes, the
+// hashes without cross msgs are cached as empty until the fetcher writes their messages
+func (h *HistoryLogic) setCachedTxsByHashes(ctx context.Context, hashes []string, crossMsgs []*orm.CrossMsg, txHistories []*types.TxHistoryInfo) {
+	if h.cache == nil {
+		return
+	}
+	txHistoryMap := make(map[string]*types.TxHistoryInfo, len(txHistories))
+	for _, txHistory := range txHistories {
+		txHistoryMap[txHistory.MsgHash] = txHistory
+	}
+	hashTxHistories := make(map[string][]*types.TxHistoryInfo, len(hashes))
+	for _, hash := range hashes {
+		hashTxHistories[hash] = []*types.TxHistoryInfo{}
+	}
+	for _, crossMsg := range crossMsgs {
+		txHistory, found := txHistoryMap[crossMsg.MsgHash]
+		if !found {
+			continue
+		}
+		for _, hash := range []string{crossMsg.Layer1Hash, crossMsg.Layer2Hash} {
+			if cached, queried := hashTxHistories[hash]; queried {
+				hashTxHistories[hash] = append(cached, txHistory)
+			}
+		}
+	}
+	for hash, cached := range hashTxHistories {
+		h.setCached(ctx, txCacheKey(hash), cached)
+	}
+}
+
+// sortTxHistories sorts the tx histories by block timestamp, tx hash and msg hash in the order, the txs without block
+// timestamp sort as the latest ones. It merges the cached and the queried tx histories in the order of the db.
+func sortTxHistories(txHistories []*types.TxHistoryInfo, order types.SortOrder) {
+	sort.SliceStable(txHistories, func(i, j int) bool {
+		a, b := txHistories[i], txHistories[j]
+		if order == types.SortOrderAsc {
+			a, b = b, a
+		}
+		switch {
+		case a.BlockTimestamp == nil || b.BlockTimestamp == nil:
+			if a.BlockTimestamp != nil || b.BlockTimestamp != nil {
+				return a.BlockTimestamp == nil
+			}
+		case !a.BlockTimestamp.Equal(*b.BlockTimestamp):
+			return a.BlockTimestamp.After(*b.BlockTimestamp)
+		}
+		if a.Hash != b.Hash {
+			return a.Hash > b.Hash
+		}
+		return a.MsgHash > b.MsgHash
+	})
+}
+
+// InvalidateHistoryCache removes the cached tx histories and claimable txs of the senders of the saved messages and
+// the cached tx histories of their tx hashes, the relayed msgs invalidate the entries of the messages they relay. It
+// must run once the messages are saved so that the new messages and the relays show up before the entries expire.
+func InvalidateHistoryCache(ctx context.Context, cache Cache, db *gorm.DB, crossMsgs []*orm.CrossMsg, relayedMsgs []*orm.RelayedMsg, l2SentMsgs []*orm.L2SentMsg) error {
+	if cache == nil {
+		return nil
+	}
+	var msgHashes []string
+	for _, relayedMsg := range relayedMsgs {
+		msgHashes = append(msgHashes, relayedMsg.MsgHash)
+	}
+	// the withdrawals relayed on layer1 and the deposits relayed on layer2
+	var relayedL2SentMsgs []*orm.L2SentMsg
+	var relayedCrossMsgs []*orm.CrossMsg
+	if len(msgHashes) > 0 {
+		var err error
+		relayedL2SentMsgs, err = orm.NewL2SentMsg(db).GetL2SentMsgsByHashes(ctx, msgHashes)
+		if err != nil {
+			return err
+		}
+		relayedCrossMsgs, err = orm.NewCrossMsg(db).GetL1CrossMsgByMsgHashList(ctx, msgHashes)
+		if err != nil {
+			return err
+		}
+	}
+
+	keySet := make(map[string]struct{})
+	var keys []string
+	addKey := func(key string) {
+		if _, exists := keySet[key]; !exists {
+			keySet[key] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	addAddress := func(address string) {
+		if address != "" {
+			addKey(txsCacheVersionKey(common.HexToAddress(address)))
+			addKey(claimableCacheKey(common.HexToAddress(address)))
+		}
+	}
+	addTxHash := func(hash string) {
+		if hash != "" {
+			addKey(txCacheKey(hash))
+		}
+	}
+	for _, msgs := range [][]*orm.CrossMsg{crossMsgs, relayedCrossMsgs} {
+		for _, crossMsg := range msgs {
+			addAddress(crossMsg.Sender)
+			addTxHash(crossMsg.Layer1Hash)
+			addTxHash(crossMsg.Layer2Hash)
+		}
+	}
+	for _, msgs := range [][]*orm.L2SentMsg{l2SentMsgs, relayedL2SentMsgs} {
+		for _, l2SentMsg := range msgs {
+			addAddress(l2SentMsg.Sender)
+			addAddress(l2SentMsg.OriginalSender)
+			addTxHash(l2SentMsg.TxHash)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := cache.Del(ctx, keys...); err != nil {
+		return fmt.Errorf("delete cached histories error: %w", err)
+	}
+	return nil
+}
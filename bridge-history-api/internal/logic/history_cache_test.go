@@ -0,0 +1,137 @@
+package logic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestGetTxsByHashesCache(t *testing.T) {
+	hash1 := common.HexToHash("0x01").Hex()
+	hash2 := common.HexToHash("0x02").Hex()
+	cache := newMemCache()
+	db, counter := newCountingDB(t, map[string]interface{}{
+		(&orm.CrossMsg{}).TableName(): []*orm.CrossMsg{{MsgHash: "0xa1", Layer1Hash: hash1, MsgType: int(orm.Layer1Msg), Amount: "1"}},
+	})
+	logic := &HistoryLogic{db: db, cache: cache}
+
+	// miss: queried from the db and cached by hash, hash2 is cached as not found
+	txHistories, err := logic.GetTxsByHashes(context.Background(), []string{hash1, hash2}, types.TokenTypeAll, types.SortOrderDesc)
+	assert.NoError(t, err)
+	assert.Len(t, txHistories, 1)
+	assert.Equal(t, 1, counter.calls["(*CrossMsg).GetCrossMsgsByHashes"])
+	assert.Contains(t, cache.values, txCacheKey(hash1))
+	assert.Contains(t, cache.values, txCacheKey(hash2))
+
+	// hit: served from the cache
+	txHistories, err = logic.GetTxsByHashes(context.Background(), []string{hash1, hash2}, types.TokenTypeAll, types.SortOrderDesc)
+	assert.NoError(t, err)
+	assert.Len(t, txHistories, 1)
+	assert.Equal(t, "0xa1", txHistories[0].MsgHash)
+	assert.Equal(t, 1, counter.calls["(*CrossMsg).GetCrossMsgsByHashes"])
+
+	// only the missed hashes are queried
+	delete(cache.values, txCacheKey(hash2))
+	_, err = logic.GetTxsByHashes(context.Background(), []string{hash1, hash2}, types.TokenTypeAll, types.SortOrderDesc)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, counter.calls["(*CrossMsg).GetCrossMsgsByHashes"])
+	assert.Equal(t, []interface{}{hash2, hash2}, counter.vars["(*CrossMsg).GetCrossMsgsByHashes"][:2])
+
+	// the filtered queries aren't cached
+	_, err = logic.GetTxsByHashes(context.Background(), []string{hash1}, types.TokenTypeETH, types.SortOrderDesc)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, counter.calls["(*CrossMsg).GetCrossMsgsByHashes"])
+}
+
+func TestGetTxsByAddressCache(t *testing.T) {
+	address := common.HexToAddress("0x01")
+	cache := newMemCache()
+	db, counter := newCountingDB(t, nil)
+	logic := &HistoryLogic{db: db, cache: cache}
+
+	// the sub queries of the merged deposits and withdrawals are counted too
+	_, _, err := logic.GetTxsByAddress(context.Background(), address, 1, 10)
+	assert.NoError(t, err)
+	queriesPerCall := counter.calls["(*CrossMsg).GetTotalUnifiedMsgCountByAddress"]
+	assert.NotZero(t, queriesPerCall)
+	assert.Contains(t, cache.values, txsCacheVersionKey(address))
+
+	// hit: served from the cache
+	_, _, err = logic.GetTxsByAddress(context.Background(), address, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, queriesPerCall, counter.calls["(*CrossMsg).GetTotalUnifiedMsgCountByAddress"])
+
+	// another page
+	_, _, err = logic.GetTxsByAddress(context.Background(), address, 2, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2*queriesPerCall, counter.calls["(*CrossMsg).GetTotalUnifiedMsgCountByAddress"])
+
+	// a new message of the address invalidates all the pages
+	assert.NoError(t, InvalidateHistoryCache(context.Background(), cache, db, []*orm.CrossMsg{{Sender: address.Hex(), Layer1Hash: "0x01"}}, nil, nil))
+	_, _, err = logic.GetTxsByAddress(context.Background(), address, 1, 10)
+	assert.NoError(t, err)
+	_, _, err = logic.GetTxsByAddress(context.Background(), address, 2, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 4*queriesPerCall, counter.calls["(*CrossMsg).GetTotalUnifiedMsgCountByAddress"])
+}
+
+func TestInvalidateHistoryCache(t *testing.T) {
+	depositor := common.HexToAddress("0x01")
+	withdrawer := common.HexToAddress("0x02")
+	sender := common.HexToAddress("0x03")
+	db, _ := newCountingDB(t, map[string]interface{}{
+		(&orm.L2SentMsg{}).TableName(): []*orm.L2SentMsg{{MsgHash: "0xb1", TxHash: "0x12", OriginalSender: withdrawer.Hex()}},
+	})
+	cache := newMemCache()
+
+	assert.NoError(t, InvalidateHistoryCache(context.Background(), cache, db, nil, nil, nil))
+	assert.Empty(t, cache.deleted)
+
+	assert.NoError(t, InvalidateHistoryCache(context.Background(), cache, db,
+		[]*orm.CrossMsg{{MsgHash: "0xa1", Sender: depositor.Hex(), Layer1Hash: "0x11"}},
+		[]*orm.RelayedMsg{{MsgHash: "0xb1", Layer1Hash: "0x13"}},
+		[]*orm.L2SentMsg{{MsgHash: "0xc1", Sender: sender.Hex(), TxHash: "0x14"}},
+	))
+	assert.ElementsMatch(t, []string{
+		txsCacheVersionKey(depositor), claimableCacheKey(depositor), txCacheKey("0x11"),
+		txsCacheVersionKey(sender), claimableCacheKey(sender), txCacheKey("0x14"),
+		// the withdrawal relayed on layer1
+		txsCacheVersionKey(withdrawer), claimableCacheKey(withdrawer), txCacheKey("0x12"),
+	}, cache.deleted)
+
+	// no cache configured
+	assert.NoError(t, InvalidateHistoryCache(context.Background(), nil, db, []*orm.CrossMsg{{Sender: depositor.Hex()}}, nil, nil))
+}
+
+func TestSortTxHistories(t *testing.T) {
+	ts := func(sec int64) *time.Time {
+		tm := time.Unix(sec, 0)
+		return &tm
+	}
+	txHistories := []*types.TxHistoryInfo{
+		{MsgHash: "0xa1", Hash: "0x01", BlockTimestamp: ts(100)},
+		{MsgHash: "0xa2", Hash: "0x02", BlockTimestamp: ts(200)},
+		{MsgHash: "0xa3", Hash: "0x03"},
+		{MsgHash: "0xa4", Hash: "0x04", BlockTimestamp: ts(200)},
+		{MsgHash: "0xa5", Hash: "0x04", BlockTimestamp: ts(200)},
+	}
+	msgHashes := func() []string {
+		var result []string
+		for _, txHistory := range txHistories {
+			result = append(result, txHistory.MsgHash)
+		}
+		return result
+	}
+
+	sortTxHistories(txHistories, types.SortOrderDesc)
+	assert.Equal(t, []string{"0xa3", "0xa5", "0xa4", "0xa2", "0xa1"}, msgHashes())
+
+	sortTxHistories(txHistories, types.SortOrderAsc)
+	assert.Equal(t, []string{"0xa1", "0xa2", "0xa4", "0xa5", "0xa3"}, msgHashes())
+}
@@ -2,186 +2,1853 @@ package logic
 
 import (
 	"context"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/errgroup"
 	"gorm.io/gorm"
 
+	"bridge-history-api/config"
 	"bridge-history-api/internal/types"
 	"bridge-history-api/orm"
 )
 
+// proofActiveWindow is the number of the latest batches whose message proofs are kept in storage,
+// proofs of older batches may have been pruned.
+const proofActiveWindow = 10000
+
+// defaultQueryTimeout bounds the queries of each call when the timeout is not configured
+const defaultQueryTimeout = 5 * time.Second
+
+const (
+	// defaultPageSize is used when the page size is not given
+	defaultPageSize = 10
+	// maxPageSize is the upper bound of the page size
+	maxPageSize = 100
+	// maxClaimableAddresses is the upper bound of the addresses queried at once by GetClaimableTxsByAddresses
+	maxClaimableAddresses = 100
+)
+
 // HistoryLogic example service.
 type HistoryLogic struct {
 	db *gorm.DB
+	// claimExpiry is the period a withdrawal can be claimed after it's sent on layer2, 0 means claims never expire
+	claimExpiry time.Duration
+	// gateways is nil if the layer configs are not given
+	gateways *gatewayRegistry
+	// includeIndexedAt enables the IndexedAt enrichment of the tx histories
+	includeIndexedAt bool
+	// includeRelativeTime enables the RelativeTime enrichment of the tx histories
+	includeRelativeTime bool
+	// redactSensitive blanks the calldata and the proofs of the tx histories, for the public endpoints
+	redactSensitive bool
+	// depositCreditFinality is the finality the layer1 block of a relayed deposit reaches before it's reported
+	// credited, empty credits the deposits once relayed
+	depositCreditFinality types.L1FinalityStatus
+	// faucets are the senders whose deposits are excluded from the tx histories, nil disables the exclusion
+	faucets []string
+	// cache caches the claimable txs and the tx histories of the addresses and the tx hashes, nil queries the db every time
+	cache Cache
+	// cacheTTL is the time the query results are cached, 0 uses defaultCacheTTL
+	cacheTTL time.Duration
+	// queryTimeout bounds the queries of each exported method call, 0 leaves them unbounded
+	queryTimeout time.Duration
+	// metrics instruments the exported methods, nil records nothing
+	metrics *historyMetrics
+	// archiveLookup looks the tx hashes missing from the hot tables up in the archive tables
+	archiveLookup bool
+	// addressBook labels the addresses of the tx histories, nil leaves them unlabeled
+	addressBook *addressBook
+	// claimLookups caches the finalized batches and the proven withdrawals of the claim infos, nil queries them every time
+	claimLookups *claimLookupCache
+	// claimVerifier checks the claimable withdrawals against the layer1 messenger, nil trusts the indexed relays
+	claimVerifier *ClaimVerifier
 }
 
-// NewHistoryLogic returns services backed with a "db"
-func NewHistoryLogic(db *gorm.DB) *HistoryLogic {
-	logic := &HistoryLogic{db: db}
+// NewHistoryLogic returns services backed with a "db", the query results are cached in "cache" if it's not nil and
+// the metrics are registered on "reg" if it's not nil
+func NewHistoryLogic(cfg *config.Config, db *gorm.DB, cache Cache, reg prometheus.Registerer) *HistoryLogic {
+	logic := &HistoryLogic{db: db, cache: cache, queryTimeout: defaultQueryTimeout, metrics: newHistoryMetrics(reg)}
+	if cfg != nil && cfg.Server != nil {
+		if cfg.Server.QueryTimeout != 0 {
+			logic.queryTimeout = time.Duration(cfg.Server.QueryTimeout) * time.Second
+		}
+		logic.claimExpiry = time.Duration(cfg.Server.ClaimExpiry) * time.Second
+		logic.includeIndexedAt = cfg.Server.IncludeIndexedAt
+		logic.includeRelativeTime = cfg.Server.IncludeRelativeTime
+		logic.redactSensitive = cfg.Server.RedactSensitive
+		logic.faucets = faucetSenders(cfg.Server.FaucetAddrs)
+		logic.depositCreditFinality = depositCreditFinality(cfg.Server.DepositCreditFinality)
+		if cfg.Server.AddressLabels {
+			logic.addressBook = newAddressBook(orm.NewAddressLabel(db).GetAddressLabels)
+		}
+		logic.claimLookups = newClaimLookupCache(cfg.Server.ClaimLookupCacheSize, reg)
+	}
+	if cfg != nil && cfg.Redis != nil {
+		logic.cacheTTL = time.Duration(cfg.Redis.TTL) * time.Second
+	}
+	if cfg != nil && cfg.Archive != nil {
+		logic.archiveLookup = true
+	}
+	if cfg != nil && cfg.L1 != nil && cfg.L2 != nil {
+		logic.gateways = newGatewayRegistry(cfg.L1, cfg.L2)
+	}
 	return logic
 }
 
-// updateL2TxClaimInfo updates UserClaimInfos for each transaction history.
-func updateL2TxClaimInfo(ctx context.Context, txHistories []*types.TxHistoryInfo, db *gorm.DB) {
-	l2SentMsgOrm := orm.NewL2SentMsg(db)
-	rollupOrm := orm.NewRollupBatch(db)
+// SetClaimVerifier checks the withdrawals indexed as claimable with verifier before they are listed as claimable, it
+// must be called before the logic serves any request
+func (h *HistoryLogic) SetClaimVerifier(verifier *ClaimVerifier) {
+	h.claimVerifier = verifier
+}
+
+// withQueryTimeout derives the context bounding the queries of one exported method call from the incoming ctx.
+// The methods only calling other exported methods are bounded by the callees.
+func (h *HistoryLogic) withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if h.queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, h.queryTimeout)
+}
+
+// depositCreditFinality returns the finality status of the depositCreditFinality config, empty if not configured
+func depositCreditFinality(finality string) types.L1FinalityStatus {
+	switch finality {
+	case "safe":
+		return types.L1FinalityStatusSafe
+	case "finalized":
+		return types.L1FinalityStatusFinalized
+	default:
+		return ""
+	}
+}
+
+// faucetSenders returns the faucet addresses in the checksummed form the senders are stored in, nil if no faucet is
+// given
+func faucetSenders(faucetAddrs []string) []string {
+	var faucets []string
+	for _, faucetAddr := range faucetAddrs {
+		faucets = append(faucets, common.HexToAddress(faucetAddr).Hex())
+	}
+	return faucets
+}
+
+// crossMsgOrm returns the CrossMsg orm of the history queries, the deposits of the faucets are skipped by the queries
+// so that the pages are full and the totals don't count them
+func (h *HistoryLogic) crossMsgOrm() *orm.CrossMsg {
+	return orm.NewCrossMsgExcludingSenders(h.db, h.faucets)
+}
+
+// crossMsgsToTxHistoryInfos converts the cross messages into the tx history infos with the routes
+func (h *HistoryLogic) crossMsgsToTxHistoryInfos(crossMsgs []*orm.CrossMsg) []*types.TxHistoryInfo {
+	var txHistories []*types.TxHistoryInfo
+	for _, crossMsg := range crossMsgs {
+		txHistory := crossMsgToTxHistoryInfo(crossMsg)
+		if h.gateways != nil {
+			txHistory.Route = h.gateways.route(crossMsg)
+		}
+		if h.includeIndexedAt {
+			txHistory.IndexedAt = crossMsg.CreatedAt
+		}
+		txHistories = append(txHistories, txHistory)
+	}
+	h.updateRelativeTimes(txHistories, time.Now())
+	return txHistories
+}
 
-	var l2MsgHashes []string
+// updateRelativeTimes updates the relative time of each transaction history to now if the enrichment is enabled
+func (h *HistoryLogic) updateRelativeTimes(txHistories []*types.TxHistoryInfo, now time.Time) {
+	if !h.includeRelativeTime {
+		return
+	}
 	for _, txHistory := range txHistories {
-		if !txHistory.IsL1 {
-			l2MsgHashes = append(l2MsgHashes, txHistory.MsgHash)
+		txHistory.RelativeTime = relativeTime(txHistory.BlockTimestamp, now)
+	}
+}
+
+// relativeTime renders the age of the block timestamp to now in the largest whole unit, e.g. "2 hours ago",
+// empty is returned if the block timestamp is unknown.
+func relativeTime(blockTimestamp *time.Time, now time.Time) string {
+	if blockTimestamp == nil {
+		return ""
+	}
+	age := now.Sub(*blockTimestamp)
+	units := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, unit := range units {
+		if n := int64(age / unit.duration); n >= 1 {
+			if n == 1 {
+				return fmt.Sprintf("1 %s ago", unit.name)
+			}
+			return fmt.Sprintf("%d %ss ago", n, unit.name)
 		}
 	}
+	// less than a minute, or in the future because of the clock drift
+	return "just now"
+}
 
-	l2sentMsgs, err := l2SentMsgOrm.GetL2SentMsgsByHashes(ctx, l2MsgHashes)
-	if err != nil || len(l2sentMsgs) == 0 {
-		log.Debug("GetL2SentMsgsByHashes failed", "l2 sent msgs", l2sentMsgs, "error", err)
+// finishTxHistories fills the enrichments derived from the converted tx histories, the claim expiry, the address
+// labels and the data completeness, then redacts their sensitive fields. It runs last on every converted page.
+func (h *HistoryLogic) finishTxHistories(ctx context.Context, txHistories []*types.TxHistoryInfo) {
+	h.updateClaimExpiresAt(txHistories)
+	h.updateAddressLabels(ctx, txHistories)
+	updateDataCompleteness(txHistories)
+	h.redactSensitiveFields(txHistories)
+}
+
+// updateClaimExpiresAt updates the claim expiry time of each transaction history with claim info
+func (h *HistoryLogic) updateClaimExpiresAt(txHistories []*types.TxHistoryInfo) {
+	for _, txHistory := range txHistories {
+		if txHistory.ClaimInfo != nil {
+			txHistory.ClaimInfo.ClaimExpiresAt = claimExpiresAt(txHistory.BlockTimestamp, h.claimExpiry)
+		}
+	}
+}
+
+// updateDataCompleteness updates the data completeness score of each transaction history
+func updateDataCompleteness(txHistories []*types.TxHistoryInfo) {
+	for _, txHistory := range txHistories {
+		txHistory.DataCompleteness = dataCompleteness(txHistory)
+	}
+}
+
+// dataCompleteness scores from 0 to 100 the share of the enrichments populated in the tx history, among the ones
+// expected once the message is relayed on the target layer. A message not relayed yet scores below 100, since its
+// relay and for a withdrawal its batch finalization can still be filled by a later fetch.
+func dataCompleteness(txHistory *types.TxHistoryInfo) uint8 {
+	checks := []bool{
+		txHistory.BlockTimestamp != nil,
+		txHistory.FinalizeTx != nil && txHistory.FinalizeTx.Hash != "",
+	}
+	if txHistory.IsL1 {
+		checks = append(checks, txHistory.L1BlockHash != "")
+	} else {
+		claimInfo := txHistory.ClaimInfo
+		checks = append(checks,
+			// the batch of the withdrawal is found
+			claimInfo != nil,
+			claimInfo != nil && claimInfo.Proof != "0x" && claimInfo.Proof != "" && !claimInfo.ProofPruned,
+			txHistory.ClaimStatus == types.ClaimStatusClaimable || txHistory.ClaimStatus == types.ClaimStatusClaimed,
+		)
+	}
+	var populated int
+	for _, check := range checks {
+		if check {
+			populated++
+		}
+	}
+	return uint8(populated * 100 / len(checks))
+}
+
+// redactSensitiveFields blanks the message calldata and the proof of each transaction history if the redaction is
+// enabled, the other fields including the claim key computed from the calldata are kept
+func (h *HistoryLogic) redactSensitiveFields(txHistories []*types.TxHistoryInfo) {
+	if !h.redactSensitive {
+		return
+	}
+	for _, txHistory := range txHistories {
+		if txHistory.ClaimInfo != nil {
+			txHistory.ClaimInfo.Message = ""
+			txHistory.ClaimInfo.Proof = ""
+		}
+		if txHistory.ExecuteParams != nil {
+			txHistory.ExecuteParams.Message = ""
+		}
+	}
+}
+
+// claimExpiresAt computes the claim expiry time from the layer2 block timestamp of the withdrawal,
+// nil is returned if claims never expire or the block timestamp is unknown.
+func claimExpiresAt(blockTimestamp *time.Time, claimExpiry time.Duration) *time.Time {
+	if claimExpiry == 0 || blockTimestamp == nil {
+		return nil
+	}
+	expiresAt := blockTimestamp.Add(claimExpiry)
+	return &expiresAt
+}
+
+// sortClaimableTxs sorts the claimable txs in place by the given order
+func sortClaimableTxs(txHistories []*types.TxHistoryInfo, sortBy types.ClaimableSortBy) {
+	if sortBy != types.ClaimableSortByExpirySoonest {
 		return
 	}
+	expiresAt := func(txHistory *types.TxHistoryInfo) *time.Time {
+		if txHistory.ClaimInfo == nil {
+			return nil
+		}
+		return txHistory.ClaimInfo.ClaimExpiresAt
+	}
+	sort.SliceStable(txHistories, func(i, j int) bool {
+		a, b := expiresAt(txHistories[i]), expiresAt(txHistories[j])
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.Before(*b)
+	})
+}
+
+// splitByProofReadiness partitions the claimable txs into the ones whose proof verifies against a finalized batch
+// and the others, keeping the order. The claimable txs always have a stored proof, unless it's pruned.
+func splitByProofReadiness(txHistories []*types.TxHistoryInfo) (ready, proofPending []*types.TxHistoryInfo) {
+	for _, txHistory := range txHistories {
+		if txHistory.ClaimInfo != nil && txHistory.ClaimStatus == types.ClaimStatusClaimable && !txHistory.ClaimInfo.ProofPruned {
+			ready = append(ready, txHistory)
+		} else {
+			proofPending = append(proofPending, txHistory)
+		}
+	}
+	return ready, proofPending
+}
+
+// filterClaimsExpiringWithin returns the txs whose claim expires within [now, now+window], in the original order
+func filterClaimsExpiringWithin(txHistories []*types.TxHistoryInfo, now time.Time, window time.Duration) []*types.TxHistoryInfo {
+	deadline := now.Add(window)
+	var expiring []*types.TxHistoryInfo
+	for _, txHistory := range txHistories {
+		if txHistory.ClaimInfo == nil || txHistory.ClaimInfo.ClaimExpiresAt == nil {
+			continue
+		}
+		expiresAt := *txHistory.ClaimInfo.ClaimExpiresAt
+		if !expiresAt.Before(now) && !expiresAt.After(deadline) {
+			expiring = append(expiring, txHistory)
+		}
+	}
+	return expiring
+}
+
+// getOffsetLimit converts the pagination into sql offset and limit, page < 1 is treated as page 1
+// and the page size is clamped to maxPageSize.
+func getOffsetLimit(pagination types.Pagination) (int, int) {
+	page := pagination.Page
+	if page < 1 {
+		page = 1
+	}
+	limit := getLimit(pagination.PageSize)
+	return int(page-1) * limit, limit
+}
+
+// getLimit converts the page size into sql limit, zero is treated as defaultPageSize and the page size is clamped to maxPageSize.
+func getLimit(pageSize uint64) int {
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return int(pageSize)
+}
+
+// txHashOnLayer returns layer1Hash if onLayer1 and layer2Hash otherwise, the messages are sent and relayed on opposite
+// layers so only the hash of the given layer is relevant. Empty is returned if the hash is empty or "0x".
+func txHashOnLayer(layer1Hash, layer2Hash string, onLayer1 bool) string {
+	hash := layer2Hash
+	if onLayer1 {
+		hash = layer1Hash
+	}
+	if hash == "0x" {
+		return ""
+	}
+	return hash
+}
+
+// crossMsgToTxHistoryInfo converts a cross message into the tx history info without claim and finalize infos.
+func crossMsgToTxHistoryInfo(crossMsg *orm.CrossMsg) *types.TxHistoryInfo {
+	isL1 := orm.MsgType(crossMsg.MsgType) == orm.Layer1Msg
+	txHistory := &types.TxHistoryInfo{
+		Hash:           txHashOnLayer(crossMsg.Layer1Hash, crossMsg.Layer2Hash, isL1),
+		MsgHash:        crossMsg.MsgHash,
+		Amount:         crossMsg.Amount,
+		GasFee:         crossMsg.GasFee,
+		BridgeFee:      crossMsg.BridgeFee,
+		To:             crossMsg.Target,
+		L1Token:        crossMsg.Layer1Token,
+		L2Token:        crossMsg.Layer2Token,
+		TokenType:      tokenType(crossMsg),
+		TokenIDs:       crossMsg.TokenIDList(),
+		TokenAmounts:   crossMsg.TokenAmountList(),
+		IsL1:           isL1,
+		BlockNumber:    crossMsg.Height,
+		BlockTimestamp: crossMsg.Timestamp,
+		CreatedAt:      crossMsg.CreatedAt,
+		FinalizeTx:     &types.Finalized{Hash: ""},
+	}
+	txHistory.Fees = sendTxFees(crossMsg, txHistory.Hash, isL1)
+	if txHistory.IsL1 {
+		txHistory.L1BlockHash = crossMsg.BlockHash
+		txHistory.OriginMethod = crossMsg.OriginMethod
+		txHistory.OriginTxNonce = crossMsg.OriginTxNonce
+	}
+	return txHistory
+}
+
+// tokenType returns the category of the token bridged by the cross message, the messages without token addresses
+// bridge native ETH
+func tokenType(crossMsg *orm.CrossMsg) types.TokenType {
+	if isEmptyToken(crossMsg.Layer1Token) && isEmptyToken(crossMsg.Layer2Token) {
+		return types.TokenTypeETH
+	}
+	switch orm.AssetType(crossMsg.Asset) {
+	case orm.ERC20:
+		return types.TokenTypeERC20
+	case orm.ERC721:
+		return types.TokenTypeERC721
+	case orm.ERC1155:
+		return types.TokenTypeERC1155
+	default:
+		return types.TokenTypeETH
+	}
+}
+
+// isEmptyToken returns whether the token address is not set
+func isEmptyToken(token string) bool {
+	return token == "" || common.HexToAddress(token) == (common.Address{})
+}
+
+// tokenTypeAssets returns the assets of the token type to filter the cross messages by, nil for TokenTypeAll
+func tokenTypeAssets(tokenType types.TokenType) ([]orm.AssetType, error) {
+	switch tokenType {
+	case types.TokenTypeAll:
+		return nil, nil
+	case types.TokenTypeETH:
+		return []orm.AssetType{orm.ETH}, nil
+	case types.TokenTypeERC20:
+		return []orm.AssetType{orm.ERC20}, nil
+	case types.TokenTypeNFT:
+		return []orm.AssetType{orm.ERC721, orm.ERC1155}, nil
+	case types.TokenTypeERC721:
+		return []orm.AssetType{orm.ERC721}, nil
+	case types.TokenTypeERC1155:
+		return []orm.AssetType{orm.ERC1155}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown token type %q", ErrInvalidParameter, tokenType)
+	}
+}
+
+// sortOrder returns the orm sort order of the given order
+func sortOrder(order types.SortOrder) (orm.SortOrder, error) {
+	switch order {
+	case types.SortOrderDesc:
+		return orm.SortDesc, nil
+	case types.SortOrderAsc:
+		return orm.SortAsc, nil
+	default:
+		return orm.SortDesc, fmt.Errorf("%w: unknown sort order %q", ErrInvalidParameter, order)
+	}
+}
+
+// updateL2TxClaimInfo updates UserClaimInfos for each transaction history.
+func updateL2TxClaimInfo(ctx context.Context, txHistories []*types.TxHistoryInfo, db *gorm.DB, lookups *claimLookupCache) error {
+	l2MsgHashes := uniqueMsgHashes(txHistories, func(txHistory *types.TxHistoryInfo) bool { return !txHistory.IsL1 })
+	if len(l2MsgHashes) == 0 {
+		return nil
+	}
+
+	// the proven withdrawals are served from the lookups, only the others are queried
+	var l2sentMsgs []*orm.L2SentMsg
+	missingHashes := make([]string, 0, len(l2MsgHashes))
+	for _, msgHash := range l2MsgHashes {
+		if l2sentMsg, found := lookups.l2SentMsg(msgHash); found {
+			l2sentMsgs = append(l2sentMsgs, l2sentMsg)
+		} else {
+			missingHashes = append(missingHashes, msgHash)
+		}
+	}
+	l2SentMsgOrm := orm.NewL2SentMsg(db)
+	err := forEachChunk(len(missingHashes), func(start, end int) error {
+		msgs, err := l2SentMsgOrm.GetL2SentMsgsByHashes(ctx, missingHashes[start:end])
+		l2sentMsgs = append(l2sentMsgs, msgs...)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return updateL2TxClaimInfoFromMsgs(ctx, txHistories, l2sentMsgs, db, lookups)
+}
+
+// updateL2TxClaimInfoFromMsgs updates UserClaimInfos for each transaction history from the already fetched
+// layer2 sent messages, so that callers holding them don't query them again. The batches, the reverted batches
+// and the latest batch are looked up concurrently, the finalized batches and the proven withdrawals are cached in
+// lookups, may be nil.
+func updateL2TxClaimInfoFromMsgs(ctx context.Context, txHistories []*types.TxHistoryInfo, l2sentMsgs []*orm.L2SentMsg, db *gorm.DB, lookups *claimLookupCache) error {
+	if len(l2sentMsgs) == 0 {
+		return nil
+	}
+	rollupOrm := orm.NewRollupBatch(db)
 
 	l2MsgMap := make(map[string]*orm.L2SentMsg, len(l2sentMsgs))
+	batchMap := make(map[uint64]*orm.RollupBatch)
+	revertedBatchMap := make(map[uint64][]*orm.RollupBatch)
 	var batchIndexes []uint64
+	seenBatchIndexes := make(map[uint64]struct{})
 	for _, l2sentMsg := range l2sentMsgs {
 		l2MsgMap[l2sentMsg.MsgHash] = l2sentMsg
-		batchIndexes = append(batchIndexes, l2sentMsg.BatchIndex)
+		if _, exists := seenBatchIndexes[l2sentMsg.BatchIndex]; !exists {
+			seenBatchIndexes[l2sentMsg.BatchIndex] = struct{}{}
+			// the finalized batches are served from the lookups, only the others are queried
+			if cached, found := lookups.batch(l2sentMsg.BatchIndex); found {
+				batchMap[l2sentMsg.BatchIndex] = cached.batch
+				revertedBatchMap[l2sentMsg.BatchIndex] = cached.reverted
+				continue
+			}
+			batchIndexes = append(batchIndexes, l2sentMsg.BatchIndex)
+		}
 	}
 
-	batches, err := rollupOrm.GetRollupBatchesByIndexes(ctx, batchIndexes)
-	if err != nil {
-		log.Debug("GetRollupBatchesByIndexes failed", "error", err)
-		return
+	var batches, revertedBatches []*orm.RollupBatch
+	var latestBatch *orm.RollupBatch
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return forEachChunk(len(batchIndexes), func(start, end int) error {
+			chunk, err := rollupOrm.GetRollupBatchesByIndexes(gctx, batchIndexes[start:end])
+			batches = append(batches, chunk...)
+			return err
+		})
+	})
+	g.Go(func() error {
+		return forEachChunk(len(batchIndexes), func(start, end int) error {
+			chunk, err := rollupOrm.GetRevertedRollupBatchesByIndexes(gctx, batchIndexes[start:end])
+			revertedBatches = append(revertedBatches, chunk...)
+			return err
+		})
+	})
+	g.Go(func() error {
+		var err error
+		latestBatch, err = rollupOrm.GetLatestRollupBatch(gctx)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return err
 	}
 
-	batchMap := make(map[uint64]*orm.RollupBatch, len(batches))
 	for _, batch := range batches {
 		batchMap[batch.BatchIndex] = batch
 	}
+	for _, batch := range revertedBatches {
+		revertedBatchMap[batch.BatchIndex] = append(revertedBatchMap[batch.BatchIndex], batch)
+	}
+	lookups.addBatches(batchIndexes, batchMap, revertedBatchMap)
+	lookups.addL2SentMsgs(l2sentMsgs, batchMap)
+	var latestBatchIndex uint64
+	if latestBatch != nil {
+		latestBatchIndex = latestBatch.BatchIndex
+	}
+
+	getRebatch := func(height uint64) (*orm.RollupBatch, error) {
+		return rollupOrm.GetRollupBatchByBlockNumber(ctx, height)
+	}
+	return fillL2TxClaimInfos(txHistories, l2MsgMap, batchMap, revertedBatchMap, latestBatchIndex, getRebatch)
+}
 
+// fillL2TxClaimInfos fills the claim infos of the layer2 transaction histories from the sent messages and the batches
+// keyed by the message hash and the batch index, getRebatch looks up the batch committing a block again after a revert.
+// The transaction histories whose message is not in l2MsgMap, e.g. not indexed yet, are left without claim info.
+// The error of getRebatch is returned.
+func fillL2TxClaimInfos(txHistories []*types.TxHistoryInfo, l2MsgMap map[string]*orm.L2SentMsg, batchMap map[uint64]*orm.RollupBatch,
+	revertedBatchMap map[uint64][]*orm.RollupBatch, latestBatchIndex uint64, getRebatch func(height uint64) (*orm.RollupBatch, error)) error {
 	for _, txHistory := range txHistories {
 		if txHistory.IsL1 {
 			continue
 		}
 
 		l2sentMsg, foundL2SentMsg := l2MsgMap[txHistory.MsgHash]
+		if !foundL2SentMsg {
+			continue
+		}
+		txHistory.GlobalWithdrawalIndex = globalWithdrawalIndex(l2sentMsg)
+		txHistory.MessageIndexInBlock = l2sentMsg.LogIndex
 		batch, foundBatch := batchMap[l2sentMsg.BatchIndex]
-		if foundL2SentMsg && foundBatch {
+		if foundBatch {
 			txHistory.ClaimInfo = &types.UserClaimInfo{
-				From:       l2sentMsg.Sender,
-				To:         l2sentMsg.Target,
-				Value:      l2sentMsg.Value,
-				Nonce:      strconv.FormatUint(l2sentMsg.Nonce, 10),
-				Message:    l2sentMsg.MsgData,
-				Proof:      "0x" + l2sentMsg.MsgProof,
-				BatchHash:  batch.BatchHash,
-				BatchIndex: strconv.FormatUint(l2sentMsg.BatchIndex, 10),
+				From:        l2sentMsg.Sender,
+				To:          l2sentMsg.Target,
+				Value:       l2sentMsg.Value,
+				Nonce:       strconv.FormatUint(l2sentMsg.Nonce, 10),
+				Message:     l2sentMsg.MsgData,
+				Proof:       "0x" + l2sentMsg.MsgProof,
+				BatchHash:   batch.BatchHash,
+				BatchIndex:  strconv.FormatUint(l2sentMsg.BatchIndex, 10),
+				StateRoot:   batch.StateRoot,
+				Claimable:   isClaimableAtBlock(l2sentMsg, batch, math.MaxUint64),
+				ProofPruned: isProofPruned(l2sentMsg, batch, latestBatchIndex),
+			}
+			txHistory.ClaimStatus = claimStatusAtBlock(l2sentMsg, batch, nil, math.MaxUint64)
+			// the gas is left unestimated if the claim can't be encoded, the claim key and the reverted batch are still
+			// resolved
+			estimatedGas, err := estimateClaimGas(txHistory.ClaimInfo)
+			if err != nil {
+				log.Debug("estimateClaimGas failed", "msg hash", txHistory.MsgHash, "error", err)
+			} else {
+				txHistory.ClaimInfo.EstimatedGas = estimatedGas
+			}
+			txHistory.ClaimInfo.ClaimKey, err = claimKey(txHistory.ClaimInfo)
+			if err != nil {
+				log.Debug("claimKey failed", "msg hash", txHistory.MsgHash, "error", err)
+			}
+		}
+
+		if inRevertedBatch(l2sentMsg, revertedBatchMap[l2sentMsg.BatchIndex]) {
+			rebatch := batch
+			if !foundBatch || !batch.ContainsBlock(l2sentMsg.Height) {
+				var err error
+				rebatch, err = getRebatch(l2sentMsg.Height)
+				if err != nil {
+					return err
+				}
 			}
+			updateRevertedBatchInfo(txHistory, l2sentMsg, rebatch)
 		}
 	}
+	return nil
 }
 
-func updateCrossTxHashes(ctx context.Context, txHistories []*types.TxHistoryInfo, db *gorm.DB) {
-	msgHashes := make([]string, len(txHistories))
-	for i, txHistory := range txHistories {
-		msgHashes[i] = txHistory.MsgHash
+// inRevertedBatch returns whether the message was committed in one of the reverted batches
+func inRevertedBatch(l2sentMsg *orm.L2SentMsg, revertedBatches []*orm.RollupBatch) bool {
+	for _, revertedBatch := range revertedBatches {
+		if revertedBatch.ContainsBlock(l2sentMsg.Height) {
+			return true
+		}
 	}
+	return false
+}
 
-	relayed := orm.NewRelayedMsg(db)
-	relayedMsgs, err := relayed.GetRelayedMsgsByHashes(ctx, msgHashes)
-	if err != nil || len(relayedMsgs) == 0 {
-		log.Debug("GetRelayedMsgsByHashes failed", "msg hashes", msgHashes, "relayed msgs", relayedMsgs, "error", err)
-		return
+// updateRevertedBatchInfo updates the tx history of a message committed in a reverted batch, rebatch is the batch
+// the message is committed in again, nil if not committed yet. The claim info is only kept once the message is
+// proven against the finalized rebatch, so that users aren't shown a proof against the reverted batch.
+func updateRevertedBatchInfo(txHistory *types.TxHistoryInfo, l2sentMsg *orm.L2SentMsg, rebatch *orm.RollupBatch) {
+	txHistory.BatchReverted = true
+	if rebatch != nil {
+		rebatchedIndex := rebatch.BatchIndex
+		txHistory.RebatchedIndex = &rebatchedIndex
+		if rebatch.IsFinalized() && rebatch.BatchIndex == l2sentMsg.BatchIndex {
+			return
+		}
+	}
+	txHistory.ClaimInfo = nil
+	txHistory.ClaimStatus = types.ClaimStatusRebatchPending
+}
+
+// globalWithdrawalIndex returns the position of the message in the withdrawal sequence of all users, which is the
+// message nonce assigned by L2ScrollMessenger in sending order. nil is returned if the message is not found.
+func globalWithdrawalIndex(l2sentMsg *orm.L2SentMsg) *uint64 {
+	if l2sentMsg == nil {
+		return nil
+	}
+	index := l2sentMsg.Nonce
+	return &index
+}
+
+// isProofPruned returns true when the batch of the message is finalized but the stored proof is empty
+// and the batch has fallen out of the proof active window.
+func isProofPruned(l2sentMsg *orm.L2SentMsg, batch *orm.RollupBatch, latestBatchIndex uint64) bool {
+	if !batch.IsFinalized() || l2sentMsg.MsgProof != "" {
+		return false
+	}
+	return latestBatchIndex > batch.BatchIndex+proofActiveWindow
+}
+
+// isClaimableAtBlock returns whether the layer2 withdrawal can be claimed at the layer1 block atBlock, once its batch
+// is finalized at or before atBlock and its proof is generated. Both the claim status and ClaimInfo.Claimable derive
+// from it, so that a withdrawal is never shown claimable without a proof to claim it with.
+func isClaimableAtBlock(l2sentMsg *orm.L2SentMsg, batch *orm.RollupBatch, atBlock uint64) bool {
+	return batch != nil && batch.IsFinalized() && batch.FinalizeHeight <= atBlock && l2sentMsg.MsgProof != ""
+}
+
+// claimStatusAtBlock computes the claim status of the layer2 withdrawal using only the batch finalization
+// and the layer1 relay happened at or before the layer1 block atBlock, see isClaimableAtBlock. The proof isn't
+// timestamped, the current one is used.
+func claimStatusAtBlock(l2sentMsg *orm.L2SentMsg, batch *orm.RollupBatch, relayedMsg *orm.RelayedMsg, atBlock uint64) types.ClaimStatus {
+	if l2sentMsg == nil {
+		return types.ClaimStatusUnknown
+	}
+	if relayedMsg != nil && relayedMsg.Layer1Hash != "" && relayedMsg.Height <= atBlock {
+		return types.ClaimStatusClaimed
 	}
+	if isClaimableAtBlock(l2sentMsg, batch, atBlock) {
+		return types.ClaimStatusClaimable
+	}
+	return types.ClaimStatusPending
+}
+
+// uniqueMsgHashes returns the distinct message hashes of the tx histories selected by include, all of them if include
+// is nil, in order. The empty hashes are dropped, so that they don't match the rows without message hash.
+func uniqueMsgHashes(txHistories []*types.TxHistoryInfo, include func(txHistory *types.TxHistoryInfo) bool) []string {
+	msgHashes := make([]string, 0, len(txHistories))
+	seen := make(map[string]struct{}, len(txHistories))
+	for _, txHistory := range txHistories {
+		if txHistory.MsgHash == "" || (include != nil && !include(txHistory)) {
+			continue
+		}
+		if _, exists := seen[txHistory.MsgHash]; exists {
+			continue
+		}
+		seen[txHistory.MsgHash] = struct{}{}
+		msgHashes = append(msgHashes, txHistory.MsgHash)
+	}
+	return msgHashes
+}
+
+// enrichmentChunkSize is the maximum number of the values in the IN clause of each enrichment query, the longer lists
+// are queried in chunks so that the size of the queries stays bounded whatever the size of the result set
+const enrichmentChunkSize = 500
+
+// forEachChunk calls fn with the bounds of the consecutive chunks of at most enrichmentChunkSize of the n values in
+// order, it stops at the first error.
+func forEachChunk(n int, fn func(start, end int) error) error {
+	for start := 0; start < n; start += enrichmentChunkSize {
+		end := start + enrichmentChunkSize
+		if end > n {
+			end = n
+		}
+		if err := fn(start, end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	relayedMsgMap := make(map[string]*orm.RelayedMsg, len(relayedMsgs))
-	for _, relayedMsg := range relayedMsgs {
-		relayedMsgMap[relayedMsg.MsgHash] = relayedMsg
+// updateCrossTxHashes updates the finalize tx of each transaction history from the relay of its message.
+func updateCrossTxHashes(ctx context.Context, txHistories []*types.TxHistoryInfo, db *gorm.DB) error {
+	msgHashes := uniqueMsgHashes(txHistories, nil)
+	if len(msgHashes) == 0 {
+		return nil
+	}
+
+	relayedOrm := orm.NewRelayedMsg(db)
+	relayedMsgMap := make(map[string]*orm.RelayedMsg, len(msgHashes))
+	err := forEachChunk(len(msgHashes), func(start, end int) error {
+		relayedMsgs, err := relayedOrm.GetRelayedMsgsByHashes(ctx, msgHashes[start:end])
+		for _, relayedMsg := range relayedMsgs {
+			relayedMsgMap[relayedMsg.MsgHash] = relayedMsg
+		}
+		return err
+	})
+	if err != nil {
+		return err
 	}
 
 	for _, txHistory := range txHistories {
 		if relayedMsg, found := relayedMsgMap[txHistory.MsgHash]; found {
-			txHistory.FinalizeTx.Hash = relayedMsg.Layer1Hash + relayedMsg.Layer2Hash
+			// deposits are relayed on layer2 and withdrawals on layer1
+			txHistory.FinalizeTx.Hash = txHashOnLayer(relayedMsg.Layer1Hash, relayedMsg.Layer2Hash, !txHistory.IsL1)
 			txHistory.FinalizeTx.BlockNumber = relayedMsg.Height
+			txHistory.FinalizeTx.GasFee = relayedMsg.GasFee
+			if !txHistory.IsL1 {
+				addClaimTxFees(txHistory.Fees, txHistory.FinalizeTx.Hash, relayedMsg.GasFee)
+			}
+			txHistory.Delivered = relayedMsg.Delivered
 		}
 	}
+	return nil
 }
 
-func updateCrossTxHashesAndL2TxClaimInfo(ctx context.Context, txHistories []*types.TxHistoryInfo, db *gorm.DB) {
-	updateCrossTxHashes(ctx, txHistories, db)
-	updateL2TxClaimInfo(ctx, txHistories, db)
+// updateCrossTxHashesAndL2TxClaimInfo enriches the transaction histories with the relays, the claim infos and the token
+// metadata. The lookups don't depend on each other and write disjoint fields, so they run concurrently, the first
+// error cancels the others and is returned. The relayed deposits are credited once their layer1 block reaches the
+// creditFinality. The finalized batches and the proven withdrawals of the claim infos are cached in lookups, may be nil.
+func updateCrossTxHashesAndL2TxClaimInfo(ctx context.Context, txHistories []*types.TxHistoryInfo, db *gorm.DB, creditFinality types.L1FinalityStatus, lookups *claimLookupCache) error {
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return updateCrossTxHashes(gctx, txHistories, db)
+	})
+	g.Go(func() error {
+		return updateL2TxClaimInfo(gctx, txHistories, db, lookups)
+	})
+	g.Go(func() error {
+		return updateTokenMetadata(gctx, txHistories, db)
+	})
+	if err := g.Wait(); err != nil {
+		return err
+	}
+	return updateOperationTypes(ctx, txHistories, db, creditFinality)
 }
 
-// GetClaimableTxsByAddress get all claimable txs under given address
-func (h *HistoryLogic) GetClaimableTxsByAddress(ctx context.Context, address common.Address) ([]*types.TxHistoryInfo, uint64, error) {
-	var txHistories []*types.TxHistoryInfo
-	l2SentMsgOrm := orm.NewL2SentMsg(h.db)
-	l2CrossMsgOrm := orm.NewCrossMsg(h.db)
-	results, err := l2SentMsgOrm.GetClaimableL2SentMsgByAddress(ctx, address.Hex())
-	if err != nil || len(results) == 0 {
-		return txHistories, 0, err
+// updateOperationTypes labels each transaction history with its operation type, the relayed deposits whose layer1
+// block hasn't reached the creditFinality are labeled pending. It must run after the finalize tx and the claim info
+// are updated.
+func updateOperationTypes(ctx context.Context, txHistories []*types.TxHistoryInfo, db *gorm.DB, creditFinality types.L1FinalityStatus) error {
+	msgHashes := uniqueMsgHashes(txHistories, nil)
+	failedRelayedOrm := orm.NewFailedRelayedMsg(db)
+	relayFailedSet := make(map[string]struct{})
+	err := forEachChunk(len(msgHashes), func(start, end int) error {
+		failedRelayedMsgs, err := failedRelayedOrm.GetFailedRelayedMsgsByHashes(ctx, msgHashes[start:end])
+		for _, failedRelayedMsg := range failedRelayedMsgs {
+			relayFailedSet[failedRelayedMsg.MsgHash] = struct{}{}
+		}
+		return err
+	})
+	if err != nil {
+		return err
 	}
-	var msgHashList []string
-	for _, result := range results {
-		msgHashList = append(msgHashList, result.MsgHash)
+	if err = updateL1FinalityStatuses(ctx, txHistories, db); err != nil {
+		return err
 	}
-	crossMsgs, err := l2CrossMsgOrm.GetL2CrossMsgByMsgHashList(ctx, msgHashList)
-	// crossMsgs can be empty, because they can be emitted by user directly call contract
+	if err = updateReplayedAndDropped(ctx, txHistories, db); err != nil {
+		return err
+	}
+
+	for _, txHistory := range txHistories {
+		if !txHistory.IsL1 && isRelayed(txHistory) {
+			txHistory.ClaimStatus = types.ClaimStatusClaimed
+		}
+		_, relayFailed := relayFailedSet[txHistory.MsgHash]
+		txHistory.OperationType = operationType(txHistory, relayFailed)
+		txHistory.Status = txStatus(txHistory, relayFailed)
+		switch {
+		case txHistory.IsL1 && isRelayed(txHistory) && !reachesFinality(txHistory.L1FinalityStatus, creditFinality):
+			txHistory.OperationType = types.OperationTypeDepositPending
+			txHistory.Status = types.TxStatusPending
+		case txHistory.IsL1 && !isRelayed(txHistory) && txHistory.DropTxHash != "":
+			// the messenger drops the deposits not executed only, also the failed ones
+			txHistory.OperationType = types.OperationTypeDepositDropped
+			txHistory.Status = types.TxStatusDropped
+			if txHistory.Refunded {
+				txHistory.OperationType = types.OperationTypeDepositRefunded
+				txHistory.Status = types.TxStatusRefunded
+			}
+		}
+	}
+	return updateExecuteParams(ctx, txHistories, db)
+}
+
+// updateL1FinalityStatuses updates the finality of the layer1 block of each deposit from the safe and the finalized
+// blocks saved by the fetcher, the finality is left empty if neither is saved yet.
+func updateL1FinalityStatuses(ctx context.Context, txHistories []*types.TxHistoryInfo, db *gorm.DB) error {
+	hasDeposits := false
+	for _, txHistory := range txHistories {
+		hasDeposits = hasDeposits || txHistory.IsL1
+	}
+	if !hasDeposits {
+		return nil
+	}
+
+	numbers, err := orm.NewL1BlockTag(db).GetL1BlockTagNumbers(ctx)
 	if err != nil {
-		return txHistories, 0, err
+		return err
 	}
-	crossMsgMap := make(map[string]*orm.CrossMsg)
-	for _, crossMsg := range crossMsgs {
-		crossMsgMap[crossMsg.MsgHash] = crossMsg
+	safe, finalized := numbers[orm.L1BlockTagSafe], numbers[orm.L1BlockTagFinalized]
+	for _, txHistory := range txHistories {
+		if txHistory.IsL1 {
+			txHistory.L1FinalityStatus = l1FinalityStatus(txHistory.BlockNumber, safe, finalized)
+		}
 	}
-	for _, result := range results {
-		txInfo := &types.TxHistoryInfo{
-			Hash:        result.TxHash,
-			MsgHash:     result.MsgHash,
-			IsL1:        false,
-			BlockNumber: result.Height,
-			FinalizeTx:  &types.Finalized{},
+	return nil
+}
+
+// updateReplayedAndDropped updates the latest replay, the drop and the refund of each deposit, the replays append the
+// message to the L1MessageQueue again with a new gas limit and the drop refunds the deposit once its queue indexes are
+// skipped. The refunds are queried for the dropped deposits only.
+func updateReplayedAndDropped(ctx context.Context, txHistories []*types.TxHistoryInfo, db *gorm.DB) error {
+	msgHashes := uniqueMsgHashes(txHistories, func(txHistory *types.TxHistoryInfo) bool { return txHistory.IsL1 })
+	if len(msgHashes) == 0 {
+		return nil
+	}
+
+	replayedOrm := orm.NewReplayedMsg(db)
+	droppedOrm := orm.NewDroppedMsg(db)
+	replayTxHashes := make(map[string]string, len(msgHashes))
+	dropTxHashes := make(map[string]string, len(msgHashes))
+	err := forEachChunk(len(msgHashes), func(start, end int) error {
+		replayedMsgs, err := replayedOrm.GetReplayedMsgsByHashes(ctx, msgHashes[start:end])
+		if err != nil {
+			return err
 		}
-		if crossMsg, exist := crossMsgMap[result.MsgHash]; exist {
-			txInfo.Amount = crossMsg.Amount
-			txInfo.To = crossMsg.Target
-			txInfo.BlockTimestamp = crossMsg.Timestamp
-			txInfo.CreatedAt = crossMsg.CreatedAt
-			txInfo.L1Token = crossMsg.Layer1Token
-			txInfo.L2Token = crossMsg.Layer2Token
+		// the latest replay of each deposit comes first
+		for _, replayedMsg := range replayedMsgs {
+			if _, exists := replayTxHashes[replayedMsg.MsgHash]; !exists {
+				replayTxHashes[replayedMsg.MsgHash] = replayedMsg.Layer1Hash
+			}
 		}
-		txHistories = append(txHistories, txInfo)
+		droppedMsgs, err := droppedOrm.GetDroppedMsgsByHashes(ctx, msgHashes[start:end])
+		for _, droppedMsg := range droppedMsgs {
+			dropTxHashes[droppedMsg.MsgHash] = droppedMsg.Layer1Hash
+		}
+		return err
+	})
+	if err != nil {
+		return err
 	}
-	updateL2TxClaimInfo(ctx, txHistories, h.db)
-	return txHistories, uint64(len(results)), err
-}
 
-// GetTxsByHashes get tx infos under given tx hashes
-func (h *HistoryLogic) GetTxsByHashes(ctx context.Context, hashes []string) ([]*types.TxHistoryInfo, error) {
-	CrossMsgOrm := orm.NewCrossMsg(h.db)
-	results, err := CrossMsgOrm.GetCrossMsgsByHashes(ctx, hashes)
+	refundTxHashes, err := getRefundTxHashes(ctx, dropTxHashes, db)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	var txHistories []*types.TxHistoryInfo
-	for _, result := range results {
-		txHistory := &types.TxHistoryInfo{
-			Hash:           result.Layer1Hash + result.Layer2Hash,
-			MsgHash:        result.MsgHash,
-			Amount:         result.Amount,
-			To:             result.Target,
-			L1Token:        result.Layer1Token,
-			L2Token:        result.Layer2Token,
-			IsL1:           orm.MsgType(result.MsgType) == orm.Layer1Msg,
-			BlockNumber:    result.Height,
-			BlockTimestamp: result.Timestamp,
-			CreatedAt:      result.CreatedAt,
-			FinalizeTx:     &types.Finalized{Hash: ""},
+	for _, txHistory := range txHistories {
+		if !txHistory.IsL1 {
+			continue
 		}
-		txHistories = append(txHistories, txHistory)
+		txHistory.ReplayTxHash, txHistory.Replayed = replayTxHashes[txHistory.MsgHash]
+		txHistory.DropTxHash = dropTxHashes[txHistory.MsgHash]
+		txHistory.RefundTxHash, txHistory.Refunded = refundTxHashes[txHistory.MsgHash]
 	}
+	return nil
+}
 
-	updateCrossTxHashesAndL2TxClaimInfo(ctx, txHistories, h.db)
-	return txHistories, nil
+// getRefundTxHashes returns the layer1 txs refunding the dropped deposits, keyed by the message hash
+func getRefundTxHashes(ctx context.Context, dropTxHashes map[string]string, db *gorm.DB) (map[string]string, error) {
+	refundTxHashes := make(map[string]string, len(dropTxHashes))
+	if len(dropTxHashes) == 0 {
+		return refundTxHashes, nil
+	}
+	msgHashes := make([]string, 0, len(dropTxHashes))
+	for msgHash := range dropTxHashes {
+		msgHashes = append(msgHashes, msgHash)
+	}
+	sort.Strings(msgHashes)
+
+	refundedOrm := orm.NewRefundedMsg(db)
+	err := forEachChunk(len(msgHashes), func(start, end int) error {
+		refundedMsgs, err := refundedOrm.GetRefundedMsgsByHashes(ctx, msgHashes[start:end])
+		for _, refundedMsg := range refundedMsgs {
+			refundTxHashes[refundedMsg.MsgHash] = refundedMsg.Layer1Hash
+		}
+		return err
+	})
+	return refundTxHashes, err
+}
+
+// l1FinalityStatus returns the finality of the layer1 block of the number by the numbers of the safe and the finalized
+// blocks, 0 if unknown. The finalized blocks are safe too.
+func l1FinalityStatus(blockNumber, safe, finalized uint64) types.L1FinalityStatus {
+	switch {
+	case safe == 0 && finalized == 0:
+		return ""
+	case blockNumber <= finalized:
+		return types.L1FinalityStatusFinalized
+	case blockNumber <= safe:
+		return types.L1FinalityStatusSafe
+	default:
+		return types.L1FinalityStatusUnsafe
+	}
+}
+
+// reachesFinality returns whether the finality reaches the required one, anything does if nothing is required. The
+// unknown finality doesn't block the deposits, e.g. the fetcher hasn't polled the layer1 node yet.
+func reachesFinality(finality, required types.L1FinalityStatus) bool {
+	switch {
+	case required == "" || finality == "":
+		return true
+	case required == types.L1FinalityStatusSafe:
+		return finality != types.L1FinalityStatusUnsafe
+	default:
+		return finality == types.L1FinalityStatusFinalized
+	}
+}
+
+// txStatus returns the lifecycle status of the tx history following the state machine documented on types.TxStatus,
+// relayFailed is whether a relay of the message failed on the target layer.
+func txStatus(txHistory *types.TxHistoryInfo, relayFailed bool) types.TxStatus {
+	if txHistory.IsL1 {
+		switch {
+		case isRelayed(txHistory):
+			return types.TxStatusRelayed
+		case relayFailed:
+			return types.TxStatusFailed
+		default:
+			return types.TxStatusPending
+		}
+	}
+	switch {
+	case isRelayed(txHistory):
+		return types.TxStatusClaimed
+	case txHistory.ClaimStatus == types.ClaimStatusClaimable && txHistory.ClaimInfo != nil &&
+		!txHistory.ClaimInfo.ProofPruned && txHistory.ClaimInfo.Proof != "0x":
+		return types.TxStatusClaimable
+	default:
+		return types.TxStatusPending
+	}
+}
+
+// updateExecuteParams fills the params of the manual execution on layer2 for the deposits whose execution failed,
+// it must run after the operation types are updated.
+func updateExecuteParams(ctx context.Context, txHistories []*types.TxHistoryInfo, db *gorm.DB) error {
+	msgHashes := uniqueMsgHashes(txHistories, func(txHistory *types.TxHistoryInfo) bool {
+		return txHistory.OperationType == types.OperationTypeDepositFailed
+	})
+	if len(msgHashes) == 0 {
+		return nil
+	}
+
+	crossMsgOrm := orm.NewCrossMsg(db)
+	l1CrossMsgMap := make(map[string]*orm.CrossMsg, len(msgHashes))
+	err := forEachChunk(len(msgHashes), func(start, end int) error {
+		l1CrossMsgs, err := crossMsgOrm.GetL1CrossMsgByMsgHashList(ctx, msgHashes[start:end])
+		for _, l1CrossMsg := range l1CrossMsgs {
+			l1CrossMsgMap[l1CrossMsg.MsgHash] = l1CrossMsg
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, txHistory := range txHistories {
+		if txHistory.OperationType != types.OperationTypeDepositFailed {
+			continue
+		}
+		txHistory.RequiresManualExecution = true
+		txHistory.ExecuteParams = executeParams(l1CrossMsgMap[txHistory.MsgHash])
+	}
+	return nil
+}
+
+// executeParams builds the manual execution params from the layer1 cross message,
+// it returns nil if the message of the deposit is unknown.
+func executeParams(l1CrossMsg *orm.CrossMsg) *types.ExecuteParams {
+	if l1CrossMsg == nil || l1CrossMsg.MsgSender == "" {
+		return nil
+	}
+	return &types.ExecuteParams{
+		From:      l1CrossMsg.MsgSender,
+		To:        l1CrossMsg.MsgTarget,
+		Value:     l1CrossMsg.MsgValue,
+		Nonce:     strconv.FormatUint(l1CrossMsg.MsgNonce, 10),
+		Message:   l1CrossMsg.MsgData,
+		BlockHash: l1CrossMsg.BlockHash,
+	}
+}
+
+// isRelayed returns whether the message of the tx history is relayed on the target layer
+func isRelayed(txHistory *types.TxHistoryInfo) bool {
+	return txHistory.FinalizeTx != nil && txHistory.FinalizeTx.Hash != ""
+}
+
+// operationType classifies the tx history by its direction and status, relayFailed is whether a relay
+// of the message failed on the target layer. A successful relay takes precedence over the failed ones.
+func operationType(txHistory *types.TxHistoryInfo, relayFailed bool) types.OperationType {
+	if txHistory.IsL1 {
+		switch {
+		case isRelayed(txHistory):
+			return types.OperationTypeDeposit
+		case relayFailed:
+			return types.OperationTypeDepositFailed
+		default:
+			return types.OperationTypeDepositPending
+		}
+	}
+	switch {
+	case isRelayed(txHistory):
+		return types.OperationTypeWithdrawalClaimed
+	case relayFailed:
+		return types.OperationTypeWithdrawalClaimFailed
+	case txHistory.ClaimStatus == types.ClaimStatusClaimable:
+		return types.OperationTypeWithdrawalClaimable
+	default:
+		return types.OperationTypeWithdrawalPending
+	}
+}
+
+// GetClaimableTxsByAddress get all claimable txs under given address whose ETH value is at least minValue in wei,
+// nil minValue returns all of them. Only the txs bridging tokens of tokenType are returned, TokenTypeAll doesn't filter.
+// The txs are sorted by the order, latest first by default, and the unfiltered results in the default order are served
+// from the cache when it's configured.
+func (h *HistoryLogic) GetClaimableTxsByAddress(ctx context.Context, address common.Address, minValue *big.Int, tokenType types.TokenType, order types.SortOrder) ([]*types.TxHistoryInfo, uint64, error) {
+	return instrumentPage(h.metrics, "GetClaimableTxsByAddress", func() ([]*types.TxHistoryInfo, uint64, error) {
+		return h.getClaimableTxsByAddress(ctx, address, minValue, tokenType, order)
+	})
+}
+
+// getClaimableTxsByAddress implements GetClaimableTxsByAddress
+func (h *HistoryLogic) getClaimableTxsByAddress(ctx context.Context, address common.Address, minValue *big.Int, tokenType types.TokenType, order types.SortOrder) ([]*types.TxHistoryInfo, uint64, error) {
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	var txHistories []*types.TxHistoryInfo
+	assets, err := tokenTypeAssets(tokenType)
+	if err != nil {
+		return txHistories, 0, err
+	}
+	ormOrder, err := sortOrder(order)
+	if err != nil {
+		return txHistories, 0, err
+	}
+	// only the default order is cached
+	cacheable := minValue == nil && tokenType == types.TokenTypeAll && order == types.SortOrderDesc
+	if cacheable {
+		if resultData := h.getCachedClaimables(ctx, address); resultData != nil {
+			return resultData.Result, resultData.Total, nil
+		}
+	}
+	l2SentMsgOrm := orm.NewL2SentMsg(h.db)
+	results, err := l2SentMsgOrm.GetClaimableL2SentMsgByAddress(ctx, address.Hex(), orm.ClaimableFilter{MinValue: minValue, Assets: assets, Order: ormOrder})
+	if err != nil || len(results) == 0 {
+		return txHistories, 0, err
+	}
+	txHistories, err = h.l2SentMsgsToTxHistoryInfos(ctx, results)
+	if err != nil {
+		return txHistories, 0, err
+	}
+	h.finishTxHistories(ctx, txHistories)
+	if cacheable {
+		h.setCachedClaimables(ctx, address, &types.ResultData{Result: txHistories, Total: uint64(len(txHistories))})
+	}
+	return txHistories, uint64(len(txHistories)), nil
+}
+
+// GetClaimableTxsByAddresses get all claimable txs of each of the addresses keyed by the address hex, in one query for
+// the clients managing many addresses. The addresses without claimable txs are absent, a tx sent by one address on
+// behalf of another is listed under both if both are queried. At most maxClaimableAddresses distinct addresses are
+// queried at once.
+func (h *HistoryLogic) GetClaimableTxsByAddresses(ctx context.Context, addresses []common.Address) (map[string][]*types.TxHistoryInfo, error) {
+	return instrument(h.metrics, "GetClaimableTxsByAddresses", func() (map[string][]*types.TxHistoryInfo, error) {
+		return h.getClaimableTxsByAddresses(ctx, addresses)
+	})
+}
+
+// getClaimableTxsByAddresses implements GetClaimableTxsByAddresses
+func (h *HistoryLogic) getClaimableTxsByAddresses(ctx context.Context, addresses []common.Address) (map[string][]*types.TxHistoryInfo, error) {
+	addressSet := make(map[common.Address]struct{}, len(addresses))
+	addressHexes := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if _, exists := addressSet[address]; !exists {
+			addressSet[address] = struct{}{}
+			addressHexes = append(addressHexes, address.Hex())
+		}
+	}
+	if len(addressHexes) > maxClaimableAddresses {
+		return nil, fmt.Errorf("%w: %d addresses exceed the allowed maximum of %d", ErrInvalidParameter, len(addressHexes), maxClaimableAddresses)
+	}
+	if len(addressHexes) == 0 {
+		return nil, nil
+	}
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	results, err := orm.NewL2SentMsg(h.db).GetClaimableL2SentMsgByAddresses(ctx, addressHexes)
+	if err != nil || len(results) == 0 {
+		return nil, err
+	}
+	txHistories, err := h.l2SentMsgsToTxHistoryInfos(ctx, results)
+	if err != nil {
+		return nil, err
+	}
+	h.finishTxHistories(ctx, txHistories)
+
+	l2MsgMap := make(map[string]*orm.L2SentMsg, len(results))
+	for _, l2sentMsg := range results {
+		l2MsgMap[l2sentMsg.MsgHash] = l2sentMsg
+	}
+	txsByAddress := make(map[string][]*types.TxHistoryInfo)
+	for _, txHistory := range txHistories {
+		l2sentMsg := l2MsgMap[txHistory.MsgHash]
+		senders := []common.Address{common.HexToAddress(l2sentMsg.OriginalSender), common.HexToAddress(l2sentMsg.Sender)}
+		// the original sender is empty for the messages sent by calling the messenger directly
+		if l2sentMsg.OriginalSender == "" || senders[0] == senders[1] {
+			senders = senders[1:]
+		}
+		for _, sender := range senders {
+			if _, queried := addressSet[sender]; queried {
+				txsByAddress[sender.Hex()] = append(txsByAddress[sender.Hex()], txHistory)
+			}
+		}
+	}
+	return txsByAddress, nil
+}
+
+// GetClaimableTxsByAddressPaged get a page of the claimable txs under given address, in the same order as
+// GetClaimableTxsByAddress. The total is the count of all the claimable txs of the address bridging tokens of tokenType,
+// TokenTypeAll doesn't filter. The total and the pages are of the indexed claimable txs, the ones left out of a page by
+// the claim verifier are still counted.
+func (h *HistoryLogic) GetClaimableTxsByAddressPaged(ctx context.Context, address common.Address, tokenType types.TokenType, pagination types.Pagination) ([]*types.TxHistoryInfo, uint64, error) {
+	return instrumentPage(h.metrics, "GetClaimableTxsByAddressPaged", func() ([]*types.TxHistoryInfo, uint64, error) {
+		return h.getClaimableTxsByAddressPaged(ctx, address, tokenType, pagination)
+	})
+}
+
+// getClaimableTxsByAddressPaged implements GetClaimableTxsByAddressPaged
+func (h *HistoryLogic) getClaimableTxsByAddressPaged(ctx context.Context, address common.Address, tokenType types.TokenType, pagination types.Pagination) ([]*types.TxHistoryInfo, uint64, error) {
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	assets, err := tokenTypeAssets(tokenType)
+	if err != nil {
+		return nil, 0, err
+	}
+	l2SentMsgOrm := orm.NewL2SentMsg(h.db)
+	total, err := l2SentMsgOrm.GetTotalClaimableL2SentMsgCountByAddress(ctx, address.Hex(), assets)
+	if err != nil || total == 0 {
+		return nil, 0, err
+	}
+	offset, limit := getOffsetLimit(pagination)
+	results, err := l2SentMsgOrm.GetClaimableL2SentMsgByAddressWithOffset(ctx, address.Hex(), assets, offset, limit)
+	if err != nil || len(results) == 0 {
+		return nil, total, err
+	}
+	txHistories, err := h.l2SentMsgsToTxHistoryInfos(ctx, results)
+	if err != nil {
+		return nil, 0, err
+	}
+	h.finishTxHistories(ctx, txHistories)
+	return txHistories, total, nil
+}
+
+// GetClaimableTxsByAddressWithCursor get a page of the claimable txs under given address ordered by nonce desc,
+// the returned cursor is used to fetch the next page and is nil on the last page.
+func (h *HistoryLogic) GetClaimableTxsByAddressWithCursor(ctx context.Context, address common.Address, pagination types.CursorPagination) ([]*types.TxHistoryInfo, *uint64, error) {
+	return instrumentPage(h.metrics, "GetClaimableTxsByAddressWithCursor", func() ([]*types.TxHistoryInfo, *uint64, error) {
+		return h.getClaimableTxsByAddressWithCursor(ctx, address, pagination)
+	})
+}
+
+// getClaimableTxsByAddressWithCursor implements GetClaimableTxsByAddressWithCursor
+func (h *HistoryLogic) getClaimableTxsByAddressWithCursor(ctx context.Context, address common.Address, pagination types.CursorPagination) ([]*types.TxHistoryInfo, *uint64, error) {
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	limit := getLimit(pagination.PageSize)
+	results, err := orm.NewL2SentMsg(h.db).GetClaimableL2SentMsgByAddressWithCursor(ctx, address.Hex(), pagination.Cursor, limit)
+	if err != nil || len(results) == 0 {
+		return nil, nil, err
+	}
+	txHistories, err := h.l2SentMsgsToTxHistoryInfos(ctx, results)
+	if err != nil {
+		return nil, nil, err
+	}
+	h.finishTxHistories(ctx, txHistories)
+	if len(results) < limit {
+		return txHistories, nil, nil
+	}
+	nextCursor := results[len(results)-1].Nonce
+	return txHistories, &nextCursor, nil
+}
+
+// GetClaimableTxsByAddressWithKeyset get a page of the claimable txs under given address ordered by block number, tx hash
+// and msg hash desc. The returned opaque cursor is used to fetch the next page and is empty on the last page, the pages
+// neither skip nor repeat txs when new txs are sent meanwhile.
+func (h *HistoryLogic) GetClaimableTxsByAddressWithKeyset(ctx context.Context, address common.Address, pagination types.KeysetPagination) ([]*types.TxHistoryInfo, string, error) {
+	return instrumentPage(h.metrics, "GetClaimableTxsByAddressWithKeyset", func() ([]*types.TxHistoryInfo, string, error) {
+		return h.getClaimableTxsByAddressWithKeyset(ctx, address, pagination)
+	})
+}
+
+// getClaimableTxsByAddressWithKeyset implements GetClaimableTxsByAddressWithKeyset
+func (h *HistoryLogic) getClaimableTxsByAddressWithKeyset(ctx context.Context, address common.Address, pagination types.KeysetPagination) ([]*types.TxHistoryInfo, string, error) {
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+	cursor, err := decodeCursor(pagination.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	limit := getLimit(pagination.PageSize)
+	results, err := orm.NewL2SentMsg(h.db).GetClaimableL2SentMsgByAddressWithKeyset(ctx, address.Hex(), cursor, limit)
+	if err != nil || len(results) == 0 {
+		return nil, "", err
+	}
+	txHistories, err := h.l2SentMsgsToTxHistoryInfos(ctx, results)
+	if err != nil {
+		return nil, "", err
+	}
+	h.finishTxHistories(ctx, txHistories)
+	last := results[len(results)-1]
+	return txHistories, nextCursor(len(results), limit, &orm.MsgCursor{Height: last.Height, TxHash: last.TxHash, MsgHash: last.MsgHash}), nil
+}
+
+// l2SentMsgsToTxHistoryInfos converts the l2 sent msgs into tx history infos with the claim infos
+func (h *HistoryLogic) l2SentMsgsToTxHistoryInfos(ctx context.Context, l2sentMsgs []*orm.L2SentMsg) ([]*types.TxHistoryInfo, error) {
+	var txHistories []*types.TxHistoryInfo
+	var msgHashList []string
+	for _, l2sentMsg := range l2sentMsgs {
+		msgHashList = append(msgHashList, l2sentMsg.MsgHash)
+	}
+	crossMsgs, err := orm.NewCrossMsg(h.db).GetL2CrossMsgByMsgHashList(ctx, msgHashList)
+	// crossMsgs can be empty, because they can be emitted by user directly call contract
+	if err != nil {
+		return txHistories, err
+	}
+	crossMsgMap := make(map[string]*orm.CrossMsg)
+	for _, crossMsg := range crossMsgs {
+		crossMsgMap[crossMsg.MsgHash] = crossMsg
+	}
+	for _, l2sentMsg := range l2sentMsgs {
+		txInfo := &types.TxHistoryInfo{
+			Hash:        l2sentMsg.TxHash,
+			MsgHash:     l2sentMsg.MsgHash,
+			IsL1:        false,
+			BlockNumber: l2sentMsg.Height,
+			TokenType:   types.TokenTypeETH,
+			FinalizeTx:  &types.Finalized{},
+		}
+		if h.includeIndexedAt {
+			txInfo.IndexedAt = l2sentMsg.CreatedAt
+		}
+		if crossMsg, exist := crossMsgMap[l2sentMsg.MsgHash]; exist {
+			txInfo.Amount = crossMsg.Amount
+			txInfo.GasFee = crossMsg.GasFee
+			txInfo.BridgeFee = crossMsg.BridgeFee
+			txInfo.Fees = sendTxFees(crossMsg, txInfo.Hash, false)
+			txInfo.To = crossMsg.Target
+			txInfo.BlockTimestamp = crossMsg.Timestamp
+			txInfo.CreatedAt = crossMsg.CreatedAt
+			txInfo.L1Token = crossMsg.Layer1Token
+			txInfo.L2Token = crossMsg.Layer2Token
+			txInfo.TokenType = tokenType(crossMsg)
+		}
+		txHistories = append(txHistories, txInfo)
+	}
+	h.updateRelativeTimes(txHistories, time.Now())
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		return updateL2TxClaimInfoFromMsgs(gctx, txHistories, l2sentMsgs, h.db, h.claimLookups)
+	})
+	g.Go(func() error {
+		return updateTokenMetadata(gctx, txHistories, h.db)
+	})
+	if err = g.Wait(); err != nil {
+		return nil, err
+	}
+	txHistories = h.claimVerifier.dropExecutedClaimables(ctx, txHistories)
+	if err = updateOperationTypes(ctx, txHistories, h.db, h.depositCreditFinality); err != nil {
+		return nil, err
+	}
+	return txHistories, nil
+}
+
+// GetClaimableTxsByAddressSorted get all claimable txs under given address in the given order
+func (h *HistoryLogic) GetClaimableTxsByAddressSorted(ctx context.Context, address common.Address, sortBy types.ClaimableSortBy) ([]*types.TxHistoryInfo, uint64, error) {
+	return instrumentPage(h.metrics, "GetClaimableTxsByAddressSorted", func() ([]*types.TxHistoryInfo, uint64, error) {
+		return h.getClaimableTxsByAddressSorted(ctx, address, sortBy)
+	})
+}
+
+// getClaimableTxsByAddressSorted implements GetClaimableTxsByAddressSorted
+func (h *HistoryLogic) getClaimableTxsByAddressSorted(ctx context.Context, address common.Address, sortBy types.ClaimableSortBy) ([]*types.TxHistoryInfo, uint64, error) {
+	txHistories, total, err := h.GetClaimableTxsByAddress(ctx, address, nil, types.TokenTypeAll, types.SortOrderDesc)
+	if err != nil {
+		return nil, 0, err
+	}
+	sortClaimableTxs(txHistories, sortBy)
+	return txHistories, total, nil
+}
+
+// GetClaimableExpiringWithin get the claimable txs under given address whose claim expires within the window from now,
+// nothing is returned if claims never expire.
+func (h *HistoryLogic) GetClaimableExpiringWithin(ctx context.Context, address common.Address, window time.Duration) ([]*types.TxHistoryInfo, error) {
+	return instrument(h.metrics, "GetClaimableExpiringWithin", func() ([]*types.TxHistoryInfo, error) {
+		return h.getClaimableExpiringWithin(ctx, address, window)
+	})
+}
+
+// getClaimableExpiringWithin implements GetClaimableExpiringWithin
+func (h *HistoryLogic) getClaimableExpiringWithin(ctx context.Context, address common.Address, window time.Duration) ([]*types.TxHistoryInfo, error) {
+	txHistories, _, err := h.GetClaimableTxsByAddress(ctx, address, nil, types.TokenTypeAll, types.SortOrderDesc)
+	if err != nil {
+		return nil, err
+	}
+	return filterClaimsExpiringWithin(txHistories, time.Now(), window), nil
+}
+
+// GetClaimableTxsByAddressSplit get all claimable txs under given address split by the proof readiness
+func (h *HistoryLogic) GetClaimableTxsByAddressSplit(ctx context.Context, address common.Address) (*types.ClaimableSplitResultData, error) {
+	return instrument(h.metrics, "GetClaimableTxsByAddressSplit", func() (*types.ClaimableSplitResultData, error) {
+		return h.getClaimableTxsByAddressSplit(ctx, address)
+	})
+}
+
+// getClaimableTxsByAddressSplit implements GetClaimableTxsByAddressSplit
+func (h *HistoryLogic) getClaimableTxsByAddressSplit(ctx context.Context, address common.Address) (*types.ClaimableSplitResultData, error) {
+	txHistories, total, err := h.GetClaimableTxsByAddress(ctx, address, nil, types.TokenTypeAll, types.SortOrderDesc)
+	if err != nil {
+		return nil, err
+	}
+	ready, proofPending := splitByProofReadiness(txHistories)
+	return &types.ClaimableSplitResultData{Ready: ready, ProofPending: proofPending, Total: total}, nil
+}
+
+// GetClaimableTxsWithGasEstimateByAddress get all claimable txs under given address, together with the total
+// estimated gas of claiming all of them in a batch
+func (h *HistoryLogic) GetClaimableTxsWithGasEstimateByAddress(ctx context.Context, address common.Address) (*types.ClaimableResultData, error) {
+	return instrument(h.metrics, "GetClaimableTxsWithGasEstimateByAddress", func() (*types.ClaimableResultData, error) {
+		return h.getClaimableTxsWithGasEstimateByAddress(ctx, address)
+	})
+}
+
+// getClaimableTxsWithGasEstimateByAddress implements GetClaimableTxsWithGasEstimateByAddress
+func (h *HistoryLogic) getClaimableTxsWithGasEstimateByAddress(ctx context.Context, address common.Address) (*types.ClaimableResultData, error) {
+	txHistories, total, err := h.GetClaimableTxsByAddress(ctx, address, nil, types.TokenTypeAll, types.SortOrderDesc)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ClaimableResultData{
+		Result:            txHistories,
+		Total:             total,
+		TotalEstimatedGas: sumEstimatedGas(txHistories),
+	}, nil
+}
+
+// GetTxsByHashes get tx infos under given tx hashes bridging tokens of tokenType, TokenTypeAll doesn't filter.
+// The txs are sorted by block timestamp then tx hash in the order, latest first by default. The unfiltered results
+// are served from the cache by hash when it's configured.
+func (h *HistoryLogic) GetTxsByHashes(ctx context.Context, hashes []string, tokenType types.TokenType, order types.SortOrder) ([]*types.TxHistoryInfo, error) {
+	return instrument(h.metrics, "GetTxsByHashes", func() ([]*types.TxHistoryInfo, error) {
+		return h.getTxsByHashes(ctx, hashes, tokenType, order)
+	})
+}
+
+// getTxsByHashes implements GetTxsByHashes
+func (h *HistoryLogic) getTxsByHashes(ctx context.Context, hashes []string, tokenType types.TokenType, order types.SortOrder) ([]*types.TxHistoryInfo, error) {
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	assets, err := tokenTypeAssets(tokenType)
+	if err != nil {
+		return nil, err
+	}
+	ormOrder, err := sortOrder(order)
+	if err != nil {
+		return nil, err
+	}
+	hashes, err = normalizeTxHashes(hashes)
+	if err != nil || len(hashes) == 0 {
+		return nil, err
+	}
+	// the unfiltered tx histories are cached by hash, the cached and the queried ones are merged in the order
+	cacheable := h.cache != nil && tokenType == types.TokenTypeAll
+	var cachedTxHistories []*types.TxHistoryInfo
+	if cacheable {
+		cachedTxHistories, hashes = h.getCachedTxsByHashes(ctx, hashes)
+		if len(hashes) == 0 {
+			sortTxHistories(cachedTxHistories, order)
+			return cachedTxHistories, nil
+		}
+	}
+	CrossMsgOrm := h.crossMsgOrm()
+	results, err := CrossMsgOrm.GetCrossMsgsByHashes(ctx, hashes, assets, ormOrder)
+	if err != nil {
+		return nil, err
+	}
+	archived, err := h.getArchivedCrossMsgs(ctx, hashes, results, assets, ormOrder)
+	if err != nil {
+		return nil, err
+	}
+	results = append(results, archived...)
+
+	txHistories := h.crossMsgsToTxHistoryInfos(results)
+	if len(archived) != 0 {
+		sortTxHistories(txHistories, order)
+	}
+
+	if err := updateCrossTxHashesAndL2TxClaimInfo(ctx, txHistories, h.db, h.depositCreditFinality, h.claimLookups); err != nil {
+		return nil, err
+	}
+	h.finishTxHistories(ctx, txHistories)
+	if cacheable {
+		h.setCachedTxsByHashes(ctx, hashes, results, txHistories)
+		txHistories = append(cachedTxHistories, txHistories...)
+		sortTxHistories(txHistories, order)
+	}
+	return txHistories, nil
+}
+
+// normalizeTxHashes returns the distinct tx hashes in the canonical form stored in the db, lowercase and 0x prefixed,
+// in order. The invalid hashes are dropped, an error is returned only if all the hashes are invalid.
+func normalizeTxHashes(hashes []string) ([]string, error) {
+	normalized := make([]string, 0, len(hashes))
+	seen := make(map[string]struct{}, len(hashes))
+	for _, hash := range hashes {
+		hash = strings.ToLower(strings.TrimSpace(hash))
+		if !strings.HasPrefix(hash, "0x") {
+			hash = "0x" + hash
+		}
+		if len(hash) != 2+2*common.HashLength {
+			continue
+		}
+		if _, err := hex.DecodeString(hash[2:]); err != nil {
+			continue
+		}
+		if _, exists := seen[hash]; exists {
+			continue
+		}
+		seen[hash] = struct{}{}
+		normalized = append(normalized, hash)
+	}
+	if len(hashes) != 0 && len(normalized) == 0 {
+		return nil, fmt.Errorf("%w: none of the %d tx hashes is valid", ErrInvalidParameter, len(hashes))
+	}
+	return normalized, nil
+}
+
+// GetUnifiedHistory get the deposits and withdrawals of the given address merged in one feed ordered by block timestamp,
+// the pagination is applied on the merged set. Only the txs within txRange are returned, the zero txRange doesn't bound them.
+func (h *HistoryLogic) GetUnifiedHistory(ctx context.Context, address common.Address, txRange types.TxRange, pagination types.Pagination) ([]*types.TxHistoryInfo, uint64, error) {
+	return instrumentPage(h.metrics, "GetUnifiedHistory", func() ([]*types.TxHistoryInfo, uint64, error) {
+		return h.getUnifiedHistory(ctx, address, txRange, pagination)
+	})
+}
+
+// getUnifiedHistory implements GetUnifiedHistory
+func (h *HistoryLogic) getUnifiedHistory(ctx context.Context, address common.Address, txRange types.TxRange, pagination types.Pagination) ([]*types.TxHistoryInfo, uint64, error) {
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	crossMsgOrm := h.crossMsgOrm()
+	msgRange := txRangeToMsgRange(txRange)
+	total, err := crossMsgOrm.GetTotalUnifiedMsgCountByAddress(ctx, address.Hex(), msgRange)
+	if err != nil || total == 0 {
+		return nil, 0, err
+	}
+
+	offset, limit := getOffsetLimit(pagination)
+	results, err := crossMsgOrm.GetUnifiedMsgsByAddressWithOffset(ctx, address.Hex(), msgRange, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	txHistories := h.crossMsgsToTxHistoryInfos(results)
+
+	if err := updateCrossTxHashesAndL2TxClaimInfo(ctx, txHistories, h.db, h.depositCreditFinality, h.claimLookups); err != nil {
+		return nil, 0, err
+	}
+	h.finishTxHistories(ctx, txHistories)
+	return txHistories, total, nil
+}
+
+// GetTxsByAddress get the full deposit and withdrawal history of the given address, the page of pageSize txs ordered by
+// block timestamp desc across both layers, the total counts the txs on both layers. The pages are served from the cache
+// when it's configured.
+func (h *HistoryLogic) GetTxsByAddress(ctx context.Context, address common.Address, page, pageSize uint64) ([]*types.TxHistoryInfo, uint64, error) {
+	return instrumentPage(h.metrics, "GetTxsByAddress", func() ([]*types.TxHistoryInfo, uint64, error) {
+		return h.getTxsByAddress(ctx, address, page, pageSize)
+	})
+}
+
+// getTxsByAddress implements GetTxsByAddress
+func (h *HistoryLogic) getTxsByAddress(ctx context.Context, address common.Address, page, pageSize uint64) ([]*types.TxHistoryInfo, uint64, error) {
+	version := h.txsCacheVersion(ctx, address)
+	if version != "" {
+		var resultData types.ResultData
+		if h.getCached(ctx, txsCacheKey(address, version, page, pageSize), &resultData) {
+			h.refreshCachedTxHistories(resultData.Result)
+			return resultData.Result, resultData.Total, nil
+		}
+	}
+	txHistories, total, err := h.GetUnifiedHistory(ctx, address, types.TxRange{}, types.Pagination{Page: page, PageSize: pageSize})
+	if err != nil {
+		return nil, 0, err
+	}
+	if version != "" {
+		h.setCached(ctx, txsCacheKey(address, version, page, pageSize), &types.ResultData{Result: txHistories, Total: total})
+	}
+	return txHistories, total, nil
+}
+
+// GetTxsByAddressWithKeyset get a page of the deposit and withdrawal history of the given address ordered by block number,
+// tx hash and msg hash desc, the block numbers are the ones of the layer each tx is sent on. The returned opaque cursor is
+// used to fetch the next page and is empty on the last page, the pages neither skip nor repeat txs when new txs are sent
+// meanwhile.
+func (h *HistoryLogic) GetTxsByAddressWithKeyset(ctx context.Context, address common.Address, pagination types.KeysetPagination) ([]*types.TxHistoryInfo, string, error) {
+	return instrumentPage(h.metrics, "GetTxsByAddressWithKeyset", func() ([]*types.TxHistoryInfo, string, error) {
+		return h.getTxsByAddressWithKeyset(ctx, address, pagination)
+	})
+}
+
+// getTxsByAddressWithKeyset implements GetTxsByAddressWithKeyset
+func (h *HistoryLogic) getTxsByAddressWithKeyset(ctx context.Context, address common.Address, pagination types.KeysetPagination) ([]*types.TxHistoryInfo, string, error) {
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+	cursor, err := decodeCursor(pagination.Cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	limit := getLimit(pagination.PageSize)
+	results, err := h.crossMsgOrm().GetUnifiedMsgsByAddressWithKeyset(ctx, address.Hex(), cursor, limit)
+	if err != nil || len(results) == 0 {
+		return nil, "", err
+	}
+
+	txHistories := h.crossMsgsToTxHistoryInfos(results)
+
+	if err := updateCrossTxHashesAndL2TxClaimInfo(ctx, txHistories, h.db, h.depositCreditFinality, h.claimLookups); err != nil {
+		return nil, "", err
+	}
+	h.finishTxHistories(ctx, txHistories)
+	last := results[len(results)-1]
+	lastTxHash := last.Layer2Hash
+	if last.MsgType == int(orm.Layer1Msg) {
+		lastTxHash = last.Layer1Hash
+	}
+	return txHistories, nextCursor(len(results), limit, &orm.MsgCursor{Height: last.Height, TxHash: lastTxHash, MsgHash: last.MsgHash}), nil
+}
+
+// txRangeToMsgRange converts the tx range into the range of the orm queries, nil is returned if both bounds are open
+func txRangeToMsgRange(txRange types.TxRange) *orm.MsgRange {
+	if txRange.From == nil && txRange.To == nil {
+		return nil
+	}
+	return &orm.MsgRange{From: txRange.From, To: txRange.To, ByTimestamp: txRange.ByTimestamp}
+}
+
+// GetTxsBetween get the deposits and withdrawals sent by from to the recipient to, ordered by block timestamp
+func (h *HistoryLogic) GetTxsBetween(ctx context.Context, from, to common.Address, pagination types.Pagination) ([]*types.TxHistoryInfo, uint64, error) {
+	return instrumentPage(h.metrics, "GetTxsBetween", func() ([]*types.TxHistoryInfo, uint64, error) {
+		return h.getTxsBetween(ctx, from, to, pagination)
+	})
+}
+
+// getTxsBetween implements GetTxsBetween
+func (h *HistoryLogic) getTxsBetween(ctx context.Context, from, to common.Address, pagination types.Pagination) ([]*types.TxHistoryInfo, uint64, error) {
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	crossMsgOrm := h.crossMsgOrm()
+	total, err := crossMsgOrm.GetTotalMsgCountBetween(ctx, from.Hex(), to.Hex())
+	if err != nil || total == 0 {
+		return nil, 0, err
+	}
+
+	offset, limit := getOffsetLimit(pagination)
+	results, err := crossMsgOrm.GetMsgsBetweenWithOffset(ctx, from.Hex(), to.Hex(), offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	txHistories := h.crossMsgsToTxHistoryInfos(results)
+
+	if err := updateCrossTxHashesAndL2TxClaimInfo(ctx, txHistories, h.db, h.depositCreditFinality, h.claimLookups); err != nil {
+		return nil, 0, err
+	}
+	h.finishTxHistories(ctx, txHistories)
+	return txHistories, total, nil
+}
+
+// GetTxsGroupedByStatus get all the deposits and withdrawals of the given address partitioned by claim status,
+// see groupTxsByStatus for the status of the deposits.
+func (h *HistoryLogic) GetTxsGroupedByStatus(ctx context.Context, address common.Address) (map[types.ClaimStatus][]*types.TxHistoryInfo, error) {
+	return instrument(h.metrics, "GetTxsGroupedByStatus", func() (map[types.ClaimStatus][]*types.TxHistoryInfo, error) {
+		return h.getTxsGroupedByStatus(ctx, address)
+	})
+}
+
+// getTxsGroupedByStatus implements GetTxsGroupedByStatus
+func (h *HistoryLogic) getTxsGroupedByStatus(ctx context.Context, address common.Address) (map[types.ClaimStatus][]*types.TxHistoryInfo, error) {
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	results, err := h.crossMsgOrm().GetUnifiedMsgsByAddress(ctx, address.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	txHistories := h.crossMsgsToTxHistoryInfos(results)
+
+	if err := updateCrossTxHashesAndL2TxClaimInfo(ctx, txHistories, h.db, h.depositCreditFinality, h.claimLookups); err != nil {
+		return nil, err
+	}
+	h.finishTxHistories(ctx, txHistories)
+	return groupTxsByStatus(txHistories), nil
+}
+
+// groupTxsByStatus partitions the tx histories by claim status keeping their order. Deposits are not claimed by
+// users, they are grouped into ClaimStatusClaimed once relayed on layer2 and ClaimStatusPending otherwise.
+func groupTxsByStatus(txHistories []*types.TxHistoryInfo) map[types.ClaimStatus][]*types.TxHistoryInfo {
+	groups := make(map[types.ClaimStatus][]*types.TxHistoryInfo)
+	for _, txHistory := range txHistories {
+		status := txHistory.ClaimStatus
+		if txHistory.IsL1 {
+			status = types.ClaimStatusPending
+			if isRelayed(txHistory) {
+				status = types.ClaimStatusClaimed
+			}
+		}
+		groups[status] = append(groups[status], txHistory)
+	}
+	return groups
+}
+
+// GetTxsByRelayer get the withdrawals claimed on layer1 by the given relayer
+func (h *HistoryLogic) GetTxsByRelayer(ctx context.Context, relayer common.Address, pagination types.Pagination) ([]*types.TxHistoryInfo, uint64, error) {
+	return instrumentPage(h.metrics, "GetTxsByRelayer", func() ([]*types.TxHistoryInfo, uint64, error) {
+		return h.getTxsByRelayer(ctx, relayer, pagination)
+	})
+}
+
+// getTxsByRelayer implements GetTxsByRelayer
+func (h *HistoryLogic) getTxsByRelayer(ctx context.Context, relayer common.Address, pagination types.Pagination) ([]*types.TxHistoryInfo, uint64, error) {
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	crossMsgOrm := orm.NewCrossMsg(h.db)
+	total, err := crossMsgOrm.GetTotalRelayedMsgCountByRelayer(ctx, relayer.Hex())
+	if err != nil || total == 0 {
+		return nil, 0, err
+	}
+
+	offset, limit := getOffsetLimit(pagination)
+	results, err := crossMsgOrm.GetRelayedMsgsByRelayerWithOffset(ctx, relayer.Hex(), offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	txHistories := h.crossMsgsToTxHistoryInfos(results)
+
+	if err := updateCrossTxHashesAndL2TxClaimInfo(ctx, txHistories, h.db, h.depositCreditFinality, h.claimLookups); err != nil {
+		return nil, 0, err
+	}
+	h.finishTxHistories(ctx, txHistories)
+	return txHistories, total, nil
+}
+
+// GetTxsByBatchIndex get the withdrawals sent in the layer2 blocks of the rollup batch of the index in the order they
+// are sent, ErrNotFound is returned if the batch is not committed
+func (h *HistoryLogic) GetTxsByBatchIndex(ctx context.Context, batchIndex uint64, pagination types.Pagination) ([]*types.TxHistoryInfo, uint64, error) {
+	return instrumentPage(h.metrics, "GetTxsByBatchIndex", func() ([]*types.TxHistoryInfo, uint64, error) {
+		return h.getTxsByBatchIndex(ctx, batchIndex, pagination)
+	})
+}
+
+// getTxsByBatchIndex implements GetTxsByBatchIndex
+func (h *HistoryLogic) getTxsByBatchIndex(ctx context.Context, batchIndex uint64, pagination types.Pagination) ([]*types.TxHistoryInfo, uint64, error) {
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	batch, err := orm.NewRollupBatch(h.db).GetRollupBatchByIndex(ctx, batchIndex)
+	if err != nil {
+		return nil, 0, err
+	}
+	crossMsgOrm := orm.NewCrossMsg(h.db)
+	total, err := crossMsgOrm.GetTotalWithdrawalCountInBlocks(ctx, batch.StartBlockNumber, batch.EndBlockNumber)
+	if err != nil || total == 0 {
+		return nil, 0, err
+	}
+
+	offset, limit := getOffsetLimit(pagination)
+	results, err := crossMsgOrm.GetWithdrawalsInBlocksWithOffset(ctx, batch.StartBlockNumber, batch.EndBlockNumber, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	txHistories := h.crossMsgsToTxHistoryInfos(results)
+
+	if err := updateCrossTxHashesAndL2TxClaimInfo(ctx, txHistories, h.db, h.depositCreditFinality, h.claimLookups); err != nil {
+		return nil, 0, err
+	}
+	h.finishTxHistories(ctx, txHistories)
+	return txHistories, total, nil
+}
+
+// GetTxsByTimeRange get the deposits and withdrawals of all addresses of the direction whose block timestamp is from
+// startTime to endTime in unix seconds inclusive, ordered by block timestamp
+func (h *HistoryLogic) GetTxsByTimeRange(ctx context.Context, startTime, endTime uint64, direction types.TxDirection, order types.SortOrder, pagination types.Pagination) ([]*types.TxHistoryInfo, uint64, error) {
+	return instrumentPage(h.metrics, "GetTxsByTimeRange", func() ([]*types.TxHistoryInfo, uint64, error) {
+		return h.getTxsByTimeRange(ctx, startTime, endTime, direction, order, pagination)
+	})
+}
+
+// getTxsByTimeRange implements GetTxsByTimeRange
+func (h *HistoryLogic) getTxsByTimeRange(ctx context.Context, startTime, endTime uint64, direction types.TxDirection, order types.SortOrder, pagination types.Pagination) ([]*types.TxHistoryInfo, uint64, error) {
+	if startTime > endTime {
+		return nil, 0, fmt.Errorf("%w: the start time %d is after the end time %d", ErrInvalidParameter, startTime, endTime)
+	}
+	msgType, err := directionMsgType(direction)
+	if err != nil {
+		return nil, 0, err
+	}
+	ormOrder, err := sortOrder(order)
+	if err != nil {
+		return nil, 0, err
+	}
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err = ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	crossMsgOrm := h.crossMsgOrm()
+	msgRange := &orm.MsgRange{From: &startTime, To: &endTime, ByTimestamp: true}
+	total, err := crossMsgOrm.GetTotalMsgCountInRange(ctx, msgType, msgRange)
+	if err != nil || total == 0 {
+		return nil, 0, err
+	}
+
+	offset, limit := getOffsetLimit(pagination)
+	results, err := crossMsgOrm.GetMsgsInRangeWithOffset(ctx, msgType, msgRange, ormOrder, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	txHistories := h.crossMsgsToTxHistoryInfos(results)
+
+	if err := updateCrossTxHashesAndL2TxClaimInfo(ctx, txHistories, h.db, h.depositCreditFinality, h.claimLookups); err != nil {
+		return nil, 0, err
+	}
+	h.finishTxHistories(ctx, txHistories)
+	return txHistories, total, nil
+}
+
+// GetClaimStatusAtBlock get the claim status of the layer2 withdrawal as it was at the given layer1 block
+func (h *HistoryLogic) GetClaimStatusAtBlock(ctx context.Context, msgHash string, atBlock uint64) (types.ClaimStatus, error) {
+	return instrument(h.metrics, "GetClaimStatusAtBlock", func() (types.ClaimStatus, error) {
+		return h.getClaimStatusAtBlock(ctx, msgHash, atBlock)
+	})
+}
+
+// getClaimStatusAtBlock implements GetClaimStatusAtBlock
+func (h *HistoryLogic) getClaimStatusAtBlock(ctx context.Context, msgHash string, atBlock uint64) (types.ClaimStatus, error) {
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return types.ClaimStatusUnknown, err
+	}
+	l2sentMsgs, err := orm.NewL2SentMsg(h.db).GetL2SentMsgsByHashes(ctx, []string{msgHash})
+	if err != nil || len(l2sentMsgs) == 0 {
+		return types.ClaimStatusUnknown, err
+	}
+	l2sentMsg := l2sentMsgs[0]
+
+	var batch *orm.RollupBatch
+	batches, err := orm.NewRollupBatch(h.db).GetRollupBatchesByIndexes(ctx, []uint64{l2sentMsg.BatchIndex})
+	if err != nil {
+		return types.ClaimStatusUnknown, err
+	}
+	if len(batches) > 0 {
+		batch = batches[0]
+	}
+
+	relayedMsg, err := orm.NewRelayedMsg(h.db).GetRelayedMsgByHash(ctx, msgHash)
+	if err != nil {
+		return types.ClaimStatusUnknown, err
+	}
+	return claimStatusAtBlock(l2sentMsg, batch, relayedMsg, atBlock), nil
 }
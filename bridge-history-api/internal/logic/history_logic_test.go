@@ -0,0 +1,968 @@
+package logic
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/config"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestIsProofPruned(t *testing.T) {
+	finalizedBatch := &orm.RollupBatch{BatchIndex: 1, FinalizeHeight: 100}
+	latestBatchIndex := uint64(proofActiveWindow + 2)
+
+	// pruned: finalized, empty proof and out of the active window
+	assert.True(t, isProofPruned(&orm.L2SentMsg{BatchIndex: 1}, finalizedBatch, latestBatchIndex))
+
+	// available: the proof is still stored
+	assert.False(t, isProofPruned(&orm.L2SentMsg{BatchIndex: 1, MsgProof: "abcd"}, finalizedBatch, latestBatchIndex))
+
+	// empty proof within the active window is still being generated, not pruned
+	assert.False(t, isProofPruned(&orm.L2SentMsg{BatchIndex: 1}, finalizedBatch, 2))
+
+	// batch not finalized yet
+	assert.False(t, isProofPruned(&orm.L2SentMsg{BatchIndex: 1}, &orm.RollupBatch{BatchIndex: 1}, latestBatchIndex))
+}
+
+func TestCrossMsgToTxHistoryInfoL1BlockHash(t *testing.T) {
+	deposit := &orm.CrossMsg{MsgHash: "hash1", Layer1Hash: "l1hash", BlockHash: "blockhash1", MsgType: int(orm.Layer1Msg)}
+	txHistory := crossMsgToTxHistoryInfo(deposit)
+	assert.True(t, txHistory.IsL1)
+	assert.Equal(t, "blockhash1", txHistory.L1BlockHash)
+
+	withdrawal := &orm.CrossMsg{MsgHash: "hash2", Layer2Hash: "l2hash", BlockHash: "blockhash2", MsgType: int(orm.Layer2Msg)}
+	txHistory = crossMsgToTxHistoryInfo(withdrawal)
+	assert.False(t, txHistory.IsL1)
+	assert.Empty(t, txHistory.L1BlockHash)
+}
+
+func TestCrossMsgToTxHistoryInfoOriginTxNonce(t *testing.T) {
+	nonce := uint64(42)
+	deposit := &orm.CrossMsg{MsgHash: "hash1", Layer1Hash: "l1hash", OriginTxNonce: &nonce, MsgType: int(orm.Layer1Msg)}
+	txHistory := crossMsgToTxHistoryInfo(deposit)
+	assert.NotNil(t, txHistory.OriginTxNonce)
+	assert.Equal(t, uint64(42), *txHistory.OriginTxNonce)
+
+	// deposits indexed before the nonce is fetched
+	deposit = &orm.CrossMsg{MsgHash: "hash2", Layer1Hash: "l1hash2", MsgType: int(orm.Layer1Msg)}
+	assert.Nil(t, crossMsgToTxHistoryInfo(deposit).OriginTxNonce)
+}
+
+func TestCrossMsgsToTxHistoryInfosIndexedAt(t *testing.T) {
+	blockTime := time.Unix(1000, 0)
+	indexedTime := blockTime.Add(time.Minute)
+	crossMsgs := []*orm.CrossMsg{{MsgHash: "hash1", MsgType: int(orm.Layer1Msg), Timestamp: &blockTime, CreatedAt: &indexedTime}}
+
+	// requested
+	txHistories := (&HistoryLogic{includeIndexedAt: true}).crossMsgsToTxHistoryInfos(crossMsgs)
+	assert.Len(t, txHistories, 1)
+	assert.Equal(t, &indexedTime, txHistories[0].IndexedAt)
+	assert.Equal(t, &blockTime, txHistories[0].BlockTimestamp)
+
+	// not requested
+	txHistories = (&HistoryLogic{}).crossMsgsToTxHistoryInfos(crossMsgs)
+	assert.Nil(t, txHistories[0].IndexedAt)
+}
+
+func TestFaucetDepositsExcludedByQueries(t *testing.T) {
+	faucet := "0x0000000000000000000000000000000000000Fa0"
+	db, counter := newCountingDB(t, nil)
+	logic := NewHistoryLogic(&config.Config{Server: &config.ServerConfig{FaucetAddrs: []string{strings.ToLower(faucet)}}}, db, nil, nil)
+	// the senders are checksummed by the parser
+	faucetSender := common.HexToAddress(faucet).Hex()
+	assert.Equal(t, []string{faucetSender}, logic.faucets)
+
+	// the faucet deposits are skipped by the count and the page queries, not after the pagination
+	_, _, err := logic.GetTxsByTimeRange(context.Background(), 100, 200, types.TxDirectionAll, types.SortOrderDesc, types.Pagination{Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.Contains(t, counter.vars["(*CrossMsg).GetTotalMsgCountInRange"], faucetSender)
+	_, err = logic.GetTxsByHashes(context.Background(), []string{common.HexToHash("0x01").Hex()}, types.TokenTypeAll, types.SortOrderDesc)
+	assert.NoError(t, err)
+	assert.Contains(t, counter.vars["(*CrossMsg).GetCrossMsgsByHashes"], faucetSender)
+
+	// nothing is excluded without faucets
+	db, counter = newCountingDB(t, nil)
+	_, _, err = (&HistoryLogic{db: db}).GetTxsByTimeRange(context.Background(), 100, 200, types.TxDirectionAll, types.SortOrderDesc, types.Pagination{Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.NotContains(t, counter.vars["(*CrossMsg).GetTotalMsgCountInRange"], faucetSender)
+}
+
+func TestFillL2TxClaimInfosWithoutL2SentMsg(t *testing.T) {
+	l2MsgMap := map[string]*orm.L2SentMsg{
+		"indexed": {MsgHash: "indexed", Sender: "0x0000000000000000000000000000000000000021", Target: "0x0000000000000000000000000000000000000022",
+			Value: "1", Height: 15, Nonce: 3, BatchIndex: 2, MsgData: "0x", MsgProof: "01"},
+	}
+	batchMap := map[uint64]*orm.RollupBatch{2: {BatchIndex: 2, BatchHash: "batchhash", StartBlockNumber: 10, EndBlockNumber: 20, FinalizeHeight: 100}}
+	txHistories := []*types.TxHistoryInfo{
+		{MsgHash: "not-indexed"},
+		{MsgHash: "indexed"},
+		{MsgHash: "deposit", IsL1: true},
+	}
+	getRebatch := func(height uint64) (*orm.RollupBatch, error) {
+		t.Fatalf("unexpected rebatch lookup of block %d", height)
+		return nil, nil
+	}
+	assert.NotPanics(t, func() {
+		assert.NoError(t, fillL2TxClaimInfos(txHistories, l2MsgMap, batchMap, nil, 2, getRebatch))
+	})
+	assert.Nil(t, txHistories[0].ClaimInfo)
+	assert.Nil(t, txHistories[0].GlobalWithdrawalIndex)
+	assert.Equal(t, types.ClaimStatusUnknown, txHistories[0].ClaimStatus)
+	assert.NotNil(t, txHistories[1].ClaimInfo)
+	assert.Equal(t, "batchhash", txHistories[1].ClaimInfo.BatchHash)
+	assert.Equal(t, types.ClaimStatusClaimable, txHistories[1].ClaimStatus)
+	assert.Nil(t, txHistories[2].ClaimInfo)
+}
+
+func TestFillL2TxClaimInfosGasEstimateFailure(t *testing.T) {
+	// the malformed proof fails the gas estimate but not the claim key computed without the proof
+	l2MsgMap := map[string]*orm.L2SentMsg{
+		"malformed": {MsgHash: "malformed", Height: 15, Nonce: 1, Value: "1", BatchIndex: 2, MsgData: "0x", MsgProof: "zz"},
+		"reverted":  {MsgHash: "reverted", Height: 16, Nonce: 2, Value: "1", BatchIndex: 2, MsgData: "0x", MsgProof: "zz"},
+	}
+	// the batch 2 is committed again without the blocks from 16 on, they are rebatched in the batch 3
+	batchMap := map[uint64]*orm.RollupBatch{2: {BatchIndex: 2, BatchHash: "batchhash", StartBlockNumber: 10, EndBlockNumber: 15, FinalizeHeight: 100}}
+	revertedBatchMap := map[uint64][]*orm.RollupBatch{2: {{BatchIndex: 2, BatchHash: "revertedhash", StartBlockNumber: 16, EndBlockNumber: 20}}}
+	rebatch := &orm.RollupBatch{BatchIndex: 3, BatchHash: "rebatchhash", StartBlockNumber: 16, EndBlockNumber: 25}
+	txHistories := []*types.TxHistoryInfo{{MsgHash: "malformed"}, {MsgHash: "reverted"}}
+	assert.NoError(t, fillL2TxClaimInfos(txHistories, l2MsgMap, batchMap, revertedBatchMap, 2, func(height uint64) (*orm.RollupBatch, error) {
+		return rebatch, nil
+	}))
+
+	_, err := estimateClaimGas(txHistories[0].ClaimInfo)
+	assert.Error(t, err)
+	assert.Zero(t, txHistories[0].ClaimInfo.EstimatedGas)
+	assert.NotEmpty(t, txHistories[0].ClaimInfo.ClaimKey)
+	assert.False(t, txHistories[0].BatchReverted)
+
+	// the batch info of the reverted batch isn't served
+	assert.True(t, txHistories[1].BatchReverted)
+	assert.Equal(t, uint64(3), *txHistories[1].RebatchedIndex)
+	assert.Nil(t, txHistories[1].ClaimInfo)
+	assert.Equal(t, types.ClaimStatusRebatchPending, txHistories[1].ClaimStatus)
+}
+
+func TestFillL2TxClaimInfosClaimable(t *testing.T) {
+	l2MsgMap := map[string]*orm.L2SentMsg{
+		"committed": {MsgHash: "committed", Height: 15, BatchIndex: 2, MsgData: "0x"},
+		"finalized": {MsgHash: "finalized", Height: 25, BatchIndex: 3, MsgData: "0x", MsgProof: "01"},
+		"proving":   {MsgHash: "proving", Height: 26, BatchIndex: 3, MsgData: "0x"},
+	}
+	batchMap := map[uint64]*orm.RollupBatch{
+		2: {BatchIndex: 2, BatchHash: "committedbatch", StartBlockNumber: 10, EndBlockNumber: 20},
+		3: {BatchIndex: 3, BatchHash: "finalizedbatch", StartBlockNumber: 21, EndBlockNumber: 30, FinalizeHeight: 100},
+	}
+	txHistories := []*types.TxHistoryInfo{{MsgHash: "committed"}, {MsgHash: "finalized"}, {MsgHash: "proving"}}
+	assert.NoError(t, fillL2TxClaimInfos(txHistories, l2MsgMap, batchMap, nil, 3, nil))
+
+	// committed but not finalized: the batch is returned, not claimable yet
+	assert.NotNil(t, txHistories[0].ClaimInfo)
+	assert.Equal(t, "committedbatch", txHistories[0].ClaimInfo.BatchHash)
+	assert.Equal(t, "2", txHistories[0].ClaimInfo.BatchIndex)
+	assert.False(t, txHistories[0].ClaimInfo.Claimable)
+	assert.Equal(t, types.ClaimStatusPending, txHistories[0].ClaimStatus)
+
+	// finalized with the proof
+	assert.True(t, txHistories[1].ClaimInfo.Claimable)
+	assert.Equal(t, types.ClaimStatusClaimable, txHistories[1].ClaimStatus)
+
+	// finalized but the proof is not generated yet
+	assert.Equal(t, "finalizedbatch", txHistories[2].ClaimInfo.BatchHash)
+	assert.False(t, txHistories[2].ClaimInfo.Claimable)
+}
+
+func TestGlobalWithdrawalIndex(t *testing.T) {
+	// the messages of different users in the order they are sent on layer2
+	l2sentMsgs := []*orm.L2SentMsg{
+		{MsgHash: "hash1", Sender: "sender1", Nonce: 0},
+		{MsgHash: "hash2", Sender: "sender2", Nonce: 1},
+		{MsgHash: "hash3", Sender: "sender1", Nonce: 2},
+	}
+	for i, l2sentMsg := range l2sentMsgs {
+		index := globalWithdrawalIndex(l2sentMsg)
+		assert.NotNil(t, index)
+		assert.Equal(t, uint64(i), *index)
+	}
+
+	// the index is not shared with the message
+	index := globalWithdrawalIndex(l2sentMsgs[0])
+	*index = 100
+	assert.Equal(t, uint64(0), l2sentMsgs[0].Nonce)
+
+	assert.Nil(t, globalWithdrawalIndex(nil))
+}
+
+func TestGetOffsetLimit(t *testing.T) {
+	tests := []struct {
+		pagination    types.Pagination
+		offset, limit int
+	}{
+		{types.Pagination{}, 0, defaultPageSize},
+		{types.Pagination{Page: 0, PageSize: 10}, 0, 10},
+		{types.Pagination{Page: 1, PageSize: 10}, 0, 10},
+		{types.Pagination{Page: 3, PageSize: 10}, 20, 10},
+		{types.Pagination{Page: 2, PageSize: 1000}, maxPageSize, maxPageSize},
+	}
+	for _, tt := range tests {
+		offset, limit := getOffsetLimit(tt.pagination)
+		assert.Equal(t, tt.offset, offset, "pagination %+v", tt.pagination)
+		assert.Equal(t, tt.limit, limit, "pagination %+v", tt.pagination)
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Unix(100000000, 0)
+	ago := func(d time.Duration) *time.Time {
+		ts := now.Add(-d)
+		return &ts
+	}
+	tests := []struct {
+		blockTimestamp *time.Time
+		expected       string
+	}{
+		{nil, ""},
+		{ago(30 * time.Second), "just now"},
+		{ago(-time.Minute), "just now"},
+		{ago(time.Minute), "1 minute ago"},
+		{ago(59 * time.Minute), "59 minutes ago"},
+		{ago(2*time.Hour + 30*time.Minute), "2 hours ago"},
+		{ago(24 * time.Hour), "1 day ago"},
+		{ago(45 * 24 * time.Hour), "1 month ago"},
+		{ago(3 * 365 * 24 * time.Hour), "3 years ago"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, relativeTime(tt.blockTimestamp, now))
+	}
+
+	txHistories := []*types.TxHistoryInfo{{BlockTimestamp: ago(2 * time.Hour)}}
+	(&HistoryLogic{}).updateRelativeTimes(txHistories, now)
+	assert.Empty(t, txHistories[0].RelativeTime)
+	(&HistoryLogic{includeRelativeTime: true}).updateRelativeTimes(txHistories, now)
+	assert.Equal(t, "2 hours ago", txHistories[0].RelativeTime)
+}
+
+func TestSumEstimatedGas(t *testing.T) {
+	newClaimInfo := func(nonce string, proof string) *types.UserClaimInfo {
+		return &types.UserClaimInfo{
+			From:      "0x0000000000000000000000000000000000000001",
+			To:        "0x0000000000000000000000000000000000000002",
+			Value:     "1000",
+			Nonce:     nonce,
+			BatchHash: "0x01",
+			Message:   "0x1234",
+			Proof:     proof,
+		}
+	}
+
+	proofNode := "0000000000000000000000000000000000000000000000000000000000000001"
+	claimInfos := []*types.UserClaimInfo{
+		newClaimInfo("1", "0x"),
+		newClaimInfo("2", "0x"+proofNode),
+		newClaimInfo("3", "0x"+proofNode+proofNode),
+	}
+
+	var txHistories []*types.TxHistoryInfo
+	var expected uint64
+	for _, claimInfo := range claimInfos {
+		estimatedGas, err := estimateClaimGas(claimInfo)
+		assert.NoError(t, err)
+		assert.Greater(t, estimatedGas, uint64(claimTxIntrinsicGas+claimExecutionGas))
+		claimInfo.EstimatedGas = estimatedGas
+		expected += estimatedGas
+		txHistories = append(txHistories, &types.TxHistoryInfo{ClaimInfo: claimInfo})
+	}
+	// longer proofs cost more gas
+	assert.Greater(t, claimInfos[2].EstimatedGas, claimInfos[1].EstimatedGas)
+	assert.Greater(t, claimInfos[1].EstimatedGas, claimInfos[0].EstimatedGas)
+
+	// txs without claim info are skipped
+	txHistories = append(txHistories, &types.TxHistoryInfo{})
+	assert.Equal(t, expected, sumEstimatedGas(txHistories))
+
+	_, err := estimateClaimGas(newClaimInfo("invalid", "0x"))
+	assert.Error(t, err)
+}
+
+func TestClaimStatusAtBlock(t *testing.T) {
+	l2sentMsg := &orm.L2SentMsg{MsgHash: "hash1", BatchIndex: 1, MsgProof: "abcd"}
+	batch := &orm.RollupBatch{BatchIndex: 1, FinalizeHeight: 100}
+	relayedMsg := &orm.RelayedMsg{MsgHash: "hash1", Height: 200, Layer1Hash: "l1hash"}
+
+	assert.Equal(t, types.ClaimStatusUnknown, claimStatusAtBlock(nil, batch, relayedMsg, 300))
+
+	// before the batch finalization
+	assert.Equal(t, types.ClaimStatusPending, claimStatusAtBlock(l2sentMsg, batch, relayedMsg, 99))
+	// finalized but not relayed yet
+	assert.Equal(t, types.ClaimStatusClaimable, claimStatusAtBlock(l2sentMsg, batch, relayedMsg, 150))
+	// relayed
+	assert.Equal(t, types.ClaimStatusClaimed, claimStatusAtBlock(l2sentMsg, batch, relayedMsg, 200))
+
+	// batch not committed, or committed but not finalized
+	assert.Equal(t, types.ClaimStatusPending, claimStatusAtBlock(l2sentMsg, nil, nil, 150))
+	assert.Equal(t, types.ClaimStatusPending, claimStatusAtBlock(l2sentMsg, &orm.RollupBatch{BatchIndex: 1}, nil, 150))
+
+	// finalized but the proof isn't generated yet, neither the status nor the claim info is claimable
+	unproven := &orm.L2SentMsg{MsgHash: "hash1", BatchIndex: 1}
+	assert.Equal(t, types.ClaimStatusPending, claimStatusAtBlock(unproven, batch, nil, 150))
+	assert.False(t, isClaimableAtBlock(unproven, batch, math.MaxUint64))
+	assert.True(t, isClaimableAtBlock(l2sentMsg, batch, math.MaxUint64))
+}
+
+func TestRevertedThenRebatchedMessage(t *testing.T) {
+	l2sentMsg := &orm.L2SentMsg{MsgHash: "hash1", Height: 15, BatchIndex: 2}
+	revertedBatches := []*orm.RollupBatch{{BatchIndex: 2, BatchHash: "reverted", StartBlockNumber: 11, EndBlockNumber: 20, Reverted: true}}
+	assert.True(t, inRevertedBatch(l2sentMsg, revertedBatches))
+	assert.False(t, inRevertedBatch(&orm.L2SentMsg{Height: 21, BatchIndex: 2}, revertedBatches))
+	assert.False(t, inRevertedBatch(l2sentMsg, nil))
+
+	newTxHistory := func() *types.TxHistoryInfo {
+		return &types.TxHistoryInfo{
+			MsgHash:     "hash1",
+			ClaimInfo:   &types.UserClaimInfo{BatchHash: "reverted"},
+			ClaimStatus: types.ClaimStatusPending,
+		}
+	}
+
+	// reverted and not committed again yet
+	txHistory := newTxHistory()
+	updateRevertedBatchInfo(txHistory, l2sentMsg, nil)
+	assert.True(t, txHistory.BatchReverted)
+	assert.Nil(t, txHistory.RebatchedIndex)
+	assert.Nil(t, txHistory.ClaimInfo)
+	assert.Equal(t, types.ClaimStatusRebatchPending, txHistory.ClaimStatus)
+
+	// committed again but not finalized
+	rebatch := &orm.RollupBatch{BatchIndex: 2, BatchHash: "rebatch", StartBlockNumber: 11, EndBlockNumber: 18}
+	txHistory = newTxHistory()
+	updateRevertedBatchInfo(txHistory, l2sentMsg, rebatch)
+	assert.True(t, txHistory.BatchReverted)
+	assert.Equal(t, uint64(2), *txHistory.RebatchedIndex)
+	assert.Nil(t, txHistory.ClaimInfo)
+	assert.Equal(t, types.ClaimStatusRebatchPending, txHistory.ClaimStatus)
+
+	// the rebatch is finalized, the claim info is against the rebatch
+	rebatch.FinalizeHeight = 100
+	txHistory = newTxHistory()
+	txHistory.ClaimInfo.BatchHash = "rebatch"
+	txHistory.ClaimStatus = types.ClaimStatusClaimable
+	updateRevertedBatchInfo(txHistory, l2sentMsg, rebatch)
+	assert.True(t, txHistory.BatchReverted)
+	assert.Equal(t, uint64(2), *txHistory.RebatchedIndex)
+	assert.NotNil(t, txHistory.ClaimInfo)
+	assert.Equal(t, types.ClaimStatusClaimable, txHistory.ClaimStatus)
+
+	// re-batched under another index which the message is not proven against yet
+	txHistory = newTxHistory()
+	updateRevertedBatchInfo(txHistory, l2sentMsg, &orm.RollupBatch{BatchIndex: 3, StartBlockNumber: 15, EndBlockNumber: 20, FinalizeHeight: 100})
+	assert.Equal(t, uint64(3), *txHistory.RebatchedIndex)
+	assert.Nil(t, txHistory.ClaimInfo)
+	assert.Equal(t, types.ClaimStatusRebatchPending, txHistory.ClaimStatus)
+}
+
+func TestOperationType(t *testing.T) {
+	relayed := &types.Finalized{Hash: "relayhash"}
+	tests := []struct {
+		name        string
+		txHistory   *types.TxHistoryInfo
+		relayFailed bool
+		expected    types.OperationType
+	}{
+		{"deposit pending", &types.TxHistoryInfo{IsL1: true, FinalizeTx: &types.Finalized{}}, false, types.OperationTypeDepositPending},
+		{"deposit relayed", &types.TxHistoryInfo{IsL1: true, FinalizeTx: relayed}, false, types.OperationTypeDeposit},
+		{"deposit relayed after a failed relay", &types.TxHistoryInfo{IsL1: true, FinalizeTx: relayed}, true, types.OperationTypeDeposit},
+		{"deposit failed", &types.TxHistoryInfo{IsL1: true, FinalizeTx: &types.Finalized{}}, true, types.OperationTypeDepositFailed},
+		{"withdrawal without batch", &types.TxHistoryInfo{FinalizeTx: &types.Finalized{}}, false, types.OperationTypeWithdrawalPending},
+		{"withdrawal pending", &types.TxHistoryInfo{FinalizeTx: &types.Finalized{}, ClaimStatus: types.ClaimStatusPending}, false, types.OperationTypeWithdrawalPending},
+		{"withdrawal claimable", &types.TxHistoryInfo{FinalizeTx: &types.Finalized{}, ClaimStatus: types.ClaimStatusClaimable}, false, types.OperationTypeWithdrawalClaimable},
+		{"withdrawal claim failed", &types.TxHistoryInfo{FinalizeTx: &types.Finalized{}, ClaimStatus: types.ClaimStatusClaimable}, true, types.OperationTypeWithdrawalClaimFailed},
+		{"withdrawal claimed", &types.TxHistoryInfo{FinalizeTx: relayed, ClaimStatus: types.ClaimStatusClaimed}, false, types.OperationTypeWithdrawalClaimed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, operationType(tt.txHistory, tt.relayFailed))
+		})
+	}
+}
+
+func TestTxStatus(t *testing.T) {
+	relayed := &types.Finalized{Hash: "relayhash"}
+	claimInfo := &types.UserClaimInfo{Proof: "0x1234"}
+	tests := []struct {
+		name        string
+		txHistory   *types.TxHistoryInfo
+		relayFailed bool
+		expected    types.TxStatus
+	}{
+		{"deposit pending", &types.TxHistoryInfo{IsL1: true, FinalizeTx: &types.Finalized{}}, false, types.TxStatusPending},
+		{"deposit relayed", &types.TxHistoryInfo{IsL1: true, FinalizeTx: relayed}, false, types.TxStatusRelayed},
+		{"deposit failed", &types.TxHistoryInfo{IsL1: true, FinalizeTx: &types.Finalized{}}, true, types.TxStatusFailed},
+		{"deposit relayed after a failed relay", &types.TxHistoryInfo{IsL1: true, FinalizeTx: relayed}, true, types.TxStatusRelayed},
+		{"withdrawal without batch", &types.TxHistoryInfo{FinalizeTx: &types.Finalized{}}, false, types.TxStatusPending},
+		{"withdrawal batch not finalized", &types.TxHistoryInfo{FinalizeTx: &types.Finalized{}, ClaimStatus: types.ClaimStatusPending, ClaimInfo: claimInfo}, false, types.TxStatusPending},
+		{"withdrawal claimable", &types.TxHistoryInfo{FinalizeTx: &types.Finalized{}, ClaimStatus: types.ClaimStatusClaimable, ClaimInfo: claimInfo}, false, types.TxStatusClaimable},
+		{"withdrawal claimable after a failed claim", &types.TxHistoryInfo{FinalizeTx: &types.Finalized{}, ClaimStatus: types.ClaimStatusClaimable, ClaimInfo: claimInfo}, true, types.TxStatusClaimable},
+		{"withdrawal proof not generated", &types.TxHistoryInfo{FinalizeTx: &types.Finalized{}, ClaimStatus: types.ClaimStatusClaimable, ClaimInfo: &types.UserClaimInfo{Proof: "0x"}}, false, types.TxStatusPending},
+		{"withdrawal proof pruned", &types.TxHistoryInfo{FinalizeTx: &types.Finalized{}, ClaimStatus: types.ClaimStatusClaimable, ClaimInfo: &types.UserClaimInfo{Proof: "0x", ProofPruned: true}}, false, types.TxStatusPending},
+		{"withdrawal rebatch pending", &types.TxHistoryInfo{FinalizeTx: &types.Finalized{}, ClaimStatus: types.ClaimStatusRebatchPending}, false, types.TxStatusPending},
+		{"withdrawal claimed", &types.TxHistoryInfo{FinalizeTx: relayed, ClaimStatus: types.ClaimStatusClaimed, ClaimInfo: claimInfo}, false, types.TxStatusClaimed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, txStatus(tt.txHistory, tt.relayFailed))
+		})
+	}
+}
+
+func TestL1FinalityStatus(t *testing.T) {
+	assert.Equal(t, types.L1FinalityStatus(""), l1FinalityStatus(100, 0, 0))
+	assert.Equal(t, types.L1FinalityStatusFinalized, l1FinalityStatus(100, 164, 100))
+	assert.Equal(t, types.L1FinalityStatusSafe, l1FinalityStatus(101, 164, 100))
+	assert.Equal(t, types.L1FinalityStatusUnsafe, l1FinalityStatus(165, 164, 100))
+	// the finalized block isn't polled yet
+	assert.Equal(t, types.L1FinalityStatusSafe, l1FinalityStatus(100, 164, 0))
+
+	assert.True(t, reachesFinality(types.L1FinalityStatusUnsafe, ""))
+	assert.True(t, reachesFinality("", types.L1FinalityStatusFinalized))
+	assert.True(t, reachesFinality(types.L1FinalityStatusFinalized, types.L1FinalityStatusSafe))
+	assert.False(t, reachesFinality(types.L1FinalityStatusUnsafe, types.L1FinalityStatusSafe))
+	assert.False(t, reachesFinality(types.L1FinalityStatusSafe, types.L1FinalityStatusFinalized))
+}
+
+func TestDepositCreditFinality(t *testing.T) {
+	db, _ := newCountingDB(t, map[string]interface{}{
+		(&orm.L1BlockTag{}).TableName(): []*orm.L1BlockTag{
+			{Tag: orm.L1BlockTagSafe, Number: 164},
+			{Tag: orm.L1BlockTagFinalized, Number: 100},
+		},
+	})
+	newTxHistories := func() []*types.TxHistoryInfo {
+		return []*types.TxHistoryInfo{
+			{IsL1: true, BlockNumber: 100, FinalizeTx: &types.Finalized{Hash: "0x01"}},
+			{IsL1: true, BlockNumber: 150, FinalizeTx: &types.Finalized{Hash: "0x02"}},
+			{IsL1: true, BlockNumber: 200, FinalizeTx: &types.Finalized{}},
+			{BlockNumber: 300, FinalizeTx: &types.Finalized{}},
+		}
+	}
+
+	txHistories := newTxHistories()
+	assert.NoError(t, updateOperationTypes(context.Background(), txHistories, db, ""))
+	assert.Equal(t, types.L1FinalityStatusFinalized, txHistories[0].L1FinalityStatus)
+	assert.Equal(t, types.L1FinalityStatusSafe, txHistories[1].L1FinalityStatus)
+	assert.Equal(t, types.L1FinalityStatusUnsafe, txHistories[2].L1FinalityStatus)
+	assert.Empty(t, txHistories[3].L1FinalityStatus)
+	assert.Equal(t, types.TxStatusRelayed, txHistories[1].Status)
+
+	// the relayed deposit of the safe block isn't credited until finalized
+	txHistories = newTxHistories()
+	assert.NoError(t, updateOperationTypes(context.Background(), txHistories, db, types.L1FinalityStatusFinalized))
+	assert.Equal(t, types.TxStatusRelayed, txHistories[0].Status)
+	assert.Equal(t, types.OperationTypeDeposit, txHistories[0].OperationType)
+	assert.Equal(t, types.TxStatusPending, txHistories[1].Status)
+	assert.Equal(t, types.OperationTypeDepositPending, txHistories[1].OperationType)
+}
+
+func TestReplayedAndDroppedDeposits(t *testing.T) {
+	db, counter := newCountingDB(t, map[string]interface{}{
+		(&orm.ReplayedMsg{}).TableName(): []*orm.ReplayedMsg{
+			{MsgHash: "0xa1", QueueIndex: 9, Layer1Hash: "0x12"},
+			{MsgHash: "0xa1", QueueIndex: 5, Layer1Hash: "0x11"},
+		},
+		(&orm.DroppedMsg{}).TableName(): []*orm.DroppedMsg{
+			{MsgHash: "0xa1", QueueIndex: 1, Layer1Hash: "0x13"},
+		},
+		(&orm.FailedRelayedMsg{}).TableName(): []*orm.FailedRelayedMsg{
+			{MsgHash: "0xa1", Layer2Hash: "0x14"},
+		},
+	})
+	txHistories := []*types.TxHistoryInfo{
+		{IsL1: true, MsgHash: "0xa1", FinalizeTx: &types.Finalized{}},
+		{IsL1: true, MsgHash: "0xa2", FinalizeTx: &types.Finalized{}},
+		{MsgHash: "0xb1", FinalizeTx: &types.Finalized{}},
+	}
+	assert.NoError(t, updateOperationTypes(context.Background(), txHistories, db, ""))
+	// the latest replay is reported, the dropped deposit doesn't require the manual execution even though it failed
+	assert.True(t, txHistories[0].Replayed)
+	assert.Equal(t, "0x12", txHistories[0].ReplayTxHash)
+	assert.Equal(t, "0x13", txHistories[0].DropTxHash)
+	assert.Equal(t, types.TxStatusDropped, txHistories[0].Status)
+	assert.Equal(t, types.OperationTypeDepositDropped, txHistories[0].OperationType)
+	assert.Nil(t, txHistories[0].ExecuteParams)
+	assert.False(t, txHistories[1].Replayed)
+	assert.Equal(t, types.TxStatusPending, txHistories[1].Status)
+	// the withdrawals aren't queried
+	assert.Equal(t, []interface{}{"0xa1", "0xa2"}, counter.vars["(*DroppedMsg).GetDroppedMsgsByHashes"])
+
+	// the relayed deposits are never dropped
+	txHistories = []*types.TxHistoryInfo{{IsL1: true, MsgHash: "0xa1", FinalizeTx: &types.Finalized{Hash: "0x15"}}}
+	assert.NoError(t, updateOperationTypes(context.Background(), txHistories, db, ""))
+	assert.Equal(t, types.TxStatusRelayed, txHistories[0].Status)
+}
+
+func TestRefundedDeposits(t *testing.T) {
+	db, counter := newCountingDB(t, map[string]interface{}{
+		(&orm.DroppedMsg{}).TableName(): []*orm.DroppedMsg{
+			{MsgHash: "0xa1", QueueIndex: 1, Layer1Hash: "0x13"},
+		},
+		(&orm.RefundedMsg{}).TableName(): []*orm.RefundedMsg{
+			{MsgHash: "0xa1", Layer1Hash: "0x13", Recipient: "0x21", Amount: "100"},
+		},
+	})
+	txHistories := []*types.TxHistoryInfo{
+		{IsL1: true, MsgHash: "0xa1", FinalizeTx: &types.Finalized{}},
+		{IsL1: true, MsgHash: "0xa2", FinalizeTx: &types.Finalized{}},
+	}
+	assert.NoError(t, updateOperationTypes(context.Background(), txHistories, db, ""))
+	assert.True(t, txHistories[0].Refunded)
+	assert.Equal(t, "0x13", txHistories[0].RefundTxHash)
+	assert.Equal(t, types.TxStatusRefunded, txHistories[0].Status)
+	assert.Equal(t, types.OperationTypeDepositRefunded, txHistories[0].OperationType)
+	assert.False(t, txHistories[1].Refunded)
+	// the refunds are queried for the dropped deposits only
+	assert.Equal(t, []interface{}{"0xa1"}, counter.vars["(*RefundedMsg).GetRefundedMsgsByHashes"])
+
+	// no refund is queried without a drop
+	db, counter = newCountingDB(t, nil)
+	txHistories = []*types.TxHistoryInfo{{IsL1: true, MsgHash: "0xa1", FinalizeTx: &types.Finalized{}}}
+	assert.NoError(t, updateOperationTypes(context.Background(), txHistories, db, ""))
+	assert.Equal(t, types.TxStatusPending, txHistories[0].Status)
+	assert.Zero(t, counter.calls["(*RefundedMsg).GetRefundedMsgsByHashes"])
+}
+
+func TestExecuteParams(t *testing.T) {
+	// the deposit failed to be executed on layer2 and requires the manual execution
+	failedDeposit := &types.TxHistoryInfo{IsL1: true, MsgHash: "hash1", FinalizeTx: &types.Finalized{}}
+	assert.Equal(t, types.OperationTypeDepositFailed, operationType(failedDeposit, true))
+
+	l1CrossMsg := &orm.CrossMsg{
+		MsgHash:   "hash1",
+		BlockHash: "blockhash1",
+		MsgType:   int(orm.Layer1Msg),
+		MsgSender: "0x0000000000000000000000000000000000000001",
+		MsgTarget: "0x0000000000000000000000000000000000000002",
+		MsgValue:  "1000",
+		MsgNonce:  7,
+		MsgData:   "0x1234",
+	}
+	assert.Equal(t, &types.ExecuteParams{
+		From:      "0x0000000000000000000000000000000000000001",
+		To:        "0x0000000000000000000000000000000000000002",
+		Value:     "1000",
+		Nonce:     "7",
+		Message:   "0x1234",
+		BlockHash: "blockhash1",
+	}, executeParams(l1CrossMsg))
+
+	// the message of the deposit is unknown
+	assert.Nil(t, executeParams(nil))
+	assert.Nil(t, executeParams(&orm.CrossMsg{MsgHash: "hash2", MsgType: int(orm.Layer1Msg)}))
+}
+
+func TestGroupTxsByStatus(t *testing.T) {
+	relayed := &types.Finalized{Hash: "relayhash"}
+	txHistories := []*types.TxHistoryInfo{
+		{MsgHash: "deposit-pending", IsL1: true, FinalizeTx: &types.Finalized{}},
+		{MsgHash: "deposit-relayed", IsL1: true, FinalizeTx: relayed},
+		{MsgHash: "withdrawal-unknown", FinalizeTx: &types.Finalized{}},
+		{MsgHash: "withdrawal-pending", FinalizeTx: &types.Finalized{}, ClaimStatus: types.ClaimStatusPending},
+		{MsgHash: "withdrawal-claimable1", FinalizeTx: &types.Finalized{}, ClaimStatus: types.ClaimStatusClaimable},
+		{MsgHash: "withdrawal-claimed", FinalizeTx: relayed, ClaimStatus: types.ClaimStatusClaimed},
+		{MsgHash: "withdrawal-claimable2", FinalizeTx: &types.Finalized{}, ClaimStatus: types.ClaimStatusClaimable},
+	}
+
+	groups := groupTxsByStatus(txHistories)
+	expected := map[types.ClaimStatus][]string{
+		types.ClaimStatusUnknown:   {"withdrawal-unknown"},
+		types.ClaimStatusPending:   {"deposit-pending", "withdrawal-pending"},
+		types.ClaimStatusClaimable: {"withdrawal-claimable1", "withdrawal-claimable2"},
+		types.ClaimStatusClaimed:   {"deposit-relayed", "withdrawal-claimed"},
+	}
+	assert.Len(t, groups, len(expected))
+	for status, msgHashes := range expected {
+		var groupMsgHashes []string
+		for _, txHistory := range groups[status] {
+			groupMsgHashes = append(groupMsgHashes, txHistory.MsgHash)
+		}
+		assert.Equal(t, msgHashes, groupMsgHashes, "status %d", status)
+	}
+}
+
+func TestSplitByProofReadiness(t *testing.T) {
+	txHistories := []*types.TxHistoryInfo{
+		{MsgHash: "ready1", ClaimStatus: types.ClaimStatusClaimable, ClaimInfo: &types.UserClaimInfo{Proof: "0x01"}},
+		{MsgHash: "batch-not-finalized", ClaimStatus: types.ClaimStatusPending, ClaimInfo: &types.UserClaimInfo{Proof: "0x02"}},
+		{MsgHash: "pruned", ClaimStatus: types.ClaimStatusClaimable, ClaimInfo: &types.UserClaimInfo{Proof: "0x", ProofPruned: true}},
+		{MsgHash: "ready2", ClaimStatus: types.ClaimStatusClaimable, ClaimInfo: &types.UserClaimInfo{Proof: "0x03"}},
+		{MsgHash: "rebatch-pending", ClaimStatus: types.ClaimStatusRebatchPending},
+	}
+	ready, proofPending := splitByProofReadiness(txHistories)
+	msgHashes := func(txHistories []*types.TxHistoryInfo) []string {
+		var hashes []string
+		for _, txHistory := range txHistories {
+			hashes = append(hashes, txHistory.MsgHash)
+		}
+		return hashes
+	}
+	assert.Equal(t, []string{"ready1", "ready2"}, msgHashes(ready))
+	assert.Equal(t, []string{"batch-not-finalized", "pruned", "rebatch-pending"}, msgHashes(proofPending))
+
+	ready, proofPending = splitByProofReadiness(nil)
+	assert.Empty(t, ready)
+	assert.Empty(t, proofPending)
+}
+
+func TestFilterClaimsExpiringWithin(t *testing.T) {
+	now := time.Unix(100000, 0)
+	claimExpiry := 24 * time.Hour
+	newTxHistory := func(msgHash string, age time.Duration) *types.TxHistoryInfo {
+		blockTimestamp := now.Add(-age)
+		return &types.TxHistoryInfo{
+			MsgHash:        msgHash,
+			BlockTimestamp: &blockTimestamp,
+			ClaimInfo:      &types.UserClaimInfo{ClaimExpiresAt: claimExpiresAt(&blockTimestamp, claimExpiry)},
+		}
+	}
+	txHistories := []*types.TxHistoryInfo{
+		newTxHistory("expired", 25*time.Hour),
+		newTxHistory("expiring-now", 24*time.Hour),
+		newTxHistory("expiring-in-2h", 22*time.Hour),
+		newTxHistory("expiring-in-6h", 18*time.Hour),
+		newTxHistory("expiring-in-7h", 17*time.Hour),
+		{MsgHash: "non-expiring", ClaimInfo: &types.UserClaimInfo{}},
+		{MsgHash: "no-claim-info"},
+	}
+
+	var msgHashes []string
+	for _, txHistory := range filterClaimsExpiringWithin(txHistories, now, 6*time.Hour) {
+		msgHashes = append(msgHashes, txHistory.MsgHash)
+	}
+	assert.Equal(t, []string{"expiring-now", "expiring-in-2h", "expiring-in-6h"}, msgHashes)
+	assert.Empty(t, filterClaimsExpiringWithin(txHistories, now.Add(-48*time.Hour), time.Hour))
+}
+
+func TestDataCompleteness(t *testing.T) {
+	blockTimestamp := time.Unix(1000, 0)
+	relayedDeposit := &types.TxHistoryInfo{
+		IsL1:           true,
+		BlockTimestamp: &blockTimestamp,
+		L1BlockHash:    "blockhash",
+		FinalizeTx:     &types.Finalized{Hash: "l2hash"},
+	}
+	assert.Equal(t, uint8(100), dataCompleteness(relayedDeposit))
+	pendingDeposit := &types.TxHistoryInfo{IsL1: true, L1BlockHash: "blockhash", FinalizeTx: &types.Finalized{}}
+	assert.Equal(t, uint8(33), dataCompleteness(pendingDeposit))
+
+	claimedWithdrawal := &types.TxHistoryInfo{
+		BlockTimestamp: &blockTimestamp,
+		FinalizeTx:     &types.Finalized{Hash: "l1hash"},
+		ClaimInfo:      &types.UserClaimInfo{Proof: "0x01"},
+		ClaimStatus:    types.ClaimStatusClaimed,
+	}
+	assert.Equal(t, uint8(100), dataCompleteness(claimedWithdrawal))
+	// the proof is missing
+	claimableWithdrawal := &types.TxHistoryInfo{
+		BlockTimestamp: &blockTimestamp,
+		FinalizeTx:     &types.Finalized{},
+		ClaimInfo:      &types.UserClaimInfo{Proof: "0x"},
+		ClaimStatus:    types.ClaimStatusClaimable,
+	}
+	assert.Equal(t, uint8(60), dataCompleteness(claimableWithdrawal))
+	// the batch is missing
+	unbatchedWithdrawal := &types.TxHistoryInfo{BlockTimestamp: &blockTimestamp, FinalizeTx: &types.Finalized{}, ClaimStatus: types.ClaimStatusUnknown}
+	assert.Equal(t, uint8(20), dataCompleteness(unbatchedWithdrawal))
+
+	txHistories := []*types.TxHistoryInfo{relayedDeposit, unbatchedWithdrawal}
+	updateDataCompleteness(txHistories)
+	assert.Equal(t, uint8(100), txHistories[0].DataCompleteness)
+	assert.Equal(t, uint8(20), txHistories[1].DataCompleteness)
+}
+
+func TestRedactSensitiveFields(t *testing.T) {
+	newTxHistories := func() []*types.TxHistoryInfo {
+		return []*types.TxHistoryInfo{
+			{
+				MsgHash: "withdrawal",
+				Amount:  "100",
+				ClaimInfo: &types.UserClaimInfo{
+					From:       "sender",
+					To:         "target",
+					Value:      "100",
+					Nonce:      "1",
+					BatchIndex: "2",
+					Message:    "0x1234",
+					Proof:      "0x5678",
+					ClaimKey:   "0x9abc",
+				},
+			},
+			{
+				MsgHash:       "deposit",
+				IsL1:          true,
+				ExecuteParams: &types.ExecuteParams{From: "sender", Nonce: "3", Message: "0x1234", BlockHash: "0xdef0"},
+			},
+			{MsgHash: "pending"},
+		}
+	}
+
+	txHistories := newTxHistories()
+	(&HistoryLogic{}).redactSensitiveFields(txHistories)
+	assert.Equal(t, newTxHistories(), txHistories)
+
+	(&HistoryLogic{redactSensitive: true}).redactSensitiveFields(txHistories)
+	expected := newTxHistories()
+	expected[0].ClaimInfo.Message = ""
+	expected[0].ClaimInfo.Proof = ""
+	expected[1].ExecuteParams.Message = ""
+	assert.Equal(t, expected, txHistories)
+	assert.Equal(t, "0x9abc", txHistories[0].ClaimInfo.ClaimKey)
+	assert.Equal(t, "0xdef0", txHistories[1].ExecuteParams.BlockHash)
+}
+
+func TestSortClaimableTxsByExpirySoonest(t *testing.T) {
+	blockTime := time.Unix(1000, 0)
+	claimExpiry := time.Hour
+	newTxHistory := func(msgHash string, blockTimestamp *time.Time) *types.TxHistoryInfo {
+		return &types.TxHistoryInfo{
+			MsgHash:        msgHash,
+			BlockTimestamp: blockTimestamp,
+			ClaimInfo:      &types.UserClaimInfo{ClaimExpiresAt: claimExpiresAt(blockTimestamp, claimExpiry)},
+		}
+	}
+	later := blockTime.Add(2 * time.Minute)
+	earlier := blockTime.Add(time.Minute)
+	txHistories := []*types.TxHistoryInfo{
+		newTxHistory("non-expiring", nil),
+		newTxHistory("later", &later),
+		{MsgHash: "no-claim-info"},
+		newTxHistory("earlier", &earlier),
+	}
+	assert.Equal(t, earlier.Add(claimExpiry), *txHistories[3].ClaimInfo.ClaimExpiresAt)
+
+	sortClaimableTxs(txHistories, types.ClaimableSortByExpirySoonest)
+	expected := []string{"earlier", "later", "non-expiring", "no-claim-info"}
+	for i, txHistory := range txHistories {
+		assert.Equal(t, expected[i], txHistory.MsgHash)
+	}
+
+	// claims never expire without the claim expiry
+	assert.Nil(t, claimExpiresAt(&earlier, 0))
+}
+
+func TestTokenType(t *testing.T) {
+	assert.Equal(t, types.TokenTypeETH, tokenType(&orm.CrossMsg{Asset: int(orm.ETH)}))
+	assert.Equal(t, types.TokenTypeERC20, tokenType(&orm.CrossMsg{Asset: int(orm.ERC20), Layer1Token: "0x01", Layer2Token: "0x02"}))
+	assert.Equal(t, types.TokenTypeERC721, tokenType(&orm.CrossMsg{Asset: int(orm.ERC721), Layer1Token: "0x01", Layer2Token: "0x02"}))
+	assert.Equal(t, types.TokenTypeERC1155, tokenType(&orm.CrossMsg{Asset: int(orm.ERC1155), Layer1Token: "0x01", Layer2Token: "0x02"}))
+
+	// empty token addresses are native ETH whatever the asset
+	assert.Equal(t, types.TokenTypeETH, tokenType(&orm.CrossMsg{Asset: int(orm.ERC20)}))
+	assert.Equal(t, types.TokenTypeETH, tokenType(&orm.CrossMsg{Asset: int(orm.ERC20), Layer1Token: common.Address{}.Hex(), Layer2Token: common.Address{}.Hex()}))
+
+	txHistory := crossMsgToTxHistoryInfo(&orm.CrossMsg{Asset: int(orm.ERC20), Layer1Token: "0x01", Layer2Token: "0x02"})
+	assert.Equal(t, types.TokenTypeERC20, txHistory.TokenType)
+	assert.Nil(t, txHistory.TokenIDs)
+
+	txHistory = crossMsgToTxHistoryInfo(&orm.CrossMsg{Asset: int(orm.ERC1155), Layer1Token: "0x01", Layer2Token: "0x02", TokenIDs: "1, 2", TokenAmounts: "10, 20"})
+	assert.Equal(t, types.TokenTypeERC1155, txHistory.TokenType)
+	assert.Equal(t, []string{"1", "2"}, txHistory.TokenIDs)
+	assert.Equal(t, []string{"10", "20"}, txHistory.TokenAmounts)
+}
+
+func TestTokenTypeAssets(t *testing.T) {
+	assets, err := tokenTypeAssets(types.TokenTypeAll)
+	assert.NoError(t, err)
+	assert.Nil(t, assets)
+
+	assets, err = tokenTypeAssets(types.TokenTypeNFT)
+	assert.NoError(t, err)
+	assert.Equal(t, []orm.AssetType{orm.ERC721, orm.ERC1155}, assets)
+
+	assets, err = tokenTypeAssets(types.TokenTypeERC1155)
+	assert.NoError(t, err)
+	assert.Equal(t, []orm.AssetType{orm.ERC1155}, assets)
+
+	_, err = tokenTypeAssets("ERC4626")
+	assert.Error(t, err)
+}
+
+func TestSortOrder(t *testing.T) {
+	order, err := sortOrder(types.SortOrderDesc)
+	assert.NoError(t, err)
+	assert.Equal(t, orm.SortDesc, order)
+
+	order, err = sortOrder(types.SortOrderAsc)
+	assert.NoError(t, err)
+	assert.Equal(t, orm.SortAsc, order)
+
+	_, err = sortOrder("latest")
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+}
+
+func TestWithQueryTimeout(t *testing.T) {
+	assert.Equal(t, defaultQueryTimeout, NewHistoryLogic(nil, nil, nil, nil).queryTimeout)
+	logic := NewHistoryLogic(&config.Config{Server: &config.ServerConfig{QueryTimeout: 30}}, nil, nil, nil)
+	assert.Equal(t, 30*time.Second, logic.queryTimeout)
+
+	ctx, cancel := logic.withQueryTimeout(context.Background())
+	defer cancel()
+	deadline, ok := ctx.Deadline()
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), deadline, time.Second)
+
+	// the deadline of the incoming context is kept if it's earlier
+	parent, parentCancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer parentCancel()
+	ctx, cancel = logic.withQueryTimeout(parent)
+	defer cancel()
+	<-ctx.Done()
+	assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+
+	// unbounded
+	ctx, cancel = (&HistoryLogic{}).withQueryTimeout(context.Background())
+	defer cancel()
+	_, ok = ctx.Deadline()
+	assert.False(t, ok)
+}
+
+func TestNormalizeTxHashes(t *testing.T) {
+	hash := "0x1c1cfec9ab8ccc1a4a5ee7dc4d7d5c4eeb461a7efb5e7ab19abd12e3c54b7cbd"
+	hash2 := "0x2c1cfec9ab8ccc1a4a5ee7dc4d7d5c4eeb461a7efb5e7ab19abd12e3c54b7cbd"
+	normalized, err := normalizeTxHashes([]string{
+		hash,
+		strings.ToUpper(hash[2:]), // uppercase and unprefixed, duplicates the first one
+		"0X" + strings.ToUpper(hash2[2:]),
+		"",
+		"0x1234",                        // too short
+		"0x" + strings.Repeat("zz", 32), // not hex
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{hash, hash2}, normalized)
+
+	_, err = normalizeTxHashes([]string{"", "garbage"})
+	assert.Error(t, err)
+
+	normalized, err = normalizeTxHashes(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, normalized)
+}
+
+func TestTxRangeToMsgRange(t *testing.T) {
+	assert.Nil(t, txRangeToMsgRange(types.TxRange{}))
+	assert.Nil(t, txRangeToMsgRange(types.TxRange{ByTimestamp: true}))
+
+	from := uint64(100)
+	assert.Equal(t, &orm.MsgRange{From: &from, ByTimestamp: true}, txRangeToMsgRange(types.TxRange{From: &from, ByTimestamp: true}))
+}
+
+func TestTxHashOnLayer(t *testing.T) {
+	assert.Equal(t, "0x01", txHashOnLayer("0x01", "0x02", true))
+	assert.Equal(t, "0x02", txHashOnLayer("0x01", "0x02", false))
+	assert.Equal(t, "", txHashOnLayer("0x", "0x02", true))
+	assert.Equal(t, "", txHashOnLayer("0x01", "", false))
+}
+
+func TestCrossTxHashesByDirection(t *testing.T) {
+	db, _ := newCountingDB(t, map[string]interface{}{
+		(&orm.RelayedMsg{}).TableName(): []*orm.RelayedMsg{
+			{MsgHash: "0xa1", Layer2Hash: "0x12", Height: 20},
+			{MsgHash: "0xb1", Layer1Hash: "0x11", Height: 10},
+		},
+	})
+	deposit := crossMsgToTxHistoryInfo(&orm.CrossMsg{MsgHash: "0xa1", Layer1Hash: "0x01", Layer2Hash: "0x", MsgType: int(orm.Layer1Msg)})
+	withdrawal := crossMsgToTxHistoryInfo(&orm.CrossMsg{MsgHash: "0xb1", Layer1Hash: "0x", Layer2Hash: "0x02", MsgType: int(orm.Layer2Msg)})
+	notRelayed := crossMsgToTxHistoryInfo(&orm.CrossMsg{MsgHash: "0xb2", Layer2Hash: "0x03", MsgType: int(orm.Layer2Msg)})
+	assert.Equal(t, "0x01", deposit.Hash)
+	assert.Equal(t, "0x02", withdrawal.Hash)
+
+	assert.NoError(t, updateCrossTxHashes(context.Background(), []*types.TxHistoryInfo{deposit, withdrawal, notRelayed}, db))
+	assert.Equal(t, "0x12", deposit.FinalizeTx.Hash)
+	assert.Equal(t, "0x11", withdrawal.FinalizeTx.Hash)
+	assert.Equal(t, "", notRelayed.FinalizeTx.Hash)
+}
+
+func TestGetClaimableTxsByAddresses(t *testing.T) {
+	alice := common.HexToAddress("0x0a")
+	bob := common.HexToAddress("0x0b")
+	gateway := common.HexToAddress("0x0c")
+	db, counter := newCountingDB(t, map[string]interface{}{
+		(&orm.L2SentMsg{}).TableName(): []*orm.L2SentMsg{
+			{MsgHash: "0xb1", OriginalSender: alice.Hex(), Sender: gateway.Hex(), BatchIndex: 1, MsgProof: "abcd"},
+			{MsgHash: "0xb2", Sender: bob.Hex(), BatchIndex: 1, MsgProof: "abcd"},
+			// bob withdraws on behalf of alice
+			{MsgHash: "0xb3", OriginalSender: alice.Hex(), Sender: bob.Hex(), BatchIndex: 1, MsgProof: "abcd"},
+		},
+		(&orm.RollupBatch{}).TableName(): []*orm.RollupBatch{
+			{BatchIndex: 1, FinalizeHeight: 15},
+		},
+	})
+	logic := NewHistoryLogic(nil, db, nil, nil)
+
+	txsByAddress, err := logic.GetClaimableTxsByAddresses(context.Background(), []common.Address{alice, bob, alice})
+	assert.NoError(t, err)
+	assert.Len(t, txsByAddress, 2)
+	msgHashes := func(txHistories []*types.TxHistoryInfo) []string {
+		var hashes []string
+		for _, txHistory := range txHistories {
+			hashes = append(hashes, txHistory.MsgHash)
+		}
+		return hashes
+	}
+	assert.Equal(t, []string{"0xb1", "0xb3"}, msgHashes(txsByAddress[alice.Hex()]))
+	assert.Equal(t, []string{"0xb2", "0xb3"}, msgHashes(txsByAddress[bob.Hex()]))
+	assert.NotNil(t, txsByAddress[alice.Hex()][0].ClaimInfo)
+	// the addresses are deduplicated and queried at once, the enrichment runs once over all the txs
+	assert.Equal(t, []interface{}{alice.Hex(), bob.Hex(), alice.Hex(), bob.Hex()}, counter.vars["(*L2SentMsg).GetClaimableL2SentMsgByAddresses"])
+	assert.Equal(t, 1, counter.calls["(*L2SentMsg).GetClaimableL2SentMsgByAddresses"])
+	assert.Equal(t, 1, counter.calls["(*RollupBatch).GetRollupBatchesByIndexes"])
+
+	counter.calls = make(map[string]int)
+	txsByAddress, err = logic.GetClaimableTxsByAddresses(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, txsByAddress)
+	assert.Empty(t, counter.calls)
+
+	addresses := make([]common.Address, maxClaimableAddresses+1)
+	for i := range addresses {
+		addresses[i] = common.BytesToAddress([]byte{byte(i)})
+	}
+	_, err = logic.GetClaimableTxsByAddresses(context.Background(), addresses)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+}
+
+func TestGetTxsByTimeRange(t *testing.T) {
+	db, counter := newCountingDB(t, nil)
+	logic := &HistoryLogic{db: db}
+
+	_, _, err := logic.GetTxsByTimeRange(context.Background(), 200, 100, types.TxDirectionAll, types.SortOrderDesc, types.Pagination{})
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+	_, _, err = logic.GetTxsByTimeRange(context.Background(), 100, 200, "sideways", types.SortOrderDesc, types.Pagination{})
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+	_, _, err = logic.GetTxsByTimeRange(context.Background(), 100, 200, types.TxDirectionAll, "random", types.Pagination{})
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+	assert.Empty(t, counter.calls)
+
+	// the bounds are inclusive and compared with the block timestamps, no tx is in the range
+	txHistories, total, err := logic.GetTxsByTimeRange(context.Background(), 100, 200, types.TxDirectionDeposit, types.SortOrderAsc, types.Pagination{})
+	assert.NoError(t, err)
+	assert.Empty(t, txHistories)
+	assert.Zero(t, total)
+	assert.Equal(t, []interface{}{orm.Layer1Msg, time.Unix(100, 0).UTC(), time.Unix(200, 0).UTC()}, counter.vars["(*CrossMsg).GetTotalMsgCountInRange"])
+	assert.Zero(t, counter.calls["(*CrossMsg).GetMsgsInRangeWithOffset"])
+}
+
+func TestGetTxsByBatchIndex(t *testing.T) {
+	db, counter := newCountingDB(t, txsByHashesFixtures())
+	txHistories, total, err := (&HistoryLogic{db: db}).GetTxsByBatchIndex(context.Background(), 1, types.Pagination{})
+	assert.NoError(t, err)
+	assert.Empty(t, txHistories)
+	assert.Zero(t, total)
+	// the withdrawals are looked up in the layer2 blocks of the batch
+	assert.Equal(t, []interface{}{uint64(1)}, counter.vars["(*RollupBatch).GetRollupBatchByIndex"][:1])
+	assert.Equal(t, []interface{}{int(orm.ETH), int(orm.Layer2Msg), orm.Layer2Msg, uint64(90), uint64(99)}, counter.vars["(*CrossMsg).GetTotalWithdrawalCountInBlocks"])
+}
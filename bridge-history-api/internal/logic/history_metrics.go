@@ -0,0 +1,122 @@
+package logic
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"bridge-history-api/internal/types"
+)
+
+// historyMetrics instruments the exported HistoryLogic methods, a nil historyMetrics records nothing
+type historyMetrics struct {
+	queryDuration *prometheus.HistogramVec
+	queryTotal    *prometheus.CounterVec
+	queryResults  *prometheus.HistogramVec
+}
+
+// newHistoryMetrics registers the history metrics on reg, nil is returned if reg is nil so that nothing is recorded
+func newHistoryMetrics(reg prometheus.Registerer) *historyMetrics {
+	if reg == nil {
+		return nil
+	}
+	return &historyMetrics{
+		queryDuration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "bridge_history_api_query_duration_seconds",
+			Help:    "The duration of the history queries in seconds",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method"}),
+		queryTotal: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "bridge_history_api_query_total",
+			Help: "The total number of the history queries by outcome",
+		}, []string{"method", "outcome"}),
+		queryResults: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "bridge_history_api_query_results",
+			Help:    "The number of the tx histories returned by the history queries",
+			Buckets: prometheus.ExponentialBuckets(1, 4, 6),
+		}, []string{"method"}),
+	}
+}
+
+// observe records the duration and the outcome of the call of method started at start
+func (m *historyMetrics) observe(method string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+	m.queryDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	m.queryTotal.WithLabelValues(method, outcome).Inc()
+}
+
+// observeResults records the number of tx histories returned by the successful call of method
+func (m *historyMetrics) observeResults(method string, count int, err error) {
+	if m == nil || err != nil {
+		return
+	}
+	m.queryResults.WithLabelValues(method).Observe(float64(count))
+}
+
+// instrument runs fn as the call of method, classifies its error and records the duration and the outcome of the call,
+// and the number of the tx histories its result holds, see countTxHistories
+func instrument[T any](m *historyMetrics, method string, fn func() (T, error)) (T, error) {
+	start := time.Now()
+	result, err := fn()
+	err = classifyError(err)
+	m.observe(method, start, err)
+	if count, ok := countTxHistories(result); ok {
+		m.observeResults(method, count, err)
+	}
+	return result, err
+}
+
+// instrumentPage is instrument for the methods returning a page of tx histories along with its total or its cursor
+func instrumentPage[P any](m *historyMetrics, method string, fn func() ([]*types.TxHistoryInfo, P, error)) ([]*types.TxHistoryInfo, P, error) {
+	var page P
+	txHistories, err := instrument(m, method, func() ([]*types.TxHistoryInfo, error) {
+		txHistories, p, err := fn()
+		page = p
+		return txHistories, err
+	})
+	return txHistories, page, err
+}
+
+// exportedRecords is the number of the records written by an export
+type exportedRecords int
+
+// countTxHistories returns the number of the tx histories held by the result of a history query, false is returned
+// if the result holds no tx histories
+func countTxHistories(result any) (int, bool) {
+	switch result := result.(type) {
+	case []*types.TxHistoryInfo:
+		return len(result), true
+	case map[string][]*types.TxHistoryInfo:
+		var count int
+		for _, txHistories := range result {
+			count += len(txHistories)
+		}
+		return count, true
+	case map[types.ClaimStatus][]*types.TxHistoryInfo:
+		var count int
+		for _, txHistories := range result {
+			count += len(txHistories)
+		}
+		return count, true
+	case *types.ClaimableSplitResultData:
+		if result == nil {
+			return 0, false
+		}
+		return len(result.Ready) + len(result.ProofPending), true
+	case *types.ClaimableResultData:
+		if result == nil {
+			return 0, false
+		}
+		return len(result.Result), true
+	case exportedRecords:
+		return int(result), true
+	}
+	return 0, false
+}
@@ -0,0 +1,67 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/internal/types"
+)
+
+func TestHistoryMetrics(t *testing.T) {
+	db, _ := newCountingDB(t, txsByHashesFixtures())
+	reg := prometheus.NewRegistry()
+	logic := NewHistoryLogic(nil, db, nil, reg)
+
+	_, err := logic.GetTxsByHashes(context.Background(), []string{common.HexToHash("0x01").Hex(), common.HexToHash("0x02").Hex()}, types.TokenTypeAll, types.SortOrderDesc)
+	assert.NoError(t, err)
+	_, err = logic.GetTxsByHashes(context.Background(), []string{"0xzz"}, types.TokenTypeAll, types.SortOrderDesc)
+	assert.Error(t, err)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(logic.metrics.queryTotal.WithLabelValues("GetTxsByHashes", "ok")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(logic.metrics.queryTotal.WithLabelValues("GetTxsByHashes", "error")))
+	assert.Equal(t, 1, testutil.CollectAndCount(logic.metrics.queryDuration))
+
+	// the failed call returns no tx histories
+	metricFamilies, err := reg.Gather()
+	assert.NoError(t, err)
+	for _, metricFamily := range metricFamilies {
+		if metricFamily.GetName() == "bridge_history_api_query_results" {
+			histogram := metricFamily.GetMetric()[0].GetHistogram()
+			assert.Equal(t, uint64(1), histogram.GetSampleCount())
+			assert.Equal(t, float64(2), histogram.GetSampleSum())
+		}
+	}
+
+	// the metrics of another logic don't clash and nil registerer records nothing
+	assert.NotPanics(t, func() { NewHistoryLogic(nil, db, nil, prometheus.NewRegistry()) })
+	assert.Nil(t, NewHistoryLogic(nil, db, nil, nil).metrics)
+}
+
+func TestCountTxHistories(t *testing.T) {
+	txHistories := []*types.TxHistoryInfo{{}, {}}
+	var split *types.ClaimableSplitResultData
+	testCases := []struct {
+		result  any
+		count   int
+		counted bool
+	}{
+		{txHistories, 2, true},
+		{map[string][]*types.TxHistoryInfo{"0x01": txHistories, "0x02": txHistories[:1]}, 3, true},
+		{map[types.ClaimStatus][]*types.TxHistoryInfo{types.ClaimStatusClaimable: txHistories}, 2, true},
+		{&types.ClaimableSplitResultData{Ready: txHistories, ProofPending: txHistories[:1]}, 3, true},
+		{&types.ClaimableResultData{Result: txHistories}, 2, true},
+		{exportedRecords(5), 5, true},
+		{split, 0, false},
+		{types.ClaimStatusClaimable, 0, false},
+	}
+	for _, testCase := range testCases {
+		count, counted := countTxHistories(testCase.result)
+		assert.Equal(t, testCase.count, count)
+		assert.Equal(t, testCase.counted, counted)
+	}
+}
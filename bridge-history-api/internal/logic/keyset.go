@@ -0,0 +1,44 @@
+package logic
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"bridge-history-api/orm"
+)
+
+// encodeCursor encodes the position of the last message of a page into the opaque cursor of the next page
+func encodeCursor(cursor *orm.MsgCursor) string {
+	raw := strings.Join([]string{strconv.FormatUint(cursor.Height, 10), cursor.TxHash, cursor.MsgHash}, ",")
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor decodes the opaque cursor returned by encodeCursor, nil is returned for the empty cursor of the first page
+func decodeCursor(cursor string) (*orm.MsgCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor %q", ErrInvalidParameter, cursor)
+	}
+	fields := strings.Split(string(raw), ",")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("%w: malformed cursor %q", ErrInvalidParameter, cursor)
+	}
+	height, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor %q", ErrInvalidParameter, cursor)
+	}
+	return &orm.MsgCursor{Height: height, TxHash: fields[1], MsgHash: fields[2]}, nil
+}
+
+// nextCursor returns the cursor of the page after the messages, empty if the page isn't full and so is the last one
+func nextCursor(count, limit int, last *orm.MsgCursor) string {
+	if count < limit {
+		return ""
+	}
+	return encodeCursor(last)
+}
@@ -0,0 +1,46 @@
+package logic
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestCursor(t *testing.T) {
+	cursor := &orm.MsgCursor{Height: 100, TxHash: common.HexToHash("0x01").Hex(), MsgHash: common.HexToHash("0x02").Hex()}
+	decoded, err := decodeCursor(encodeCursor(cursor))
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+
+	// the first page
+	decoded, err = decodeCursor("")
+	assert.NoError(t, err)
+	assert.Nil(t, decoded)
+
+	for _, malformed := range []string{"!", base64.RawURLEncoding.EncodeToString([]byte("100,0x01")), base64.RawURLEncoding.EncodeToString([]byte("-1,0x01,0x02"))} {
+		_, err = decodeCursor(malformed)
+		assert.ErrorIs(t, err, ErrInvalidParameter)
+	}
+
+	assert.Empty(t, nextCursor(1, 2, cursor))
+	assert.Equal(t, encodeCursor(cursor), nextCursor(2, 2, cursor))
+}
+
+func TestKeysetMalformedCursor(t *testing.T) {
+	db, counter := newCountingDB(t, nil)
+	logic := NewHistoryLogic(nil, db, nil, nil)
+	pagination := types.KeysetPagination{Cursor: "!"}
+
+	_, _, err := logic.GetTxsByAddressWithKeyset(context.Background(), common.HexToAddress("0x01"), pagination)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+	_, _, err = logic.GetClaimableTxsByAddressWithKeyset(context.Background(), common.HexToAddress("0x01"), pagination)
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+	// rejected before querying
+	assert.Empty(t, counter.calls)
+}
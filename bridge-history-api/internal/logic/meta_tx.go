@@ -0,0 +1,90 @@
+package logic
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"bridge-history-api/internal/types"
+)
+
+// ForwarderDomain the EIP-712 domain of the ERC2771Forwarder the meta-txs are submitted to
+type ForwarderDomain struct {
+	Name    string
+	Version string
+	ChainID uint64
+	Address common.Address
+}
+
+// forwardRequestTypes the EIP-712 types of the ForwardRequest of ERC2771Forwarder, the nonce is the forwarder
+// nonce of the signer and isn't a member of the submitted request struct
+var forwardRequestTypes = apitypes.Types{
+	"EIP712Domain": {
+		{Name: "name", Type: "string"},
+		{Name: "version", Type: "string"},
+		{Name: "chainId", Type: "uint256"},
+		{Name: "verifyingContract", Type: "address"},
+	},
+	"ForwardRequest": {
+		{Name: "from", Type: "address"},
+		{Name: "to", Type: "address"},
+		{Name: "value", Type: "uint256"},
+		{Name: "gas", Type: "uint256"},
+		{Name: "nonce", Type: "uint256"},
+		{Name: "deadline", Type: "uint48"},
+		{Name: "data", Type: "bytes"},
+	},
+}
+
+// MetaTxClaim builds the forward request of from claiming the withdrawal on layer1 through the forwarder, and the
+// digest from signs. l1Messenger is the L1ScrollMessenger the request calls, nonce is the forwarder nonce of from.
+// The forwarder appends from to the calldata, so L1ScrollMessenger does not need to trust the forwarder: the claim
+// is permissionless and its result doesn't depend on the caller.
+func MetaTxClaim(claimInfo *types.UserClaimInfo, from, l1Messenger common.Address, nonce, deadline uint64, domain ForwarderDomain) (*types.MetaTxClaimPayload, error) {
+	calldata, err := buildClaimCalldata(claimInfo)
+	if err != nil {
+		return nil, err
+	}
+	gas, err := estimateClaimGas(claimInfo)
+	if err != nil {
+		return nil, err
+	}
+	request := &types.ForwardRequest{
+		From:     from.Hex(),
+		To:       l1Messenger.Hex(),
+		Value:    "0",
+		Gas:      gas,
+		Nonce:    nonce,
+		Deadline: deadline,
+		Data:     hexutil.Encode(calldata),
+	}
+
+	typedData := apitypes.TypedData{
+		Types:       forwardRequestTypes,
+		PrimaryType: "ForwardRequest",
+		Domain: apitypes.TypedDataDomain{
+			Name:              domain.Name,
+			Version:           domain.Version,
+			ChainId:           math.NewHexOrDecimal256(int64(domain.ChainID)),
+			VerifyingContract: domain.Address.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"from":     request.From,
+			"to":       request.To,
+			"value":    big.NewInt(0),
+			"gas":      new(big.Int).SetUint64(request.Gas),
+			"nonce":    new(big.Int).SetUint64(request.Nonce),
+			"deadline": new(big.Int).SetUint64(request.Deadline),
+			"data":     request.Data,
+		},
+	}
+	digest, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash the forward request: %w", err)
+	}
+	return &types.MetaTxClaimPayload{Request: request, SigningDigest: hexutil.Encode(digest)}, nil
+}
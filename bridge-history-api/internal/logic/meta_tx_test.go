@@ -0,0 +1,68 @@
+package logic
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+
+	backendabi "bridge-history-api/abi"
+	"bridge-history-api/internal/types"
+)
+
+func TestMetaTxClaim(t *testing.T) {
+	claimInfo := &types.UserClaimInfo{
+		From:       "0x0000000000000000000000000000000000000021",
+		To:         "0x0000000000000000000000000000000000000022",
+		Value:      "100",
+		Nonce:      "7",
+		BatchIndex: "3",
+		Message:    "0x1234",
+		Proof:      "0x" + common.Bytes2Hex(common.HexToHash("0x33").Bytes()),
+	}
+	from := common.HexToAddress("0x0000000000000000000000000000000000000041")
+	l1Messenger := common.HexToAddress("0x0000000000000000000000000000000000000042")
+	domain := ForwarderDomain{Name: "ClaimForwarder", Version: "1", ChainID: 1, Address: common.HexToAddress("0x0000000000000000000000000000000000000043")}
+
+	payload, err := MetaTxClaim(claimInfo, from, l1Messenger, 5, 1700000000, domain)
+	assert.NoError(t, err)
+	request := payload.Request
+	assert.Equal(t, from.Hex(), request.From)
+	assert.Equal(t, l1Messenger.Hex(), request.To)
+	assert.Equal(t, "0", request.Value)
+	assert.Equal(t, uint64(5), request.Nonce)
+	assert.Equal(t, uint64(1700000000), request.Deadline)
+	data, err := hexutil.Decode(request.Data)
+	assert.NoError(t, err)
+	assert.Equal(t, backendabi.L1ScrollMessengerABI.Methods["relayMessageWithProof"].ID, data[:4])
+
+	// the EIP-712 digest encoded by hand
+	word := func(v *big.Int) []byte { return common.LeftPadBytes(v.Bytes(), 32) }
+	address := func(a common.Address) []byte { return common.LeftPadBytes(a.Bytes(), 32) }
+	domainSeparator := crypto.Keccak256(
+		crypto.Keccak256([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)")),
+		crypto.Keccak256([]byte("ClaimForwarder")),
+		crypto.Keccak256([]byte("1")),
+		word(big.NewInt(1)),
+		address(domain.Address),
+	)
+	structHash := crypto.Keccak256(
+		crypto.Keccak256([]byte("ForwardRequest(address from,address to,uint256 value,uint256 gas,uint256 nonce,uint48 deadline,bytes data)")),
+		address(from),
+		address(l1Messenger),
+		word(big.NewInt(0)),
+		word(new(big.Int).SetUint64(request.Gas)),
+		word(big.NewInt(5)),
+		word(big.NewInt(1700000000)),
+		crypto.Keccak256(data),
+	)
+	expected := crypto.Keccak256([]byte("\x19\x01"), domainSeparator, structHash)
+	assert.Equal(t, hexutil.Encode(expected), payload.SigningDigest)
+
+	claimInfo.Value = "invalid"
+	_, err = MetaTxClaim(claimInfo, from, l1Messenger, 5, 1700000000, domain)
+	assert.Error(t, err)
+}
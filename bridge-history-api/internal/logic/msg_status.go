@@ -0,0 +1,112 @@
+package logic
+
+import (
+	"context"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// newMsgStatus computes the lifecycle state of the message of crossMsg following the state machine documented on
+// types.MsgState. For a withdrawal l2sentMsg is its sent message if the message hash is known, batch is the batch
+// committing it and nil if the batch is reverted, relayedMsg is nil until relayed on the target layer, relayFailed is
+// whether a relay of the message failed on the target layer.
+func newMsgStatus(crossMsg *orm.CrossMsg, l2sentMsg *orm.L2SentMsg, batch *orm.RollupBatch, relayedMsg *orm.RelayedMsg, relayFailed bool) *types.MsgStatus {
+	isL1 := crossMsg.MsgType == int(orm.Layer1Msg)
+	status := &types.MsgStatus{
+		MsgHash: crossMsg.MsgHash,
+		TxHash:  txHashOnLayer(crossMsg.Layer1Hash, crossMsg.Layer2Hash, isL1),
+		IsL1:    isL1,
+	}
+	if l2sentMsg != nil && batch != nil {
+		batchIndex := batch.BatchIndex
+		status.BatchIndex = &batchIndex
+	}
+	if relayedMsg != nil {
+		status.CounterpartTxHash = txHashOnLayer(relayedMsg.Layer1Hash, relayedMsg.Layer2Hash, !isL1)
+	}
+
+	switch {
+	case crossMsg.DeletedAt.Valid:
+		status.State = types.MsgStateDropped
+	case relayedMsg != nil && isL1:
+		status.State = types.MsgStateRelayed
+	case relayedMsg != nil:
+		status.State = types.MsgStateClaimed
+	case relayFailed:
+		status.State = types.MsgStateFailed
+	case status.BatchIndex != nil && batch.IsFinalized():
+		status.State = types.MsgStateFinalized
+	case status.BatchIndex != nil:
+		status.State = types.MsgStateCommitted
+	default:
+		status.State = types.MsgStateSent
+	}
+	return status
+}
+
+// GetTxStatus get the lifecycle state of the message sent by the layer1 or layer2 tx, with the batch it's committed
+// in and its relay tx on the target layer, nil is returned if the tx sends no message
+func (h *HistoryLogic) GetTxStatus(ctx context.Context, txHash string) (*types.MsgStatus, error) {
+	return instrument(h.metrics, "GetTxStatus", func() (*types.MsgStatus, error) {
+		return h.getTxStatus(ctx, txHash)
+	})
+}
+
+// getTxStatus implements GetTxStatus
+func (h *HistoryLogic) getTxStatus(ctx context.Context, txHash string) (*types.MsgStatus, error) {
+	txHashes, err := normalizeTxHashes([]string{txHash})
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	crossMsg, err := orm.NewCrossMsg(h.db).GetCrossMsgByTxHashUnscoped(ctx, txHashes[0])
+	if err != nil {
+		return nil, err
+	}
+	if crossMsg == nil {
+		archived, err := h.getArchivedCrossMsgs(ctx, txHashes, nil, nil, orm.SortDesc)
+		if err != nil || len(archived) == 0 {
+			return nil, err
+		}
+		crossMsg = archived[0]
+	}
+	// the message hash of the message is unknown until the fetcher updates it
+	if crossMsg.MsgHash == "" {
+		return newMsgStatus(crossMsg, nil, nil, nil, false), nil
+	}
+
+	var l2sentMsg *orm.L2SentMsg
+	var batch *orm.RollupBatch
+	if crossMsg.MsgType == int(orm.Layer2Msg) {
+		l2sentMsgs, err := orm.NewL2SentMsg(h.db).GetL2SentMsgsByHashes(ctx, []string{crossMsg.MsgHash})
+		if err != nil {
+			return nil, err
+		}
+		if len(l2sentMsgs) > 0 && l2sentMsgs[0].BatchIndex != 0 {
+			l2sentMsg = l2sentMsgs[0]
+			batches, err := orm.NewRollupBatch(h.db).GetRollupBatchesByIndexes(ctx, []uint64{l2sentMsg.BatchIndex})
+			if err != nil {
+				return nil, err
+			}
+			// the batch index is stale if the batch is reverted and the message is not committed again yet
+			if len(batches) > 0 && batches[0].ContainsBlock(l2sentMsg.Height) {
+				batch = batches[0]
+			}
+		}
+	}
+
+	relayedMsg, err := orm.NewRelayedMsg(h.db).GetRelayedMsgByHash(ctx, crossMsg.MsgHash)
+	if err != nil {
+		return nil, err
+	}
+	failedRelayedMsgs, err := orm.NewFailedRelayedMsg(h.db).GetFailedRelayedMsgsByHashes(ctx, []string{crossMsg.MsgHash})
+	if err != nil {
+		return nil, err
+	}
+	return newMsgStatus(crossMsg, l2sentMsg, batch, relayedMsg, len(failedRelayedMsgs) > 0), nil
+}
@@ -0,0 +1,61 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestNewMsgStatus(t *testing.T) {
+	withdrawal := &orm.CrossMsg{MsgHash: "msghash", Layer2Hash: "l2hash", Height: 15, MsgType: int(orm.Layer2Msg)}
+	l2sentMsg := &orm.L2SentMsg{MsgHash: "msghash", TxHash: "l2hash", Height: 15, BatchIndex: 3}
+	batch := &orm.RollupBatch{BatchIndex: 3, StartBlockNumber: 10, EndBlockNumber: 20}
+	relayedMsg := &orm.RelayedMsg{MsgHash: "msghash", Layer1Hash: "claimhash"}
+
+	status := newMsgStatus(withdrawal, nil, nil, nil, false)
+	assert.Equal(t, &types.MsgStatus{MsgHash: "msghash", TxHash: "l2hash", State: types.MsgStateSent}, status)
+
+	status = newMsgStatus(withdrawal, l2sentMsg, batch, nil, false)
+	assert.Equal(t, types.MsgStateCommitted, status.State)
+	assert.Equal(t, uint64(3), *status.BatchIndex)
+
+	batch.FinalizeHeight = 110
+	assert.Equal(t, types.MsgStateFinalized, newMsgStatus(withdrawal, l2sentMsg, batch, nil, false).State)
+	assert.Equal(t, types.MsgStateFailed, newMsgStatus(withdrawal, l2sentMsg, batch, nil, true).State)
+
+	// a successful claim takes precedence over the failed ones
+	status = newMsgStatus(withdrawal, l2sentMsg, batch, relayedMsg, true)
+	assert.Equal(t, types.MsgStateClaimed, status.State)
+	assert.Equal(t, "claimhash", status.CounterpartTxHash)
+
+	deposit := &orm.CrossMsg{MsgHash: "deposithash", Layer1Hash: "l1hash", Height: 5, MsgType: int(orm.Layer1Msg)}
+	assert.Equal(t, types.MsgStateSent, newMsgStatus(deposit, nil, nil, nil, false).State)
+	assert.Equal(t, types.MsgStateFailed, newMsgStatus(deposit, nil, nil, nil, true).State)
+	status = newMsgStatus(deposit, nil, nil, &orm.RelayedMsg{MsgHash: "deposithash", Layer2Hash: "executehash"}, false)
+	assert.Equal(t, &types.MsgStatus{
+		MsgHash:           "deposithash",
+		TxHash:            "l1hash",
+		IsL1:              true,
+		State:             types.MsgStateRelayed,
+		CounterpartTxHash: "executehash",
+	}, status)
+
+	// the messages of a reorged tx are dropped whatever their relays
+	deposit.DeletedAt = gorm.DeletedAt{Time: time.Unix(1000, 0), Valid: true}
+	assert.Equal(t, types.MsgStateDropped, newMsgStatus(deposit, nil, nil, nil, true).State)
+}
+
+func TestGetTxStatusInvalidHash(t *testing.T) {
+	db, counter := newCountingDB(t, nil)
+	h := NewHistoryLogic(nil, db, nil, nil)
+	_, err := h.GetTxStatus(context.Background(), "0x01")
+	assert.True(t, errors.Is(err, ErrInvalidParameter))
+	assert.Empty(t, counter.calls)
+}
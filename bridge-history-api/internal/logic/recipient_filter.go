@@ -0,0 +1,118 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"bridge-history-api/internal/types"
+)
+
+// CodeChecker checks whether an account has code, onL1 is the layer the account is checked on
+type CodeChecker interface {
+	HasCode(ctx context.Context, onL1 bool, address common.Address) (bool, error)
+}
+
+// ethCodeChecker checks the account code with the layer1 and layer2 nodes
+type ethCodeChecker struct {
+	l1Client *ethclient.Client
+	l2Client *ethclient.Client
+}
+
+// NewEthCodeChecker returns a CodeChecker backed with the layer1 and layer2 nodes
+func NewEthCodeChecker(l1Client, l2Client *ethclient.Client) CodeChecker {
+	return &ethCodeChecker{l1Client: l1Client, l2Client: l2Client}
+}
+
+// HasCode implements CodeChecker, the code is checked at the latest block
+func (e *ethCodeChecker) HasCode(ctx context.Context, onL1 bool, address common.Address) (bool, error) {
+	client := e.l2Client
+	if onL1 {
+		client = e.l1Client
+	}
+	code, err := client.CodeAt(ctx, address, nil)
+	if err != nil {
+		return false, err
+	}
+	return len(code) > 0, nil
+}
+
+// filterByRecipientType keeps the tx histories whose recipient on the target layer is of the recipient type,
+// the recipients of deposits are checked on layer2 and the recipients of withdrawals on layer1.
+func filterByRecipientType(ctx context.Context, txHistories []*types.TxHistoryInfo, recipientType types.RecipientType, checker CodeChecker) ([]*types.TxHistoryInfo, error) {
+	if recipientType == types.RecipientTypeAll {
+		return txHistories, nil
+	}
+	if recipientType != types.RecipientTypeEOA && recipientType != types.RecipientTypeContract {
+		return nil, fmt.Errorf("%w: unknown recipient type %s", ErrInvalidParameter, recipientType)
+	}
+
+	type account struct {
+		onL1    bool
+		address common.Address
+	}
+	hasCodeCache := make(map[account]bool)
+	var filtered []*types.TxHistoryInfo
+	for _, txHistory := range txHistories {
+		recipient := account{onL1: !txHistory.IsL1, address: common.HexToAddress(txHistory.To)}
+		hasCode, found := hasCodeCache[recipient]
+		if !found {
+			var err error
+			hasCode, err = checker.HasCode(ctx, recipient.onL1, recipient.address)
+			if err != nil {
+				return nil, err
+			}
+			hasCodeCache[recipient] = hasCode
+		}
+		if hasCode == (recipientType == types.RecipientTypeContract) {
+			filtered = append(filtered, txHistory)
+		}
+	}
+	return filtered, nil
+}
+
+// GetTxsByRecipientType get the deposits and withdrawals of the given address whose recipient is of the recipient
+// type, ordered by block timestamp. The recipients are classified by the checker, so all the messages of the address
+// are filtered before the pagination is applied.
+func (h *HistoryLogic) GetTxsByRecipientType(ctx context.Context, address common.Address, recipientType types.RecipientType, checker CodeChecker, pagination types.Pagination) ([]*types.TxHistoryInfo, uint64, error) {
+	return instrumentPage(h.metrics, "GetTxsByRecipientType", func() ([]*types.TxHistoryInfo, uint64, error) {
+		return h.getTxsByRecipientType(ctx, address, recipientType, checker, pagination)
+	})
+}
+
+// getTxsByRecipientType implements GetTxsByRecipientType
+func (h *HistoryLogic) getTxsByRecipientType(ctx context.Context, address common.Address, recipientType types.RecipientType, checker CodeChecker, pagination types.Pagination) ([]*types.TxHistoryInfo, uint64, error) {
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	results, err := h.crossMsgOrm().GetUnifiedMsgsByAddress(ctx, address.Hex())
+	if err != nil || len(results) == 0 {
+		return nil, 0, err
+	}
+
+	txHistories, err := filterByRecipientType(ctx, h.crossMsgsToTxHistoryInfos(results), recipientType, checker)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total := uint64(len(txHistories))
+	offset, limit := getOffsetLimit(pagination)
+	if offset >= len(txHistories) {
+		return nil, total, nil
+	}
+	if end := offset + limit; end < len(txHistories) {
+		txHistories = txHistories[offset:end]
+	} else {
+		txHistories = txHistories[offset:]
+	}
+
+	if err := updateCrossTxHashesAndL2TxClaimInfo(ctx, txHistories, h.db, h.depositCreditFinality, h.claimLookups); err != nil {
+		return nil, 0, err
+	}
+	h.finishTxHistories(ctx, txHistories)
+	return txHistories, total, nil
+}
@@ -0,0 +1,66 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/internal/types"
+)
+
+// mockCodeChecker reports the accounts in contracts as contracts, and counts the checks
+type mockCodeChecker struct {
+	contracts map[common.Address]bool
+	checks    int
+}
+
+func (m *mockCodeChecker) HasCode(_ context.Context, onL1 bool, address common.Address) (bool, error) {
+	m.checks++
+	if address == (common.Address{}) {
+		return false, errors.New("invalid address")
+	}
+	// the contract only exists on layer1
+	return onL1 && m.contracts[address], nil
+}
+
+func TestFilterByRecipientType(t *testing.T) {
+	eoa := "0x0000000000000000000000000000000000000001"
+	contract := "0x0000000000000000000000000000000000000002"
+	checker := &mockCodeChecker{contracts: map[common.Address]bool{common.HexToAddress(contract): true}}
+
+	txHistories := []*types.TxHistoryInfo{
+		{MsgHash: "withdrawal-to-eoa", To: eoa},
+		{MsgHash: "withdrawal-to-contract", To: contract},
+		// the recipient of the deposit is checked on layer2, where it has no code
+		{MsgHash: "deposit-to-contract-address", IsL1: true, To: contract},
+		{MsgHash: "withdrawal-to-eoa-again", To: eoa},
+	}
+
+	filtered, err := filterByRecipientType(context.Background(), txHistories, types.RecipientTypeEOA, checker)
+	assert.NoError(t, err)
+	expected := []string{"withdrawal-to-eoa", "deposit-to-contract-address", "withdrawal-to-eoa-again"}
+	assert.Len(t, filtered, len(expected))
+	for i, txHistory := range filtered {
+		assert.Equal(t, expected[i], txHistory.MsgHash)
+	}
+	// the recipient is checked once per layer
+	assert.Equal(t, 3, checker.checks)
+
+	filtered, err = filterByRecipientType(context.Background(), txHistories, types.RecipientTypeContract, checker)
+	assert.NoError(t, err)
+	assert.Len(t, filtered, 1)
+	assert.Equal(t, "withdrawal-to-contract", filtered[0].MsgHash)
+
+	// no filter
+	filtered, err = filterByRecipientType(context.Background(), txHistories, types.RecipientTypeAll, nil)
+	assert.NoError(t, err)
+	assert.Len(t, filtered, len(txHistories))
+
+	_, err = filterByRecipientType(context.Background(), txHistories, "unknown", checker)
+	assert.Error(t, err)
+	_, err = filterByRecipientType(context.Background(), []*types.TxHistoryInfo{{To: ""}}, types.RecipientTypeEOA, checker)
+	assert.Error(t, err)
+}
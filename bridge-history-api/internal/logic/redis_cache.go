@@ -0,0 +1,141 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/go-redis/redis/v8"
+
+	"bridge-history-api/config"
+	"bridge-history-api/internal/types"
+)
+
+// claimableEventsChannel is the redis pub/sub channel of the claimable events
+const claimableEventsChannel = "claimable_events"
+
+// claimableEventsChannelOf returns the pub/sub channel of the claimable events of the redis db, the channels aren't
+// scoped by the redis db so the networks sharing a redis are kept apart by the db number in the channel
+func claimableEventsChannelOf(db int) string {
+	if db == 0 {
+		return claimableEventsChannel
+	}
+	return fmt.Sprintf("%s:%d", claimableEventsChannel, db)
+}
+
+// redisCache is the Cache backed by redis
+type redisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache returns the Cache backed by the redis of cfg, nil is returned if cfg is nil or disabled so that the
+// queries hit the db every time
+func NewRedisCache(cfg *config.RedisConfig) Cache {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	return &redisCache{client: newRedisClient(cfg)}
+}
+
+// newRedisClient returns the client of the redis of cfg
+func newRedisClient(cfg *config.RedisConfig) *redis.Client {
+	return redis.NewClient(&redis.Options{
+		Addr:     cfg.Address,
+		Password: cfg.Password,
+		DB:       cfg.DB,
+	})
+}
+
+// Get returns the value of the key, found is false if the key doesn't exist or expired
+func (r *redisCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Set stores the value of the key expiring after ttl
+func (r *redisCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Del removes the keys, the keys not existing are ignored
+func (r *redisCache) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
+
+// redisClaimableEvents is the ClaimableEvents backed by redis pub/sub
+type redisClaimableEvents struct {
+	client  *redis.Client
+	channel string
+}
+
+// NewRedisClaimableEvents returns the ClaimableEvents backed by the redis pub/sub of cfg, nil is returned if cfg is nil
+// or disabled so that no events are published nor watched
+func NewRedisClaimableEvents(cfg *config.RedisConfig) ClaimableEvents {
+	if cfg == nil || !cfg.Enabled {
+		return nil
+	}
+	return &redisClaimableEvents{client: newRedisClient(cfg), channel: claimableEventsChannelOf(cfg.DB)}
+}
+
+// Publish sends the events to the current subscribers
+func (r *redisClaimableEvents) Publish(ctx context.Context, events ...*types.ClaimableEvent) error {
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		if err = r.client.Publish(ctx, r.channel, payload).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe returns the events published from now on, the channel is closed once ctx is done
+func (r *redisClaimableEvents) Subscribe(ctx context.Context) (<-chan *types.ClaimableEvent, error) {
+	pubsub := r.client.Subscribe(ctx, r.channel)
+	// wait for the confirmation so that the events published once returned are received
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+		return nil, err
+	}
+	events := make(chan *types.ClaimableEvent)
+	go func() {
+		defer close(events)
+		defer func() { _ = pubsub.Close() }()
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event types.ClaimableEvent
+				if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+					log.Debug("unmarshal claimable event failed", "payload", message.Payload, "error", err)
+					continue
+				}
+				select {
+				case events <- &event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return events, nil
+}
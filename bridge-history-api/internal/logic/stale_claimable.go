@@ -0,0 +1,115 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// summarizeClaimablesByToken aggregates the withdrawals by their layer1 and layer2 tokens, ordered by the tokens
+func summarizeClaimablesByToken(withdrawals []*orm.CrossMsg) ([]*types.StaleClaimableSummary, error) {
+	type tokenKey struct{ l1Token, l2Token string }
+	values := make(map[tokenKey]*big.Int)
+	counts := make(map[tokenKey]uint64)
+	for _, withdrawal := range withdrawals {
+		key := tokenKey{withdrawal.Layer1Token, withdrawal.Layer2Token}
+		if _, found := values[key]; !found {
+			values[key] = new(big.Int)
+		}
+		counts[key]++
+		if withdrawal.Amount == "" {
+			continue
+		}
+		amount, ok := new(big.Int).SetString(withdrawal.Amount, 10)
+		if !ok {
+			return nil, fmt.Errorf("invalid amount %s of msg %s", withdrawal.Amount, withdrawal.MsgHash)
+		}
+		values[key].Add(values[key], amount)
+	}
+
+	summaries := make([]*types.StaleClaimableSummary, 0, len(values))
+	for key, value := range values {
+		summaries = append(summaries, &types.StaleClaimableSummary{
+			L1Token: key.l1Token,
+			L2Token: key.l2Token,
+			Count:   counts[key],
+			Value:   value.String(),
+		})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].L1Token != summaries[j].L1Token {
+			return summaries[i].L1Token < summaries[j].L1Token
+		}
+		return summaries[i].L2Token < summaries[j].L2Token
+	})
+	return summaries, nil
+}
+
+// GetStaleClaimableSummary get the per token summary of the withdrawals of all addresses which are claimable but
+// not claimed, and sent more than olderThan ago. It surfaces the funds likely abandoned on the bridge.
+func (h *HistoryLogic) GetStaleClaimableSummary(ctx context.Context, olderThan time.Duration) ([]*types.StaleClaimableSummary, error) {
+	return instrument(h.metrics, "GetStaleClaimableSummary", func() ([]*types.StaleClaimableSummary, error) {
+		return h.getStaleClaimableSummary(ctx, olderThan)
+	})
+}
+
+// getStaleClaimableSummary implements GetStaleClaimableSummary
+func (h *HistoryLogic) getStaleClaimableSummary(ctx context.Context, olderThan time.Duration) ([]*types.StaleClaimableSummary, error) {
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	withdrawals, err := orm.NewCrossMsg(h.db).GetStaleClaimableWithdrawals(ctx, time.Now().Add(-olderThan))
+	if err != nil {
+		return nil, err
+	}
+	return summarizeClaimablesByToken(withdrawals)
+}
+
+// GetClaimableSummary get the counts and the values by token of the withdrawals of the address which are claimable
+// but not claimed
+func (h *HistoryLogic) GetClaimableSummary(ctx context.Context, address common.Address) (*types.ClaimableSummary, error) {
+	return instrument(h.metrics, "GetClaimableSummary", func() (*types.ClaimableSummary, error) {
+		return h.getClaimableSummary(ctx, address)
+	})
+}
+
+// getClaimableSummary implements GetClaimableSummary
+func (h *HistoryLogic) getClaimableSummary(ctx context.Context, address common.Address) (*types.ClaimableSummary, error) {
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	withdrawals, err := orm.NewCrossMsg(h.db).GetClaimableWithdrawalsByAddress(ctx, address.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if h.claimVerifier != nil {
+		msgHashes := make([]string, 0, len(withdrawals))
+		for _, withdrawal := range withdrawals {
+			msgHashes = append(msgHashes, withdrawal.MsgHash)
+		}
+		executed := h.claimVerifier.executedMsgHashes(ctx, msgHashes)
+		unclaimed := withdrawals[:0]
+		for _, withdrawal := range withdrawals {
+			if _, found := executed[withdrawal.MsgHash]; !found {
+				unclaimed = append(unclaimed, withdrawal)
+			}
+		}
+		withdrawals = unclaimed
+	}
+	tokens, err := summarizeClaimablesByToken(withdrawals)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ClaimableSummary{Address: address.Hex(), Count: uint64(len(withdrawals)), Tokens: tokens}, nil
+}
@@ -0,0 +1,35 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestSummarizeClaimablesByToken(t *testing.T) {
+	withdrawals := []*orm.CrossMsg{
+		{MsgHash: "eth1", Amount: "100"},
+		{MsgHash: "usdc1", Layer1Token: "l1usdc", Layer2Token: "l2usdc", Amount: "5"},
+		{MsgHash: "eth2", Amount: "250"},
+		{MsgHash: "nft1", Layer1Token: "l1nft", Layer2Token: "l2nft", Asset: int(orm.ERC721)},
+		{MsgHash: "usdc2", Layer1Token: "l1usdc", Layer2Token: "l2usdc", Amount: "7"},
+		{MsgHash: "nft2", Layer1Token: "l1nft", Layer2Token: "l2nft", Asset: int(orm.ERC721)},
+	}
+	summaries, err := summarizeClaimablesByToken(withdrawals)
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.StaleClaimableSummary{
+		{Count: 2, Value: "350"},
+		{L1Token: "l1nft", L2Token: "l2nft", Count: 2, Value: "0"},
+		{L1Token: "l1usdc", L2Token: "l2usdc", Count: 2, Value: "12"},
+	}, summaries)
+
+	summaries, err = summarizeClaimablesByToken(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, summaries)
+
+	_, err = summarizeClaimablesByToken([]*orm.CrossMsg{{MsgHash: "invalid", Amount: "0x10"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,185 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+const (
+	// statsDateLayout is the format of the dates of the stats apis
+	statsDateLayout = "2006-01-02"
+	// defaultStatsDays is the number of the days up to the end date returned when the start date is not given
+	defaultStatsDays = 30
+	// maxStatsDays is the upper bound of the number of the days queried at once
+	maxStatsDays = 366
+)
+
+// StatsLogic the bridge stats service, backed with the daily aggregates maintained by the fetcher
+type StatsLogic struct {
+	volumeOrm       *orm.BridgeDailyVolume
+	finalizationOrm *orm.BridgeDailyFinalization
+}
+
+// NewStatsLogic returns services backed with a "db"
+func NewStatsLogic(db *gorm.DB) *StatsLogic {
+	return &StatsLogic{
+		volumeOrm:       orm.NewBridgeDailyVolume(db),
+		finalizationOrm: orm.NewBridgeDailyFinalization(db),
+	}
+}
+
+// statsDateRange returns the inclusive range of the UTC days of the request, the end date defaults to the day of now
+// and the start date to defaultStatsDays days up to the end date
+func statsDateRange(req types.QueryStatsRequest, now time.Time) (time.Time, time.Time, error) {
+	end := now.UTC().Truncate(24 * time.Hour)
+	if req.EndDate != "" {
+		var err error
+		if end, err = time.Parse(statsDateLayout, req.EndDate); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: invalid end date %q", ErrInvalidParameter, req.EndDate)
+		}
+	}
+	start := end.AddDate(0, 0, 1-defaultStatsDays)
+	if req.StartDate != "" {
+		var err error
+		if start, err = time.Parse(statsDateLayout, req.StartDate); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: invalid start date %q", ErrInvalidParameter, req.StartDate)
+		}
+	}
+	if start.After(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: the start date %s is after the end date %s", ErrInvalidParameter, start.Format(statsDateLayout), end.Format(statsDateLayout))
+	}
+	if end.Sub(start) >= maxStatsDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: the range exceeds the allowed maximum of %d days", ErrInvalidParameter, maxStatsDays)
+	}
+	return start, end, nil
+}
+
+// msgTypeDirection returns the direction of the cross messages of the msg type
+func msgTypeDirection(msgType int) types.TxDirection {
+	if orm.MsgType(msgType) == orm.Layer1Msg {
+		return types.TxDirectionDeposit
+	}
+	return types.TxDirectionWithdraw
+}
+
+// GetDailyVolumes get the number and the bridged amount of the txs of each direction and token sent each day of the
+// range, the days and the tokens without txs are skipped
+func (s *StatsLogic) GetDailyVolumes(ctx context.Context, req *types.QueryVolumeStatsRequest) ([]*types.DailyVolume, error) {
+	start, end, err := statsDateRange(req.QueryStatsRequest, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	var token string
+	if req.Token != "" {
+		tokens, err := checksumAddresses([]string{req.Token})
+		if err != nil {
+			return nil, err
+		}
+		token = tokens[0]
+	}
+	volumes, err := s.volumeOrm.GetBridgeDailyVolumes(ctx, start, end, token)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	dailyVolumes := make([]*types.DailyVolume, 0, len(volumes))
+	for _, volume := range volumes {
+		dailyVolumes = append(dailyVolumes, &types.DailyVolume{
+			Date:      volume.Day.Format(statsDateLayout),
+			Direction: msgTypeDirection(volume.MsgType),
+			TokenType: tokenType(&orm.CrossMsg{Asset: volume.Asset, Layer1Token: volume.Token}),
+			Token:     volume.Token,
+			TxCount:   volume.TxCount,
+			Volume:    volume.Volume,
+		})
+	}
+	return dailyVolumes, nil
+}
+
+// GetDailyTxCounts get the number of the deposits and the withdrawals sent each day of the range, the days without
+// txs are returned with zero counts
+func (s *StatsLogic) GetDailyTxCounts(ctx context.Context, req *types.QueryStatsRequest) ([]*types.DailyTxCount, error) {
+	start, end, err := statsDateRange(*req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.volumeOrm.GetDailyTxCounts(ctx, start, end)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	return dailyTxCounts(start, end, counts), nil
+}
+
+// dailyTxCounts returns the counts of each day between start and end inclusive out of the counts of each day and
+// direction
+func dailyTxCounts(start, end time.Time, counts []*orm.DailyTxCount) []*types.DailyTxCount {
+	indexes := make(map[string]int)
+	var dailyCounts []*types.DailyTxCount
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format(statsDateLayout)
+		indexes[date] = len(dailyCounts)
+		dailyCounts = append(dailyCounts, &types.DailyTxCount{Date: date})
+	}
+	for _, count := range counts {
+		index, ok := indexes[count.Day.Format(statsDateLayout)]
+		if !ok {
+			continue
+		}
+		if msgTypeDirection(count.MsgType) == types.TxDirectionDeposit {
+			dailyCounts[index].Deposits += count.TxCount
+		} else {
+			dailyCounts[index].Withdrawals += count.TxCount
+		}
+	}
+	return dailyCounts
+}
+
+// GetDailyFinalizeTimes get the number of the txs of each direction relayed each day of the range and the average time
+// from their sending, the days without relays are skipped
+func (s *StatsLogic) GetDailyFinalizeTimes(ctx context.Context, req *types.QueryStatsRequest) ([]*types.DailyFinalizeTime, error) {
+	start, end, err := statsDateRange(*req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	finalizations, err := s.finalizationOrm.GetBridgeDailyFinalizations(ctx, start, end)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	finalizeTimes := make([]*types.DailyFinalizeTime, 0, len(finalizations))
+	for _, finalization := range finalizations {
+		if finalization.FinalizedCount == 0 {
+			continue
+		}
+		finalizeTimes = append(finalizeTimes, &types.DailyFinalizeTime{
+			Date:                   finalization.Day.Format(statsDateLayout),
+			Direction:              msgTypeDirection(finalization.MsgType),
+			FinalizedCount:         finalization.FinalizedCount,
+			AverageFinalizeSeconds: finalization.TotalFinalizeSeconds / finalization.FinalizedCount,
+		})
+	}
+	return finalizeTimes, nil
+}
+
+// GetPendingWithdrawals get the number of the withdrawals sent and not relayed on layer1 yet, out of the aggregated
+// withdrawals and relays
+func (s *StatsLogic) GetPendingWithdrawals(ctx context.Context) (*types.PendingWithdrawals, error) {
+	sent, err := s.volumeOrm.GetTotalTxCount(ctx, orm.Layer2Msg)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	relayed, err := s.finalizationOrm.GetTotalFinalizedCount(ctx, orm.Layer2Msg)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	pending := &types.PendingWithdrawals{}
+	// a relay may be aggregated before its withdrawal whose cursor waits for an earlier block timestamp
+	if sent > relayed {
+		pending.Count = sent - relayed
+	}
+	return pending, nil
+}
@@ -0,0 +1,105 @@
+package logic
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestStatsDateRange(t *testing.T) {
+	now := time.Date(2023, 7, 20, 15, 4, 5, 0, time.UTC)
+	day := func(month time.Month, day int) time.Time { return time.Date(2023, month, day, 0, 0, 0, 0, time.UTC) }
+
+	// the last days up to today by default
+	start, end, err := statsDateRange(types.QueryStatsRequest{}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, day(6, 21), start)
+	assert.Equal(t, day(7, 20), end)
+
+	// the default start date follows the end date
+	start, end, err = statsDateRange(types.QueryStatsRequest{EndDate: "2023-07-10"}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, day(6, 11), start)
+	assert.Equal(t, day(7, 10), end)
+
+	start, end, err = statsDateRange(types.QueryStatsRequest{StartDate: "2023-07-01", EndDate: "2023-07-01"}, now)
+	assert.NoError(t, err)
+	assert.Equal(t, day(7, 1), start)
+	assert.Equal(t, day(7, 1), end)
+
+	for _, req := range []types.QueryStatsRequest{
+		{StartDate: "2023/07/01"},
+		{EndDate: "yesterday"},
+		{StartDate: "2023-07-02", EndDate: "2023-07-01"},
+		{StartDate: "2022-01-01", EndDate: "2023-07-01"},
+	} {
+		_, _, err = statsDateRange(req, now)
+		assert.ErrorIs(t, err, ErrInvalidParameter, req)
+	}
+}
+
+func TestDailyTxCounts(t *testing.T) {
+	start := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 2)
+	counts := dailyTxCounts(start, end, []*orm.DailyTxCount{
+		{Day: start, MsgType: int(orm.Layer1Msg), TxCount: 3},
+		{Day: start, MsgType: int(orm.Layer2Msg), TxCount: 1},
+		{Day: end, MsgType: int(orm.Layer2Msg), TxCount: 2},
+	})
+	assert.Equal(t, []*types.DailyTxCount{
+		{Date: "2023-07-01", Deposits: 3, Withdrawals: 1},
+		{Date: "2023-07-02"},
+		{Date: "2023-07-03", Withdrawals: 2},
+	}, counts)
+}
+
+func TestGetDailyVolumes(t *testing.T) {
+	day := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	token := "0x5300000000000000000000000000000000000004"
+	db, counter := newCountingDB(t, map[string]interface{}{
+		(&orm.BridgeDailyVolume{}).TableName(): []*orm.BridgeDailyVolume{
+			{Day: day, MsgType: int(orm.Layer1Msg), Asset: int(orm.ETH), TxCount: 2, Volume: "3000"},
+			{Day: day, MsgType: int(orm.Layer2Msg), Asset: int(orm.ERC20), Token: token, TxCount: 1, Volume: "10"},
+			{Day: day, MsgType: int(orm.Layer2Msg), Asset: int(orm.ERC721), Token: token, TxCount: 1, Volume: "0"},
+		},
+	})
+	volumes, err := NewStatsLogic(db).GetDailyVolumes(context.Background(), &types.QueryVolumeStatsRequest{
+		QueryStatsRequest: types.QueryStatsRequest{StartDate: "2023-07-01", EndDate: "2023-07-01"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.DailyVolume{
+		{Date: "2023-07-01", Direction: types.TxDirectionDeposit, TokenType: types.TokenTypeETH, TxCount: 2, Volume: "3000"},
+		{Date: "2023-07-01", Direction: types.TxDirectionWithdraw, TokenType: types.TokenTypeERC20, Token: token, TxCount: 1, Volume: "10"},
+		{Date: "2023-07-01", Direction: types.TxDirectionWithdraw, TokenType: types.TokenTypeERC721, Token: token, TxCount: 1, Volume: "0"},
+	}, volumes)
+
+	// the token is checksummed before being queried
+	_, err = NewStatsLogic(db).GetDailyVolumes(context.Background(), &types.QueryVolumeStatsRequest{Token: "0xffffffffffffffffffffffffffffffffffffffff"})
+	assert.NoError(t, err)
+	assert.Contains(t, counter.vars["(*BridgeDailyVolume).GetBridgeDailyVolumes"], "0xFFfFfFffFFfffFFfFFfFFFFFffFFFffffFfFFFfF")
+
+	_, err = NewStatsLogic(db).GetDailyVolumes(context.Background(), &types.QueryVolumeStatsRequest{Token: "eth"})
+	assert.ErrorIs(t, err, ErrInvalidParameter)
+}
+
+func TestGetDailyFinalizeTimes(t *testing.T) {
+	day := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	db, _ := newCountingDB(t, map[string]interface{}{
+		(&orm.BridgeDailyFinalization{}).TableName(): []*orm.BridgeDailyFinalization{
+			{Day: day, MsgType: int(orm.Layer1Msg), FinalizedCount: 4, TotalFinalizeSeconds: 1000},
+			{Day: day, MsgType: int(orm.Layer2Msg), FinalizedCount: 0},
+			{Day: day, MsgType: int(orm.Layer2Msg), FinalizedCount: 2, TotalFinalizeSeconds: 7200},
+		},
+	})
+	finalizeTimes, err := NewStatsLogic(db).GetDailyFinalizeTimes(context.Background(), &types.QueryStatsRequest{StartDate: "2023-07-01", EndDate: "2023-07-01"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.DailyFinalizeTime{
+		{Date: "2023-07-01", Direction: types.TxDirectionDeposit, FinalizedCount: 4, AverageFinalizeSeconds: 250},
+		{Date: "2023-07-01", Direction: types.TxDirectionWithdraw, FinalizedCount: 2, AverageFinalizeSeconds: 3600},
+	}, finalizeTimes)
+}
@@ -0,0 +1,102 @@
+package logic
+
+import (
+	"context"
+	"math/big"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+const (
+	// ethSymbol the symbol of native ETH
+	ethSymbol = "ETH"
+	// ethDecimals the decimals of native ETH
+	ethDecimals uint8 = 18
+)
+
+// updateTokenMetadata decorates the tx histories of ETH and the ERC20 tokens with the symbol, the decimals and the logo
+// of the bridged token and the amount in token units. An ERC20 token takes the metadata of the token on the source layer,
+// or of its counterpart if unknown. The NFTs are left as is.
+func updateTokenMetadata(ctx context.Context, txHistories []*types.TxHistoryInfo, db *gorm.DB) error {
+	tokens := map[orm.MsgType][]string{}
+	seen := map[orm.MsgType]map[string]struct{}{orm.Layer1Msg: {}, orm.Layer2Msg: {}}
+	addToken := func(layer orm.MsgType, token string) {
+		if isEmptyToken(token) {
+			return
+		}
+		if _, exists := seen[layer][token]; !exists {
+			seen[layer][token] = struct{}{}
+			tokens[layer] = append(tokens[layer], token)
+		}
+	}
+	for _, txHistory := range txHistories {
+		if txHistory.TokenType == types.TokenTypeERC20 {
+			addToken(orm.Layer1Msg, txHistory.L1Token)
+			addToken(orm.Layer2Msg, txHistory.L2Token)
+		}
+	}
+
+	metadataMap := map[orm.MsgType]map[string]*orm.TokenMetadata{orm.Layer1Msg: {}, orm.Layer2Msg: {}}
+	tokenMetadataOrm := orm.NewTokenMetadata(db)
+	for layer, addresses := range tokens {
+		err := forEachChunk(len(addresses), func(start, end int) error {
+			metadata, err := tokenMetadataOrm.GetTokenMetadataByAddresses(ctx, layer, addresses[start:end])
+			for _, tokenMetadata := range metadata {
+				metadataMap[layer][tokenMetadata.Address] = tokenMetadata
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, txHistory := range txHistories {
+		switch txHistory.TokenType {
+		case types.TokenTypeETH:
+			decimals := ethDecimals
+			txHistory.TokenSymbol = ethSymbol
+			txHistory.TokenDecimals = &decimals
+		case types.TokenTypeERC20:
+			source, counterpart := metadataMap[orm.Layer1Msg][txHistory.L1Token], metadataMap[orm.Layer2Msg][txHistory.L2Token]
+			if !txHistory.IsL1 {
+				source, counterpart = counterpart, source
+			}
+			if source == nil {
+				source = counterpart
+			}
+			if source == nil {
+				continue
+			}
+			decimals := source.Decimals
+			txHistory.TokenSymbol = source.Symbol
+			txHistory.TokenDecimals = &decimals
+			txHistory.TokenLogoURI = source.LogoURI
+		default:
+			continue
+		}
+		txHistory.FormattedAmount = formatAmount(txHistory.Amount, *txHistory.TokenDecimals)
+	}
+	return nil
+}
+
+// formatAmount formats the amount in the smallest unit of a token of the decimals in token units, the trailing zeros
+// of the fraction are trimmed. Empty is returned if the amount isn't a non negative integer.
+func formatAmount(amount string, decimals uint8) string {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok || value.Sign() < 0 {
+		return ""
+	}
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	quotient, remainder := new(big.Int).QuoRem(value, unit, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient.String()
+	}
+	fraction := remainder.String()
+	fraction = strings.Repeat("0", int(decimals)-len(fraction)) + fraction
+	return quotient.String() + "." + strings.TrimRight(fraction, "0")
+}
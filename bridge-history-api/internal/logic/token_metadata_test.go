@@ -0,0 +1,53 @@
+package logic
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestFormatAmount(t *testing.T) {
+	assert.Equal(t, "1.5", formatAmount("1500000000000000000", 18))
+	assert.Equal(t, "0.000001", formatAmount("1", 6))
+	assert.Equal(t, "12", formatAmount("12000000", 6))
+	assert.Equal(t, "0", formatAmount("0", 18))
+	assert.Equal(t, "42", formatAmount("42", 0))
+	assert.Equal(t, "", formatAmount("", 18))
+	assert.Equal(t, "", formatAmount("-1", 18))
+}
+
+func TestUpdateTokenMetadata(t *testing.T) {
+	usdcL1, usdcL2 := "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", "0x06eFdBFf2a14a7c8E15944D1F4A48F9F95F663A4"
+	db, _ := newCountingDB(t, map[string]interface{}{
+		(&orm.TokenMetadata{}).TableName(): []*orm.TokenMetadata{
+			{Layer: int(orm.Layer1Msg), Address: usdcL1, Symbol: "USDC", Decimals: 6, LogoURI: "https://logo/usdc.svg"},
+		},
+	})
+	txHistories := []*types.TxHistoryInfo{
+		{IsL1: true, TokenType: types.TokenTypeETH, Amount: "1500000000000000000"},
+		// the withdrawal takes the metadata of the layer1 token since its layer2 token has none
+		{IsL1: false, TokenType: types.TokenTypeERC20, L1Token: usdcL1, L2Token: usdcL2, Amount: "2500000"},
+		{IsL1: true, TokenType: types.TokenTypeERC20, L1Token: "0x0000000000000000000000000000000000000abc", Amount: "1"},
+		{IsL1: true, TokenType: types.TokenTypeERC721, L1Token: usdcL1, Amount: "0"},
+	}
+	assert.NoError(t, updateTokenMetadata(context.Background(), txHistories, db))
+
+	assert.Equal(t, "ETH", txHistories[0].TokenSymbol)
+	assert.Equal(t, uint8(18), *txHistories[0].TokenDecimals)
+	assert.Equal(t, "1.5", txHistories[0].FormattedAmount)
+
+	assert.Equal(t, "USDC", txHistories[1].TokenSymbol)
+	assert.Equal(t, uint8(6), *txHistories[1].TokenDecimals)
+	assert.Equal(t, "https://logo/usdc.svg", txHistories[1].TokenLogoURI)
+	assert.Equal(t, "2.5", txHistories[1].FormattedAmount)
+
+	for _, txHistory := range txHistories[2:] {
+		assert.Empty(t, txHistory.TokenSymbol)
+		assert.Nil(t, txHistory.TokenDecimals)
+		assert.Empty(t, txHistory.FormattedAmount)
+	}
+}
@@ -0,0 +1,55 @@
+package logic
+
+import (
+	"math/big"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// sendTxFees returns the fees of the tx of the hash sending the cross message, nil if its gas fee is unknown. The
+// layer1 data fee is left out of the withdrawals indexed before it's indexed.
+func sendTxFees(crossMsg *orm.CrossMsg, txHash string, isL1 bool) *types.TxFees {
+	gasFee, ok := parseWei(crossMsg.GasFee)
+	if !ok {
+		return nil
+	}
+	bridgeFee, ok := parseWei(crossMsg.BridgeFee)
+	if !ok {
+		bridgeFee = new(big.Int)
+	}
+	send := &types.LegFees{TxHash: txHash, IsL1: isL1, GasFee: feeAmount(gasFee), BridgeFee: feeAmount(bridgeFee)}
+	total := new(big.Int).Add(gasFee, bridgeFee)
+	if l1DataFee, found := parseWei(crossMsg.L1DataFee); found && !isL1 {
+		send.L1DataFee = feeAmount(l1DataFee)
+		total.Add(total, l1DataFee)
+	}
+	send.Total = feeAmount(total)
+	return &types.TxFees{Send: send, Total: feeAmount(total)}
+}
+
+// addClaimTxFees adds the gas fee of the layer1 tx of the hash claiming the withdrawal to its fees, nothing is added if
+// the fees or the gas fee are unknown
+func addClaimTxFees(fees *types.TxFees, txHash string, gasFeeWei string) {
+	gasFee, ok := parseWei(gasFeeWei)
+	if fees == nil || !ok {
+		return
+	}
+	fees.Claim = &types.LegFees{TxHash: txHash, IsL1: true, GasFee: feeAmount(gasFee), BridgeFee: feeAmount(new(big.Int)), Total: feeAmount(gasFee)}
+	total, _ := parseWei(fees.Send.Total.Wei)
+	fees.Total = feeAmount(total.Add(total, gasFee))
+}
+
+// parseWei parses the amount in wei stored as a decimal string, false if it's empty or invalid
+func parseWei(wei string) (*big.Int, bool) {
+	amount, ok := new(big.Int).SetString(wei, 10)
+	if !ok || amount.Sign() < 0 {
+		return nil, false
+	}
+	return amount, true
+}
+
+// feeAmount returns the amount in wei along with the amount in ETH
+func feeAmount(wei *big.Int) *types.FeeAmount {
+	return &types.FeeAmount{Wei: wei.String(), ETH: formatAmount(wei.String(), ethDecimals)}
+}
@@ -0,0 +1,53 @@
+package logic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestSendTxFees(t *testing.T) {
+	// a deposit pays the gas of the layer1 tx and the fee of the message queue
+	fees := sendTxFees(&orm.CrossMsg{GasFee: "210000000000000", BridgeFee: "50000000000000", L1DataFee: "1"}, "0x01", true)
+	assert.Equal(t, &types.TxFees{
+		Send: &types.LegFees{
+			TxHash:    "0x01",
+			IsL1:      true,
+			GasFee:    &types.FeeAmount{Wei: "210000000000000", ETH: "0.00021"},
+			BridgeFee: &types.FeeAmount{Wei: "50000000000000", ETH: "0.00005"},
+			Total:     &types.FeeAmount{Wei: "260000000000000", ETH: "0.00026"},
+		},
+		Total: &types.FeeAmount{Wei: "260000000000000", ETH: "0.00026"},
+	}, fees)
+
+	// a withdrawal also pays the layer1 data fee of the layer2 tx, then the gas of the claim
+	fees = sendTxFees(&orm.CrossMsg{GasFee: "1000", BridgeFee: "0", L1DataFee: "500"}, "0x02", false)
+	assert.Equal(t, &types.FeeAmount{Wei: "500", ETH: "0.0000000000000005"}, fees.Send.L1DataFee)
+	assert.Equal(t, "1500", fees.Total.Wei)
+	addClaimTxFees(fees, "0x03", "1000000000000000000")
+	assert.Equal(t, &types.LegFees{
+		TxHash:    "0x03",
+		IsL1:      true,
+		GasFee:    &types.FeeAmount{Wei: "1000000000000000000", ETH: "1"},
+		BridgeFee: &types.FeeAmount{Wei: "0", ETH: "0"},
+		Total:     &types.FeeAmount{Wei: "1000000000000000000", ETH: "1"},
+	}, fees.Claim)
+	assert.Equal(t, &types.FeeAmount{Wei: "1000000000000001500", ETH: "1.0000000000000015"}, fees.Total)
+
+	// the data fee indexed before the column is left out
+	fees = sendTxFees(&orm.CrossMsg{GasFee: "1000", BridgeFee: "0"}, "0x02", false)
+	assert.Nil(t, fees.Send.L1DataFee)
+	assert.Equal(t, "1000", fees.Total.Wei)
+
+	// the fees are unknown without the gas fee, no claim is added then
+	fees = sendTxFees(&orm.CrossMsg{}, "0x02", false)
+	assert.Nil(t, fees)
+	addClaimTxFees(fees, "0x03", "1000")
+	fees = sendTxFees(&orm.CrossMsg{GasFee: "1000"}, "0x02", false)
+	addClaimTxFees(fees, "0x03", "")
+	assert.Nil(t, fees.Claim)
+	assert.Equal(t, "1000", fees.Total.Wei)
+}
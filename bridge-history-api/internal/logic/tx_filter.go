@@ -0,0 +1,140 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// maxTxFilterValues is the maximum number of the values of each list filter of a tx filter
+const maxTxFilterValues = 10
+
+// txStatusMsgStatuses maps the tx statuses to the statuses of the orm filters
+var txStatusMsgStatuses = map[types.TxStatus]orm.MsgStatus{
+	types.TxStatusPending:   orm.MsgStatusPending,
+	types.TxStatusRelayed:   orm.MsgStatusRelayed,
+	types.TxStatusFailed:    orm.MsgStatusFailed,
+	types.TxStatusDropped:   orm.MsgStatusDropped,
+	types.TxStatusRefunded:  orm.MsgStatusRefunded,
+	types.TxStatusClaimable: orm.MsgStatusClaimable,
+	types.TxStatusClaimed:   orm.MsgStatusClaimed,
+}
+
+// directionMsgType returns the type of the messages of the direction, UnknownMsg matches both directions
+func directionMsgType(direction types.TxDirection) (orm.MsgType, error) {
+	switch direction {
+	case types.TxDirectionAll:
+		return orm.UnknownMsg, nil
+	case types.TxDirectionDeposit:
+		return orm.Layer1Msg, nil
+	case types.TxDirectionWithdraw:
+		return orm.Layer2Msg, nil
+	default:
+		return orm.UnknownMsg, fmt.Errorf("%w: unknown direction %q", ErrInvalidParameter, direction)
+	}
+}
+
+// newMsgFilter validates the tx filter and converts it into the filter of the orm queries, the addresses and the
+// tokens are checksummed and the tx hashes are normalized the way they are stored
+func newMsgFilter(filter types.TxFilter) (*orm.MsgFilter, error) {
+	if len(filter.Addresses) == 0 && len(filter.Txs) == 0 {
+		return nil, fmt.Errorf("%w: at least one address or tx hash is required", ErrInvalidParameter)
+	}
+	for _, list := range []struct {
+		name  string
+		count int
+	}{{"addresses", len(filter.Addresses)}, {"txs", len(filter.Txs)}, {"tokens", len(filter.Tokens)}, {"statuses", len(filter.Statuses)}} {
+		if list.count > maxTxFilterValues {
+			return nil, fmt.Errorf("%w: the number of %s exceeds the allowed maximum of %d", ErrInvalidParameter, list.name, maxTxFilterValues)
+		}
+	}
+
+	msgFilter := &orm.MsgFilter{}
+	var err error
+	if msgFilter.Addresses, err = checksumAddresses(filter.Addresses); err != nil {
+		return nil, err
+	}
+	if msgFilter.Tokens, err = checksumAddresses(filter.Tokens); err != nil {
+		return nil, err
+	}
+	if msgFilter.TxHashes, err = normalizeTxHashes(filter.Txs); err != nil {
+		return nil, err
+	}
+	if msgFilter.MsgType, err = directionMsgType(filter.Direction); err != nil {
+		return nil, err
+	}
+	for _, status := range filter.Statuses {
+		msgStatus, ok := txStatusMsgStatuses[status]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidParameter, status)
+		}
+		msgFilter.Statuses = append(msgFilter.Statuses, msgStatus)
+	}
+	if filter.StartTime != nil && filter.EndTime != nil && *filter.StartTime > *filter.EndTime {
+		return nil, fmt.Errorf("%w: the start time %d is after the end time %d", ErrInvalidParameter, *filter.StartTime, *filter.EndTime)
+	}
+	msgFilter.Range = txRangeToMsgRange(types.TxRange{From: filter.StartTime, To: filter.EndTime, ByTimestamp: true})
+	return msgFilter, nil
+}
+
+// checksumAddresses returns the addresses in the checksummed form stored in the db, an error is returned if any of
+// them is invalid
+func checksumAddresses(addresses []string) ([]string, error) {
+	checksummed := make([]string, 0, len(addresses))
+	for _, address := range addresses {
+		if !common.IsHexAddress(address) {
+			return nil, fmt.Errorf("%w: invalid address %q", ErrInvalidParameter, address)
+		}
+		checksummed = append(checksummed, common.HexToAddress(address).Hex())
+	}
+	return checksummed, nil
+}
+
+// GetTxsByFilter get the deposits and withdrawals matching the filter sorted by block timestamp in the given order, the
+// pagination is applied on the matched set. The statuses are matched in the db, where a withdrawal is claimable once its
+// proof is generated.
+func (h *HistoryLogic) GetTxsByFilter(ctx context.Context, filter types.TxFilter, order types.SortOrder, pagination types.Pagination) ([]*types.TxHistoryInfo, uint64, error) {
+	return instrumentPage(h.metrics, "GetTxsByFilter", func() ([]*types.TxHistoryInfo, uint64, error) {
+		return h.getTxsByFilter(ctx, filter, order, pagination)
+	})
+}
+
+// getTxsByFilter implements GetTxsByFilter
+func (h *HistoryLogic) getTxsByFilter(ctx context.Context, filter types.TxFilter, order types.SortOrder, pagination types.Pagination) ([]*types.TxHistoryInfo, uint64, error) {
+	msgFilter, err := newMsgFilter(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	ormOrder, err := sortOrder(order)
+	if err != nil {
+		return nil, 0, err
+	}
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err = ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	crossMsgOrm := h.crossMsgOrm()
+	total, err := crossMsgOrm.GetTotalMsgCountByFilter(ctx, msgFilter)
+	if err != nil || total == 0 {
+		return nil, 0, err
+	}
+
+	offset, limit := getOffsetLimit(pagination)
+	results, err := crossMsgOrm.GetMsgsByFilterWithOffset(ctx, msgFilter, ormOrder, offset, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	txHistories := h.crossMsgsToTxHistoryInfos(results)
+
+	if err := updateCrossTxHashesAndL2TxClaimInfo(ctx, txHistories, h.db, h.depositCreditFinality, h.claimLookups); err != nil {
+		return nil, 0, err
+	}
+	h.finishTxHistories(ctx, txHistories)
+	return txHistories, total, nil
+}
@@ -0,0 +1,47 @@
+package logic
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestNewMsgFilter(t *testing.T) {
+	start, end := uint64(100), uint64(200)
+	filter, err := newMsgFilter(types.TxFilter{
+		Addresses: []string{"0x1c5a77d9fa7ef466951b2f01f724bca3a5820b63"},
+		Txs:       []string{"0X" + "AB" + "00000000000000000000000000000000000000000000000000000000000000"},
+		Tokens:    []string{"0x1c5a77d9fa7ef466951b2f01f724bca3a5820b63"},
+		Direction: types.TxDirectionWithdraw,
+		Statuses:  []types.TxStatus{types.TxStatusClaimable, types.TxStatusClaimed},
+		StartTime: &start,
+		EndTime:   &end,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, &orm.MsgFilter{
+		Addresses: []string{"0x1C5A77d9FA7eF466951B2F01F724BCa3A5820b63"},
+		TxHashes:  []string{"0xab00000000000000000000000000000000000000000000000000000000000000"},
+		Tokens:    []string{"0x1C5A77d9FA7eF466951B2F01F724BCa3A5820b63"},
+		MsgType:   orm.Layer2Msg,
+		Statuses:  []orm.MsgStatus{orm.MsgStatusClaimable, orm.MsgStatusClaimed},
+		Range:     &orm.MsgRange{From: &start, To: &end, ByTimestamp: true},
+	}, filter)
+
+	address := "0x1c5a77d9fa7ef466951b2f01f724bca3a5820b63"
+	for _, invalid := range []types.TxFilter{
+		{},
+		{Tokens: []string{address}},
+		{Addresses: []string{"0x01"}},
+		{Addresses: []string{address}, Direction: "sideways"},
+		{Addresses: []string{address}, Statuses: []types.TxStatus{"Lost"}},
+		{Addresses: []string{address}, StartTime: &end, EndTime: &start},
+		{Addresses: make([]string, maxTxFilterValues+1)},
+	} {
+		_, err = newMsgFilter(invalid)
+		assert.True(t, errors.Is(err, ErrInvalidParameter), "%+v", invalid)
+	}
+}
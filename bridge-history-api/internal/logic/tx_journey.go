@@ -0,0 +1,106 @@
+package logic
+
+import (
+	"context"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// newTxJourney builds the timeline of a message. For a withdrawal l2sentMsg is set and crossMsg is its layer2 cross
+// message if any, for a deposit crossMsg is the layer1 cross message and l2sentMsg is nil. batch and relayedMsg are
+// nil until the message is committed in a batch and relayed on the target layer.
+func newTxJourney(crossMsg *orm.CrossMsg, l2sentMsg *orm.L2SentMsg, batch *orm.RollupBatch, relayedMsg *orm.RelayedMsg) *types.TxJourney {
+	journey := &types.TxJourney{}
+	if l2sentMsg != nil {
+		journey.MsgHash = l2sentMsg.MsgHash
+		journey.Origin = &types.JourneyStage{TxHash: l2sentMsg.TxHash, BlockNumber: l2sentMsg.Height}
+		if crossMsg != nil {
+			journey.Origin.BlockTimestamp = crossMsg.Timestamp
+		}
+		if batch != nil && l2sentMsg.BatchIndex != 0 {
+			journey.Batched = &types.JourneyStage{TxHash: batch.CommitTxHash, BlockNumber: batch.CommitHeight}
+			if batch.IsFinalized() {
+				journey.Finalized = &types.JourneyStage{
+					TxHash:         batch.FinalizeTxHash,
+					BlockNumber:    batch.FinalizeHeight,
+					BlockTimestamp: batch.FinalizeTimestamp,
+				}
+			}
+		}
+	} else {
+		journey.MsgHash = crossMsg.MsgHash
+		journey.IsL1 = true
+		journey.Origin = &types.JourneyStage{
+			TxHash:         crossMsg.Layer1Hash,
+			BlockNumber:    crossMsg.Height,
+			BlockTimestamp: crossMsg.Timestamp,
+		}
+	}
+	if relayedMsg != nil {
+		journey.Claimed = &types.JourneyStage{
+			TxHash:         txHashOnLayer(relayedMsg.Layer1Hash, relayedMsg.Layer2Hash, !journey.IsL1),
+			BlockNumber:    relayedMsg.Height,
+			BlockTimestamp: relayedMsg.Timestamp,
+		}
+	}
+	return journey
+}
+
+// GetTxJourney get the timeline of the message across both layers, nil is returned if the message is not found
+func (h *HistoryLogic) GetTxJourney(ctx context.Context, msgHash string) (*types.TxJourney, error) {
+	return instrument(h.metrics, "GetTxJourney", func() (*types.TxJourney, error) {
+		return h.getTxJourney(ctx, msgHash)
+	})
+}
+
+// getTxJourney implements GetTxJourney
+func (h *HistoryLogic) getTxJourney(ctx context.Context, msgHash string) (*types.TxJourney, error) {
+	ctx, cancel := h.withQueryTimeout(ctx)
+	defer cancel()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	crossMsgOrm := orm.NewCrossMsg(h.db)
+	l2sentMsgs, err := orm.NewL2SentMsg(h.db).GetL2SentMsgsByHashes(ctx, []string{msgHash})
+	if err != nil {
+		return nil, err
+	}
+
+	var crossMsgs []*orm.CrossMsg
+	var l2sentMsg *orm.L2SentMsg
+	var batch *orm.RollupBatch
+	if len(l2sentMsgs) > 0 {
+		l2sentMsg = l2sentMsgs[0]
+		crossMsgs, err = crossMsgOrm.GetL2CrossMsgByMsgHashList(ctx, []string{msgHash})
+		if err != nil {
+			return nil, err
+		}
+		batches, err := orm.NewRollupBatch(h.db).GetRollupBatchesByIndexes(ctx, []uint64{l2sentMsg.BatchIndex})
+		if err != nil {
+			return nil, err
+		}
+		// the batch index is stale if the batch is reverted and the message is not committed again yet
+		if len(batches) > 0 && batches[0].ContainsBlock(l2sentMsg.Height) {
+			batch = batches[0]
+		}
+	} else {
+		crossMsgs, err = crossMsgOrm.GetL1CrossMsgByMsgHashList(ctx, []string{msgHash})
+		if err != nil {
+			return nil, err
+		}
+		if len(crossMsgs) == 0 {
+			return nil, nil
+		}
+	}
+	var crossMsg *orm.CrossMsg
+	if len(crossMsgs) > 0 {
+		crossMsg = crossMsgs[0]
+	}
+
+	relayedMsg, err := orm.NewRelayedMsg(h.db).GetRelayedMsgByHash(ctx, msgHash)
+	if err != nil {
+		return nil, err
+	}
+	return newTxJourney(crossMsg, l2sentMsg, batch, relayedMsg), nil
+}
@@ -0,0 +1,59 @@
+package logic
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+func TestNewTxJourney(t *testing.T) {
+	sentAt := time.Unix(1000, 0)
+	finalizedAt := time.Unix(2000, 0)
+	claimedAt := time.Unix(3000, 0)
+
+	// a fully completed withdrawal
+	l2sentMsg := &orm.L2SentMsg{MsgHash: "msghash", TxHash: "l2hash", Height: 15, BatchIndex: 3}
+	crossMsg := &orm.CrossMsg{MsgHash: "msghash", Layer2Hash: "l2hash", Height: 15, Timestamp: &sentAt, MsgType: int(orm.Layer2Msg)}
+	batch := &orm.RollupBatch{
+		BatchIndex:        3,
+		CommitHeight:      100,
+		CommitTxHash:      "commithash",
+		StartBlockNumber:  10,
+		EndBlockNumber:    20,
+		FinalizeHeight:    110,
+		FinalizeTxHash:    "finalizehash",
+		FinalizeTimestamp: &finalizedAt,
+	}
+	relayedMsg := &orm.RelayedMsg{MsgHash: "msghash", Layer1Hash: "claimhash", Height: 120, Timestamp: &claimedAt}
+	journey := newTxJourney(crossMsg, l2sentMsg, batch, relayedMsg)
+	assert.Equal(t, &types.TxJourney{
+		MsgHash:   "msghash",
+		IsL1:      false,
+		Origin:    &types.JourneyStage{TxHash: "l2hash", BlockNumber: 15, BlockTimestamp: &sentAt},
+		Batched:   &types.JourneyStage{TxHash: "commithash", BlockNumber: 100},
+		Finalized: &types.JourneyStage{TxHash: "finalizehash", BlockNumber: 110, BlockTimestamp: &finalizedAt},
+		Claimed:   &types.JourneyStage{TxHash: "claimhash", BlockNumber: 120, BlockTimestamp: &claimedAt},
+	}, journey)
+
+	// a withdrawal committed but not finalized, sent by calling the messenger directly
+	batch.FinalizeHeight, batch.FinalizeTxHash, batch.FinalizeTimestamp = 0, "", nil
+	journey = newTxJourney(nil, l2sentMsg, batch, nil)
+	assert.Nil(t, journey.Origin.BlockTimestamp)
+	assert.NotNil(t, journey.Batched)
+	assert.Nil(t, journey.Finalized)
+	assert.Nil(t, journey.Claimed)
+
+	// a deposit executed on layer2
+	deposit := &orm.CrossMsg{MsgHash: "deposithash", Layer1Hash: "l1hash", Height: 5, Timestamp: &sentAt, MsgType: int(orm.Layer1Msg)}
+	relayedMsg = &orm.RelayedMsg{MsgHash: "deposithash", Layer2Hash: "executehash", Height: 50}
+	journey = newTxJourney(deposit, nil, nil, relayedMsg)
+	assert.True(t, journey.IsL1)
+	assert.Equal(t, &types.JourneyStage{TxHash: "l1hash", BlockNumber: 5, BlockTimestamp: &sentAt}, journey.Origin)
+	assert.Nil(t, journey.Batched)
+	assert.Nil(t, journey.Finalized)
+	assert.Equal(t, &types.JourneyStage{TxHash: "executehash", BlockNumber: 50}, journey.Claimed)
+}
@@ -0,0 +1,228 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// ormCallCounter counts the queries issued by each ORM method and answers them with the fixture rows of the table.
+type ormCallCounter struct {
+	mu       sync.Mutex
+	calls    map[string]int
+	vars     map[string][]interface{} // the query vars of the latest call of each ORM method
+	fixtures map[string]interface{}
+}
+
+// newCountingDB returns a dry run db, which never connects to a database, answering the queries from the fixtures
+// keyed by the table name.
+func newCountingDB(t testing.TB, fixtures map[string]interface{}) (*gorm.DB, *ormCallCounter) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter := &ormCallCounter{calls: make(map[string]int), vars: make(map[string][]interface{}), fixtures: fixtures}
+	if err = db.Callback().Query().After("gorm:query").Register("test:count_orm_calls", counter.onQuery); err != nil {
+		t.Fatal(err)
+	}
+	return db, counter
+}
+
+func (c *ormCallCounter) onQuery(db *gorm.DB) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	caller := ormCaller()
+	c.calls[caller]++
+	c.vars[caller] = db.Statement.Vars
+
+	fixture, ok := c.fixtures[db.Statement.Table]
+	if !ok {
+		return
+	}
+	rows := reflect.ValueOf(fixture)
+	dest := reflect.ValueOf(db.Statement.Dest).Elem()
+	switch {
+	case rows.Type().AssignableTo(dest.Type()):
+		dest.Set(rows)
+		db.RowsAffected = int64(rows.Len())
+	case rows.Len() > 0 && rows.Index(0).Elem().Type() == dest.Type():
+		dest.Set(rows.Index(0).Elem())
+	}
+}
+
+// ormCaller returns the name of the orm method on the call stack
+func ormCaller() string {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(0, pcs)])
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, "bridge-history-api/orm.(") {
+			return strings.TrimPrefix(frame.Function, "bridge-history-api/orm.")
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+func txsByHashesFixtures() map[string]interface{} {
+	return map[string]interface{}{
+		(&orm.CrossMsg{}).TableName(): []*orm.CrossMsg{
+			{MsgHash: "0xa1", Layer1Hash: "0x01", MsgType: int(orm.Layer1Msg), Amount: "1", MsgSender: "0x21", MsgValue: "1", GasFee: "21000", BridgeFee: "5"},
+			{MsgHash: "0xb1", Layer2Hash: "0x02", MsgType: int(orm.Layer2Msg), Amount: "2", GasFee: "42000", BridgeFee: "0", L1DataFee: "1000"},
+		},
+		(&orm.RelayedMsg{}).TableName(): []*orm.RelayedMsg{
+			{MsgHash: "0xb1", Layer1Hash: "0x03", Height: 20, GasFee: "63000"},
+		},
+		(&orm.FailedRelayedMsg{}).TableName(): []*orm.FailedRelayedMsg{
+			{MsgHash: "0xa1", Layer2Hash: "0x04", Height: 10},
+		},
+		(&orm.L2SentMsg{}).TableName(): []*orm.L2SentMsg{
+			{MsgHash: "0xb1", TxHash: "0x02", Height: 100, BatchIndex: 1, MsgProof: "abcd"},
+		},
+		(&orm.RollupBatch{}).TableName(): []*orm.RollupBatch{
+			{BatchIndex: 1, StartBlockNumber: 90, EndBlockNumber: 99, FinalizeHeight: 15},
+		},
+	}
+}
+
+func TestGetTxsByHashesQueriesEachOrmMethodOnce(t *testing.T) {
+	db, counter := newCountingDB(t, txsByHashesFixtures())
+	txHistories, err := (&HistoryLogic{db: db}).GetTxsByHashes(context.Background(), []string{common.HexToHash("0x01").Hex(), common.HexToHash("0x02").Hex()}, types.TokenTypeAll, types.SortOrderDesc)
+	assert.NoError(t, err)
+	assert.Len(t, txHistories, 2)
+
+	// every enrichment ran on the fixtures
+	assert.Equal(t, types.OperationTypeDepositFailed, txHistories[0].OperationType)
+	assert.NotNil(t, txHistories[0].ExecuteParams)
+	assert.Equal(t, "0x03", txHistories[1].FinalizeTx.Hash)
+	assert.NotNil(t, txHistories[1].ClaimInfo)
+	assert.Equal(t, types.ClaimStatusClaimed, txHistories[1].ClaimStatus)
+	assert.Equal(t, types.TxStatusFailed, txHistories[0].Status)
+	assert.Equal(t, types.TxStatusClaimed, txHistories[1].Status)
+	assert.Equal(t, "21000", txHistories[0].GasFee)
+	assert.Equal(t, "5", txHistories[0].BridgeFee)
+	assert.Equal(t, "42000", txHistories[1].GasFee)
+	assert.Equal(t, "63000", txHistories[1].FinalizeTx.GasFee)
+	assert.Equal(t, "21005", txHistories[0].Fees.Total.Wei)
+	assert.Nil(t, txHistories[0].Fees.Claim)
+	assert.Equal(t, "1000", txHistories[1].Fees.Send.L1DataFee.Wei)
+	assert.Equal(t, "0x03", txHistories[1].Fees.Claim.TxHash)
+	assert.Equal(t, "106000", txHistories[1].Fees.Total.Wei)
+
+	assert.Equal(t, map[string]int{
+		"(*CrossMsg).GetCrossMsgsByHashes":                 1,
+		"(*RelayedMsg).GetRelayedMsgsByHashes":             1,
+		"(*L2SentMsg).GetL2SentMsgsByHashes":               1,
+		"(*RollupBatch).GetRollupBatchesByIndexes":         1,
+		"(*RollupBatch).GetRevertedRollupBatchesByIndexes": 1,
+		"(*RollupBatch).GetLatestRollupBatch":              1,
+		"(*FailedRelayedMsg).GetFailedRelayedMsgsByHashes": 1,
+		"(*CrossMsg).GetL1CrossMsgByMsgHashList":           1,
+		"(*L1BlockTag).GetL1BlockTagNumbers":               1,
+		"(*ReplayedMsg).GetReplayedMsgsByHashes":           1,
+		"(*DroppedMsg).GetDroppedMsgsByHashes":             1,
+	}, counter.calls)
+}
+
+func TestMsgHashesQueriedOnce(t *testing.T) {
+	db, counter := newCountingDB(t, nil)
+	txHistories := []*types.TxHistoryInfo{
+		{MsgHash: "0xb1", FinalizeTx: &types.Finalized{}},
+		{MsgHash: "0xb1", FinalizeTx: &types.Finalized{}},
+		// sent by calling the contract directly
+		{MsgHash: "", FinalizeTx: &types.Finalized{}},
+	}
+	assert.NoError(t, updateCrossTxHashesAndL2TxClaimInfo(context.Background(), txHistories, db, "", nil))
+	assert.Equal(t, []interface{}{"0xb1"}, counter.vars["(*RelayedMsg).GetRelayedMsgsByHashes"])
+	assert.Equal(t, []interface{}{"0xb1"}, counter.vars["(*L2SentMsg).GetL2SentMsgsByHashes"])
+	assert.Equal(t, []interface{}{"0xb1"}, counter.vars["(*FailedRelayedMsg).GetFailedRelayedMsgsByHashes"])
+
+	// nothing to query
+	counter.calls = make(map[string]int)
+	assert.NoError(t, updateCrossTxHashesAndL2TxClaimInfo(context.Background(), []*types.TxHistoryInfo{{FinalizeTx: &types.Finalized{}}}, db, "", nil))
+	assert.Empty(t, counter.calls)
+}
+
+func TestEnrichmentQueriedInChunks(t *testing.T) {
+	db, counter := newCountingDB(t, nil)
+	txHistories := make([]*types.TxHistoryInfo, 2*enrichmentChunkSize+1)
+	for i := range txHistories {
+		txHistories[i] = &types.TxHistoryInfo{MsgHash: common.BigToHash(big.NewInt(int64(i + 1))).Hex(), FinalizeTx: &types.Finalized{}}
+	}
+	assert.NoError(t, updateCrossTxHashesAndL2TxClaimInfo(context.Background(), txHistories, db, "", nil))
+	for _, method := range []string{"(*RelayedMsg).GetRelayedMsgsByHashes", "(*L2SentMsg).GetL2SentMsgsByHashes", "(*FailedRelayedMsg).GetFailedRelayedMsgsByHashes"} {
+		assert.Equal(t, 3, counter.calls[method], method)
+		// the last chunk holds the remaining hash
+		assert.Equal(t, []interface{}{txHistories[2*enrichmentChunkSize].MsgHash}, counter.vars[method], method)
+	}
+
+	calls := 0
+	assert.NoError(t, forEachChunk(0, func(start, end int) error {
+		calls++
+		return nil
+	}))
+	assert.Zero(t, calls)
+	var bounds [][2]int
+	assert.NoError(t, forEachChunk(enrichmentChunkSize+1, func(start, end int) error {
+		bounds = append(bounds, [2]int{start, end})
+		return nil
+	}))
+	assert.Equal(t, [][2]int{{0, enrichmentChunkSize}, {enrichmentChunkSize, enrichmentChunkSize + 1}}, bounds)
+}
+
+func TestEnrichmentErrorReturned(t *testing.T) {
+	db, _ := newCountingDB(t, txsByHashesFixtures())
+	errRelayedMsg := errors.New("relayed_msg unavailable")
+	err := db.Callback().Query().After("test:count_orm_calls").Register("test:fail_relayed_msg", func(db *gorm.DB) {
+		if db.Statement.Table == (&orm.RelayedMsg{}).TableName() {
+			_ = db.AddError(errRelayedMsg)
+		}
+	})
+	assert.NoError(t, err)
+
+	_, err = NewHistoryLogic(nil, db, nil, nil).GetTxsByHashes(context.Background(), []string{common.HexToHash("0x01").Hex(), common.HexToHash("0x02").Hex()}, types.TokenTypeAll, types.SortOrderDesc)
+	assert.ErrorIs(t, err, ErrDatabase)
+	assert.ErrorIs(t, err, errRelayedMsg)
+}
+
+func TestCancelledContextNotQueried(t *testing.T) {
+	db, counter := newCountingDB(t, txsByHashesFixtures())
+	logic := NewHistoryLogic(nil, db, nil, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := logic.GetTxsByHashes(ctx, []string{common.HexToHash("0x01").Hex(), common.HexToHash("0x02").Hex()}, types.TokenTypeAll, types.SortOrderDesc)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, _, err = logic.GetClaimableTxsByAddress(ctx, common.HexToAddress("0x01"), nil, types.TokenTypeAll, types.SortOrderDesc)
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = logic.GetClaimableTxsByAddressSplit(ctx, common.HexToAddress("0x01"))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, counter.calls)
+}
+
+func BenchmarkGetTxsByHashes(b *testing.B) {
+	db, _ := newCountingDB(b, txsByHashesFixtures())
+	logic := &HistoryLogic{db: db}
+	ctx := context.Background()
+	hashes := []string{common.HexToHash("0x01").Hex(), common.HexToHash("0x02").Hex()}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := logic.GetTxsByHashes(ctx, hashes, types.TokenTypeAll, types.SortOrderDesc); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package logic
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"bridge-history-api/config"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+const (
+	// maxWebhooksPerAddress is the maximum number of the webhooks registered for an address
+	maxWebhooksPerAddress = 10
+	// minWebhookSecretLength is the minimum length of the secret of a webhook
+	minWebhookSecretLength = 16
+	// maxWebhookSecretLength is the maximum length of the secret of a webhook
+	maxWebhookSecretLength = 256
+	// webhookSignatureValidity is the time the signature of a webhook registration is accepted before or after its
+	// timestamp
+	webhookSignatureValidity = 10 * time.Minute
+)
+
+// SignWebhookPayload returns the value of the WebhookSignatureHeader of the payload delivered to the webhook of the secret
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookEvent the event of a message for the webhooks of an address
+type webhookEvent struct {
+	eventType types.WebhookEventType
+	address   string
+	msgHash   string
+	txHash    string
+}
+
+// WebhookNotifier queues the deliveries of the events of the messages saved by the fetcher to the webhooks of their
+// addresses, a nil WebhookNotifier queues nothing
+type WebhookNotifier struct {
+	webhookOrm  *orm.Webhook
+	deliveryOrm *orm.WebhookDelivery
+	db          *gorm.DB
+}
+
+// NewWebhookNotifier returns the WebhookNotifier of db, nil is returned if the webhooks are disabled
+func NewWebhookNotifier(cfg *config.WebhookConfig, db *gorm.DB) *WebhookNotifier {
+	if cfg == nil {
+		return nil
+	}
+	return &WebhookNotifier{
+		webhookOrm:  orm.NewWebhook(db),
+		deliveryOrm: orm.NewWebhookDelivery(db),
+		db:          db,
+	}
+}
+
+// NotifyClaimable queues the claimable events of the withdrawals whose proofs are saved to the webhooks of their
+// senders and original senders
+func (n *WebhookNotifier) NotifyClaimable(ctx context.Context, l2SentMsgs []*orm.L2SentMsg) error {
+	if n == nil {
+		return nil
+	}
+	var events []*webhookEvent
+	for _, l2SentMsg := range l2SentMsgs {
+		for _, address := range eventAddresses(l2SentMsg.Sender, l2SentMsg.OriginalSender) {
+			events = append(events, &webhookEvent{eventType: types.WebhookEventWithdrawalClaimable, address: address.Hex(), msgHash: l2SentMsg.MsgHash, txHash: l2SentMsg.TxHash})
+		}
+	}
+	return n.notify(ctx, events)
+}
+
+// NotifyRelayed queues the finalized events of the messages relayed by the relayed msgs saved, the withdrawals relayed
+// on layer1 to the webhooks of their senders and original senders and the deposits relayed on layer2 to the webhooks
+// of their senders and targets
+func (n *WebhookNotifier) NotifyRelayed(ctx context.Context, relayedMsgs []*orm.RelayedMsg) error {
+	if n == nil {
+		return nil
+	}
+	relayTxHashes := make(map[string]string, len(relayedMsgs))
+	var withdrawalHashes, depositHashes []string
+	for _, relayedMsg := range relayedMsgs {
+		switch {
+		case relayedMsg.Layer1Hash != "":
+			relayTxHashes[relayedMsg.MsgHash] = relayedMsg.Layer1Hash
+			withdrawalHashes = append(withdrawalHashes, relayedMsg.MsgHash)
+		case relayedMsg.Layer2Hash != "":
+			relayTxHashes[relayedMsg.MsgHash] = relayedMsg.Layer2Hash
+			depositHashes = append(depositHashes, relayedMsg.MsgHash)
+		}
+	}
+
+	var events []*webhookEvent
+	if len(withdrawalHashes) != 0 {
+		l2SentMsgs, err := orm.NewL2SentMsg(n.db).GetL2SentMsgsByHashes(ctx, withdrawalHashes)
+		if err != nil {
+			return err
+		}
+		for _, l2SentMsg := range l2SentMsgs {
+			for _, address := range eventAddresses(l2SentMsg.Sender, l2SentMsg.OriginalSender) {
+				events = append(events, &webhookEvent{eventType: types.WebhookEventWithdrawalFinalized, address: address.Hex(), msgHash: l2SentMsg.MsgHash, txHash: relayTxHashes[l2SentMsg.MsgHash]})
+			}
+		}
+	}
+	if len(depositHashes) != 0 {
+		deposits, err := orm.NewCrossMsg(n.db).GetL1CrossMsgByMsgHashList(ctx, depositHashes)
+		if err != nil {
+			return err
+		}
+		for _, deposit := range deposits {
+			for _, address := range eventAddresses(deposit.Sender, deposit.Target) {
+				events = append(events, &webhookEvent{eventType: types.WebhookEventDepositFinalized, address: address.Hex(), msgHash: deposit.MsgHash, txHash: relayTxHashes[deposit.MsgHash]})
+			}
+		}
+	}
+	return n.notify(ctx, events)
+}
+
+// notify queues the deliveries of the events to the webhooks of their addresses
+func (n *WebhookNotifier) notify(ctx context.Context, events []*webhookEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	addresses := make([]string, 0, len(events))
+	for _, event := range events {
+		addresses = append(addresses, event.address)
+	}
+	webhooks, err := n.webhookOrm.GetWebhooksByAddresses(ctx, addresses)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+	webhooksByAddress := make(map[string][]*orm.Webhook, len(webhooks))
+	for _, webhook := range webhooks {
+		webhooksByAddress[webhook.Address] = append(webhooksByAddress[webhook.Address], webhook)
+	}
+
+	now := time.Now()
+	var deliveries []*orm.WebhookDelivery
+	for _, event := range events {
+		for _, webhook := range webhooksByAddress[event.address] {
+			deliveries = append(deliveries, &orm.WebhookDelivery{
+				WebhookID:     webhook.ID,
+				EventType:     string(event.eventType),
+				Address:       event.address,
+				MsgHash:       event.msgHash,
+				TxHash:        event.txHash,
+				NextAttemptAt: &now,
+			})
+		}
+	}
+	log.Debug("WebhookNotifier: queue the webhook deliveries", "deliveries", len(deliveries))
+	return n.deliveryOrm.InsertWebhookDeliveries(ctx, deliveries)
+}
+
+// WebhookLogic registers and deletes the webhooks
+type WebhookLogic struct {
+	webhookOrm *orm.Webhook
+}
+
+// NewWebhookLogic returns the WebhookLogic backed with db
+func NewWebhookLogic(db *gorm.DB) *WebhookLogic {
+	return &WebhookLogic{webhookOrm: orm.NewWebhook(db)}
+}
+
+// validateWebhookURL checks the url is an absolute http or https url
+func validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("%w: invalid url: %v", ErrInvalidParameter, err)
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%w: the url must be an absolute http or https url", ErrInvalidParameter)
+	}
+	return nil
+}
+
+// WebhookRegistrationMessage returns the message signed by the address registering the webhook of the url at the
+// timestamp in unix seconds
+func WebhookRegistrationMessage(address common.Address, url string, timestamp int64) string {
+	return fmt.Sprintf("Register the webhook %s for %s at %d", url, address.Hex(), timestamp)
+}
+
+// verifyWebhookSignature checks the hex EIP-191 personal signature is signed by the address over the registration
+// message of the url and the timestamp, and the timestamp is within webhookSignatureValidity of now
+func verifyWebhookSignature(address common.Address, url string, timestamp int64, signature string, now time.Time) error {
+	signedAt := time.Unix(timestamp, 0)
+	if signedAt.Before(now.Add(-webhookSignatureValidity)) || signedAt.After(now.Add(webhookSignatureValidity)) {
+		return fmt.Errorf("%w: the timestamp must be within %v of now", ErrInvalidParameter, webhookSignatureValidity)
+	}
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return fmt.Errorf("%w: invalid signature", ErrInvalidParameter)
+	}
+	// the wallets sign with the recovery id 27 or 28
+	sig = append([]byte{}, sig...)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	pubKey, err := crypto.SigToPub(accounts.TextHash([]byte(WebhookRegistrationMessage(address, url, timestamp))), sig)
+	if err != nil || crypto.PubkeyToAddress(*pubKey) != address {
+		return fmt.Errorf("%w: the signature is not signed by %s", ErrInvalidParameter, address.Hex())
+	}
+	return nil
+}
+
+// validateWebhookSecret checks the length of the secret of a webhook
+func validateWebhookSecret(secret string) error {
+	if len(secret) < minWebhookSecretLength || len(secret) > maxWebhookSecretLength {
+		return fmt.Errorf("%w: the secret must be %d to %d characters", ErrInvalidParameter, minWebhookSecretLength, maxWebhookSecretLength)
+	}
+	return nil
+}
+
+// RegisterWebhook registers the webhook of the address, the caller proves owning the address by the signature of the
+// request. ErrConflict is returned if the webhook of the same address and url is already registered, its secret is
+// only replaced by UpdateWebhookSecret. At most maxWebhooksPerAddress webhooks are registered for an address.
+func (w *WebhookLogic) RegisterWebhook(ctx context.Context, req *types.RegisterWebhookRequest) (*types.Webhook, error) {
+	if !common.IsHexAddress(req.Address) {
+		return nil, fmt.Errorf("%w: invalid address %q", ErrInvalidParameter, req.Address)
+	}
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	if err := validateWebhookSecret(req.Secret); err != nil {
+		return nil, err
+	}
+	address := common.HexToAddress(req.Address).Hex()
+	if err := verifyWebhookSignature(common.HexToAddress(address), req.URL, req.Timestamp, req.Signature, time.Now()); err != nil {
+		return nil, err
+	}
+	count, err := w.webhookOrm.GetWebhookCountByAddress(ctx, address)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	if count >= maxWebhooksPerAddress {
+		return nil, fmt.Errorf("%w: the address has the allowed maximum of %d webhooks", ErrInvalidParameter, maxWebhooksPerAddress)
+	}
+	webhook := &orm.Webhook{Address: address, URL: req.URL, Secret: req.Secret}
+	if err = w.webhookOrm.InsertWebhook(ctx, webhook); err != nil {
+		if errors.Is(err, orm.ErrWebhookExists) {
+			return nil, fmt.Errorf("%w: the webhook of %s and the url is already registered", ErrConflict, address)
+		}
+		return nil, classifyError(err)
+	}
+	return &types.Webhook{ID: webhook.ID, Address: webhook.Address, URL: webhook.URL}, nil
+}
+
+// authenticateWebhook returns the webhook of the id if the secret matches, ErrNotFound is returned if the webhook
+// doesn't exist or the secret doesn't match so that the ids can't be probed
+func (w *WebhookLogic) authenticateWebhook(ctx context.Context, id uint64, secret string) (*orm.Webhook, error) {
+	webhook, err := w.webhookOrm.GetWebhookByID(ctx, id)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	if webhook == nil || !hmac.Equal([]byte(secret), []byte(webhook.Secret)) {
+		return nil, fmt.Errorf("%w: webhook %d", ErrNotFound, id)
+	}
+	return webhook, nil
+}
+
+// UpdateWebhookSecret replaces the secret of the webhook of the id authenticated by its current secret
+func (w *WebhookLogic) UpdateWebhookSecret(ctx context.Context, id uint64, secret, newSecret string) (*types.Webhook, error) {
+	if err := validateWebhookSecret(newSecret); err != nil {
+		return nil, err
+	}
+	webhook, err := w.authenticateWebhook(ctx, id, secret)
+	if err != nil {
+		return nil, err
+	}
+	if err = w.webhookOrm.UpdateWebhookSecret(ctx, id, newSecret); err != nil {
+		return nil, classifyError(err)
+	}
+	return &types.Webhook{ID: webhook.ID, Address: webhook.Address, URL: webhook.URL}, nil
+}
+
+// DeleteWebhook deletes the webhook of the id authenticated by its secret
+func (w *WebhookLogic) DeleteWebhook(ctx context.Context, id uint64, secret string) error {
+	if _, err := w.authenticateWebhook(ctx, id, secret); err != nil {
+		return err
+	}
+	return classifyError(w.webhookOrm.DeleteWebhook(ctx, id))
+}
@@ -0,0 +1,60 @@
+package logic
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	// the HMAC-SHA256 test vector of RFC 4231 test case 2
+	assert.Equal(t, "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843", SignWebhookPayload("Jefe", []byte("what do ya want for nothing?")))
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	assert.NoError(t, validateWebhookURL("https://example.com/hooks/scroll"))
+	assert.NoError(t, validateWebhookURL("http://example.com:8080"))
+	for _, rawURL := range []string{"", "example.com/hooks", "ftp://example.com", "https://", "://example.com"} {
+		err := validateWebhookURL(rawURL)
+		assert.True(t, errors.Is(err, ErrInvalidParameter), rawURL)
+	}
+}
+
+func TestEventAddresses(t *testing.T) {
+	addresses := eventAddresses("0x1c5a77d9fa7ef466951b2f01f724bca3a5820b63", "", "0x1C5A77d9FA7eF466951B2F01F724BCa3A5820b63", "0x0000000000000000000000000000000000000001")
+	assert.Equal(t, []common.Address{
+		common.HexToAddress("0x1C5A77d9FA7eF466951B2F01F724BCa3A5820b63"),
+		common.HexToAddress("0x0000000000000000000000000000000000000001"),
+	}, addresses)
+}
+
+func TestVerifyWebhookSignature(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+	now := time.Unix(1700000000, 0)
+	sign := func(url string, timestamp int64) string {
+		sig, err := crypto.Sign(accounts.TextHash([]byte(WebhookRegistrationMessage(address, url, timestamp))), key)
+		require.NoError(t, err)
+		// as signed by the wallets
+		sig[crypto.RecoveryIDOffset] += 27
+		return hexutil.Encode(sig)
+	}
+
+	assert.NoError(t, verifyWebhookSignature(address, "https://example.com/hooks", now.Unix(), sign("https://example.com/hooks", now.Unix()), now))
+	for name, err := range map[string]error{
+		"another url":     verifyWebhookSignature(address, "https://attacker.com/hooks", now.Unix(), sign("https://example.com/hooks", now.Unix()), now),
+		"another address": verifyWebhookSignature(common.HexToAddress("0x01"), "https://example.com/hooks", now.Unix(), sign("https://example.com/hooks", now.Unix()), now),
+		"expired":         verifyWebhookSignature(address, "https://example.com/hooks", now.Unix()-3600, sign("https://example.com/hooks", now.Unix()-3600), now),
+		"malformed":       verifyWebhookSignature(address, "https://example.com/hooks", now.Unix(), "0x1234", now),
+	} {
+		assert.True(t, errors.Is(err, ErrInvalidParameter), name)
+	}
+}
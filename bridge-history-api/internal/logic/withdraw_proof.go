@@ -0,0 +1,164 @@
+package logic
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+	"bridge-history-api/utils"
+)
+
+const (
+	// withdrawProofPageSize is the number of the message hashes read per query when rebuilding the withdraw trie
+	withdrawProofPageSize = 10000
+	// withdrawProofTimeout bounds the regeneration of a proof, which reads every message sent before the batch
+	withdrawProofTimeout = time.Minute
+)
+
+// withdrawProofCacheKey returns the cache key of the regenerated claim info of the message of the nonce
+func withdrawProofCacheKey(nonce uint64) string {
+	return "withdrawProof:" + strconv.FormatUint(nonce, 10)
+}
+
+// rebuildWithdrawProof rebuilds the withdraw trie the way the proof updater does and returns the proof of the message
+// of the nonce along with the withdraw root after its batch. The messages sent before the batch are read page by page
+// through readHashes, batchMsgs are the messages of the batch in the nonce order. The proofs are against the root after
+// the whole batch is appended, the one the batch is finalized with, so the batch is appended in one go.
+func rebuildWithdrawProof(readHashes func(startNonce, endNonce uint64) ([]*orm.L2SentMsg, error), batchMsgs []*orm.L2SentMsg, nonce uint64, pageSize uint64) ([]byte, common.Hash, error) {
+	if len(batchMsgs) == 0 || nonce < batchMsgs[0].Nonce || nonce > batchMsgs[len(batchMsgs)-1].Nonce {
+		return nil, common.Hash{}, fmt.Errorf("the message of nonce %d is not in the messages of its batch", nonce)
+	}
+	trie := utils.NewWithdrawTrie()
+	appendMsgs := func(msgs []*orm.L2SentMsg) ([][]byte, error) {
+		hashes := make([]common.Hash, 0, len(msgs))
+		for _, msg := range msgs {
+			// a missing message shifts the leaves of all the following ones
+			if msg.Nonce != trie.NextMessageNonce+uint64(len(hashes)) {
+				return nil, fmt.Errorf("the message of nonce %d is missing", trie.NextMessageNonce+uint64(len(hashes)))
+			}
+			hashes = append(hashes, common.HexToHash(msg.MsgHash))
+		}
+		return trie.AppendMessages(hashes), nil
+	}
+
+	firstNonce := batchMsgs[0].Nonce
+	for trie.NextMessageNonce < firstNonce {
+		endNonce := trie.NextMessageNonce + pageSize - 1
+		if endNonce >= firstNonce {
+			endNonce = firstNonce - 1
+		}
+		msgs, err := readHashes(trie.NextMessageNonce, endNonce)
+		if err != nil {
+			return nil, common.Hash{}, err
+		}
+		if len(msgs) == 0 {
+			return nil, common.Hash{}, fmt.Errorf("the message of nonce %d is missing", trie.NextMessageNonce)
+		}
+		if _, err = appendMsgs(msgs); err != nil {
+			return nil, common.Hash{}, err
+		}
+	}
+	proofs, err := appendMsgs(batchMsgs)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	return proofs[nonce-firstNonce], trie.MessageRoot(), nil
+}
+
+// withdrawProofOf rebuilds the proof of the finalized message of the nonce from the stored messages, returned along
+// with the message and its batch
+func withdrawProofOf(ctx context.Context, db *gorm.DB, nonce uint64) (*orm.L2SentMsg, *orm.RollupBatch, []byte, error) {
+	l2SentMsgOrm := orm.NewL2SentMsg(db)
+	l2sentMsg, err := l2SentMsgOrm.GetL2SentMessageByNonce(ctx, nonce)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if l2sentMsg == nil {
+		return nil, nil, nil, fmt.Errorf("%w: no message of nonce %d", ErrNotFound, nonce)
+	}
+	batch, err := orm.NewRollupBatch(db).GetRollupBatchByBlockNumber(ctx, l2sentMsg.Height)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if batch == nil || !batch.IsFinalized() {
+		return nil, nil, nil, fmt.Errorf("%w: the batch of the message of nonce %d is not finalized", ErrInvalidParameter, nonce)
+	}
+	batchMsgs, err := l2SentMsgOrm.GetL2SentMsgMsgHashByHeightRange(ctx, batch.StartBlockNumber, batch.EndBlockNumber)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	proof, withdrawRoot, err := rebuildWithdrawProof(func(startNonce, endNonce uint64) ([]*orm.L2SentMsg, error) {
+		return l2SentMsgOrm.GetL2SentMsgHashesByNonceRange(ctx, startNonce, endNonce)
+	}, batchMsgs, nonce, withdrawProofPageSize)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	// a mismatch means stored messages are missing or corrupted, the claim with the proof would revert
+	if batch.WithdrawRoot != "" && common.HexToHash(batch.WithdrawRoot) != withdrawRoot {
+		return nil, nil, nil, fmt.Errorf("the regenerated withdraw root %s mismatches the root %s of batch %d", withdrawRoot.Hex(), batch.WithdrawRoot, batch.BatchIndex)
+	}
+	return l2sentMsg, batch, proof, nil
+}
+
+// GetWithdrawProof regenerates the claim info of the withdrawal of the nonce, with the proof rebuilt from the stored
+// messages instead of the stored proof, for the withdrawals whose stored proof is missing or corrupted. Only the
+// withdrawals of the finalized batches have a proof.
+func (h *HistoryLogic) GetWithdrawProof(ctx context.Context, nonce uint64) (*types.UserClaimInfo, error) {
+	return instrument(h.metrics, "GetWithdrawProof", func() (*types.UserClaimInfo, error) {
+		return h.getWithdrawProof(ctx, nonce)
+	})
+}
+
+// getWithdrawProof implements GetWithdrawProof
+func (h *HistoryLogic) getWithdrawProof(ctx context.Context, nonce uint64) (*types.UserClaimInfo, error) {
+	var claimInfo types.UserClaimInfo
+	if !h.getCached(ctx, withdrawProofCacheKey(nonce), &claimInfo) {
+		regenerated, err := h.regenerateWithdrawProof(ctx, nonce)
+		if err != nil {
+			return nil, err
+		}
+		// the proofs of the finalized batches never change
+		h.setCached(ctx, withdrawProofCacheKey(nonce), regenerated)
+		claimInfo = *regenerated
+	}
+	h.redactSensitiveFields([]*types.TxHistoryInfo{{ClaimInfo: &claimInfo}})
+	return &claimInfo, nil
+}
+
+// regenerateWithdrawProof regenerates the claim info of the finalized message of the nonce from the stored messages,
+// the stored proof isn't read so that the messages whose proof is missing or corrupted can be claimed
+func (h *HistoryLogic) regenerateWithdrawProof(ctx context.Context, nonce uint64) (*types.UserClaimInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, withdrawProofTimeout)
+	defer cancel()
+	l2sentMsg, batch, proof, err := withdrawProofOf(ctx, h.db, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	claimInfo := &types.UserClaimInfo{
+		From:       l2sentMsg.Sender,
+		To:         l2sentMsg.Target,
+		Value:      l2sentMsg.Value,
+		Nonce:      strconv.FormatUint(l2sentMsg.Nonce, 10),
+		Message:    l2sentMsg.MsgData,
+		Proof:      "0x" + common.Bytes2Hex(proof),
+		BatchHash:  batch.BatchHash,
+		BatchIndex: strconv.FormatUint(batch.BatchIndex, 10),
+		StateRoot:  batch.StateRoot,
+		Claimable:  true,
+	}
+	if claimInfo.EstimatedGas, err = estimateClaimGas(claimInfo); err != nil {
+		log.Debug("estimateClaimGas failed", "nonce", nonce, "error", err)
+	}
+	if claimInfo.ClaimKey, err = claimKey(claimInfo); err != nil {
+		log.Debug("claimKey failed", "nonce", nonce, "error", err)
+	}
+	return claimInfo, nil
+}
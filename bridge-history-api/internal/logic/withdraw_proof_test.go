@@ -0,0 +1,65 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/orm"
+	"bridge-history-api/utils"
+)
+
+func TestRebuildWithdrawProof(t *testing.T) {
+	var msgs []*orm.L2SentMsg
+	for nonce := uint64(0); nonce < 10; nonce++ {
+		msgs = append(msgs, &orm.L2SentMsg{Nonce: nonce, MsgHash: common.BigToHash(new(big.Int).SetUint64(nonce + 1)).Hex()})
+	}
+	hashes := func(msgs []*orm.L2SentMsg) []common.Hash {
+		var result []common.Hash
+		for _, msg := range msgs {
+			result = append(result, common.HexToHash(msg.MsgHash))
+		}
+		return result
+	}
+	// the proof updater appends the messages batch by batch, the batches are [0, 4), [4, 7) and [7, 10)
+	trie := utils.NewWithdrawTrie()
+	trie.AppendMessages(hashes(msgs[:4]))
+	proofs := trie.AppendMessages(hashes(msgs[4:7]))
+	withdrawRoot := trie.MessageRoot()
+
+	readHashes := func(startNonce, endNonce uint64) ([]*orm.L2SentMsg, error) {
+		return msgs[startNonce : endNonce+1], nil
+	}
+	for nonce := uint64(4); nonce < 7; nonce++ {
+		proof, root, err := rebuildWithdrawProof(readHashes, msgs[4:7], nonce, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, proofs[nonce-4], proof)
+		assert.Equal(t, withdrawRoot, root)
+	}
+
+	// the messages of the first batch need no message before them
+	proof, _, err := rebuildWithdrawProof(nil, msgs[:4], 0, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, utils.NewWithdrawTrie().AppendMessages(hashes(msgs[:4]))[0], proof)
+
+	_, _, err = rebuildWithdrawProof(readHashes, msgs[4:7], 8, 3)
+	assert.Error(t, err)
+	// a missing message shifts the leaves, the proof is not returned
+	_, _, err = rebuildWithdrawProof(func(startNonce, endNonce uint64) ([]*orm.L2SentMsg, error) {
+		return append(append([]*orm.L2SentMsg{}, msgs[startNonce:2]...), msgs[3:endNonce+1]...), nil
+	}, msgs[4:7], 5, 10)
+	assert.Error(t, err)
+}
+
+func TestGetWithdrawProofNotFinalized(t *testing.T) {
+	db, _ := newCountingDB(t, map[string]interface{}{
+		orm.L2SentMsgAllViewName:         []*orm.L2SentMsg{{Nonce: 3, Height: 15}},
+		(&orm.RollupBatch{}).TableName(): []*orm.RollupBatch{{BatchIndex: 1, StartBlockNumber: 10, EndBlockNumber: 20}},
+	})
+	_, err := NewHistoryLogic(nil, db, nil, nil).GetWithdrawProof(context.Background(), 3)
+	assert.True(t, errors.Is(err, ErrInvalidParameter))
+}
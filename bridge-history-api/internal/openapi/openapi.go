@@ -0,0 +1,221 @@
+package openapi
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version the version of the OpenAPI specification of the generated documents
+const Version = "3.0.3"
+
+// Document the OpenAPI document of the apis, only the objects the apis use are modeled
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+// Info the metadata of the apis
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem the operations of a path keyed by the lower case http method
+type PathItem map[string]*operation
+
+// Components the schemas referenced by the operations keyed by the name of their go type
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+type operation struct {
+	Summary     string               `json:"summary,omitempty"`
+	OperationID string               `json:"operationId,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *requestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*response `json:"responses"`
+}
+
+type requestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*mediaType `json:"content"`
+}
+
+type response struct {
+	Description string                `json:"description"`
+	Content     map[string]*mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Parameter a query, path or header parameter of an operation
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// Operation documents an api. The parameters and the body are described by the structs the handler binds, the query
+// and path parameters by their form and uri tags and the body by its json tags, the fields tagged binding:"required"
+// are required. The path parameters not bound from a struct are documented as required strings.
+type Operation struct {
+	Method string
+	// Path the gin path of the api, e.g. /api/txs/:hash/status
+	Path    string
+	Summary string
+	// Params the struct the query and path parameters are bound to, nil if none
+	Params interface{}
+	// Headers the header parameters
+	Headers []*Parameter
+	// Body the struct the json body is bound to, nil if none
+	Body interface{}
+	// Data the value of the data of the success response, nil if none
+	Data interface{}
+	// Raw the data is responded as is rather than wrapped in the response envelope
+	Raw bool
+}
+
+// Generator builds the document of the operations, the operations share the envelope of the responses and the
+// common parameters
+type Generator struct {
+	info         Info
+	envelope     reflect.Type
+	errorCodes   map[int]string
+	commonParams []*Parameter
+}
+
+// NewGenerator returns a Generator wrapping the data of the responses in the envelope, a struct whose interface field
+// tagged json:"data" carries the data. errorCodes describes the error codes of the int field tagged json:"errcode".
+func NewGenerator(info Info, envelope interface{}, errorCodes map[int]string, commonParams ...*Parameter) *Generator {
+	return &Generator{
+		info:         info,
+		envelope:     reflect.TypeOf(envelope),
+		errorCodes:   errorCodes,
+		commonParams: commonParams,
+	}
+}
+
+// Generate returns the document of the operations
+func (g *Generator) Generate(operations []Operation) *Document {
+	schemas := newSchemaBuilder()
+	schemas.components[errorCodeSchema] = g.errorCodeSchema()
+	envelope := func(data interface{}) *Schema {
+		var dataSchema *Schema
+		if data != nil {
+			dataSchema = schemas.schema(reflect.TypeOf(data))
+		}
+		return schemas.envelope(g.envelope, dataSchema)
+	}
+	schemas.components[errorResponseSchema] = envelope(nil)
+
+	doc := &Document{
+		OpenAPI:    Version,
+		Info:       g.info,
+		Paths:      make(map[string]PathItem),
+		Components: Components{Schemas: schemas.components},
+	}
+	for _, op := range operations {
+		path, pathParams := openAPIPath(op.Path)
+		params := schemas.parameters(op.Params)
+		for _, name := range pathParams {
+			if !hasParameter(params, name, "path") {
+				params = append(params, &Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+			}
+		}
+		params = append(params, op.Headers...)
+		params = append(params, g.commonParams...)
+
+		var dataSchema *Schema
+		if op.Raw {
+			if op.Data != nil {
+				dataSchema = schemas.schema(reflect.TypeOf(op.Data))
+			}
+		} else {
+			dataSchema = envelope(op.Data)
+		}
+		success := &response{Description: "the data of the api, or the failure with errcode and errmsg"}
+		if dataSchema != nil {
+			success.Content = jsonContent(dataSchema)
+		}
+		item := &operation{
+			Summary:     op.Summary,
+			OperationID: operationID(op.Method, op.Path),
+			Parameters:  params,
+			Responses: map[string]*response{
+				"200": success,
+				"500": {Description: "the server fails to serve the request", Content: jsonContent(&Schema{Ref: componentRef(errorResponseSchema)})},
+			},
+		}
+		if op.Body != nil {
+			item.RequestBody = &requestBody{Required: true, Content: jsonContent(schemas.schema(reflect.TypeOf(op.Body)))}
+		}
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(PathItem)
+		}
+		doc.Paths[path][strings.ToLower(op.Method)] = item
+	}
+	return doc
+}
+
+// errorCodeSchema the integer schema of the error codes, enumerating the codes and describing each
+func (g *Generator) errorCodeSchema() *Schema {
+	codes := make([]int, 0, len(g.errorCodes))
+	for code := range g.errorCodes {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	schema := &Schema{Type: "integer"}
+	descriptions := make([]string, 0, len(codes))
+	for _, code := range codes {
+		schema.Enum = append(schema.Enum, code)
+		descriptions = append(descriptions, strconv.Itoa(code)+": "+g.errorCodes[code])
+	}
+	schema.Description = "the error code, 0 on success. " + strings.Join(descriptions, "; ")
+	return schema
+}
+
+// openAPIPath converts the gin path to the OpenAPI path, returning the names of its path parameters.
+// e.g. /api/txs/:hash/status is /api/txs/{hash}/status
+func openAPIPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID returns the unique id of the operation of the method and the path, e.g. getApiTxsHashStatus
+func operationID(method, path string) string {
+	var id strings.Builder
+	id.WriteString(strings.ToLower(method))
+	for _, word := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == ':' || r == '*' || r == '.' }) {
+		id.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return id.String()
+}
+
+func hasParameter(params []*Parameter, name, in string) bool {
+	for _, param := range params {
+		if param.Name == name && param.In == in {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonContent(schema *Schema) map[string]*mediaType {
+	return map[string]*mediaType{"application/json": {Schema: schema}}
+}
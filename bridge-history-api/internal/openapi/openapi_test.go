@@ -0,0 +1,119 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testEnvelope struct {
+	ErrCode int         `json:"errcode"`
+	ErrMsg  string      `json:"errmsg"`
+	Data    interface{} `json:"data"`
+}
+
+type testPagination struct {
+	Cursor   string `form:"cursor"`
+	PageSize uint64 `form:"page_size"`
+}
+
+type testParams struct {
+	Hash    string `uri:"hash" binding:"required"`
+	Address string `form:"address" binding:"required"`
+	testPagination
+}
+
+type testStage struct {
+	TxHash    string     `json:"txHash"`
+	Timestamp *time.Time `json:"timestamp"`
+}
+
+type testFilter struct {
+	Addresses []string `json:"addresses" binding:"required"`
+	From      *uint64  `json:"from"`
+}
+
+type testBody struct {
+	testFilter
+	Page    uint64 `json:"page"`
+	Ignored string `json:"-"`
+}
+
+type testData struct {
+	Stage  *testStage   `json:"stage"`
+	Stages []*testStage `json:"stages"`
+	Labels map[string]string
+	Data   []byte `json:"data"`
+}
+
+func TestOpenAPIPath(t *testing.T) {
+	path, params := openAPIPath("/api/txs/:hash/status")
+	assert.Equal(t, "/api/txs/{hash}/status", path)
+	assert.Equal(t, []string{"hash"}, params)
+
+	path, params = openAPIPath("/api/txs")
+	assert.Equal(t, "/api/txs", path)
+	assert.Empty(t, params)
+
+	assert.Equal(t, "getApiTxsHashStatus", operationID(http.MethodGet, "/api/txs/:hash/status"))
+	assert.Equal(t, "deleteApiWebhooksId", operationID(http.MethodDelete, "/api/webhooks/:id"))
+}
+
+func TestGenerate(t *testing.T) {
+	network := &Parameter{Name: "network", In: "query", Schema: &Schema{Type: "string"}}
+	generator := NewGenerator(Info{Title: "test", Version: "1"}, testEnvelope{}, map[int]string{0: "success", 40001: "invalid"}, network)
+	doc := generator.Generate([]Operation{
+		{Method: http.MethodGet, Path: "/api/txs/:hash", Summary: "get", Params: testParams{}, Data: testData{}},
+		{Method: http.MethodPost, Path: "/api/txs", Body: testBody{}, Data: []*testStage{}},
+		{Method: http.MethodGet, Path: "/ws/:address", Data: testStage{}, Raw: true},
+	})
+	assert.Equal(t, Version, doc.OpenAPI)
+
+	// the parameters of the struct, then the common parameters
+	get := doc.Paths["/api/txs/{hash}"]["get"]
+	assert.Equal(t, "getApiTxsHash", get.OperationID)
+	assert.Equal(t, []*Parameter{
+		{Name: "hash", In: "path", Required: true, Schema: &Schema{Type: "string"}},
+		{Name: "address", In: "query", Required: true, Schema: &Schema{Type: "string"}},
+		{Name: "cursor", In: "query", Schema: &Schema{Type: "string"}},
+		{Name: "page_size", In: "query", Schema: &Schema{Type: "integer", Format: "int64"}},
+		network,
+	}, get.Parameters)
+
+	// the data is wrapped in the envelope
+	envelope := get.Responses["200"].Content["application/json"].Schema
+	assert.Equal(t, &Schema{Ref: componentRef("testData")}, envelope.Properties["data"])
+	assert.Equal(t, &Schema{Ref: componentRef(errorCodeSchema)}, envelope.Properties["errcode"])
+	assert.Equal(t, &Schema{Type: "string"}, envelope.Properties["errmsg"])
+	assert.Equal(t, &Schema{Ref: componentRef(errorResponseSchema)}, get.Responses["500"].Content["application/json"].Schema)
+	assert.Equal(t, []interface{}{0, 40001}, doc.Components.Schemas[errorCodeSchema].Enum)
+
+	assert.Equal(t, &Schema{Type: "object", Properties: map[string]*Schema{
+		"stage":  {Ref: componentRef("testStage")},
+		"stages": {Type: "array", Items: &Schema{Ref: componentRef("testStage")}},
+		"Labels": {Type: "object", AdditionalProperties: &Schema{Type: "string"}},
+		"data":   {Type: "string", Format: "byte"},
+	}}, doc.Components.Schemas["testData"])
+	assert.Equal(t, &Schema{Type: "object", Properties: map[string]*Schema{
+		"txHash":    {Type: "string"},
+		"timestamp": {Type: "string", Format: "date-time", Nullable: true},
+	}}, doc.Components.Schemas["testStage"])
+
+	// the fields of the embedded structs are promoted, the ignored fields are skipped
+	post := doc.Paths["/api/txs"]["post"]
+	assert.Equal(t, &Schema{Ref: componentRef("testBody")}, post.RequestBody.Content["application/json"].Schema)
+	assert.Equal(t, &Schema{Type: "object", Required: []string{"addresses"}, Properties: map[string]*Schema{
+		"addresses": {Type: "array", Items: &Schema{Type: "string"}},
+		"from":      {Type: "integer", Format: "int64", Nullable: true},
+		"page":      {Type: "integer", Format: "int64"},
+	}}, doc.Components.Schemas["testBody"])
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Ref: componentRef("testStage")}},
+		post.Responses["200"].Content["application/json"].Schema.Properties["data"])
+
+	// the path parameters not bound from a struct are strings, the raw data isn't wrapped
+	ws := doc.Paths["/ws/{address}"]["get"]
+	assert.Equal(t, []*Parameter{{Name: "address", In: "path", Required: true, Schema: &Schema{Type: "string"}}, network}, ws.Parameters)
+	assert.Equal(t, &Schema{Ref: componentRef("testStage")}, ws.Responses["200"].Content["application/json"].Schema)
+}
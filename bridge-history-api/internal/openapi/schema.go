@@ -0,0 +1,196 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// errorCodeSchema the component of the error codes of the envelope
+	errorCodeSchema = "ErrorCode"
+	// errorResponseSchema the component of the envelope of the failures
+	errorResponseSchema = "ErrorResponse"
+)
+
+// Schema the schema of a value, only the keywords the go types map to are modeled
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaBuilder maps the go types to schemas, the named structs are added to the components once and referenced
+type schemaBuilder struct {
+	components map[string]*Schema
+}
+
+func newSchemaBuilder() *schemaBuilder {
+	return &schemaBuilder{components: make(map[string]*Schema)}
+}
+
+func componentRef(name string) string {
+	return "#/components/schemas/" + name
+}
+
+// schema returns the schema of the json encoding of the type
+func (b *schemaBuilder) schema(t reflect.Type) *Schema {
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := b.schema(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: b.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schema(t.Elem())}
+	case reflect.Struct:
+		if t == timeType {
+			return &Schema{Type: "string", Format: "date-time"}
+		}
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		if _, exists := b.components[t.Name()]; !exists {
+			// reserved before the fields are built, so that the recursive types terminate
+			b.components[t.Name()] = &Schema{}
+			b.components[t.Name()] = b.object(t)
+		}
+		return &Schema{Ref: componentRef(t.Name())}
+	default:
+		// any value, e.g. an interface
+		return &Schema{}
+	}
+}
+
+// object returns the object schema of the exported fields of the struct, the fields of the embedded structs are
+// promoted as encoding/json does
+func (b *schemaBuilder) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	b.addFields(schema, t)
+	return schema
+}
+
+func (b *schemaBuilder) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, skip := tagName(field, "json")
+		if skip {
+			continue
+		}
+		if field.Anonymous && name == "" && indirect(field.Type).Kind() == reflect.Struct {
+			b.addFields(schema, indirect(field.Type))
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = b.schema(field.Type)
+		if isRequired(field) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// envelope returns the schema of the envelope struct with the data field of the data schema and the error code field
+// referencing the error codes, the data is null on failure
+func (b *schemaBuilder) envelope(t reflect.Type, data *Schema) *Schema {
+	schema := b.object(t)
+	schema.Description = "the response envelope, errcode is 0 and data is the result on success, errcode is the error " +
+		"code and errmsg the error message on failure"
+	if _, exists := schema.Properties["errcode"]; exists {
+		schema.Properties["errcode"] = &Schema{Ref: componentRef(errorCodeSchema)}
+	}
+	if data == nil {
+		data = &Schema{Nullable: true}
+	}
+	schema.Properties["data"] = data
+	return schema
+}
+
+// parameters returns the query parameters of the form tags and the path parameters of the uri tags of the struct
+func (b *schemaBuilder) parameters(params interface{}) []*Parameter {
+	if params == nil {
+		return nil
+	}
+	var parameters []*Parameter
+	var addParams func(t reflect.Type)
+	addParams = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Anonymous && indirect(field.Type).Kind() == reflect.Struct {
+				addParams(indirect(field.Type))
+				continue
+			}
+			for _, binding := range []struct{ in, tag string }{{"query", "form"}, {"path", "uri"}} {
+				in := binding.in
+				name, skip := tagName(field, binding.tag)
+				if skip || name == "" {
+					continue
+				}
+				parameters = append(parameters, &Parameter{
+					Name: name,
+					In:   in,
+					// the path parameters are always required
+					Required: in == "path" || isRequired(field),
+					Schema:   b.schema(field.Type),
+				})
+			}
+		}
+	}
+	addParams(indirect(reflect.TypeOf(params)))
+	return parameters
+}
+
+// tagName returns the name of the tag of the field, skip is true for the fields ignored by the tag
+func tagName(field reflect.StructField, key string) (string, bool) {
+	tag := field.Tag.Get(key)
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name, false
+}
+
+func isRequired(field reflect.StructField) bool {
+	for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+		if rule == "required" {
+			return true
+		}
+	}
+	return false
+}
+
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
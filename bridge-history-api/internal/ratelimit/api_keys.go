@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+
+	"bridge-history-api/orm"
+)
+
+// defaultAPIKeyRefreshInterval is the interval the api keys are reloaded from the db by default
+const defaultAPIKeyRefreshInterval = time.Minute
+
+// apiKeyStore caches the limits of the api keys keyed by the key hash, the keys are reloaded once stale in the
+// background of the request noticing it, the requests keep using the stale keys meanwhile
+type apiKeyStore struct {
+	load            func(ctx context.Context) ([]*orm.APIKeyLimit, error)
+	refreshInterval time.Duration
+
+	mu       sync.RWMutex
+	keys     map[string]*orm.APIKeyLimit
+	loaded   bool
+	loadedAt time.Time
+	loading  bool
+}
+
+func newAPIKeyStore(load func(ctx context.Context) ([]*orm.APIKeyLimit, error), refreshInterval time.Duration) *apiKeyStore {
+	return &apiKeyStore{load: load, refreshInterval: refreshInterval}
+}
+
+// hashAPIKey returns the hex sha256 of the api key, the api_key table stores the hashes only
+func hashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// lookup returns the limit of the api key of the hash and whether the key is known, loaded is false until the keys are
+// loaded once. The keys due are reloaded by one of the requests, synchronously until loaded once and in the background
+// after.
+func (s *apiKeyStore) lookup(ctx context.Context, keyHash string, now time.Time) (limit *orm.APIKeyLimit, known bool, loaded bool) {
+	s.mu.Lock()
+	loaded = s.loaded
+	reload := !s.loading && now.Sub(s.loadedAt) >= s.refreshInterval
+	if reload {
+		s.loading = true
+	}
+	s.mu.Unlock()
+	if reload {
+		if loaded {
+			go s.reload(context.Background(), now)
+		} else {
+			s.reload(ctx, now)
+		}
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	limit, known = s.keys[keyHash]
+	return limit, known, s.loaded
+}
+
+// reload replaces the keys by the keys of the db, the keys are kept if the loading fails and retried after the
+// refresh interval
+func (s *apiKeyStore) reload(ctx context.Context, now time.Time) {
+	limits, err := s.load(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loading = false
+	s.loadedAt = now
+	if err != nil {
+		log.Error("failed to load the api keys", "err", err)
+		return
+	}
+	keys := make(map[string]*orm.APIKeyLimit, len(limits))
+	for _, limit := range limits {
+		keys[limit.KeyHash] = limit
+	}
+	s.keys, s.loaded = keys, true
+}
@@ -0,0 +1,164 @@
+package ratelimit
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+	"gorm.io/gorm"
+
+	"bridge-history-api/config"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+const (
+	// defaultRequestsPerSecond is the rate the bucket of each client ip is refilled at by default
+	defaultRequestsPerSecond = 10
+	// bucketSweepInterval is the interval the full buckets are dropped, a dropped bucket is recreated full
+	bucketSweepInterval = 10 * time.Minute
+)
+
+// the label values of the limit of the throttled requests
+const (
+	limitIP     = "ip"
+	limitAPIKey = "api_key"
+)
+
+// bucket the token bucket of a client ip or an api key
+type bucket struct {
+	limiter *rate.Limiter
+	// the limit of the api key the bucket is created for, the bucket is recreated if the tier of the key changes
+	limit rate.Limit
+	burst int
+}
+
+// Limiter limits the rate of the requests by a token bucket per client ip, the requests carrying a known api key are
+// limited by a bucket per key with the limits of the tier of the key instead
+type Limiter struct {
+	ipLimit rate.Limit
+	ipBurst int
+	// apiKeys is nil if the api keys are disabled, the api key header is ignored then
+	apiKeys *apiKeyStore
+	now     func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+
+	throttledRequests *prometheus.CounterVec
+	rejectedAPIKeys   prometheus.Counter
+}
+
+// NewLimiter returns a Limiter of the config, the api keys are loaded from the db if enabled
+func NewLimiter(cfg *config.RateLimitConfig, db *gorm.DB, reg prometheus.Registerer) *Limiter {
+	requestsPerSecond := cfg.RequestsPerSecond
+	if requestsPerSecond == 0 {
+		requestsPerSecond = defaultRequestsPerSecond
+	}
+	limit, burst := bucketLimits(requestsPerSecond, cfg.Burst)
+	l := &Limiter{
+		ipLimit:   limit,
+		ipBurst:   burst,
+		now:       time.Now,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+		throttledRequests: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Name: "bridge_history_api_throttled_requests_total",
+			Help: "The total number of the requests rejected by the rate limiting, by the limit exceeded and the tier of the api key.",
+		}, []string{"limit", "tier"}),
+		rejectedAPIKeys: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Name: "bridge_history_api_rejected_api_keys_total",
+			Help: "The total number of the requests rejected for an unknown api key.",
+		}),
+	}
+	if cfg.APIKeys {
+		refreshInterval := time.Duration(cfg.APIKeyRefreshInterval) * time.Second
+		if refreshInterval == 0 {
+			refreshInterval = defaultAPIKeyRefreshInterval
+		}
+		l.apiKeys = newAPIKeyStore(orm.NewAPIKey(db).GetAPIKeyLimits, refreshInterval)
+	}
+	return l
+}
+
+// bucketLimits returns the limit and the burst of the bucket of the rate, the burst defaults to twice the rate and is
+// at least 1
+func bucketLimits(requestsPerSecond float64, burst int) (rate.Limit, int) {
+	if burst <= 0 {
+		burst = int(math.Ceil(2 * requestsPerSecond))
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return rate.Limit(requestsPerSecond), burst
+}
+
+// Middleware returns the handler rejecting the requests over the limits with the status 429 and the Retry-After
+// header, and the requests of the unknown api keys with the status 401. The keys of the tiers without rate aren't
+// limited.
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		now := l.now()
+		bucketKey, limit, burst, limitLabel, tier := "ip:"+ctx.ClientIP(), l.ipLimit, l.ipBurst, limitIP, ""
+		// the requests of the api keys are limited by the client ip until the keys are loaded, e.g. the db is unavailable
+		if key := ctx.GetHeader(types.APIKeyHeader); key != "" && l.apiKeys != nil {
+			keyHash := hashAPIKey(key)
+			keyLimit, known, loaded := l.apiKeys.lookup(ctx, keyHash, now)
+			switch {
+			case loaded && !known:
+				l.rejectedAPIKeys.Inc()
+				types.RenderAbort(ctx, http.StatusUnauthorized, types.ErrInvalidAPIKeyNo, errors.New("unknown api key"))
+				return
+			case known && keyLimit.RequestsPerSecond <= 0:
+				ctx.Next()
+				return
+			case known:
+				limit, burst = bucketLimits(keyLimit.RequestsPerSecond, keyLimit.Burst)
+				bucketKey, limitLabel, tier = "key:"+keyHash, limitAPIKey, keyLimit.Tier
+			}
+		}
+
+		if delay := l.reserve(bucketKey, limit, burst, now); delay > 0 {
+			l.throttledRequests.WithLabelValues(limitLabel, tier).Inc()
+			ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			types.RenderAbort(ctx, http.StatusTooManyRequests, types.ErrTooManyRequestsNo, errors.New("too many requests"))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// reserve takes a token of the bucket of the key, the delay until a token is available is returned if the bucket is
+// empty, and no token is taken then
+func (l *Limiter) reserve(key string, limit rate.Limit, burst int, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Sub(l.lastSweep) >= bucketSweepInterval {
+		// the full buckets are dropped to bound the memory, recreating them full is equivalent
+		for k, b := range l.buckets {
+			if b.limiter.TokensAt(now) >= float64(b.burst) {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+	b, ok := l.buckets[key]
+	if !ok || b.limit != limit || b.burst != burst {
+		b = &bucket{limiter: rate.NewLimiter(limit, burst), limit: limit, burst: burst}
+		l.buckets[key] = b
+	}
+	reservation := b.limiter.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if delay > 0 {
+		reservation.CancelAt(now)
+	}
+	return delay
+}
@@ -0,0 +1,143 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"bridge-history-api/config"
+	"bridge-history-api/internal/types"
+	"bridge-history-api/orm"
+)
+
+// newTestRouter returns a router limited by the limiter of the config with the clock now and the api keys of load
+func newTestRouter(cfg *config.RateLimitConfig, now *time.Time, load func(context.Context) ([]*orm.APIKeyLimit, error)) (*gin.Engine, *Limiter) {
+	limiter := NewLimiter(cfg, nil, nil)
+	limiter.now = func() time.Time { return *now }
+	limiter.lastSweep = *now
+	if load != nil {
+		limiter.apiKeys = newAPIKeyStore(load, time.Minute)
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(limiter.Middleware())
+	router.GET("/api/txs", func(ctx *gin.Context) { types.RenderSuccess(ctx, nil) })
+	return router, limiter
+}
+
+func request(router *gin.Engine, ip, apiKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/txs", nil)
+	req.RemoteAddr = ip + ":1234"
+	if apiKey != "" {
+		req.Header.Set(types.APIKeyHeader, apiKey)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestLimitByIP(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	router, limiter := newTestRouter(&config.RateLimitConfig{RequestsPerSecond: 0.5, Burst: 2}, &now, nil)
+
+	assert.Equal(t, http.StatusOK, request(router, "10.0.0.1", "").Code)
+	assert.Equal(t, http.StatusOK, request(router, "10.0.0.1", "").Code)
+	w := request(router, "10.0.0.1", "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "2", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), `"errcode":40015`)
+	assert.Equal(t, float64(1), testutil.ToFloat64(limiter.throttledRequests.WithLabelValues(limitIP, "")))
+
+	// the other ips have their own buckets, the api key header is ignored without api keys
+	assert.Equal(t, http.StatusOK, request(router, "10.0.0.2", "key").Code)
+
+	// the rejected requests take no token
+	now = now.Add(2 * time.Second)
+	assert.Equal(t, http.StatusOK, request(router, "10.0.0.1", "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, request(router, "10.0.0.1", "").Code)
+
+	// the full buckets are dropped
+	now = now.Add(bucketSweepInterval)
+	assert.Equal(t, http.StatusOK, request(router, "10.0.0.1", "").Code)
+	assert.Len(t, limiter.buckets, 1)
+}
+
+func TestLimitByAPIKey(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	var loads int
+	keys := []*orm.APIKeyLimit{
+		{KeyHash: hashAPIKey("partner"), Tier: "partner", RequestsPerSecond: 1, Burst: 3},
+		{KeyHash: hashAPIKey("internal"), Tier: "internal"},
+	}
+	router, limiter := newTestRouter(&config.RateLimitConfig{RequestsPerSecond: 1, Burst: 1}, &now, func(context.Context) ([]*orm.APIKeyLimit, error) {
+		loads++
+		return keys, nil
+	})
+
+	// the key is limited by its tier rather than by the ip
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, http.StatusOK, request(router, "10.0.0.1", "partner").Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, request(router, "10.0.0.1", "partner").Code)
+	assert.Equal(t, float64(1), testutil.ToFloat64(limiter.throttledRequests.WithLabelValues(limitAPIKey, "partner")))
+	assert.Equal(t, http.StatusOK, request(router, "10.0.0.1", "").Code)
+
+	// the tiers without rate aren't limited
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, http.StatusOK, request(router, "10.0.0.1", "internal").Code)
+	}
+
+	w := request(router, "10.0.0.1", "unknown")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), `"errcode":40016`)
+	assert.Equal(t, float64(1), testutil.ToFloat64(limiter.rejectedAPIKeys))
+	assert.Equal(t, 1, loads)
+}
+
+func TestAPIKeyStore(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	key := &orm.APIKeyLimit{KeyHash: hashAPIKey("key"), Tier: "partner", RequestsPerSecond: 1}
+	var loadErr error
+	loaded := make(chan struct{}, 1)
+	store := newAPIKeyStore(func(context.Context) ([]*orm.APIKeyLimit, error) {
+		defer func() { loaded <- struct{}{} }()
+		if loadErr != nil {
+			return nil, loadErr
+		}
+		return []*orm.APIKeyLimit{key}, nil
+	}, time.Minute)
+
+	// the keys are unknown until loaded once, the failed loads are retried after the refresh interval
+	loadErr = errors.New("db unavailable")
+	_, known, isLoaded := store.lookup(context.Background(), key.KeyHash, now)
+	<-loaded
+	assert.False(t, known)
+	assert.False(t, isLoaded)
+	_, _, isLoaded = store.lookup(context.Background(), key.KeyHash, now.Add(time.Second))
+	assert.False(t, isLoaded)
+	assert.Empty(t, loaded)
+
+	loadErr = nil
+	limit, known, isLoaded := store.lookup(context.Background(), key.KeyHash, now.Add(time.Minute))
+	<-loaded
+	assert.True(t, known)
+	assert.True(t, isLoaded)
+	assert.Equal(t, key, limit)
+
+	// the stale keys are served while reloaded in the background
+	key = &orm.APIKeyLimit{KeyHash: hashAPIKey("other"), Tier: "partner"}
+	_, known, _ = store.lookup(context.Background(), hashAPIKey("key"), now.Add(2*time.Minute))
+	assert.True(t, known)
+	<-loaded
+	assert.Eventually(t, func() bool {
+		_, known, _ := store.lookup(context.Background(), hashAPIKey("other"), now.Add(2*time.Minute))
+		return known
+	}, time.Second, 10*time.Millisecond)
+}
@@ -1,6 +1,9 @@
 package route
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -9,22 +12,252 @@ import (
 
 	"bridge-history-api/config"
 	"bridge-history-api/internal/controller"
+	"bridge-history-api/internal/openapi"
+	"bridge-history-api/internal/ratelimit"
+	"bridge-history-api/internal/types"
 	"bridge-history-api/observability"
 )
 
-// Route routes the APIs
-func Route(router *gin.Engine, conf *config.Config, reg prometheus.Registerer) {
+// networkQuery is the query parameter of every api selecting the network served, the top level network of the config
+// if absent
+const networkQuery = "network"
+
+// specVersion is the version of the apis in the openapi spec
+const specVersion = "1.0.0"
+
+// Route routes the APIs to the controllers of the network of each request, networks are keyed by the network name.
+// The requests are rate limited by the limiter, nil doesn't limit them.
+func Route(router *gin.Engine, conf *config.Config, reg prometheus.Registerer, networks map[string]*controller.Controllers, limiter *ratelimit.Limiter) {
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", types.WebhookSecretHeader, types.APIKeyHeader, types.AdminTokenHeader},
+		ExposeHeaders:    []string{"Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
 	observability.Use(router, "bridge_history_api", reg)
+	// the probes of the load balancers are not rate limited
+	probes := controller.NewHealthController(networks)
+	router.GET("/healthz", probes.Healthz)
+	router.GET("/readyz", probes.Readyz)
+	// the contexts of the handlers fall back to the contexts of the requests, which carry the spans of the requests
+	router.ContextWithFallback = true
+	router.Use(observability.Tracing())
+	if limiter != nil {
+		router.Use(limiter.Middleware())
+	}
+
+	byNetwork := func(handler func(*controller.Controllers) gin.HandlerFunc) gin.HandlerFunc {
+		return networkHandler(networks, conf.NetworkName(), handler)
+	}
+
+	// the panics of the handlers are responded in the envelope, the panic isn't exposed
+	router.Use(gin.CustomRecovery(func(ctx *gin.Context, _ interface{}) {
+		types.RenderFatal(ctx, errors.New("internal server error"))
+	}))
+	notFound := func(ctx *gin.Context) {
+		types.RenderFailure(ctx, types.ErrNotFoundNo, fmt.Errorf("unknown api %s %s", ctx.Request.Method, ctx.Request.URL.Path))
+	}
+	router.NoRoute(notFound)
+	router.NoMethod(notFound)
+
+	routes := apis(conf)
+	operations := make([]openapi.Operation, 0, len(routes))
+	for _, api := range routes {
+		router.Handle(api.Method, api.Path, byNetwork(api.handler))
+		operations = append(operations, api.Operation)
+	}
+	commonParams := []*openapi.Parameter{
+		{Name: networkQuery, In: "query", Description: "the network served, the top level network of the config if absent", Schema: &openapi.Schema{Type: "string"}},
+	}
+	if conf.RateLimit != nil && conf.RateLimit.APIKeys {
+		commonParams = append(commonParams, &openapi.Parameter{Name: types.APIKeyHeader, In: "header", Description: "the api key of the rate limit tier, the client ip is limited if absent", Schema: &openapi.Schema{Type: "string"}})
+	}
+	spec := openapi.NewGenerator(
+		openapi.Info{Title: "Bridge History API", Description: "The history of the deposits and the withdrawals of the scroll bridge", Version: specVersion},
+		types.Response{},
+		types.ErrorCodes,
+		commonParams...,
+	).Generate(operations)
+	router.GET("/api/openapi.json", func(ctx *gin.Context) { ctx.JSON(http.StatusOK, spec) })
+}
+
+// api an api served by the controllers of the network of each request, documented in the openapi spec
+type api struct {
+	openapi.Operation
+	handler func(*controller.Controllers) gin.HandlerFunc
+}
+
+// apis returns the apis of the config, the spec of the handlers is generated from the structs they bind and render
+func apis(conf *config.Config) []api {
+	apis := []api{
+		{
+			Operation: openapi.Operation{Method: http.MethodPost, Path: "/api/txsbyhashes", Summary: "get the txs of the hashes, at most 10 hashes",
+				Body: types.QueryByHashRequest{}, Data: types.ResultData{}},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.History.PostQueryTxsByHash },
+		},
+		{
+			Operation: openapi.Operation{Method: http.MethodPost, Path: "/api/txsbyfilter", Summary: "get the txs matching the filter, at least one address or tx hash is required",
+				Body: types.QueryByFilterRequest{}, Data: types.ResultData{}},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.History.PostQueryTxsByFilter },
+		},
+		{
+			Operation: openapi.Operation{Method: http.MethodPost, Path: "/api/export", Summary: "stream the txs matching the filter in csv or ndjson from the offset on, a record per line",
+				Body: types.ExportTxsRequest{}, Data: types.TxExportRecord{}, Raw: true},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.History.PostExportTxs },
+		},
+		{
+			Operation: openapi.Operation{Method: http.MethodGet, Path: "/api/claimable", Summary: "get all the claimable withdrawals of the address",
+				Params: types.QueryByAddressRequest{}, Data: types.ResultData{}},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.History.GetAllClaimableTxsByAddr },
+		},
+		{
+			Operation: openapi.Operation{Method: http.MethodGet, Path: "/api/claimable/summary/:address", Summary: "get the counts and the values by token of the claimable withdrawals of the address",
+				Params: types.QueryClaimableSummaryRequest{}, Data: types.ClaimableSummary{}},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.History.GetClaimableSummary },
+		},
+		{
+			Operation: openapi.Operation{Method: http.MethodGet, Path: "/api/txs", Summary: "get the txs of the address, paginated by the opaque cursor",
+				Params: types.QueryByAddressWithCursorRequest{}, Data: types.KeysetResultData{}},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.History.GetTxsByAddr },
+		},
+		{
+			Operation: openapi.Operation{Method: http.MethodGet, Path: "/api/txs/:hash/status", Summary: "get the lifecycle state of the message sent by the tx",
+				Params: types.QueryTxStatusRequest{}, Data: types.MsgStatus{}},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.History.GetTxStatus },
+		},
+		{
+			Operation: openapi.Operation{Method: http.MethodGet, Path: "/api/withdrawals/:nonce/proof", Summary: "get the claim info of the withdrawal of the nonce with its proof regenerated",
+				Params: types.QueryWithdrawProofRequest{}, Data: types.UserClaimInfo{}},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.History.GetWithdrawProof },
+		},
+		{
+			Operation: openapi.Operation{Method: http.MethodGet, Path: "/api/claimtx", Summary: "get the layer1 tx claiming the claimable withdrawal of the msg hash",
+				Params: types.QueryClaimTxRequest{}, Data: types.ClaimTx{}},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.History.GetClaimTx },
+		},
+		{
+			Operation: openapi.Operation{Method: http.MethodGet, Path: "/api/claimablepage", Summary: "get the claimable withdrawals of the address, paginated by the opaque cursor",
+				Params: types.QueryByAddressWithCursorRequest{}, Data: types.KeysetResultData{}},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.History.GetClaimableTxsByAddrWithCursor },
+		},
+		{
+			Operation: openapi.Operation{Method: http.MethodGet, Path: "/api/batchtxs", Summary: "get the withdrawals sent in the layer2 blocks of the rollup batch of the index",
+				Params: types.QueryTxsByBatchIndexRequest{}, Data: types.ResultData{}},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.History.GetTxsByBatchIndex },
+		},
+		{
+			Operation: openapi.Operation{Method: http.MethodGet, Path: "/api/txsbytime", Summary: "get the txs of all addresses whose block timestamp is in the range, ordered by block timestamp",
+				Params: types.QueryByTimeRangeRequest{}, Data: types.ResultData{}},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.History.GetTxsByTimeRange },
+		},
+		{
+			Operation: openapi.Operation{Method: http.MethodGet, Path: "/api/batches", Summary: "get the rollup batches from the latest one with their withdrawal counts and finalize txs",
+				Params: types.Pagination{}, Data: types.BatchResultData{}},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.Batch.GetBatches },
+		},
+		{
+			Operation: openapi.Operation{Method: http.MethodGet, Path: "/api/stats/volume", Summary: "get the number of the txs and the bridged amount of each direction and token per UTC day",
+				Params: types.QueryVolumeStatsRequest{}, Data: []*types.DailyVolume{}},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.Stats.GetVolumes },
+		},
+		{
+			Operation: openapi.Operation{Method: http.MethodGet, Path: "/api/stats/txcounts", Summary: "get the number of the deposits and the withdrawals sent per UTC day",
+				Params: types.QueryStatsRequest{}, Data: []*types.DailyTxCount{}},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.Stats.GetTxCounts },
+		},
+		{
+			Operation: openapi.Operation{Method: http.MethodGet, Path: "/api/stats/finalizetime", Summary: "get the number of the txs of each direction relayed per UTC day and their average finalize time",
+				Params: types.QueryStatsRequest{}, Data: []*types.DailyFinalizeTime{}},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.Stats.GetFinalizeTimes },
+		},
+		{
+			Operation: openapi.Operation{Method: http.MethodGet, Path: "/api/stats/pendingwithdrawals", Summary: "get the number of the withdrawals not relayed on layer1 yet",
+				Data: types.PendingWithdrawals{}},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.Stats.GetPendingWithdrawals },
+		},
+		{
+			// the graphql responses follow the graphql spec rather than the envelope
+			Operation: openapi.Operation{Method: http.MethodPost, Path: "/api/graphql", Summary: "query the txs with graphql, see the schema of internal/graph",
+				Body: types.GraphQLRequest{}, Data: map[string]interface{}{}, Raw: true},
+			handler: func(c *controller.Controllers) gin.HandlerFunc { return c.GraphQL.PostQuery },
+		},
+	}
+	if conf.Webhooks != nil {
+		apis = append(apis,
+			api{
+				Operation: openapi.Operation{Method: http.MethodPost, Path: "/api/webhooks", Summary: "register a webhook notified of the withdrawals and the deposits of the address",
+					Body: types.RegisterWebhookRequest{}, Data: types.Webhook{}},
+				handler: func(c *controller.Controllers) gin.HandlerFunc { return c.Webhook.PostRegisterWebhook },
+			},
+			api{
+				Operation: openapi.Operation{Method: http.MethodPut, Path: "/api/webhooks/:id", Summary: "replace the secret of the webhook of the id, authenticated by its current secret",
+					Params: types.DeleteWebhookRequest{}, Body: types.UpdateWebhookRequest{}, Data: types.Webhook{}, Headers: []*openapi.Parameter{{Name: types.WebhookSecretHeader, In: "header", Required: true, Schema: &openapi.Schema{Type: "string"}}}},
+				handler: func(c *controller.Controllers) gin.HandlerFunc { return c.Webhook.PutUpdateWebhook },
+			},
+			api{
+				Operation: openapi.Operation{Method: http.MethodDelete, Path: "/api/webhooks/:id", Summary: "delete the webhook of the id, authenticated by its secret",
+					Params: types.DeleteWebhookRequest{}, Headers: []*openapi.Parameter{{Name: types.WebhookSecretHeader, In: "header", Required: true, Schema: &openapi.Schema{Type: "string"}}}},
+				handler: func(c *controller.Controllers) gin.HandlerFunc { return c.Webhook.DeleteWebhook },
+			},
+		)
+	}
+	if conf.Server != nil && conf.Server.AdminToken != "" {
+		adminToken := []*openapi.Parameter{{Name: types.AdminTokenHeader, In: "header", Required: true, Schema: &openapi.Schema{Type: "string"}}}
+		apis = append(apis,
+			api{
+				Operation: openapi.Operation{Method: http.MethodGet, Path: "/api/admin/gaps", Summary: "scan the checkpoints and the nonces of the indexed events for the gaps",
+					Data: types.EventGaps{}, Headers: adminToken},
+				handler: func(c *controller.Controllers) gin.HandlerFunc { return c.Admin.GetGaps },
+			},
+			api{
+				Operation: openapi.Operation{Method: http.MethodPost, Path: "/api/admin/refetchtx", Summary: "fetch the events of the tx again from the node, the cross messages of the tx are replaced",
+					Body: types.AdminRefetchTxRequest{}, Data: types.AdminActionResult{}, Headers: adminToken},
+				handler: func(c *controller.Controllers) gin.HandlerFunc { return c.Admin.PostRefetchTx },
+			},
+			api{
+				Operation: openapi.Operation{Method: http.MethodPost, Path: "/api/admin/recomputeproof", Summary: "rebuild the proof of the finalized withdrawal from the stored messages and save it",
+					Body: types.AdminMsgRequest{}, Data: types.AdminActionResult{}, Headers: adminToken},
+				handler: func(c *controller.Controllers) gin.HandlerFunc { return c.Admin.PostRecomputeProof },
+			},
+			api{
+				Operation: openapi.Operation{Method: http.MethodPost, Path: "/api/admin/reenrich", Summary: "read the fees, the origin method and the block timestamp of the cross message again",
+					Body: types.AdminMsgRequest{}, Data: types.AdminActionResult{}, Headers: adminToken},
+				handler: func(c *controller.Controllers) gin.HandlerFunc { return c.Admin.PostReenrichMsg },
+			},
+			api{
+				Operation: openapi.Operation{Method: http.MethodPost, Path: "/api/admin/deleterow", Summary: "soft delete the orphaned row of a message table",
+					Body: types.AdminDeleteRowRequest{}, Data: types.AdminActionResult{}, Headers: adminToken},
+				handler: func(c *controller.Controllers) gin.HandlerFunc { return c.Admin.PostDeleteRow },
+			},
+		)
+	}
+	// the events are pushed as json messages of the websocket, the failures before the upgrade are responded in the
+	// envelope
+	apis = append(apis, api{
+		Operation: openapi.Operation{Method: http.MethodGet, Path: "/ws/claimable/:address", Summary: "watch the claimable events of the address over a websocket",
+			Data: types.ClaimableEvent{}, Raw: true},
+		handler: func(c *controller.Controllers) gin.HandlerFunc { return c.ClaimableWatch.WatchClaimables },
+	})
+	return apis
+}
 
-	r := router.Group("api/")
-	r.POST("/txsbyhashes", controller.HistoryCtrler.PostQueryTxsByHash)
-	r.GET("/claimable", controller.HistoryCtrler.GetAllClaimableTxsByAddr)
+// networkHandler returns the handler dispatching the requests to the handler of the controllers of the network of the
+// network query parameter, the requests of unknown networks are rejected
+func networkHandler(networks map[string]*controller.Controllers, defaultNetwork string, handler func(*controller.Controllers) gin.HandlerFunc) gin.HandlerFunc {
+	handlers := make(map[string]gin.HandlerFunc, len(networks))
+	for name, controllers := range networks {
+		handlers[name] = handler(controllers)
+	}
+	return func(ctx *gin.Context) {
+		network := ctx.DefaultQuery(networkQuery, defaultNetwork)
+		networkHandler, ok := handlers[network]
+		if !ok {
+			types.RenderFailure(ctx, types.ErrParameterInvalidNo, fmt.Errorf("unknown network %q", network))
+			return
+		}
+		networkHandler(ctx)
+	}
 }
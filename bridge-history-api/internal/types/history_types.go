@@ -22,6 +22,271 @@ const (
 	ErrGetTxsByAddrFailure = 40004
 	// ErrGetWithdrawRootByBatchIndexFailure is getting withdraw root by batch index error
 	ErrGetWithdrawRootByBatchIndexFailure = 40005
+	// ErrWatchClaimablesFailure is watching the claimable txs error
+	ErrWatchClaimablesFailure = 40006
+	// ErrGetTxStatusFailure is getting the status of the message of a tx error
+	ErrGetTxStatusFailure = 40007
+	// ErrGetWithdrawProofFailure is regenerating the proof of a withdrawal error
+	ErrGetWithdrawProofFailure = 40008
+	// ErrGetTxsByFilterFailure is getting txs by filter error
+	ErrGetTxsByFilterFailure = 40009
+	// ErrRegisterWebhookFailure is registering a webhook error
+	ErrRegisterWebhookFailure = 40010
+	// ErrDeleteWebhookFailure is deleting a webhook error
+	ErrDeleteWebhookFailure = 40011
+	// ErrGetClaimTxFailure is building the claim tx of a withdrawal error
+	ErrGetClaimTxFailure = 40012
+	// ErrGetStatsFailure is getting the bridge stats error
+	ErrGetStatsFailure = 40013
+	// ErrNotFoundNo is the queried record or the requested api not found
+	ErrNotFoundNo = 40014
+	// ErrTooManyRequestsNo is the rate limit of the client ip or the api key exceeded
+	ErrTooManyRequestsNo = 40015
+	// ErrInvalidAPIKeyNo is the api key unknown
+	ErrInvalidAPIKeyNo = 40016
+	// ErrUnavailableNo is the server unhealthy or not ready, see the checks of the data
+	ErrUnavailableNo = 40017
+	// ErrExportTxsFailure is exporting the txs by filter error
+	ErrExportTxsFailure = 40018
+	// ErrResolveENSNameFailure is resolving the ENS name of the address parameter error
+	ErrResolveENSNameFailure = 40019
+	// ErrScanGapsFailure is scanning the indexed events for the gaps error
+	ErrScanGapsFailure = 40020
+	// ErrInvalidAdminTokenNo is the admin token of the admin apis missing or wrong
+	ErrInvalidAdminTokenNo = 40021
+	// ErrAdminActionFailure is running an admin action error, the failed actions are audited too
+	ErrAdminActionFailure = 40022
+	// ErrGetTxsByBatchIndexFailure is getting the withdrawals of a batch error
+	ErrGetTxsByBatchIndexFailure = 40023
+	// ErrGetTxsByTimeRangeFailure is getting the txs by block timestamp range error
+	ErrGetTxsByTimeRangeFailure = 40024
+	// ErrGetBatchesFailure is listing the rollup batches error
+	ErrGetBatchesFailure = 40025
+	// ErrGetClaimableSummaryFailure is getting the claimable summary of the address error
+	ErrGetClaimableSummaryFailure = 40026
+	// ErrConflictNo is the record to create already exists
+	ErrConflictNo = 40027
+	// ErrUpdateWebhookFailure is updating a webhook error
+	ErrUpdateWebhookFailure = 40028
+)
+
+// ErrorCodes describes the error codes of the responses, the api specific codes report the failures other than the
+// invalid parameters, the records not found and the fatal errors
+var ErrorCodes = map[int]string{
+	Success:                               "success",
+	InternalServerError:                   "the server fails to serve the request, e.g. the database is unavailable",
+	ErrParameterInvalidNo:                 "the parameters are invalid",
+	ErrGetClaimablesFailure:               "getting the claimable txs failed",
+	ErrGetTxsByHashFailure:                "getting the txs by hash failed",
+	ErrGetTxsByAddrFailure:                "getting the txs by address failed",
+	ErrGetWithdrawRootByBatchIndexFailure: "getting the withdraw root of the batch failed",
+	ErrWatchClaimablesFailure:             "watching the claimable txs failed",
+	ErrGetTxStatusFailure:                 "getting the status of the tx failed",
+	ErrGetWithdrawProofFailure:            "regenerating the proof of the withdrawal failed",
+	ErrGetTxsByFilterFailure:              "getting the txs by filter failed",
+	ErrRegisterWebhookFailure:             "registering the webhook failed",
+	ErrDeleteWebhookFailure:               "deleting the webhook failed",
+	ErrGetClaimTxFailure:                  "building the claim tx failed",
+	ErrGetStatsFailure:                    "getting the bridge stats failed",
+	ErrNotFoundNo:                         "the queried record or the requested api is not found",
+	ErrTooManyRequestsNo:                  "the rate limit is exceeded, retry after the seconds of the Retry-After header",
+	ErrInvalidAPIKeyNo:                    "the api key is unknown",
+	ErrUnavailableNo:                      "the server is unhealthy or not ready, the failed checks are returned",
+	ErrExportTxsFailure:                   "exporting the txs by filter failed",
+	ErrResolveENSNameFailure:              "resolving the ENS name of the address failed",
+	ErrScanGapsFailure:                    "scanning the indexed events for the gaps failed",
+	ErrInvalidAdminTokenNo:                "the admin token is missing or invalid",
+	ErrAdminActionFailure:                 "the admin action failed",
+	ErrGetTxsByBatchIndexFailure:          "getting the withdrawals of the batch failed",
+	ErrGetTxsByTimeRangeFailure:           "getting the txs by block timestamp range failed",
+	ErrGetBatchesFailure:                  "listing the rollup batches failed",
+	ErrGetClaimableSummaryFailure:         "getting the claimable summary of the address failed",
+	ErrConflictNo:                         "the record to create already exists",
+	ErrUpdateWebhookFailure:               "updating the webhook failed",
+}
+
+// ClaimStatus the claim status of a layer2 withdrawal on layer1
+type ClaimStatus int
+
+const (
+	// ClaimStatusUnknown the withdrawal is not found
+	ClaimStatusUnknown ClaimStatus = iota
+	// ClaimStatusPending the batch of the withdrawal is not finalized on layer1 yet
+	ClaimStatusPending
+	// ClaimStatusClaimable the batch of the withdrawal is finalized on layer1, but the withdrawal is not claimed yet
+	ClaimStatusClaimable
+	// ClaimStatusClaimed the withdrawal is relayed on layer1
+	ClaimStatusClaimed
+	// ClaimStatusRebatchPending the batch of the withdrawal is reverted on layer1, the batch the withdrawal is
+	// committed in again is not finalized yet
+	ClaimStatusRebatchPending
+)
+
+// OperationType the label classifying a tx history by its direction and status
+type OperationType string
+
+const (
+	// OperationTypeDepositPending the deposit is not relayed on layer2 yet
+	OperationTypeDepositPending OperationType = "DepositPending"
+	// OperationTypeDeposit the deposit is relayed on layer2
+	OperationTypeDeposit OperationType = "Deposit"
+	// OperationTypeDepositFailed the relay of the deposit failed on layer2
+	OperationTypeDepositFailed OperationType = "DepositFailed"
+	// OperationTypeDepositDropped the deposit is dropped on layer1 before relayed, its value is refunded
+	OperationTypeDepositDropped OperationType = "DepositDropped"
+	// OperationTypeDepositRefunded the deposit is dropped on layer1 before relayed and its gateway refunded its value
+	OperationTypeDepositRefunded OperationType = "DepositRefunded"
+	// OperationTypeWithdrawalPending the batch of the withdrawal is not finalized on layer1 yet
+	OperationTypeWithdrawalPending OperationType = "WithdrawalPending"
+	// OperationTypeWithdrawalClaimable the withdrawal can be claimed on layer1
+	OperationTypeWithdrawalClaimable OperationType = "WithdrawalClaimable"
+	// OperationTypeWithdrawalClaimed the withdrawal is claimed on layer1
+	OperationTypeWithdrawalClaimed OperationType = "WithdrawalClaimed"
+	// OperationTypeWithdrawalClaimFailed the claim of the withdrawal failed on layer1, it can be claimed again
+	OperationTypeWithdrawalClaimFailed OperationType = "WithdrawalClaimFailed"
+)
+
+// TxStatus the lifecycle status of a tx history, the transitions are
+//
+//	deposit:    Pending -> Relayed
+//	            Pending -> Failed -> Relayed, the failed relay is executed again on layer2
+//	            Pending -> Dropped, the deposit is dropped on layer1 and its value is refunded
+//	            Pending -> Refunded, the deposit is dropped on layer1 and the refund of its gateway is indexed
+//	withdrawal: Pending -> Claimable -> Claimed
+//
+// A withdrawal stays Pending until its batch is finalized and its proof is available, also after a batch revert
+// until the batch it's committed in again is finalized. A relayed deposit stays Pending until its layer1 block reaches
+// the finality the deposits are credited at, if configured.
+type TxStatus string
+
+const (
+	// TxStatusPending the deposit is not relayed on layer2 yet, or the withdrawal can't be claimed on layer1 yet
+	TxStatusPending TxStatus = "Pending"
+	// TxStatusRelayed the deposit is relayed on layer2
+	TxStatusRelayed TxStatus = "Relayed"
+	// TxStatusFailed the relay of the deposit failed on layer2, it requires the manual execution
+	TxStatusFailed TxStatus = "Failed"
+	// TxStatusDropped the deposit is dropped on layer1 before relayed, its value is refunded to the sender
+	TxStatusDropped TxStatus = "Dropped"
+	// TxStatusRefunded the deposit is dropped on layer1 before relayed and its gateway refunded the ETH or the tokens
+	TxStatusRefunded TxStatus = "Refunded"
+	// TxStatusClaimable the withdrawal has a proof against a finalized batch and isn't relayed on layer1 yet
+	TxStatusClaimable TxStatus = "Claimable"
+	// TxStatusClaimed the withdrawal is relayed on layer1
+	TxStatusClaimed TxStatus = "Claimed"
+)
+
+// L1FinalityStatus the finality of the layer1 block of a deposit, by the safe and the finalized block tags of the
+// layer1 node
+type L1FinalityStatus string
+
+const (
+	// L1FinalityStatusUnsafe the layer1 block of the deposit is not safe yet, it may still be reorged out
+	L1FinalityStatusUnsafe L1FinalityStatus = "Unsafe"
+	// L1FinalityStatusSafe the layer1 block of the deposit is justified by the beacon chain but not finalized yet
+	L1FinalityStatusSafe L1FinalityStatus = "Safe"
+	// L1FinalityStatusFinalized the layer1 block of the deposit is finalized, it can't be reorged out
+	L1FinalityStatusFinalized L1FinalityStatus = "Finalized"
+)
+
+// MsgState the normalized lifecycle state of a bridge message, the transitions are
+//
+//	deposit:    Sent -> Relayed
+//	            Sent -> Failed -> Relayed, the failed relay is executed again on layer2
+//	withdrawal: Sent -> Committed -> Finalized -> Claimed
+//	            Finalized -> Failed -> Claimed, the failed claim is submitted again on layer1
+//
+// A committed withdrawal is Sent again if its batch is reverted, until the batch it's committed in again. A message
+// of any state is Dropped if the tx sending it is reorged out of the source layer.
+type MsgState string
+
+const (
+	// MsgStateSent the message is sent on the source layer
+	MsgStateSent MsgState = "Sent"
+	// MsgStateCommitted the withdrawal is committed in a batch on layer1, the batch is not finalized yet
+	MsgStateCommitted MsgState = "Committed"
+	// MsgStateFinalized the batch of the withdrawal is finalized on layer1, the withdrawal can be claimed
+	MsgStateFinalized MsgState = "Finalized"
+	// MsgStateRelayed the deposit is relayed on layer2
+	MsgStateRelayed MsgState = "Relayed"
+	// MsgStateClaimed the withdrawal is claimed on layer1
+	MsgStateClaimed MsgState = "Claimed"
+	// MsgStateDropped the tx sending the message is reorged out of the source layer
+	MsgStateDropped MsgState = "Dropped"
+	// MsgStateFailed the relay of the message failed on the target layer and it's not relayed since
+	MsgStateFailed MsgState = "Failed"
+)
+
+// ClaimableSortBy the sort order of the claimable txs
+type ClaimableSortBy string
+
+const (
+	// ClaimableSortByDefault keeps the claimable txs ordered by the latest sent first
+	ClaimableSortByDefault ClaimableSortBy = ""
+	// ClaimableSortByExpirySoonest orders the claimable txs by ClaimExpiresAt asc, the non expiring ones come last
+	ClaimableSortByExpirySoonest ClaimableSortBy = "expiry_soonest"
+)
+
+// RecipientType the kind of the recipient account of a message on the target layer
+type RecipientType string
+
+const (
+	// RecipientTypeAll does not filter by the recipient
+	RecipientTypeAll RecipientType = ""
+	// RecipientTypeEOA the recipient has no code on the target layer
+	RecipientTypeEOA RecipientType = "eoa"
+	// RecipientTypeContract the recipient has code on the target layer
+	RecipientTypeContract RecipientType = "contract"
+)
+
+// TokenType the category of the token bridged by a message
+type TokenType string
+
+const (
+	// TokenTypeAll does not filter by the token type
+	TokenTypeAll TokenType = ""
+	// TokenTypeETH native ETH, the messages without token addresses are native ETH
+	TokenTypeETH TokenType = "ETH"
+	// TokenTypeERC20 ERC20 tokens
+	TokenTypeERC20 TokenType = "ERC20"
+	// TokenTypeNFT ERC721 and ERC1155 tokens, it only filters the txs, which are typed TokenTypeERC721 or TokenTypeERC1155
+	TokenTypeNFT TokenType = "NFT"
+	// TokenTypeERC721 ERC721 tokens
+	TokenTypeERC721 TokenType = "ERC721"
+	// TokenTypeERC1155 ERC1155 tokens
+	TokenTypeERC1155 TokenType = "ERC1155"
+)
+
+// SortOrder the direction the txs are sorted in by block timestamp, ties are broken by the tx hash
+type SortOrder string
+
+const (
+	// SortOrderDesc the latest txs first
+	SortOrderDesc SortOrder = ""
+	// SortOrderAsc the oldest txs first
+	SortOrderAsc SortOrder = "asc"
+)
+
+// TxDirection the direction of the txs to filter by
+type TxDirection string
+
+const (
+	// TxDirectionAll does not filter by the direction
+	TxDirectionAll TxDirection = ""
+	// TxDirectionDeposit the deposits from layer1 to layer2
+	TxDirectionDeposit TxDirection = "deposit"
+	// TxDirectionWithdraw the withdrawals from layer2 to layer1
+	TxDirectionWithdraw TxDirection = "withdraw"
+)
+
+// ClaimableEventType the change of the claimable txs of an address pushed to its watchers
+type ClaimableEventType string
+
+const (
+	// ClaimableEventClaimable the proof of a withdrawal is generated, it shows up in the claimable txs
+	ClaimableEventClaimable ClaimableEventType = "claimable"
+	// ClaimableEventFinalized the withdrawal is relayed on layer1, it's removed from the claimable txs
+	ClaimableEventFinalized ClaimableEventType = "finalized"
 )
 
 // QueryByAddressRequest the request parameter of address api
@@ -29,9 +294,92 @@ type QueryByAddressRequest struct {
 	Address string `form:"address" binding:"required"`
 }
 
+// Pagination the pagination parameters of list apis, page starts from 1
+type Pagination struct {
+	Page     uint64 `form:"page"`
+	PageSize uint64 `form:"page_size"`
+}
+
+// TxRange bounds the txs by the block number of the layer the tx is sent on, or by the block timestamp in unix seconds
+// if ByTimestamp is set, the bounds are inclusive and the nil bounds are open
+type TxRange struct {
+	From        *uint64 `form:"from"`
+	To          *uint64 `form:"to"`
+	ByTimestamp bool    `form:"by_timestamp"`
+}
+
+// CursorPagination the cursor pagination parameters, the first page is returned if the cursor is not given
+type CursorPagination struct {
+	Cursor   *uint64 `form:"cursor"`
+	PageSize uint64  `form:"page_size"`
+}
+
+// KeysetPagination the keyset pagination parameters, Cursor is the opaque cursor returned with the previous page and
+// the first page is returned if it's empty
+type KeysetPagination struct {
+	Cursor   string `form:"cursor"`
+	PageSize uint64 `form:"page_size"`
+}
+
+// QueryByAddressWithCursorRequest the request parameter of the keyset paginated address apis
+type QueryByAddressWithCursorRequest struct {
+	Address string `form:"address" binding:"required"`
+	KeysetPagination
+}
+
 // QueryByHashRequest the request parameter of hash api
 type QueryByHashRequest struct {
-	Txs []string `raw:"txs" binding:"required"`
+	Txs []string `json:"txs" binding:"required"`
+}
+
+// TxFilter filters the txs, the filters are combined with AND and the values of a filter with OR, the empty filters
+// don't filter. At least one address or tx hash is required.
+type TxFilter struct {
+	Addresses []string    `json:"addresses"`
+	Txs       []string    `json:"txs"`
+	Tokens    []string    `json:"tokens"`
+	Direction TxDirection `json:"direction"`
+	Statuses  []TxStatus  `json:"statuses"`
+	// StartTime and EndTime bound the block timestamp of the txs in unix seconds, the bounds are inclusive and the nil
+	// bounds are open
+	StartTime *uint64 `json:"startTime"`
+	EndTime   *uint64 `json:"endTime"`
+}
+
+// QueryByFilterRequest the request parameter of filter api, page starts from 1
+type QueryByFilterRequest struct {
+	TxFilter
+	Order    SortOrder `json:"order"`
+	Page     uint64    `json:"page"`
+	PageSize uint64    `json:"pageSize"`
+}
+
+// TxExportFormat the format of the tx exports
+type TxExportFormat string
+
+const (
+	// TxExportFormatCSV a header row and a row per tx, the nested fields flattened
+	TxExportFormatCSV TxExportFormat = "csv"
+	// TxExportFormatNDJSON a TxExportRecord per line
+	TxExportFormatNDJSON TxExportFormat = "ndjson"
+)
+
+// ExportTxsRequest the request parameter of the export api, the txs matching the filter from the offset on
+type ExportTxsRequest struct {
+	TxFilter
+	// Order is asc if empty, so that the txs sent meanwhile are exported last and don't shift the offsets
+	Order SortOrder `json:"order"`
+	// Format is csv if empty
+	Format TxExportFormat `json:"format"`
+	// Offset is the position in the matched txs the export starts at, the offset of the last record received plus one
+	// resumes an interrupted export
+	Offset uint64 `json:"offset"`
+}
+
+// TxExportRecord a tx of an export along with its position in the matched txs
+type TxExportRecord struct {
+	Offset uint64 `json:"offset"`
+	*TxHistoryInfo
 }
 
 // QueryByBatchIndexRequest the request parameter of batch index api
@@ -40,13 +388,414 @@ type QueryByBatchIndexRequest struct {
 	BatchIndex uint64 `form:"batch_index" binding:"required"`
 }
 
+// QueryTxsByBatchIndexRequest the request parameter of the batch txs api, page starts from 1
+type QueryTxsByBatchIndexRequest struct {
+	QueryByBatchIndexRequest
+	Pagination
+}
+
+// QueryByTimeRangeRequest the request parameter of the time range api, StartTime and EndTime bound the block
+// timestamp of the txs of all addresses in unix seconds and the bounds are inclusive. page starts from 1
+type QueryByTimeRangeRequest struct {
+	StartTime uint64      `form:"start_time" binding:"required"`
+	EndTime   uint64      `form:"end_time" binding:"required"`
+	Direction TxDirection `form:"direction"`
+	Order     SortOrder   `form:"order"`
+	Pagination
+}
+
+// QueryTxStatusRequest the request parameter of tx status api
+type QueryTxStatusRequest struct {
+	Hash string `uri:"hash" binding:"required"`
+}
+
+// QueryClaimableSummaryRequest the request parameter of claimable summary api, the address is a hex address or an ENS
+// name
+type QueryClaimableSummaryRequest struct {
+	Address string `uri:"address" binding:"required"`
+}
+
+// QueryWithdrawProofRequest the request parameter of withdraw proof api, the nonce isn't required since 0 is a nonce
+type QueryWithdrawProofRequest struct {
+	Nonce uint64 `uri:"nonce"`
+}
+
+// QueryClaimTxRequest the request parameter of claim tx api, from is the account sending the claim, the zero address
+// if empty
+type QueryClaimTxRequest struct {
+	MsgHash string `form:"msg_hash" binding:"required"`
+	From    string `form:"from"`
+}
+
+// QueryStatsRequest the request parameter of the daily stats apis, the dates are the UTC days formatted 2006-01-02 and
+// the range is inclusive, the last days up to today are returned by default
+type QueryStatsRequest struct {
+	StartDate string `form:"start_date"`
+	EndDate   string `form:"end_date"`
+}
+
+// QueryVolumeStatsRequest the request parameter of volume stats api, the volumes of the other tokens are skipped if
+// the layer1 token address is given
+type QueryVolumeStatsRequest struct {
+	QueryStatsRequest
+	Token string `form:"token"`
+}
+
+// RegisterWebhookRequest the request parameter of webhook registration api, the secret signs the payloads delivered.
+// The signature is the EIP-191 personal signature of the address over the WebhookRegistrationMessage of the url and
+// the timestamp in unix seconds, proving the address is owned by the caller
+type RegisterWebhookRequest struct {
+	Address   string `json:"address" binding:"required"`
+	URL       string `json:"url" binding:"required"`
+	Secret    string `json:"secret" binding:"required"`
+	Timestamp int64  `json:"timestamp" binding:"required"`
+	Signature string `json:"signature" binding:"required"`
+}
+
+// UpdateWebhookRequest the body of webhook update api, the id is the path parameter of DeleteWebhookRequest and the
+// current secret of the webhook is sent in the WebhookSecretHeader header
+type UpdateWebhookRequest struct {
+	Secret string `json:"secret" binding:"required"`
+}
+
+// DeleteWebhookRequest the request parameter of webhook deletion and update apis, the secret of the webhook is sent
+// in the WebhookSecretHeader header
+type DeleteWebhookRequest struct {
+	ID uint64 `uri:"id" binding:"required"`
+}
+
+// AdminRefetchTxRequest the request parameter of the admin api fetching the events of a tx again, layer is 1 for a
+// layer1 tx and 2 for a layer2 tx
+type AdminRefetchTxRequest struct {
+	Layer  int    `json:"layer" binding:"required"`
+	TxHash string `json:"tx_hash" binding:"required"`
+}
+
+// AdminMsgRequest the request parameter of the admin apis correcting a message by its hash
+type AdminMsgRequest struct {
+	MsgHash string `json:"msg_hash" binding:"required"`
+}
+
+// AdminDeleteRowRequest the request parameter of the admin api soft deleting a row, table is cross_message,
+// relayed_msg or l2_sent_msg
+type AdminDeleteRowRequest struct {
+	Table string `json:"table" binding:"required"`
+	ID    uint64 `json:"id" binding:"required"`
+}
+
+// GraphQLRequest the request parameter of graphql api
+type GraphQLRequest struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
 // ResultData contains return txs and total
 type ResultData struct {
 	Result []*TxHistoryInfo `json:"result"`
 	Total  uint64           `json:"total"`
 }
 
-// Response the response schema
+// ClaimableResultData contains return claimable txs, total and the total estimated gas of claiming all of them
+type ClaimableResultData struct {
+	Result            []*TxHistoryInfo `json:"result"`
+	Total             uint64           `json:"total"`
+	TotalEstimatedGas uint64           `json:"totalEstimatedGas"`
+}
+
+// ClaimableSplitResultData contains return claimable txs split by the proof readiness, Ready can be claimed now
+// and ProofPending are waiting for the finalization of their batch or the regeneration of their pruned proof
+type ClaimableSplitResultData struct {
+	Ready        []*TxHistoryInfo `json:"ready"`
+	ProofPending []*TxHistoryInfo `json:"proofPending"`
+	Total        uint64           `json:"total"`
+}
+
+// CursorResultData contains return txs and the cursor of the next page, the cursor is nil on the last page
+type CursorResultData struct {
+	Result     []*TxHistoryInfo `json:"result"`
+	NextCursor *uint64          `json:"nextCursor"`
+}
+
+// KeysetResultData contains return txs and the opaque cursor of the next page, the cursor is empty on the last page
+type KeysetResultData struct {
+	Result     []*TxHistoryInfo `json:"result"`
+	NextCursor string           `json:"nextCursor"`
+}
+
+// AggregatableClaimGroup the claimable messages of the same batch which can be claimed together,
+// Proofs are the merkle proofs of the messages in the same order as Txs, all against the withdraw root of the batch
+type AggregatableClaimGroup struct {
+	BatchIndex uint64           `json:"batchIndex"`
+	BatchHash  string           `json:"batchHash"`
+	Txs        []*TxHistoryInfo `json:"txs"`
+	Proofs     []string         `json:"proofs"`
+}
+
+// BatchInfo the schema of rollup batch infos
+type BatchInfo struct {
+	BatchIndex   uint64 `json:"batchIndex"`
+	BatchHash    string `json:"batchHash"`
+	WithdrawRoot string `json:"withdrawRoot"`
+	// StateRoot is the post state root of the batch, empty if the batch is not finalized
+	StateRoot string `json:"stateRoot"`
+	// TreeLeafCount is the number of the leaves in the withdraw tree at the batch, 0 if unknown
+	TreeLeafCount uint64 `json:"treeLeafCount"`
+	// IsGenesisBatch is true for the genesis batch 0 only
+	IsGenesisBatch bool `json:"isGenesisBatch"`
+}
+
+// BatchSummary the schema of a rollup batch in the batch lists
+type BatchSummary struct {
+	BatchIndex       uint64 `json:"batchIndex"`
+	BatchHash        string `json:"batchHash"`
+	StartBlockNumber uint64 `json:"startBlockNumber"`
+	EndBlockNumber   uint64 `json:"endBlockNumber"`
+	CommitTxHash     string `json:"commitTxHash"`
+	// FinalizeTxHash and FinalizeTimestamp are empty if the batch is not finalized
+	FinalizeTxHash    string     `json:"finalizeTxHash"`
+	FinalizeTimestamp *time.Time `json:"finalizeTimestamp"`
+	IsFinalized       bool       `json:"isFinalized"`
+	// MsgCount is the number of the withdrawals sent in the layer2 blocks of the batch
+	MsgCount uint64 `json:"msgCount"`
+}
+
+// BatchResultData contains the returned batches and total
+type BatchResultData struct {
+	Result []*BatchSummary `json:"result"`
+	Total  uint64          `json:"total"`
+}
+
+// ClaimableHistoryPoint the claimable withdrawals of an address at the end of a day
+type ClaimableHistoryPoint struct {
+	// Date is the start of the day in UTC
+	Date time.Time `json:"date"`
+	// Value is the sum of the ETH value of the claimable messages in wei
+	Value string `json:"value"`
+	Count uint64 `json:"count"`
+}
+
+// StaleClaimableSummary the claimable but unclaimed withdrawals of a token across all addresses or of one address,
+// the tokens are empty for ETH
+type StaleClaimableSummary struct {
+	L1Token string `json:"l1Token"`
+	L2Token string `json:"l2Token"`
+	Count   uint64 `json:"count"`
+	// Value is the sum of the amounts in the smallest unit of the token, the NFTs without amounts are only counted
+	Value string `json:"value"`
+}
+
+// ClaimableSummary the counts and the values by token of the claimable but unclaimed withdrawals of the address
+type ClaimableSummary struct {
+	Address string                   `json:"address"`
+	Count   uint64                   `json:"count"`
+	Tokens  []*StaleClaimableSummary `json:"tokens"`
+}
+
+// JourneyStage a step of a message on one of the layers, BlockTimestamp is nil if not fetched yet
+type JourneyStage struct {
+	TxHash         string     `json:"txHash"`
+	BlockNumber    uint64     `json:"blockNumber"`
+	BlockTimestamp *time.Time `json:"blockTimestamp"`
+}
+
+// TxJourney the timeline of a message across both layers, a stage is nil until the message reaches it.
+// Batched and Finalized are the commit and the finalization on layer1 of the batch of a withdrawal, they are always
+// nil for deposits. Claimed is the relay of the message on the target layer, i.e. the claim of a withdrawal on layer1
+// or the execution of a deposit on layer2.
+type TxJourney struct {
+	MsgHash   string        `json:"msgHash"`
+	IsL1      bool          `json:"isL1"`
+	Origin    *JourneyStage `json:"origin"`
+	Batched   *JourneyStage `json:"batched"`
+	Finalized *JourneyStage `json:"finalized"`
+	Claimed   *JourneyStage `json:"claimed"`
+}
+
+// MsgStatus the lifecycle state of the message of a tx. BatchIndex is the batch a withdrawal is committed in, nil for
+// the deposits and the withdrawals not committed yet. CounterpartTxHash is the relay tx on the target layer, empty
+// until the message is relayed.
+type MsgStatus struct {
+	MsgHash           string   `json:"msgHash"`
+	TxHash            string   `json:"txHash"`
+	IsL1              bool     `json:"isL1"`
+	State             MsgState `json:"state"`
+	BatchIndex        *uint64  `json:"batchIndex"`
+	CounterpartTxHash string   `json:"counterpartTxHash"`
+}
+
+// ClaimableEvent the change of the claimable status of a withdrawal of Address, TxHash is the layer2 tx of the
+// withdrawal for ClaimableEventClaimable and the layer1 relay tx for ClaimableEventFinalized
+type ClaimableEvent struct {
+	Type    ClaimableEventType `json:"type"`
+	Address string             `json:"address"`
+	MsgHash string             `json:"msgHash"`
+	TxHash  string             `json:"txHash"`
+}
+
+// WebhookEventType the event of the txs of an address delivered to its webhooks
+type WebhookEventType string
+
+const (
+	// WebhookEventWithdrawalClaimable the proof of a withdrawal is generated, it can be claimed on layer1
+	WebhookEventWithdrawalClaimable WebhookEventType = "withdrawal_claimable"
+	// WebhookEventWithdrawalFinalized the withdrawal is relayed on layer1
+	WebhookEventWithdrawalFinalized WebhookEventType = "withdrawal_finalized"
+	// WebhookEventDepositFinalized the deposit is relayed on layer2
+	WebhookEventDepositFinalized WebhookEventType = "deposit_finalized"
+)
+
+const (
+	// WebhookSecretHeader the header of the secret of the webhook in the webhook deletion and update apis
+	WebhookSecretHeader = "X-Webhook-Secret"
+	// WebhookSignatureHeader the header of the deliveries carrying the hex HMAC-SHA256 of the payload keyed by the
+	// secret of the webhook, prefixed by "sha256="
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	// APIKeyHeader the header of the api key of the rate limiting
+	APIKeyHeader = "X-API-Key"
+	// AdminTokenHeader the header of the admin token of the admin apis
+	AdminTokenHeader = "X-Admin-Token"
+)
+
+// Webhook the schema of a registered webhook, the secret is never returned
+type Webhook struct {
+	ID      uint64 `json:"id"`
+	Address string `json:"address"`
+	URL     string `json:"url"`
+}
+
+// WebhookPayload the body posted to a webhook. ID is the id of the delivery, the same across the retries so that the
+// receivers can dedupe the deliveries. TxHash is the layer2 tx of the withdrawal for WebhookEventWithdrawalClaimable
+// and the relay tx for the finalized events. Timestamp is the unix time of the attempt in seconds.
+type WebhookPayload struct {
+	ID        uint64           `json:"id"`
+	Type      WebhookEventType `json:"type"`
+	Address   string           `json:"address"`
+	MsgHash   string           `json:"msgHash"`
+	TxHash    string           `json:"txHash"`
+	Timestamp int64            `json:"timestamp"`
+}
+
+// IndexedEventType the kind of the indexed bridge events published to the message broker, each kind is published to
+// its own topic
+type IndexedEventType string
+
+const (
+	// IndexedEventCrossMsg a deposit sent on layer1 or a withdrawal sent on layer2 is indexed
+	IndexedEventCrossMsg IndexedEventType = "cross_msg"
+	// IndexedEventRelayedMsg the relay of a message on the other layer is indexed
+	IndexedEventRelayedMsg IndexedEventType = "relayed_msg"
+	// IndexedEventBatchFinalized the finalization of a batch on layer1 is indexed
+	IndexedEventBatchFinalized IndexedEventType = "batch_finalized"
+)
+
+// IndexedEvent the message of an indexed bridge event published to the message broker, keyed by the message hash or
+// by the batch hash. Layer and TxHash are the layer and the tx of the event, the fields of the other kinds are empty.
+// The events are published at least once, a range fetched again publishes its events again.
+type IndexedEvent struct {
+	Type         IndexedEventType `json:"type"`
+	Network      string           `json:"network"`
+	Layer        int              `json:"layer"`
+	TxHash       string           `json:"txHash"`
+	Height       uint64           `json:"height"`
+	MsgHash      string           `json:"msgHash,omitempty"`
+	Sender       string           `json:"sender,omitempty"`
+	Target       string           `json:"target,omitempty"`
+	Amount       string           `json:"amount,omitempty"`
+	L1Token      string           `json:"l1Token,omitempty"`
+	L2Token      string           `json:"l2Token,omitempty"`
+	TokenIDs     []string         `json:"tokenIds,omitempty"`
+	TokenAmounts []string         `json:"tokenAmounts,omitempty"`
+	Relayer      string           `json:"relayer,omitempty"`
+	BatchIndex   uint64           `json:"batchIndex,omitempty"`
+	BatchHash    string           `json:"batchHash,omitempty"`
+	StateRoot    string           `json:"stateRoot,omitempty"`
+}
+
+// DailyVolume the number and the bridged amount in the smallest unit of the token of the txs of one direction and one
+// token sent on a UTC day, Token is the layer1 address of the token, empty for ETH, and Volume is 0 for the NFTs
+type DailyVolume struct {
+	Date      string      `json:"date"`
+	Direction TxDirection `json:"direction"`
+	TokenType TokenType   `json:"tokenType"`
+	Token     string      `json:"token"`
+	TxCount   uint64      `json:"txCount"`
+	Volume    string      `json:"volume"`
+}
+
+// DailyTxCount the number of the deposits and the withdrawals sent on a UTC day
+type DailyTxCount struct {
+	Date        string `json:"date"`
+	Deposits    uint64 `json:"deposits"`
+	Withdrawals uint64 `json:"withdrawals"`
+}
+
+// DailyFinalizeTime the number of the txs of one direction relayed on the target layer on a UTC day and the average
+// time from their sending to their relay in seconds
+type DailyFinalizeTime struct {
+	Date                   string      `json:"date"`
+	Direction              TxDirection `json:"direction"`
+	FinalizedCount         uint64      `json:"finalizedCount"`
+	AverageFinalizeSeconds uint64      `json:"averageFinalizeSeconds"`
+}
+
+// PendingWithdrawals the backlog of the withdrawals sent on layer2 and not relayed on layer1 yet
+type PendingWithdrawals struct {
+	Count uint64 `json:"count"`
+}
+
+// HealthCheck the result of a check of the probes of a network, Name is "db", "l1_rpc", "l2_rpc", "l1_lag" or
+// "l2_lag"
+type HealthCheck struct {
+	Network string `json:"network"`
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+	// Lag is the number of the blocks the indexed height is behind the head beyond the confirmations, lag checks only
+	Lag *uint64 `json:"lag,omitempty"`
+}
+
+// HealthData the checks of the probes of all the networks
+type HealthData struct {
+	Checks []*HealthCheck `json:"checks"`
+}
+
+// CheckpointGap the blocks from FromBlock to ToBlock, both inclusive, the events of the type of the contract are not
+// indexed from, Layer is 1 or 2
+type CheckpointGap struct {
+	Layer     int    `json:"layer"`
+	Contract  string `json:"contract"`
+	EventType string `json:"event_type"`
+	FromBlock uint64 `json:"from_block"`
+	ToBlock   uint64 `json:"to_block"`
+}
+
+// NonceGap the nonces from StartNonce to EndNonce, both inclusive, of the messages sent on layer2 not indexed
+type NonceGap struct {
+	StartNonce uint64 `json:"start_nonce"`
+	EndNonce   uint64 `json:"end_nonce"`
+}
+
+// EventGaps the result of a gap scan of the indexed events, Checkpoints is the number of the checkpoints scanned and
+// at most 100 nonce gaps are returned, the lowest first
+type EventGaps struct {
+	GapFree            bool             `json:"gap_free"`
+	Checkpoints        int              `json:"checkpoints"`
+	CheckpointGaps     []*CheckpointGap `json:"checkpoint_gaps"`
+	L2SentMsgNonceGaps []*NonceGap      `json:"l2_sent_msg_nonce_gaps"`
+}
+
+// AdminActionResult the result of an admin action, AuditID is the id of its row in the audit table and Affected is
+// the number of the rows saved, updated or deleted
+type AdminActionResult struct {
+	AuditID  uint64 `json:"audit_id"`
+	Action   string `json:"action"`
+	Target   string `json:"target"`
+	Affected int64  `json:"affected"`
+}
+
+// Response the envelope of the responses of all the apis, ErrCode is Success and Data is the result of the api on
+// success, otherwise ErrCode is one of ErrorCodes, ErrMsg is the error and Data is nil
 type Response struct {
 	ErrCode int         `json:"errcode"`
 	ErrMsg  string      `json:"errmsg"`
@@ -61,6 +810,7 @@ type Finalized struct {
 	IsL1           bool       `json:"isL1"`
 	BlockNumber    uint64     `json:"blockNumber"`
 	BlockTimestamp *time.Time `json:"blockTimestamp"` // uselesss
+	GasFee         string     `json:"gasFee"`         // only for withdrawals, the gas fee in wei of the layer1 relay tx, empty if unknown
 }
 
 // UserClaimInfo the schema of tx claim infos
@@ -73,22 +823,153 @@ type UserClaimInfo struct {
 	Message    string `json:"message"`
 	Proof      string `json:"proof"`
 	BatchIndex string `json:"batch_index"`
+	// StateRoot is the post state root of the batch, empty if the batch is not finalized
+	StateRoot string `json:"state_root"`
+	// Claimable is true only when the batch is finalized on layer1 and the proof is available, the batch hash and index
+	// are returned as soon as the batch is committed, clients should hide the claim until it's true
+	Claimable bool `json:"claimable"`
+	// ProofPruned is true when the batch is finalized but the proof has been pruned from storage,
+	// clients should request the proof regeneration before claiming
+	ProofPruned bool `json:"proof_pruned"`
+	// EstimatedGas is the static estimation of the L1 gas used by claiming the message
+	EstimatedGas uint64 `json:"estimated_gas"`
+	// ClaimKey is the deterministic hash of the claim params, relayers use it to dedupe the claim submissions
+	ClaimKey string `json:"claim_key"`
+	// ClaimExpiresAt is the time the message can no longer be claimed, nil if the claim never expires
+	ClaimExpiresAt *time.Time `json:"claim_expires_at"`
+	// FromLabel and ToLabel are the labels of the sender and the recipient in the address book, only returned when
+	// enabled
+	FromLabel string `json:"from_label,omitempty"`
+	ToLabel   string `json:"to_label,omitempty"`
+}
+
+// ExecuteParams the params of L2ScrollMessenger.retryMessageWithProof to execute a deposit manually on layer2,
+// the state root proof of the message on layer1 at BlockHash is generated by the client
+type ExecuteParams struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Value     string `json:"value"`
+	Nonce     string `json:"nonce"`
+	Message   string `json:"message"`
+	BlockHash string `json:"blockHash"`
+}
+
+// ForwardRequest the meta-tx request of an ERC2771Forwarder (OpenZeppelin contracts v5) calling To with Data on behalf
+// of From, the forwarder verifies the EIP-712 signature of From over the request and the forwarder nonce of From
+type ForwardRequest struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Value    string `json:"value"`
+	Gas      uint64 `json:"gas"`
+	Nonce    uint64 `json:"nonce"`
+	Deadline uint64 `json:"deadline"` // the unix timestamp after which the request can't be executed
+	Data     string `json:"data"`
+}
+
+// MetaTxClaimPayload the forward request claiming a withdrawal on layer1, and the EIP-712 digest of the request
+// which the client signs for the relayer to submit the request to the forwarder
+type MetaTxClaimPayload struct {
+	Request       *ForwardRequest `json:"request"`
+	SigningDigest string          `json:"signingDigest"`
+}
+
+// Route the gateways and the messengers a message is routed through, the gateways are empty if unknown
+type Route struct {
+	L1Gateway   string `json:"l1Gateway"`
+	L1Messenger string `json:"l1Messenger"`
+	L2Messenger string `json:"l2Messenger"`
+	L2Gateway   string `json:"l2Gateway"`
+}
+
+// FeeAmount an amount of fees in wei and in ETH, e.g. "0.00021"
+type FeeAmount struct {
+	Wei string `json:"wei"`
+	ETH string `json:"eth"`
+}
+
+// LegFees the fees paid by the sender of one tx of a bridging, the tx sending the message or the layer1 tx claiming a
+// withdrawal
+type LegFees struct {
+	TxHash    string     `json:"txHash"`
+	IsL1      bool       `json:"isL1"`
+	GasFee    *FeeAmount `json:"gasFee"`              // the gas used times the effective gas price
+	L1DataFee *FeeAmount `json:"l1DataFee,omitempty"` // only for the layer2 txs, the fee of posting the tx on layer1, absent if unknown
+	BridgeFee *FeeAmount `json:"bridgeFee"`           // the fee charged by the messenger, 0 for the claims
+	Total     *FeeAmount `json:"total"`
+}
+
+// TxFees the fees paid by the user for a bridging per leg, the deposits are relayed on layer2 at no cost to the user
+type TxFees struct {
+	Send  *LegFees   `json:"send"`
+	Claim *LegFees   `json:"claim,omitempty"` // only for the claimed withdrawals whose claim gas fee is known
+	Total *FeeAmount `json:"total"`
 }
 
 // TxHistoryInfo the schema of tx history infos
 type TxHistoryInfo struct {
-	Hash           string         `json:"hash"`
-	MsgHash        string         `json:"msgHash"`
-	Amount         string         `json:"amount"`
-	To             string         `json:"to"` // useless
-	IsL1           bool           `json:"isL1"`
-	L1Token        string         `json:"l1Token"`
-	L2Token        string         `json:"l2Token"`
-	BlockNumber    uint64         `json:"blockNumber"`
-	BlockTimestamp *time.Time     `json:"blockTimestamp"` // useless
-	FinalizeTx     *Finalized     `json:"finalizeTx"`
-	ClaimInfo      *UserClaimInfo `json:"claimInfo"`
-	CreatedAt      *time.Time     `json:"createdTime"`
+	Hash                    string           `json:"hash"`
+	MsgHash                 string           `json:"msgHash"`
+	Amount                  string           `json:"amount"`
+	GasFee                  string           `json:"gasFee"`         // the gas fee in wei of the tx sending the message, empty if unknown
+	BridgeFee               string           `json:"bridgeFee"`      // the fee in wei paid to the bridge on top of the amount, empty if unknown
+	Fees                    *TxFees          `json:"fees,omitempty"` // the fees of the send and the claim txs, absent if the gas fee of the send tx is unknown
+	To                      string           `json:"to"`             // useless
+	IsL1                    bool             `json:"isL1"`
+	L1Token                 string           `json:"l1Token"`
+	L2Token                 string           `json:"l2Token"`
+	TokenType               TokenType        `json:"tokenType"`
+	TokenIDs                []string         `json:"tokenIds"`                // the ids of the NFTs bridged, empty for ETH and ERC20
+	TokenAmounts            []string         `json:"tokenAmounts"`            // the amounts of the ERC1155 token ids, empty otherwise
+	TokenSymbol             string           `json:"tokenSymbol"`             // the symbol of ETH and the ERC20 tokens, empty if unknown
+	TokenDecimals           *uint8           `json:"tokenDecimals,omitempty"` // the decimals of ETH and the ERC20 tokens, absent if unknown
+	TokenLogoURI            string           `json:"tokenLogoURI"`            // the logo of the tokens of the official token list
+	FormattedAmount         string           `json:"formattedAmount"`         // the amount in token units, e.g. "1.5", empty if the decimals are unknown
+	BlockNumber             uint64           `json:"blockNumber"`
+	BlockTimestamp          *time.Time       `json:"blockTimestamp"`          // useless
+	L1BlockHash             string           `json:"l1BlockHash"`             // only for deposits
+	OriginMethod            string           `json:"originMethod"`            // only for deposits, empty if unknown
+	OriginTxNonce           *uint64          `json:"originTxNonce,omitempty"` // only for deposits, the account nonce of the layer1 tx, absent if unknown
+	L1FinalityStatus        L1FinalityStatus `json:"l1FinalityStatus"`        // only for deposits, the finality of the layer1 block, empty if the layer1 heads are unknown
+	Replayed                bool             `json:"replayed"`                // only for deposits, the deposit is replayed with a new gas limit on layer1
+	ReplayTxHash            string           `json:"replayTxHash"`            // only for deposits, the layer1 tx of the latest replay, empty if not replayed
+	DropTxHash              string           `json:"dropTxHash"`              // only for deposits, the layer1 tx dropping the deposit, empty if not dropped
+	Refunded                bool             `json:"refunded"`                // only for deposits, the gateway refunded the dropped deposit on layer1
+	RefundTxHash            string           `json:"refundTxHash"`            // only for deposits, the layer1 tx refunding the deposit, empty if not refunded
+	FinalizeTx              *Finalized       `json:"finalizeTx"`
+	Delivered               bool             `json:"delivered"` // the token transfer to the recipient is observed in the relay tx
+	ClaimInfo               *UserClaimInfo   `json:"claimInfo"`
+	ClaimStatus             ClaimStatus      `json:"claimStatus"`                     // only for withdrawals
+	GlobalWithdrawalIndex   *uint64          `json:"globalWithdrawalIndex,omitempty"` // only for withdrawals, the position in all users' withdrawals
+	MessageIndexInBlock     *uint64          `json:"messageIndexInBlock,omitempty"`   // only for withdrawals, the log index of the sent message in the layer2 block
+	BatchReverted           bool             `json:"batchReverted"`                   // the withdrawal was committed in a batch reverted on layer1
+	RebatchedIndex          *uint64          `json:"rebatchedIndex,omitempty"`        // the batch the withdrawal is committed in again after the revert
+	OperationType           OperationType    `json:"operationType"`
+	Status                  TxStatus         `json:"status"`
+	Route                   *Route           `json:"route"`
+	RequiresManualExecution bool             `json:"requiresManualExecution"` // the deposit failed to be executed on layer2 and is not executed yet
+	ExecuteParams           *ExecuteParams   `json:"executeParams"`           // only for deposits requiring manual execution
+	CreatedAt               *time.Time       `json:"createdTime"`
+	IndexedAt               *time.Time       `json:"indexedAt,omitempty"`    // the time the message is indexed, only returned when enabled
+	RelativeTime            string           `json:"relativeTime,omitempty"` // the age of the block timestamp, e.g. "2 hours ago", only returned when enabled
+	ToLabel                 string           `json:"toLabel,omitempty"`      // the label of the recipient in the address book, only returned when enabled
+	DataCompleteness        uint8            `json:"dataCompleteness"`       // 0 to 100, how fully the optional fields are populated, refetch later if below 100
+}
+
+// ClaimTx the layer1 tx claiming a withdrawal, to be signed and sent as is. The gas is estimated by the layer1 node,
+// or statically if the node is not configured or can't estimate it, e.g. the claim would revert since it's claimed.
+type ClaimTx struct {
+	To    string `json:"to"`    // the L1ScrollMessenger
+	Data  string `json:"data"`  // the ABI encoded relayMessageWithProof calldata
+	Value string `json:"value"` // always 0, the value of the message is released by the messenger
+	// EstimatedGas is the gas used by the claim
+	EstimatedGas uint64 `json:"estimatedGas"`
+	// GasLimit is EstimatedGas with a safety margin, the gas limit suggested for the tx
+	GasLimit uint64 `json:"gasLimit"`
+	// EstimatedByNode is true if EstimatedGas is estimated by the layer1 node, false if it's the static estimation
+	EstimatedByNode bool `json:"estimatedByNode"`
+	// EstimateError is the error of the node estimation, e.g. the revert reason of the claim, empty if none
+	EstimateError string         `json:"estimateError,omitempty"`
+	ClaimInfo     *UserClaimInfo `json:"claimInfo"`
 }
 
 // RenderJSON renders response with json
@@ -115,6 +996,19 @@ func RenderFailure(ctx *gin.Context, errCode int, err error) {
 	RenderJSON(ctx, errCode, err, nil)
 }
 
+// RenderAbort renders failure response with json and the http status, the handlers after the current one are skipped
+func RenderAbort(ctx *gin.Context, status int, errCode int, err error) {
+	var errMsg string
+	if err != nil {
+		errMsg = err.Error()
+	}
+	ctx.AbortWithStatusJSON(status, Response{
+		ErrCode: errCode,
+		ErrMsg:  errMsg,
+		Data:    nil,
+	})
+}
+
 // RenderFatal renders fatal response with json
 func RenderFatal(ctx *gin.Context, err error) {
 	var errMsg string
@@ -10,21 +10,23 @@ import (
 
 // ProbesController probe check controller
 type ProbesController struct {
-	db *gorm.DB
+	dbs []*gorm.DB
 }
 
-// NewProbesController returns an ProbesController instance
-func NewProbesController(db *gorm.DB) *ProbesController {
+// NewProbesController returns an ProbesController instance checking the dbs of all the networks
+func NewProbesController(dbs ...*gorm.DB) *ProbesController {
 	return &ProbesController{
-		db: db,
+		dbs: dbs,
 	}
 }
 
 // HealthCheck the api controller for health check
 func (a *ProbesController) HealthCheck(c *gin.Context) {
-	if _, err := utils.Ping(a.db); err != nil {
-		types.RenderFatal(c, err)
-		return
+	for _, db := range a.dbs {
+		if _, err := utils.Ping(db); err != nil {
+			types.RenderFatal(c, err)
+			return
+		}
 	}
 	types.RenderSuccess(c, nil)
 }
@@ -21,7 +21,7 @@ import (
 
 // Server starts the metrics server on the given address, will be closed when the given
 // context is canceled.
-func Server(c *cli.Context, db *gorm.DB) {
+func Server(c *cli.Context, dbs ...*gorm.DB) {
 	if !c.Bool(utils.MetricsEnabled.Name) {
 		return
 	}
@@ -33,7 +33,7 @@ func Server(c *cli.Context, db *gorm.DB) {
 		promhttp.Handler().ServeHTTP(context.Writer, context.Request)
 	})
 
-	probeController := NewProbesController(db)
+	probeController := NewProbesController(dbs...)
 	r.GET("/health", probeController.HealthCheck)
 	r.GET("/ready", probeController.Ready)
 
@@ -0,0 +1,42 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AddressLabel is the struct for address_label table, the label of a known contract
+type AddressLabel struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID uint64 `json:"id" gorm:"column:id"`
+	// Address is the checksummed address of the contract
+	Address   string         `json:"address" gorm:"column:address"`
+	Label     string         `json:"label" gorm:"column:label"`
+	CreatedAt *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewAddressLabel create an AddressLabel instance
+func NewAddressLabel(db *gorm.DB) *AddressLabel {
+	return &AddressLabel{db: db}
+}
+
+// TableName returns the table name for the AddressLabel model.
+func (*AddressLabel) TableName() string {
+	return "address_label"
+}
+
+// GetAddressLabels get all the labels of the address book
+func (a *AddressLabel) GetAddressLabels(ctx context.Context) ([]*AddressLabel, error) {
+	var results []*AddressLabel
+	err := a.db.WithContext(ctx).Model(&AddressLabel{}).Find(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("AddressLabel.GetAddressLabels error: %w", err)
+	}
+	return results, nil
+}
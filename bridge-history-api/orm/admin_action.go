@@ -0,0 +1,49 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AdminAction is the struct for admin_actions table, the audit record of a correction run through the admin apis
+type AdminAction struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID     uint64 `json:"id" gorm:"column:id"`
+	Action string `json:"action" gorm:"column:action"`
+	Target string `json:"target" gorm:"column:target"`
+	Actor  string `json:"actor" gorm:"column:actor"`
+	// Affected is the number of the rows saved, updated or deleted by the action
+	Affected int64 `json:"affected" gorm:"column:affected"`
+	// Error is the failure of the action, empty if it succeeded
+	Error     string         `json:"error" gorm:"column:error"`
+	CreatedAt *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewAdminAction create an AdminAction instance
+func NewAdminAction(db *gorm.DB) *AdminAction {
+	return &AdminAction{db: db}
+}
+
+// TableName returns the table name for the AdminAction model.
+func (*AdminAction) TableName() string {
+	return "admin_actions"
+}
+
+// InsertAdminAction insert the audit record of an admin action into db, the id of the record is set
+func (a *AdminAction) InsertAdminAction(ctx context.Context, action *AdminAction, dbTx ...*gorm.DB) error {
+	db := a.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Model(&AdminAction{}).Create(action).Error
+	if err != nil {
+		return fmt.Errorf("AdminAction.InsertAdminAction error: %w", err)
+	}
+	return nil
+}
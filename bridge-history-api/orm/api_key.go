@@ -0,0 +1,56 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIKey is the struct for api_key table, an api key of the rate limiting stored hashed
+type APIKey struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID uint64 `json:"id" gorm:"column:id"`
+	// KeyHash is the hex sha256 of the api key
+	KeyHash   string         `json:"key_hash" gorm:"column:key_hash"`
+	Tier      string         `json:"tier" gorm:"column:tier"`
+	Owner     string         `json:"owner" gorm:"column:owner;default:''"`
+	CreatedAt *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// APIKeyLimit the rate limit of an api key, the limit of its tier
+type APIKeyLimit struct {
+	KeyHash           string  `gorm:"column:key_hash"`
+	Tier              string  `gorm:"column:tier"`
+	RequestsPerSecond float64 `gorm:"column:requests_per_second"`
+	Burst             int     `gorm:"column:burst"`
+}
+
+// NewAPIKey create an APIKey instance
+func NewAPIKey(db *gorm.DB) *APIKey {
+	return &APIKey{db: db}
+}
+
+// TableName returns the table name for the APIKey model.
+func (*APIKey) TableName() string {
+	return "api_key"
+}
+
+// GetAPIKeyLimits get the rate limits of all the api keys, the keys of the unknown tiers are skipped
+func (a *APIKey) GetAPIKeyLimits(ctx context.Context) ([]*APIKeyLimit, error) {
+	var results []*APIKeyLimit
+	err := a.db.WithContext(ctx).Table("api_key AS k").
+		Select("k.key_hash, k.tier, t.requests_per_second, t.burst").
+		Joins("JOIN api_key_tier AS t ON t.name = k.tier AND t.deleted_at IS NULL").
+		Where("k.deleted_at IS NULL").
+		Scan(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("APIKey.GetAPIKeyLimits error: %w", err)
+	}
+	return results, nil
+}
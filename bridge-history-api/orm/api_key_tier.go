@@ -0,0 +1,32 @@
+package orm
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyTier is the struct for api_key_tier table, the rate limits of the api keys of a tier
+type APIKeyTier struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID   uint64 `json:"id" gorm:"column:id"`
+	Name string `json:"name" gorm:"column:name"`
+	// RequestsPerSecond is the rate the bucket of each key of the tier is refilled at, 0 doesn't limit the keys
+	RequestsPerSecond float64 `json:"requests_per_second" gorm:"column:requests_per_second"`
+	// Burst is the capacity of the bucket of each key of the tier, 0 is twice the rate
+	Burst     int            `json:"burst" gorm:"column:burst"`
+	CreatedAt *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewAPIKeyTier create an APIKeyTier instance
+func NewAPIKeyTier(db *gorm.DB) *APIKeyTier {
+	return &APIKeyTier{db: db}
+}
+
+// TableName returns the table name for the APIKeyTier model.
+func (*APIKeyTier) TableName() string {
+	return "api_key_tier"
+}
@@ -0,0 +1,141 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// CrossMsgArchiveTableName is the table the relayed cross messages are archived into
+	CrossMsgArchiveTableName = "cross_message_archive"
+	// L2SentMsgArchiveTableName is the table the l2 sent messages of the archived withdrawals are archived into
+	L2SentMsgArchiveTableName = "l2_sent_msg_archive"
+	// L2SentMsgAllViewName is the view of the l2 sent messages of both l2_sent_msg and its archive
+	L2SentMsgAllViewName = "l2_sent_msg_all"
+	// CrossMsgAllViewName is the view of the cross messages of both cross_message and its archive
+	CrossMsgAllViewName = "cross_message_all"
+)
+
+// Archive moves the relayed cross messages and the l2 sent messages of the withdrawals into the archive tables, which
+// are partitioned by the range of the height, and reads them back
+type Archive struct {
+	db *gorm.DB
+	// excludedSenders the senders whose archived deposits the queries of the histories skip
+	excludedSenders []string
+}
+
+// NewArchive create an Archive instance
+func NewArchive(db *gorm.DB) *Archive {
+	return &Archive{db: db}
+}
+
+// NewArchiveExcludingSenders create an Archive instance whose queries of the histories skip the archived deposits sent
+// by the senders
+func NewArchiveExcludingSenders(db *gorm.DB, senders []string) *Archive {
+	return &Archive{db: db, excludedSenders: senders}
+}
+
+// GetArchivableCrossMsgs returns at most limit cross messages of the msg type with a block timestamp before the time
+// and relayed, ordered by id. Only the messages with an id up to maxID and relayed by a relay with an id up to
+// maxRelayID are returned, so that the rows not aggregated into the bridge stats yet are kept.
+func (a *Archive) GetArchivableCrossMsgs(ctx context.Context, msgType MsgType, before time.Time, maxID, maxRelayID uint64, limit int) ([]*CrossMsg, error) {
+	var results []*CrossMsg
+	err := a.db.WithContext(ctx).Model(&CrossMsg{}).
+		Where("msg_type = ? AND id <= ? AND block_timestamp < ? AND msg_hash != ''", msgType, maxID, before).
+		Where("EXISTS (SELECT 1 FROM relayed_msg WHERE relayed_msg.msg_hash = cross_message.msg_hash AND relayed_msg.deleted_at IS NULL AND relayed_msg.id <= ?)", maxRelayID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("Archive.GetArchivableCrossMsgs error: %w", err)
+	}
+	return results, nil
+}
+
+// ArchiveCrossMsgs moves the cross messages, along with the l2 sent messages of the withdrawals among them, into the
+// archive tables in one transaction. The partitions of the heights of the messages are created first, each partition
+// holds partitionSize heights.
+func (a *Archive) ArchiveCrossMsgs(ctx context.Context, crossMsgs []*CrossMsg, partitionSize uint64) error {
+	if len(crossMsgs) == 0 {
+		return nil
+	}
+	var ids, heights, withdrawalHeights []uint64
+	var withdrawalHashes []string
+	for _, crossMsg := range crossMsgs {
+		ids = append(ids, crossMsg.ID)
+		heights = append(heights, crossMsg.Height)
+		if MsgType(crossMsg.MsgType) == Layer2Msg && crossMsg.MsgHash != "" {
+			withdrawalHashes = append(withdrawalHashes, crossMsg.MsgHash)
+			withdrawalHeights = append(withdrawalHeights, crossMsg.Height)
+		}
+	}
+	if err := EnsureHeightPartitions(ctx, a.db, CrossMsgArchiveTableName, partitionSize, heights); err != nil {
+		return fmt.Errorf("Archive.ArchiveCrossMsgs error: %w", err)
+	}
+	if err := EnsureHeightPartitions(ctx, a.db, L2SentMsgArchiveTableName, partitionSize, withdrawalHeights); err != nil {
+		return fmt.Errorf("Archive.ArchiveCrossMsgs error: %w", err)
+	}
+	err := a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("INSERT INTO cross_message_archive SELECT * FROM cross_message WHERE id IN ?", ids).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM cross_message WHERE id IN ?", ids).Error; err != nil {
+			return err
+		}
+		if len(withdrawalHashes) == 0 {
+			return nil
+		}
+		if err := tx.Exec("INSERT INTO l2_sent_msg_archive SELECT * FROM l2_sent_msg WHERE msg_hash IN ? AND deleted_at IS NULL", withdrawalHashes).Error; err != nil {
+			return err
+		}
+		return tx.Exec("DELETE FROM l2_sent_msg WHERE msg_hash IN ? AND deleted_at IS NULL", withdrawalHashes).Error
+	})
+	if err != nil {
+		return fmt.Errorf("Archive.ArchiveCrossMsgs error: %w", err)
+	}
+	return nil
+}
+
+// GetArchivedCrossMsgsByHashes returns the archived cross messages with a layer1 or layer2 hash among the hashes,
+// the same way as CrossMsg.GetCrossMsgsByHashes
+func (a *Archive) GetArchivedCrossMsgsByHashes(ctx context.Context, hashes []string, assets []AssetType, order SortOrder) ([]*CrossMsg, error) {
+	var results []*CrossMsg
+	db := a.db.WithContext(ctx).Model(&CrossMsg{}).Table(CrossMsgArchiveTableName).
+		Where("layer1_hash IN (?) OR layer2_hash IN (?)", hashes, hashes)
+	db = excludeSenders(db, a.excludedSenders)
+	if len(assets) != 0 {
+		query, args := assetCondition("", assets)
+		db = db.Where(query, args...)
+	}
+	db = db.Order(order.orderBy("block_timestamp", "layer1_hash", "layer2_hash", "id"))
+	if err := db.Find(&results).Error; err != nil {
+		return nil, fmt.Errorf("Archive.GetArchivedCrossMsgsByHashes error: %w", err)
+	}
+	return results, nil
+}
+
+// EnsureHeightPartitions creates the partitions of the table partitioned by the range of the height holding the
+// heights, each partition holds the size heights starting from a multiple of size and is named after its start
+func EnsureHeightPartitions(ctx context.Context, db *gorm.DB, table string, size uint64, heights []uint64) error {
+	if size == 0 {
+		return fmt.Errorf("the partition size of %s is 0", table)
+	}
+	created := make(map[uint64]bool)
+	for _, height := range heights {
+		start := height - height%size
+		if created[start] {
+			continue
+		}
+		// the identifiers can not be bound, the table names are constants and the bounds integers
+		sql := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s_p%d PARTITION OF %s FOR VALUES FROM (%d) TO (%d)", table, start, table, start, start+size)
+		if err := db.WithContext(ctx).Exec(sql).Error; err != nil {
+			return fmt.Errorf("EnsureHeightPartitions error: %w", err)
+		}
+		created[start] = true
+	}
+	return nil
+}
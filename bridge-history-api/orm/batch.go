@@ -7,22 +7,29 @@ import (
 
 	"github.com/ethereum/go-ethereum/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // RollupBatch is the struct for rollup_batch table
 type RollupBatch struct {
 	db *gorm.DB `gorm:"column:-"`
 
-	ID               uint64         `json:"id" gorm:"column:id"`
-	BatchIndex       uint64         `json:"batch_index" gorm:"column:batch_index"`
-	BatchHash        string         `json:"batch_hash" gorm:"column:batch_hash"`
-	CommitHeight     uint64         `json:"commit_height" gorm:"column:commit_height"`
-	StartBlockNumber uint64         `json:"start_block_number" gorm:"column:start_block_number"`
-	EndBlockNumber   uint64         `json:"end_block_number" gorm:"column:end_block_number"`
-	WithdrawRoot     string         `json:"withdraw_root" gorm:"column:withdraw_root;default:NULL"`
-	CreatedAt        *time.Time     `json:"created_at" gorm:"column:created_at"`
-	UpdatedAt        *time.Time     `json:"updated_at" gorm:"column:updated_at"`
-	DeletedAt        gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+	ID                uint64         `json:"id" gorm:"column:id"`
+	BatchIndex        uint64         `json:"batch_index" gorm:"column:batch_index"`
+	BatchHash         string         `json:"batch_hash" gorm:"column:batch_hash"`
+	CommitHeight      uint64         `json:"commit_height" gorm:"column:commit_height"`
+	CommitTxHash      string         `json:"commit_tx_hash" gorm:"column:commit_tx_hash;default:''"`
+	StartBlockNumber  uint64         `json:"start_block_number" gorm:"column:start_block_number"`
+	EndBlockNumber    uint64         `json:"end_block_number" gorm:"column:end_block_number"`
+	WithdrawRoot      string         `json:"withdraw_root" gorm:"column:withdraw_root;default:NULL"`
+	StateRoot         string         `json:"state_root" gorm:"column:state_root;default:''"`
+	FinalizeHeight    uint64         `json:"finalize_height" gorm:"column:finalize_height;default:0"`
+	FinalizeTxHash    string         `json:"finalize_tx_hash" gorm:"column:finalize_tx_hash;default:''"`
+	FinalizeTimestamp *time.Time     `json:"finalize_timestamp" gorm:"column:finalize_timestamp;default:NULL"`
+	Reverted          bool           `json:"reverted" gorm:"column:reverted;default:false"`
+	CreatedAt         *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt         *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt         gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
 }
 
 // NewRollupBatch create an RollupBatch instance
@@ -81,7 +88,82 @@ func (r *RollupBatch) GetRollupBatchesByIndexes(ctx context.Context, indexes []u
 	return results, nil
 }
 
-// InsertRollupBatch batch insert rollup batch into db and return the transaction
+// GetRevertedRollupBatchesByIndexes return the reverted rollup batches by indexes, there can be more than one
+// reverted batch under an index
+func (r *RollupBatch) GetRevertedRollupBatchesByIndexes(ctx context.Context, indexes []uint64) ([]*RollupBatch, error) {
+	var results []*RollupBatch
+	err := r.db.WithContext(ctx).Unscoped().Model(&RollupBatch{}).
+		Where("batch_index IN (?) AND reverted", indexes).
+		Order("id ASC").
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("RollupBatch.GetRevertedRollupBatchesByIndexes error: %w", err)
+	}
+	return results, nil
+}
+
+// GetRollupBatchByBlockNumber return the rollup batch containing the layer2 block, nil if not committed yet
+func (r *RollupBatch) GetRollupBatchByBlockNumber(ctx context.Context, blockNumber uint64) (*RollupBatch, error) {
+	var result RollupBatch
+	err := r.db.WithContext(ctx).Model(&RollupBatch{}).
+		Where("start_block_number <= ? AND end_block_number >= ?", blockNumber, blockNumber).
+		First(&result).
+		Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("RollupBatch.GetRollupBatchByBlockNumber error: %w", err)
+	}
+	return &result, nil
+}
+
+// RollupBatchWithMsgCount is a rollup batch along with the number of the layer2 withdrawals sent in its blocks
+type RollupBatchWithMsgCount struct {
+	RollupBatch
+	MsgCount uint64 `json:"msg_count" gorm:"column:msg_count"`
+}
+
+// GetTotalRollupBatchCount return the total count of the rollup batches, the reverted batches are not counted
+func (r *RollupBatch) GetTotalRollupBatchCount(ctx context.Context) (uint64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&RollupBatch{}).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("RollupBatch.GetTotalRollupBatchCount error: %w", err)
+	}
+	return uint64(count), nil
+}
+
+// GetRollupBatchesWithMsgCounts return the rollup batches from the latest one along with the number of the withdrawals
+// sent in their blocks, the reverted batches are excluded
+func (r *RollupBatch) GetRollupBatchesWithMsgCounts(ctx context.Context, offset int, limit int) ([]*RollupBatchWithMsgCount, error) {
+	var results []*RollupBatchWithMsgCount
+	err := r.db.WithContext(ctx).Model(&RollupBatch{}).
+		Select("rollup_batch.*, (SELECT COUNT(*) FROM " + L2SentMsgAllViewName + " AS s WHERE s.height BETWEEN rollup_batch.start_block_number AND rollup_batch.end_block_number AND s.deleted_at IS NULL) AS msg_count").
+		Order("batch_index DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("RollupBatch.GetRollupBatchesWithMsgCounts error: %w", err)
+	}
+	return results, nil
+}
+
+// ContainsBlock returns whether the layer2 block is in the batch
+func (r *RollupBatch) ContainsBlock(blockNumber uint64) bool {
+	return r.StartBlockNumber <= blockNumber && blockNumber <= r.EndBlockNumber
+}
+
+// IsFinalized returns whether the batch has been finalized on layer1
+func (r *RollupBatch) IsFinalized() bool {
+	return r.FinalizeHeight != 0
+}
+
+// InsertRollupBatch batch insert rollup batch into db and return the transaction. The batches
+// saved already, by batch index or batch hash, are skipped
 func (r *RollupBatch) InsertRollupBatch(ctx context.Context, batches []*RollupBatch, dbTx ...*gorm.DB) error {
 	if len(batches) == 0 {
 		return nil
@@ -90,7 +172,7 @@ func (r *RollupBatch) InsertRollupBatch(ctx context.Context, batches []*RollupBa
 	if len(dbTx) > 0 && dbTx[0] != nil {
 		db = dbTx[0]
 	}
-	err := db.WithContext(ctx).Model(&RollupBatch{}).Create(&batches).Error
+	err := db.WithContext(ctx).Model(&RollupBatch{}).Clauses(clause.OnConflict{DoNothing: true}).Create(&batches).Error
 	if err != nil {
 		batchIndexes := make([]uint64, 0, len(batches))
 		heights := make([]uint64, 0, len(batches))
@@ -104,6 +186,106 @@ func (r *RollupBatch) InsertRollupBatch(ctx context.Context, batches []*RollupBa
 	return nil
 }
 
+// UpdateRollupBatchFinalization updates the finalize height, finalize tx hash and state root of the given batch
+func (r *RollupBatch) UpdateRollupBatchFinalization(ctx context.Context, batchIndex uint64, finalizeHeight uint64, finalizeTxHash string, stateRoot string, dbTx ...*gorm.DB) error {
+	db := r.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Model(&RollupBatch{}).
+		Where("batch_index = ?", batchIndex).
+		Updates(map[string]interface{}{
+			"finalize_height":  finalizeHeight,
+			"finalize_tx_hash": finalizeTxHash,
+			"state_root":       stateRoot,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("RollupBatch.UpdateRollupBatchFinalization error: %w", err)
+	}
+	return nil
+}
+
+// GetEarliestNoFinalizeTimestampHeight returns the earliest finalize height of the batches which have no finalize timestamp
+func (r *RollupBatch) GetEarliestNoFinalizeTimestampHeight(ctx context.Context) (uint64, error) {
+	var result RollupBatch
+	err := r.db.WithContext(ctx).Model(&RollupBatch{}).
+		Where("finalize_timestamp IS NULL AND finalize_height != 0").
+		Select("finalize_height").
+		Order("finalize_height ASC").
+		First(&result).
+		Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("RollupBatch.GetEarliestNoFinalizeTimestampHeight error: %w", err)
+	}
+	return result.FinalizeHeight, nil
+}
+
+// UpdateFinalizeTimestamp updates the finalize timestamp of the batches finalized at the given height
+func (r *RollupBatch) UpdateFinalizeTimestamp(ctx context.Context, height uint64, timestamp time.Time) error {
+	err := r.db.WithContext(ctx).Model(&RollupBatch{}).
+		Where("finalize_height = ?", height).
+		Update("finalize_timestamp", timestamp).Error
+	if err != nil {
+		return fmt.Errorf("RollupBatch.UpdateFinalizeTimestamp error: %w", err)
+	}
+	return nil
+}
+
+// RevertRollupBatch marks the batch of the given hash reverted and soft deletes it, so the index can be committed again
+func (r *RollupBatch) RevertRollupBatch(ctx context.Context, batchHash string, dbTx ...*gorm.DB) error {
+	db := r.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Model(&RollupBatch{}).
+		Where("batch_hash = ?", batchHash).
+		Updates(map[string]interface{}{
+			"reverted":   true,
+			"deleted_at": time.Now(),
+		}).Error
+	if err != nil {
+		return fmt.Errorf("RollupBatch.RevertRollupBatch error: %w", err)
+	}
+	return nil
+}
+
+// DeleteRollupBatchesCommittedAfterHeight deletes the batches committed after the layer1 height, the reverted ones
+// included. The rows are removed for good, so that the processed height drops and the batches are fetched again.
+func (r *RollupBatch) DeleteRollupBatchesCommittedAfterHeight(ctx context.Context, height uint64, dbTx ...*gorm.DB) error {
+	db := r.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Unscoped().Delete(&RollupBatch{}, "commit_height > ?", height).Error
+	if err != nil {
+		return fmt.Errorf("RollupBatch.DeleteRollupBatchesCommittedAfterHeight error: %w", err)
+	}
+	return nil
+}
+
+// ResetRollupBatchFinalizationAfterHeight clears the finalization of the batches finalized after the layer1 height
+func (r *RollupBatch) ResetRollupBatchFinalizationAfterHeight(ctx context.Context, height uint64, dbTx ...*gorm.DB) error {
+	db := r.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Model(&RollupBatch{}).
+		Where("finalize_height > ?", height).
+		Updates(map[string]interface{}{
+			"finalize_height":    0,
+			"finalize_tx_hash":   "",
+			"finalize_timestamp": nil,
+			"state_root":         "",
+		}).Error
+	if err != nil {
+		return fmt.Errorf("RollupBatch.ResetRollupBatchFinalizationAfterHeight error: %w", err)
+	}
+	return nil
+}
+
 // UpdateRollupBatchWithdrawRoot updates the withdraw_root column in rollup_batch table
 func (r *RollupBatch) UpdateRollupBatchWithdrawRoot(ctx context.Context, batchIndex uint64, withdrawRoot string) error {
 	err := r.db.WithContext(ctx).Model(&RollupBatch{}).Where("batch_index = ?", batchIndex).Update("withdraw_root", withdrawRoot).Error
@@ -0,0 +1,90 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BridgeDailyFinalization is the struct for bridge_daily_finalization table, the number of the cross messages of one
+// direction relayed on the target layer on a UTC day and the sum of the times from their sending to their relay
+type BridgeDailyFinalization struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID                   uint64         `json:"id" gorm:"column:id"`
+	Day                  time.Time      `json:"day" gorm:"column:day"`
+	MsgType              int            `json:"msg_type" gorm:"column:msg_type"` // Layer1Msg for the deposits and Layer2Msg for the withdrawals
+	FinalizedCount       uint64         `json:"finalized_count" gorm:"column:finalized_count"`
+	TotalFinalizeSeconds uint64         `json:"total_finalize_seconds" gorm:"column:total_finalize_seconds"`
+	CreatedAt            *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt            *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt            gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewBridgeDailyFinalization create a BridgeDailyFinalization instance
+func NewBridgeDailyFinalization(db *gorm.DB) *BridgeDailyFinalization {
+	return &BridgeDailyFinalization{db: db}
+}
+
+// TableName returns the table name for the BridgeDailyFinalization model.
+func (*BridgeDailyFinalization) TableName() string {
+	return "bridge_daily_finalization"
+}
+
+// GetBridgeDailyFinalizations get the daily finalizations of the days between from and to inclusive ordered by day
+func (b *BridgeDailyFinalization) GetBridgeDailyFinalizations(ctx context.Context, from, to time.Time) ([]*BridgeDailyFinalization, error) {
+	var results []*BridgeDailyFinalization
+	err := b.db.WithContext(ctx).Model(&BridgeDailyFinalization{}).
+		Where("day >= ? AND day <= ?", from, to).
+		Order("day ASC, msg_type ASC").
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("BridgeDailyFinalization.GetBridgeDailyFinalizations error: %w", err)
+	}
+	return results, nil
+}
+
+// GetTotalFinalizedCount get the number of all the aggregated relays of the cross messages of the direction
+func (b *BridgeDailyFinalization) GetTotalFinalizedCount(ctx context.Context, msgType MsgType) (uint64, error) {
+	var total uint64
+	err := b.db.WithContext(ctx).Model(&BridgeDailyFinalization{}).
+		Select("COALESCE(SUM(finalized_count), 0)").
+		Where("msg_type = ?", msgType).
+		Scan(&total).
+		Error
+	if err != nil {
+		return 0, fmt.Errorf("BridgeDailyFinalization.GetTotalFinalizedCount error: %w", err)
+	}
+	return total, nil
+}
+
+// InsertBridgeDailyFinalizations batch add the numbers and the times to the daily finalizations of the same day and
+// direction in db, the missing ones are inserted
+func (b *BridgeDailyFinalization) InsertBridgeDailyFinalizations(ctx context.Context, finalizations []*BridgeDailyFinalization, dbTx ...*gorm.DB) error {
+	if len(finalizations) == 0 {
+		return nil
+	}
+	db := b.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Model(&BridgeDailyFinalization{}).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "day"}, {Name: "msg_type"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"finalized_count":        gorm.Expr("bridge_daily_finalization.finalized_count + EXCLUDED.finalized_count"),
+				"total_finalize_seconds": gorm.Expr("bridge_daily_finalization.total_finalize_seconds + EXCLUDED.total_finalize_seconds"),
+			}),
+		}).
+		Create(&finalizations).
+		Error
+	if err != nil {
+		return fmt.Errorf("BridgeDailyFinalization.InsertBridgeDailyFinalizations error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BridgeDailyVolume is the struct for bridge_daily_volume table, the number and the bridged amount of the cross
+// messages of one direction and one token sent on a UTC day
+type BridgeDailyVolume struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID      uint64    `json:"id" gorm:"column:id"`
+	Day     time.Time `json:"day" gorm:"column:day"`
+	MsgType int       `json:"msg_type" gorm:"column:msg_type"` // Layer1Msg for the deposits and Layer2Msg for the withdrawals
+	Asset   int       `json:"asset" gorm:"column:asset"`
+	// Token the layer1 address of the bridged token, empty for ETH
+	Token   string `json:"token" gorm:"column:token;default:''"`
+	TxCount uint64 `json:"tx_count" gorm:"column:tx_count"`
+	// Volume the sum of the amounts in the smallest unit of the token, always 0 for the NFTs
+	Volume    string         `json:"volume" gorm:"column:volume"`
+	CreatedAt *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// DailyTxCount the number of the cross messages of one direction sent on a UTC day
+type DailyTxCount struct {
+	Day     time.Time `gorm:"column:day"`
+	MsgType int       `gorm:"column:msg_type"`
+	TxCount uint64    `gorm:"column:tx_count"`
+}
+
+// NewBridgeDailyVolume create a BridgeDailyVolume instance
+func NewBridgeDailyVolume(db *gorm.DB) *BridgeDailyVolume {
+	return &BridgeDailyVolume{db: db}
+}
+
+// TableName returns the table name for the BridgeDailyVolume model.
+func (*BridgeDailyVolume) TableName() string {
+	return "bridge_daily_volume"
+}
+
+// GetBridgeDailyVolumes get the daily volumes of the days between from and to inclusive ordered by day, the volumes
+// of the other tokens are skipped if token is not empty
+func (b *BridgeDailyVolume) GetBridgeDailyVolumes(ctx context.Context, from, to time.Time, token string) ([]*BridgeDailyVolume, error) {
+	db := b.db.WithContext(ctx).Model(&BridgeDailyVolume{}).Where("day >= ? AND day <= ?", from, to)
+	if token != "" {
+		db = db.Where("token = ?", token)
+	}
+	var results []*BridgeDailyVolume
+	err := db.Order("day ASC, msg_type ASC, asset ASC, token ASC").Find(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("BridgeDailyVolume.GetBridgeDailyVolumes error: %w", err)
+	}
+	return results, nil
+}
+
+// GetDailyTxCounts get the number of the cross messages of each direction sent each day between from and to inclusive
+// ordered by day
+func (b *BridgeDailyVolume) GetDailyTxCounts(ctx context.Context, from, to time.Time) ([]*DailyTxCount, error) {
+	var results []*DailyTxCount
+	err := b.db.WithContext(ctx).Model(&BridgeDailyVolume{}).
+		Select("day, msg_type, SUM(tx_count) AS tx_count").
+		Where("day >= ? AND day <= ?", from, to).
+		Group("day, msg_type").
+		Order("day ASC, msg_type ASC").
+		Scan(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("BridgeDailyVolume.GetDailyTxCounts error: %w", err)
+	}
+	return results, nil
+}
+
+// GetTotalTxCount get the number of all the aggregated cross messages of the direction
+func (b *BridgeDailyVolume) GetTotalTxCount(ctx context.Context, msgType MsgType) (uint64, error) {
+	var total uint64
+	err := b.db.WithContext(ctx).Model(&BridgeDailyVolume{}).
+		Select("COALESCE(SUM(tx_count), 0)").
+		Where("msg_type = ?", msgType).
+		Scan(&total).
+		Error
+	if err != nil {
+		return 0, fmt.Errorf("BridgeDailyVolume.GetTotalTxCount error: %w", err)
+	}
+	return total, nil
+}
+
+// InsertBridgeDailyVolumes batch add the numbers and the volumes to the daily volumes of the same day, direction and
+// token in db, the missing ones are inserted
+func (b *BridgeDailyVolume) InsertBridgeDailyVolumes(ctx context.Context, volumes []*BridgeDailyVolume, dbTx ...*gorm.DB) error {
+	if len(volumes) == 0 {
+		return nil
+	}
+	db := b.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Model(&BridgeDailyVolume{}).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "day"}, {Name: "msg_type"}, {Name: "asset"}, {Name: "token"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"tx_count": gorm.Expr("bridge_daily_volume.tx_count + EXCLUDED.tx_count"),
+				"volume":   gorm.Expr("bridge_daily_volume.volume + EXCLUDED.volume"),
+			}),
+		}).
+		Create(&volumes).
+		Error
+	if err != nil {
+		return fmt.Errorf("BridgeDailyVolume.InsertBridgeDailyVolumes error: %w", err)
+	}
+	return nil
+}
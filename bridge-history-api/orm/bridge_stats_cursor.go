@@ -0,0 +1,67 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BridgeStatsCursor is the struct for bridge_stats_cursor table, the id of the last row of a source table aggregated
+// into the bridge stats
+type BridgeStatsCursor struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID        uint64         `json:"id" gorm:"column:id"`
+	Name      string         `json:"name" gorm:"column:name"`
+	LastID    uint64         `json:"last_id" gorm:"column:last_id"`
+	CreatedAt *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewBridgeStatsCursor create a BridgeStatsCursor instance
+func NewBridgeStatsCursor(db *gorm.DB) *BridgeStatsCursor {
+	return &BridgeStatsCursor{db: db}
+}
+
+// TableName returns the table name for the BridgeStatsCursor model.
+func (*BridgeStatsCursor) TableName() string {
+	return "bridge_stats_cursor"
+}
+
+// GetLastID get the id of the last row aggregated by the cursor of the name, 0 is returned if nothing is aggregated yet
+func (b *BridgeStatsCursor) GetLastID(ctx context.Context, name string) (uint64, error) {
+	var result BridgeStatsCursor
+	err := b.db.WithContext(ctx).Model(&BridgeStatsCursor{}).Where("name = ?", name).First(&result).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("BridgeStatsCursor.GetLastID error: %w", err)
+	}
+	return result.LastID, nil
+}
+
+// UpdateLastID upsert the id of the last row aggregated by the cursor of the name
+func (b *BridgeStatsCursor) UpdateLastID(ctx context.Context, name string, lastID uint64, dbTx ...*gorm.DB) error {
+	db := b.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Model(&BridgeStatsCursor{}).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "name"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+			DoUpdates:   clause.AssignmentColumns([]string{"last_id"}),
+		}).
+		Create(&BridgeStatsCursor{Name: name, LastID: lastID}).
+		Error
+	if err != nil {
+		return fmt.Errorf("BridgeStatsCursor.UpdateLastID error: %w", err)
+	}
+	return nil
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // AssetType can be ETH/ERC20/ERC1155/ERC721
@@ -54,25 +56,39 @@ const (
 // CrossMsg represents a cross message from layer 1 to layer 2
 type CrossMsg struct {
 	db *gorm.DB `gorm:"column:-"`
-
-	ID           uint64         `json:"id" gorm:"column:id"`
-	MsgHash      string         `json:"msg_hash" gorm:"column:msg_hash"`
-	Height       uint64         `json:"height" gorm:"column:height"`
-	Sender       string         `json:"sender" gorm:"column:sender"`
-	Target       string         `json:"target" gorm:"column:target"`
-	Amount       string         `json:"amount" gorm:"column:amount"`
-	Layer1Hash   string         `json:"layer1_hash" gorm:"column:layer1_hash;default:''"`
-	Layer2Hash   string         `json:"layer2_hash" gorm:"column:layer2_hash;default:''"`
-	Layer1Token  string         `json:"layer1_token" gorm:"column:layer1_token;default:''"`
-	Layer2Token  string         `json:"layer2_token" gorm:"column:layer2_token;default:''"`
-	TokenIDs     string         `json:"token_ids" gorm:"column:token_ids;default:''"`
-	TokenAmounts string         `json:"token_amounts" gorm:"column:token_amounts;default:''"`
-	Asset        int            `json:"asset" gorm:"column:asset"`
-	MsgType      int            `json:"msg_type" gorm:"column:msg_type"`
-	Timestamp    *time.Time     `json:"timestamp" gorm:"column:block_timestamp;default;NULL"`
-	CreatedAt    *time.Time     `json:"created_at" gorm:"column:created_at"`
-	UpdatedAt    *time.Time     `json:"updated_at" gorm:"column:updated_at"`
-	DeletedAt    gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+	// excludedSenders the senders whose deposits the queries of the histories skip, e.g. the faucets of the testnet
+	excludedSenders []string `gorm:"column:-"`
+
+	ID            uint64         `json:"id" gorm:"column:id"`
+	MsgHash       string         `json:"msg_hash" gorm:"column:msg_hash"`
+	Height        uint64         `json:"height" gorm:"column:height"`
+	Sender        string         `json:"sender" gorm:"column:sender"`
+	Target        string         `json:"target" gorm:"column:target"`
+	Amount        string         `json:"amount" gorm:"column:amount"`
+	Layer1Hash    string         `json:"layer1_hash" gorm:"column:layer1_hash;default:''"`
+	Layer2Hash    string         `json:"layer2_hash" gorm:"column:layer2_hash;default:''"`
+	BlockHash     string         `json:"block_hash" gorm:"column:block_hash;default:''"`
+	OriginMethod  string         `json:"origin_method" gorm:"column:origin_method;default:''"`
+	OriginTxNonce *uint64        `json:"origin_tx_nonce" gorm:"column:origin_tx_nonce"`
+	Gateway       string         `json:"gateway" gorm:"column:gateway;default:''"`
+	MsgSender     string         `json:"msg_sender" gorm:"column:msg_sender;default:''"`
+	MsgTarget     string         `json:"msg_target" gorm:"column:msg_target;default:''"`
+	MsgValue      string         `json:"msg_value" gorm:"column:msg_value;default:''"`
+	MsgNonce      uint64         `json:"msg_nonce" gorm:"column:msg_nonce;default:0"`
+	MsgData       string         `json:"msg_data" gorm:"column:msg_data;default:''"`
+	Layer1Token   string         `json:"layer1_token" gorm:"column:layer1_token;default:''"`
+	Layer2Token   string         `json:"layer2_token" gorm:"column:layer2_token;default:''"`
+	TokenIDs      string         `json:"token_ids" gorm:"column:token_ids;default:''"`
+	TokenAmounts  string         `json:"token_amounts" gorm:"column:token_amounts;default:''"`
+	GasFee        string         `json:"gas_fee" gorm:"column:gas_fee;default:''"`
+	BridgeFee     string         `json:"bridge_fee" gorm:"column:bridge_fee;default:''"`
+	L1DataFee     string         `json:"l1_data_fee" gorm:"column:l1_data_fee;default:''"`
+	Asset         int            `json:"asset" gorm:"column:asset"`
+	MsgType       int            `json:"msg_type" gorm:"column:msg_type"`
+	Timestamp     *time.Time     `json:"timestamp" gorm:"column:block_timestamp;default;NULL"`
+	CreatedAt     *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt     *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
 }
 
 // TableName returns the table name for the CrossMsg model.
@@ -85,6 +101,43 @@ func NewCrossMsg(db *gorm.DB) *CrossMsg {
 	return &CrossMsg{db: db}
 }
 
+// NewCrossMsgExcludingSenders returns a new instance of CrossMsg whose queries of the histories skip the deposits sent
+// by the senders, so that the pages and the totals are counted without them
+func NewCrossMsgExcludingSenders(db *gorm.DB, senders []string) *CrossMsg {
+	return &CrossMsg{db: db, excludedSenders: senders}
+}
+
+// excludeSenders adds the condition skipping the deposits of the excluded senders to the query of the messages, the
+// table of the messages isn't aliased
+func excludeSenders(db *gorm.DB, senders []string) *gorm.DB {
+	if len(senders) == 0 {
+		return db
+	}
+	return db.Where("(msg_type != ? OR sender NOT IN (?))", Layer1Msg, senders)
+}
+
+// TokenIDList returns the ids of the NFTs bridged by the message, nil for ETH and ERC20
+func (c *CrossMsg) TokenIDList() []string {
+	return splitTokens(c.TokenIDs)
+}
+
+// TokenAmountList returns the amounts of the ERC1155 token ids bridged by the message, nil otherwise
+func (c *CrossMsg) TokenAmountList() []string {
+	return splitTokens(c.TokenAmounts)
+}
+
+// splitTokens splits the token ids or amounts stored comma separated
+func splitTokens(tokens string) []string {
+	if tokens == "" {
+		return nil
+	}
+	list := strings.Split(tokens, ",")
+	for i := range list {
+		list[i] = strings.TrimSpace(list[i])
+	}
+	return list
+}
+
 // L1 Cross Msgs Operations
 
 // GetL1CrossMsgByHash returns layer1 cross message by given hash
@@ -117,6 +170,41 @@ func (c *CrossMsg) GetLatestL1ProcessedHeight(ctx context.Context) (uint64, erro
 	return result.Height, nil
 }
 
+// GetL1CrossMsgByMsgHashList returns layer1 cross messages under given msg hashes
+func (c *CrossMsg) GetL1CrossMsgByMsgHashList(ctx context.Context, msgHashList []string) ([]*CrossMsg, error) {
+	var results []*CrossMsg
+	err := c.db.WithContext(ctx).Model(&CrossMsg{}).
+		Where("msg_hash IN (?) AND msg_type = ?", msgHashList, Layer1Msg).
+		Find(&results).
+		Error
+
+	if err != nil {
+		return nil, fmt.Errorf("CrossMsg.GetL1CrossMsgByMsgHashList error: %w", err)
+	}
+	if len(results) == 0 {
+		log.Debug("no CrossMsg under given msg hashes", "msg hash list", msgHashList)
+	}
+	return results, nil
+}
+
+// GetL1CrossMsgsByMsgNonces get the layer1 cross messages sent at the nonces, the nonce of a deposit is the index in
+// the L1MessageQueue it's sent at
+func (c *CrossMsg) GetL1CrossMsgsByMsgNonces(ctx context.Context, msgNonces []uint64, dbTx ...*gorm.DB) ([]*CrossMsg, error) {
+	db := c.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	var results []*CrossMsg
+	err := db.WithContext(ctx).Model(&CrossMsg{}).
+		Where("msg_nonce IN (?) AND msg_type = ? AND msg_sender != ''", msgNonces, Layer1Msg).
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("CrossMsg.GetL1CrossMsgsByMsgNonces error: %w", err)
+	}
+	return results, nil
+}
+
 // GetL1EarliestNoBlockTimestampHeight returns the earliest layer1 cross message height which has no block timestamp
 func (c *CrossMsg) GetL1EarliestNoBlockTimestampHeight(ctx context.Context) (uint64, error) {
 	var result CrossMsg
@@ -135,7 +223,8 @@ func (c *CrossMsg) GetL1EarliestNoBlockTimestampHeight(ctx context.Context) (uin
 	return result.Height, nil
 }
 
-// InsertL1CrossMsg batch insert layer1 cross messages into db
+// InsertL1CrossMsg batch insert layer1 cross messages into db. The messages saved already are skipped,
+// so that a block range can be indexed again
 func (c *CrossMsg) InsertL1CrossMsg(ctx context.Context, messages []*CrossMsg, dbTx ...*gorm.DB) error {
 	if len(messages) == 0 {
 		return nil
@@ -145,7 +234,7 @@ func (c *CrossMsg) InsertL1CrossMsg(ctx context.Context, messages []*CrossMsg, d
 		db = dbTx[0]
 	}
 	db.WithContext(ctx)
-	err := db.Model(&CrossMsg{}).Create(&messages).Error
+	err := db.Model(&CrossMsg{}).Clauses(clause.OnConflict{DoNothing: true}).Create(&messages).Error
 	if err != nil {
 		l1hashes := make([]string, 0, len(messages))
 		heights := make([]uint64, 0, len(messages))
@@ -185,6 +274,20 @@ func (c *CrossMsg) UpdateL1BlockTimestamp(ctx context.Context, height uint64, ti
 	return err
 }
 
+// GetCrossMsgsAfterHeight returns the cross messages of the layer after given height, e.g. the ones a reorg deletes
+func (c *CrossMsg) GetCrossMsgsAfterHeight(ctx context.Context, layer MsgType, height uint64, dbTx ...*gorm.DB) ([]*CrossMsg, error) {
+	db := c.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	var results []*CrossMsg
+	err := db.WithContext(ctx).Model(&CrossMsg{}).Where("height > ? AND msg_type = ?", height, layer).Find(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("CrossMsg.GetCrossMsgsAfterHeight error: %w", err)
+	}
+	return results, nil
+}
+
 // DeleteL1CrossMsgAfterHeight soft delete layer1 cross messages after given height
 func (c *CrossMsg) DeleteL1CrossMsgAfterHeight(ctx context.Context, height uint64, dbTx ...*gorm.DB) error {
 	db := c.db
@@ -267,7 +370,7 @@ func (c *CrossMsg) GetL2EarliestNoBlockTimestampHeight(ctx context.Context) (uin
 	return result.Height, nil
 }
 
-// InsertL2CrossMsg batch insert layer2 cross messages
+// InsertL2CrossMsg batch insert layer2 cross messages, the messages saved already are skipped
 func (c *CrossMsg) InsertL2CrossMsg(ctx context.Context, messages []*CrossMsg, dbTx ...*gorm.DB) error {
 	if len(messages) == 0 {
 		return nil
@@ -277,7 +380,7 @@ func (c *CrossMsg) InsertL2CrossMsg(ctx context.Context, messages []*CrossMsg, d
 		db = dbTx[0]
 	}
 	db.WithContext(ctx)
-	err := db.Model(&CrossMsg{}).Create(&messages).Error
+	err := db.Model(&CrossMsg{}).Clauses(clause.OnConflict{DoNothing: true}).Create(&messages).Error
 	if err != nil {
 		l2hashes := make([]string, 0, len(messages))
 		heights := make([]uint64, 0, len(messages))
@@ -325,8 +428,7 @@ func (c *CrossMsg) DeleteL2CrossMsgFromHeight(ctx context.Context, height uint64
 	if len(dbTx) > 0 && dbTx[0] != nil {
 		db = dbTx[0]
 	}
-	db.WithContext(ctx)
-	err := db.Model(&CrossMsg{}).Delete("height > ? AND msg_type = ?", height, Layer2Msg).Error
+	err := db.WithContext(ctx).Delete(&CrossMsg{}, "height > ? AND msg_type = ?", height, Layer2Msg).Error
 	if err != nil {
 		return fmt.Errorf("CrossMsg.DeleteL2CrossMsgFromHeight error: %w", err)
 
@@ -370,12 +472,497 @@ func (c *CrossMsg) GetCrossMsgsByAddressWithOffset(ctx context.Context, sender s
 }
 
 // GetCrossMsgsByHashes retrieves a list of cross messages identified by their Layer 1 or Layer 2 hashes.
-func (c *CrossMsg) GetCrossMsgsByHashes(ctx context.Context, hashes []string) ([]*CrossMsg, error) {
+// Only the messages bridging one of the assets are returned, all of them if assets is empty. The messages are sorted
+// by block timestamp then tx hash in the given order, the id breaks the remaining ties.
+func (c *CrossMsg) GetCrossMsgsByHashes(ctx context.Context, hashes []string, assets []AssetType, order SortOrder) ([]*CrossMsg, error) {
 	var results []*CrossMsg
-	err := c.db.WithContext(ctx).Model(&CrossMsg{}).Where("layer1_hash IN (?) OR layer2_hash IN (?)", hashes, hashes).Find(&results).Error
+	db := c.db.WithContext(ctx).Model(&CrossMsg{}).Where("layer1_hash IN (?) OR layer2_hash IN (?)", hashes, hashes)
+	db = excludeSenders(db, c.excludedSenders)
+	if len(assets) != 0 {
+		query, args := assetCondition("", assets)
+		db = db.Where(query, args...)
+	}
+	db = db.Order(order.orderBy("block_timestamp", "layer1_hash", "layer2_hash", "id"))
+	err := db.Find(&results).Error
 	if err != nil {
 		return nil, fmt.Errorf("CrossMsg.GetCrossMsgsByHashes error: %w", err)
 	}
 
 	return results, nil
 }
+
+// GetCrossMsgsAfterID returns at most limit cross messages of the msg type with an id greater than afterID ordered by id
+func (c *CrossMsg) GetCrossMsgsAfterID(ctx context.Context, msgType MsgType, afterID uint64, limit int) ([]*CrossMsg, error) {
+	var results []*CrossMsg
+	err := c.db.WithContext(ctx).Model(&CrossMsg{}).
+		Where("msg_type = ? AND id > ?", msgType, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("CrossMsg.GetCrossMsgsAfterID error: %w", err)
+	}
+	return results, nil
+}
+
+// GetCrossMsgByTxHashUnscoped returns the first cross message sent by the layer1 or layer2 tx, including the ones
+// soft deleted by a reorg. The messages still on chain come first, nil is returned if none is found.
+func (c *CrossMsg) GetCrossMsgByTxHashUnscoped(ctx context.Context, txHash string) (*CrossMsg, error) {
+	var result CrossMsg
+	err := c.db.WithContext(ctx).Unscoped().Model(&CrossMsg{}).
+		Where("layer1_hash = ? OR layer2_hash = ?", txHash, txHash).
+		Order("deleted_at IS NOT NULL, id ASC").
+		First(&result).
+		Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("CrossMsg.GetCrossMsgByTxHashUnscoped error: %w", err)
+	}
+	return &result, nil
+}
+
+// UpdateCrossMsgEnrichments update the gas fee, the bridge fee, the layer1 data fee, the origin method, the origin tx
+// nonce and the block timestamp of each cross message by its id, the other columns are kept
+func (c *CrossMsg) UpdateCrossMsgEnrichments(ctx context.Context, messages []*CrossMsg, dbTx ...*gorm.DB) error {
+	db := c.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	for _, message := range messages {
+		err := db.WithContext(ctx).Model(&CrossMsg{}).
+			Where("id = ?", message.ID).
+			Updates(map[string]interface{}{
+				"gas_fee":         message.GasFee,
+				"bridge_fee":      message.BridgeFee,
+				"l1_data_fee":     message.L1DataFee,
+				"origin_method":   message.OriginMethod,
+				"origin_tx_nonce": message.OriginTxNonce,
+				"block_timestamp": message.Timestamp,
+			}).Error
+		if err != nil {
+			return fmt.Errorf("CrossMsg.UpdateCrossMsgEnrichments error: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteCrossMsgsByTxHash soft delete the cross messages of the layer sent by the tx, the number of the messages
+// deleted is returned
+func (c *CrossMsg) DeleteCrossMsgsByTxHash(ctx context.Context, msgType MsgType, txHash string, dbTx ...*gorm.DB) (int64, error) {
+	db := c.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	column := "layer1_hash"
+	if msgType == Layer2Msg {
+		column = "layer2_hash"
+	}
+	result := db.WithContext(ctx).Delete(&CrossMsg{}, column+" = ? AND msg_type = ?", txHash, msgType)
+	if result.Error != nil {
+		return 0, fmt.Errorf("CrossMsg.DeleteCrossMsgsByTxHash error: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// GetCrossMsgByID get the cross message of the id, nil is returned if it doesn't exist
+func (c *CrossMsg) GetCrossMsgByID(ctx context.Context, id uint64) (*CrossMsg, error) {
+	var result CrossMsg
+	err := c.db.WithContext(ctx).Model(&CrossMsg{}).Where("id = ?", id).First(&result).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("CrossMsg.GetCrossMsgByID error: %w", err)
+	}
+	return &result, nil
+}
+
+// DeleteCrossMsgByID soft delete the cross message of the id, the number of the messages deleted is returned
+func (c *CrossMsg) DeleteCrossMsgByID(ctx context.Context, id uint64, dbTx ...*gorm.DB) (int64, error) {
+	db := c.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	result := db.WithContext(ctx).Delete(&CrossMsg{}, "id = ?", id)
+	if result.Error != nil {
+		return 0, fmt.Errorf("CrossMsg.DeleteCrossMsgByID error: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// assetCondition returns the condition selecting the cross messages bridging one of the assets, prefix is the
+// prefix of the cross_message columns, e.g. "c.". The messages without token addresses bridge native ETH,
+// whatever their asset.
+func assetCondition(prefix string, assets []AssetType) (string, []interface{}) {
+	assetValues := make([]int, len(assets))
+	for i, asset := range assets {
+		assetValues[i] = int(asset)
+	}
+	emptyTokens := []string{"", common.Address{}.Hex()}
+	noToken := fmt.Sprintf("(%[1]slayer1_token IN (?) AND %[1]slayer2_token IN (?))", prefix)
+	if includesETH(assets) {
+		return fmt.Sprintf("(%sasset IN (?) OR %s)", prefix, noToken), []interface{}{assetValues, emptyTokens, emptyTokens}
+	}
+	return fmt.Sprintf("(%sasset IN (?) AND NOT %s)", prefix, noToken), []interface{}{assetValues, emptyTokens, emptyTokens}
+}
+
+// includesETH returns whether native ETH is one of the assets
+func includesETH(assets []AssetType) bool {
+	for _, asset := range assets {
+		if asset == ETH {
+			return true
+		}
+	}
+	return false
+}
+
+// MsgRange bounds the messages by their height, or by their block timestamp in unix seconds if ByTimestamp is set.
+// The bounds are inclusive and the nil bounds are open, the messages without block timestamp are out of any
+// timestamp range.
+type MsgRange struct {
+	From        *uint64
+	To          *uint64
+	ByTimestamp bool
+}
+
+// where adds the bounds of the range to the query, the query is returned as is if the range is nil
+func (r *MsgRange) where(db *gorm.DB) *gorm.DB {
+	if r == nil {
+		return db
+	}
+	column := "height"
+	bound := func(value uint64) interface{} { return value }
+	if r.ByTimestamp {
+		column = "block_timestamp"
+		bound = func(value uint64) interface{} { return time.Unix(int64(value), 0).UTC() }
+	}
+	if r.From != nil {
+		db = db.Where(column+" >= ?", bound(*r.From))
+	}
+	if r.To != nil {
+		db = db.Where(column+" <= ?", bound(*r.To))
+	}
+	return db
+}
+
+// SortOrder the direction the messages are sorted in
+type SortOrder int
+
+const (
+	// SortDesc = 0, the latest messages first
+	SortDesc SortOrder = iota
+	// SortAsc = 1, the oldest messages first
+	SortAsc
+)
+
+// orderBy returns the ORDER BY clause sorting by the columns in this direction, the columns are compared in order
+// so the last ones break the ties of the first ones. The null values sort as the latest ones in both directions,
+// so reversing the direction reverses the sequence exactly.
+func (o SortOrder) orderBy(columns ...string) string {
+	direction := " DESC NULLS FIRST"
+	if o == SortAsc {
+		direction = " ASC NULLS LAST"
+	}
+	return strings.Join(columns, direction+", ") + direction
+}
+
+// MsgCursor the position of a message in the keyset paginated queries, the messages are ordered by height, tx hash and
+// msg hash desc so the position doesn't move when new messages are inserted
+type MsgCursor struct {
+	Height  uint64
+	TxHash  string
+	MsgHash string
+}
+
+// keysetOrder the ORDER BY clause of the keyset paginated queries
+const keysetOrder = "height DESC, tx_hash DESC, msg_hash DESC"
+
+// after adds the condition selecting the messages right after the cursor to the query whose rows have the tx_hash
+// column, the query is returned as is if the cursor is nil
+func (c *MsgCursor) after(db *gorm.DB) *gorm.DB {
+	if c == nil {
+		return db
+	}
+	return db.Where("(height, tx_hash, msg_hash) < (?, ?, ?)", c.Height, c.TxHash, c.MsgHash)
+}
+
+// depositColumns the columns of the layer1 deposits in the merged data sets of the deposits and the withdrawals
+const depositColumns = "id, msg_hash, height, sender, target, amount, layer1_hash, layer2_hash, block_hash, layer1_token, layer2_token, asset, origin_method, origin_tx_nonce, gateway, gas_fee, bridge_fee, l1_data_fee, msg_type, block_timestamp, created_at"
+
+// unifiedMsgsByAddressQuery merges the layer1 deposits and the layer2 withdrawals of the given address into one data set.
+// Withdrawals are sourced from l2_sent_msg, so messages sent by calling the messenger directly are included too.
+func (c *CrossMsg) unifiedMsgsByAddressQuery(ctx context.Context, address string) *gorm.DB {
+	deposits := c.depositMsgsQuery(ctx).
+		Where("sender = ? AND msg_type = ? AND deleted_at IS NULL", address, Layer1Msg)
+
+	withdrawals := c.withdrawalMsgsQuery(ctx).
+		Where("(s.original_sender = ? OR s.sender = ?) AND s.deleted_at IS NULL", address, address)
+
+	return c.db.WithContext(ctx).Table("(? UNION ALL ?) AS unified", deposits, withdrawals)
+}
+
+// msgsBetweenQuery merges the layer1 deposits and the layer2 withdrawals sent by from to the recipient to into one data set
+func (c *CrossMsg) msgsBetweenQuery(ctx context.Context, from, to string) *gorm.DB {
+	deposits := c.depositMsgsQuery(ctx).
+		Where("sender = ? AND target = ? AND msg_type = ? AND deleted_at IS NULL", from, to, Layer1Msg)
+
+	withdrawals := c.withdrawalMsgsQuery(ctx).
+		Where("(s.original_sender = ? OR s.sender = ?) AND COALESCE(c.target, s.target) = ? AND s.deleted_at IS NULL", from, from, to)
+
+	return c.db.WithContext(ctx).Table("(? UNION ALL ?) AS between_msgs", deposits, withdrawals)
+}
+
+// depositMsgsQuery selects the layer1 deposits from cross_message_all in the cross message columns, the deposits of
+// the excluded senders are skipped
+func (c *CrossMsg) depositMsgsQuery(ctx context.Context) *gorm.DB {
+	return excludeSenders(c.db.WithContext(ctx).Table(CrossMsgAllViewName).Select(depositColumns), c.excludedSenders)
+}
+
+// withdrawalMsgsQuery selects the layer2 withdrawals from l2_sent_msg_all (aliased s) in the cross message columns,
+// token and block timestamp info are taken from the matched layer2 cross message if exists. The archived withdrawals
+// are selected along with the hot ones, as the deposits read from cross_message_all by the merged data sets.
+func (c *CrossMsg) withdrawalMsgsQuery(ctx context.Context) *gorm.DB {
+	return c.db.WithContext(ctx).Table(L2SentMsgAllViewName+" AS s").
+		Select("s.id, s.msg_hash, s.height, COALESCE(NULLIF(s.original_sender, ''), s.sender) AS sender, "+
+			"COALESCE(c.target, s.target) AS target, COALESCE(c.amount, s.value) AS amount, '' AS layer1_hash, s.tx_hash AS layer2_hash, COALESCE(c.block_hash, '') AS block_hash, "+
+			"COALESCE(c.layer1_token, '') AS layer1_token, COALESCE(c.layer2_token, '') AS layer2_token, COALESCE(c.asset, CAST(? AS SMALLINT)) AS asset, "+
+			"'' AS origin_method, CAST(NULL AS BIGINT) AS origin_tx_nonce, COALESCE(c.gateway, '') AS gateway, COALESCE(c.gas_fee, '') AS gas_fee, COALESCE(c.bridge_fee, '') AS bridge_fee, COALESCE(c.l1_data_fee, '') AS l1_data_fee, CAST(? AS SMALLINT) AS msg_type, c.block_timestamp, s.created_at", int(ETH), int(Layer2Msg)).
+		Joins("LEFT JOIN "+CrossMsgAllViewName+" AS c ON c.msg_hash = s.msg_hash AND c.msg_type = ? AND c.deleted_at IS NULL", Layer2Msg)
+}
+
+// relayedWithdrawalsByRelayerQuery selects the layer2 withdrawals claimed on layer1 by the given relayer
+func (c *CrossMsg) relayedWithdrawalsByRelayerQuery(ctx context.Context, relayer string) *gorm.DB {
+	withdrawals := c.withdrawalMsgsQuery(ctx).
+		Where("s.deleted_at IS NULL AND EXISTS (SELECT 1 FROM relayed_msg AS r WHERE r.msg_hash = s.msg_hash AND r.relayer = ? AND r.layer1_hash != '' AND r.deleted_at IS NULL)", relayer)
+	return c.db.WithContext(ctx).Table("(?) AS relayed", withdrawals)
+}
+
+// msgsByTypeQuery selects the layer1 deposits of all addresses if msgType is Layer1Msg, the layer2 withdrawals if
+// Layer2Msg, and both merged into one data set otherwise
+func (c *CrossMsg) msgsByTypeQuery(ctx context.Context, msgType MsgType) *gorm.DB {
+	deposits := c.depositMsgsQuery(ctx).
+		Where("msg_type = ? AND deleted_at IS NULL", Layer1Msg)
+	withdrawals := c.withdrawalMsgsQuery(ctx).Where("s.deleted_at IS NULL")
+	switch msgType {
+	case Layer1Msg:
+		return c.db.WithContext(ctx).Table("(?) AS ranged", deposits)
+	case Layer2Msg:
+		return c.db.WithContext(ctx).Table("(?) AS ranged", withdrawals)
+	default:
+		return c.db.WithContext(ctx).Table("(? UNION ALL ?) AS ranged", deposits, withdrawals)
+	}
+}
+
+// withdrawalsInBlocksQuery selects the layer2 withdrawals sent in the layer2 blocks from startBlock to endBlock inclusive
+func (c *CrossMsg) withdrawalsInBlocksQuery(ctx context.Context, startBlock, endBlock uint64) *gorm.DB {
+	withdrawals := c.withdrawalMsgsQuery(ctx).
+		Where("s.height BETWEEN ? AND ? AND s.deleted_at IS NULL", startBlock, endBlock)
+	return c.db.WithContext(ctx).Table("(?) AS batch_msgs", withdrawals)
+}
+
+// GetTotalUnifiedMsgCountByAddress get the total count of the merged deposits and withdrawals of the given address
+// within msgRange, nil msgRange counts all of them
+func (c *CrossMsg) GetTotalUnifiedMsgCountByAddress(ctx context.Context, address string, msgRange *MsgRange) (uint64, error) {
+	var count int64
+	err := msgRange.where(c.unifiedMsgsByAddressQuery(ctx, address)).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("CrossMsg.GetTotalUnifiedMsgCountByAddress error: %w", err)
+	}
+	return uint64(count), nil
+}
+
+// GetUnifiedMsgsByAddressWithOffset get the merged deposits and withdrawals of the given address within msgRange ordered
+// by block timestamp, the messages not having block timestamp yet come first. nil msgRange doesn't bound the messages.
+func (c *CrossMsg) GetUnifiedMsgsByAddressWithOffset(ctx context.Context, address string, msgRange *MsgRange, offset int, limit int) ([]*CrossMsg, error) {
+	var messages []*CrossMsg
+	// soft deleted rows are already excluded in the sub queries
+	err := msgRange.where(c.unifiedMsgsByAddressQuery(ctx, address)).Unscoped().
+		Order("block_timestamp DESC NULLS FIRST, height DESC, msg_hash DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&messages).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("CrossMsg.GetUnifiedMsgsByAddressWithOffset error: %w", err)
+	}
+	return messages, nil
+}
+
+// GetUnifiedMsgsByAddressWithKeyset get at most limit merged deposits and withdrawals of the given address ordered by
+// height, tx hash and msg hash desc, starting right after the cursor, or from the latest message if cursor is nil.
+// The heights of the deposits are layer1 block numbers and the ones of the withdrawals are layer2 block numbers.
+func (c *CrossMsg) GetUnifiedMsgsByAddressWithKeyset(ctx context.Context, address string, cursor *MsgCursor, limit int) ([]*CrossMsg, error) {
+	var messages []*CrossMsg
+	unified := c.unifiedMsgsByAddressQuery(ctx, address).
+		Select("*, CASE WHEN msg_type = ? THEN layer1_hash ELSE layer2_hash END AS tx_hash", Layer1Msg)
+	// soft deleted rows are already excluded in the sub queries
+	err := cursor.after(c.db.WithContext(ctx).Table("(?) AS keyset", unified)).Unscoped().
+		Order(keysetOrder).
+		Limit(limit).
+		Find(&messages).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("CrossMsg.GetUnifiedMsgsByAddressWithKeyset error: %w", err)
+	}
+	return messages, nil
+}
+
+// GetUnifiedMsgsByAddress get all the merged deposits and withdrawals of the given address in the same order as
+// GetUnifiedMsgsByAddressWithOffset
+func (c *CrossMsg) GetUnifiedMsgsByAddress(ctx context.Context, address string) ([]*CrossMsg, error) {
+	var messages []*CrossMsg
+	err := c.unifiedMsgsByAddressQuery(ctx, address).Unscoped().
+		Order("block_timestamp DESC NULLS FIRST, height DESC, msg_hash DESC").
+		Find(&messages).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("CrossMsg.GetUnifiedMsgsByAddress error: %w", err)
+	}
+	return messages, nil
+}
+
+// GetTotalMsgCountBetween get the total count of the deposits and withdrawals sent by from to the recipient to
+func (c *CrossMsg) GetTotalMsgCountBetween(ctx context.Context, from, to string) (uint64, error) {
+	var count int64
+	err := c.msgsBetweenQuery(ctx, from, to).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("CrossMsg.GetTotalMsgCountBetween error: %w", err)
+	}
+	return uint64(count), nil
+}
+
+// GetMsgsBetweenWithOffset get the deposits and withdrawals sent by from to the recipient to, in the same order as
+// GetUnifiedMsgsByAddressWithOffset
+func (c *CrossMsg) GetMsgsBetweenWithOffset(ctx context.Context, from, to string, offset int, limit int) ([]*CrossMsg, error) {
+	var messages []*CrossMsg
+	// soft deleted rows are already excluded in the sub queries
+	err := c.msgsBetweenQuery(ctx, from, to).Unscoped().
+		Order("block_timestamp DESC NULLS FIRST, height DESC, msg_hash DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&messages).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("CrossMsg.GetMsgsBetweenWithOffset error: %w", err)
+	}
+	return messages, nil
+}
+
+// GetTotalRelayedMsgCountByRelayer get the total count of the withdrawals claimed by the given relayer
+func (c *CrossMsg) GetTotalRelayedMsgCountByRelayer(ctx context.Context, relayer string) (uint64, error) {
+	var count int64
+	err := c.relayedWithdrawalsByRelayerQuery(ctx, relayer).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("CrossMsg.GetTotalRelayedMsgCountByRelayer error: %w", err)
+	}
+	return uint64(count), nil
+}
+
+// GetRelayedMsgsByRelayerWithOffset get the withdrawals claimed by the given relayer ordered by block timestamp
+func (c *CrossMsg) GetRelayedMsgsByRelayerWithOffset(ctx context.Context, relayer string, offset int, limit int) ([]*CrossMsg, error) {
+	var messages []*CrossMsg
+	// soft deleted rows are already excluded in the sub query
+	err := c.relayedWithdrawalsByRelayerQuery(ctx, relayer).Unscoped().
+		Order("block_timestamp DESC NULLS FIRST, height DESC, msg_hash DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&messages).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("CrossMsg.GetRelayedMsgsByRelayerWithOffset error: %w", err)
+	}
+	return messages, nil
+}
+
+// GetTotalMsgCountInRange get the total count of the messages of all addresses of msgType within msgRange, UnknownMsg
+// counts both the deposits and the withdrawals. The withdrawals whose layer2 cross message isn't indexed have no block
+// timestamp and are out of the timestamp ranges.
+func (c *CrossMsg) GetTotalMsgCountInRange(ctx context.Context, msgType MsgType, msgRange *MsgRange) (uint64, error) {
+	var count int64
+	err := msgRange.where(c.msgsByTypeQuery(ctx, msgType)).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("CrossMsg.GetTotalMsgCountInRange error: %w", err)
+	}
+	return uint64(count), nil
+}
+
+// GetMsgsInRangeWithOffset get the messages of all addresses of msgType within msgRange, ordered by block timestamp
+// in the order given
+func (c *CrossMsg) GetMsgsInRangeWithOffset(ctx context.Context, msgType MsgType, msgRange *MsgRange, order SortOrder, offset int, limit int) ([]*CrossMsg, error) {
+	var messages []*CrossMsg
+	// soft deleted rows are already excluded in the sub queries
+	err := msgRange.where(c.msgsByTypeQuery(ctx, msgType)).Unscoped().
+		Order(order.orderBy("block_timestamp", "height", "msg_hash")).
+		Limit(limit).
+		Offset(offset).
+		Find(&messages).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("CrossMsg.GetMsgsInRangeWithOffset error: %w", err)
+	}
+	return messages, nil
+}
+
+// GetTotalWithdrawalCountInBlocks get the total count of the layer2 withdrawals sent in the layer2 blocks from
+// startBlock to endBlock inclusive
+func (c *CrossMsg) GetTotalWithdrawalCountInBlocks(ctx context.Context, startBlock, endBlock uint64) (uint64, error) {
+	var count int64
+	err := c.withdrawalsInBlocksQuery(ctx, startBlock, endBlock).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("CrossMsg.GetTotalWithdrawalCountInBlocks error: %w", err)
+	}
+	return uint64(count), nil
+}
+
+// GetWithdrawalsInBlocksWithOffset get the layer2 withdrawals sent in the layer2 blocks from startBlock to endBlock
+// inclusive, in the order they are sent
+func (c *CrossMsg) GetWithdrawalsInBlocksWithOffset(ctx context.Context, startBlock, endBlock uint64, offset int, limit int) ([]*CrossMsg, error) {
+	var messages []*CrossMsg
+	// soft deleted rows are already excluded in the sub query
+	err := c.withdrawalsInBlocksQuery(ctx, startBlock, endBlock).Unscoped().
+		Order("height ASC, id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&messages).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("CrossMsg.GetWithdrawalsInBlocksWithOffset error: %w", err)
+	}
+	return messages, nil
+}
+
+// GetStaleClaimableWithdrawals get the withdrawals of all addresses which are claimable but not claimed, and sent before the
+// given time. The layer2 block timestamp is used if known, otherwise the time the message is indexed.
+func (c *CrossMsg) GetStaleClaimableWithdrawals(ctx context.Context, before time.Time) ([]*CrossMsg, error) {
+	var messages []*CrossMsg
+	withdrawals := c.withdrawalMsgsQuery(ctx).
+		Where("s.msg_proof != '' AND s.deleted_at IS NULL AND COALESCE(c.block_timestamp, s.created_at) < ?", before).
+		Where("NOT EXISTS (SELECT 1 FROM relayed_msg AS r WHERE r.msg_hash = s.msg_hash AND r.deleted_at IS NULL)")
+	err := c.db.WithContext(ctx).Table("(?) AS stale", withdrawals).Unscoped().
+		Order("id ASC").
+		Find(&messages).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("CrossMsg.GetStaleClaimableWithdrawals error: %w", err)
+	}
+	return messages, nil
+}
+
+// GetClaimableWithdrawalsByAddress get the withdrawals sent by the address which are claimable but not claimed
+func (c *CrossMsg) GetClaimableWithdrawalsByAddress(ctx context.Context, address string) ([]*CrossMsg, error) {
+	var messages []*CrossMsg
+	withdrawals := c.withdrawalMsgsQuery(ctx).
+		Where("(s.original_sender = ? OR s.sender = ?) AND s.msg_proof != '' AND s.deleted_at IS NULL", address, address).
+		Where("NOT EXISTS (SELECT 1 FROM relayed_msg AS r WHERE r.msg_hash = s.msg_hash AND r.deleted_at IS NULL)")
+	err := c.db.WithContext(ctx).Table("(?) AS claimable", withdrawals).Unscoped().
+		Order("id ASC").
+		Find(&messages).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("CrossMsg.GetClaimableWithdrawalsByAddress error: %w", err)
+	}
+	return messages, nil
+}
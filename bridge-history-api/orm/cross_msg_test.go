@@ -0,0 +1,595 @@
+package orm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"bridge-history-api/orm/migrate"
+
+	"scroll-tech/common/database"
+	"scroll-tech/common/docker"
+)
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	base := docker.NewDockerApp()
+	base.RunDBImage(t)
+	t.Cleanup(base.Free)
+
+	db, err := database.InitDB(
+		&database.Config{
+			DSN:        base.DBConfig.DSN,
+			DriverName: base.DBConfig.DriverName,
+			MaxOpenNum: base.DBConfig.MaxOpenNum,
+			MaxIdleNum: base.DBConfig.MaxIdleNum,
+		},
+	)
+	assert.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, migrate.ResetDB(sqlDB))
+	return db
+}
+
+func TestGetUnifiedMsgsByAddressWithOffset(t *testing.T) {
+	db := setupTestDB(t)
+	crossMsgOrm := NewCrossMsg(db)
+	l2SentMsgOrm := NewL2SentMsg(db)
+
+	ts := func(sec int64) *time.Time {
+		tm := time.Unix(sec, 0).UTC()
+		return &tm
+	}
+
+	deposits := []*CrossMsg{
+		{MsgHash: "deposit1", Height: 1, Sender: "sender1", Target: "target1", Amount: "1", Layer1Hash: "l1hash1", MsgType: int(Layer1Msg), Timestamp: ts(100)},
+		{MsgHash: "deposit2", Height: 3, Sender: "sender1", Target: "target1", Amount: "3", Layer1Hash: "l1hash2", MsgType: int(Layer1Msg), Timestamp: ts(300)},
+		{MsgHash: "deposit3", Height: 4, Sender: "sender2", Target: "target2", Amount: "4", Layer1Hash: "l1hash3", MsgType: int(Layer1Msg), Timestamp: ts(350)},
+	}
+	assert.NoError(t, crossMsgOrm.InsertL1CrossMsg(context.Background(), deposits))
+
+	withdrawals := []*CrossMsg{
+		{MsgHash: "withdraw1", Height: 2, Sender: "sender1", Target: "target1", Amount: "2", Layer2Hash: "l2hash1", MsgType: int(Layer2Msg), Timestamp: ts(200)},
+		{MsgHash: "withdraw2", Height: 5, Sender: "sender1", Target: "target1", Amount: "5", Layer2Hash: "l2hash2", MsgType: int(Layer2Msg), Timestamp: ts(400)},
+	}
+	assert.NoError(t, crossMsgOrm.InsertL2CrossMsg(context.Background(), withdrawals))
+
+	l2SentMsgs := []*L2SentMsg{
+		{OriginalSender: "sender1", Sender: "gateway", TxHash: "l2hash1", MsgHash: "withdraw1", Height: 2, Nonce: 0, Value: "0"},
+		{OriginalSender: "sender1", Sender: "gateway", TxHash: "l2hash2", MsgHash: "withdraw2", Height: 5, Nonce: 1, Value: "0"},
+	}
+	assert.NoError(t, l2SentMsgOrm.InsertL2SentMsg(context.Background(), l2SentMsgs))
+
+	total, err := crossMsgOrm.GetTotalUnifiedMsgCountByAddress(context.Background(), "sender1", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4), total)
+
+	msgs, err := crossMsgOrm.GetUnifiedMsgsByAddressWithOffset(context.Background(), "sender1", nil, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 4)
+	expected := []string{"withdraw2", "deposit2", "withdraw1", "deposit1"}
+	for i, msg := range msgs {
+		assert.Equal(t, expected[i], msg.MsgHash)
+	}
+	assert.Equal(t, int(Layer2Msg), msgs[0].MsgType)
+	assert.Equal(t, "l2hash2", msgs[0].Layer2Hash)
+	assert.Equal(t, "5", msgs[0].Amount)
+	assert.Equal(t, int(Layer1Msg), msgs[1].MsgType)
+
+	// pagination works across the merged set
+	msgs, err = crossMsgOrm.GetUnifiedMsgsByAddressWithOffset(context.Background(), "sender1", nil, 1, 2)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, "deposit2", msgs[0].MsgHash)
+	assert.Equal(t, "withdraw1", msgs[1].MsgHash)
+}
+
+func TestGetUnifiedMsgsByAddressSingleLayer(t *testing.T) {
+	db := setupTestDB(t)
+	crossMsgOrm := NewCrossMsg(db)
+	l2SentMsgOrm := NewL2SentMsg(db)
+
+	ts := func(sec int64) *time.Time {
+		tm := time.Unix(sec, 0).UTC()
+		return &tm
+	}
+
+	deposits := []*CrossMsg{
+		{MsgHash: "deposit1", Height: 1, Sender: "depositor", Amount: "1", Layer1Hash: "l1hash1", MsgType: int(Layer1Msg), Timestamp: ts(100)},
+		{MsgHash: "deposit2", Height: 2, Sender: "depositor", Amount: "2", Layer1Hash: "l1hash2", MsgType: int(Layer1Msg), Timestamp: ts(200)},
+	}
+	assert.NoError(t, crossMsgOrm.InsertL1CrossMsg(context.Background(), deposits))
+
+	withdrawals := []*CrossMsg{
+		{MsgHash: "withdraw1", Height: 3, Sender: "withdrawer", Amount: "3", Layer2Hash: "l2hash1", MsgType: int(Layer2Msg), Timestamp: ts(300)},
+	}
+	assert.NoError(t, crossMsgOrm.InsertL2CrossMsg(context.Background(), withdrawals))
+	l2SentMsgs := []*L2SentMsg{
+		{OriginalSender: "withdrawer", Sender: "gateway", TxHash: "l2hash1", MsgHash: "withdraw1", Height: 3, Nonce: 0, Value: "0"},
+		// sent by calling the messenger directly, no cross message
+		{Sender: "withdrawer", TxHash: "l2hash2", MsgHash: "withdraw2", Height: 4, Nonce: 1, Value: "4"},
+	}
+	assert.NoError(t, l2SentMsgOrm.InsertL2SentMsg(context.Background(), l2SentMsgs))
+
+	// only layer1 activity
+	total, err := crossMsgOrm.GetTotalUnifiedMsgCountByAddress(context.Background(), "depositor", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), total)
+	msgs, err := crossMsgOrm.GetUnifiedMsgsByAddressWithOffset(context.Background(), "depositor", nil, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, "deposit2", msgs[0].MsgHash)
+	assert.Equal(t, "deposit1", msgs[1].MsgHash)
+
+	// only layer2 activity, the direct message has no block timestamp and comes first
+	total, err = crossMsgOrm.GetTotalUnifiedMsgCountByAddress(context.Background(), "withdrawer", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), total)
+	msgs, err = crossMsgOrm.GetUnifiedMsgsByAddressWithOffset(context.Background(), "withdrawer", nil, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, "withdraw2", msgs[0].MsgHash)
+	assert.Equal(t, "withdraw1", msgs[1].MsgHash)
+	assert.Equal(t, int(Layer2Msg), msgs[1].MsgType)
+}
+
+func TestGetUnifiedMsgsByAddressInRange(t *testing.T) {
+	db := setupTestDB(t)
+	crossMsgOrm := NewCrossMsg(db)
+
+	ts := func(sec int64) *time.Time {
+		tm := time.Unix(sec, 0).UTC()
+		return &tm
+	}
+	bound := func(value uint64) *uint64 {
+		return &value
+	}
+
+	deposits := []*CrossMsg{
+		{MsgHash: "deposit1", Height: 1, Sender: "depositor", Amount: "1", Layer1Hash: "l1hash1", MsgType: int(Layer1Msg), Timestamp: ts(100)},
+		{MsgHash: "deposit2", Height: 2, Sender: "depositor", Amount: "2", Layer1Hash: "l1hash2", MsgType: int(Layer1Msg), Timestamp: ts(200)},
+		{MsgHash: "deposit3", Height: 3, Sender: "depositor", Amount: "3", Layer1Hash: "l1hash3", MsgType: int(Layer1Msg), Timestamp: ts(300)},
+	}
+	assert.NoError(t, crossMsgOrm.InsertL1CrossMsg(context.Background(), deposits))
+
+	// the range excludes everything
+	msgRange := &MsgRange{From: bound(4)}
+	total, err := crossMsgOrm.GetTotalUnifiedMsgCountByAddress(context.Background(), "depositor", msgRange)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), total)
+	msgs, err := crossMsgOrm.GetUnifiedMsgsByAddressWithOffset(context.Background(), "depositor", msgRange, 0, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, msgs)
+
+	// the bounds are inclusive
+	msgRange = &MsgRange{From: bound(2), To: bound(3)}
+	total, err = crossMsgOrm.GetTotalUnifiedMsgCountByAddress(context.Background(), "depositor", msgRange)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), total)
+	msgs, err = crossMsgOrm.GetUnifiedMsgsByAddressWithOffset(context.Background(), "depositor", msgRange, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, "deposit3", msgs[0].MsgHash)
+	assert.Equal(t, "deposit2", msgs[1].MsgHash)
+
+	msgRange = &MsgRange{To: bound(200), ByTimestamp: true}
+	msgs, err = crossMsgOrm.GetUnifiedMsgsByAddressWithOffset(context.Background(), "depositor", msgRange, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, "deposit2", msgs[0].MsgHash)
+	assert.Equal(t, "deposit1", msgs[1].MsgHash)
+
+	msgRange = &MsgRange{From: bound(301), To: bound(400), ByTimestamp: true}
+	total, err = crossMsgOrm.GetTotalUnifiedMsgCountByAddress(context.Background(), "depositor", msgRange)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), total)
+}
+
+func TestGetCrossMsgsByHashesSortOrder(t *testing.T) {
+	db := setupTestDB(t)
+	crossMsgOrm := NewCrossMsg(db)
+
+	ts := func(sec int64) *time.Time {
+		tm := time.Unix(sec, 0).UTC()
+		return &tm
+	}
+
+	// inserted out of order, deposit2 and deposit4 are in the same block and the pending deposit3 has no timestamp yet
+	deposits := []*CrossMsg{
+		{MsgHash: "deposit2", Height: 2, Sender: "depositor", Amount: "2", Layer1Hash: "l1hash2", MsgType: int(Layer1Msg), Timestamp: ts(200)},
+		{MsgHash: "deposit1", Height: 1, Sender: "depositor", Amount: "1", Layer1Hash: "l1hash1", MsgType: int(Layer1Msg), Timestamp: ts(100)},
+		{MsgHash: "deposit3", Height: 3, Sender: "depositor", Amount: "3", Layer1Hash: "l1hash3", MsgType: int(Layer1Msg)},
+		{MsgHash: "deposit4", Height: 2, Sender: "depositor", Amount: "4", Layer1Hash: "l1hash4", MsgType: int(Layer1Msg), Timestamp: ts(200)},
+	}
+	assert.NoError(t, crossMsgOrm.InsertL1CrossMsg(context.Background(), deposits))
+	hashes := []string{"l1hash1", "l1hash2", "l1hash3", "l1hash4"}
+
+	msgHashes := func(msgs []*CrossMsg) []string {
+		var result []string
+		for _, msg := range msgs {
+			result = append(result, msg.MsgHash)
+		}
+		return result
+	}
+
+	msgs, err := crossMsgOrm.GetCrossMsgsByHashes(context.Background(), hashes, nil, SortDesc)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"deposit3", "deposit4", "deposit2", "deposit1"}, msgHashes(msgs))
+
+	msgs, err = crossMsgOrm.GetCrossMsgsByHashes(context.Background(), hashes, nil, SortAsc)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"deposit1", "deposit2", "deposit4", "deposit3"}, msgHashes(msgs))
+}
+
+func TestGetUnifiedMsgsByAddressWithKeyset(t *testing.T) {
+	db := setupTestDB(t)
+	crossMsgOrm := NewCrossMsg(db)
+	l2SentMsgOrm := NewL2SentMsg(db)
+
+	deposits := []*CrossMsg{
+		{MsgHash: "deposit1", Height: 1, Sender: "user", Amount: "1", Layer1Hash: "l1hash1", MsgType: int(Layer1Msg)},
+		{MsgHash: "deposit2", Height: 3, Sender: "user", Amount: "2", Layer1Hash: "l1hash2", MsgType: int(Layer1Msg)},
+	}
+	assert.NoError(t, crossMsgOrm.InsertL1CrossMsg(context.Background(), deposits))
+	// the withdrawals are sent in the same block as deposit2, the tx hash breaks the tie
+	l2SentMsgs := []*L2SentMsg{
+		{OriginalSender: "user", MsgHash: "withdrawal1", TxHash: "l2hash1", Height: 3, Nonce: 0},
+		{OriginalSender: "user", MsgHash: "withdrawal2", TxHash: "l2hash2", Height: 2, Nonce: 1},
+	}
+	assert.NoError(t, l2SentMsgOrm.InsertL2SentMsg(context.Background(), l2SentMsgs))
+
+	var seen []string
+	page, err := crossMsgOrm.GetUnifiedMsgsByAddressWithKeyset(context.Background(), "user", nil, 3)
+	assert.NoError(t, err)
+	assert.Len(t, page, 3)
+	for _, msg := range page {
+		seen = append(seen, msg.MsgHash)
+	}
+
+	// a new deposit is sent before the next page is fetched
+	assert.NoError(t, crossMsgOrm.InsertL1CrossMsg(context.Background(), []*CrossMsg{
+		{MsgHash: "deposit3", Height: 4, Sender: "user", Amount: "3", Layer1Hash: "l1hash3", MsgType: int(Layer1Msg)},
+	}))
+
+	last := page[len(page)-1]
+	page, err = crossMsgOrm.GetUnifiedMsgsByAddressWithKeyset(context.Background(), "user", &MsgCursor{Height: last.Height, TxHash: last.Layer2Hash, MsgHash: last.MsgHash}, 3)
+	assert.NoError(t, err)
+	for _, msg := range page {
+		seen = append(seen, msg.MsgHash)
+	}
+
+	assert.Equal(t, []string{"withdrawal1", "deposit2", "withdrawal2", "deposit1"}, seen)
+}
+
+func TestGetRelayedMsgsByRelayerWithOffset(t *testing.T) {
+	db := setupTestDB(t)
+	crossMsgOrm := NewCrossMsg(db)
+	l2SentMsgOrm := NewL2SentMsg(db)
+	relayedMsgOrm := NewRelayedMsg(db)
+
+	l2SentMsgs := []*L2SentMsg{
+		{OriginalSender: "sender1", Sender: "gateway", TxHash: "l2hash1", MsgHash: "withdraw1", Height: 1, Nonce: 0, Value: "0"},
+		{OriginalSender: "sender2", Sender: "gateway", TxHash: "l2hash2", MsgHash: "withdraw2", Height: 2, Nonce: 1, Value: "0"},
+		{OriginalSender: "sender1", Sender: "gateway", TxHash: "l2hash3", MsgHash: "withdraw3", Height: 3, Nonce: 2, Value: "0"},
+		{OriginalSender: "sender1", Sender: "gateway", TxHash: "l2hash4", MsgHash: "withdraw4", Height: 4, Nonce: 3, Value: "0"},
+	}
+	assert.NoError(t, l2SentMsgOrm.InsertL2SentMsg(context.Background(), l2SentMsgs))
+
+	relayedMsgs := []*RelayedMsg{
+		{MsgHash: "withdraw1", Height: 10, Layer1Hash: "l1hash1", Relayer: "relayer1"},
+		{MsgHash: "withdraw2", Height: 11, Layer1Hash: "l1hash2", Relayer: "relayer1"},
+		{MsgHash: "withdraw3", Height: 12, Layer1Hash: "l1hash3", Relayer: "relayer2"},
+		// withdraw4 is not claimed yet
+	}
+	assert.NoError(t, relayedMsgOrm.InsertRelayedMsg(context.Background(), relayedMsgs))
+
+	total, err := crossMsgOrm.GetTotalRelayedMsgCountByRelayer(context.Background(), "relayer1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), total)
+
+	msgs, err := crossMsgOrm.GetRelayedMsgsByRelayerWithOffset(context.Background(), "relayer1", 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, "withdraw2", msgs[0].MsgHash)
+	assert.Equal(t, "sender2", msgs[0].Sender)
+	assert.Equal(t, "withdraw1", msgs[1].MsgHash)
+	assert.Equal(t, "l2hash1", msgs[1].Layer2Hash)
+
+	msgs, err = crossMsgOrm.GetRelayedMsgsByRelayerWithOffset(context.Background(), "relayer2", 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, "withdraw3", msgs[0].MsgHash)
+}
+
+func TestGetMsgsBetweenWithOffset(t *testing.T) {
+	db := setupTestDB(t)
+	crossMsgOrm := NewCrossMsg(db)
+	l2SentMsgOrm := NewL2SentMsg(db)
+
+	ts := func(sec int64) *time.Time {
+		tm := time.Unix(sec, 0).UTC()
+		return &tm
+	}
+
+	deposits := []*CrossMsg{
+		{MsgHash: "deposit1", Height: 1, Sender: "sender1", Target: "target1", Amount: "1", Layer1Hash: "l1hash1", MsgType: int(Layer1Msg), Timestamp: ts(100)},
+		{MsgHash: "deposit2", Height: 2, Sender: "sender1", Target: "target2", Amount: "2", Layer1Hash: "l1hash2", MsgType: int(Layer1Msg), Timestamp: ts(200)},
+		{MsgHash: "deposit3", Height: 3, Sender: "sender2", Target: "target1", Amount: "3", Layer1Hash: "l1hash3", MsgType: int(Layer1Msg), Timestamp: ts(300)},
+	}
+	assert.NoError(t, crossMsgOrm.InsertL1CrossMsg(context.Background(), deposits))
+
+	withdrawals := []*CrossMsg{
+		{MsgHash: "withdraw1", Height: 4, Sender: "sender1", Target: "target1", Amount: "4", Layer2Hash: "l2hash1", MsgType: int(Layer2Msg), Timestamp: ts(400)},
+		{MsgHash: "withdraw2", Height: 5, Sender: "sender1", Target: "target2", Amount: "5", Layer2Hash: "l2hash2", MsgType: int(Layer2Msg), Timestamp: ts(500)},
+	}
+	assert.NoError(t, crossMsgOrm.InsertL2CrossMsg(context.Background(), withdrawals))
+
+	l2SentMsgs := []*L2SentMsg{
+		{OriginalSender: "sender1", Sender: "gateway", Target: "gateway", TxHash: "l2hash1", MsgHash: "withdraw1", Height: 4, Nonce: 0, Value: "0"},
+		{OriginalSender: "sender1", Sender: "gateway", Target: "gateway", TxHash: "l2hash2", MsgHash: "withdraw2", Height: 5, Nonce: 1, Value: "0"},
+		// sent by calling the messenger directly, the target is taken from the message
+		{Sender: "sender1", Target: "target1", TxHash: "l2hash3", MsgHash: "withdraw3", Height: 6, Nonce: 2, Value: "6"},
+	}
+	assert.NoError(t, l2SentMsgOrm.InsertL2SentMsg(context.Background(), l2SentMsgs))
+
+	total, err := crossMsgOrm.GetTotalMsgCountBetween(context.Background(), "sender1", "target1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), total)
+
+	msgs, err := crossMsgOrm.GetMsgsBetweenWithOffset(context.Background(), "sender1", "target1", 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 3)
+	// withdraw3 has no block timestamp and comes first
+	expected := []string{"withdraw3", "withdraw1", "deposit1"}
+	for i, msg := range msgs {
+		assert.Equal(t, expected[i], msg.MsgHash)
+		assert.Equal(t, "sender1", msg.Sender)
+		assert.Equal(t, "target1", msg.Target)
+	}
+
+	// the reversed pair does not match
+	total, err = crossMsgOrm.GetTotalMsgCountBetween(context.Background(), "target1", "sender1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), total)
+}
+
+func TestGetMsgsByFilterWithOffset(t *testing.T) {
+	db := setupTestDB(t)
+	crossMsgOrm := NewCrossMsg(db)
+	l2SentMsgOrm := NewL2SentMsg(db)
+
+	ts := func(sec int64) *time.Time {
+		tm := time.Unix(sec, 0).UTC()
+		return &tm
+	}
+
+	deposits := []*CrossMsg{
+		{MsgHash: "deposit1", Height: 1, Sender: "sender1", Amount: "1", Layer1Hash: "l1hash1", Layer1Token: "token1", MsgType: int(Layer1Msg), Timestamp: ts(100)},
+		{MsgHash: "deposit2", Height: 2, Sender: "sender1", Amount: "2", Layer1Hash: "l1hash2", MsgType: int(Layer1Msg), Timestamp: ts(200)},
+		{MsgHash: "deposit3", Height: 3, Sender: "sender2", Amount: "3", Layer1Hash: "l1hash3", MsgType: int(Layer1Msg), Timestamp: ts(300)},
+	}
+	assert.NoError(t, crossMsgOrm.InsertL1CrossMsg(context.Background(), deposits))
+
+	withdrawals := []*CrossMsg{
+		{MsgHash: "withdraw1", Height: 4, Sender: "sender1", Amount: "4", Layer2Hash: "l2hash1", Layer2Token: "token1", MsgType: int(Layer2Msg), Timestamp: ts(400)},
+		{MsgHash: "withdraw2", Height: 5, Sender: "sender1", Amount: "5", Layer2Hash: "l2hash2", MsgType: int(Layer2Msg), Timestamp: ts(500)},
+	}
+	assert.NoError(t, crossMsgOrm.InsertL2CrossMsg(context.Background(), withdrawals))
+
+	l2SentMsgs := []*L2SentMsg{
+		{OriginalSender: "sender1", Sender: "gateway", TxHash: "l2hash1", MsgHash: "withdraw1", Height: 4, Nonce: 0, Value: "0", MsgProof: "proof1"},
+		{OriginalSender: "sender1", Sender: "gateway", TxHash: "l2hash2", MsgHash: "withdraw2", Height: 5, Nonce: 1, Value: "0", MsgProof: "proof2"},
+	}
+	assert.NoError(t, l2SentMsgOrm.InsertL2SentMsg(context.Background(), l2SentMsgs))
+	assert.NoError(t, NewRelayedMsg(db).InsertRelayedMsg(context.Background(), []*RelayedMsg{
+		{MsgHash: "deposit1", Height: 10, Layer2Hash: "relay1"},
+		{MsgHash: "withdraw2", Height: 11, Layer1Hash: "relay2"},
+	}))
+	assert.NoError(t, NewFailedRelayedMsg(db).InsertFailedRelayedMsg(context.Background(), []*FailedRelayedMsg{
+		{MsgHash: "deposit2", Height: 12, Layer2Hash: "failed1"},
+	}))
+
+	msgHashes := func(filter *MsgFilter, order SortOrder) []string {
+		total, err := crossMsgOrm.GetTotalMsgCountByFilter(context.Background(), filter)
+		assert.NoError(t, err)
+		msgs, err := crossMsgOrm.GetMsgsByFilterWithOffset(context.Background(), filter, order, 0, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(len(msgs)), total)
+		var result []string
+		for _, msg := range msgs {
+			result = append(result, msg.MsgHash)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"withdraw2", "withdraw1", "deposit2", "deposit1"}, msgHashes(&MsgFilter{Addresses: []string{"sender1"}}, SortDesc))
+	assert.Equal(t, []string{"deposit1", "deposit2", "withdraw1", "withdraw2"}, msgHashes(&MsgFilter{Addresses: []string{"sender1"}}, SortAsc))
+	// the addresses and the hashes are combined with AND, the values of each with OR
+	assert.Equal(t, []string{"withdraw1", "deposit3"}, msgHashes(&MsgFilter{Addresses: []string{"sender1", "sender2"}, TxHashes: []string{"l1hash3", "l2hash1"}}, SortDesc))
+	assert.Equal(t, []string{"withdraw1", "deposit1"}, msgHashes(&MsgFilter{Tokens: []string{"token1"}}, SortDesc))
+	assert.Equal(t, []string{"withdraw2", "withdraw1"}, msgHashes(&MsgFilter{Addresses: []string{"sender1"}, MsgType: Layer2Msg}, SortDesc))
+	from, to := uint64(200), uint64(400)
+	assert.Equal(t, []string{"withdraw1", "deposit3", "deposit2"}, msgHashes(&MsgFilter{Range: &MsgRange{From: &from, To: &to, ByTimestamp: true}}, SortDesc))
+
+	assert.Equal(t, []string{"withdraw2", "deposit1"}, msgHashes(&MsgFilter{Statuses: []MsgStatus{MsgStatusRelayed, MsgStatusClaimed}}, SortDesc))
+	assert.Equal(t, []string{"deposit2"}, msgHashes(&MsgFilter{Statuses: []MsgStatus{MsgStatusFailed}}, SortDesc))
+	assert.Equal(t, []string{"withdraw1"}, msgHashes(&MsgFilter{Statuses: []MsgStatus{MsgStatusClaimable}}, SortDesc))
+	assert.Equal(t, []string{"deposit3"}, msgHashes(&MsgFilter{Statuses: []MsgStatus{MsgStatusPending}}, SortDesc))
+	// the deposits are never claimable
+	assert.Empty(t, msgHashes(&MsgFilter{MsgType: Layer1Msg, Statuses: []MsgStatus{MsgStatusClaimable}}, SortDesc))
+
+	// the dropped deposits are no longer pending
+	assert.NoError(t, NewDroppedMsg(db).InsertDroppedMsg(context.Background(), []*DroppedMsg{
+		{MsgHash: "deposit3", QueueIndex: 3, Height: 13, Layer1Hash: "drop1"},
+	}))
+	assert.Equal(t, []string{"deposit3"}, msgHashes(&MsgFilter{Statuses: []MsgStatus{MsgStatusDropped}}, SortDesc))
+	assert.Empty(t, msgHashes(&MsgFilter{Statuses: []MsgStatus{MsgStatusPending}}, SortDesc))
+
+	// the refunded deposits are no longer dropped
+	assert.NoError(t, NewRefundedMsg(db).InsertRefundedMsg(context.Background(), []*RefundedMsg{
+		{MsgHash: "deposit3", Height: 13, Layer1Hash: "drop1", Recipient: "sender2", Amount: "100"},
+	}))
+	assert.Equal(t, []string{"deposit3"}, msgHashes(&MsgFilter{Statuses: []MsgStatus{MsgStatusRefunded}}, SortDesc))
+	assert.Empty(t, msgHashes(&MsgFilter{Statuses: []MsgStatus{MsgStatusDropped}}, SortDesc))
+}
+
+func TestGetMsgsInRangeWithOffset(t *testing.T) {
+	db := setupTestDB(t)
+	crossMsgOrm := NewCrossMsg(db)
+	l2SentMsgOrm := NewL2SentMsg(db)
+
+	ts := func(sec int64) *time.Time {
+		tm := time.Unix(sec, 0).UTC()
+		return &tm
+	}
+
+	deposits := []*CrossMsg{
+		{MsgHash: "deposit1", Height: 1, Sender: "sender1", Target: "target1", Amount: "1", Layer1Hash: "l1hash1", MsgType: int(Layer1Msg), Timestamp: ts(100)},
+		{MsgHash: "deposit2", Height: 2, Sender: "sender2", Target: "target2", Amount: "2", Layer1Hash: "l1hash2", MsgType: int(Layer1Msg), Timestamp: ts(300)},
+	}
+	assert.NoError(t, crossMsgOrm.InsertL1CrossMsg(context.Background(), deposits))
+
+	withdrawals := []*CrossMsg{
+		{MsgHash: "withdraw1", Height: 3, Sender: "sender1", Target: "target1", Amount: "3", Layer2Hash: "l2hash1", MsgType: int(Layer2Msg), Timestamp: ts(200)},
+	}
+	assert.NoError(t, crossMsgOrm.InsertL2CrossMsg(context.Background(), withdrawals))
+
+	l2SentMsgs := []*L2SentMsg{
+		{OriginalSender: "sender1", Sender: "gateway", TxHash: "l2hash1", MsgHash: "withdraw1", Height: 3, Nonce: 0, Value: "0"},
+		// the layer2 cross message isn't indexed, so the block timestamp is unknown
+		{Sender: "sender3", Target: "target3", TxHash: "l2hash2", MsgHash: "withdraw2", Height: 4, Nonce: 1, Value: "4"},
+	}
+	assert.NoError(t, l2SentMsgOrm.InsertL2SentMsg(context.Background(), l2SentMsgs))
+
+	from, to := uint64(100), uint64(300)
+	msgRange := &MsgRange{From: &from, To: &to, ByTimestamp: true}
+	total, err := crossMsgOrm.GetTotalMsgCountInRange(context.Background(), UnknownMsg, msgRange)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), total)
+
+	msgs, err := crossMsgOrm.GetMsgsInRangeWithOffset(context.Background(), UnknownMsg, msgRange, SortAsc, 0, 10)
+	assert.NoError(t, err)
+	expected := []string{"deposit1", "withdraw1", "deposit2"}
+	assert.Len(t, msgs, len(expected))
+	for i, msg := range msgs {
+		assert.Equal(t, expected[i], msg.MsgHash)
+	}
+
+	msgs, err = crossMsgOrm.GetMsgsInRangeWithOffset(context.Background(), Layer1Msg, msgRange, SortDesc, 0, 1)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, "deposit2", msgs[0].MsgHash)
+
+	// the bounds are inclusive
+	from, to = 200, 200
+	total, err = crossMsgOrm.GetTotalMsgCountInRange(context.Background(), Layer2Msg, msgRange)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), total)
+}
+
+func TestGetMsgsInRangeExcludingSenders(t *testing.T) {
+	db := setupTestDB(t)
+	l2SentMsgOrm := NewL2SentMsg(db)
+
+	ts := func(sec int64) *time.Time {
+		tm := time.Unix(sec, 0).UTC()
+		return &tm
+	}
+
+	// the faucet deposits sit between the deposits of the users, in the middle of the pages
+	deposits := []*CrossMsg{
+		{MsgHash: "deposit1", Height: 1, Sender: "sender1", Target: "target1", Amount: "1", Layer1Hash: "l1hash1", MsgType: int(Layer1Msg), Timestamp: ts(100)},
+		{MsgHash: "faucet1", Height: 2, Sender: "faucet", Target: "target2", Amount: "2", Layer1Hash: "l1hash2", MsgType: int(Layer1Msg), Timestamp: ts(200)},
+		{MsgHash: "faucet2", Height: 3, Sender: "faucet", Target: "target3", Amount: "3", Layer1Hash: "l1hash3", MsgType: int(Layer1Msg), Timestamp: ts(300)},
+		{MsgHash: "deposit2", Height: 4, Sender: "sender2", Target: "target4", Amount: "4", Layer1Hash: "l1hash4", MsgType: int(Layer1Msg), Timestamp: ts(400)},
+	}
+	assert.NoError(t, NewCrossMsg(db).InsertL1CrossMsg(context.Background(), deposits))
+
+	// the withdrawals of the faucet are kept
+	withdrawals := []*CrossMsg{
+		{MsgHash: "withdraw1", Height: 5, Sender: "faucet", Target: "target5", Amount: "5", Layer2Hash: "l2hash1", MsgType: int(Layer2Msg), Timestamp: ts(500)},
+	}
+	assert.NoError(t, NewCrossMsg(db).InsertL2CrossMsg(context.Background(), withdrawals))
+	assert.NoError(t, l2SentMsgOrm.InsertL2SentMsg(context.Background(), []*L2SentMsg{
+		{Sender: "faucet", Target: "target5", TxHash: "l2hash1", MsgHash: "withdraw1", Height: 5, Nonce: 0, Value: "5"},
+	}))
+
+	crossMsgOrm := NewCrossMsgExcludingSenders(db, []string{"faucet"})
+	from, to := uint64(100), uint64(500)
+	msgRange := &MsgRange{From: &from, To: &to, ByTimestamp: true}
+	total, err := crossMsgOrm.GetTotalMsgCountInRange(context.Background(), UnknownMsg, msgRange)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), total)
+
+	// the first page holds the deposits on both sides of the faucet deposits, the second page the rest
+	msgs, err := crossMsgOrm.GetMsgsInRangeWithOffset(context.Background(), UnknownMsg, msgRange, SortAsc, 0, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, msgs, 2) {
+		assert.Equal(t, "deposit1", msgs[0].MsgHash)
+		assert.Equal(t, "deposit2", msgs[1].MsgHash)
+	}
+	msgs, err = crossMsgOrm.GetMsgsInRangeWithOffset(context.Background(), UnknownMsg, msgRange, SortAsc, 2, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, msgs, 1) {
+		assert.Equal(t, "withdraw1", msgs[0].MsgHash)
+	}
+
+	msgs, err = crossMsgOrm.GetCrossMsgsByHashes(context.Background(), []string{"l1hash2", "l1hash4", "l2hash1"}, nil, SortAsc)
+	assert.NoError(t, err)
+	if assert.Len(t, msgs, 2) {
+		assert.Equal(t, "deposit2", msgs[0].MsgHash)
+		assert.Equal(t, "withdraw1", msgs[1].MsgHash)
+	}
+
+	// nothing is excluded by the default orm
+	total, err = NewCrossMsg(db).GetTotalMsgCountInRange(context.Background(), UnknownMsg, msgRange)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), total)
+}
+
+func TestGetWithdrawalsInBlocksWithOffset(t *testing.T) {
+	db := setupTestDB(t)
+	crossMsgOrm := NewCrossMsg(db)
+	l2SentMsgOrm := NewL2SentMsg(db)
+	rollupBatchOrm := NewRollupBatch(db)
+
+	l2SentMsgs := []*L2SentMsg{
+		{OriginalSender: "sender1", Sender: "gateway", TxHash: "l2hash1", MsgHash: "withdraw1", Height: 10, Nonce: 0, Value: "0"},
+		{OriginalSender: "sender2", Sender: "gateway", TxHash: "l2hash2", MsgHash: "withdraw2", Height: 15, Nonce: 1, Value: "0"},
+		{OriginalSender: "sender1", Sender: "gateway", TxHash: "l2hash3", MsgHash: "withdraw3", Height: 21, Nonce: 2, Value: "0"},
+	}
+	assert.NoError(t, l2SentMsgOrm.InsertL2SentMsg(context.Background(), l2SentMsgs))
+
+	total, err := crossMsgOrm.GetTotalWithdrawalCountInBlocks(context.Background(), 10, 20)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), total)
+
+	msgs, err := crossMsgOrm.GetWithdrawalsInBlocksWithOffset(context.Background(), 10, 20, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, "withdraw2", msgs[0].MsgHash)
+
+	batches := []*RollupBatch{
+		{BatchIndex: 1, BatchHash: "batch1", CommitHeight: 1, CommitTxHash: "commit1", StartBlockNumber: 1, EndBlockNumber: 20},
+		{BatchIndex: 2, BatchHash: "batch2", CommitHeight: 2, CommitTxHash: "commit2", StartBlockNumber: 21, EndBlockNumber: 30},
+	}
+	assert.NoError(t, rollupBatchOrm.InsertRollupBatch(context.Background(), batches))
+	assert.NoError(t, rollupBatchOrm.UpdateRollupBatchFinalization(context.Background(), 1, 5, "finalize1", "stateroot1"))
+
+	count, err := rollupBatchOrm.GetTotalRollupBatchCount(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2), count)
+
+	results, err := rollupBatchOrm.GetRollupBatchesWithMsgCounts(context.Background(), 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, uint64(2), results[0].BatchIndex)
+	assert.Equal(t, uint64(1), results[0].MsgCount)
+	assert.Equal(t, uint64(1), results[1].BatchIndex)
+	assert.Equal(t, uint64(2), results[1].MsgCount)
+	assert.Equal(t, "finalize1", results[1].FinalizeTxHash)
+}
@@ -0,0 +1,117 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DroppedMsg is the struct for dropped_msg table, a deposit dropped from the L1MessageQueue whose value is refunded on
+// layer1
+type DroppedMsg struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID         uint64         `json:"id" gorm:"column:id"`
+	MsgHash    string         `json:"msg_hash" gorm:"column:msg_hash"`
+	QueueIndex uint64         `json:"queue_index" gorm:"column:queue_index"`
+	Height     uint64         `json:"height" gorm:"column:height"`
+	Layer1Hash string         `json:"layer1_hash" gorm:"column:layer1_hash"`
+	CreatedAt  *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt  *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewDroppedMsg create an DroppedMsg instance
+func NewDroppedMsg(db *gorm.DB) *DroppedMsg {
+	return &DroppedMsg{db: db}
+}
+
+// TableName returns the table name for the DroppedMsg model.
+func (*DroppedMsg) TableName() string {
+	return "dropped_msg"
+}
+
+// GetDroppedMsgsByHashes get the drops of the deposits of the msg hashes
+func (d *DroppedMsg) GetDroppedMsgsByHashes(ctx context.Context, msgHashes []string) ([]*DroppedMsg, error) {
+	var results []*DroppedMsg
+	err := d.db.WithContext(ctx).Model(&DroppedMsg{}).
+		Where("msg_hash IN (?)", msgHashes).
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("DroppedMsg.GetDroppedMsgsByHashes error: %w", err)
+	}
+	return results, nil
+}
+
+// GetDroppedMsgsByLayer1Hashes get the drops of the dropMessage txs
+func (d *DroppedMsg) GetDroppedMsgsByLayer1Hashes(ctx context.Context, layer1Hashes []string, dbTx ...*gorm.DB) ([]*DroppedMsg, error) {
+	db := d.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	var results []*DroppedMsg
+	err := db.WithContext(ctx).Model(&DroppedMsg{}).
+		Where("layer1_hash IN (?)", layer1Hashes).
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("DroppedMsg.GetDroppedMsgsByLayer1Hashes error: %w", err)
+	}
+	return results, nil
+}
+
+// GetLatestDroppedHeight get the height of the latest drop
+func (d *DroppedMsg) GetLatestDroppedHeight(ctx context.Context) (uint64, error) {
+	var result DroppedMsg
+	err := d.db.WithContext(ctx).Model(&DroppedMsg{}).
+		Select("height").
+		Order("height DESC").
+		First(&result).
+		Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("DroppedMsg.GetLatestDroppedHeight error: %w", err)
+	}
+	return result.Height, nil
+}
+
+// InsertDroppedMsg batch insert dropped msg into db, the drops of the msg hashes saved already are skipped
+func (d *DroppedMsg) InsertDroppedMsg(ctx context.Context, messages []*DroppedMsg, dbTx ...*gorm.DB) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	db := d.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Model(&DroppedMsg{}).Clauses(clause.OnConflict{DoNothing: true}).Create(&messages).Error
+	if err != nil {
+		msgHashes := make([]string, 0, len(messages))
+		for _, msg := range messages {
+			msgHashes = append(msgHashes, msg.MsgHash)
+		}
+		log.Error("failed to insert dropped messages", "msg hashes", msgHashes, "err", err)
+		return fmt.Errorf("DroppedMsg.InsertDroppedMsg error: %w", err)
+	}
+	return nil
+}
+
+// DeleteDroppedMsgAfterHeight delete the drops after height
+func (d *DroppedMsg) DeleteDroppedMsgAfterHeight(ctx context.Context, height uint64, dbTx ...*gorm.DB) error {
+	db := d.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Delete(&DroppedMsg{}, "height > ?", height).Error
+	if err != nil {
+		return fmt.Errorf("DroppedMsg.DeleteDroppedMsgAfterHeight error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EventCheckpoint is the struct for event_checkpoint table, the range of the blocks the events of a type of a contract
+// are indexed from without a gap
+type EventCheckpoint struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID          uint64         `json:"id" gorm:"column:id"`
+	Layer       int            `json:"layer" gorm:"column:layer"` // the layer of the contract, Layer1Msg or Layer2Msg
+	Contract    string         `json:"contract" gorm:"column:contract"`
+	EventType   string         `json:"event_type" gorm:"column:event_type"`
+	StartHeight uint64         `json:"start_height" gorm:"column:start_height"`
+	Height      uint64         `json:"height" gorm:"column:height"`
+	CreatedAt   *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt   *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewEventCheckpoint create an EventCheckpoint instance
+func NewEventCheckpoint(db *gorm.DB) *EventCheckpoint {
+	return &EventCheckpoint{db: db}
+}
+
+// TableName returns the table name for the EventCheckpoint model.
+func (*EventCheckpoint) TableName() string {
+	return "event_checkpoint"
+}
+
+// GetEventCheckpoints returns the checkpoints of both layers, ordered by layer, contract and event type
+func (e *EventCheckpoint) GetEventCheckpoints(ctx context.Context) ([]*EventCheckpoint, error) {
+	var results []*EventCheckpoint
+	err := e.db.WithContext(ctx).Model(&EventCheckpoint{}).
+		Order("layer ASC, contract ASC, event_type ASC").
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("EventCheckpoint.GetEventCheckpoints error: %w", err)
+	}
+	return results, nil
+}
+
+// GetLatestCheckpointHeight returns the highest checkpoint height of the event types on the layer, 0 if there is no
+// checkpoint
+func (e *EventCheckpoint) GetLatestCheckpointHeight(ctx context.Context, layer MsgType, eventTypes []string) (uint64, error) {
+	var result struct {
+		Height uint64
+	}
+	err := e.db.WithContext(ctx).Model(&EventCheckpoint{}).
+		Select("COALESCE(MAX(height), 0) AS height").
+		Where("layer = ? AND event_type IN ?", layer, eventTypes).
+		Scan(&result).
+		Error
+	if err != nil {
+		return 0, fmt.Errorf("EventCheckpoint.GetLatestCheckpointHeight error: %w", err)
+	}
+	return result.Height, nil
+}
+
+// ExtendEventCheckpoints merges the block range from the start height to the height of each checkpoint into the range
+// of its contract and event type. The ranges not adjacent to the range indexed already are kept out, so that the
+// range of a checkpoint never spans a block its events are not indexed from. The checkpoints not saved yet start at
+// their range.
+func (e *EventCheckpoint) ExtendEventCheckpoints(ctx context.Context, checkpoints []*EventCheckpoint, dbTx ...*gorm.DB) error {
+	if len(checkpoints) == 0 {
+		return nil
+	}
+	db := e.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	// the adjacency of both bounds is checked against the range before the update
+	adjacent := "excluded.start_height <= event_checkpoint.height + 1 AND excluded.height + 1 >= event_checkpoint.start_height"
+	err := db.WithContext(ctx).Model(&EventCheckpoint{}).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "layer"}, {Name: "contract"}, {Name: "event_type"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+			DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "start_height"}, Value: gorm.Expr("CASE WHEN " + adjacent + " THEN LEAST(event_checkpoint.start_height, excluded.start_height) ELSE event_checkpoint.start_height END")},
+				{Column: clause.Column{Name: "height"}, Value: gorm.Expr("CASE WHEN " + adjacent + " THEN GREATEST(event_checkpoint.height, excluded.height) ELSE event_checkpoint.height END")},
+			},
+		}).
+		Create(&checkpoints).
+		Error
+	if err != nil {
+		return fmt.Errorf("EventCheckpoint.ExtendEventCheckpoints error: %w", err)
+	}
+	return nil
+}
+
+// RollbackEventCheckpoints cuts the ranges of the checkpoints of the layer at the height, the checkpoints starting after
+// the height are soft deleted
+func (e *EventCheckpoint) RollbackEventCheckpoints(ctx context.Context, layer MsgType, height uint64, dbTx ...*gorm.DB) error {
+	db := e.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Delete(&EventCheckpoint{}, "layer = ? AND start_height > ?", layer, height).Error
+	if err != nil {
+		return fmt.Errorf("EventCheckpoint.RollbackEventCheckpoints error: %w", err)
+	}
+	err = db.WithContext(ctx).Model(&EventCheckpoint{}).
+		Where("layer = ? AND height > ?", layer, height).
+		Update("height", height).
+		Error
+	if err != nil {
+		return fmt.Errorf("EventCheckpoint.RollbackEventCheckpoints error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// eventOutboxLockKey is the key of the advisory lock taken by the transactions inserting into event_outbox
+const eventOutboxLockKey = 0x6576656e746f7574 // "eventout"
+
+// EventOutbox is the struct for event_outbox table, an indexed bridge event queued for the message broker
+type EventOutbox struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID        uint64     `json:"id" gorm:"column:id"`
+	Topic     string     `json:"topic" gorm:"column:topic"`
+	MsgKey    string     `json:"msg_key" gorm:"column:msg_key"`
+	Payload   string     `json:"payload" gorm:"column:payload"`
+	CreatedAt *time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+// NewEventOutbox create an EventOutbox instance
+func NewEventOutbox(db *gorm.DB) *EventOutbox {
+	return &EventOutbox{db: db}
+}
+
+// TableName returns the table name for the EventOutbox model.
+func (*EventOutbox) TableName() string {
+	return "event_outbox"
+}
+
+// InsertEventOutbox batch insert the events into db in order. The inserts take an advisory lock held until the
+// transaction ends, so that the transactions queueing events commit them in the order of their ids and the cursor
+// never skips an event committed late. dbTx must be the transaction saving the events.
+func (e *EventOutbox) InsertEventOutbox(ctx context.Context, events []*EventOutbox, dbTx ...*gorm.DB) error {
+	if len(events) == 0 {
+		return nil
+	}
+	db := e.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	db = db.WithContext(ctx)
+	if err := db.Exec("SELECT pg_advisory_xact_lock(?)", eventOutboxLockKey).Error; err != nil {
+		return fmt.Errorf("EventOutbox.InsertEventOutbox lock error: %w", err)
+	}
+	if err := db.Model(&EventOutbox{}).Create(&events).Error; err != nil {
+		return fmt.Errorf("EventOutbox.InsertEventOutbox error: %w", err)
+	}
+	return nil
+}
+
+// GetEventOutboxAfter get at most limit events queued after the id, in the order of their ids
+func (e *EventOutbox) GetEventOutboxAfter(ctx context.Context, afterID uint64, limit int) ([]*EventOutbox, error) {
+	var results []*EventOutbox
+	err := e.db.WithContext(ctx).Model(&EventOutbox{}).
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("EventOutbox.GetEventOutboxAfter error: %w", err)
+	}
+	return results, nil
+}
+
+// GetEventOutboxCursor get the id of the last event accepted by the message broker, 0 if none is
+func (e *EventOutbox) GetEventOutboxCursor(ctx context.Context) (uint64, error) {
+	var result struct {
+		LastID uint64
+	}
+	err := e.db.WithContext(ctx).Table("event_outbox_cursor").
+		Select("last_id").
+		Where("id = 1").
+		Scan(&result).
+		Error
+	if err != nil {
+		return 0, fmt.Errorf("EventOutbox.GetEventOutboxCursor error: %w", err)
+	}
+	return result.LastID, nil
+}
+
+// UpdateEventOutboxCursor moves the cursor to the id of the last event accepted by the message broker, the cursor
+// never moves back
+func (e *EventOutbox) UpdateEventOutboxCursor(ctx context.Context, lastID uint64) error {
+	err := e.db.WithContext(ctx).Table("event_outbox_cursor").
+		Where("id = 1 AND last_id < ?", lastID).
+		Update("last_id", lastID).
+		Error
+	if err != nil {
+		return fmt.Errorf("EventOutbox.UpdateEventOutboxCursor error: %w", err)
+	}
+	return nil
+}
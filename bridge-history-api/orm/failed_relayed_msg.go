@@ -0,0 +1,134 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FailedRelayedMsg is the struct for failed_relayed_msg table
+type FailedRelayedMsg struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID         uint64         `json:"id" gorm:"column:id"`
+	MsgHash    string         `json:"msg_hash" gorm:"column:msg_hash"`
+	Height     uint64         `json:"height" gorm:"column:height"`
+	Layer1Hash string         `json:"layer1_hash" gorm:"column:layer1_hash;default:''"`
+	Layer2Hash string         `json:"layer2_hash" gorm:"column:layer2_hash;default:''"`
+	CreatedAt  *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt  *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewFailedRelayedMsg create an NewFailedRelayedMsg instance
+func NewFailedRelayedMsg(db *gorm.DB) *FailedRelayedMsg {
+	return &FailedRelayedMsg{db: db}
+}
+
+// TableName returns the table name for the FailedRelayedMsg model.
+func (*FailedRelayedMsg) TableName() string {
+	return "failed_relayed_msg"
+}
+
+// GetFailedRelayedMsgsByHashes get failed relayed msgs by hash array
+func (f *FailedRelayedMsg) GetFailedRelayedMsgsByHashes(ctx context.Context, msgHashes []string) ([]*FailedRelayedMsg, error) {
+	var results []*FailedRelayedMsg
+	err := f.db.WithContext(ctx).Model(&FailedRelayedMsg{}).
+		Where("msg_hash IN (?)", msgHashes).
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("FailedRelayedMsg.GetFailedRelayedMsgsByHashes error: %w", err)
+	}
+	return results, nil
+}
+
+// GetLatestFailedRelayedHeightOnL1 get latest failed relayed height on l1
+func (f *FailedRelayedMsg) GetLatestFailedRelayedHeightOnL1(ctx context.Context) (uint64, error) {
+	var result FailedRelayedMsg
+	err := f.db.WithContext(ctx).Model(&FailedRelayedMsg{}).
+		Select("height").
+		Where("layer1_hash != ''").
+		Order("height DESC").
+		First(&result).
+		Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("FailedRelayedMsg.GetLatestFailedRelayedHeightOnL1 error: %w", err)
+	}
+	return result.Height, nil
+}
+
+// GetLatestFailedRelayedHeightOnL2 get latest failed relayed height on l2
+func (f *FailedRelayedMsg) GetLatestFailedRelayedHeightOnL2(ctx context.Context) (uint64, error) {
+	var result FailedRelayedMsg
+	err := f.db.WithContext(ctx).Model(&FailedRelayedMsg{}).
+		Select("height").
+		Where("layer2_hash != ''").
+		Order("height DESC").
+		First(&result).
+		Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("FailedRelayedMsg.GetLatestFailedRelayedHeightOnL2 error: %w", err)
+	}
+	return result.Height, nil
+}
+
+// InsertFailedRelayedMsg batch insert failed relayed msg into db, the failures saved already are skipped
+func (f *FailedRelayedMsg) InsertFailedRelayedMsg(ctx context.Context, messages []*FailedRelayedMsg, dbTx ...*gorm.DB) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	db := f.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	db.WithContext(ctx)
+	err := db.Model(&FailedRelayedMsg{}).Clauses(clause.OnConflict{DoNothing: true}).Create(&messages).Error
+	if err != nil {
+		msgHashes := make([]string, 0, len(messages))
+		heights := make([]uint64, 0, len(messages))
+		for _, msg := range messages {
+			msgHashes = append(msgHashes, msg.MsgHash)
+			heights = append(heights, msg.Height)
+		}
+		log.Error("failed to insert failed relayed messages", "msg hashes", msgHashes, "heights", heights, "err", err)
+		return fmt.Errorf("FailedRelayedMsg.InsertFailedRelayedMsg error: %w", err)
+	}
+	return nil
+}
+
+// DeleteL1FailedRelayedHashAfterHeight delete l1 failed relayed hash after height
+func (f *FailedRelayedMsg) DeleteL1FailedRelayedHashAfterHeight(ctx context.Context, height uint64, dbTx ...*gorm.DB) error {
+	db := f.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Delete(&FailedRelayedMsg{}, "height > ? AND layer1_hash != ''", height).Error
+	if err != nil {
+		return fmt.Errorf("FailedRelayedMsg.DeleteL1FailedRelayedHashAfterHeight error: %w", err)
+	}
+	return nil
+}
+
+// DeleteL2FailedRelayedHashAfterHeight delete l2 failed relayed hash after height
+func (f *FailedRelayedMsg) DeleteL2FailedRelayedHashAfterHeight(ctx context.Context, height uint64, dbTx ...*gorm.DB) error {
+	db := f.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Delete(&FailedRelayedMsg{}, "height > ? AND layer2_hash != ''", height).Error
+	if err != nil {
+		return fmt.Errorf("FailedRelayedMsg.DeleteL2FailedRelayedHashAfterHeight error: %w", err)
+	}
+	return nil
+}
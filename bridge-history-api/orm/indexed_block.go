@@ -0,0 +1,84 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IndexedBlock is the struct for indexed_block table, the hash of a block the events are indexed from
+type IndexedBlock struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID        uint64         `json:"id" gorm:"column:id"`
+	Layer     int            `json:"layer" gorm:"column:layer"` // the layer of the block, Layer1Msg or Layer2Msg
+	Height    uint64         `json:"height" gorm:"column:height"`
+	BlockHash string         `json:"block_hash" gorm:"column:block_hash"`
+	CreatedAt *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewIndexedBlock create an IndexedBlock instance
+func NewIndexedBlock(db *gorm.DB) *IndexedBlock {
+	return &IndexedBlock{db: db}
+}
+
+// TableName returns the table name for the IndexedBlock model.
+func (*IndexedBlock) TableName() string {
+	return "indexed_block"
+}
+
+// GetLatestIndexedBlocks returns at most limit indexed blocks of the layer, the highest first
+func (i *IndexedBlock) GetLatestIndexedBlocks(ctx context.Context, layer MsgType, limit int) ([]*IndexedBlock, error) {
+	var results []*IndexedBlock
+	err := i.db.WithContext(ctx).Model(&IndexedBlock{}).
+		Where("layer = ?", layer).
+		Order("height DESC").
+		Limit(limit).
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("IndexedBlock.GetLatestIndexedBlocks error: %w", err)
+	}
+	return results, nil
+}
+
+// InsertIndexedBlocks batch insert indexed blocks into db, the heights already indexed are kept as is
+func (i *IndexedBlock) InsertIndexedBlocks(ctx context.Context, blocks []*IndexedBlock, dbTx ...*gorm.DB) error {
+	if len(blocks) == 0 {
+		return nil
+	}
+	db := i.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Model(&IndexedBlock{}).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "layer"}, {Name: "height"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+			DoNothing:   true,
+		}).
+		Create(&blocks).
+		Error
+	if err != nil {
+		return fmt.Errorf("IndexedBlock.InsertIndexedBlocks error: %w", err)
+	}
+	return nil
+}
+
+// DeleteIndexedBlocksAfterHeight soft delete the indexed blocks of the layer after the height
+func (i *IndexedBlock) DeleteIndexedBlocksAfterHeight(ctx context.Context, layer MsgType, height uint64, dbTx ...*gorm.DB) error {
+	db := i.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Delete(&IndexedBlock{}, "layer = ? AND height > ?", layer, height).Error
+	if err != nil {
+		return fmt.Errorf("IndexedBlock.DeleteIndexedBlocksAfterHeight error: %w", err)
+	}
+	return nil
+}
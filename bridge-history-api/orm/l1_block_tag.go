@@ -0,0 +1,74 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// the block tags of the layer1 node polled by the fetcher
+const (
+	// L1BlockTagSafe the latest block justified by the beacon chain
+	L1BlockTagSafe = "safe"
+	// L1BlockTagFinalized the latest block finalized by the beacon chain
+	L1BlockTagFinalized = "finalized"
+)
+
+// L1BlockTag is the struct for l1_block_tag table, the latest layer1 block number of a block tag
+type L1BlockTag struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID        uint64         `json:"id" gorm:"column:id"`
+	Tag       string         `json:"tag" gorm:"column:tag"`
+	Number    uint64         `json:"number" gorm:"column:number"`
+	CreatedAt *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewL1BlockTag create a L1BlockTag instance
+func NewL1BlockTag(db *gorm.DB) *L1BlockTag {
+	return &L1BlockTag{db: db}
+}
+
+// TableName returns the table name for the L1BlockTag model.
+func (*L1BlockTag) TableName() string {
+	return "l1_block_tag"
+}
+
+// GetL1BlockTagNumbers get the latest block number of each block tag keyed by the tag, the tags never polled are absent
+func (l *L1BlockTag) GetL1BlockTagNumbers(ctx context.Context) (map[string]uint64, error) {
+	var results []*L1BlockTag
+	err := l.db.WithContext(ctx).Model(&L1BlockTag{}).Find(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("L1BlockTag.GetL1BlockTagNumbers error: %w", err)
+	}
+	numbers := make(map[string]uint64, len(results))
+	for _, result := range results {
+		numbers[result.Tag] = result.Number
+	}
+	return numbers, nil
+}
+
+// UpdateL1BlockTagNumber upsert the latest block number of the block tag
+func (l *L1BlockTag) UpdateL1BlockTagNumber(ctx context.Context, tag string, number uint64, dbTx ...*gorm.DB) error {
+	db := l.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Model(&L1BlockTag{}).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "tag"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+			DoUpdates:   clause.AssignmentColumns([]string{"number"}),
+		}).
+		Create(&L1BlockTag{Tag: tag, Number: number}).
+		Error
+	if err != nil {
+		return fmt.Errorf("L1BlockTag.UpdateL1BlockTagNumber error: %w", err)
+	}
+	return nil
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/big"
 	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // L2SentMsg defines the struct for l2_sent_msg table record
@@ -24,6 +26,7 @@ type L2SentMsg struct {
 	Value          string         `json:"value" gorm:"column:value"`
 	Height         uint64         `json:"height" gorm:"column:height"`
 	Nonce          uint64         `json:"nonce" gorm:"column:nonce"`
+	LogIndex       *uint64        `json:"log_index" gorm:"column:log_index"`
 	BatchIndex     uint64         `json:"batch_index" gorm:"column:batch_index;default:0"`
 	MsgProof       string         `json:"msg_proof" gorm:"column:msg_proof;default:''"`
 	MsgData        string         `json:"msg_data" gorm:"column:msg_data;default:''"`
@@ -86,16 +89,36 @@ func (l *L2SentMsg) GetLatestSentMsgHeightOnL2(ctx context.Context) (uint64, err
 	return result.Height, nil
 }
 
-// GetClaimableL2SentMsgByAddress returns both the total number of unclaimed messages and a paginated list of those messages.
+// claimableL2SentMsgOrderColumns the sort columns of the claimable messages, the height stands in for the block
+// timestamp which isn't indexed for the layer2 sent messages
+var claimableL2SentMsgOrderColumns = []string{"height", "tx_hash", "id"}
+
+// ClaimableFilter the options of GetClaimableL2SentMsgByAddress, the zero value selects all the unclaimed messages
+// latest first
+type ClaimableFilter struct {
+	// MinValue excludes the messages whose value is below it, nil doesn't filter by the value
+	MinValue *big.Int
+	// Assets selects the messages bridging one of the assets, empty doesn't filter by the asset
+	Assets []AssetType
+	// Order the direction of the sort by height then tx hash, the id breaks the remaining ties
+	Order SortOrder
+}
+
+// GetClaimableL2SentMsgByAddress returns both the total number of unclaimed messages and a paginated list of those messages,
+// selected and sorted by the filter.
 // TODO: Add metrics about the result set sizes (total/claimed/unclaimed messages).
-func (l *L2SentMsg) GetClaimableL2SentMsgByAddress(ctx context.Context, address string) ([]*L2SentMsg, error) {
+func (l *L2SentMsg) GetClaimableL2SentMsgByAddress(ctx context.Context, address string, filter ClaimableFilter) ([]*L2SentMsg, error) {
 	var totalMsgs []*L2SentMsg
 	db := l.db.WithContext(ctx)
 	db = db.Table("l2_sent_msg")
 	db = db.Where("original_sender = ? OR sender = ?", address, address)
 	db = db.Where("msg_proof != ''")
+	if filter.MinValue != nil {
+		db = db.Where("CAST(value AS NUMERIC) >= ?", filter.MinValue.String())
+	}
+	db = whereAssets(db, filter.Assets)
 	db = db.Where("deleted_at IS NULL")
-	db = db.Order("id DESC")
+	db = db.Order(filter.Order.orderBy(claimableL2SentMsgOrderColumns...))
 	tx := db.Find(&totalMsgs)
 	if tx.Error != nil || tx.RowsAffected == 0 {
 		return nil, tx.Error
@@ -143,6 +166,121 @@ func (l *L2SentMsg) GetClaimableL2SentMsgByAddress(ctx context.Context, address
 	return unclaimedL2Msgs, nil
 }
 
+// GetL2SentMsgsByAddress returns all the messages sent by the address, claimed or not
+func (l *L2SentMsg) GetL2SentMsgsByAddress(ctx context.Context, address string) ([]*L2SentMsg, error) {
+	var results []*L2SentMsg
+	err := l.db.WithContext(ctx).Model(&L2SentMsg{}).
+		Where("original_sender = ? OR sender = ?", address, address).
+		Order("nonce ASC").
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("L2SentMsg.GetL2SentMsgsByAddress error: %w", err)
+	}
+	return results, nil
+}
+
+// GetClaimableL2SentMsgByAddresses get the unclaimed messages sent by any of the addresses latest first, in one
+// query for the clients managing many addresses
+func (l *L2SentMsg) GetClaimableL2SentMsgByAddresses(ctx context.Context, addresses []string) ([]*L2SentMsg, error) {
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+	var results []*L2SentMsg
+	db := l.db.WithContext(ctx)
+	db = db.Table("l2_sent_msg")
+	db = db.Where("original_sender IN ? OR sender IN ?", addresses, addresses)
+	db = db.Where("msg_proof != ''")
+	db = db.Where("deleted_at IS NULL")
+	db = db.Where("NOT EXISTS (SELECT 1 FROM relayed_msg WHERE relayed_msg.msg_hash = l2_sent_msg.msg_hash AND relayed_msg.deleted_at IS NULL)")
+	db = db.Order(SortDesc.orderBy(claimableL2SentMsgOrderColumns...))
+	if err := db.Find(&results).Error; err != nil {
+		return nil, fmt.Errorf("L2SentMsg.GetClaimableL2SentMsgByAddresses error: %w", err)
+	}
+	return results, nil
+}
+
+// claimableL2SentMsgsByAddressQuery selects the unclaimed messages of the address with proofs,
+// the claimed ones are excluded in sql so that the pagination can be pushed down.
+func (l *L2SentMsg) claimableL2SentMsgsByAddressQuery(ctx context.Context, address string, assets []AssetType) *gorm.DB {
+	db := l.db.WithContext(ctx)
+	db = db.Table("l2_sent_msg")
+	db = db.Where("original_sender = ? OR sender = ?", address, address)
+	db = db.Where("msg_proof != ''")
+	db = db.Where("deleted_at IS NULL")
+	db = db.Where("NOT EXISTS (SELECT 1 FROM relayed_msg WHERE relayed_msg.msg_hash = l2_sent_msg.msg_hash AND relayed_msg.deleted_at IS NULL)")
+	return whereAssets(db, assets)
+}
+
+// whereAssets filters the sent messages by the assets of their cross messages, nothing is filtered if assets is empty.
+// The messages sent by calling the messenger directly have no cross message and bridge native ETH.
+func whereAssets(db *gorm.DB, assets []AssetType) *gorm.DB {
+	if len(assets) == 0 {
+		return db
+	}
+	query, args := assetCondition("c.", assets)
+	query = "EXISTS (SELECT 1 FROM cross_message c WHERE c.msg_hash = l2_sent_msg.msg_hash AND c.deleted_at IS NULL AND " + query + ")"
+	if includesETH(assets) {
+		query = "(" + query + " OR NOT EXISTS (SELECT 1 FROM cross_message c WHERE c.msg_hash = l2_sent_msg.msg_hash AND c.deleted_at IS NULL))"
+	}
+	return db.Where(query, args...)
+}
+
+// GetTotalClaimableL2SentMsgCountByAddress get the total count of the unclaimed messages of the address bridging
+// one of the assets, all of them if assets is empty
+func (l *L2SentMsg) GetTotalClaimableL2SentMsgCountByAddress(ctx context.Context, address string, assets []AssetType) (uint64, error) {
+	var count int64
+	if err := l.claimableL2SentMsgsByAddressQuery(ctx, address, assets).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("L2SentMsg.GetTotalClaimableL2SentMsgCountByAddress error: %w", err)
+	}
+	return uint64(count), nil
+}
+
+// GetClaimableL2SentMsgByAddressWithOffset returns a page of the unclaimed messages of the address latest first,
+// in the same order as GetClaimableL2SentMsgByAddress with SortDesc, only the messages bridging one of the assets are returned,
+// all of them if assets is empty
+func (l *L2SentMsg) GetClaimableL2SentMsgByAddressWithOffset(ctx context.Context, address string, assets []AssetType, offset int, limit int) ([]*L2SentMsg, error) {
+	var results []*L2SentMsg
+	db := l.claimableL2SentMsgsByAddressQuery(ctx, address, assets)
+	db = db.Order(SortDesc.orderBy(claimableL2SentMsgOrderColumns...))
+	db = db.Limit(limit)
+	db = db.Offset(offset)
+	if err := db.Find(&results).Error; err != nil {
+		return nil, fmt.Errorf("L2SentMsg.GetClaimableL2SentMsgByAddressWithOffset error: %w", err)
+	}
+	return results, nil
+}
+
+// GetClaimableL2SentMsgByAddressWithKeyset returns at most limit unclaimed messages of the address ordered by height,
+// tx hash and msg hash desc, starting right after the cursor, or from the latest message if cursor is nil.
+func (l *L2SentMsg) GetClaimableL2SentMsgByAddressWithKeyset(ctx context.Context, address string, cursor *MsgCursor, limit int) ([]*L2SentMsg, error) {
+	var results []*L2SentMsg
+	db := cursor.after(l.claimableL2SentMsgsByAddressQuery(ctx, address, nil))
+	db = db.Order(keysetOrder)
+	db = db.Limit(limit)
+	if err := db.Find(&results).Error; err != nil {
+		return nil, fmt.Errorf("L2SentMsg.GetClaimableL2SentMsgByAddressWithKeyset error: %w", err)
+	}
+	return results, nil
+}
+
+// GetClaimableL2SentMsgByAddressWithCursor returns at most limit unclaimed messages of the address ordered by nonce desc,
+// starting right after the message whose nonce is cursor, or from the latest message if cursor is nil.
+// Nonces are monotonic, so messages claimed between two page fetches do not shift the following pages.
+func (l *L2SentMsg) GetClaimableL2SentMsgByAddressWithCursor(ctx context.Context, address string, cursor *uint64, limit int) ([]*L2SentMsg, error) {
+	var results []*L2SentMsg
+	db := l.claimableL2SentMsgsByAddressQuery(ctx, address, nil)
+	if cursor != nil {
+		db = db.Where("nonce < ?", *cursor)
+	}
+	db = db.Order("nonce DESC")
+	db = db.Limit(limit)
+	if err := db.Find(&results).Error; err != nil {
+		return nil, fmt.Errorf("L2SentMsg.GetClaimableL2SentMsgByAddressWithCursor error: %w", err)
+	}
+	return results, nil
+}
+
 // GetLatestL2SentMsgBatchIndex get latest l2 sent msg batch index
 func (l *L2SentMsg) GetLatestL2SentMsgBatchIndex(ctx context.Context) (int64, error) {
 	var result L2SentMsg
@@ -162,10 +300,10 @@ func (l *L2SentMsg) GetLatestL2SentMsgBatchIndex(ctx context.Context) (int64, er
 	return int64(result.BatchIndex), nil
 }
 
-// GetL2SentMsgMsgHashByHeightRange get l2 sent msg msg hash by height range
+// GetL2SentMsgMsgHashByHeightRange get l2 sent msg msg hash by height range, the archived ones included
 func (l *L2SentMsg) GetL2SentMsgMsgHashByHeightRange(ctx context.Context, startHeight, endHeight uint64) ([]*L2SentMsg, error) {
 	var results []*L2SentMsg
-	err := l.db.WithContext(ctx).Model(&L2SentMsg{}).
+	err := l.db.WithContext(ctx).Model(&L2SentMsg{}).Table(L2SentMsgAllViewName).
 		Where("height >= ? AND height <= ?", startHeight, endHeight).
 		Order("nonce ASC").
 		Find(&results).
@@ -176,10 +314,26 @@ func (l *L2SentMsg) GetL2SentMsgMsgHashByHeightRange(ctx context.Context, startH
 	return results, nil
 }
 
-// GetL2SentMessageByNonce get l2 sent message by nonce
+// GetL2SentMsgHashesByNonceRange get the msg hashes and the nonces of the l2 sent msgs by nonce range, both inclusive,
+// the archived ones included
+func (l *L2SentMsg) GetL2SentMsgHashesByNonceRange(ctx context.Context, startNonce, endNonce uint64) ([]*L2SentMsg, error) {
+	var results []*L2SentMsg
+	err := l.db.WithContext(ctx).Model(&L2SentMsg{}).Table(L2SentMsgAllViewName).
+		Select("msg_hash, nonce").
+		Where("nonce >= ? AND nonce <= ?", startNonce, endNonce).
+		Order("nonce ASC").
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("L2SentMsg.GetL2SentMsgHashesByNonceRange error: %w", err)
+	}
+	return results, nil
+}
+
+// GetL2SentMessageByNonce get l2 sent message by nonce, the archived ones included
 func (l *L2SentMsg) GetL2SentMessageByNonce(ctx context.Context, nonce uint64) (*L2SentMsg, error) {
 	var result L2SentMsg
-	err := l.db.WithContext(ctx).Model(&L2SentMsg{}).
+	err := l.db.WithContext(ctx).Model(&L2SentMsg{}).Table(L2SentMsgAllViewName).
 		Where("nonce = ?", nonce).
 		First(&result).
 		Error
@@ -193,10 +347,36 @@ func (l *L2SentMsg) GetL2SentMessageByNonce(ctx context.Context, nonce uint64) (
 	return &result, nil
 }
 
-// GetLatestL2SentMsgLEHeight get latest l2 sent msg less than or equal to end block number
+// NonceRange is a range of the nonces of the l2 sent messages, both inclusive
+type NonceRange struct {
+	StartNonce uint64 `gorm:"column:start_nonce"`
+	EndNonce   uint64 `gorm:"column:end_nonce"`
+}
+
+// GetMissingNonceRanges get at most limit ranges of the nonces missing between the nonces of the l2 sent messages,
+// the lowest first and the archived ones included. The nonces are sequential, a missing nonce is a withdrawal not
+// indexed.
+func (l *L2SentMsg) GetMissingNonceRanges(ctx context.Context, limit int) ([]*NonceRange, error) {
+	var results []*NonceRange
+	nonces := l.db.Model(&L2SentMsg{}).Table(L2SentMsgAllViewName).
+		Select("nonce, LEAD(nonce) OVER (ORDER BY nonce) AS next_nonce")
+	err := l.db.WithContext(ctx).Table("(?) AS nonces", nonces).
+		Select("nonce + 1 AS start_nonce, next_nonce - 1 AS end_nonce").
+		Where("next_nonce > nonce + 1").
+		Order("nonce ASC").
+		Limit(limit).
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("L2SentMsg.GetMissingNonceRanges error: %w", err)
+	}
+	return results, nil
+}
+
+// GetLatestL2SentMsgLEHeight get latest l2 sent msg less than or equal to end block number, the archived ones included
 func (l *L2SentMsg) GetLatestL2SentMsgLEHeight(ctx context.Context, endBlockNumber uint64) (*L2SentMsg, error) {
 	var result L2SentMsg
-	err := l.db.WithContext(ctx).Model(&L2SentMsg{}).
+	err := l.db.WithContext(ctx).Model(&L2SentMsg{}).Table(L2SentMsgAllViewName).
 		Where("height <= ?", endBlockNumber).
 		Order("nonce DESC").
 		First(&result).
@@ -211,7 +391,7 @@ func (l *L2SentMsg) GetLatestL2SentMsgLEHeight(ctx context.Context, endBlockNumb
 	return &result, nil
 }
 
-// InsertL2SentMsg batch insert l2 sent msg
+// InsertL2SentMsg batch insert l2 sent msg, the msgs of the msg hashes or the nonces saved already are skipped
 func (l *L2SentMsg) InsertL2SentMsg(ctx context.Context, messages []*L2SentMsg, dbTx ...*gorm.DB) error {
 	if len(messages) == 0 {
 		return nil
@@ -221,7 +401,7 @@ func (l *L2SentMsg) InsertL2SentMsg(ctx context.Context, messages []*L2SentMsg,
 		db = dbTx[0]
 	}
 	db.WithContext(ctx)
-	err := db.Model(&L2SentMsg{}).Create(&messages).Error
+	err := db.Model(&L2SentMsg{}).Clauses(clause.OnConflict{DoNothing: true}).Create(&messages).Error
 	if err != nil {
 		l2hashes := make([]string, 0, len(messages))
 		heights := make([]uint64, 0, len(messages))
@@ -254,15 +434,73 @@ func (l *L2SentMsg) UpdateL2MessageProof(ctx context.Context, msgHash string, pr
 	return nil
 }
 
+// GetL2SentMsgsAfterHeight returns the l2 sent msgs after given height, e.g. the ones a reorg deletes
+func (l *L2SentMsg) GetL2SentMsgsAfterHeight(ctx context.Context, height uint64, dbTx ...*gorm.DB) ([]*L2SentMsg, error) {
+	db := l.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	var results []*L2SentMsg
+	err := db.WithContext(ctx).Model(&L2SentMsg{}).Where("height > ?", height).Find(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("L2SentMsg.GetL2SentMsgsAfterHeight error: %w", err)
+	}
+	return results, nil
+}
+
+// GetL2SentMsgsInBatchesAfterHeight returns the proven l2 sent msgs of the batches committed or finalized after the
+// layer1 height, the withdrawals whose claim status a layer1 reorg changes
+func (l *L2SentMsg) GetL2SentMsgsInBatchesAfterHeight(ctx context.Context, height uint64, dbTx ...*gorm.DB) ([]*L2SentMsg, error) {
+	db := l.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	var results []*L2SentMsg
+	err := db.WithContext(ctx).Model(&L2SentMsg{}).
+		Where("msg_proof != '' AND batch_index IN (SELECT batch_index FROM rollup_batch WHERE commit_height > ? OR finalize_height > ?)", height, height).
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("L2SentMsg.GetL2SentMsgsInBatchesAfterHeight error: %w", err)
+	}
+	return results, nil
+}
+
 // DeleteL2SentMsgAfterHeight delete l2 sent msg after height
 func (l *L2SentMsg) DeleteL2SentMsgAfterHeight(ctx context.Context, height uint64, dbTx ...*gorm.DB) error {
 	db := l.db
 	if len(dbTx) > 0 && dbTx[0] != nil {
 		db = dbTx[0]
 	}
-	err := db.WithContext(ctx).Model(&L2SentMsg{}).Delete("height > ?", height).Error
+	err := db.WithContext(ctx).Delete(&L2SentMsg{}, "height > ?", height).Error
 	if err != nil {
 		return fmt.Errorf("L2SentMsg.DeleteL2SentMsgAfterHeight error: %w", err)
 	}
 	return nil
 }
+
+// GetL2SentMsgByID get the l2 sent msg of the id, nil is returned if it doesn't exist
+func (l *L2SentMsg) GetL2SentMsgByID(ctx context.Context, id uint64) (*L2SentMsg, error) {
+	var result L2SentMsg
+	err := l.db.WithContext(ctx).Model(&L2SentMsg{}).Where("id = ?", id).First(&result).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("L2SentMsg.GetL2SentMsgByID error: %w", err)
+	}
+	return &result, nil
+}
+
+// DeleteL2SentMsgByID soft delete the l2 sent msg of the id, the number of the msgs deleted is returned
+func (l *L2SentMsg) DeleteL2SentMsgByID(ctx context.Context, id uint64, dbTx ...*gorm.DB) (int64, error) {
+	db := l.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	result := db.WithContext(ctx).Delete(&L2SentMsg{}, "id = ?", id)
+	if result.Error != nil {
+		return 0, fmt.Errorf("L2SentMsg.DeleteL2SentMsgByID error: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -2,6 +2,8 @@ package orm
 
 import (
 	"context"
+	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -33,7 +35,7 @@ func TestGetClaimableL2SentMsgByAddress(t *testing.T) {
 	l2SentMsgOrm := NewL2SentMsg(db)
 	relayedMsgOrm := NewRelayedMsg(db)
 
-	msgs, err := l2SentMsgOrm.GetClaimableL2SentMsgByAddress(context.Background(), "sender1")
+	msgs, err := l2SentMsgOrm.GetClaimableL2SentMsgByAddress(context.Background(), "sender1", ClaimableFilter{})
 	assert.NoError(t, err)
 	assert.Len(t, msgs, 0)
 
@@ -70,8 +72,267 @@ func TestGetClaimableL2SentMsgByAddress(t *testing.T) {
 	err = relayedMsgOrm.InsertRelayedMsg(context.Background(), relayedMsgs)
 	assert.NoError(t, err)
 
-	msgs, err = l2SentMsgOrm.GetClaimableL2SentMsgByAddress(context.Background(), "sender1")
+	msgs, err = l2SentMsgOrm.GetClaimableL2SentMsgByAddress(context.Background(), "sender1", ClaimableFilter{})
 	assert.NoError(t, err)
 	assert.Len(t, msgs, 1)
 	assert.Equal(t, "hash1", msgs[0].MsgHash)
 }
+
+func TestGetClaimableL2SentMsgByAddressWithMinValue(t *testing.T) {
+	db := setupTestDB(t)
+	l2SentMsgOrm := NewL2SentMsg(db)
+
+	l2SentMsgs := []*L2SentMsg{
+		{Sender: "sender1", MsgHash: "hash1", MsgProof: "proof1", Value: "999", Nonce: 0},
+		{Sender: "sender1", MsgHash: "hash2", MsgProof: "proof2", Value: "1000", Nonce: 1},
+		// compared as numbers, not as strings
+		{Sender: "sender1", MsgHash: "hash3", MsgProof: "proof3", Value: "20000000000000000000", Nonce: 2},
+		{Sender: "sender1", MsgHash: "hash4", MsgProof: "proof4", Value: "0", Nonce: 3},
+	}
+	assert.NoError(t, l2SentMsgOrm.InsertL2SentMsg(context.Background(), l2SentMsgs))
+
+	msgs, err := l2SentMsgOrm.GetClaimableL2SentMsgByAddress(context.Background(), "sender1", ClaimableFilter{MinValue: big.NewInt(1000)})
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, "hash3", msgs[0].MsgHash)
+	assert.Equal(t, "hash2", msgs[1].MsgHash)
+
+	msgs, err = l2SentMsgOrm.GetClaimableL2SentMsgByAddress(context.Background(), "sender1", ClaimableFilter{})
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 4)
+}
+
+func TestGetClaimableL2SentMsgByAddressWithAssets(t *testing.T) {
+	db := setupTestDB(t)
+	l2SentMsgOrm := NewL2SentMsg(db)
+	crossMsgOrm := NewCrossMsg(db)
+
+	l2SentMsgs := []*L2SentMsg{
+		{Sender: "sender1", MsgHash: "eth", MsgProof: "proof1", Nonce: 0},
+		{Sender: "sender1", MsgHash: "erc20", MsgProof: "proof2", Nonce: 1},
+		{Sender: "sender1", MsgHash: "erc721", MsgProof: "proof3", Nonce: 2},
+		// sent by calling the messenger directly, no cross message
+		{Sender: "sender1", MsgHash: "direct", MsgProof: "proof4", Nonce: 3},
+	}
+	assert.NoError(t, l2SentMsgOrm.InsertL2SentMsg(context.Background(), l2SentMsgs))
+	crossMsgs := []*CrossMsg{
+		{MsgHash: "eth", Layer2Hash: "0x01", Asset: int(ETH)},
+		{MsgHash: "erc20", Layer2Hash: "0x02", Asset: int(ERC20), Layer1Token: "l1token", Layer2Token: "l2token"},
+		{MsgHash: "erc721", Layer2Hash: "0x03", Asset: int(ERC721), Layer1Token: "l1nft", Layer2Token: "l2nft"},
+	}
+	assert.NoError(t, crossMsgOrm.InsertL2CrossMsg(context.Background(), crossMsgs))
+
+	msgs, err := l2SentMsgOrm.GetClaimableL2SentMsgByAddress(context.Background(), "sender1", ClaimableFilter{Assets: []AssetType{ETH}})
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, "direct", msgs[0].MsgHash)
+	assert.Equal(t, "eth", msgs[1].MsgHash)
+
+	msgs, err = l2SentMsgOrm.GetClaimableL2SentMsgByAddress(context.Background(), "sender1", ClaimableFilter{Assets: []AssetType{ERC721, ERC1155}})
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, "erc721", msgs[0].MsgHash)
+
+	// the counts of the pagination are filtered too
+	total, err := l2SentMsgOrm.GetTotalClaimableL2SentMsgCountByAddress(context.Background(), "sender1", []AssetType{ERC20})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), total)
+	msgs, err = l2SentMsgOrm.GetClaimableL2SentMsgByAddressWithOffset(context.Background(), "sender1", []AssetType{ERC20}, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, "erc20", msgs[0].MsgHash)
+}
+
+func TestGetClaimableL2SentMsgByAddresses(t *testing.T) {
+	db := setupTestDB(t)
+	l2SentMsgOrm := NewL2SentMsg(db)
+	relayedMsgOrm := NewRelayedMsg(db)
+
+	l2SentMsgs := []*L2SentMsg{
+		{OriginalSender: "sender1", Sender: "gateway", MsgHash: "hash1", MsgProof: "proof1", Nonce: 0},
+		{Sender: "sender2", MsgHash: "hash2", MsgProof: "proof2", Nonce: 1},
+		{Sender: "sender2", MsgHash: "claimed", MsgProof: "proof3", Nonce: 2},
+		{Sender: "sender3", MsgHash: "hash4", MsgProof: "proof4", Nonce: 3},
+	}
+	assert.NoError(t, l2SentMsgOrm.InsertL2SentMsg(context.Background(), l2SentMsgs))
+	assert.NoError(t, relayedMsgOrm.InsertRelayedMsg(context.Background(), []*RelayedMsg{{MsgHash: "claimed", Layer1Hash: "l1hash"}}))
+
+	msgs, err := l2SentMsgOrm.GetClaimableL2SentMsgByAddresses(context.Background(), []string{"sender1", "sender2"})
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, "hash2", msgs[0].MsgHash)
+	assert.Equal(t, "hash1", msgs[1].MsgHash)
+
+	msgs, err = l2SentMsgOrm.GetClaimableL2SentMsgByAddresses(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, msgs)
+}
+
+func TestGetClaimableL2SentMsgByAddressSortOrder(t *testing.T) {
+	db := setupTestDB(t)
+	l2SentMsgOrm := NewL2SentMsg(db)
+
+	// inserted out of order, hash2 and hash3 are sent in the same block
+	l2SentMsgs := []*L2SentMsg{
+		{OriginalSender: "sender1", MsgHash: "hash3", TxHash: "txhash3", MsgProof: "proof", Height: 2, Nonce: 0},
+		{OriginalSender: "sender1", MsgHash: "hash1", TxHash: "txhash1", MsgProof: "proof", Height: 1, Nonce: 1},
+		{OriginalSender: "sender1", MsgHash: "hash4", TxHash: "txhash4", MsgProof: "proof", Height: 3, Nonce: 2},
+		{OriginalSender: "sender1", MsgHash: "hash2", TxHash: "txhash2", MsgProof: "proof", Height: 2, Nonce: 3},
+	}
+	assert.NoError(t, l2SentMsgOrm.InsertL2SentMsg(context.Background(), l2SentMsgs))
+
+	msgHashes := func(msgs []*L2SentMsg) []string {
+		var result []string
+		for _, msg := range msgs {
+			result = append(result, msg.MsgHash)
+		}
+		return result
+	}
+
+	msgs, err := l2SentMsgOrm.GetClaimableL2SentMsgByAddress(context.Background(), "sender1", ClaimableFilter{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hash4", "hash3", "hash2", "hash1"}, msgHashes(msgs))
+
+	msgs, err = l2SentMsgOrm.GetClaimableL2SentMsgByAddress(context.Background(), "sender1", ClaimableFilter{Order: SortAsc})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hash1", "hash2", "hash3", "hash4"}, msgHashes(msgs))
+
+	// the pages follow the default order
+	msgs, err = l2SentMsgOrm.GetClaimableL2SentMsgByAddressWithOffset(context.Background(), "sender1", nil, 1, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hash3", "hash2"}, msgHashes(msgs))
+}
+
+func TestGetClaimableL2SentMsgByAddressWithOffset(t *testing.T) {
+	db := setupTestDB(t)
+	l2SentMsgOrm := NewL2SentMsg(db)
+	relayedMsgOrm := NewRelayedMsg(db)
+
+	total, err := l2SentMsgOrm.GetTotalClaimableL2SentMsgCountByAddress(context.Background(), "sender1", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), total)
+	msgs, err := l2SentMsgOrm.GetClaimableL2SentMsgByAddressWithOffset(context.Background(), "sender1", nil, 0, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, msgs)
+
+	var l2SentMsgs []*L2SentMsg
+	for i := 0; i < 6; i++ {
+		l2SentMsgs = append(l2SentMsgs, &L2SentMsg{
+			OriginalSender: "sender1",
+			MsgHash:        fmt.Sprintf("hash%d", i),
+			MsgProof:       "proof",
+			Nonce:          uint64(i),
+		})
+	}
+	assert.NoError(t, l2SentMsgOrm.InsertL2SentMsg(context.Background(), l2SentMsgs))
+	// hash5 is claimed, hash0 to hash4 are left
+	assert.NoError(t, relayedMsgOrm.InsertRelayedMsg(context.Background(), []*RelayedMsg{{MsgHash: "hash5"}}))
+
+	total, err = l2SentMsgOrm.GetTotalClaimableL2SentMsgCountByAddress(context.Background(), "sender1", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), total)
+
+	msgs, err = l2SentMsgOrm.GetClaimableL2SentMsgByAddressWithOffset(context.Background(), "sender1", nil, 0, 2)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 2)
+	assert.Equal(t, "hash4", msgs[0].MsgHash)
+	assert.Equal(t, "hash3", msgs[1].MsgHash)
+
+	// the partial last page
+	msgs, err = l2SentMsgOrm.GetClaimableL2SentMsgByAddressWithOffset(context.Background(), "sender1", nil, 4, 2)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, "hash0", msgs[0].MsgHash)
+
+	msgs, err = l2SentMsgOrm.GetClaimableL2SentMsgByAddressWithOffset(context.Background(), "sender1", nil, 6, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, msgs)
+}
+
+func TestGetClaimableL2SentMsgByAddressWithCursor(t *testing.T) {
+	db := setupTestDB(t)
+	l2SentMsgOrm := NewL2SentMsg(db)
+	relayedMsgOrm := NewRelayedMsg(db)
+
+	var l2SentMsgs []*L2SentMsg
+	for i := 0; i < 5; i++ {
+		l2SentMsgs = append(l2SentMsgs, &L2SentMsg{
+			OriginalSender: "sender1",
+			MsgHash:        fmt.Sprintf("hash%d", i),
+			MsgProof:       "proof",
+			Nonce:          uint64(i),
+		})
+	}
+	assert.NoError(t, l2SentMsgOrm.InsertL2SentMsg(context.Background(), l2SentMsgs))
+
+	seen := make(map[string]int)
+	page, err := l2SentMsgOrm.GetClaimableL2SentMsgByAddressWithCursor(context.Background(), "sender1", nil, 2)
+	assert.NoError(t, err)
+	assert.Len(t, page, 2)
+	assert.Equal(t, "hash4", page[0].MsgHash)
+	assert.Equal(t, "hash3", page[1].MsgHash)
+	for _, msg := range page {
+		seen[msg.MsgHash]++
+	}
+
+	// a message of the fetched page is claimed before the next page is fetched
+	assert.NoError(t, relayedMsgOrm.InsertRelayedMsg(context.Background(), []*RelayedMsg{{MsgHash: "hash4", Layer1Hash: "l1hash4"}}))
+
+	cursor := page[len(page)-1].Nonce
+	for {
+		page, err = l2SentMsgOrm.GetClaimableL2SentMsgByAddressWithCursor(context.Background(), "sender1", &cursor, 2)
+		assert.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		for _, msg := range page {
+			seen[msg.MsgHash]++
+		}
+		cursor = page[len(page)-1].Nonce
+	}
+
+	// no message is skipped or duplicated
+	assert.Len(t, seen, 5)
+	for _, count := range seen {
+		assert.Equal(t, 1, count)
+	}
+}
+
+func TestGetClaimableL2SentMsgByAddressWithKeyset(t *testing.T) {
+	db := setupTestDB(t)
+	l2SentMsgOrm := NewL2SentMsg(db)
+
+	// hash1 and hash2 are sent in the same tx, hash3 in another tx of the same block
+	l2SentMsgs := []*L2SentMsg{
+		{OriginalSender: "sender1", MsgHash: "hash0", TxHash: "txhash0", MsgProof: "proof", Height: 1, Nonce: 0},
+		{OriginalSender: "sender1", MsgHash: "hash1", TxHash: "txhash1", MsgProof: "proof", Height: 2, Nonce: 1},
+		{OriginalSender: "sender1", MsgHash: "hash2", TxHash: "txhash1", MsgProof: "proof", Height: 2, Nonce: 2},
+		{OriginalSender: "sender1", MsgHash: "hash3", TxHash: "txhash3", MsgProof: "proof", Height: 2, Nonce: 3},
+		{OriginalSender: "sender1", MsgHash: "hash4", TxHash: "txhash4", MsgProof: "proof", Height: 3, Nonce: 4},
+	}
+	assert.NoError(t, l2SentMsgOrm.InsertL2SentMsg(context.Background(), l2SentMsgs))
+
+	var seen []string
+	page, err := l2SentMsgOrm.GetClaimableL2SentMsgByAddressWithKeyset(context.Background(), "sender1", nil, 2)
+	assert.NoError(t, err)
+	for _, msg := range page {
+		seen = append(seen, msg.MsgHash)
+	}
+
+	// a new message is sent before the next page is fetched
+	assert.NoError(t, l2SentMsgOrm.InsertL2SentMsg(context.Background(), []*L2SentMsg{
+		{OriginalSender: "sender1", MsgHash: "hash5", TxHash: "txhash5", MsgProof: "proof", Height: 4, Nonce: 5},
+	}))
+
+	for len(page) > 0 {
+		last := page[len(page)-1]
+		page, err = l2SentMsgOrm.GetClaimableL2SentMsgByAddressWithKeyset(context.Background(), "sender1", &MsgCursor{Height: last.Height, TxHash: last.TxHash, MsgHash: last.MsgHash}, 2)
+		assert.NoError(t, err)
+		for _, msg := range page {
+			seen = append(seen, msg.MsgHash)
+		}
+	}
+
+	// no message is skipped or duplicated, the new one is on the pages before the cursor
+	assert.Equal(t, []string{"hash4", "hash3", "hash2", "hash1", "hash0"}, seen)
+}
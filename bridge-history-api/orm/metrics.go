@@ -0,0 +1,100 @@
+package orm
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+const metricsStartKey = "orm:metrics_start"
+
+// ormPkgPath the package path of the orm, the frames of the orm methods start with it
+var ormPkgPath = reflect.TypeOf(CrossMsg{}).PkgPath()
+
+// dbMetrics records the duration of the db queries by the orm method running them
+type dbMetrics struct {
+	queryDuration *prometheus.HistogramVec
+}
+
+// RegisterMetrics registers the metrics of the db queries on reg and records the duration of the queries of db
+// labeled by the orm method running them, the queries running outside the orm methods are labeled as other
+func RegisterMetrics(db *gorm.DB, reg prometheus.Registerer) error {
+	m := &dbMetrics{
+		queryDuration: promauto.With(reg).NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "bridge_history_api_db_query_duration_seconds",
+			Help:    "The duration of the db queries in seconds by the orm method",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"method", "outcome"}),
+	}
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().Before("gorm:create").Register("metrics:before_create", m.before),
+		callbacks.Create().After("gorm:create").Register("metrics:after_create", m.after),
+		callbacks.Query().Before("gorm:query").Register("metrics:before_query", m.before),
+		callbacks.Query().After("gorm:query").Register("metrics:after_query", m.after),
+		callbacks.Update().Before("gorm:update").Register("metrics:before_update", m.before),
+		callbacks.Update().After("gorm:update").Register("metrics:after_update", m.after),
+		callbacks.Delete().Before("gorm:delete").Register("metrics:before_delete", m.before),
+		callbacks.Delete().After("gorm:delete").Register("metrics:after_delete", m.after),
+		callbacks.Row().Before("gorm:row").Register("metrics:before_row", m.before),
+		callbacks.Row().After("gorm:row").Register("metrics:after_row", m.after),
+		callbacks.Raw().Before("gorm:raw").Register("metrics:before_raw", m.before),
+		callbacks.Raw().After("gorm:raw").Register("metrics:after_raw", m.after),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *dbMetrics) before(db *gorm.DB) {
+	db.InstanceSet(metricsStartKey, time.Now())
+}
+
+func (m *dbMetrics) after(db *gorm.DB) {
+	start, ok := db.InstanceGet(metricsStartKey)
+	if !ok {
+		return
+	}
+	outcome := "ok"
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		outcome = "error"
+	}
+	m.queryDuration.WithLabelValues(callerMethod(), outcome).Observe(time.Since(start.(time.Time)).Seconds())
+}
+
+// callerMethod returns the orm method on the stack of the query, e.g. CrossMsg.GetL2CrossMsgByHash
+func callerMethod() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if method, ok := ormMethod(frame.Function); ok {
+			return method
+		}
+		if !more {
+			return "other"
+		}
+	}
+}
+
+// ormMethod returns the orm method of the function name of a frame, the closures are attributed to their method and
+// the functions other than the methods of the orm structs are not orm methods
+func ormMethod(function string) (string, bool) {
+	name := strings.TrimPrefix(function, ormPkgPath+".")
+	if name == function || !strings.HasPrefix(name, "(*") || strings.HasPrefix(name, "(*dbMetrics)") {
+		return "", false
+	}
+	name = strings.NewReplacer("(*", "", ")", "").Replace(name)
+	if i := strings.Index(name, ".func"); i >= 0 {
+		name = name[:i]
+	}
+	return name, true
+}
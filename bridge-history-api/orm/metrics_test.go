@@ -0,0 +1,50 @@
+package orm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestRegisterMetrics(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	assert.NoError(t, err)
+	reg := prometheus.NewRegistry()
+	assert.NoError(t, RegisterMetrics(db, reg))
+
+	_, err = NewCrossMsg(db).GetL1CrossMsgByHash(context.Background(), common.HexToHash("0x01"))
+	assert.NoError(t, err)
+	var results []*CrossMsg
+	assert.NoError(t, db.Find(&results).Error)
+
+	metricFamilies, err := reg.Gather()
+	assert.NoError(t, err)
+	assert.Len(t, metricFamilies, 1)
+	methods := make(map[string]uint64)
+	for _, metric := range metricFamilies[0].GetMetric() {
+		for _, label := range metric.GetLabel() {
+			if label.GetName() == "method" {
+				methods[label.GetValue()] = metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	assert.Equal(t, map[string]uint64{"CrossMsg.GetL1CrossMsgByHash": 1, "other": 1}, methods)
+}
+
+func TestOrmMethod(t *testing.T) {
+	method, ok := ormMethod(ormPkgPath + ".(*CrossMsg).InsertL1CrossMsg")
+	assert.True(t, ok)
+	assert.Equal(t, "CrossMsg.InsertL1CrossMsg", method)
+	method, ok = ormMethod(ormPkgPath + ".(*RollupBatch).RevertRollupBatch.func1")
+	assert.True(t, ok)
+	assert.Equal(t, "RollupBatch.RevertRollupBatch", method)
+	_, ok = ormMethod(ormPkgPath + ".(*dbMetrics).after")
+	assert.False(t, ok)
+	_, ok = ormMethod("bridge-history-api/crossmsg.saveL1Events.func1")
+	assert.False(t, ok)
+}
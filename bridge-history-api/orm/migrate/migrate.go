@@ -3,6 +3,8 @@ package migrate
 import (
 	"database/sql"
 	"embed"
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
 
@@ -15,6 +17,9 @@ var embedMigrations embed.FS
 // MigrationsDir migration dir
 const MigrationsDir string = "migrations"
 
+// ErrVersionMismatch the db isn't migrated to the latest migration embedded in the binary
+var ErrVersionMismatch = errors.New("schema version mismatch")
+
 func init() {
 	goose.SetBaseFS(embedMigrations)
 	goose.SetSequential(true)
@@ -55,6 +60,46 @@ func Status(db *sql.DB) error {
 	return goose.Version(db, MigrationsDir)
 }
 
+// PrintStatus prints the embedded migrations along with the time each one is applied at, pending if not applied
+func PrintStatus(db *sql.DB) error {
+	return goose.Status(db, MigrationsDir)
+}
+
+// Latest get the version of the latest migration embedded in the binary
+func Latest() (int64, error) {
+	migrations, err := goose.CollectMigrations(MigrationsDir, 0, goose.MaxVersion)
+	if err != nil {
+		return 0, err
+	}
+	last, err := migrations.Last()
+	if err != nil {
+		return 0, err
+	}
+	return last.Version, nil
+}
+
+// CheckVersion returns ErrVersionMismatch unless the db is at the version of the latest embedded migration, the db
+// is behind if the migrations of the binary are not applied yet and ahead if the binary is older than the last
+// migration applied. The binaries check the version at startup rather than migrating the db themselves, so that the
+// migrations are applied once and on purpose with `migrate up`.
+func CheckVersion(db *sql.DB) error {
+	latest, err := Latest()
+	if err != nil {
+		return err
+	}
+	current, err := Current(db)
+	if err != nil {
+		return err
+	}
+	switch {
+	case current < latest:
+		return fmt.Errorf("%w: the db is at version %d behind the version %d of the binary, run `migrate up`", ErrVersionMismatch, current, latest)
+	case current > latest:
+		return fmt.Errorf("%w: the db is at version %d ahead of the version %d of the binary, run the binary of the db version or `migrate down --version %d`", ErrVersionMismatch, current, latest, latest)
+	}
+	return nil
+}
+
 // Create a new migration folder
 func Create(db *sql.DB, name, migrationType string) error {
 	return goose.Create(db, MigrationsDir, name, migrationType)
@@ -0,0 +1,31 @@
+package migrate
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatest(t *testing.T) {
+	files, err := fs.Glob(embedMigrations, MigrationsDir+"/*.sql")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	latest, err := Latest()
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(files)), latest)
+}
+
+func TestMigrationsRollBack(t *testing.T) {
+	files, err := fs.Glob(embedMigrations, MigrationsDir+"/*.sql")
+	require.NoError(t, err)
+	for _, file := range files {
+		content, readErr := fs.ReadFile(embedMigrations, file)
+		require.NoError(t, readErr)
+		assert.Contains(t, string(content), "-- +goose Up", file)
+		assert.True(t, strings.Contains(string(content), "-- +goose Down"), "%s has no rollback", file)
+	}
+}
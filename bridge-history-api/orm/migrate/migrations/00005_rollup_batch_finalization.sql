@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE rollup_batch
+    ADD COLUMN finalize_height  BIGINT  NOT NULL DEFAULT 0,
+    ADD COLUMN finalize_tx_hash VARCHAR NOT NULL DEFAULT '';
+
+comment
+on column rollup_batch.finalize_height is '0 means the batch is not finalized on layer1 yet';
+
+CREATE INDEX idx_finalize_height_rollup_batch ON rollup_batch (finalize_height, deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_finalize_height_rollup_batch;
+
+ALTER TABLE rollup_batch
+    DROP COLUMN IF EXISTS finalize_height,
+    DROP COLUMN IF EXISTS finalize_tx_hash;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE cross_message
+    ADD COLUMN block_hash VARCHAR NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cross_message
+    DROP COLUMN IF EXISTS block_hash;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE relayed_msg
+    ADD COLUMN delivered BOOLEAN NOT NULL DEFAULT FALSE;
+
+comment
+on column relayed_msg.delivered is 'true if the gateway finalize (token transfer) event is observed in the relay tx';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE relayed_msg
+    DROP COLUMN IF EXISTS delivered;
+-- +goose StatementEnd
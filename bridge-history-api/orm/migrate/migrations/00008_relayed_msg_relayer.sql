@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE relayed_msg
+    ADD COLUMN relayer VARCHAR NOT NULL DEFAULT '';
+
+comment
+on column relayed_msg.relayer is 'the sender of the layer1 relay tx, empty for messages relayed on layer2';
+
+CREATE INDEX idx_relayer_relayed_msg ON relayed_msg (relayer, deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_relayer_relayed_msg;
+
+ALTER TABLE relayed_msg
+    DROP COLUMN IF EXISTS relayer;
+-- +goose StatementEnd
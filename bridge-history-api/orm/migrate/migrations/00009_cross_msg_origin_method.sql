@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE cross_message
+    ADD COLUMN origin_method VARCHAR NOT NULL DEFAULT '';
+
+comment
+on column cross_message.origin_method is 'the method called by the layer1 deposit tx, empty if unknown';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cross_message
+    DROP COLUMN IF EXISTS origin_method;
+-- +goose StatementEnd
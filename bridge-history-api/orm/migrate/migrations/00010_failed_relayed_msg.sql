@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+create table failed_relayed_msg
+(
+    id          BIGSERIAL PRIMARY KEY,
+    msg_hash    VARCHAR NOT NULL,
+    height      BIGINT NOT NULL,
+    layer1_hash VARCHAR NOT NULL DEFAULT '',
+    layer2_hash VARCHAR NOT NULL DEFAULT '',
+    created_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at  TIMESTAMP(0) DEFAULT NULL
+);
+
+comment
+on table failed_relayed_msg is 'the relay txs in which the message execution failed, the message can be relayed again';
+
+create unique index uk_msg_hash_l1_hash_l2_hash_failed_relayed_msg
+on failed_relayed_msg (msg_hash, layer1_hash, layer2_hash) where deleted_at IS NULL;
+
+CREATE INDEX idx_l1_msg_failed_relayed_msg ON failed_relayed_msg (layer1_hash, deleted_at);
+
+CREATE INDEX idx_l2_msg_failed_relayed_msg ON failed_relayed_msg (layer2_hash, deleted_at);
+
+CREATE INDEX idx_msg_hash_deleted_at_failed_relayed_msg on failed_relayed_msg (msg_hash, deleted_at);
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON failed_relayed_msg FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists failed_relayed_msg;
+-- +goose StatementEnd
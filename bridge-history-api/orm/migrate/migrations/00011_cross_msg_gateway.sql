@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE cross_message
+    ADD COLUMN gateway VARCHAR NOT NULL DEFAULT '';
+
+comment
+on column cross_message.gateway is 'the address of the gateway emitted the deposit or withdraw event';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cross_message
+    DROP COLUMN IF EXISTS gateway;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE cross_message
+    ADD COLUMN msg_sender VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN msg_target VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN msg_value  VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN msg_nonce  BIGINT  NOT NULL DEFAULT 0,
+    ADD COLUMN msg_data   TEXT    NOT NULL DEFAULT '';
+
+comment
+on column cross_message.msg_sender is 'the sender of the SentMessage event emitted along with the deposit, empty if unknown';
+
+comment
+on column cross_message.msg_data is 'the hex encoded message of the SentMessage event emitted along with the deposit';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cross_message
+    DROP COLUMN IF EXISTS msg_sender,
+    DROP COLUMN IF EXISTS msg_target,
+    DROP COLUMN IF EXISTS msg_value,
+    DROP COLUMN IF EXISTS msg_nonce,
+    DROP COLUMN IF EXISTS msg_data;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE rollup_batch
+    ADD COLUMN finalize_timestamp TIMESTAMP(0) DEFAULT NULL;
+
+comment
+on column rollup_batch.finalize_timestamp is 'the layer1 block timestamp of the finalize height, NULL if not finalized or not fetched yet';
+
+ALTER TABLE relayed_msg
+    ADD COLUMN block_timestamp TIMESTAMP(0) DEFAULT NULL;
+
+comment
+on column relayed_msg.block_timestamp is 'the block timestamp of the relay height, only fetched for the relays on layer1';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE relayed_msg
+    DROP COLUMN IF EXISTS block_timestamp;
+
+ALTER TABLE rollup_batch
+    DROP COLUMN IF EXISTS finalize_timestamp;
+-- +goose StatementEnd
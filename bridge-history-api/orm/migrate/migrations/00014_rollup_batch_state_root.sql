@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE rollup_batch
+    ADD COLUMN state_root VARCHAR NOT NULL DEFAULT '';
+
+comment
+on column rollup_batch.state_root is 'the post state root of the batch, empty until the batch is finalized on layer1';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE rollup_batch
+    DROP COLUMN IF EXISTS state_root;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE rollup_batch
+    ADD COLUMN reverted BOOLEAN NOT NULL DEFAULT false;
+
+comment
+on column rollup_batch.reverted is 'the batch is reverted on layer1, reverted batches are soft deleted so the batch index can be committed again';
+
+CREATE INDEX idx_reverted_rollup_batch ON rollup_batch (batch_index) WHERE reverted;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_reverted_rollup_batch;
+
+ALTER TABLE rollup_batch
+    DROP COLUMN IF EXISTS reverted;
+-- +goose StatementEnd
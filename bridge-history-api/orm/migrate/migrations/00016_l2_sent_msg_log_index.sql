@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE l2_sent_msg
+    ADD COLUMN log_index BIGINT DEFAULT NULL;
+
+comment
+on column l2_sent_msg.log_index is 'the index of the SentMessage log in the layer2 block, NULL for the messages indexed before the column is added';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE l2_sent_msg
+    DROP COLUMN IF EXISTS log_index;
+-- +goose StatementEnd
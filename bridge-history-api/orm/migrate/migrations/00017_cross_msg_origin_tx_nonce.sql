@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE cross_message
+    ADD COLUMN origin_tx_nonce BIGINT DEFAULT NULL;
+
+comment
+on column cross_message.origin_tx_nonce is 'the account nonce of the layer1 deposit tx, NULL if unknown';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cross_message
+    DROP COLUMN IF EXISTS origin_tx_nonce;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE rollup_batch
+    ADD COLUMN commit_tx_hash VARCHAR NOT NULL DEFAULT '';
+
+comment
+on column rollup_batch.commit_tx_hash is 'the layer1 tx committing the batch, empty for the batches indexed before the column is added';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE rollup_batch
+    DROP COLUMN IF EXISTS commit_tx_hash;
+-- +goose StatementEnd
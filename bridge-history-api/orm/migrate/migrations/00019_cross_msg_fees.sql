@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE cross_message
+    ADD COLUMN gas_fee    VARCHAR NOT NULL DEFAULT '',
+    ADD COLUMN bridge_fee VARCHAR NOT NULL DEFAULT '';
+
+comment
+on column cross_message.gas_fee is 'the gas fee in wei paid by the tx sending the message, empty for the messages indexed before the column is added';
+comment
+on column cross_message.bridge_fee is 'the value in wei paid by the tx sending the message on top of the message value, empty for the messages indexed before the column is added';
+
+ALTER TABLE relayed_msg
+    ADD COLUMN gas_fee VARCHAR NOT NULL DEFAULT '';
+
+comment
+on column relayed_msg.gas_fee is 'the gas fee in wei paid by the layer1 relay tx, empty on layer2 and for the messages indexed before the column is added';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE relayed_msg
+    DROP COLUMN IF EXISTS gas_fee;
+
+ALTER TABLE cross_message
+    DROP COLUMN IF EXISTS bridge_fee,
+    DROP COLUMN IF EXISTS gas_fee;
+-- +goose StatementEnd
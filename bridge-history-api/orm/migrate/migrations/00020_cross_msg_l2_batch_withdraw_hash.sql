@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the layer2 batch withdrawals of ERC721 and ERC1155 were indexed with the layer2 tx hash in layer1_hash
+UPDATE cross_message
+SET layer2_hash = layer1_hash,
+    layer1_hash = ''
+WHERE msg_type = 2
+  AND layer2_hash = ''
+  AND layer1_hash != '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- the fixed hashes are not moved back
+SELECT 1;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+create table indexed_block
+(
+    id         BIGSERIAL PRIMARY KEY,
+    layer      SMALLINT NOT NULL,
+    height     BIGINT NOT NULL,
+    block_hash VARCHAR NOT NULL,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP(0) DEFAULT NULL
+);
+
+comment
+on table indexed_block is 'the hashes of the blocks the events are indexed from, to detect the reorgs of the indexed blocks';
+
+create unique index uk_layer_height_indexed_block
+on indexed_block (layer, height) where deleted_at IS NULL;
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON indexed_block FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists indexed_block;
+-- +goose StatementEnd
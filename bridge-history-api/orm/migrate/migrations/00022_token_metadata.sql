@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+create table token_metadata
+(
+    id         BIGSERIAL PRIMARY KEY,
+    layer      SMALLINT NOT NULL,
+    address    VARCHAR NOT NULL,
+    symbol     VARCHAR NOT NULL DEFAULT '',
+    name       VARCHAR NOT NULL DEFAULT '',
+    decimals   SMALLINT NOT NULL,
+    logo_uri   VARCHAR NOT NULL DEFAULT '',
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP(0) DEFAULT NULL
+);
+
+comment
+on table token_metadata is 'the metadata of the bridged ERC20 tokens, from the official token list or read from the token contracts';
+
+create unique index uk_layer_address_token_metadata
+on token_metadata (layer, address) where deleted_at IS NULL;
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON token_metadata FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists token_metadata;
+-- +goose StatementEnd
@@ -0,0 +1,61 @@
+-- +goose Up
+-- +goose StatementBegin
+create table webhook
+(
+    id         BIGSERIAL PRIMARY KEY,
+    address    VARCHAR NOT NULL,
+    url        VARCHAR NOT NULL,
+    secret     VARCHAR NOT NULL,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP(0) DEFAULT NULL
+);
+
+comment
+on table webhook is 'the callback urls notified of the claimable withdrawals and the finalized deposits and withdrawals of an address';
+
+create unique index uk_address_url_webhook
+on webhook (address, url) where deleted_at IS NULL;
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON webhook FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+
+create table webhook_delivery
+(
+    id              BIGSERIAL PRIMARY KEY,
+    webhook_id      BIGINT NOT NULL,
+    event_type      VARCHAR NOT NULL,
+    address         VARCHAR NOT NULL,
+    msg_hash        VARCHAR NOT NULL,
+    tx_hash         VARCHAR NOT NULL DEFAULT '',
+    status          SMALLINT NOT NULL DEFAULT 0,
+    attempts        INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error      VARCHAR NOT NULL DEFAULT '',
+    delivered_at    TIMESTAMP(0) DEFAULT NULL,
+    created_at      TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at      TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at      TIMESTAMP(0) DEFAULT NULL
+);
+
+comment
+on column webhook_delivery.status is 'pending, delivered, failed';
+
+create unique index uk_webhook_id_event_type_msg_hash_webhook_delivery
+on webhook_delivery (webhook_id, event_type, msg_hash) where deleted_at IS NULL;
+
+create index idx_status_next_attempt_at_webhook_delivery
+on webhook_delivery (status, next_attempt_at) where deleted_at IS NULL;
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON webhook_delivery FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists webhook_delivery;
+drop table if exists webhook;
+-- +goose StatementEnd
@@ -0,0 +1,85 @@
+-- +goose Up
+-- +goose StatementBegin
+create table bridge_daily_volume
+(
+    id         BIGSERIAL PRIMARY KEY,
+    day        DATE NOT NULL,
+    msg_type   SMALLINT NOT NULL,
+    asset      SMALLINT NOT NULL,
+    token      VARCHAR NOT NULL DEFAULT '',
+    tx_count   BIGINT NOT NULL DEFAULT 0,
+    volume     NUMERIC(78, 0) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP(0) DEFAULT NULL
+);
+
+comment
+on table bridge_daily_volume is 'the number and the bridged amount of the deposits and the withdrawals of each token sent per day, maintained by the fetcher';
+
+comment
+on column bridge_daily_volume.token is 'the layer1 address of the bridged token, empty for ETH';
+
+create unique index uk_day_msg_type_asset_token_bridge_daily_volume
+on bridge_daily_volume (day, msg_type, asset, token) where deleted_at IS NULL;
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON bridge_daily_volume FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+
+create table bridge_daily_finalization
+(
+    id                     BIGSERIAL PRIMARY KEY,
+    day                    DATE NOT NULL,
+    msg_type               SMALLINT NOT NULL,
+    finalized_count        BIGINT NOT NULL DEFAULT 0,
+    total_finalize_seconds BIGINT NOT NULL DEFAULT 0,
+    created_at             TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at             TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at             TIMESTAMP(0) DEFAULT NULL
+);
+
+comment
+on table bridge_daily_finalization is 'the number of the deposits and the withdrawals relayed on the target layer per day and the sum of the times from their sending to their relay, maintained by the fetcher';
+
+create unique index uk_day_msg_type_bridge_daily_finalization
+on bridge_daily_finalization (day, msg_type) where deleted_at IS NULL;
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON bridge_daily_finalization FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+
+create table bridge_stats_cursor
+(
+    id         BIGSERIAL PRIMARY KEY,
+    name       VARCHAR NOT NULL,
+    last_id    BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP(0) DEFAULT NULL
+);
+
+comment
+on table bridge_stats_cursor is 'the id of the last row of each source table aggregated into the bridge stats';
+
+create unique index uk_name_bridge_stats_cursor
+on bridge_stats_cursor (name) where deleted_at IS NULL;
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON bridge_stats_cursor FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+
+comment
+on column relayed_msg.block_timestamp is 'the block timestamp of the relay height';
+
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+comment
+on column relayed_msg.block_timestamp is 'the block timestamp of the relay height, only fetched for the relays on layer1';
+
+drop table if exists bridge_stats_cursor;
+drop table if exists bridge_daily_finalization;
+drop table if exists bridge_daily_volume;
+-- +goose StatementEnd
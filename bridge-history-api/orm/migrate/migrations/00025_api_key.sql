@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+create table api_key_tier
+(
+    id                  BIGSERIAL PRIMARY KEY,
+    name                VARCHAR NOT NULL,
+    requests_per_second DOUBLE PRECISION NOT NULL DEFAULT 0,
+    burst               INTEGER NOT NULL DEFAULT 0,
+    created_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at          TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at          TIMESTAMP(0) DEFAULT NULL
+);
+
+comment
+on table api_key_tier is 'the rate limits of the api keys of each tier';
+
+comment
+on column api_key_tier.requests_per_second is 'the rate the bucket of each api key of the tier is refilled at, 0 does not limit the keys of the tier';
+
+comment
+on column api_key_tier.burst is 'the capacity of the bucket of each api key of the tier, 0 is twice the rate';
+
+create unique index uk_name_api_key_tier
+on api_key_tier (name) where deleted_at IS NULL;
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON api_key_tier FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+
+create table api_key
+(
+    id         BIGSERIAL PRIMARY KEY,
+    key_hash   VARCHAR NOT NULL,
+    tier       VARCHAR NOT NULL,
+    owner      VARCHAR NOT NULL DEFAULT '',
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP(0) DEFAULT NULL
+);
+
+comment
+on table api_key is 'the api keys of the rate limiting, the keys are only stored hashed';
+
+comment
+on column api_key.key_hash is 'the hex sha256 of the api key, e.g. encode(sha256(''the key''), ''hex'')';
+
+comment
+on column api_key.tier is 'the name of the api_key_tier of the key, the keys of unknown tiers are rejected';
+
+create unique index uk_key_hash_api_key
+on api_key (key_hash) where deleted_at IS NULL;
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON api_key FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists api_key;
+drop table if exists api_key_tier;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+create table l1_block_tag
+(
+    id         BIGSERIAL PRIMARY KEY,
+    tag        VARCHAR NOT NULL,
+    number     BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP(0) DEFAULT NULL
+);
+
+comment
+on table l1_block_tag is 'the latest layer1 block number of each block tag polled by the fetcher';
+
+comment
+on column l1_block_tag.tag is 'the block tag of the layer1 node, safe or finalized';
+
+create unique index uk_tag_l1_block_tag
+on l1_block_tag (tag) where deleted_at IS NULL;
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON l1_block_tag FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists l1_block_tag;
+-- +goose StatementEnd
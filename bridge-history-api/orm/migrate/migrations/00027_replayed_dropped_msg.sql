@@ -0,0 +1,73 @@
+-- +goose Up
+-- +goose StatementBegin
+create table replayed_msg
+(
+    id          BIGSERIAL PRIMARY KEY,
+    msg_hash    VARCHAR NOT NULL,
+    queue_index BIGINT NOT NULL,
+    height      BIGINT NOT NULL,
+    layer1_hash VARCHAR NOT NULL,
+    created_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at  TIMESTAMP(0) DEFAULT NULL
+);
+
+comment
+on table replayed_msg is 'the replays of the deposits by the L1ScrollMessenger, each replay appends the message to the L1MessageQueue again with a new gas limit';
+
+comment
+on column replayed_msg.msg_hash is 'the message hash of the replayed deposit, the replays relay the same message';
+
+comment
+on column replayed_msg.queue_index is 'the index in the L1MessageQueue the message is appended at by the replay';
+
+comment
+on column replayed_msg.layer1_hash is 'the replayMessage tx';
+
+create unique index uk_queue_index_replayed_msg
+on replayed_msg (queue_index) where deleted_at IS NULL;
+
+CREATE INDEX idx_msg_hash_deleted_at_replayed_msg on replayed_msg (msg_hash, deleted_at);
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON replayed_msg FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+
+create table dropped_msg
+(
+    id          BIGSERIAL PRIMARY KEY,
+    msg_hash    VARCHAR NOT NULL,
+    queue_index BIGINT NOT NULL,
+    height      BIGINT NOT NULL,
+    layer1_hash VARCHAR NOT NULL,
+    created_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at  TIMESTAMP(0) DEFAULT NULL
+);
+
+comment
+on table dropped_msg is 'the deposits dropped from the L1MessageQueue by the L1ScrollMessenger, their value is refunded on layer1 and they can not be relayed anymore';
+
+comment
+on column dropped_msg.queue_index is 'the index in the L1MessageQueue the deposit is sent at, the indexes of its replays are dropped along';
+
+comment
+on column dropped_msg.layer1_hash is 'the dropMessage tx';
+
+create unique index uk_msg_hash_dropped_msg
+on dropped_msg (msg_hash) where deleted_at IS NULL;
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON dropped_msg FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+
+-- the drops are resolved to the deposits by the index they are sent at
+CREATE INDEX idx_msg_nonce_msg_type_cross_message ON cross_message (msg_nonce, msg_type, deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop index if exists idx_msg_nonce_msg_type_cross_message;
+drop table if exists dropped_msg;
+drop table if exists replayed_msg;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the archive tables keep the columns of the hot tables in the same order, the rows are moved by INSERT ... SELECT *.
+-- The migrations adding columns to the hot tables must add them to the archive tables and recreate l2_sent_msg_all.
+create table cross_message_archive
+(
+    like cross_message
+) partition by range (height);
+
+comment
+on table cross_message_archive is 'the relayed cross messages moved out of cross_message by the archival, partitioned by height';
+
+CREATE INDEX idx_l1_msg_index_archive ON cross_message_archive (layer1_hash, deleted_at);
+
+CREATE INDEX idx_l2_msg_index_archive ON cross_message_archive (layer2_hash, deleted_at);
+
+CREATE INDEX idx_msg_hash_archive ON cross_message_archive (msg_hash, deleted_at);
+
+create table l2_sent_msg_archive
+(
+    like l2_sent_msg
+) partition by range (height);
+
+comment
+on table l2_sent_msg_archive is 'the l2 sent messages of the withdrawals moved out of cross_message by the archival, partitioned by height';
+
+CREATE INDEX idx_nonce_l2_sent_msg_archive ON l2_sent_msg_archive (nonce, deleted_at);
+
+CREATE INDEX idx_msg_hash_l2_sent_msg_archive ON l2_sent_msg_archive (msg_hash, deleted_at);
+
+-- the withdraw trie is built from all the l2 sent messages, the archived ones included
+create view l2_sent_msg_all as
+select * from l2_sent_msg
+union all
+select * from l2_sent_msg_archive;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop view if exists l2_sent_msg_all;
+drop table if exists l2_sent_msg_archive;
+drop table if exists cross_message_archive;
+-- +goose StatementEnd
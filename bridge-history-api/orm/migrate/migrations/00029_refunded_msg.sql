@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+create table refunded_msg
+(
+    id          BIGSERIAL PRIMARY KEY,
+    msg_hash    VARCHAR NOT NULL,
+    height      BIGINT NOT NULL,
+    layer1_hash VARCHAR NOT NULL,
+    token       VARCHAR NOT NULL DEFAULT '',
+    recipient   VARCHAR NOT NULL,
+    amount      VARCHAR NOT NULL,
+    created_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at  TIMESTAMP(0) DEFAULT NULL
+);
+
+comment
+on table refunded_msg is 'the refunds of the dropped deposits by the L1 gateways, emitted in the dropMessage tx of the deposit';
+
+comment
+on column refunded_msg.msg_hash is 'the message hash of the refunded deposit, resolved by the drop of the same tx';
+
+comment
+on column refunded_msg.layer1_hash is 'the dropMessage tx';
+
+comment
+on column refunded_msg.token is 'the layer1 token refunded by RefundERC20, empty for RefundETH';
+
+create unique index uk_msg_hash_refunded_msg
+on refunded_msg (msg_hash) where deleted_at IS NULL;
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON refunded_msg FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists refunded_msg;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+create table address_label
+(
+    id          BIGSERIAL PRIMARY KEY,
+    address     VARCHAR NOT NULL,
+    label       VARCHAR NOT NULL,
+    created_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at  TIMESTAMP(0) DEFAULT NULL
+);
+
+comment
+on table address_label is 'the address book of the known contracts, e.g. the gateways, the messengers and the routers, maintained by the operators';
+
+comment
+on column address_label.address is 'the checksummed address of the contract, on either layer';
+
+comment
+on column address_label.label is 'the label returned along with the address, e.g. "L1StandardERC20Gateway"';
+
+create unique index uk_address_address_label
+on address_label (address) where deleted_at IS NULL;
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON address_label FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists address_label;
+-- +goose StatementEnd
@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+create table event_checkpoint
+(
+    id           BIGSERIAL PRIMARY KEY,
+    layer        SMALLINT NOT NULL,
+    contract     VARCHAR NOT NULL,
+    event_type   VARCHAR NOT NULL,
+    start_height BIGINT NOT NULL,
+    height       BIGINT NOT NULL,
+    created_at   TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at   TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at   TIMESTAMP(0) DEFAULT NULL
+);
+
+comment
+on table event_checkpoint is 'the range of the blocks the events of each type of each contract are indexed from without a gap, updated in the transactions saving the events';
+
+comment
+on column event_checkpoint.event_type is 'the kind of the events, e.g. cross_msgs, relayed_msgs or batches';
+
+comment
+on column event_checkpoint.start_height is 'the first block of the range, the blocks before are not indexed for the contract unless backfilled';
+
+comment
+on column event_checkpoint.height is 'the last block of the range';
+
+create unique index uk_layer_contract_event_type_event_checkpoint
+on event_checkpoint (layer, contract, event_type) where deleted_at IS NULL;
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON event_checkpoint FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists event_checkpoint;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+create table admin_actions
+(
+    id          BIGSERIAL PRIMARY KEY,
+    action      VARCHAR NOT NULL,
+    target      VARCHAR NOT NULL,
+    actor       VARCHAR NOT NULL DEFAULT '',
+    affected    BIGINT NOT NULL DEFAULT 0,
+    error       VARCHAR NOT NULL DEFAULT '',
+    created_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at  TIMESTAMP(0) DEFAULT NULL
+);
+
+comment
+on table admin_actions is 'the audit log of the corrections run by the operators through the admin apis, the failed ones included';
+
+comment
+on column admin_actions.action is 'the correction, refetch_tx, recompute_proof, reenrich_msg or delete_row';
+
+comment
+on column admin_actions.target is 'the tx hash, the message hash or the table and the id of the row corrected';
+
+comment
+on column admin_actions.actor is 'the client ip of the request';
+
+comment
+on column admin_actions.error is 'the failure of the action, empty if it succeeded';
+
+create index idx_created_at_admin_actions
+on admin_actions (created_at);
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON admin_actions FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists admin_actions;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE cross_message
+    ADD COLUMN l1_data_fee VARCHAR NOT NULL DEFAULT '';
+
+comment
+on column cross_message.l1_data_fee is 'the layer1 data fee in wei paid by the layer2 tx sending the withdrawal on top of its gas fee, empty for the deposits and for the messages indexed before the column is added';
+
+-- the archived messages are copied column by column, the archive keeps the columns of cross_message in order
+ALTER TABLE cross_message_archive
+    ADD COLUMN l1_data_fee VARCHAR NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE cross_message_archive
+    DROP COLUMN IF EXISTS l1_data_fee;
+
+ALTER TABLE cross_message
+    DROP COLUMN IF EXISTS l1_data_fee;
+-- +goose StatementEnd
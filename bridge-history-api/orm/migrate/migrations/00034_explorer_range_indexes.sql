@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the explorer apis list the messages of all addresses by block timestamp and the withdrawals by the block range of a batch
+CREATE INDEX idx_block_timestamp_msg_type_cross_message ON cross_message (block_timestamp, msg_type, deleted_at);
+
+CREATE INDEX idx_height_deleted_at_l2_sent_msg ON l2_sent_msg (height, deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_height_deleted_at_l2_sent_msg;
+
+DROP INDEX IF EXISTS idx_block_timestamp_msg_type_cross_message;
+-- +goose StatementEnd
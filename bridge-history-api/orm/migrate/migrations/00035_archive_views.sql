@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+-- the address, the relayer and the explorer listings read the messages of both the hot tables and their archive.
+-- The backfill of an archived range indexes its messages into the hot tables again, the archived copies of the
+-- messages found in the hot tables are left out so that they are listed once. The migrations adding columns to the
+-- hot tables must recreate both views.
+create view cross_message_all as
+select * from cross_message
+union all
+select * from cross_message_archive a
+where not exists (select 1 from cross_message c where c.msg_hash = a.msg_hash and c.msg_type = a.msg_type and c.deleted_at is null);
+
+drop view if exists l2_sent_msg_all;
+
+create view l2_sent_msg_all as
+select * from l2_sent_msg
+union all
+select * from l2_sent_msg_archive a
+where not exists (select 1 from l2_sent_msg s where s.msg_hash = a.msg_hash and s.deleted_at is null);
+
+-- the archive holds most of the messages of the old addresses
+CREATE INDEX idx_sender_archive ON cross_message_archive (sender, deleted_at);
+
+CREATE INDEX idx_sender_l2_sent_msg_archive ON l2_sent_msg_archive (sender, deleted_at);
+
+CREATE INDEX idx_original_sender_l2_sent_msg_archive ON l2_sent_msg_archive (original_sender, deleted_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_original_sender_l2_sent_msg_archive;
+
+DROP INDEX IF EXISTS idx_sender_l2_sent_msg_archive;
+
+DROP INDEX IF EXISTS idx_sender_archive;
+
+drop view if exists l2_sent_msg_all;
+
+create view l2_sent_msg_all as
+select * from l2_sent_msg
+union all
+select * from l2_sent_msg_archive;
+
+drop view if exists cross_message_all;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+create table event_outbox
+(
+    id         BIGSERIAL PRIMARY KEY,
+    topic      VARCHAR NOT NULL,
+    msg_key    VARCHAR NOT NULL,
+    payload    VARCHAR NOT NULL,
+    created_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+comment
+on table event_outbox is 'the indexed bridge events queued for the message broker of the publisher, inserted in the transactions saving the events and published in the order of the id';
+
+comment
+on column event_outbox.msg_key is 'the message hash, or the batch hash of the batch finalizations';
+
+comment
+on column event_outbox.payload is 'the json of the event';
+
+create table event_outbox_cursor
+(
+    id         SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    last_id    BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP(0) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+comment
+on table event_outbox_cursor is 'the id of the last event of event_outbox accepted by the message broker, a single row';
+
+insert into event_outbox_cursor (id, last_id) values (1, 0);
+
+CREATE TRIGGER update_timestamp BEFORE UPDATE
+ON event_outbox_cursor FOR EACH ROW EXECUTE PROCEDURE
+update_timestamp();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table if exists event_outbox_cursor;
+drop table if exists event_outbox;
+-- +goose StatementEnd
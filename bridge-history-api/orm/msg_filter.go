@@ -0,0 +1,203 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// MsgStatus the lifecycle status of a merged deposit or withdrawal to filter by, the same as the tx status of the apis
+// except that a withdrawal is claimable once its proof is generated
+type MsgStatus int
+
+const (
+	// MsgStatusPending = 0, the deposit is neither relayed nor failed nor dropped, or the withdrawal is neither claimed nor proven
+	MsgStatusPending MsgStatus = iota
+	// MsgStatusRelayed = 1, the deposit is relayed on layer2
+	MsgStatusRelayed
+	// MsgStatusFailed = 2, the deposit is neither relayed nor dropped and a relay of it failed on layer2
+	MsgStatusFailed
+	// MsgStatusClaimable = 3, the withdrawal is not claimed and its proof is generated
+	MsgStatusClaimable
+	// MsgStatusClaimed = 4, the withdrawal is relayed on layer1
+	MsgStatusClaimed
+	// MsgStatusDropped = 5, the deposit is not relayed and it is dropped on layer1 without a refund indexed
+	MsgStatusDropped
+	// MsgStatusRefunded = 6, the deposit is not relayed and its gateway refunded it on layer1
+	MsgStatusRefunded
+)
+
+// MsgFilter filters the merged deposits and withdrawals, the filters are combined with AND and the values of a filter
+// with OR. The zero value of a filter doesn't filter.
+type MsgFilter struct {
+	// Addresses the senders of the messages, the withdrawals sent through a gateway also match their original sender
+	Addresses []string
+	// TxHashes the hashes of the txs sending the messages on the source layer
+	TxHashes []string
+	// Tokens the layer1 or layer2 addresses of the bridged tokens
+	Tokens []string
+	// MsgType Layer1Msg selects the deposits only and Layer2Msg the withdrawals only
+	MsgType  MsgType
+	Statuses []MsgStatus
+	Range    *MsgRange
+}
+
+// the conditions of the statuses of the deposits, the table of the deposits isn't aliased
+var depositStatusConditions = func() map[MsgStatus]string {
+	relayed := "EXISTS (SELECT 1 FROM relayed_msg AS r WHERE r.msg_hash = cross_message.msg_hash AND r.deleted_at IS NULL)"
+	failed := "EXISTS (SELECT 1 FROM failed_relayed_msg AS f WHERE f.msg_hash = cross_message.msg_hash AND f.deleted_at IS NULL)"
+	dropped := "EXISTS (SELECT 1 FROM dropped_msg AS d WHERE d.msg_hash = cross_message.msg_hash AND d.deleted_at IS NULL)"
+	refunded := "EXISTS (SELECT 1 FROM refunded_msg AS rf WHERE rf.msg_hash = cross_message.msg_hash AND rf.deleted_at IS NULL)"
+	return map[MsgStatus]string{
+		MsgStatusPending:  "NOT " + relayed + " AND NOT " + failed + " AND NOT " + dropped,
+		MsgStatusRelayed:  relayed,
+		MsgStatusFailed:   "NOT " + relayed + " AND " + failed + " AND NOT " + dropped,
+		MsgStatusDropped:  "NOT " + relayed + " AND " + dropped + " AND NOT " + refunded,
+		MsgStatusRefunded: "NOT " + relayed + " AND " + refunded,
+	}
+}()
+
+// the conditions of the statuses of the withdrawals, the l2_sent_msg of the withdrawals is aliased s
+var withdrawalStatusConditions = func() map[MsgStatus]string {
+	claimed := "EXISTS (SELECT 1 FROM relayed_msg AS r WHERE r.msg_hash = s.msg_hash AND r.deleted_at IS NULL)"
+	return map[MsgStatus]string{
+		MsgStatusPending:   "NOT " + claimed + " AND s.msg_proof = ''",
+		MsgStatusClaimable: "NOT " + claimed + " AND s.msg_proof != ''",
+		MsgStatusClaimed:   claimed,
+	}
+}()
+
+// statusCondition returns the condition selecting the messages in one of the statuses out of the conditions of each
+// status, no message is selected if none of the statuses has a condition
+func statusCondition(statuses []MsgStatus, conditions map[MsgStatus]string) string {
+	var matched []string
+	for _, status := range statuses {
+		if condition, ok := conditions[status]; ok {
+			matched = append(matched, "("+condition+")")
+		}
+	}
+	if len(matched) == 0 {
+		return "FALSE"
+	}
+	return "(" + strings.Join(matched, " OR ") + ")"
+}
+
+// deposits adds the conditions of the filter on the layer1 deposits to the query of the cross_message table
+func (f *MsgFilter) deposits(db *gorm.DB) *gorm.DB {
+	db = db.Where("msg_type = ? AND deleted_at IS NULL", Layer1Msg)
+	if len(f.Addresses) != 0 {
+		db = db.Where("sender IN (?)", f.Addresses)
+	}
+	if len(f.Statuses) != 0 {
+		db = db.Where(statusCondition(f.Statuses, depositStatusConditions))
+	}
+	return db
+}
+
+// withdrawals adds the conditions of the filter on the layer2 withdrawals to the query of l2_sent_msg aliased s
+func (f *MsgFilter) withdrawals(db *gorm.DB) *gorm.DB {
+	db = db.Where("s.deleted_at IS NULL")
+	if len(f.Addresses) != 0 {
+		db = db.Where("(s.original_sender IN (?) OR s.sender IN (?))", f.Addresses, f.Addresses)
+	}
+	if len(f.Statuses) != 0 {
+		db = db.Where(statusCondition(f.Statuses, withdrawalStatusConditions))
+	}
+	return db
+}
+
+// where adds the conditions of the filter shared by the deposits and the withdrawals to the query of the merged data set
+func (f *MsgFilter) where(db *gorm.DB) *gorm.DB {
+	if len(f.TxHashes) != 0 {
+		db = db.Where("(layer1_hash IN (?) OR layer2_hash IN (?))", f.TxHashes, f.TxHashes)
+	}
+	if len(f.Tokens) != 0 {
+		db = db.Where("(layer1_token IN (?) OR layer2_token IN (?))", f.Tokens, f.Tokens)
+	}
+	return f.Range.where(db)
+}
+
+// filteredMsgsQuery merges the layer1 deposits and the layer2 withdrawals matching the filter into one data set, only
+// the direction selected by the filter is queried
+func (c *CrossMsg) filteredMsgsQuery(ctx context.Context, filter *MsgFilter) *gorm.DB {
+	deposits := filter.deposits(c.depositMsgsQuery(ctx))
+	withdrawals := filter.withdrawals(c.withdrawalMsgsQuery(ctx))
+
+	var merged *gorm.DB
+	switch filter.MsgType {
+	case Layer1Msg:
+		merged = c.db.WithContext(ctx).Table("(?) AS filtered", deposits)
+	case Layer2Msg:
+		merged = c.db.WithContext(ctx).Table("(?) AS filtered", withdrawals)
+	default:
+		merged = c.db.WithContext(ctx).Table("(? UNION ALL ?) AS filtered", deposits, withdrawals)
+	}
+	return filter.where(merged)
+}
+
+// GetTotalMsgCountByFilter get the total count of the merged deposits and withdrawals matching the filter
+func (c *CrossMsg) GetTotalMsgCountByFilter(ctx context.Context, filter *MsgFilter) (uint64, error) {
+	var count int64
+	err := c.filteredMsgsQuery(ctx, filter).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("CrossMsg.GetTotalMsgCountByFilter error: %w", err)
+	}
+	return uint64(count), nil
+}
+
+// GetMsgsByFilterWithOffset get the merged deposits and withdrawals matching the filter sorted by block timestamp in the
+// given order, the height and the msg hash break the ties
+func (c *CrossMsg) GetMsgsByFilterWithOffset(ctx context.Context, filter *MsgFilter, order SortOrder, offset int, limit int) ([]*CrossMsg, error) {
+	var messages []*CrossMsg
+	// soft deleted rows are already excluded in the sub queries
+	err := c.filteredMsgsQuery(ctx, filter).Unscoped().
+		Order(order.orderBy("block_timestamp", "height", "msg_hash")).
+		Limit(limit).
+		Offset(offset).
+		Find(&messages).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("CrossMsg.GetMsgsByFilterWithOffset error: %w", err)
+	}
+	return messages, nil
+}
+
+// StreamMsgsByFilter streams the merged deposits and withdrawals matching the filter in the order of
+// GetMsgsByFilterWithOffset from the offset on, they are read from the db cursor and passed to fn in batches of
+// batchSize. The streaming stops at the first error of fn, which is returned as is.
+func (c *CrossMsg) StreamMsgsByFilter(ctx context.Context, filter *MsgFilter, order SortOrder, offset int, batchSize int, fn func([]*CrossMsg) error) error {
+	// soft deleted rows are already excluded in the sub queries
+	db := c.filteredMsgsQuery(ctx, filter).Unscoped().
+		Order(order.orderBy("block_timestamp", "height", "msg_hash")).
+		Offset(offset)
+	rows, err := db.Rows()
+	if err != nil {
+		return fmt.Errorf("CrossMsg.StreamMsgsByFilter error: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	batch := make([]*CrossMsg, 0, batchSize)
+	for rows.Next() {
+		var message CrossMsg
+		if err = db.ScanRows(rows, &message); err != nil {
+			return fmt.Errorf("CrossMsg.StreamMsgsByFilter error: %w", err)
+		}
+		batch = append(batch, &message)
+		if len(batch) < batchSize {
+			continue
+		}
+		if err = fn(batch); err != nil {
+			return err
+		}
+		batch = make([]*CrossMsg, 0, batchSize)
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("CrossMsg.StreamMsgsByFilter error: %w", err)
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return fn(batch)
+}
@@ -0,0 +1,102 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RefundedMsg is the struct for refunded_msg table, the refund of a dropped deposit by the L1 gateway it's sent
+// through. The token is empty for the refunds of ETH.
+type RefundedMsg struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID         uint64         `json:"id" gorm:"column:id"`
+	MsgHash    string         `json:"msg_hash" gorm:"column:msg_hash"`
+	Height     uint64         `json:"height" gorm:"column:height"`
+	Layer1Hash string         `json:"layer1_hash" gorm:"column:layer1_hash"`
+	Token      string         `json:"token" gorm:"column:token"`
+	Recipient  string         `json:"recipient" gorm:"column:recipient"`
+	Amount     string         `json:"amount" gorm:"column:amount"`
+	CreatedAt  *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt  *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewRefundedMsg create an RefundedMsg instance
+func NewRefundedMsg(db *gorm.DB) *RefundedMsg {
+	return &RefundedMsg{db: db}
+}
+
+// TableName returns the table name for the RefundedMsg model.
+func (*RefundedMsg) TableName() string {
+	return "refunded_msg"
+}
+
+// GetRefundedMsgsByHashes get the refunds of the deposits of the msg hashes
+func (r *RefundedMsg) GetRefundedMsgsByHashes(ctx context.Context, msgHashes []string) ([]*RefundedMsg, error) {
+	var results []*RefundedMsg
+	err := r.db.WithContext(ctx).Model(&RefundedMsg{}).
+		Where("msg_hash IN (?)", msgHashes).
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("RefundedMsg.GetRefundedMsgsByHashes error: %w", err)
+	}
+	return results, nil
+}
+
+// GetLatestRefundedHeight get the height of the latest refund
+func (r *RefundedMsg) GetLatestRefundedHeight(ctx context.Context) (uint64, error) {
+	var result RefundedMsg
+	err := r.db.WithContext(ctx).Model(&RefundedMsg{}).
+		Select("height").
+		Order("height DESC").
+		First(&result).
+		Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("RefundedMsg.GetLatestRefundedHeight error: %w", err)
+	}
+	return result.Height, nil
+}
+
+// InsertRefundedMsg batch insert refunded msg into db, the refunds of the msg hashes saved already are skipped
+func (r *RefundedMsg) InsertRefundedMsg(ctx context.Context, messages []*RefundedMsg, dbTx ...*gorm.DB) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	db := r.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Model(&RefundedMsg{}).Clauses(clause.OnConflict{DoNothing: true}).Create(&messages).Error
+	if err != nil {
+		msgHashes := make([]string, 0, len(messages))
+		for _, msg := range messages {
+			msgHashes = append(msgHashes, msg.MsgHash)
+		}
+		log.Error("failed to insert refunded messages", "msg hashes", msgHashes, "err", err)
+		return fmt.Errorf("RefundedMsg.InsertRefundedMsg error: %w", err)
+	}
+	return nil
+}
+
+// DeleteRefundedMsgAfterHeight delete the refunds after height
+func (r *RefundedMsg) DeleteRefundedMsgAfterHeight(ctx context.Context, height uint64, dbTx ...*gorm.DB) error {
+	db := r.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Delete(&RefundedMsg{}, "height > ?", height).Error
+	if err != nil {
+		return fmt.Errorf("RefundedMsg.DeleteRefundedMsgAfterHeight error: %w", err)
+	}
+	return nil
+}
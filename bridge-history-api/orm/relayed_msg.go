@@ -7,8 +7,19 @@ import (
 
 	"github.com/ethereum/go-ethereum/log"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// RelayTiming the block timestamps of a relay and of the cross message it relays, MsgFound is false if the cross
+// message isn't indexed
+type RelayTiming struct {
+	ID             uint64     `gorm:"column:id"`
+	RelayTimestamp *time.Time `gorm:"column:relay_timestamp"`
+	MsgFound       bool       `gorm:"column:msg_found"`
+	MsgTimestamp   *time.Time `gorm:"column:msg_timestamp"`
+	CreatedAt      *time.Time `gorm:"column:created_at"`
+}
+
 // RelayedMsg is the struct for relayed_msg table
 type RelayedMsg struct {
 	db *gorm.DB `gorm:"column:-"`
@@ -18,6 +29,10 @@ type RelayedMsg struct {
 	Height     uint64         `json:"height" gorm:"column:height"`
 	Layer1Hash string         `json:"layer1_hash" gorm:"column:layer1_hash;default:''"`
 	Layer2Hash string         `json:"layer2_hash" gorm:"column:layer2_hash;default:''"`
+	Delivered  bool           `json:"delivered" gorm:"column:delivered;default:false"`
+	Relayer    string         `json:"relayer" gorm:"column:relayer;default:''"`
+	GasFee     string         `json:"gas_fee" gorm:"column:gas_fee;default:''"`
+	Timestamp  *time.Time     `json:"timestamp" gorm:"column:block_timestamp;default:NULL"`
 	CreatedAt  *time.Time     `json:"created_at" gorm:"column:created_at"`
 	UpdatedAt  *time.Time     `json:"updated_at" gorm:"column:updated_at"`
 	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
@@ -80,6 +95,58 @@ func (r *RelayedMsg) GetLatestRelayedHeightOnL1(ctx context.Context) (uint64, er
 	return result.Height, err
 }
 
+// GetL1EarliestNoBlockTimestampHeight returns the earliest height of the relays on layer1 which have no block timestamp
+func (r *RelayedMsg) GetL1EarliestNoBlockTimestampHeight(ctx context.Context) (uint64, error) {
+	var result RelayedMsg
+	err := r.db.WithContext(ctx).Model(&RelayedMsg{}).
+		Where("block_timestamp IS NULL AND layer1_hash != ''").
+		Select("height").
+		Order("height ASC").
+		First(&result).
+		Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("RelayedMsg.GetL1EarliestNoBlockTimestampHeight error: %w", err)
+	}
+	return result.Height, nil
+}
+
+// UpdateL1BlockTimestamp updates the block timestamp of the relays on layer1 at the given height
+func (r *RelayedMsg) UpdateL1BlockTimestamp(ctx context.Context, height uint64, timestamp time.Time) error {
+	err := r.db.WithContext(ctx).Model(&RelayedMsg{}).
+		Where("height = ? AND layer1_hash != ''", height).
+		Update("block_timestamp", timestamp).Error
+	if err != nil {
+		return fmt.Errorf("RelayedMsg.UpdateL1BlockTimestamp error: %w", err)
+	}
+	return nil
+}
+
+// GetRelayTimingsAfterID returns the timings of at most limit relays with an id greater than afterID of the cross
+// messages of the msg type ordered by id, the deposits of Layer1Msg are relayed on layer2 and the withdrawals of
+// Layer2Msg on layer1
+func (r *RelayedMsg) GetRelayTimingsAfterID(ctx context.Context, msgType MsgType, afterID uint64, limit int) ([]*RelayTiming, error) {
+	relayedOn := "r.layer2_hash != ''"
+	if msgType == Layer2Msg {
+		relayedOn = "r.layer1_hash != ''"
+	}
+	var results []*RelayTiming
+	err := r.db.WithContext(ctx).Table("relayed_msg AS r").
+		Select("r.id, r.block_timestamp AS relay_timestamp, c.id IS NOT NULL AS msg_found, c.block_timestamp AS msg_timestamp, r.created_at").
+		Joins("LEFT JOIN cross_message AS c ON c.msg_hash = r.msg_hash AND c.msg_type = ? AND c.deleted_at IS NULL", msgType).
+		Where("r.id > ? AND "+relayedOn+" AND r.deleted_at IS NULL", afterID).
+		Order("r.id ASC").
+		Limit(limit).
+		Scan(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("RelayedMsg.GetRelayTimingsAfterID error: %w", err)
+	}
+	return results, nil
+}
+
 // GetLatestRelayedHeightOnL2 get latest relayed height on l2
 func (r *RelayedMsg) GetLatestRelayedHeightOnL2(ctx context.Context) (uint64, error) {
 	var result RelayedMsg
@@ -98,7 +165,37 @@ func (r *RelayedMsg) GetLatestRelayedHeightOnL2(ctx context.Context) (uint64, er
 	return result.Height, nil
 }
 
-// InsertRelayedMsg batch insert relayed msg into db and return the transaction
+// GetL2EarliestNoBlockTimestampHeight returns the earliest height of the relays on layer2 which have no block timestamp
+func (r *RelayedMsg) GetL2EarliestNoBlockTimestampHeight(ctx context.Context) (uint64, error) {
+	var result RelayedMsg
+	err := r.db.WithContext(ctx).Model(&RelayedMsg{}).
+		Where("block_timestamp IS NULL AND layer2_hash != ''").
+		Select("height").
+		Order("height ASC").
+		First(&result).
+		Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("RelayedMsg.GetL2EarliestNoBlockTimestampHeight error: %w", err)
+	}
+	return result.Height, nil
+}
+
+// UpdateL2BlockTimestamp updates the block timestamp of the relays on layer2 at the given height
+func (r *RelayedMsg) UpdateL2BlockTimestamp(ctx context.Context, height uint64, timestamp time.Time) error {
+	err := r.db.WithContext(ctx).Model(&RelayedMsg{}).
+		Where("height = ? AND layer2_hash != ''", height).
+		Update("block_timestamp", timestamp).Error
+	if err != nil {
+		return fmt.Errorf("RelayedMsg.UpdateL2BlockTimestamp error: %w", err)
+	}
+	return nil
+}
+
+// InsertRelayedMsg batch insert relayed msg into db and return the transaction, the relays saved already are
+// skipped
 func (r *RelayedMsg) InsertRelayedMsg(ctx context.Context, messages []*RelayedMsg, dbTx ...*gorm.DB) error {
 	if len(messages) == 0 {
 		return nil
@@ -108,7 +205,7 @@ func (r *RelayedMsg) InsertRelayedMsg(ctx context.Context, messages []*RelayedMs
 		db = dbTx[0]
 	}
 	db.WithContext(ctx)
-	err := db.Model(&RelayedMsg{}).Create(&messages).Error
+	err := db.Model(&RelayedMsg{}).Clauses(clause.OnConflict{DoNothing: true}).Create(&messages).Error
 	if err != nil {
 		l2hashes := make([]string, 0, len(messages))
 		l1hashes := make([]string, 0, len(messages))
@@ -124,15 +221,31 @@ func (r *RelayedMsg) InsertRelayedMsg(ctx context.Context, messages []*RelayedMs
 	return nil
 }
 
+// GetRelayedMsgsAfterHeight returns the relayed msgs on the layer after given height, e.g. the ones a reorg deletes
+func (r *RelayedMsg) GetRelayedMsgsAfterHeight(ctx context.Context, layer MsgType, height uint64, dbTx ...*gorm.DB) ([]*RelayedMsg, error) {
+	db := r.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	hashColumn := "layer2_hash"
+	if layer == Layer1Msg {
+		hashColumn = "layer1_hash"
+	}
+	var results []*RelayedMsg
+	err := db.WithContext(ctx).Model(&RelayedMsg{}).Where("height > ? AND "+hashColumn+" != ''", height).Find(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("RelayedMsg.GetRelayedMsgsAfterHeight error: %w", err)
+	}
+	return results, nil
+}
+
 // DeleteL1RelayedHashAfterHeight delete l1 relayed hash after height
 func (r *RelayedMsg) DeleteL1RelayedHashAfterHeight(ctx context.Context, height uint64, dbTx ...*gorm.DB) error {
 	db := r.db
 	if len(dbTx) > 0 && dbTx[0] != nil {
 		db = dbTx[0]
 	}
-	db.WithContext(ctx)
-	err := db.Model(&RelayedMsg{}).
-		Delete("height > ? AND layer1_hash != ''", height).Error
+	err := db.WithContext(ctx).Delete(&RelayedMsg{}, "height > ? AND layer1_hash != ''", height).Error
 	if err != nil {
 		return fmt.Errorf("RelayedMsg.DeleteL1RelayedHashAfterHeight error: %w", err)
 	}
@@ -145,11 +258,35 @@ func (r *RelayedMsg) DeleteL2RelayedHashAfterHeight(ctx context.Context, height
 	if len(dbTx) > 0 && dbTx[0] != nil {
 		db = dbTx[0]
 	}
-	db.WithContext(ctx)
-	err := db.Model(&RelayedMsg{}).
-		Delete("height > ? AND layer2_hash != ''", height).Error
+	err := db.WithContext(ctx).Delete(&RelayedMsg{}, "height > ? AND layer2_hash != ''", height).Error
 	if err != nil {
 		return fmt.Errorf("RelayedMsg.DeleteL2RelayedHashAfterHeight error: %w", err)
 	}
 	return nil
 }
+
+// GetRelayedMsgByID get the relayed msg of the id, nil is returned if it doesn't exist
+func (r *RelayedMsg) GetRelayedMsgByID(ctx context.Context, id uint64) (*RelayedMsg, error) {
+	var result RelayedMsg
+	err := r.db.WithContext(ctx).Model(&RelayedMsg{}).Where("id = ?", id).First(&result).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("RelayedMsg.GetRelayedMsgByID error: %w", err)
+	}
+	return &result, nil
+}
+
+// DeleteRelayedMsgByID soft delete the relayed msg of the id, the number of the msgs deleted is returned
+func (r *RelayedMsg) DeleteRelayedMsgByID(ctx context.Context, id uint64, dbTx ...*gorm.DB) (int64, error) {
+	db := r.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	result := db.WithContext(ctx).Delete(&RelayedMsg{}, "id = ?", id)
+	if result.Error != nil {
+		return 0, fmt.Errorf("RelayedMsg.DeleteRelayedMsgByID error: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,101 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReplayedMsg is the struct for replayed_msg table, a replay of a deposit appending its message to the L1MessageQueue
+// again with a new gas limit
+type ReplayedMsg struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID         uint64         `json:"id" gorm:"column:id"`
+	MsgHash    string         `json:"msg_hash" gorm:"column:msg_hash"`
+	QueueIndex uint64         `json:"queue_index" gorm:"column:queue_index"`
+	Height     uint64         `json:"height" gorm:"column:height"`
+	Layer1Hash string         `json:"layer1_hash" gorm:"column:layer1_hash"`
+	CreatedAt  *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt  *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt  gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewReplayedMsg create an ReplayedMsg instance
+func NewReplayedMsg(db *gorm.DB) *ReplayedMsg {
+	return &ReplayedMsg{db: db}
+}
+
+// TableName returns the table name for the ReplayedMsg model.
+func (*ReplayedMsg) TableName() string {
+	return "replayed_msg"
+}
+
+// GetReplayedMsgsByHashes get the replays of the deposits of the msg hashes, the latest replay of each first
+func (r *ReplayedMsg) GetReplayedMsgsByHashes(ctx context.Context, msgHashes []string) ([]*ReplayedMsg, error) {
+	var results []*ReplayedMsg
+	err := r.db.WithContext(ctx).Model(&ReplayedMsg{}).
+		Where("msg_hash IN (?)", msgHashes).
+		Order("queue_index DESC").
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("ReplayedMsg.GetReplayedMsgsByHashes error: %w", err)
+	}
+	return results, nil
+}
+
+// GetLatestReplayedHeight get the height of the latest replay
+func (r *ReplayedMsg) GetLatestReplayedHeight(ctx context.Context) (uint64, error) {
+	var result ReplayedMsg
+	err := r.db.WithContext(ctx).Model(&ReplayedMsg{}).
+		Select("height").
+		Order("height DESC").
+		First(&result).
+		Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("ReplayedMsg.GetLatestReplayedHeight error: %w", err)
+	}
+	return result.Height, nil
+}
+
+// InsertReplayedMsg batch insert replayed msg into db, the replays of the queue indexes saved already are skipped
+func (r *ReplayedMsg) InsertReplayedMsg(ctx context.Context, messages []*ReplayedMsg, dbTx ...*gorm.DB) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	db := r.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Model(&ReplayedMsg{}).Clauses(clause.OnConflict{DoNothing: true}).Create(&messages).Error
+	if err != nil {
+		queueIndexes := make([]uint64, 0, len(messages))
+		for _, msg := range messages {
+			queueIndexes = append(queueIndexes, msg.QueueIndex)
+		}
+		log.Error("failed to insert replayed messages", "queue indexes", queueIndexes, "err", err)
+		return fmt.Errorf("ReplayedMsg.InsertReplayedMsg error: %w", err)
+	}
+	return nil
+}
+
+// DeleteReplayedMsgAfterHeight delete the replays after height
+func (r *ReplayedMsg) DeleteReplayedMsgAfterHeight(ctx context.Context, height uint64, dbTx ...*gorm.DB) error {
+	db := r.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Delete(&ReplayedMsg{}, "height > ?", height).Error
+	if err != nil {
+		return fmt.Errorf("ReplayedMsg.DeleteReplayedMsgAfterHeight error: %w", err)
+	}
+	return nil
+}
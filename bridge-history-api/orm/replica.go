@@ -0,0 +1,146 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+const (
+	// replicaLagCheckInterval is the interval the replication lag of the replicas is checked at
+	replicaLagCheckInterval = 5 * time.Second
+	// replicaLagCheckTimeout bounds the check of the replication lag of each replica
+	replicaLagCheckTimeout = 2 * time.Second
+)
+
+// replicationLagSQL returns the seconds the replica lags behind the primary, 0 if it replayed all the wal received
+// since the replay timestamp of an idle primary doesn't move forward
+const replicationLagSQL = `SELECT COALESCE(CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END, 0)`
+
+// UseReplicas routes the reads of db to the replicas, the writes and the transactions stay on the primary. A replica
+// lagging behind the primary more than maxLag, or failing to report its lag, is skipped until it catches up, and the
+// reads fall back to the primary if all the replicas are skipped. The reads stick to the primary for maxLag after each
+// write through db, so that the writes are read back.
+func UseReplicas(db *gorm.DB, replicas []*sql.DB, maxLag time.Duration) error {
+	if len(replicas) == 0 {
+		return nil
+	}
+	policy := &replicaPolicy{
+		primary:   db.Config.ConnPool,
+		maxLag:    maxLag,
+		now:       time.Now,
+		readLag:   readReplicationLag,
+		replicas:  make([]gorm.ConnPool, 0, len(replicas)),
+		healthy:   make(map[gorm.ConnPool]bool, len(replicas)),
+		lastCheck: time.Now(),
+	}
+	// the primary is one of the pools of the reads, the resolver only consults the policy with more than one pool
+	dialectors := []gorm.Dialector{postgres.New(postgres.Config{Conn: db.Config.ConnPool})}
+	for _, replica := range replicas {
+		dialectors = append(dialectors, postgres.New(postgres.Config{Conn: replica}))
+		policy.replicas = append(policy.replicas, replica)
+	}
+	policy.checkLag()
+	if err := db.Use(dbresolver.Register(dbresolver.Config{Replicas: dialectors, Policy: policy})); err != nil {
+		return err
+	}
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().After("gorm:create").Register("replica:after_create", policy.afterWrite),
+		callbacks.Update().After("gorm:update").Register("replica:after_update", policy.afterWrite),
+		callbacks.Delete().After("gorm:delete").Register("replica:after_delete", policy.afterWrite),
+		callbacks.Raw().After("gorm:raw").Register("replica:after_raw", policy.afterRaw),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replicaPolicy resolves the reads to the replicas not lagging behind, in turn. The lag is checked in the background
+// of the reads once the last check is older than replicaLagCheckInterval.
+type replicaPolicy struct {
+	primary  gorm.ConnPool
+	replicas []gorm.ConnPool
+	maxLag   time.Duration
+	now      func() time.Time
+	readLag  func(ctx context.Context, replica gorm.ConnPool) (time.Duration, error)
+
+	mu        sync.Mutex
+	healthy   map[gorm.ConnPool]bool
+	lastCheck time.Time
+	checking  bool
+	lastWrite time.Time
+	next      int
+}
+
+// Resolve implements dbresolver.Policy
+func (p *replicaPolicy) Resolve([]gorm.ConnPool) gorm.ConnPool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	if !p.checking && now.Sub(p.lastCheck) >= replicaLagCheckInterval {
+		p.checking = true
+		go p.checkLag()
+	}
+	if now.Sub(p.lastWrite) < p.maxLag {
+		return p.primary
+	}
+	for i := 0; i < len(p.replicas); i++ {
+		replica := p.replicas[(p.next+i)%len(p.replicas)]
+		if p.healthy[replica] {
+			p.next = (p.next + i + 1) % len(p.replicas)
+			return replica
+		}
+	}
+	return p.primary
+}
+
+// checkLag updates which replicas lag behind the primary more than maxLag
+func (p *replicaPolicy) checkLag() {
+	healthy := make(map[gorm.ConnPool]bool, len(p.replicas))
+	for i, replica := range p.replicas {
+		ctx, cancel := context.WithTimeout(context.Background(), replicaLagCheckTimeout)
+		lag, err := p.readLag(ctx, replica)
+		cancel()
+		healthy[replica] = err == nil && lag <= p.maxLag
+		if err != nil {
+			log.Warn("failed to check the replication lag, the replica is skipped", "replica", i, "err", err)
+		} else if lag > p.maxLag {
+			log.Warn("the replica lags behind the primary, it's skipped", "replica", i, "lag", lag)
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.healthy, p.lastCheck, p.checking = healthy, p.now(), false
+}
+
+func (p *replicaPolicy) afterWrite(*gorm.DB) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lastWrite = p.now()
+}
+
+// afterRaw stamps the raw statements but the selects as writes
+func (p *replicaPolicy) afterRaw(db *gorm.DB) {
+	if !strings.HasPrefix(strings.ToLower(strings.TrimSpace(db.Statement.SQL.String())), "select") {
+		p.afterWrite(db)
+	}
+}
+
+func readReplicationLag(ctx context.Context, replica gorm.ConnPool) (time.Duration, error) {
+	var seconds float64
+	if err := replica.QueryRowContext(ctx, replicationLagSQL).Scan(&seconds); err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -0,0 +1,50 @@
+package orm
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestReplicaPolicy(t *testing.T) {
+	primary, replica1, replica2 := &sql.DB{}, &sql.DB{}, &sql.DB{}
+	now := time.Unix(1700000000, 0)
+	lags := map[gorm.ConnPool]time.Duration{replica1: time.Second, replica2: 3 * time.Second}
+	policy := &replicaPolicy{
+		primary:  primary,
+		replicas: []gorm.ConnPool{replica1, replica2},
+		maxLag:   2 * time.Second,
+		now:      func() time.Time { return now },
+		readLag: func(_ context.Context, replica gorm.ConnPool) (time.Duration, error) {
+			if lag, ok := lags[replica]; ok {
+				return lag, nil
+			}
+			return 0, errors.New("connection refused")
+		},
+	}
+	policy.checkLag()
+	// the lagging replica is skipped
+	assert.Equal(t, replica1, policy.Resolve(nil))
+	assert.Equal(t, replica1, policy.Resolve(nil))
+
+	lags[replica2] = 0
+	policy.checkLag()
+	assert.Equal(t, replica2, policy.Resolve(nil))
+	assert.Equal(t, replica1, policy.Resolve(nil))
+
+	// the reads stick to the primary for the max lag after a write
+	policy.afterWrite(nil)
+	assert.Equal(t, primary, policy.Resolve(nil))
+	now = now.Add(2 * time.Second)
+
+	// all the replicas are skipped, the reads fall back to the primary
+	delete(lags, replica1)
+	lags[replica2] = time.Minute
+	policy.checkLag()
+	assert.Equal(t, primary, policy.Resolve(nil))
+}
@@ -0,0 +1,95 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TokenMetadata is the struct for token_metadata table, the metadata of a bridged ERC20 token on one layer
+type TokenMetadata struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID        uint64         `json:"id" gorm:"column:id"`
+	Layer     int            `json:"layer" gorm:"column:layer"` // the layer of the token contract, Layer1Msg or Layer2Msg
+	Address   string         `json:"address" gorm:"column:address"`
+	Symbol    string         `json:"symbol" gorm:"column:symbol;default:''"`
+	Name      string         `json:"name" gorm:"column:name;default:''"`
+	Decimals  uint8          `json:"decimals" gorm:"column:decimals"`
+	LogoURI   string         `json:"logo_uri" gorm:"column:logo_uri;default:''"`
+	CreatedAt *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewTokenMetadata create a TokenMetadata instance
+func NewTokenMetadata(db *gorm.DB) *TokenMetadata {
+	return &TokenMetadata{db: db}
+}
+
+// TableName returns the table name for the TokenMetadata model.
+func (*TokenMetadata) TableName() string {
+	return "token_metadata"
+}
+
+// GetTokenMetadataByAddresses get the metadata of the tokens of the layer, the tokens without metadata are skipped
+func (t *TokenMetadata) GetTokenMetadataByAddresses(ctx context.Context, layer MsgType, addresses []string) ([]*TokenMetadata, error) {
+	var results []*TokenMetadata
+	err := t.db.WithContext(ctx).Model(&TokenMetadata{}).
+		Where("layer = ? AND address IN (?)", layer, addresses).
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("TokenMetadata.GetTokenMetadataByAddresses error: %w", err)
+	}
+	return results, nil
+}
+
+// GetTokensWithoutMetadata get at most limit ERC20 tokens of the layer bridged by the cross messages and having no
+// metadata yet, the tokens in excluded are skipped
+func (t *TokenMetadata) GetTokensWithoutMetadata(ctx context.Context, layer MsgType, excluded []string, limit int) ([]string, error) {
+	column := "layer2_token"
+	if layer == Layer1Msg {
+		column = "layer1_token"
+	}
+	db := t.db.WithContext(ctx).Table("cross_message").
+		Distinct(column).
+		Where("asset = ? AND "+column+" NOT IN (?) AND deleted_at IS NULL", ERC20, []string{"", common.Address{}.Hex()}).
+		Where("NOT EXISTS (SELECT 1 FROM token_metadata AS t WHERE t.layer = ? AND t.address = cross_message."+column+" AND t.deleted_at IS NULL)", layer)
+	if len(excluded) != 0 {
+		db = db.Where(column+" NOT IN (?)", excluded)
+	}
+	var tokens []string
+	err := db.Order(column).Limit(limit).Pluck(column, &tokens).Error
+	if err != nil {
+		return nil, fmt.Errorf("TokenMetadata.GetTokensWithoutMetadata error: %w", err)
+	}
+	return tokens, nil
+}
+
+// InsertTokenMetadata batch upsert the metadata of the tokens into db, the metadata of the tokens already saved is replaced
+func (t *TokenMetadata) InsertTokenMetadata(ctx context.Context, tokens []*TokenMetadata, dbTx ...*gorm.DB) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	db := t.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Model(&TokenMetadata{}).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "layer"}, {Name: "address"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+			DoUpdates:   clause.AssignmentColumns([]string{"symbol", "name", "decimals", "logo_uri"}),
+		}).
+		Create(&tokens).
+		Error
+	if err != nil {
+		return fmt.Errorf("TokenMetadata.InsertTokenMetadata error: %w", err)
+	}
+	return nil
+}
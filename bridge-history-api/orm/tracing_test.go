@@ -0,0 +1,43 @@
+package orm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestUseTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	// the global tracer provider, the other tests are not affected but their spans are recorded too
+	otel.SetTracerProvider(provider)
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	assert.NoError(t, err)
+	assert.NoError(t, UseTracing(db))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	_, err = NewCrossMsg(db).GetL1CrossMsgByHash(ctx, common.HexToHash("0x01"))
+	assert.NoError(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "CrossMsg.GetL1CrossMsgByHash", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	attributes := make(map[string]string)
+	for _, attribute := range spans[0].Attributes() {
+		attributes[string(attribute.Key)] = attribute.Value.Emit()
+	}
+	assert.Equal(t, "cross_message", attributes["db.sql.table"])
+	// the vars of the statement are not recorded
+	assert.NotContains(t, attributes["db.statement"], common.HexToHash("0x01").Hex())
+	assert.Contains(t, attributes["db.statement"], "SELECT")
+}
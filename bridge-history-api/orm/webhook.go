@@ -0,0 +1,118 @@
+package orm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrWebhookExists the webhook of the same address and url is already registered
+var ErrWebhookExists = errors.New("webhook already exists")
+
+// Webhook is the struct for webhook table, a callback url notified of the events of the txs of an address
+type Webhook struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID      uint64 `json:"id" gorm:"column:id"`
+	Address string `json:"address" gorm:"column:address"`
+	URL     string `json:"url" gorm:"column:url"`
+	// Secret signs the payloads delivered to the url
+	Secret    string         `json:"-" gorm:"column:secret"`
+	CreatedAt *time.Time     `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt *time.Time     `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewWebhook create a Webhook instance
+func NewWebhook(db *gorm.DB) *Webhook {
+	return &Webhook{db: db}
+}
+
+// TableName returns the table name for the Webhook model.
+func (*Webhook) TableName() string {
+	return "webhook"
+}
+
+// InsertWebhook insert the webhook into db and sets its id, ErrWebhookExists is returned if the webhook of the same
+// address and url is already saved, its secret is kept
+func (w *Webhook) InsertWebhook(ctx context.Context, webhook *Webhook) error {
+	db := w.db.WithContext(ctx).Model(&Webhook{}).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "address"}, {Name: "url"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+			DoNothing:   true,
+		}).
+		Create(webhook)
+	if db.Error != nil {
+		return fmt.Errorf("Webhook.InsertWebhook error: %w", db.Error)
+	}
+	if db.RowsAffected == 0 {
+		return fmt.Errorf("Webhook.InsertWebhook error: %w", ErrWebhookExists)
+	}
+	return nil
+}
+
+// UpdateWebhookSecret replace the secret of the webhook of the id
+func (w *Webhook) UpdateWebhookSecret(ctx context.Context, id uint64, secret string) error {
+	err := w.db.WithContext(ctx).Model(&Webhook{}).Where("id = ?", id).Update("secret", secret).Error
+	if err != nil {
+		return fmt.Errorf("Webhook.UpdateWebhookSecret error: %w", err)
+	}
+	return nil
+}
+
+// GetWebhookByID get the webhook of the id, nil is returned if it doesn't exist
+func (w *Webhook) GetWebhookByID(ctx context.Context, id uint64) (*Webhook, error) {
+	var result Webhook
+	err := w.db.WithContext(ctx).Model(&Webhook{}).Where("id = ?", id).First(&result).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("Webhook.GetWebhookByID error: %w", err)
+	}
+	return &result, nil
+}
+
+// GetWebhooksByIDs get the webhooks of the ids, the deleted webhooks are skipped
+func (w *Webhook) GetWebhooksByIDs(ctx context.Context, ids []uint64) ([]*Webhook, error) {
+	var results []*Webhook
+	err := w.db.WithContext(ctx).Model(&Webhook{}).Where("id IN (?)", ids).Find(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("Webhook.GetWebhooksByIDs error: %w", err)
+	}
+	return results, nil
+}
+
+// GetWebhooksByAddresses get the webhooks of the addresses
+func (w *Webhook) GetWebhooksByAddresses(ctx context.Context, addresses []string) ([]*Webhook, error) {
+	var results []*Webhook
+	err := w.db.WithContext(ctx).Model(&Webhook{}).Where("address IN (?)", addresses).Find(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("Webhook.GetWebhooksByAddresses error: %w", err)
+	}
+	return results, nil
+}
+
+// GetWebhookCountByAddress get the number of the webhooks of the address
+func (w *Webhook) GetWebhookCountByAddress(ctx context.Context, address string) (uint64, error) {
+	var count int64
+	err := w.db.WithContext(ctx).Model(&Webhook{}).Where("address = ?", address).Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("Webhook.GetWebhookCountByAddress error: %w", err)
+	}
+	return uint64(count), nil
+}
+
+// DeleteWebhook soft delete the webhook of the id, its pending deliveries are no longer attempted
+func (w *Webhook) DeleteWebhook(ctx context.Context, id uint64) error {
+	err := w.db.WithContext(ctx).Delete(&Webhook{}, "id = ?", id).Error
+	if err != nil {
+		return fmt.Errorf("Webhook.DeleteWebhook error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package orm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookDeliveryStatus the status of the delivery of an event to a webhook
+type WebhookDeliveryStatus int
+
+const (
+	// WebhookDeliveryPending = 0, the event is not delivered yet and is attempted again at next_attempt_at
+	WebhookDeliveryPending WebhookDeliveryStatus = iota
+	// WebhookDeliveryDelivered = 1, the webhook accepted the event
+	WebhookDeliveryDelivered
+	// WebhookDeliveryFailed = 2, all the attempts of the delivery failed, it's no longer attempted
+	WebhookDeliveryFailed
+)
+
+// WebhookDelivery is the struct for webhook_delivery table, the delivery of the event of a message to a webhook
+type WebhookDelivery struct {
+	db *gorm.DB `gorm:"column:-"`
+
+	ID            uint64                `json:"id" gorm:"column:id"`
+	WebhookID     uint64                `json:"webhook_id" gorm:"column:webhook_id"`
+	EventType     string                `json:"event_type" gorm:"column:event_type"`
+	Address       string                `json:"address" gorm:"column:address"`
+	MsgHash       string                `json:"msg_hash" gorm:"column:msg_hash"`
+	TxHash        string                `json:"tx_hash" gorm:"column:tx_hash;default:''"`
+	Status        WebhookDeliveryStatus `json:"status" gorm:"column:status;default:0"`
+	Attempts      int                   `json:"attempts" gorm:"column:attempts;default:0"`
+	NextAttemptAt *time.Time            `json:"next_attempt_at" gorm:"column:next_attempt_at;default:CURRENT_TIMESTAMP"`
+	LastError     string                `json:"last_error" gorm:"column:last_error;default:''"`
+	DeliveredAt   *time.Time            `json:"delivered_at" gorm:"column:delivered_at;default:NULL"`
+	CreatedAt     *time.Time            `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt     *time.Time            `json:"updated_at" gorm:"column:updated_at"`
+	DeletedAt     gorm.DeletedAt        `json:"deleted_at" gorm:"column:deleted_at;default:NULL"`
+}
+
+// NewWebhookDelivery create a WebhookDelivery instance
+func NewWebhookDelivery(db *gorm.DB) *WebhookDelivery {
+	return &WebhookDelivery{db: db}
+}
+
+// TableName returns the table name for the WebhookDelivery model.
+func (*WebhookDelivery) TableName() string {
+	return "webhook_delivery"
+}
+
+// InsertWebhookDeliveries batch insert the deliveries into db, the deliveries of the same event to the same webhook
+// already saved are skipped so that an event is delivered once
+func (w *WebhookDelivery) InsertWebhookDeliveries(ctx context.Context, deliveries []*WebhookDelivery, dbTx ...*gorm.DB) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	db := w.db
+	if len(dbTx) > 0 && dbTx[0] != nil {
+		db = dbTx[0]
+	}
+	err := db.WithContext(ctx).Model(&WebhookDelivery{}).
+		Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "webhook_id"}, {Name: "event_type"}, {Name: "msg_hash"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+			DoNothing:   true,
+		}).
+		Create(&deliveries).
+		Error
+	if err != nil {
+		return fmt.Errorf("WebhookDelivery.InsertWebhookDeliveries error: %w", err)
+	}
+	return nil
+}
+
+// GetDueWebhookDeliveries get at most limit pending deliveries due at now, earliest first. The deliveries of the
+// deleted webhooks are skipped.
+func (w *WebhookDelivery) GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]*WebhookDelivery, error) {
+	var results []*WebhookDelivery
+	err := w.db.WithContext(ctx).Model(&WebhookDelivery{}).
+		Where("status = ? AND next_attempt_at <= ?", WebhookDeliveryPending, now).
+		Where("EXISTS (SELECT 1 FROM webhook AS w WHERE w.id = webhook_delivery.webhook_id AND w.deleted_at IS NULL)").
+		Order("next_attempt_at ASC, id ASC").
+		Limit(limit).
+		Find(&results).
+		Error
+	if err != nil {
+		return nil, fmt.Errorf("WebhookDelivery.GetDueWebhookDeliveries error: %w", err)
+	}
+	return results, nil
+}
+
+// UpdateWebhookDelivered marks the delivery of the id delivered at the given time
+func (w *WebhookDelivery) UpdateWebhookDelivered(ctx context.Context, id uint64, attempts int, deliveredAt time.Time) error {
+	err := w.db.WithContext(ctx).Model(&WebhookDelivery{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":       WebhookDeliveryDelivered,
+			"attempts":     attempts,
+			"last_error":   "",
+			"delivered_at": deliveredAt,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("WebhookDelivery.UpdateWebhookDelivered error: %w", err)
+	}
+	return nil
+}
+
+// UpdateWebhookDeliveryAttempt records the failed attempt of the delivery of the id, the delivery is attempted again
+// at nextAttemptAt if status is pending
+func (w *WebhookDelivery) UpdateWebhookDeliveryAttempt(ctx context.Context, id uint64, status WebhookDeliveryStatus, attempts int, nextAttemptAt time.Time, lastError string) error {
+	err := w.db.WithContext(ctx).Model(&WebhookDelivery{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":          status,
+			"attempts":        attempts,
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastError,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("WebhookDelivery.UpdateWebhookDeliveryAttempt error: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"bridge-history-api/config"
+	"bridge-history-api/orm"
+)
+
+// customGatewayFieldTypes the fields of the messages the arguments of the events of the custom gateways are mapped to,
+// along with the abi types of the arguments allowed
+var customGatewayFieldTypes = map[string][]byte{
+	"sender":       {abi.AddressTy},
+	"target":       {abi.AddressTy},
+	"amount":       {abi.UintTy, abi.IntTy},
+	"l1Token":      {abi.AddressTy},
+	"l2Token":      {abi.AddressTy},
+	"tokenIds":     {abi.UintTy, abi.SliceTy},
+	"tokenAmounts": {abi.UintTy, abi.SliceTy},
+}
+
+// customGatewayAssets the assets of the custom gateway configs
+var customGatewayAssets = map[string]orm.AssetType{
+	"ETH":     orm.ETH,
+	"ERC20":   orm.ERC20,
+	"ERC721":  orm.ERC721,
+	"ERC1155": orm.ERC1155,
+}
+
+// CustomGateways decodes the bridge events of the custom gateways of a layer into the cross messages, by the ABIs and
+// the field mappings of their configs. A nil CustomGateways decodes nothing.
+type CustomGateways struct {
+	gateways map[common.Address]*customGateway
+}
+
+// customGateway a custom gateway of the config, validated
+type customGateway struct {
+	name    string
+	event   abi.Event
+	indexed abi.Arguments
+	asset   orm.AssetType
+	// fields maps the fields of the messages to the arguments of the event
+	fields  map[string]string
+	l1Token string
+	l2Token string
+}
+
+// NewCustomGateways validates the custom gateways of a layer, nil is returned if there is none
+func NewCustomGateways(cfgs []*config.CustomGatewayConfig) (*CustomGateways, error) {
+	if len(cfgs) == 0 {
+		return nil, nil
+	}
+	g := &CustomGateways{gateways: make(map[common.Address]*customGateway, len(cfgs))}
+	for _, cfg := range cfgs {
+		gateway, err := newCustomGateway(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid custom gateway %q: %w", cfg.Name, err)
+		}
+		address := common.HexToAddress(cfg.Address)
+		if _, found := g.gateways[address]; found {
+			return nil, fmt.Errorf("the custom gateway %q is configured twice", address.Hex())
+		}
+		g.gateways[address] = gateway
+	}
+	return g, nil
+}
+
+func newCustomGateway(cfg *config.CustomGatewayConfig) (*customGateway, error) {
+	if !common.IsHexAddress(cfg.Address) {
+		return nil, fmt.Errorf("invalid address %q", cfg.Address)
+	}
+	asset, ok := customGatewayAssets[cfg.Asset]
+	if !ok {
+		return nil, fmt.Errorf("unknown asset %q, ETH, ERC20, ERC721 or ERC1155", cfg.Asset)
+	}
+	parsed, err := abi.JSON(bytes.NewReader(append(append([]byte("["), cfg.Event...), ']')))
+	if err != nil {
+		return nil, fmt.Errorf("invalid event ABI: %w", err)
+	}
+	if len(parsed.Events) != 1 {
+		return nil, fmt.Errorf("the event ABI holds %d events, exactly one is expected", len(parsed.Events))
+	}
+	gateway := &customGateway{name: cfg.Name, asset: asset, fields: cfg.Fields, l1Token: cfg.L1Token, l2Token: cfg.L2Token}
+	for _, event := range parsed.Events {
+		gateway.event = event
+	}
+	arguments := make(map[string]abi.Argument, len(gateway.event.Inputs))
+	for _, input := range gateway.event.Inputs {
+		arguments[input.Name] = input
+		if input.Indexed {
+			gateway.indexed = append(gateway.indexed, input)
+		}
+	}
+	for _, field := range []string{"sender", "target"} {
+		if cfg.Fields[field] == "" {
+			return nil, fmt.Errorf("the %s field is not mapped", field)
+		}
+	}
+	for field, name := range cfg.Fields {
+		allowedTypes, known := customGatewayFieldTypes[field]
+		if !known {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		argument, found := arguments[name]
+		if !found {
+			return nil, fmt.Errorf("the event %s has no argument %q for the %s field", gateway.event.Name, name, field)
+		}
+		if !bytes.Contains(allowedTypes, []byte{argument.Type.T}) {
+			return nil, fmt.Errorf("the argument %q of type %s can't be the %s field", name, argument.Type.String(), field)
+		}
+		if argument.Type.T == abi.SliceTy && (argument.Type.Elem.T != abi.UintTy || argument.Type.Elem.Size <= 64) {
+			return nil, fmt.Errorf("the argument %q of type %s can't be the %s field, the lists must be of uint256", name, argument.Type.String(), field)
+		}
+		if argument.Indexed && argument.Type.T == abi.SliceTy {
+			return nil, fmt.Errorf("the indexed argument %q is hashed, it can't be the %s field", name, field)
+		}
+	}
+	return gateway, nil
+}
+
+// Addresses returns the addresses of the custom gateways the events are fetched from
+func (g *CustomGateways) Addresses() []common.Address {
+	if g == nil {
+		return nil
+	}
+	addresses := make([]common.Address, 0, len(g.gateways))
+	for address := range g.gateways {
+		addresses = append(addresses, address)
+	}
+	return addresses
+}
+
+// Topics returns the signatures of the bridge events of the custom gateways, each signature once
+func (g *CustomGateways) Topics() []common.Hash {
+	if g == nil {
+		return nil
+	}
+	seen := make(map[common.Hash]bool, len(g.gateways))
+	var topics []common.Hash
+	for _, gateway := range g.gateways {
+		if !seen[gateway.event.ID] {
+			seen[gateway.event.ID] = true
+			topics = append(topics, gateway.event.ID)
+		}
+	}
+	return topics
+}
+
+// decode decodes the log into a cross message of the mapped fields, the fields of the layer and the message hash are
+// left to the caller. nil is returned if the log is not a bridge event of a custom gateway.
+func (g *CustomGateways) decode(vlog types.Log) (*orm.CrossMsg, error) {
+	if g == nil || len(vlog.Topics) == 0 {
+		return nil, nil
+	}
+	gateway, found := g.gateways[vlog.Address]
+	if !found || vlog.Topics[0] != gateway.event.ID {
+		return nil, nil
+	}
+	values := make(map[string]interface{}, len(gateway.event.Inputs))
+	if len(vlog.Data) > 0 {
+		if err := gateway.event.Inputs.UnpackIntoMap(values, vlog.Data); err != nil {
+			return nil, fmt.Errorf("failed to unpack the %s event of custom gateway %q: %w", gateway.event.Name, gateway.name, err)
+		}
+	}
+	if err := abi.ParseTopicsIntoMap(values, gateway.indexed, vlog.Topics[1:]); err != nil {
+		return nil, fmt.Errorf("failed to parse the topics of the %s event of custom gateway %q: %w", gateway.event.Name, gateway.name, err)
+	}
+
+	crossMsg := &orm.CrossMsg{
+		Height:      vlog.BlockNumber,
+		BlockHash:   vlog.BlockHash.Hex(),
+		Gateway:     vlog.Address.Hex(),
+		Asset:       int(gateway.asset),
+		Layer1Token: gateway.l1Token,
+		Layer2Token: gateway.l2Token,
+	}
+	for field, name := range gateway.fields {
+		value := formatEventArgument(values[name])
+		switch field {
+		case "sender":
+			crossMsg.Sender = value
+		case "target":
+			crossMsg.Target = value
+		case "amount":
+			crossMsg.Amount = value
+		case "l1Token":
+			crossMsg.Layer1Token = value
+		case "l2Token":
+			crossMsg.Layer2Token = value
+		case "tokenIds":
+			crossMsg.TokenIDs = value
+		case "tokenAmounts":
+			crossMsg.TokenAmounts = value
+		}
+	}
+	return crossMsg, nil
+}
+
+// formatEventArgument formats the unpacked event argument the way the events of the scroll gateways are stored
+func formatEventArgument(value interface{}) string {
+	switch v := value.(type) {
+	case common.Address:
+		return v.Hex()
+	case *big.Int:
+		return v.String()
+	case []*big.Int:
+		return convertBigIntArrayToString(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,145 @@
+package utils_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	backendabi "bridge-history-api/abi"
+	"bridge-history-api/config"
+	"bridge-history-api/orm"
+	"bridge-history-api/utils"
+)
+
+const bridgedEventABI = `{"type": "event", "name": "Bridged", "inputs": [
+	{"name": "from", "type": "address", "indexed": true},
+	{"name": "to", "type": "address", "indexed": true},
+	{"name": "token", "type": "address", "indexed": false},
+	{"name": "amount", "type": "uint256", "indexed": false}
+]}`
+
+func newBridgedGatewayConfig(address common.Address) *config.CustomGatewayConfig {
+	return &config.CustomGatewayConfig{
+		Name:    "acme",
+		Address: address.Hex(),
+		Event:   json.RawMessage(bridgedEventABI),
+		Asset:   "ERC20",
+		Fields:  map[string]string{"sender": "from", "target": "to", "l1Token": "token", "amount": "amount"},
+		L2Token: common.HexToAddress("0x32").Hex(),
+	}
+}
+
+func packBridgedEvent(t *testing.T, gateway, from, to, token common.Address, amount *big.Int, txHash common.Hash) types.Log {
+	parsed, err := abi.JSON(strings.NewReader("[" + bridgedEventABI + "]"))
+	assert.NoError(t, err)
+	data, err := parsed.Events["Bridged"].Inputs.NonIndexed().Pack(token, amount)
+	assert.NoError(t, err)
+	return types.Log{Address: gateway, Topics: []common.Hash{parsed.Events["Bridged"].ID, from.Hash(), to.Hash()}, Data: data, TxHash: txHash, BlockNumber: 1}
+}
+
+func TestParseCustomGatewayDeposit(t *testing.T) {
+	txHash := common.HexToHash("0x01")
+	gatewayAddr := common.HexToAddress("0x41")
+	sender := common.HexToAddress("0x21")
+	target := common.HexToAddress("0x22")
+	token := common.HexToAddress("0x31")
+	message := []byte{0x12, 0x34}
+
+	gateways, err := utils.NewCustomGateways([]*config.CustomGatewayConfig{newBridgedGatewayConfig(gatewayAddr)})
+	assert.NoError(t, err)
+	assert.Equal(t, []common.Address{gatewayAddr}, gateways.Addresses())
+	assert.Len(t, gateways.Topics(), 1)
+
+	sentMsgData, err := backendabi.L1ScrollMessengerABI.Events["SentMessage"].Inputs.NonIndexed().
+		Pack(big.NewInt(0), big.NewInt(7), big.NewInt(200000), message)
+	assert.NoError(t, err)
+	l1Logs := []types.Log{
+		{Topics: []common.Hash{backendabi.L1SentMessageEventSignature, gatewayAddr.Hash(), target.Hash()}, Data: sentMsgData, TxHash: txHash, BlockNumber: 1},
+		packBridgedEvent(t, gatewayAddr, sender, target, token, big.NewInt(100), txHash),
+	}
+	crossMsgs, _, err := utils.ParseBackendL1EventLogs(l1Logs, gateways)
+	assert.NoError(t, err)
+	assert.Len(t, crossMsgs, 1)
+	expectedMsgHash := utils.ComputeMessageHash(gatewayAddr, target, big.NewInt(0), big.NewInt(7), message)
+	assert.Equal(t, expectedMsgHash.Hex(), crossMsgs[0].MsgHash)
+	assert.Equal(t, sender.Hex(), crossMsgs[0].Sender)
+	assert.Equal(t, target.Hex(), crossMsgs[0].Target)
+	assert.Equal(t, "100", crossMsgs[0].Amount)
+	assert.Equal(t, token.Hex(), crossMsgs[0].Layer1Token)
+	assert.Equal(t, common.HexToAddress("0x32").Hex(), crossMsgs[0].Layer2Token)
+	assert.Equal(t, int(orm.ERC20), crossMsgs[0].Asset)
+	assert.Equal(t, int(orm.Layer1Msg), crossMsgs[0].MsgType)
+	assert.Equal(t, txHash.Hex(), crossMsgs[0].Layer1Hash)
+	assert.Equal(t, gatewayAddr.Hex(), crossMsgs[0].MsgSender)
+
+	// the events of the other addresses are not decoded
+	crossMsgs, _, err = utils.ParseBackendL1EventLogs([]types.Log{packBridgedEvent(t, common.HexToAddress("0x42"), sender, target, token, big.NewInt(100), txHash)}, gateways)
+	assert.NoError(t, err)
+	assert.Empty(t, crossMsgs)
+}
+
+func TestParseCustomGatewayWithdrawal(t *testing.T) {
+	txHash := common.HexToHash("0x01")
+	gatewayAddr := common.HexToAddress("0x41")
+	sender := common.HexToAddress("0x21")
+	target := common.HexToAddress("0x22")
+	token := common.HexToAddress("0x31")
+
+	gateways, err := utils.NewCustomGateways([]*config.CustomGatewayConfig{newBridgedGatewayConfig(gatewayAddr)})
+	assert.NoError(t, err)
+
+	sentMsgData, err := backendabi.L2ScrollMessengerABI.Events["SentMessage"].Inputs.NonIndexed().
+		Pack(big.NewInt(0), big.NewInt(3), big.NewInt(0), []byte{})
+	assert.NoError(t, err)
+	l2Logs := []types.Log{
+		{Topics: []common.Hash{backendabi.L2SentMessageEventSignature, gatewayAddr.Hash(), target.Hash()}, Data: sentMsgData, TxHash: txHash, BlockNumber: 1},
+		packBridgedEvent(t, gatewayAddr, sender, target, token, big.NewInt(100), txHash),
+	}
+	crossMsgs, _, l2SentMsgs, err := utils.ParseBackendL2EventLogs(l2Logs, gateways)
+	assert.NoError(t, err)
+	assert.Len(t, crossMsgs, 1)
+	assert.Len(t, l2SentMsgs, 1)
+	assert.Equal(t, l2SentMsgs[0].MsgHash, crossMsgs[0].MsgHash)
+	assert.Equal(t, sender.Hex(), l2SentMsgs[0].OriginalSender)
+	assert.Equal(t, int(orm.Layer2Msg), crossMsgs[0].MsgType)
+	assert.Equal(t, txHash.Hex(), crossMsgs[0].Layer2Hash)
+
+	// a withdrawal without its SentMessage can't be matched to its message
+	_, _, _, err = utils.ParseBackendL2EventLogs(l2Logs[1:], gateways)
+	assert.Error(t, err)
+}
+
+func TestNewCustomGateways(t *testing.T) {
+	gateways, err := utils.NewCustomGateways(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, gateways)
+	assert.Empty(t, gateways.Addresses())
+
+	gatewayAddr := common.HexToAddress("0x41")
+	invalid := []func(cfg *config.CustomGatewayConfig){
+		func(cfg *config.CustomGatewayConfig) { cfg.Address = "0x41" },
+		func(cfg *config.CustomGatewayConfig) { cfg.Asset = "ERC777" },
+		func(cfg *config.CustomGatewayConfig) {
+			cfg.Event = json.RawMessage(`{"type": "function", "name": "bridge"}`)
+		},
+		func(cfg *config.CustomGatewayConfig) { delete(cfg.Fields, "target") },
+		func(cfg *config.CustomGatewayConfig) { cfg.Fields["tokenIds"] = "token" },
+		func(cfg *config.CustomGatewayConfig) { cfg.Fields["amount"] = "value" },
+		func(cfg *config.CustomGatewayConfig) { cfg.Fields["amount"] = "to" },
+	}
+	for _, modify := range invalid {
+		cfg := newBridgedGatewayConfig(gatewayAddr)
+		modify(cfg)
+		_, err = utils.NewCustomGateways([]*config.CustomGatewayConfig{cfg})
+		assert.Error(t, err)
+	}
+
+	_, err = utils.NewCustomGateways([]*config.CustomGatewayConfig{newBridgedGatewayConfig(gatewayAddr), newBridgedGatewayConfig(gatewayAddr)})
+	assert.Error(t, err)
+}
@@ -13,6 +13,8 @@ import (
 	"gorm.io/gorm/utils"
 
 	"bridge-history-api/config"
+	"bridge-history-api/orm"
+	"bridge-history-api/orm/migrate"
 )
 
 type gormLogger struct {
@@ -77,9 +79,40 @@ func InitDB(config *config.DBConfig) (*gorm.DB, error) {
 	sqlDB.SetMaxOpenConns(config.MaxOpenNum)
 	sqlDB.SetMaxIdleConns(config.MaxIdleNum)
 
+	// the queries are traced once the tracing is initialized
+	if err = orm.UseTracing(db); err != nil {
+		return nil, err
+	}
 	return db, nil
 }
 
+// defaultMaxReplicationLag is the lag a replica may be behind the primary by default
+const defaultMaxReplicationLag = 5 * time.Second
+
+// InitReplicas routes the reads of db to the read replicas of the config, see orm.UseReplicas
+func InitReplicas(db *gorm.DB, cfg *config.DBConfig) error {
+	if len(cfg.Replicas) == 0 {
+		return nil
+	}
+	replicas := make([]*sql.DB, 0, len(cfg.Replicas))
+	for i, dsn := range cfg.Replicas {
+		replica, err := InitDB(&config.DBConfig{DSN: dsn, MaxOpenNum: cfg.MaxOpenNum, MaxIdleNum: cfg.MaxIdleNum})
+		if err != nil {
+			return fmt.Errorf("replica %d: %w", i, err)
+		}
+		sqlDB, err := replica.DB()
+		if err != nil {
+			return fmt.Errorf("replica %d: %w", i, err)
+		}
+		replicas = append(replicas, sqlDB)
+	}
+	maxLag := time.Duration(cfg.MaxReplicationLag) * time.Second
+	if maxLag == 0 {
+		maxLag = defaultMaxReplicationLag
+	}
+	return orm.UseReplicas(db, replicas, maxLag)
+}
+
 // Ping check db status
 func Ping(db *gorm.DB) (*sql.DB, error) {
 	sqlDB, err := db.DB()
@@ -105,6 +138,15 @@ func CloseDB(db *gorm.DB) error {
 	return nil
 }
 
+// CheckSchemaVersion returns migrate.ErrVersionMismatch unless the db is migrated to the latest migration of the binary
+func CheckSchemaVersion(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return migrate.CheckVersion(sqlDB)
+}
+
 // nowUTC get the utc time.Now
 func nowUTC() (time.Time, error) {
 	utc, err := time.LoadLocation("")
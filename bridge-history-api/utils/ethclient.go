@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// rpcTracerName the instrumentation name of the spans of the node calls
+const rpcTracerName = "bridge-history-api/rpc"
+
+// DialEthClient connects the node of the endpoint, the calls over http are traced with a span each named by the rpc
+// method and carry the trace context to the node. The websocket and the ipc endpoints are not traced.
+func DialEthClient(endpoint string) (*ethclient.Client, error) {
+	httpClient := &http.Client{Transport: &rpcTracingTransport{base: http.DefaultTransport}}
+	client, err := rpc.DialOptions(context.Background(), endpoint, rpc.WithHTTPClient(httpClient))
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(client), nil
+}
+
+// rpcTracingTransport traces the json rpc requests, as children of the spans of their contexts if any
+type rpcTracingTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *rpcTracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := rpcMethod(req)
+	ctx, span := otel.Tracer(rpcTracerName).Start(req.Context(), method, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("rpc.system", "jsonrpc"), attribute.String("rpc.method", method)))
+	defer span.End()
+	// the request must not be modified by the transport
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "request failed")
+		return nil, err
+	}
+	span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
+
+// rpcMethod returns the json rpc method of the request body, "batch" for the batches and "unknown" if unreadable
+func rpcMethod(req *http.Request) string {
+	if req.GetBody == nil {
+		return "unknown"
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return "unknown"
+	}
+	defer func() { _ = body.Close() }()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "unknown"
+	}
+	var msg struct {
+		Method string `json:"method"`
+	}
+	if len(data) > 0 && data[0] == '[' {
+		return "batch"
+	}
+	if err = json.Unmarshal(data, &msg); err != nil || msg.Method == "" {
+		return "unknown"
+	}
+	return msg.Method
+}
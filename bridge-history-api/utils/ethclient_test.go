@@ -0,0 +1,46 @@
+package utils_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"bridge-history-api/utils"
+)
+
+func TestDialEthClientTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var traceparent string
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x10"}`))
+	}))
+	defer node.Close()
+
+	client, err := utils.DialEthClient(node.URL)
+	assert.NoError(t, err)
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "fetch")
+	number, err := client.BlockNumber(ctx)
+	parent.End()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(16), number)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "eth_blockNumber", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	// the node gets the context of the span of the call
+	assert.Contains(t, traceparent, spans[0].SpanContext().SpanID().String())
+}
@@ -22,6 +22,11 @@ var (
 		Usage: "JSON configuration file",
 		Value: "./config.json",
 	}
+	// NetworkFlag selects a network of the config by name.
+	NetworkFlag = cli.StringFlag{
+		Name:  "network",
+		Usage: "The name of the network of the config, the fetcher runs all the networks and the other commands use the top level network if not specified",
+	}
 	// VerbosityFlag log level.
 	VerbosityFlag = cli.IntFlag{
 		Name:  "verbosity",
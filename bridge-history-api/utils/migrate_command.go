@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"database/sql"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli/v2"
+
+	"bridge-history-api/config"
+	"bridge-history-api/orm/migrate"
+)
+
+// migrateDownFlags are the flags of the commands rolling back the migrations
+var migrateDownFlags = []cli.Flag{
+	&ConfigFileFlag,
+	&NetworkFlag,
+	&cli.Int64Flag{
+		Name:  "version",
+		Usage: "Roll back the migrations after the specified version, 0 rolls back all of them.",
+	}}
+
+// MigrateCommand applies and rolls back the migrations embedded in the binary, on the dbs of all the networks of the
+// config unless a network is selected. It's shared by the binaries managing the schema, and migrates up if no
+// subcommand is given.
+var MigrateCommand = &cli.Command{
+	Name:   "migrate",
+	Usage:  "Apply or roll back the schema migrations embedded in the binary, on the dbs of all the networks unless --network is given. Migrates up if no subcommand is given.",
+	Action: migrateUp,
+	Flags:  []cli.Flag{&ConfigFileFlag, &NetworkFlag},
+	Subcommands: []*cli.Command{
+		{
+			Name:   "up",
+			Usage:  "Migrate the dbs to the latest version.",
+			Action: migrateUp,
+			Flags:  []cli.Flag{&ConfigFileFlag, &NetworkFlag},
+		},
+		{
+			Name:   "down",
+			Usage:  "Roll back the dbs to a previous <version>. Rolls back a single migration if no version specified.",
+			Action: migrateDown,
+			Flags:  migrateDownFlags,
+		},
+		{
+			Name:   "status",
+			Usage:  "Print the migrations applied and pending, and the versions of the dbs and the binary.",
+			Action: migrateStatus,
+			Flags:  []cli.Flag{&ConfigFileFlag, &NetworkFlag},
+		},
+	},
+}
+
+// RollbackCommand is the alias of `migrate down` kept for the deployments still running `rollback`.
+var RollbackCommand = &cli.Command{
+	Name:   "rollback",
+	Usage:  "Alias of `migrate down`, roll back the dbs to a previous <version>. Rolls back a single migration if no version specified.",
+	Action: migrateDown,
+	Flags:  migrateDownFlags,
+}
+
+// migrateUp migrates the dbs to the latest version
+func migrateUp(ctx *cli.Context) error {
+	return forEachNetworkDB(ctx, func(network string, db *sql.DB) error {
+		if err := migrate.Migrate(db); err != nil {
+			return err
+		}
+		version, err := migrate.Current(db)
+		log.Info("migrated the db", "network", network, "version", version)
+		return err
+	})
+}
+
+// migrateDown rolls back the dbs to the version of the flag, a single migration if not given
+func migrateDown(ctx *cli.Context) error {
+	var version *int64
+	if ctx.IsSet("version") {
+		target := ctx.Int64("version")
+		version = &target
+	}
+	return forEachNetworkDB(ctx, func(network string, db *sql.DB) error {
+		if err := migrate.Rollback(db, version); err != nil {
+			return err
+		}
+		current, err := migrate.Current(db)
+		log.Info("rolled back the db", "network", network, "version", current)
+		return err
+	})
+}
+
+// migrateStatus prints the status of the migrations of the dbs
+func migrateStatus(ctx *cli.Context) error {
+	latest, err := migrate.Latest()
+	if err != nil {
+		return err
+	}
+	return forEachNetworkDB(ctx, func(network string, db *sql.DB) error {
+		if err := migrate.PrintStatus(db); err != nil {
+			return err
+		}
+		current, err := migrate.Current(db)
+		if err != nil {
+			return err
+		}
+		log.Info("the schema version of the db", "network", network, "db version", current, "binary version", latest, "up to date", current == latest)
+		return nil
+	})
+}
+
+// forEachNetworkDB runs fn on the db of the network of the flag, or on the dbs of all the networks of the config in order
+func forEachNetworkDB(ctx *cli.Context, fn func(network string, db *sql.DB) error) error {
+	fileCfg, err := config.NewConfig(ctx.String(ConfigFileFlag.Name))
+	if err != nil {
+		return err
+	}
+	networkConfigs, err := fileCfg.NetworkConfigs()
+	if err != nil {
+		return err
+	}
+	if network := ctx.String(NetworkFlag.Name); network != "" {
+		cfg, cfgErr := fileCfg.NetworkConfig(network)
+		if cfgErr != nil {
+			return cfgErr
+		}
+		networkConfigs = []*config.Config{cfg}
+	}
+	for _, cfg := range networkConfigs {
+		if err = runOnDB(cfg, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runOnDB runs fn on the db of the network config, the db is closed once done
+func runOnDB(cfg *config.Config, fn func(network string, db *sql.DB) error) error {
+	gormDB, err := InitDB(cfg.DB)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if deferErr := CloseDB(gormDB); deferErr != nil {
+			log.Error("failed to close db", "network", cfg.Network, "err", deferErr)
+		}
+	}()
+	db, err := gormDB.DB()
+	if err != nil {
+		return err
+	}
+	return fn(cfg.Network, db)
+}
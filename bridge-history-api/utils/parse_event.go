@@ -1,12 +1,16 @@
 package utils
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"math/big"
 	"strings"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 
@@ -14,16 +18,42 @@ import (
 	"bridge-history-api/orm"
 )
 
-// ParseBackendL1EventLogs parses L1 watched events
-func ParseBackendL1EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedMsg, error) {
+// ParseBackendL1EventLogs parses L1 watched events, the bridge events of the custom gateways are parsed into the
+// deposits too if gateways is not nil
+func ParseBackendL1EventLogs(logs []types.Log, gateways *CustomGateways) ([]*orm.CrossMsg, []*orm.RelayedMsg, error) {
 	// Need use contract abi to parse event Log
 	// Can only be tested after we have our contracts set up
 
 	var l1CrossMsg []*orm.CrossMsg
 	var relayedMsgs []*orm.RelayedMsg
 	var msgHash string
+	// the relay txs in which a gateway transferred the withdrawn tokens since the last relayed message of the tx, the
+	// finalize events of the gateways precede the RelayedMessage of their message so that the several messages relayed
+	// in one tx are told apart
+	pendingDeliveries := make(map[common.Hash]bool)
+	// the SentMessage events by msg hash, they are kept along with the deposits to rebuild the message on layer2
+	sentMsgs := make(map[string]*backendabi.L1SentMessageEvent)
 	for _, vlog := range logs {
+		// the custom gateways are matched first, their events may share the signatures of the scroll gateways
+		customMsg, err := gateways.decode(vlog)
+		if err != nil {
+			log.Warn("Failed to unpack custom gateway event", "err", err)
+			return l1CrossMsg, relayedMsgs, err
+		}
+		if customMsg != nil {
+			customMsg.Layer1Hash = vlog.TxHash.Hex()
+			customMsg.MsgType = int(orm.Layer1Msg)
+			customMsg.MsgHash = msgHash
+			l1CrossMsg = append(l1CrossMsg, customMsg)
+			continue
+		}
 		switch vlog.Topics[0] {
+		case backendabi.L1FinalizeWithdrawETHSig, backendabi.L1FinalizeWithdrawERC20Sig, backendabi.L1FinalizeWithdrawERC721Sig,
+			backendabi.L1FinalizeWithdrawERC1155Sig, backendabi.L1FinalizeBatchWithdrawERC721Sig, backendabi.L1FinalizeBatchWithdrawERC1155Sig:
+			pendingDeliveries[vlog.TxHash] = true
+		case backendabi.L1FailedRelayedMessageEventSignature:
+			// the gateway events of the failed relay are reverted
+			delete(pendingDeliveries, vlog.TxHash)
 		case backendabi.L1DepositETHSig:
 			event := backendabi.DepositETH{}
 			err := UnpackLog(backendabi.L1ETHGatewayABI, &event, "DepositETH", vlog)
@@ -33,6 +63,8 @@ func ParseBackendL1EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedM
 			}
 			l1CrossMsg = append(l1CrossMsg, &orm.CrossMsg{
 				Height:     vlog.BlockNumber,
+				BlockHash:  vlog.BlockHash.Hex(),
+				Gateway:    vlog.Address.Hex(),
 				Sender:     event.From.String(),
 				Target:     event.To.String(),
 				Amount:     event.Amount.String(),
@@ -50,6 +82,8 @@ func ParseBackendL1EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedM
 			}
 			l1CrossMsg = append(l1CrossMsg, &orm.CrossMsg{
 				Height:      vlog.BlockNumber,
+				BlockHash:   vlog.BlockHash.Hex(),
+				Gateway:     vlog.Address.Hex(),
 				Sender:      event.From.String(),
 				Target:      event.To.String(),
 				Amount:      event.Amount.String(),
@@ -69,13 +103,15 @@ func ParseBackendL1EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedM
 			}
 			l1CrossMsg = append(l1CrossMsg, &orm.CrossMsg{
 				Height:      vlog.BlockNumber,
+				BlockHash:   vlog.BlockHash.Hex(),
+				Gateway:     vlog.Address.Hex(),
 				Sender:      event.From.String(),
 				Target:      event.To.String(),
 				Asset:       int(orm.ERC721),
 				Layer1Hash:  vlog.TxHash.Hex(),
 				Layer1Token: event.L1Token.Hex(),
 				Layer2Token: event.L2Token.Hex(),
-				TokenIDs:    event.TokenID.String(),
+				TokenIDs:    event.TokenId.String(),
 				MsgType:     int(orm.Layer1Msg),
 				MsgHash:     msgHash,
 			})
@@ -87,17 +123,20 @@ func ParseBackendL1EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedM
 				return l1CrossMsg, relayedMsgs, err
 			}
 			l1CrossMsg = append(l1CrossMsg, &orm.CrossMsg{
-				Height:      vlog.BlockNumber,
-				Sender:      event.From.String(),
-				Target:      event.To.String(),
-				Asset:       int(orm.ERC1155),
-				Layer1Hash:  vlog.TxHash.Hex(),
-				Layer1Token: event.L1Token.Hex(),
-				Layer2Token: event.L2Token.Hex(),
-				TokenIDs:    event.TokenID.String(),
-				Amount:      event.Amount.String(),
-				MsgType:     int(orm.Layer1Msg),
-				MsgHash:     msgHash,
+				Height:       vlog.BlockNumber,
+				BlockHash:    vlog.BlockHash.Hex(),
+				Gateway:      vlog.Address.Hex(),
+				Sender:       event.From.String(),
+				Target:       event.To.String(),
+				Asset:        int(orm.ERC1155),
+				Layer1Hash:   vlog.TxHash.Hex(),
+				Layer1Token:  event.L1Token.Hex(),
+				Layer2Token:  event.L2Token.Hex(),
+				TokenIDs:     event.TokenId.String(),
+				TokenAmounts: event.Amount.String(),
+				Amount:       event.Amount.String(),
+				MsgType:      int(orm.Layer1Msg),
+				MsgHash:      msgHash,
 			})
 		case backendabi.L1SentMessageEventSignature:
 			event := backendabi.L1SentMessageEvent{}
@@ -108,6 +147,7 @@ func ParseBackendL1EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedM
 			}
 			// since every deposit event will emit after a sent event, so can use this msg_hash as next withdraw event's msg_hash
 			msgHash = ComputeMessageHash(event.Sender, event.Target, event.Value, event.MessageNonce, event.Message).Hex()
+			sentMsgs[msgHash] = &event
 		case backendabi.L1BatchDepositERC721Sig:
 			event := backendabi.BatchERC721MessageEvent{}
 			err := UnpackLog(backendabi.L1ERC721GatewayABI, &event, "BatchDepositERC721", vlog)
@@ -117,13 +157,15 @@ func ParseBackendL1EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedM
 			}
 			l1CrossMsg = append(l1CrossMsg, &orm.CrossMsg{
 				Height:      vlog.BlockNumber,
+				BlockHash:   vlog.BlockHash.Hex(),
+				Gateway:     vlog.Address.Hex(),
 				Sender:      event.From.String(),
 				Target:      event.To.String(),
 				Asset:       int(orm.ERC721),
 				Layer1Hash:  vlog.TxHash.Hex(),
 				Layer1Token: event.L1Token.Hex(),
 				Layer2Token: event.L2Token.Hex(),
-				TokenIDs:    convertBigIntArrayToString(event.TokenIDs),
+				TokenIDs:    convertBigIntArrayToString(event.TokenIds),
 				MsgType:     int(orm.Layer1Msg),
 				MsgHash:     msgHash,
 			})
@@ -136,14 +178,16 @@ func ParseBackendL1EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedM
 			}
 			l1CrossMsg = append(l1CrossMsg, &orm.CrossMsg{
 				Height:       vlog.BlockNumber,
+				BlockHash:    vlog.BlockHash.Hex(),
+				Gateway:      vlog.Address.Hex(),
 				Sender:       event.From.String(),
 				Target:       event.To.String(),
 				Asset:        int(orm.ERC1155),
 				Layer1Hash:   vlog.TxHash.Hex(),
 				Layer1Token:  event.L1Token.Hex(),
 				Layer2Token:  event.L2Token.Hex(),
-				TokenIDs:     convertBigIntArrayToString(event.TokenIDs),
-				TokenAmounts: convertBigIntArrayToString(event.TokenAmounts),
+				TokenIDs:     convertBigIntArrayToString(event.TokenIds),
+				TokenAmounts: convertBigIntArrayToString(event.Amounts),
 				MsgType:      int(orm.Layer1Msg),
 				MsgHash:      msgHash,
 			})
@@ -158,16 +202,28 @@ func ParseBackendL1EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedM
 				MsgHash:    event.MessageHash.String(),
 				Height:     vlog.BlockNumber,
 				Layer1Hash: vlog.TxHash.Hex(),
+				Delivered:  pendingDeliveries[vlog.TxHash],
 			})
+			delete(pendingDeliveries, vlog.TxHash)
 
 		}
 
 	}
+	for _, crossMsg := range l1CrossMsg {
+		if sentMsg, found := sentMsgs[crossMsg.MsgHash]; found {
+			crossMsg.MsgSender = sentMsg.Sender.Hex()
+			crossMsg.MsgTarget = sentMsg.Target.Hex()
+			crossMsg.MsgValue = sentMsg.Value.String()
+			crossMsg.MsgNonce = sentMsg.MessageNonce.Uint64()
+			crossMsg.MsgData = hexutil.Encode(sentMsg.Message)
+		}
+	}
 	return l1CrossMsg, relayedMsgs, nil
 }
 
-// ParseBackendL2EventLogs parses L2 watched events
-func ParseBackendL2EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedMsg, []*orm.L2SentMsg, error) {
+// ParseBackendL2EventLogs parses L2 watched events, the bridge events of the custom gateways are parsed into the
+// withdrawals too if gateways is not nil
+func ParseBackendL2EventLogs(logs []types.Log, gateways *CustomGateways) ([]*orm.CrossMsg, []*orm.RelayedMsg, []*orm.L2SentMsg, error) {
 	// Need use contract abi to parse event Log
 	// Can only be tested after we have our contracts set up
 
@@ -175,8 +231,37 @@ func ParseBackendL2EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedM
 	// this is use to confirm finalized l1 msg
 	var relayedMsgs []*orm.RelayedMsg
 	var l2SentMsgs []*orm.L2SentMsg
+	// the relay txs in which a gateway transferred the deposited tokens since the last relayed message of the tx, the
+	// finalize events of the gateways precede the RelayedMessage of their message so that the several messages relayed
+	// in one tx are told apart
+	pendingDeliveries := make(map[common.Hash]bool)
 	for _, vlog := range logs {
+		// the custom gateways are matched first, their events may share the signatures of the scroll gateways
+		customMsg, err := gateways.decode(vlog)
+		if err != nil {
+			log.Warn("Failed to unpack custom gateway event", "err", err)
+			return l2CrossMsg, relayedMsgs, l2SentMsgs, err
+		}
+		if customMsg != nil {
+			if len(l2SentMsgs) == 0 || l2SentMsgs[len(l2SentMsgs)-1].TxHash != vlog.TxHash.Hex() {
+				err = fmt.Errorf("no SentMessage precedes the custom gateway event in tx %s", vlog.TxHash.Hex())
+				log.Warn("Failed to match custom gateway event", "err", err)
+				return l2CrossMsg, relayedMsgs, l2SentMsgs, err
+			}
+			l2SentMsgs[len(l2SentMsgs)-1].OriginalSender = customMsg.Sender
+			customMsg.Layer2Hash = vlog.TxHash.Hex()
+			customMsg.MsgType = int(orm.Layer2Msg)
+			customMsg.MsgHash = l2SentMsgs[len(l2SentMsgs)-1].MsgHash
+			l2CrossMsg = append(l2CrossMsg, customMsg)
+			continue
+		}
 		switch vlog.Topics[0] {
+		case backendabi.L2FinalizeDepositETHSig, backendabi.L2FinalizeDepositERC20Sig, backendabi.L2FinalizeDepositERC721Sig,
+			backendabi.L2FinalizeDepositERC1155Sig, backendabi.L2FinalizeBatchDepositERC721Sig, backendabi.L2FinalizeBatchDepositERC1155Sig:
+			pendingDeliveries[vlog.TxHash] = true
+		case backendabi.L2FailedRelayedMessageEventSignature:
+			// the gateway events of the failed relay are reverted
+			delete(pendingDeliveries, vlog.TxHash)
 		case backendabi.L2WithdrawETHSig:
 			event := backendabi.DepositETH{}
 			err := UnpackLog(backendabi.L2ETHGatewayABI, &event, "WithdrawETH", vlog)
@@ -187,6 +272,8 @@ func ParseBackendL2EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedM
 			l2SentMsgs[len(l2SentMsgs)-1].OriginalSender = event.From.Hex()
 			l2CrossMsg = append(l2CrossMsg, &orm.CrossMsg{
 				Height:     vlog.BlockNumber,
+				BlockHash:  vlog.BlockHash.Hex(),
+				Gateway:    vlog.Address.Hex(),
 				Sender:     event.From.String(),
 				Target:     event.To.String(),
 				Amount:     event.Amount.String(),
@@ -205,6 +292,8 @@ func ParseBackendL2EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedM
 			l2SentMsgs[len(l2SentMsgs)-1].OriginalSender = event.From.Hex()
 			l2CrossMsg = append(l2CrossMsg, &orm.CrossMsg{
 				Height:      vlog.BlockNumber,
+				BlockHash:   vlog.BlockHash.Hex(),
+				Gateway:     vlog.Address.Hex(),
 				Sender:      event.From.String(),
 				Target:      event.To.String(),
 				Amount:      event.Amount.String(),
@@ -225,13 +314,15 @@ func ParseBackendL2EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedM
 			l2SentMsgs[len(l2SentMsgs)-1].OriginalSender = event.From.Hex()
 			l2CrossMsg = append(l2CrossMsg, &orm.CrossMsg{
 				Height:      vlog.BlockNumber,
+				BlockHash:   vlog.BlockHash.Hex(),
+				Gateway:     vlog.Address.Hex(),
 				Sender:      event.From.String(),
 				Target:      event.To.String(),
 				Asset:       int(orm.ERC721),
 				Layer2Hash:  vlog.TxHash.Hex(),
 				Layer1Token: event.L1Token.Hex(),
 				Layer2Token: event.L2Token.Hex(),
-				TokenIDs:    event.TokenID.String(),
+				TokenIDs:    event.TokenId.String(),
 				MsgType:     int(orm.Layer2Msg),
 				MsgHash:     l2SentMsgs[len(l2SentMsgs)-1].MsgHash,
 			})
@@ -244,17 +335,20 @@ func ParseBackendL2EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedM
 			}
 			l2SentMsgs[len(l2SentMsgs)-1].OriginalSender = event.From.Hex()
 			l2CrossMsg = append(l2CrossMsg, &orm.CrossMsg{
-				Height:      vlog.BlockNumber,
-				Sender:      event.From.String(),
-				Target:      event.To.String(),
-				Asset:       int(orm.ERC1155),
-				Layer2Hash:  vlog.TxHash.Hex(),
-				Layer1Token: event.L1Token.Hex(),
-				Layer2Token: event.L2Token.Hex(),
-				TokenIDs:    event.TokenID.String(),
-				Amount:      event.Amount.String(),
-				MsgType:     int(orm.Layer2Msg),
-				MsgHash:     l2SentMsgs[len(l2SentMsgs)-1].MsgHash,
+				Height:       vlog.BlockNumber,
+				BlockHash:    vlog.BlockHash.Hex(),
+				Gateway:      vlog.Address.Hex(),
+				Sender:       event.From.String(),
+				Target:       event.To.String(),
+				Asset:        int(orm.ERC1155),
+				Layer2Hash:   vlog.TxHash.Hex(),
+				Layer1Token:  event.L1Token.Hex(),
+				Layer2Token:  event.L2Token.Hex(),
+				TokenIDs:     event.TokenId.String(),
+				TokenAmounts: event.Amount.String(),
+				Amount:       event.Amount.String(),
+				MsgType:      int(orm.Layer2Msg),
+				MsgHash:      l2SentMsgs[len(l2SentMsgs)-1].MsgHash,
 			})
 		case backendabi.L2BatchWithdrawERC721Sig:
 			event := backendabi.BatchERC721MessageEvent{}
@@ -266,14 +360,16 @@ func ParseBackendL2EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedM
 			l2SentMsgs[len(l2SentMsgs)-1].OriginalSender = event.From.Hex()
 			l2CrossMsg = append(l2CrossMsg, &orm.CrossMsg{
 				Height:      vlog.BlockNumber,
+				BlockHash:   vlog.BlockHash.Hex(),
+				Gateway:     vlog.Address.Hex(),
 				Sender:      event.From.String(),
 				Target:      event.To.String(),
 				Asset:       int(orm.ERC721),
-				Layer1Hash:  vlog.TxHash.Hex(),
+				Layer2Hash:  vlog.TxHash.Hex(),
 				Layer1Token: event.L1Token.Hex(),
 				Layer2Token: event.L2Token.Hex(),
 				MsgType:     int(orm.Layer2Msg),
-				TokenIDs:    convertBigIntArrayToString(event.TokenIDs),
+				TokenIDs:    convertBigIntArrayToString(event.TokenIds),
 				MsgHash:     l2SentMsgs[len(l2SentMsgs)-1].MsgHash,
 			})
 		case backendabi.L2BatchWithdrawERC1155Sig:
@@ -286,15 +382,17 @@ func ParseBackendL2EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedM
 			l2SentMsgs[len(l2SentMsgs)-1].OriginalSender = event.From.Hex()
 			l2CrossMsg = append(l2CrossMsg, &orm.CrossMsg{
 				Height:       vlog.BlockNumber,
+				BlockHash:    vlog.BlockHash.Hex(),
+				Gateway:      vlog.Address.Hex(),
 				Sender:       event.From.String(),
 				Target:       event.To.String(),
 				Asset:        int(orm.ERC1155),
-				Layer1Hash:   vlog.TxHash.Hex(),
+				Layer2Hash:   vlog.TxHash.Hex(),
 				Layer1Token:  event.L1Token.Hex(),
 				Layer2Token:  event.L2Token.Hex(),
 				MsgType:      int(orm.Layer2Msg),
-				TokenIDs:     convertBigIntArrayToString(event.TokenIDs),
-				TokenAmounts: convertBigIntArrayToString(event.TokenAmounts),
+				TokenIDs:     convertBigIntArrayToString(event.TokenIds),
+				TokenAmounts: convertBigIntArrayToString(event.Amounts),
 				MsgHash:      l2SentMsgs[len(l2SentMsgs)-1].MsgHash,
 			})
 		case backendabi.L2SentMessageEventSignature:
@@ -306,16 +404,18 @@ func ParseBackendL2EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedM
 			}
 			// since every withdraw event will emit after a sent event, so can use this msg_hash as next withdraw event's msg_hash
 			msgHash := ComputeMessageHash(event.Sender, event.Target, event.Value, event.MessageNonce, event.Message)
+			logIndex := uint64(vlog.Index)
 			l2SentMsgs = append(l2SentMsgs,
 				&orm.L2SentMsg{
-					Sender:  event.Sender.Hex(),
-					TxHash:  vlog.TxHash.Hex(),
-					Target:  event.Target.Hex(),
-					Value:   event.Value.String(),
-					MsgHash: msgHash.Hex(),
-					Height:  vlog.BlockNumber,
-					Nonce:   event.MessageNonce.Uint64(),
-					MsgData: hexutil.Encode(event.Message),
+					Sender:   event.Sender.Hex(),
+					TxHash:   vlog.TxHash.Hex(),
+					Target:   event.Target.Hex(),
+					Value:    event.Value.String(),
+					MsgHash:  msgHash.Hex(),
+					Height:   vlog.BlockNumber,
+					Nonce:    event.MessageNonce.Uint64(),
+					LogIndex: &logIndex,
+					MsgData:  hexutil.Encode(event.Message),
 				})
 		case backendabi.L2RelayedMessageEventSignature:
 			event := backendabi.L2RelayedMessageEvent{}
@@ -328,7 +428,9 @@ func ParseBackendL2EventLogs(logs []types.Log) ([]*orm.CrossMsg, []*orm.RelayedM
 				MsgHash:    event.MessageHash.String(),
 				Height:     vlog.BlockNumber,
 				Layer2Hash: vlog.TxHash.Hex(),
+				Delivered:  pendingDeliveries[vlog.TxHash],
 			})
+			delete(pendingDeliveries, vlog.TxHash)
 
 		}
 	}
@@ -359,6 +461,7 @@ func ParseBatchInfoFromScrollChain(ctx context.Context, client *ethclient.Client
 			}
 			rollupBatches = append(rollupBatches, &orm.RollupBatch{
 				CommitHeight:     vlog.BlockNumber,
+				CommitTxHash:     vlog.TxHash.Hex(),
 				BatchIndex:       index,
 				BatchHash:        event.BatchHash.Hex(),
 				StartBlockNumber: startBlock,
@@ -372,6 +475,206 @@ func ParseBatchInfoFromScrollChain(ctx context.Context, client *ethclient.Client
 	return rollupBatches, nil
 }
 
+// ParseBatchFinalizationFromScrollChain parses ScrollChain FinalizeBatch events, the returned rollup batches only
+// carry the batch index and finalization info
+func ParseBatchFinalizationFromScrollChain(logs []types.Log) ([]*orm.RollupBatch, error) {
+	var finalizedBatches []*orm.RollupBatch
+	for _, vlog := range logs {
+		switch vlog.Topics[0] {
+		case backendabi.L1FinalizeBatchEventSignature:
+			event := backendabi.L1FinalizeBatchEvent{}
+			err := UnpackLog(backendabi.ScrollChainABI, &event, "FinalizeBatch", vlog)
+			if err != nil {
+				log.Warn("Failed to unpack FinalizeBatch event", "err", err)
+				return finalizedBatches, err
+			}
+			finalizedBatches = append(finalizedBatches, &orm.RollupBatch{
+				BatchIndex:     event.BatchIndex.Uint64(),
+				BatchHash:      event.BatchHash.Hex(),
+				FinalizeHeight: vlog.BlockNumber,
+				FinalizeTxHash: vlog.TxHash.Hex(),
+				StateRoot:      event.StateRoot.Hex(),
+			})
+
+		default:
+			continue
+		}
+	}
+	return finalizedBatches, nil
+}
+
+// ParseBatchRevertFromScrollChain parses ScrollChain RevertBatch events, the returned rollup batches only
+// carry the batch index and hash
+func ParseBatchRevertFromScrollChain(logs []types.Log) ([]*orm.RollupBatch, error) {
+	var revertedBatches []*orm.RollupBatch
+	for _, vlog := range logs {
+		switch vlog.Topics[0] {
+		case backendabi.L1RevertBatchEventSignature:
+			event := backendabi.L1RevertBatchEvent{}
+			err := UnpackLog(backendabi.ScrollChainABI, &event, "RevertBatch", vlog)
+			if err != nil {
+				log.Warn("Failed to unpack RevertBatch event", "err", err)
+				return revertedBatches, err
+			}
+			revertedBatches = append(revertedBatches, &orm.RollupBatch{
+				BatchIndex: event.BatchIndex.Uint64(),
+				BatchHash:  event.BatchHash.Hex(),
+				Reverted:   true,
+			})
+
+		default:
+			continue
+		}
+	}
+	return revertedBatches, nil
+}
+
+// ParseBackendL1FailedRelayedMsgs parses L1 FailedRelayedMessage events
+func ParseBackendL1FailedRelayedMsgs(logs []types.Log) ([]*orm.FailedRelayedMsg, error) {
+	var failedRelayedMsgs []*orm.FailedRelayedMsg
+	for _, vlog := range logs {
+		switch vlog.Topics[0] {
+		case backendabi.L1FailedRelayedMessageEventSignature:
+			event := backendabi.L1FailedRelayedMessageEvent{}
+			err := UnpackLog(backendabi.L1ScrollMessengerABI, &event, "FailedRelayedMessage", vlog)
+			if err != nil {
+				log.Warn("Failed to unpack FailedRelayedMessage event", "err", err)
+				return failedRelayedMsgs, err
+			}
+			failedRelayedMsgs = append(failedRelayedMsgs, &orm.FailedRelayedMsg{
+				MsgHash:    event.MessageHash.String(),
+				Height:     vlog.BlockNumber,
+				Layer1Hash: vlog.TxHash.Hex(),
+			})
+
+		default:
+			continue
+		}
+	}
+	return failedRelayedMsgs, nil
+}
+
+// ParseBackendL2FailedRelayedMsgs parses L2 FailedRelayedMessage events
+func ParseBackendL2FailedRelayedMsgs(logs []types.Log) ([]*orm.FailedRelayedMsg, error) {
+	var failedRelayedMsgs []*orm.FailedRelayedMsg
+	for _, vlog := range logs {
+		switch vlog.Topics[0] {
+		case backendabi.L2FailedRelayedMessageEventSignature:
+			event := backendabi.L2FailedRelayedMessageEvent{}
+			err := UnpackLog(backendabi.L2ScrollMessengerABI, &event, "FailedRelayedMessage", vlog)
+			if err != nil {
+				log.Warn("Failed to unpack FailedRelayedMessage event", "err", err)
+				return failedRelayedMsgs, err
+			}
+			failedRelayedMsgs = append(failedRelayedMsgs, &orm.FailedRelayedMsg{
+				MsgHash:    event.MessageHash.String(),
+				Height:     vlog.BlockNumber,
+				Layer2Hash: vlog.TxHash.Hex(),
+			})
+
+		default:
+			continue
+		}
+	}
+	return failedRelayedMsgs, nil
+}
+
+// ParseBackendL1MessageQueueEvents parses the L1 QueueTransaction events of the replays and the DropTransaction events,
+// the QueueTransaction events of the new deposits are skipped. A replay appends the relayMessage calldata of the
+// deposit again at a new queue index, so the calldata hashes to the message hash of the deposit and encodes a nonce
+// other than the queue index. The message hashes of the drops are unknown from the events and left empty.
+func ParseBackendL1MessageQueueEvents(logs []types.Log) ([]*orm.ReplayedMsg, []*orm.DroppedMsg, error) {
+	var replayedMsgs []*orm.ReplayedMsg
+	var droppedMsgs []*orm.DroppedMsg
+	relayMessage := backendabi.L2ScrollMessengerABI.Methods["relayMessage"]
+	for _, vlog := range logs {
+		switch vlog.Topics[0] {
+		case backendabi.L1QueueTransactionEventSignature:
+			event := backendabi.L1QueueTransactionEvent{}
+			err := UnpackLog(backendabi.L1MessageQueueABI, &event, "QueueTransaction", vlog)
+			if err != nil {
+				log.Warn("Failed to unpack QueueTransaction event", "err", err)
+				return replayedMsgs, droppedMsgs, err
+			}
+			// the enforced txs aren't messages of the messenger
+			if len(event.Data) < 4 || !bytes.Equal(event.Data[:4], relayMessage.ID) {
+				continue
+			}
+			args, err := relayMessage.Inputs.Unpack(event.Data[4:])
+			if err != nil {
+				log.Warn("Failed to unpack the relayMessage calldata of QueueTransaction event", "err", err)
+				continue
+			}
+			nonce, ok := args[3].(*big.Int)
+			if !ok || nonce.Cmp(event.QueueIndex) == 0 {
+				continue
+			}
+			replayedMsgs = append(replayedMsgs, &orm.ReplayedMsg{
+				MsgHash:    crypto.Keccak256Hash(event.Data).Hex(),
+				QueueIndex: event.QueueIndex.Uint64(),
+				Height:     vlog.BlockNumber,
+				Layer1Hash: vlog.TxHash.Hex(),
+			})
+		case backendabi.L1DropTransactionEventSignature:
+			event := backendabi.L1DropTransactionEvent{}
+			err := UnpackLog(backendabi.L1MessageQueueABI, &event, "DropTransaction", vlog)
+			if err != nil {
+				log.Warn("Failed to unpack DropTransaction event", "err", err)
+				return replayedMsgs, droppedMsgs, err
+			}
+			droppedMsgs = append(droppedMsgs, &orm.DroppedMsg{
+				QueueIndex: event.Index.Uint64(),
+				Height:     vlog.BlockNumber,
+				Layer1Hash: vlog.TxHash.Hex(),
+			})
+		default:
+			continue
+		}
+	}
+	return replayedMsgs, droppedMsgs, nil
+}
+
+// ParseBackendL1RefundEvents parses the RefundETH and the RefundERC20 events of the L1 gateways. The gateways refund a
+// deposit in the dropMessage tx dropping it, the message hashes of the refunds are unknown from the events and left
+// empty, they are resolved by the drops of the same txs.
+func ParseBackendL1RefundEvents(logs []types.Log) ([]*orm.RefundedMsg, error) {
+	var refundedMsgs []*orm.RefundedMsg
+	for _, vlog := range logs {
+		switch vlog.Topics[0] {
+		case backendabi.L1RefundETHSig:
+			event := backendabi.L1RefundETHEvent{}
+			err := UnpackLog(backendabi.L1GatewayRefundABI, &event, "RefundETH", vlog)
+			if err != nil {
+				log.Warn("Failed to unpack RefundETH event", "err", err)
+				return refundedMsgs, err
+			}
+			refundedMsgs = append(refundedMsgs, &orm.RefundedMsg{
+				Height:     vlog.BlockNumber,
+				Layer1Hash: vlog.TxHash.Hex(),
+				Recipient:  event.Recipient.Hex(),
+				Amount:     event.Amount.String(),
+			})
+		case backendabi.L1RefundERC20Sig:
+			event := backendabi.L1RefundERC20Event{}
+			err := UnpackLog(backendabi.L1GatewayRefundABI, &event, "RefundERC20", vlog)
+			if err != nil {
+				log.Warn("Failed to unpack RefundERC20 event", "err", err)
+				return refundedMsgs, err
+			}
+			refundedMsgs = append(refundedMsgs, &orm.RefundedMsg{
+				Height:     vlog.BlockNumber,
+				Layer1Hash: vlog.TxHash.Hex(),
+				Token:      event.Token.Hex(),
+				Recipient:  event.Recipient.Hex(),
+				Amount:     event.Amount.String(),
+			})
+		default:
+			continue
+		}
+	}
+	return refundedMsgs, nil
+}
+
 func convertBigIntArrayToString(array []*big.Int) string {
 	stringArray := make([]string, len(array))
 	for i, num := range array {
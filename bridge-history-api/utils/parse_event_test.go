@@ -0,0 +1,292 @@
+package utils_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	backendabi "bridge-history-api/abi"
+	"bridge-history-api/orm"
+	"bridge-history-api/utils"
+)
+
+func TestParseRelayedMsgDelivered(t *testing.T) {
+	deliveredTx := common.HexToHash("0x01")
+	failedDeliveryTx := common.HexToHash("0x02")
+	deliveredMsgHash := common.HexToHash("0x11")
+	failedDeliveryMsgHash := common.HexToHash("0x12")
+
+	// l2: the gateway finalize event is emitted before RelayedMessage in the same tx
+	l2Logs := []types.Log{
+		{Topics: []common.Hash{backendabi.L2FinalizeDepositETHSig}, TxHash: deliveredTx, BlockNumber: 1},
+		{Topics: []common.Hash{backendabi.L2RelayedMessageEventSignature, deliveredMsgHash}, TxHash: deliveredTx, BlockNumber: 1},
+		{Topics: []common.Hash{backendabi.L2RelayedMessageEventSignature, failedDeliveryMsgHash}, TxHash: failedDeliveryTx, BlockNumber: 2},
+	}
+	_, relayedMsgs, _, err := utils.ParseBackendL2EventLogs(l2Logs, nil)
+	assert.NoError(t, err)
+	assert.Len(t, relayedMsgs, 2)
+	assert.Equal(t, deliveredMsgHash.String(), relayedMsgs[0].MsgHash)
+	assert.True(t, relayedMsgs[0].Delivered)
+	assert.Equal(t, failedDeliveryMsgHash.String(), relayedMsgs[1].MsgHash)
+	assert.False(t, relayedMsgs[1].Delivered)
+
+	// l1
+	l1Logs := []types.Log{
+		{Topics: []common.Hash{backendabi.L1FinalizeWithdrawERC20Sig}, TxHash: deliveredTx, BlockNumber: 1},
+		{Topics: []common.Hash{backendabi.L1RelayedMessageEventSignature, deliveredMsgHash}, TxHash: deliveredTx, BlockNumber: 1},
+		{Topics: []common.Hash{backendabi.L1RelayedMessageEventSignature, failedDeliveryMsgHash}, TxHash: failedDeliveryTx, BlockNumber: 2},
+	}
+	_, relayedMsgs, err = utils.ParseBackendL1EventLogs(l1Logs, nil)
+	assert.NoError(t, err)
+	assert.Len(t, relayedMsgs, 2)
+	assert.True(t, relayedMsgs[0].Delivered)
+	assert.False(t, relayedMsgs[1].Delivered)
+}
+
+func TestParseRelayedMsgDeliveredInBatchRelay(t *testing.T) {
+	relayTx := common.HexToHash("0x01")
+	deliveredMsgHash, undeliveredMsgHash, failedMsgHash, lastMsgHash := common.HexToHash("0x11"), common.HexToHash("0x12"), common.HexToHash("0x13"), common.HexToHash("0x14")
+
+	// the tx relays four messages: the first one is delivered by the gateway, the second one calls no gateway, the
+	// relay of the third one fails and the last one is delivered
+	l2Logs := []types.Log{
+		{Topics: []common.Hash{backendabi.L2FinalizeDepositETHSig}, TxHash: relayTx, BlockNumber: 1},
+		{Topics: []common.Hash{backendabi.L2RelayedMessageEventSignature, deliveredMsgHash}, TxHash: relayTx, BlockNumber: 1},
+		{Topics: []common.Hash{backendabi.L2RelayedMessageEventSignature, undeliveredMsgHash}, TxHash: relayTx, BlockNumber: 1},
+		{Topics: []common.Hash{backendabi.L2FailedRelayedMessageEventSignature, failedMsgHash}, TxHash: relayTx, BlockNumber: 1},
+		{Topics: []common.Hash{backendabi.L2FinalizeDepositERC20Sig}, TxHash: relayTx, BlockNumber: 1},
+		{Topics: []common.Hash{backendabi.L2RelayedMessageEventSignature, lastMsgHash}, TxHash: relayTx, BlockNumber: 1},
+	}
+	_, relayedMsgs, _, err := utils.ParseBackendL2EventLogs(l2Logs, nil)
+	assert.NoError(t, err)
+	assert.Len(t, relayedMsgs, 3)
+	delivered := make(map[string]bool)
+	for _, relayedMsg := range relayedMsgs {
+		delivered[relayedMsg.MsgHash] = relayedMsg.Delivered
+	}
+	assert.Equal(t, map[string]bool{deliveredMsgHash.String(): true, undeliveredMsgHash.String(): false, lastMsgHash.String(): true}, delivered)
+
+	l1Logs := []types.Log{
+		{Topics: []common.Hash{backendabi.L1RelayedMessageEventSignature, undeliveredMsgHash}, TxHash: relayTx, BlockNumber: 1},
+		{Topics: []common.Hash{backendabi.L1FinalizeWithdrawERC20Sig}, TxHash: relayTx, BlockNumber: 1},
+		{Topics: []common.Hash{backendabi.L1RelayedMessageEventSignature, deliveredMsgHash}, TxHash: relayTx, BlockNumber: 1},
+	}
+	_, relayedMsgs, err = utils.ParseBackendL1EventLogs(l1Logs, nil)
+	assert.NoError(t, err)
+	assert.Len(t, relayedMsgs, 2)
+	assert.False(t, relayedMsgs[0].Delivered)
+	assert.True(t, relayedMsgs[1].Delivered)
+}
+
+func TestParseFailedRelayedMsgs(t *testing.T) {
+	txHash := common.HexToHash("0x01")
+	msgHash := common.HexToHash("0x11")
+
+	l2Logs := []types.Log{
+		{Topics: []common.Hash{backendabi.L2FailedRelayedMessageEventSignature, msgHash}, TxHash: txHash, BlockNumber: 1},
+		{Topics: []common.Hash{backendabi.L2RelayedMessageEventSignature, msgHash}, TxHash: txHash, BlockNumber: 1},
+	}
+	failedRelayedMsgs, err := utils.ParseBackendL2FailedRelayedMsgs(l2Logs)
+	assert.NoError(t, err)
+	assert.Len(t, failedRelayedMsgs, 1)
+	assert.Equal(t, msgHash.String(), failedRelayedMsgs[0].MsgHash)
+	assert.Equal(t, txHash.Hex(), failedRelayedMsgs[0].Layer2Hash)
+	assert.Empty(t, failedRelayedMsgs[0].Layer1Hash)
+
+	l1Logs := []types.Log{
+		{Topics: []common.Hash{backendabi.L1FailedRelayedMessageEventSignature, msgHash}, TxHash: txHash, BlockNumber: 2},
+	}
+	failedRelayedMsgs, err = utils.ParseBackendL1FailedRelayedMsgs(l1Logs)
+	assert.NoError(t, err)
+	assert.Len(t, failedRelayedMsgs, 1)
+	assert.Equal(t, txHash.Hex(), failedRelayedMsgs[0].Layer1Hash)
+	assert.Equal(t, uint64(2), failedRelayedMsgs[0].Height)
+}
+
+func TestParseDepositSentMessage(t *testing.T) {
+	txHash := common.HexToHash("0x01")
+	sender := common.HexToAddress("0x21")
+	target := common.HexToAddress("0x22")
+	message := []byte{0x12, 0x34}
+
+	sentMsgData, err := backendabi.L1ScrollMessengerABI.Events["SentMessage"].Inputs.NonIndexed().
+		Pack(big.NewInt(100), big.NewInt(7), big.NewInt(200000), message)
+	assert.NoError(t, err)
+	depositData, err := backendabi.L1ETHGatewayABI.Events["DepositETH"].Inputs.NonIndexed().
+		Pack(big.NewInt(100), []byte{})
+	assert.NoError(t, err)
+
+	// the gateway deposit event is emitted after SentMessage in the same tx
+	l1Logs := []types.Log{
+		{Topics: []common.Hash{backendabi.L1SentMessageEventSignature, sender.Hash(), target.Hash()}, Data: sentMsgData, TxHash: txHash, BlockNumber: 1},
+		{Topics: []common.Hash{backendabi.L1DepositETHSig, sender.Hash(), target.Hash()}, Data: depositData, TxHash: txHash, BlockNumber: 1},
+	}
+	crossMsgs, _, err := utils.ParseBackendL1EventLogs(l1Logs, nil)
+	assert.NoError(t, err)
+	assert.Len(t, crossMsgs, 1)
+	expectedMsgHash := utils.ComputeMessageHash(sender, target, big.NewInt(100), big.NewInt(7), message)
+	assert.Equal(t, expectedMsgHash.Hex(), crossMsgs[0].MsgHash)
+	assert.Equal(t, sender.Hex(), crossMsgs[0].MsgSender)
+	assert.Equal(t, target.Hex(), crossMsgs[0].MsgTarget)
+	assert.Equal(t, "100", crossMsgs[0].MsgValue)
+	assert.Equal(t, uint64(7), crossMsgs[0].MsgNonce)
+	assert.Equal(t, hexutil.Encode(message), crossMsgs[0].MsgData)
+}
+
+func TestParseMessageQueueEvents(t *testing.T) {
+	depositTx := common.HexToHash("0x01")
+	replayTx := common.HexToHash("0x02")
+	dropTx := common.HexToHash("0x03")
+	sender := common.HexToAddress("0x21")
+	target := common.HexToAddress("0x22")
+	message := []byte{0x12, 0x34}
+
+	relayCalldata, err := backendabi.L2ScrollMessengerABI.Pack("relayMessage", sender, target, big.NewInt(100), big.NewInt(7), message)
+	assert.NoError(t, err)
+	queueTxData := func(queueIndex int64, data []byte) []byte {
+		eventData, packErr := backendabi.L1MessageQueueABI.Events["QueueTransaction"].Inputs.NonIndexed().
+			Pack(big.NewInt(100), big.NewInt(queueIndex), big.NewInt(200000), data)
+		assert.NoError(t, packErr)
+		return eventData
+	}
+	dropData, err := backendabi.L1MessageQueueABI.Events["DropTransaction"].Inputs.NonIndexed().Pack(big.NewInt(7))
+	assert.NoError(t, err)
+
+	l1Logs := []types.Log{
+		// the deposit is queued at its nonce, then replayed at 9 and dropped
+		{Topics: []common.Hash{backendabi.L1QueueTransactionEventSignature, sender.Hash(), target.Hash()}, Data: queueTxData(7, relayCalldata), TxHash: depositTx, BlockNumber: 1},
+		{Topics: []common.Hash{backendabi.L1QueueTransactionEventSignature, sender.Hash(), target.Hash()}, Data: queueTxData(9, relayCalldata), TxHash: replayTx, BlockNumber: 2},
+		// an enforced tx
+		{Topics: []common.Hash{backendabi.L1QueueTransactionEventSignature, sender.Hash(), target.Hash()}, Data: queueTxData(10, []byte{0x01}), TxHash: replayTx, BlockNumber: 2},
+		{Topics: []common.Hash{backendabi.L1DropTransactionEventSignature}, Data: dropData, TxHash: dropTx, BlockNumber: 3},
+	}
+	replayedMsgs, droppedMsgs, err := utils.ParseBackendL1MessageQueueEvents(l1Logs)
+	assert.NoError(t, err)
+	assert.Equal(t, []*orm.ReplayedMsg{{
+		MsgHash:    utils.ComputeMessageHash(sender, target, big.NewInt(100), big.NewInt(7), message).Hex(),
+		QueueIndex: 9,
+		Height:     2,
+		Layer1Hash: replayTx.Hex(),
+	}}, replayedMsgs)
+	assert.Equal(t, []*orm.DroppedMsg{{QueueIndex: 7, Height: 3, Layer1Hash: dropTx.Hex()}}, droppedMsgs)
+}
+
+func TestParseRefundEvents(t *testing.T) {
+	dropTx := common.HexToHash("0x03")
+	token := common.HexToAddress("0x31")
+	recipient := common.HexToAddress("0x21")
+	refundETHData, err := backendabi.L1GatewayRefundABI.Events["RefundETH"].Inputs.NonIndexed().Pack(big.NewInt(100))
+	assert.NoError(t, err)
+	refundERC20Data, err := backendabi.L1GatewayRefundABI.Events["RefundERC20"].Inputs.NonIndexed().Pack(big.NewInt(200))
+	assert.NoError(t, err)
+
+	refundedMsgs, err := utils.ParseBackendL1RefundEvents([]types.Log{
+		{Topics: []common.Hash{backendabi.L1RefundETHSig, recipient.Hash()}, Data: refundETHData, TxHash: dropTx, BlockNumber: 3},
+		{Topics: []common.Hash{backendabi.L1RefundERC20Sig, token.Hash(), recipient.Hash()}, Data: refundERC20Data, TxHash: dropTx, BlockNumber: 4},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []*orm.RefundedMsg{
+		{Height: 3, Layer1Hash: dropTx.Hex(), Recipient: recipient.Hex(), Amount: "100"},
+		{Height: 4, Layer1Hash: dropTx.Hex(), Token: token.Hex(), Recipient: recipient.Hex(), Amount: "200"},
+	}, refundedMsgs)
+}
+
+func TestParseNFTEvents(t *testing.T) {
+	l1Token := common.HexToAddress("0x31")
+	l2Token := common.HexToAddress("0x32")
+	from := common.HexToAddress("0x21")
+	to := common.HexToAddress("0x22")
+	topics := func(sig common.Hash) []common.Hash {
+		return []common.Hash{sig, l1Token.Hash(), l2Token.Hash(), from.Hash()}
+	}
+
+	depositData, err := backendabi.L1ERC1155GatewayABI.Events["DepositERC1155"].Inputs.NonIndexed().
+		Pack(to, big.NewInt(1), big.NewInt(10))
+	assert.NoError(t, err)
+	batchDepositData, err := backendabi.L1ERC1155GatewayABI.Events["BatchDepositERC1155"].Inputs.NonIndexed().
+		Pack(to, []*big.Int{big.NewInt(2), big.NewInt(3)}, []*big.Int{big.NewInt(20), big.NewInt(30)})
+	assert.NoError(t, err)
+
+	deposit721Data, err := backendabi.L1ERC721GatewayABI.Events["DepositERC721"].Inputs.NonIndexed().
+		Pack(to, big.NewInt(4))
+	assert.NoError(t, err)
+
+	l1Logs := []types.Log{
+		{Topics: topics(backendabi.L1DepositERC1155Sig), Data: depositData, TxHash: common.HexToHash("0x01"), BlockNumber: 1},
+		{Topics: topics(backendabi.L1BatchDepositERC1155Sig), Data: batchDepositData, TxHash: common.HexToHash("0x02"), BlockNumber: 2},
+		{Topics: topics(backendabi.L1DepositERC721Sig), Data: deposit721Data, TxHash: common.HexToHash("0x03"), BlockNumber: 3},
+	}
+	crossMsgs, _, err := utils.ParseBackendL1EventLogs(l1Logs, nil)
+	assert.NoError(t, err)
+	assert.Len(t, crossMsgs, 3)
+	// the amount of a single token id is stored as the token amounts too
+	assert.Equal(t, []string{"1"}, crossMsgs[0].TokenIDList())
+	assert.Equal(t, []string{"10"}, crossMsgs[0].TokenAmountList())
+	assert.Equal(t, []string{"2", "3"}, crossMsgs[1].TokenIDList())
+	assert.Equal(t, []string{"20", "30"}, crossMsgs[1].TokenAmountList())
+	assert.Equal(t, int(orm.ERC721), crossMsgs[2].Asset)
+	assert.Equal(t, []string{"4"}, crossMsgs[2].TokenIDList())
+	assert.Nil(t, crossMsgs[2].TokenAmountList())
+
+	// the arguments of the layer2 gateways are named without the underscore
+	sentMsgData, err := backendabi.L2ScrollMessengerABI.Events["SentMessage"].Inputs.NonIndexed().
+		Pack(big.NewInt(0), big.NewInt(1), big.NewInt(0), []byte{})
+	assert.NoError(t, err)
+	batchWithdrawData, err := backendabi.L2ERC1155GatewayABI.Events["BatchWithdrawERC1155"].Inputs.NonIndexed().
+		Pack(to, []*big.Int{big.NewInt(5)}, []*big.Int{big.NewInt(50)})
+	assert.NoError(t, err)
+	l2Logs := []types.Log{
+		{Topics: []common.Hash{backendabi.L2SentMessageEventSignature, from.Hash(), to.Hash()}, Data: sentMsgData, TxHash: common.HexToHash("0x04"), BlockNumber: 4},
+		{Topics: topics(backendabi.L2BatchWithdrawERC1155Sig), Data: batchWithdrawData, TxHash: common.HexToHash("0x04"), BlockNumber: 4},
+	}
+	l2CrossMsgs, _, _, err := utils.ParseBackendL2EventLogs(l2Logs, nil)
+	assert.NoError(t, err)
+	assert.Len(t, l2CrossMsgs, 1)
+	assert.Equal(t, []string{"5"}, l2CrossMsgs[0].TokenIDList())
+	assert.Equal(t, []string{"50"}, l2CrossMsgs[0].TokenAmountList())
+}
+
+func TestParseWithdrawalLogIndex(t *testing.T) {
+	sender := common.HexToAddress("0x21")
+	target := common.HexToAddress("0x22")
+	var l2Logs []types.Log
+	for i, nonce := range []int64{7, 8} {
+		sentMsgData, err := backendabi.L2ScrollMessengerABI.Events["SentMessage"].Inputs.NonIndexed().
+			Pack(big.NewInt(100), big.NewInt(nonce), big.NewInt(0), []byte{})
+		assert.NoError(t, err)
+		// two withdrawals sent in the same tx of the same block
+		l2Logs = append(l2Logs, types.Log{
+			Topics:      []common.Hash{backendabi.L2SentMessageEventSignature, sender.Hash(), target.Hash()},
+			Data:        sentMsgData,
+			TxHash:      common.HexToHash("0x01"),
+			BlockNumber: 1,
+			Index:       uint(3 + 2*i),
+		})
+	}
+	_, _, l2SentMsgs, err := utils.ParseBackendL2EventLogs(l2Logs, nil)
+	assert.NoError(t, err)
+	assert.Len(t, l2SentMsgs, 2)
+	assert.Equal(t, l2SentMsgs[0].Height, l2SentMsgs[1].Height)
+	assert.NotNil(t, l2SentMsgs[0].LogIndex)
+	assert.NotNil(t, l2SentMsgs[1].LogIndex)
+	assert.Equal(t, uint64(3), *l2SentMsgs[0].LogIndex)
+	assert.Equal(t, uint64(5), *l2SentMsgs[1].LogIndex)
+}
+
+func TestParseBatchRevert(t *testing.T) {
+	batchHash := common.HexToHash("0x31")
+	logs := []types.Log{
+		{Topics: []common.Hash{backendabi.L1RevertBatchEventSignature, common.BigToHash(big.NewInt(5)), batchHash}, BlockNumber: 1},
+		{Topics: []common.Hash{backendabi.L1CommitBatchEventSignature, common.BigToHash(big.NewInt(5)), common.HexToHash("0x32")}, BlockNumber: 2},
+	}
+	revertedBatches, err := utils.ParseBatchRevertFromScrollChain(logs)
+	assert.NoError(t, err)
+	assert.Len(t, revertedBatches, 1)
+	assert.Equal(t, uint64(5), revertedBatches[0].BatchIndex)
+	assert.Equal(t, batchHash.Hex(), revertedBatches[0].BatchHash)
+	assert.True(t, revertedBatches[0].Reverted)
+}
@@ -3,12 +3,15 @@ package utils
 import (
 	"context"
 	"encoding/binary"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -16,6 +19,16 @@ import (
 	backendabi "bridge-history-api/abi"
 )
 
+// l1DepositABIs are the abis of the layer1 contracts called by deposit txs,
+// the gateway router shares the method selectors with the gateways.
+var l1DepositABIs = []*abi.ABI{
+	backendabi.L1ETHGatewayABI,
+	backendabi.L1StandardERC20GatewayABI,
+	backendabi.L1ERC721GatewayABI,
+	backendabi.L1ERC1155GatewayABI,
+	backendabi.L1ScrollMessengerABI,
+}
+
 // Keccak2 compute the keccack256 of two concatenations of bytes32
 func Keccak2(a common.Hash, b common.Hash) common.Hash {
 	return common.BytesToHash(crypto.Keccak256(append(a.Bytes()[:], b.Bytes()[:]...)))
@@ -31,6 +44,77 @@ func GetSafeBlockNumber(ctx context.Context, client *ethclient.Client, confirmat
 	return number, nil
 }
 
+// GetTxGasFee get the gas fee in wei paid by the sender of the tx, the layer1 data fee of layer2 txs is not included
+func GetTxGasFee(ctx context.Context, client *ethclient.Client, tx *types.Transaction) (*big.Int, error) {
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		return nil, err
+	}
+	return receiptGasFee(receipt, tx), nil
+}
+
+// GetTxFees get the gas fee in wei paid by the sender of the tx along with the layer1 data fee of the layer2 txs, the
+// `l1Fee` of the receipts of the scroll nodes. The data fee is nil if the receipt has none, e.g. on layer1.
+func GetTxFees(ctx context.Context, client *ethclient.Client, tx *types.Transaction) (*big.Int, *big.Int, error) {
+	var raw json.RawMessage
+	if err := client.Client().CallContext(ctx, &raw, "eth_getTransactionReceipt", tx.Hash()); err != nil {
+		return nil, nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil, ethereum.NotFound
+	}
+	var receipt types.Receipt
+	if err := json.Unmarshal(raw, &receipt); err != nil {
+		return nil, nil, err
+	}
+	// the geth receipts drop the fields of the scroll nodes
+	var scrollReceipt struct {
+		L1Fee *hexutil.Big `json:"l1Fee"`
+	}
+	if err := json.Unmarshal(raw, &scrollReceipt); err != nil {
+		return nil, nil, err
+	}
+	return receiptGasFee(&receipt, tx), (*big.Int)(scrollReceipt.L1Fee), nil
+}
+
+// receiptGasFee returns the gas used by the receipt of the tx times its effective gas price
+func receiptGasFee(receipt *types.Receipt, tx *types.Transaction) *big.Int {
+	gasPrice := receipt.EffectiveGasPrice
+	if gasPrice == nil {
+		// the nodes not returning the effective gas price predate the dynamic fee txs
+		gasPrice = tx.GasPrice()
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), gasPrice)
+}
+
+// GetBridgeFee get the fee in wei paid by the tx for the message of msgValue, which is the value of the tx on top of the
+// message value, i.e. the fee of the layer1 message queue for deposits. Zero is returned if the tx sends less value.
+func GetBridgeFee(tx *types.Transaction, msgValue string) *big.Int {
+	value, ok := new(big.Int).SetString(msgValue, 10)
+	if !ok {
+		value = big.NewInt(0)
+	}
+	fee := new(big.Int).Sub(tx.Value(), value)
+	if fee.Sign() < 0 {
+		return big.NewInt(0)
+	}
+	return fee
+}
+
+// DecodeL1DepositMethod decodes the method name from the input of the layer1 deposit tx,
+// returns an empty string if the method is unknown.
+func DecodeL1DepositMethod(input []byte) string {
+	if len(input) < 4 {
+		return ""
+	}
+	for _, contractABI := range l1DepositABIs {
+		if method, err := contractABI.MethodById(input[:4]); err == nil {
+			return method.RawName
+		}
+	}
+	return ""
+}
+
 // UnpackLog unpacks a retrieved log into the provided output structure.
 // @todo: add unit test.
 func UnpackLog(c *abi.ABI, out interface{}, event string, log types.Log) error {
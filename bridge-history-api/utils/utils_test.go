@@ -1,11 +1,19 @@
 package utils_test
 
 import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	backendabi "bridge-history-api/abi"
 	"bridge-history-api/utils"
 )
 
@@ -41,3 +49,58 @@ func TestGetBatchRangeFromCalldataV2(t *testing.T) {
 	assert.Equal(t, finish, uint64(0))
 	assert.Equal(t, batchIndex, uint64(0))
 }
+
+func TestDecodeL1DepositMethod(t *testing.T) {
+	input, err := backendabi.L1StandardERC20GatewayABI.Pack("depositERC20",
+		common.HexToAddress("0x01"), big.NewInt(100), big.NewInt(200000))
+	assert.NoError(t, err)
+	assert.Equal(t, "depositERC20", utils.DecodeL1DepositMethod(input))
+
+	input, err = backendabi.L1ETHGatewayABI.Pack("depositETH", big.NewInt(100), big.NewInt(200000))
+	assert.NoError(t, err)
+	assert.Equal(t, "depositETH", utils.DecodeL1DepositMethod(input))
+
+	// overloaded method
+	input, err = backendabi.L1ETHGatewayABI.Pack("depositETH0", common.HexToAddress("0x01"), big.NewInt(100), big.NewInt(200000))
+	assert.NoError(t, err)
+	assert.Equal(t, "depositETH", utils.DecodeL1DepositMethod(input))
+
+	// unknown selector and missing input
+	assert.Empty(t, utils.DecodeL1DepositMethod(common.Hex2Bytes("deadbeef")))
+	assert.Empty(t, utils.DecodeL1DepositMethod(nil))
+}
+
+func TestGetBridgeFee(t *testing.T) {
+	tx := types.NewTx(&types.LegacyTx{Value: big.NewInt(1100)})
+	// an ETH deposit pays the fee on top of the amount
+	assert.Equal(t, big.NewInt(100), utils.GetBridgeFee(tx, "1000"))
+	// an ERC20 deposit sends no message value
+	assert.Equal(t, big.NewInt(1100), utils.GetBridgeFee(tx, ""))
+	assert.Equal(t, big.NewInt(0), utils.GetBridgeFee(tx, "2000"))
+}
+
+func TestGetTxFees(t *testing.T) {
+	// the receipt of a scroll node carries the layer1 data fee
+	l1Fee := `,"l1Fee":"0x3e8"`
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"jsonrpc":"2.0","id":1,"result":{"type":"0x0","status":"0x1","cumulativeGasUsed":"0x5208","logsBloom":"0x%0512x","logs":[],`+
+			`"transactionHash":"0x%064x","gasUsed":"0x5208","effectiveGasPrice":"0x2"%s}}`, 0, 1, l1Fee)
+	}))
+	defer node.Close()
+	client, err := utils.DialEthClient(node.URL)
+	require.NoError(t, err)
+	tx := types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(3)})
+
+	gasFee, l1DataFee, err := utils.GetTxFees(context.Background(), client, tx)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(42000), gasFee)
+	assert.Equal(t, big.NewInt(1000), l1DataFee)
+
+	// the layer1 receipts have none
+	l1Fee = ""
+	gasFee, l1DataFee, err = utils.GetTxFees(context.Background(), client, tx)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(42000), gasFee)
+	assert.Nil(t, l1DataFee)
+}